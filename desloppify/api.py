@@ -0,0 +1,35 @@
+"""Public embedding API facade.
+
+Run a scan in-process without shelling out to the CLI::
+
+    from desloppify import api
+
+    report = api.run(api.Options(path="services/billing", lang="go"))
+    for finding in report.findings:
+        ...
+
+The implementation lives in `desloppify.engine.planning.embed`; this module is
+the stable surface for external tooling.
+"""
+
+from desloppify.engine.planning.embed import (
+    Options,
+    PhaseResult,
+    Report,
+    ScanCancelled,
+    iter_phases,
+    run,
+    write_json,
+)
+from desloppify.state import Finding
+
+__all__ = [
+    "Finding",
+    "Options",
+    "PhaseResult",
+    "Report",
+    "ScanCancelled",
+    "iter_phases",
+    "run",
+    "write_json",
+]
@@ -5,13 +5,17 @@ from __future__ import annotations
 import argparse
 
 from desloppify.app.cli_support.parser_groups import (
+    _add_cache_parser,
+    _add_compare_parser,
     _add_config_parser,
     _add_detect_parser,
     _add_dev_parser,
     _add_fix_parser,
     _add_ignore_parser,
+    _add_install_hook_parser,
     _add_issues_parser,
     _add_langs_parser,
+    _add_lsp_parser,
     _add_move_parser,
     _add_next_parser,
     _add_plan_parser,
@@ -21,19 +25,25 @@ from desloppify.app.cli_support.parser_groups import (
     _add_show_parser,
     _add_status_parser,
     _add_tree_parser,
+    _add_triage_parser,
     _add_update_skill_parser,
+    _add_vettool_parser,
     _add_viz_parser,
+    _add_watch_parser,
     _add_zone_parser,
 )
 
 USAGE_EXAMPLES = """
 workflow:
   scan                          Run all detectors, update state, show diff
+  scan ./... (or just ./...)    Analyze the packages a Go pattern matches (--tags honored)
+  watch [path]                  Re-analyze changed packages on save (JSONL when piped)
   status                        Score dashboard with per-tier progress
   tree                          Annotated codebase tree (zoom with --focus)
   show <pattern>                Dig into findings by file/dir/detector/ID
   resolve <pattern> <status>    Mark findings as fixed/wontfix/false_positive
   ignore <pattern>              Suppress findings matching a pattern
+  triage                        Decide on open findings one at a time (resumable)
   zone show                     Show zone classifications for all files
   zone set <file> <zone>        Override zone for a file
   review --prepare              Prepare holistic codebase review data
@@ -104,12 +114,14 @@ def create_parser(*, langs: list[str], detector_names: list[str]) -> argparse.Ar
         parser_class=_NoAbbrevArgumentParser,
     )
     _add_scan_parser(sub)
+    _add_watch_parser(sub)
     _add_status_parser(sub)
     _add_tree_parser(sub)
     _add_show_parser(sub)
     _add_next_parser(sub)
     _add_resolve_parser(sub)
     _add_ignore_parser(sub)
+    _add_triage_parser(sub)
     _add_fix_parser(sub, langs)
     _add_plan_parser(sub)
     _add_viz_parser(sub)
@@ -121,8 +133,37 @@ def create_parser(*, langs: list[str], detector_names: list[str]) -> argparse.Ar
     _add_config_parser(sub)
     _add_dev_parser(sub)
     _add_langs_parser(sub)
+    _add_lsp_parser(sub)
+    _add_install_hook_parser(sub)
+    _add_cache_parser(sub)
+    _add_compare_parser(sub)
     _add_update_skill_parser(sub)
+    _add_vettool_parser(sub)
     return parser
 
 
-__all__ = ["USAGE_EXAMPLES", "create_parser"]
+# Top-level options that take a value, skipped when looking for the command.
+_TOP_LEVEL_VALUE_OPTIONS = frozenset({"--lang", "--exclude"})
+
+
+def expand_scan_shorthand(argv: list[str]) -> list[str]:
+    """Treat ``desloppify ./...`` as ``desloppify scan ./...``.
+
+    Only a package pattern (it contains ``...``) standing where the command
+    belongs is rewritten; every other command line parses as before.
+    """
+    index = 0
+    while index < len(argv):
+        token = argv[index]
+        if token in _TOP_LEVEL_VALUE_OPTIONS:
+            index += 2
+        elif token.startswith("-"):
+            index += 1
+        elif "..." in token:
+            return [*argv[:index], "scan", *argv[index:]]
+        else:
+            break
+    return argv
+
+
+__all__ = ["USAGE_EXAMPLES", "create_parser", "expand_scan_shorthand"]
@@ -2,29 +2,39 @@
 
 from __future__ import annotations
 
+import argparse
+
 from desloppify.app.cli_support.parser_groups_admin import (  # noqa: F401 (re-exports)
+    _add_cache_parser,
+    _add_compare_parser,
     _add_config_parser,
     _add_detect_parser,
     _add_dev_parser,
     _add_fix_parser,
+    _add_install_hook_parser,
     _add_issues_parser,
     _add_langs_parser,
     _add_move_parser,
     _add_plan_parser,
     _add_review_parser,
     _add_update_skill_parser,
+    _add_vettool_parser,
     _add_viz_parser,
     _add_zone_parser,
 )
 
 __all__ = [
+    "_add_cache_parser",
+    "_add_compare_parser",
     "_add_config_parser",
     "_add_detect_parser",
     "_add_dev_parser",
     "_add_fix_parser",
     "_add_ignore_parser",
+    "_add_install_hook_parser",
     "_add_issues_parser",
     "_add_langs_parser",
+    "_add_lsp_parser",
     "_add_move_parser",
     "_add_next_parser",
     "_add_plan_parser",
@@ -34,15 +44,87 @@ __all__ = [
     "_add_show_parser",
     "_add_status_parser",
     "_add_tree_parser",
+    "_add_triage_parser",
     "_add_update_skill_parser",
+    "_add_vettool_parser",
     "_add_viz_parser",
+    "_add_watch_parser",
     "_add_zone_parser",
 ]
 
 
+def _positive_int(value: str) -> int:
+    try:
+        number = int(value)
+    except ValueError:
+        number = 0
+    if number < 1:
+        raise argparse.ArgumentTypeError(f"expected a positive integer, got {value!r}")
+    return number
+
+
 def _add_scan_parser(sub) -> None:
     p_scan = sub.add_parser("scan", help="Run all detectors, update state, show diff")
+    p_scan.add_argument(
+        "patterns",
+        nargs="*",
+        metavar="PATTERN",
+        help="Package patterns to analyze, resolved by the language's loader "
+        "(Go: ./..., ./internal/..., import paths)",
+    )
     p_scan.add_argument("--path", type=str, default=None)
+    p_scan.add_argument(
+        "--tags",
+        "-tags",
+        type=str,
+        default=None,
+        metavar="TAG,...",
+        help="Build tags: files whose //go:build constraints they fail are skipped (Go)",
+    )
+    p_scan.add_argument(
+        "--module",
+        action="append",
+        default=None,
+        metavar="MODULE",
+        help="Only analyze this module of a multi-module repo, by module path or "
+        "go.mod directory; repeatable (Go)",
+    )
+    p_scan.add_argument(
+        "--include-vendor",
+        action="store_true",
+        help="Also analyze vendor/ and the configured third_party_paths (Go)",
+    )
+    p_scan.add_argument(
+        "--only",
+        action="append",
+        default=None,
+        metavar="GLOB",
+        help="Only analyze files matching this glob, relative to the scan path "
+        "(** spans directories, e.g. internal/**); repeatable or comma-separated",
+    )
+    p_scan.add_argument(
+        "--skip",
+        action="append",
+        default=None,
+        metavar="GLOB",
+        help="Leave out files matching this glob, relative to the scan path "
+        "(e.g. **/*_gen.go); repeatable or comma-separated",
+    )
+    p_scan.add_argument(
+        "--category",
+        action="append",
+        default=None,
+        metavar="CATEGORY",
+        help="Only run rules in this category (correctness, concurrency, performance, "
+        "security, style); repeatable or comma-separated (Go)",
+    )
+    p_scan.add_argument(
+        "--exclude-category",
+        action="append",
+        default=None,
+        metavar="CATEGORY",
+        help="Skip rules in this category; repeatable or comma-separated (Go)",
+    )
     p_scan.add_argument("--state", type=str, default=None)
     p_scan.add_argument(
         "--reset-subjective",
@@ -75,6 +157,158 @@ def _add_scan_parser(sub) -> None:
         metavar="PATH",
         help="Output path for scorecard image (default: scorecard.png)",
     )
+    p_scan.add_argument(
+        "--changed",
+        action="store_true",
+        help="Only analyze directories (Go packages) with files modified, staged, "
+        "or untracked per git status, plus the packages that import them",
+    )
+    p_scan.add_argument(
+        "--since",
+        type=str,
+        default=None,
+        metavar="REV",
+        help="Only analyze directories touched between REV and HEAD, plus the "
+        "packages that import them (combines with --changed)",
+    )
+    p_scan.add_argument(
+        "--diff-base",
+        type=str,
+        default=None,
+        metavar="REV",
+        help="Only report findings on lines changed in the working tree since REV "
+        "(narrows --changed and --since)",
+    )
+    p_scan.add_argument(
+        "--jobs",
+        type=int,
+        default=None,
+        metavar="N",
+        help="Analyze up to N packages in parallel (default: GOMAXPROCS, else CPU count)",
+    )
+    p_scan.add_argument(
+        "--no-cache",
+        action="store_true",
+        help="Re-analyze every package instead of reusing cached per-package results",
+    )
+    p_scan.add_argument(
+        "--lenient-config",
+        action="store_true",
+        help="Report unknown rule ids, unknown option keys and invalid option values "
+        "in config as warnings instead of errors (for configs shared across versions)",
+    )
+    p_scan.add_argument(
+        "--fast",
+        action="store_true",
+        help="Run only syntax-level rules, skipping type-aware and module-graph "
+        "analysis regardless of config (for editors and pre-commit)",
+    )
+    p_scan.add_argument(
+        "--stream",
+        action="store_true",
+        help="Print findings as JSON lines as each phase finishes, then a summary "
+        "line; the state file is not updated",
+    )
+    p_scan.add_argument(
+        "--max-memory",
+        type=int,
+        default=None,
+        metavar="MB",
+        help="Soft memory hint; findings beyond a quarter of it are spilled to "
+        "temporary files until the final sort (default: 1024)",
+    )
+    p_scan.add_argument(
+        "--no-fail",
+        action="store_true",
+        help="Report only: exit 0 even with findings at or above fail_severity "
+        "(errors still exit 2)",
+    )
+    p_scan.add_argument(
+        "--fail-on-degraded",
+        action="store_true",
+        help="Exit 3 instead of 0 when a file did not parse or a package did not "
+        "build, so some rules were skipped for it",
+    )
+    p_scan.add_argument(
+        "--timings",
+        action="store_true",
+        help="Report wall time per phase and the top 20 rules and packages at the "
+        "end of the run (also under diagnostics in JSON output)",
+    )
+    p_scan.add_argument(
+        "--cpuprofile",
+        type=str,
+        default=None,
+        metavar="FILE",
+        help="Write a cProfile (pstats) CPU profile of the run to FILE",
+    )
+    p_scan.add_argument(
+        "--memprofile",
+        type=str,
+        default=None,
+        metavar="FILE",
+        help="Write a tracemalloc snapshot of live allocations at the end of the run to FILE",
+    )
+    p_scan.add_argument(
+        "--trace",
+        type=str,
+        default=None,
+        metavar="FILE",
+        help="Write a Chrome trace-event JSON of phases and packages to FILE "
+        "(open in Perfetto or chrome://tracing)",
+    )
+    p_scan.add_argument(
+        "--staged",
+        action="store_true",
+        help="Analyze the staged (index) content of changed files, print findings, "
+        "and exit 1 at or above config staged_fail_severity (for pre-commit hooks)",
+    )
+    p_scan.add_argument(
+        "files",
+        nargs="*",
+        metavar="FILE",
+        help="With --staged: check these files instead of git's staged list "
+        "(as passed by the pre-commit framework)",
+    )
+    p_scan.add_argument(
+        "--stdin",
+        action="store_true",
+        help="Analyze source read from stdin as --stdin-filename and print its "
+        "findings as JSON (no state update; for editor integration)",
+    )
+    p_scan.add_argument(
+        "--stdin-filename",
+        type=str,
+        default=None,
+        metavar="PATH",
+        help="Real path of the stdin buffer (need not exist yet)",
+    )
+    p_scan.add_argument(
+        "--verbose",
+        action="store_true",
+        help="Also report finding counts before overlapping findings were merged "
+        "and the --timings breakdown; with --stdin: show phase progress and "
+        "analysis time on stderr",
+    )
+    p_scan.add_argument(
+        "--all-matches",
+        action="store_true",
+        help="Keep every location of an aggregated finding, not just a sample "
+        "(for tools reading --stream, such as the go vet shim)",
+    )
+    p_scan.add_argument(
+        "--max-findings",
+        type=_positive_int,
+        default=None,
+        metavar="N",
+        help="Print at most N findings (in file order) and the count of the rest; "
+        "the exit code still counts them all (with --staged and --stream)",
+    )
+    p_scan.add_argument(
+        "--quiet",
+        action="store_true",
+        help="No live progress line on stderr (it is only drawn when stderr is a terminal)",
+    )
     p_scan.add_argument(
         "--lang-opt",
         action="append",
@@ -84,10 +318,68 @@ def _add_scan_parser(sub) -> None:
     )
 
 
+def _add_watch_parser(sub) -> None:
+    p_watch = sub.add_parser(
+        "watch", help="Re-analyze changed packages on save and print finding deltas"
+    )
+    p_watch.add_argument(
+        "path", nargs="?", default=None, help="Directory to watch (default: source root)"
+    )
+    p_watch.add_argument(
+        "--interval",
+        type=float,
+        default=0.5,
+        metavar="SECONDS",
+        help="Polling interval (default: 0.5)",
+    )
+    p_watch.add_argument(
+        "--debounce",
+        type=float,
+        default=0.3,
+        metavar="SECONDS",
+        help="Quiet period after the last change before re-analyzing (default: 0.3)",
+    )
+    p_watch.add_argument(
+        "--lang-opt",
+        action="append",
+        default=None,
+        metavar="KEY=VALUE",
+        help="Language runtime option override (repeatable)",
+    )
+
+
+def _add_lsp_parser(sub) -> None:
+    p_lsp = sub.add_parser(
+        "lsp", help="Language Server Protocol over stdio (findings as diagnostics)"
+    )
+    p_lsp.add_argument(
+        "--debounce",
+        type=float,
+        default=0.5,
+        metavar="SECONDS",
+        help="Delay after the last edit before re-analyzing unsaved buffers "
+        "(default: 0.5; saves analyze immediately)",
+    )
+    p_lsp.add_argument(
+        "--lang-opt",
+        action="append",
+        default=None,
+        metavar="KEY=VALUE",
+        help="Language runtime option override (repeatable)",
+    )
+
+
 def _add_status_parser(sub) -> None:
     p_status = sub.add_parser("status", help="Score dashboard with per-tier progress")
     p_status.add_argument("--state", type=str, default=None)
     p_status.add_argument("--json", action="store_true")
+    p_status.add_argument(
+        "--owner",
+        type=str,
+        default=None,
+        metavar="OWNER",
+        help="Only findings CODEOWNERS assigns to OWNER (e.g. @platform-team, or unowned)",
+    )
 
 
 def _add_tree_parser(sub) -> None:
@@ -241,3 +533,15 @@ def _add_ignore_parser(sub) -> None:
     p_ignore.add_argument("--state", type=str, default=None)
 
 
+def _add_triage_parser(sub) -> None:
+    p_triage = sub.add_parser(
+        "triage", help="Step through open findings: fix, suppress, baseline, or skip each"
+    )
+    p_triage.add_argument(
+        "--reset",
+        action="store_true",
+        help="Forget earlier triage decisions and start over",
+    )
+    p_triage.add_argument("--state", type=str, default=None)
+
+
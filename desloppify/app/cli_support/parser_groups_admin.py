@@ -376,7 +376,14 @@ def _add_fix_parser(sub, langs: list[str]) -> None:
     p_fix.add_argument(
         "--dry-run",
         action="store_true",
-        help="Show what would change without modifying files",
+        help="Print a unified diff of what would change without modifying files",
+    )
+    p_fix.add_argument(
+        "--fix-rule",
+        action="append",
+        default=None,
+        metavar="RULE",
+        help="Only apply fixes for this rule id (repeatable, e.g. error_not_wrapped)",
     )
 
 
@@ -388,6 +395,13 @@ def _add_plan_parser(sub) -> None:
     p_plan.add_argument(
         "--output", type=str, metavar="FILE", help="Write to file instead of stdout"
     )
+    p_plan.add_argument(
+        "--owner",
+        type=str,
+        default=None,
+        metavar="OWNER",
+        help="Only findings CODEOWNERS assigns to OWNER (e.g. @platform-team, or unowned)",
+    )
 
 
 def _add_viz_parser(sub) -> None:
@@ -438,7 +452,56 @@ def _add_dev_parser(sub) -> None:
 
 
 def _add_langs_parser(sub) -> None:
-    sub.add_parser("langs", help="List all available language plugins with depth and tools")
+    p_langs = sub.add_parser(
+        "langs", help="List all available language plugins with depth and tools"
+    )
+    p_langs.add_argument(
+        "--rules",
+        action="store_true",
+        help="List each language's phases and rules with the analysis level they need "
+        "(syntax, types, module); narrow with --lang",
+    )
+
+
+def _add_cache_parser(sub) -> None:
+    p_cache = sub.add_parser("cache", help="Manage the per-package result cache")
+    cache_sub = p_cache.add_subparsers(dest="cache_action", required=True)
+    cache_sub.add_parser("clean", help="Delete every cached result")
+    cache_sub.add_parser("path", help="Print the cache directory")
+
+
+def _add_compare_parser(sub) -> None:
+    p = sub.add_parser(
+        "compare",
+        help="Findings introduced, resolved and persisting between two runs "
+        "(state files or scan --stream output); exit 1 on new findings",
+    )
+    p.add_argument("old", help="Earlier run: a state-<lang>.json file or scan --stream output")
+    p.add_argument("new", help="Later run, in either format")
+    p.add_argument("--json", action="store_true", help="Print the comparison as JSON")
+    p.add_argument(
+        "--max-introduced",
+        type=int,
+        default=0,
+        metavar="N",
+        help="Exit 1 only when more than N findings were introduced (default: 0)",
+    )
+
+
+def _add_install_hook_parser(sub) -> None:
+    sub.add_parser(
+        "install-hook",
+        help="Install a git pre-commit hook that runs `scan --staged` "
+        "(an existing hook is kept and run first)",
+    )
+
+
+def _add_vettool_parser(sub) -> None:
+    sub.add_parser(
+        "vettool",
+        help="Build the go vet shim and print its path "
+        "(go vet -vettool=$(desloppify vettool) ./...)",
+    )
 
 
 def _add_update_skill_parser(sub) -> None:
@@ -44,3 +44,15 @@ def _print_fix_file_sample(result: dict, entries: list[dict]) -> None:
             marker = colorize("  →", "red") if idx == line_idx else "   "
             print(f"    {marker} {idx+1:4d}  {lines[idx][:90]}")
         shown += 1
+
+
+_DIFF_COLORS = (("+++", "bold"), ("---", "bold"), ("@@", "cyan"), ("+", "green"), ("-", "red"))
+
+
+def show_fix_dry_run_diffs(results: list[dict]) -> None:
+    """Print the unified diff each fixed file would get, in file order."""
+    for result in results:
+        for line in str(result.get("diff", "")).splitlines():
+            color = next((c for prefix, c in _DIFF_COLORS if line.startswith(prefix)), "")
+            print(colorize(line, color) if color else line)
+    print()
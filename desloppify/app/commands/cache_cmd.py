@@ -0,0 +1,18 @@
+"""cache command: inspect or clear the per-package result cache."""
+
+from __future__ import annotations
+
+import argparse
+
+from desloppify.core.result_cache import ResultCache
+from desloppify.utils import colorize
+
+
+def cmd_cache(args: argparse.Namespace) -> None:
+    """Handle cache subcommands: clean, path."""
+    cache = ResultCache()
+    if getattr(args, "cache_action", None) == "clean":
+        removed = cache.clean()
+        print(colorize(f"  Removed {removed} cached result(s) from {cache.root}", "green"))
+    else:
+        print(cache.root)
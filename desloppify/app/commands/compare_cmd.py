@@ -0,0 +1,106 @@
+"""compare command: introduced vs resolved findings between two runs."""
+
+from __future__ import annotations
+
+import argparse
+import json
+import sys
+from pathlib import Path
+from typing import Any
+
+from desloppify.app.commands.helpers.exit_codes import ERROR_EXIT_CODE, FINDINGS_EXIT_CODE
+from desloppify.engine.compare import (
+    PROBABLY_MOVED,
+    Comparison,
+    compare_runs,
+    load_run,
+    score_delta,
+)
+from desloppify.engine.planning.fingerprint import finding_line
+from desloppify.utils import colorize
+
+
+def _row(finding: dict[str, Any]) -> dict[str, Any]:
+    return {
+        "id": finding.get("id"),
+        "file": finding.get("file"),
+        "line": finding_line(finding) or None,
+        "detector": finding.get("detector"),
+        "summary": finding.get("summary"),
+    }
+
+
+def comparison_payload(comparison: Comparison, scores: dict) -> dict[str, Any]:
+    """The ``compare --json`` document."""
+    return {
+        "introduced": [_row(f) for f in comparison.introduced],
+        "resolved": [_row(f) for f in comparison.resolved],
+        "persisting": len(comparison.persisting),
+        "moved": [
+            {
+                "id": after.get("id"),
+                "from": before.get("file"),
+                "to": after.get("file"),
+                "match": how,
+            }
+            for before, after, how in comparison.moved
+        ],
+        "score": scores,
+    }
+
+
+def _print_rows(title: str, findings: list[dict[str, Any]], color: str) -> None:
+    if not findings:
+        return
+    print(colorize(f"\n  {title}", "bold"))
+    rows = sorted((_row(f) for f in findings), key=lambda r: (r["file"] or "", r["line"] or 0))
+    for row in rows:
+        location = f"{row['file']}:{row['line']}" if row["line"] else str(row["file"])
+        print(f"    {colorize(location, color)}  {row['detector']}  {row['summary']}")
+
+
+def _print_comparison(comparison: Comparison, scores: dict) -> None:
+    probably = sum(1 for *_, how in comparison.moved if how == PROBABLY_MOVED)
+    moved = len(comparison.moved) - probably
+    moved_note = f" ({moved} moved, {probably} probably moved)" if comparison.moved else ""
+    print(
+        f"  Introduced: {len(comparison.introduced)}  Resolved: {len(comparison.resolved)}  "
+        f"Persisting: {len(comparison.persisting)}{moved_note}"
+    )
+    for name, row in scores.items():
+        if row["delta"] is not None:
+            print(
+                f"  {name.capitalize()} score: {row['old']:.1f} → {row['new']:.1f} "
+                f"({row['delta']:+.1f})"
+            )
+    _print_rows("Introduced", comparison.introduced, "red")
+    _print_rows("Resolved", comparison.resolved, "green")
+    if probably:
+        print(colorize("\n  Probably moved (same code, different finding id)", "bold"))
+        for before, after, how in comparison.moved:
+            if how == PROBABLY_MOVED:
+                print(f"    {before.get('file')} → {after.get('file')}  {after.get('summary')}")
+
+
+def cmd_compare(args: argparse.Namespace) -> None:
+    """Compare two runs; exit 1 when more findings were introduced than allowed."""
+    try:
+        old, new = load_run(Path(args.old)), load_run(Path(args.new))
+    except (OSError, UnicodeDecodeError, ValueError) as exc:
+        print(colorize(f"  Cannot compare: {exc}", "red"), file=sys.stderr)
+        sys.exit(ERROR_EXIT_CODE)
+    comparison = compare_runs(old, new)
+    scores = score_delta(old, new)
+    if getattr(args, "json", False):
+        print(json.dumps(comparison_payload(comparison, scores), indent=2))
+    else:
+        _print_comparison(comparison, scores)
+    limit = getattr(args, "max_introduced", 0) or 0
+    introduced = len(comparison.introduced)
+    if introduced > limit:
+        if not getattr(args, "json", False):
+            print(colorize(f"\n  ✗ {introduced} new finding(s), {limit} allowed", "red"))
+        sys.exit(FINDINGS_EXIT_CODE)
+
+
+__all__ = ["cmd_compare", "comparison_payload"]
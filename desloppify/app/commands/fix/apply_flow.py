@@ -76,7 +76,12 @@ def _apply_and_report(
 ) -> None:
     state_file, state = _load_state(args)
     prev = state_mod.score_snapshot(state)
-    resolved_ids = _resolve_fixer_results(state, results, fixer.detector, fixer_name)
+    if fixer.resolve is not None:
+        resolved_ids = fixer.resolve(state, results, fixer_name)
+    else:
+        resolved_ids = _resolve_fixer_results(
+            state, results, fixer.detector, fixer_name
+        )
     _save_state(state, state_file)
 
     new = state_mod.score_snapshot(state)
@@ -160,6 +165,7 @@ def _report_dry_run(
             "dry_run": True,
             "files_would_fix": len(results),
             "items_would_fix": total_items,
+            "patch": "".join(str(r.get("diff", "")) for r in results),
             "narrative": narrative,
         }
     )
@@ -3,9 +3,13 @@
 from __future__ import annotations
 
 import argparse
+import sys
 from pathlib import Path
 
-from desloppify.app.commands._show_terminal import show_fix_dry_run_samples
+from desloppify.app.commands._show_terminal import (
+    show_fix_dry_run_diffs,
+    show_fix_dry_run_samples,
+)
 from desloppify.languages._framework.base.types import FixResult
 from desloppify.utils import colorize
 
@@ -35,6 +39,9 @@ def cmd_fix(args: argparse.Namespace) -> None:
     if not dry_run:
         _warn_uncommitted_changes()
     entries = _detect(fixer, path)
+    fix_rules = getattr(args, "fix_rule", None)
+    if fix_rules:
+        entries = _select_rules(entries, set(fix_rules))
     if not entries:
         print(colorize(f"No {fixer.label} found.", "green"))
         return
@@ -51,7 +58,10 @@ def cmd_fix(args: argparse.Namespace) -> None:
     _print_fix_summary(fixer, results, total_items, total_lines, dry_run)
 
     if dry_run and results:
-        show_fix_dry_run_samples(entries, results)
+        if all("diff" in r for r in results):
+            show_fix_dry_run_diffs(results)
+        else:
+            show_fix_dry_run_samples(entries, results)
 
     if not dry_run:
         _apply_and_report(
@@ -68,3 +78,20 @@ def cmd_fix(args: argparse.Namespace) -> None:
     else:
         _report_dry_run(args, fixer_name, entries, results, total_items)
     print()
+
+
+def _select_rules(entries: list[dict], rules: set[str]) -> list[dict]:
+    """Keep the entries detected for ``rules``; fixers without rule ids can't filter."""
+    if entries and not any("smell" in e for e in entries):
+        print(
+            colorize("  --fix-rule only applies to fixers that report rule ids.", "red"),
+            file=sys.stderr,
+        )
+        sys.exit(1)
+    unknown = rules - {e["smell"] for e in entries}
+    if unknown:
+        print(
+            colorize(f"  Nothing to fix for: {', '.join(sorted(unknown))}", "dim"),
+            file=sys.stderr,
+        )
+    return [e for e in entries if e.get("smell") in rules]
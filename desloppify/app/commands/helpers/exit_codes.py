@@ -0,0 +1,49 @@
+"""Process exit codes shared by the CLI (the README's "Exit codes" table)."""
+
+from __future__ import annotations
+
+from desloppify.core.config import SEVERITY_LEVELS
+
+# Nothing at or above the fail severity, or report-only (`scan --no-fail`).
+OK_EXIT_CODE = 0
+# Findings at or above the fail severity (`fail_severity`, `staged_fail_severity`).
+FINDINGS_EXIT_CODE = 1
+# Unparseable arguments, an unreadable or invalid config, or an internal error.
+ERROR_EXIT_CODE = 2
+# `scan --fail-on-degraded` when some unit was only partly analyzed.
+DEGRADED_EXIT_CODE = 3
+# Ctrl-C, as shells report a SIGINT.
+INTERRUPTED_EXIT_CODE = 130
+
+SEVERITY_RANK = {level: rank for rank, level in enumerate(SEVERITY_LEVELS)}
+
+
+def finding_severity(finding: dict) -> str:
+    """Smell severity when the detector reports one, else finding confidence."""
+    detail = finding.get("detail") if isinstance(finding.get("detail"), dict) else {}
+    severity = detail.get("severity") or finding.get("confidence")
+    return severity if severity in SEVERITY_RANK else "low"
+
+
+def resolve_fail_severity(config: dict | None, key: str = "fail_severity") -> str:
+    """The configured fail severity under ``key``, ``medium`` when unset or invalid."""
+    value = str((config or {}).get(key, "medium"))
+    return value if value in SEVERITY_RANK else "medium"
+
+
+def is_failing(finding: dict, severity: str) -> bool:
+    """Whether ``finding`` is at or above ``severity``."""
+    return SEVERITY_RANK[finding_severity(finding)] >= SEVERITY_RANK[severity]
+
+
+__all__ = [
+    "DEGRADED_EXIT_CODE",
+    "ERROR_EXIT_CODE",
+    "FINDINGS_EXIT_CODE",
+    "INTERRUPTED_EXIT_CODE",
+    "OK_EXIT_CODE",
+    "SEVERITY_RANK",
+    "finding_severity",
+    "is_failing",
+    "resolve_fail_severity",
+]
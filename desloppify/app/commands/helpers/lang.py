@@ -9,6 +9,7 @@ from pathlib import Path
 from typing import TYPE_CHECKING
 
 from desloppify import languages as lang_api
+from desloppify.app.commands.helpers.exit_codes import ERROR_EXIT_CODE
 from desloppify.core._internal.text_utils import PROJECT_ROOT
 
 if TYPE_CHECKING:
@@ -22,14 +23,13 @@ class LangResolutionError(SystemExit):
     """Raised when language resolution fails with a user-facing message.
 
     Inherits from SystemExit so that callers which don't catch it explicitly
-    will still terminate with a non-zero exit code, matching the previous
-    sys.exit(1) behaviour.  The CLI top-level catches it to print a clean
-    error without a traceback.
+    will still terminate with ERROR_EXIT_CODE (2).  The CLI
+    top-level catches it to print a clean error without a traceback.
     """
 
     def __init__(self, message: str) -> None:
         self.message = message
-        super().__init__(1)
+        super().__init__(ERROR_EXIT_CODE)
 
 
 EXTRA_ROOT_MARKERS = (
@@ -131,3 +131,32 @@ def resolve_lang_settings(config: dict, lang: LangConfig) -> dict[str, object]:
         return lang.normalize_settings({})
     raw = languages.get(lang.name, {})
     return lang.normalize_settings(raw if isinstance(raw, dict) else {})
+
+
+def check_lang_settings(
+    config: dict, lang: LangConfig, *, source: Path | None = None
+) -> list[str]:
+    """Problems with config.languages.<lang>: unknown keys plus the language's own checks.
+
+    Each message names the config file line. An empty list means the block is valid.
+    """
+    from desloppify.core.config import CONFIG_FILE, config_line, did_you_mean
+    from desloppify.file_discovery import rel
+
+    source = source or CONFIG_FILE
+    languages = config.get("languages", {}) if isinstance(config, dict) else {}
+    raw = languages.get(lang.name, {}) if isinstance(languages, dict) else {}
+    if not isinstance(raw, dict):
+        return []
+    problems = []
+    for key in raw:
+        if key not in lang.setting_specs:
+            line = config_line(("languages", lang.name, key), source)
+            where = rel(str(source)) + (f":{line}" if line else "")
+            hint = did_you_mean(key, lang.setting_specs) or (
+                f" (settings: {', '.join(lang.setting_specs) or 'none'})"
+            )
+            problems.append(f"{where}: languages.{lang.name}.{key}: unknown setting{hint}")
+    if lang.check_settings is not None:
+        problems += lang.check_settings(raw, source)
+    return problems
@@ -0,0 +1,79 @@
+"""install-hook command: run ``scan --staged`` from git's pre-commit hook."""
+
+from __future__ import annotations
+
+import argparse
+import shlex
+import stat
+import sys
+from pathlib import Path
+
+from desloppify.app.commands.scan.scan_changed import GitSelectionError, _run_git
+from desloppify.core._internal.text_utils import get_project_root
+from desloppify.utils import colorize
+
+HOOK_MARKER = "# installed by: desloppify install-hook"
+CHAINED_HOOK_NAME = "pre-commit.pre-desloppify"
+
+
+def hook_script(command: str) -> str:
+    """Shell script that runs any chained hook, then ``command``."""
+    return (
+        "#!/bin/sh\n"
+        f"{HOOK_MARKER}\n"
+        f'chained="$(dirname "$0")/{CHAINED_HOOK_NAME}"\n'
+        'if [ -x "$chained" ]; then\n'
+        '  "$chained" "$@" || exit $?\n'
+        "fi\n"
+        f"exec {command}\n"
+    )
+
+
+def _hooks_dir(root: Path) -> Path:
+    hooks = Path(_run_git(["rev-parse", "--git-path", "hooks"], cwd=root).strip())
+    return hooks if hooks.is_absolute() else root / hooks
+
+
+def install_hook(root: Path, command: str) -> tuple[Path, Path | None]:
+    """Write the pre-commit hook, chaining any foreign hook already there.
+
+    Returns (hook path, chained hook path or None). Re-running is idempotent.
+    """
+    hooks = _hooks_dir(root)
+    hooks.mkdir(parents=True, exist_ok=True)
+    hook = hooks / "pre-commit"
+    chained = hooks / CHAINED_HOOK_NAME
+    if hook.exists() and HOOK_MARKER not in hook.read_text(errors="replace"):
+        if chained.exists():
+            raise FileExistsError(
+                f"{hook} is not a desloppify hook and {chained.name} already exists"
+            )
+        hook.rename(chained)
+    hook.write_text(hook_script(command))
+    hook.chmod(hook.stat().st_mode | stat.S_IXUSR | stat.S_IXGRP | stat.S_IXOTH)
+    return hook, chained if chained.exists() else None
+
+
+def cmd_install_hook(args: argparse.Namespace) -> None:
+    """Install (or refresh) the git pre-commit hook."""
+    command = f"{shlex.quote(sys.executable)} -m desloppify"
+    if getattr(args, "lang", None):
+        command += f" --lang {shlex.quote(args.lang)}"
+    command += " scan --staged"
+    try:
+        hook, chained = install_hook(get_project_root(), command)
+    except (GitSelectionError, FileExistsError, OSError) as exc:
+        print(colorize(f"  Could not install hook: {exc}", "red"), file=sys.stderr)
+        sys.exit(1)
+    print(colorize(f"  Installed pre-commit hook: {hook}", "green"))
+    if chained is not None:
+        print(colorize(f"  Existing hook kept and run first: {chained}", "dim"))
+    print(
+        colorize(
+            "  Threshold: desloppify config set staged_fail_severity <low|medium|high>",
+            "dim",
+        )
+    )
+
+
+__all__ = ["cmd_install_hook", "hook_script", "install_hook"]
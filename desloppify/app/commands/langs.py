@@ -4,6 +4,7 @@ from __future__ import annotations
 
 import argparse
 import logging
+import textwrap
 
 from desloppify.languages._framework import registry_state
 from desloppify.languages._framework.base.types import LangConfig
@@ -40,6 +41,30 @@ def _get_tool_labels(cfg: LangConfig) -> str:
     return (", ".join(labels) if labels else "none") + suffix
 
 
+def _print_rules(name: str, cfg: LangConfig) -> None:
+    """Print each phase and catalogued rule with the level it needs and its categories."""
+    print(colorize(name, "bold"))
+    print(f"  {'Rule':<32}{'Needs':<9}{'Categories':<26}Severity")
+    for phase in cfg.phases:
+        print(f"  {phase.label:<32}{phase.requires:<9}{'':<26}(phase)")
+    for rule in cfg.rule_catalog() if cfg.rule_catalog else []:
+        opt_in = ", opt-in" if rule["opt_in"] else ""
+        fixable = ", fixable" if rule.get("fixable") else ""
+        custom = ", custom" if rule.get("custom") else ""
+        plugin = f", plugin {rule['plugin']}" if rule.get("plugin") else ""
+        categories = ", ".join(rule.get("categories") or ()) or "-"
+        print(
+            f"  {rule['id']:<32}{rule['requires']:<9}{categories:<26}"
+            f"{rule['severity']}{opt_in}{fixable}{custom}{plugin}"
+        )
+        if rule.get("explain"):
+            text = textwrap.fill(
+                rule["explain"], width=96, initial_indent="    ", subsequent_indent="    "
+            )
+            print(colorize(text, "dim"))
+    print()
+
+
 def cmd_langs(args: argparse.Namespace) -> None:
     """List all available languages with depth and tool info."""
     load_all()
@@ -56,6 +81,22 @@ def cmd_langs(args: argparse.Namespace) -> None:
                 logger.debug("Skipping unresolvable lang config %s: %s", name, exc)
                 continue
 
+    if getattr(args, "rules", False):
+        print()
+        only = getattr(args, "lang", None)
+        for name, cfg in configs:
+            if only is None or name == only:
+                _print_rules(name, cfg)
+        print(
+            colorize(
+                "  syntax: parse only; types: type-checks the package; "
+                "module: cross-file analysis. `scan --fast` runs syntax only; "
+                "`scan --category` runs one category.",
+                "dim",
+            )
+        )
+        return
+
     # Sort: full first, then standard, shallow, minimal; alphabetical within
     depth_order = {"full": 0, "standard": 1, "shallow": 2, "minimal": 3}
     configs.sort(key=lambda x: (depth_order.get(x[1].integration_depth, 9), x[0]))
@@ -0,0 +1 @@
+"""lsp command package."""
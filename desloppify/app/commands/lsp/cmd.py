@@ -0,0 +1,53 @@
+"""lsp command: serve findings as diagnostics over the Language Server Protocol."""
+
+from __future__ import annotations
+
+import argparse
+import contextlib
+import sys
+from pathlib import Path
+from typing import Any
+
+from desloppify.app.commands.helpers.lang import resolve_lang_settings
+from desloppify.app.commands.helpers.runtime import command_runtime
+from desloppify.app.commands.helpers.runtime_options import resolve_lang_runtime_options
+from desloppify.app.commands.lsp.server import LspServer
+from desloppify.app.commands.scan.scan_stdin import analyze_buffer, lang_for_filename
+from desloppify.languages._framework.runtime import LangRunOverrides, make_lang_run
+
+
+def make_analyzer(args: argparse.Namespace):
+    """Build the (path, text) analyzer backed by stdin-buffer substitution."""
+    config = command_runtime(args).config
+
+    def analyze(path: Path, text: str) -> tuple[str, list[dict[str, Any]]]:
+        lang_cfg = lang_for_filename(args, str(path))
+        if lang_cfg is None:
+            return "", []
+        lang_run = make_lang_run(
+            lang_cfg,
+            overrides=LangRunOverrides(
+                runtime_settings=resolve_lang_settings(config, lang_cfg),
+                runtime_options=resolve_lang_runtime_options(args, lang_cfg),
+            ),
+        )
+        # stdout carries the protocol stream; keep stray detector output off it.
+        with contextlib.redirect_stdout(sys.stderr):
+            result = analyze_buffer(path, text, lang_run)
+        return lang_cfg.name, result.findings
+
+    return analyze
+
+
+def cmd_lsp(args: argparse.Namespace) -> None:
+    """Speak LSP on stdin/stdout until the client sends ``exit``."""
+    server = LspServer(
+        sys.stdin.buffer,
+        sys.stdout.buffer,
+        make_analyzer(args),
+        debounce=max(0.0, float(getattr(args, "debounce", 0.5))),
+    )
+    sys.exit(server.serve())
+
+
+__all__ = ["cmd_lsp", "make_analyzer"]
@@ -0,0 +1,254 @@
+"""Finding → LSP diagnostic / code action conversion."""
+
+from __future__ import annotations
+
+import functools
+import re
+from typing import Any
+
+from desloppify.languages._framework.resolution import get_lang
+
+_REPO_DOCS = "https://github.com/peteromallet/desloppify/blob/main"
+# Each language's rule page and its rule table; built-in rules have an
+# anchor of their own id in that table.
+_RULE_DOCS = {
+    "go": (f"{_REPO_DOCS}/docs/go-quality-pipeline.md", "3-what-only-desloppify-covers-go"),
+}
+_DEFAULT_RULE_DOCS = f"{_REPO_DOCS}/README.md"
+
+# PositionEncodingKind: columns count UTF-16 code units unless the client
+# accepts UTF-32, which is what a Python string index counts.
+ENCODING_UTF16 = "utf-16"
+ENCODING_UTF32 = "utf-32"
+
+# LSP DiagnosticSeverity
+SEVERITY_ERROR = 1
+SEVERITY_WARNING = 2
+SEVERITY_INFORMATION = 3
+SEVERITY_HINT = 4
+
+_SMELL_SEVERITY = {
+    "high": SEVERITY_WARNING,
+    "medium": SEVERITY_INFORMATION,
+    "low": SEVERITY_HINT,
+}
+_TIER_SEVERITY = {1: SEVERITY_WARNING, 2: SEVERITY_WARNING, 3: SEVERITY_INFORMATION}
+
+_OCCURRENCES_SUFFIX_RE = re.compile(r"\s*\(\d+ occurrences? in \d+ files?\)$")
+
+# Languages whose smell detectors honor the inline suppression directive.
+_SUPPRESSION_COMMENT = {"go": "//desloppify:ignore"}
+_SUPPRESSIBLE_DETECTORS = frozenset({"smells"})
+
+
+def suppression_directive(lang: str, detector: str) -> str | None:
+    """The inline directive that silences ``detector``'s findings in ``lang``, if any."""
+    if detector not in _SUPPRESSIBLE_DETECTORS:
+        return None
+    return _SUPPRESSION_COMMENT.get(lang)
+
+
+@functools.cache
+def _documented_rules(lang: str) -> frozenset[str]:
+    """``lang``'s built-in rule ids; plugin and custom rules are not in its docs."""
+    cfg = get_lang(lang)
+    catalog = cfg.rule_catalog() if cfg.rule_catalog else []
+    return frozenset(r["id"] for r in catalog if not r.get("plugin") and not r.get("custom"))
+
+
+def rule_docs_url(lang: str, rule: str | None = None) -> str:
+    """Where ``rule`` is documented: its own row when it is built in, else the rule table."""
+    if lang not in _RULE_DOCS:
+        return _DEFAULT_RULE_DOCS
+    page, table = _RULE_DOCS[lang]
+    return f"{page}#{rule if rule in _documented_rules(lang) else table}"
+
+
+def _character(text: str, column: int, encoding: str) -> int:
+    """``column``, a string index into ``text``, counted in ``encoding``."""
+    if encoding == ENCODING_UTF16:
+        return len(text[:column].encode("utf-16-le")) // 2
+    return column
+
+
+def _severity(finding: dict[str, Any]) -> int:
+    detail = finding.get("detail") if isinstance(finding.get("detail"), dict) else {}
+    smell_severity = detail.get("severity")
+    if smell_severity in _SMELL_SEVERITY:
+        return _SMELL_SEVERITY[smell_severity]
+    return _TIER_SEVERITY.get(int(finding.get("tier", 3) or 3), SEVERITY_HINT)
+
+
+def _line_range(lines: list[str], line: int, encoding: str) -> dict[str, Any]:
+    index = max(0, line - 1)
+    text = lines[index] if index < len(lines) else ""
+    start = len(text) - len(text.lstrip())
+    return {
+        "start": {"line": index, "character": _character(text, start, encoding)},
+        "end": {"line": index, "character": _character(text, len(text), encoding)},
+    }
+
+
+def findings_to_diagnostics(
+    findings: list[dict[str, Any]],
+    text: str,
+    *,
+    lang: str,
+    encoding: str = ENCODING_UTF16,
+) -> list[dict[str, Any]]:
+    """One diagnostic per finding location; aggregated matches are expanded.
+
+    Columns are counted in ``encoding``, the position encoding agreed with
+    the client.
+    """
+    lines = text.splitlines()
+    diagnostics: list[dict[str, Any]] = []
+    for finding in findings:
+        detail = finding.get("detail") if isinstance(finding.get("detail"), dict) else {}
+        rule = str(detail.get("smell_id") or finding.get("detector", "desloppify"))
+        message = _OCCURRENCES_SUFFIX_RE.sub("", str(finding.get("summary", "")))
+        matches = detail.get("matches")
+        if isinstance(matches, list) and matches and isinstance(matches[0], dict):
+            locations = matches
+        else:
+            locations = [{"line": detail.get("line") or 1}]
+        for location in locations:
+            line = int(location.get("line") or 1)
+            text_message = message
+            if location.get("suggestion"):
+                text_message = f"{message} — try `{location['suggestion']}`"
+            data: dict[str, Any] = {
+                "findingId": finding.get("id"),
+                "rule": rule,
+                "suppressible": suppression_directive(
+                    lang, str(finding.get("detector", ""))
+                )
+                is not None,
+            }
+            if isinstance(location.get("fix"), dict):
+                data["fix"] = location["fix"]
+            diagnostics.append(
+                {
+                    "range": _line_range(lines, line, encoding),
+                    "severity": _severity(finding),
+                    "code": rule,
+                    "codeDescription": {"href": rule_docs_url(lang, rule)},
+                    "source": "desloppify",
+                    "message": text_message,
+                    "data": data,
+                }
+            )
+    return diagnostics
+
+
+def _fix_action(
+    uri: str, diagnostic: dict[str, Any], lines: list[str], encoding: str
+) -> dict | None:
+    fix = (diagnostic.get("data") or {}).get("fix")
+    if not isinstance(fix, dict):
+        return None
+    index = int(fix.get("line", 0)) - 1
+    if not 0 <= index < len(lines):
+        return None
+    old = str(fix.get("old", ""))
+    # A multi-line ``old`` starts on ``line`` and continues on the lines below.
+    text = "\n".join(lines[index:])
+    column = text.find(old)
+    if column < 0 or column >= len(lines[index]):
+        return None
+    end_line = index + old.count("\n")
+    end_character = (
+        column + len(old) if end_line == index else len(old) - old.rfind("\n") - 1
+    )
+    return {
+        "title": str(fix.get("title") or "Apply desloppify fix"),
+        "kind": "quickfix",
+        "diagnostics": [diagnostic],
+        "isPreferred": True,
+        "edit": {
+            "changes": {
+                uri: [
+                    {
+                        "range": {
+                            "start": {
+                                "line": index,
+                                "character": _character(lines[index], column, encoding),
+                            },
+                            "end": {
+                                "line": end_line,
+                                "character": _character(
+                                    lines[end_line] if end_line < len(lines) else "",
+                                    end_character,
+                                    encoding,
+                                ),
+                            },
+                        },
+                        "newText": str(fix.get("new", "")),
+                    }
+                ]
+            }
+        },
+    }
+
+
+def _ignore_action(
+    uri: str, diagnostic: dict[str, Any], lines: list[str], *, lang: str
+) -> dict | None:
+    directive = _SUPPRESSION_COMMENT.get(lang)
+    if directive is None or not (diagnostic.get("data") or {}).get("suppressible"):
+        return None
+    index = int(diagnostic["range"]["start"]["line"])
+    text = lines[index] if index < len(lines) else ""
+    indent = text[: len(text) - len(text.lstrip())]
+    rule = diagnostic.get("code", "")
+    return {
+        "title": f"Ignore this finding ({directive} {rule})",
+        "kind": "quickfix",
+        "diagnostics": [diagnostic],
+        "edit": {
+            "changes": {
+                uri: [
+                    {
+                        "range": {
+                            "start": {"line": index, "character": 0},
+                            "end": {"line": index, "character": 0},
+                        },
+                        "newText": f"{indent}{directive} {rule}\n",
+                    }
+                ]
+            }
+        },
+    }
+
+
+def code_actions(
+    uri: str,
+    diagnostics: list[dict[str, Any]],
+    text: str,
+    *,
+    lang: str,
+    encoding: str = ENCODING_UTF16,
+) -> list[dict[str, Any]]:
+    """Quick fixes for desloppify diagnostics: machine fixes, then suppression."""
+    lines = text.splitlines()
+    actions: list[dict[str, Any]] = []
+    for diagnostic in diagnostics:
+        if diagnostic.get("source") != "desloppify":
+            continue
+        for action in (
+            _fix_action(uri, diagnostic, lines, encoding),
+            _ignore_action(uri, diagnostic, lines, lang=lang),
+        ):
+            if action is not None:
+                actions.append(action)
+    return actions
+
+
+__all__ = [
+    "ENCODING_UTF16",
+    "ENCODING_UTF32",
+    "code_actions",
+    "findings_to_diagnostics",
+    "rule_docs_url",
+    "suppression_directive",
+]
@@ -0,0 +1,62 @@
+"""JSON-RPC 2.0 framing over stdio (LSP base protocol)."""
+
+from __future__ import annotations
+
+import json
+import threading
+from typing import Any, BinaryIO
+
+
+class ProtocolError(RuntimeError):
+    """Raised on malformed base-protocol framing."""
+
+
+def read_message(stream: BinaryIO) -> dict[str, Any] | None:
+    """Read one ``Content-Length``-framed message; ``None`` on clean EOF."""
+    length: int | None = None
+    while True:
+        header = stream.readline()
+        if not header:
+            return None
+        header = header.strip()
+        if not header:
+            break
+        name, _, value = header.decode("ascii", errors="replace").partition(":")
+        if name.strip().lower() == "content-length":
+            try:
+                length = int(value.strip())
+            except ValueError as exc:
+                raise ProtocolError(f"bad Content-Length: {value!r}") from exc
+    if length is None:
+        raise ProtocolError("message without Content-Length header")
+    body = stream.read(length)
+    if len(body) < length:
+        return None
+    return json.loads(body.decode("utf-8"))
+
+
+class MessageWriter:
+    """Thread-safe writer (diagnostics may be published from debounce timers)."""
+
+    def __init__(self, stream: BinaryIO) -> None:
+        self._stream = stream
+        self._lock = threading.Lock()
+
+    def send(self, payload: dict[str, Any]) -> None:
+        body = json.dumps({"jsonrpc": "2.0", **payload}, default=str).encode("utf-8")
+        with self._lock:
+            self._stream.write(f"Content-Length: {len(body)}\r\n\r\n".encode("ascii"))
+            self._stream.write(body)
+            self._stream.flush()
+
+    def respond(self, request_id: Any, result: Any) -> None:
+        self.send({"id": request_id, "result": result})
+
+    def error(self, request_id: Any, code: int, message: str) -> None:
+        self.send({"id": request_id, "error": {"code": code, "message": message}})
+
+    def notify(self, method: str, params: Any) -> None:
+        self.send({"method": method, "params": params})
+
+
+__all__ = ["MessageWriter", "ProtocolError", "read_message"]
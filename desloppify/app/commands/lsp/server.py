@@ -0,0 +1,213 @@
+"""Minimal LSP server: full-document sync, diagnostics, quick-fix code actions."""
+
+from __future__ import annotations
+
+import logging
+import threading
+from collections.abc import Callable
+from pathlib import Path
+from typing import Any, BinaryIO
+from urllib.parse import unquote, urlparse
+from urllib.request import url2pathname
+
+from desloppify.app.commands.lsp.diagnostics import (
+    ENCODING_UTF16,
+    ENCODING_UTF32,
+    code_actions,
+    findings_to_diagnostics,
+)
+from desloppify.app.commands.lsp.protocol import MessageWriter, read_message
+
+logger = logging.getLogger(__name__)
+
+# JSON-RPC error codes
+_METHOD_NOT_FOUND = -32601
+_INTERNAL_ERROR = -32603
+
+# TextDocumentSyncKind.Full
+_SYNC_FULL = 1
+
+Analyzer = Callable[[Path, str], tuple[str, list[dict[str, Any]]]]
+"""(path, text) -> (lang name, findings for that file)."""
+
+
+def uri_to_path(uri: str) -> Path:
+    parsed = urlparse(uri)
+    return Path(url2pathname(unquote(parsed.path)))
+
+
+class LspServer:
+    """Serve one client over a reader/writer pair until ``exit``."""
+
+    def __init__(
+        self,
+        reader: BinaryIO,
+        writer: BinaryIO,
+        analyze: Analyzer,
+        *,
+        debounce: float = 0.5,
+        timer_factory: Callable[..., Any] = threading.Timer,
+    ) -> None:
+        self._reader = reader
+        self._out = MessageWriter(writer)
+        self._analyze = analyze
+        self._debounce = debounce
+        self._timer_factory = timer_factory
+        self._documents: dict[str, str] = {}
+        self._langs: dict[str, str] = {}
+        self._encoding = ENCODING_UTF16
+        self._timers: dict[str, Any] = {}
+        self._lock = threading.Lock()
+        self._shutdown = False
+
+    # ── lifecycle ──────────────────────────────────────────
+
+    def serve(self) -> int:
+        """Process messages; returns the process exit code."""
+        while True:
+            message = read_message(self._reader)
+            if message is None:
+                return 0 if self._shutdown else 1
+            if message.get("method") == "exit":
+                self._cancel_timers()
+                return 0 if self._shutdown else 1
+            self.handle(message)
+
+    def handle(self, message: dict[str, Any]) -> None:
+        method = message.get("method")
+        request_id = message.get("id")
+        handler = self._HANDLERS.get(method or "")
+        if handler is None:
+            if request_id is not None:
+                self._out.error(request_id, _METHOD_NOT_FOUND, f"unsupported: {method}")
+            return
+        try:
+            result = handler(self, message.get("params") or {})
+        except Exception as exc:  # pragma: no cover - defensive: keep serving
+            logger.exception("lsp handler %s failed", method)
+            if request_id is not None:
+                self._out.error(request_id, _INTERNAL_ERROR, str(exc))
+            return
+        if request_id is not None:
+            self._out.respond(request_id, result)
+
+    def _initialize(self, params: dict[str, Any]) -> dict[str, Any]:
+        general = (params.get("capabilities") or {}).get("general") or {}
+        if ENCODING_UTF32 in (general.get("positionEncodings") or ()):
+            self._encoding = ENCODING_UTF32
+        return {
+            "capabilities": {
+                "positionEncoding": self._encoding,
+                "textDocumentSync": {
+                    "openClose": True,
+                    "change": _SYNC_FULL,
+                    "save": {"includeText": True},
+                },
+                "codeActionProvider": {"codeActionKinds": ["quickfix"]},
+            },
+            "serverInfo": {"name": "desloppify"},
+        }
+
+    def _initialized(self, _params: dict[str, Any]) -> None:
+        return None
+
+    def _shutdown_request(self, _params: dict[str, Any]) -> None:
+        self._shutdown = True
+        self._cancel_timers()
+        return None
+
+    # ── document sync ──────────────────────────────────────
+
+    def _did_open(self, params: dict[str, Any]) -> None:
+        doc = params["textDocument"]
+        self._documents[doc["uri"]] = doc.get("text", "")
+        self.publish(doc["uri"])
+
+    def _did_change(self, params: dict[str, Any]) -> None:
+        uri = params["textDocument"]["uri"]
+        changes = params.get("contentChanges") or []
+        if changes:
+            # Full sync: the last change carries the whole document.
+            self._documents[uri] = changes[-1].get("text", "")
+        self._schedule(uri)
+
+    def _did_save(self, params: dict[str, Any]) -> None:
+        uri = params["textDocument"]["uri"]
+        if "text" in params:
+            self._documents[uri] = params["text"]
+        self._cancel_timer(uri)
+        self.publish(uri)
+
+    def _did_close(self, params: dict[str, Any]) -> None:
+        uri = params["textDocument"]["uri"]
+        self._cancel_timer(uri)
+        self._documents.pop(uri, None)
+        self._langs.pop(uri, None)
+        self._out.notify(
+            "textDocument/publishDiagnostics", {"uri": uri, "diagnostics": []}
+        )
+
+    # ── analysis ───────────────────────────────────────────
+
+    def _schedule(self, uri: str) -> None:
+        self._cancel_timer(uri)
+        timer = self._timer_factory(self._debounce, self.publish, args=(uri,))
+        timer.daemon = True
+        with self._lock:
+            self._timers[uri] = timer
+        timer.start()
+
+    def _cancel_timer(self, uri: str) -> None:
+        with self._lock:
+            timer = self._timers.pop(uri, None)
+        if timer is not None:
+            timer.cancel()
+
+    def _cancel_timers(self) -> None:
+        for uri in list(self._timers):
+            self._cancel_timer(uri)
+
+    def publish(self, uri: str) -> None:
+        """Analyze the current buffer for ``uri`` and publish its diagnostics."""
+        text = self._documents.get(uri)
+        if text is None:
+            return
+        try:
+            lang, findings = self._analyze(uri_to_path(uri), text)
+        except Exception:  # pragma: no cover - analyzer failures must not kill the server
+            logger.exception("desloppify analysis failed for %s", uri)
+            return
+        self._langs[uri] = lang
+        self._out.notify(
+            "textDocument/publishDiagnostics",
+            {
+                "uri": uri,
+                "diagnostics": findings_to_diagnostics(
+                    findings, text, lang=lang, encoding=self._encoding
+                ),
+            },
+        )
+
+    def _code_action(self, params: dict[str, Any]) -> list[dict[str, Any]]:
+        uri = params["textDocument"]["uri"]
+        text = self._documents.get(uri)
+        if text is None:
+            return []
+        diagnostics = (params.get("context") or {}).get("diagnostics") or []
+        return code_actions(
+            uri, diagnostics, text, lang=self._langs.get(uri, ""), encoding=self._encoding
+        )
+
+    _HANDLERS: dict[str, Callable[[LspServer, dict[str, Any]], Any]] = {
+        "initialize": _initialize,
+        "initialized": _initialized,
+        "shutdown": _shutdown_request,
+        "textDocument/didOpen": _did_open,
+        "textDocument/didChange": _did_change,
+        "textDocument/didSave": _did_save,
+        "textDocument/didClose": _did_close,
+        "textDocument/codeAction": _code_action,
+    }
+
+
+__all__ = ["Analyzer", "LspServer", "uri_to_path"]
@@ -4,6 +4,7 @@ from __future__ import annotations
 
 import argparse
 
+from desloppify import state as state_mod
 from desloppify.app.commands.helpers.rendering import print_agent_plan
 from desloppify.app.commands.helpers.runtime import command_runtime
 from desloppify.app.commands.helpers.state import require_completed_scan
@@ -19,6 +20,9 @@ def cmd_plan_output(args: argparse.Namespace) -> None:
 
     if not require_completed_scan(state):
         return
+    owner = getattr(args, "owner", None)
+    if owner:
+        state = state_mod.owner_scoped_state(state, owner)
 
     plan_md = plan_mod.generate_plan_md(state)
     next_command = "desloppify next --count 20"
@@ -12,12 +12,16 @@ _COMMAND_HANDLERS: dict[str, CommandHandler] | None = None
 
 def _build_handlers() -> dict[str, CommandHandler]:
     """Import all command modules and build the handler dict on first access."""
+    from desloppify.app.commands.cache_cmd import cmd_cache
+    from desloppify.app.commands.compare_cmd import cmd_compare
     from desloppify.app.commands.config_cmd import cmd_config
     from desloppify.app.commands.detect import cmd_detect
     from desloppify.app.commands.dev_cmd import cmd_dev
     from desloppify.app.commands.fix.cmd import cmd_fix
+    from desloppify.app.commands.install_hook import cmd_install_hook
     from desloppify.app.commands.issues_cmd import cmd_issues
     from desloppify.app.commands.langs import cmd_langs
+    from desloppify.app.commands.lsp.cmd import cmd_lsp
     from desloppify.app.commands.move.move import cmd_move
     from desloppify.app.commands.next import cmd_next
     from desloppify.app.commands.plan_cmd import cmd_plan_output
@@ -26,17 +30,22 @@ def _build_handlers() -> dict[str, CommandHandler]:
     from desloppify.app.commands.scan.scan import cmd_scan
     from desloppify.app.commands.show.cmd import cmd_show
     from desloppify.app.commands.status_cmd import cmd_status
+    from desloppify.app.commands.triage.cmd import cmd_triage
     from desloppify.app.commands.update_skill import cmd_update_skill
+    from desloppify.app.commands.vettool_cmd import cmd_vettool
     from desloppify.app.commands.viz_cmd import cmd_tree, cmd_viz
+    from desloppify.app.commands.watch.cmd import cmd_watch
     from desloppify.app.commands.zone_cmd import cmd_zone
 
     return {
         "scan": cmd_scan,
+        "watch": cmd_watch,
         "status": cmd_status,
         "show": cmd_show,
         "next": cmd_next,
         "resolve": cmd_resolve,
         "ignore": cmd_ignore_pattern,
+        "triage": cmd_triage,
         "fix": cmd_fix,
         "plan": cmd_plan_output,
         "detect": cmd_detect,
@@ -49,7 +58,12 @@ def _build_handlers() -> dict[str, CommandHandler]:
         "config": cmd_config,
         "dev": cmd_dev,
         "langs": cmd_langs,
+        "lsp": cmd_lsp,
+        "install-hook": cmd_install_hook,
+        "cache": cmd_cache,
+        "compare": cmd_compare,
         "update-skill": cmd_update_skill,
+        "vettool": cmd_vettool,
     }
 
 
@@ -3,13 +3,26 @@
 from __future__ import annotations
 
 import argparse
+import sys
 
+from desloppify.app.commands.helpers.exit_codes import (
+    FINDINGS_EXIT_CODE,
+    is_failing,
+    resolve_fail_severity,
+)
 from desloppify.app.commands.helpers.query import QUERY_FILE
 from desloppify.app.commands.helpers.score import target_strict_score_from_config
 from desloppify.app.commands.scan.scan_artifacts import (
     build_scan_query_payload,
     emit_scorecard_badge,
 )
+from desloppify.app.commands.scan.scan_contracts import ScanOutcome
+from desloppify.app.commands.scan.scan_coverage import (
+    DEGRADED_EXIT_CODE,
+    diagnostics_payload,
+    show_degraded_units,
+    show_finding_overlap,
+)
 from desloppify.app.commands.scan.scan_helpers import (  # noqa: F401 (re-exports)
     _audit_excluded_dirs,
     _collect_codebase_metrics,
@@ -41,6 +54,9 @@ from desloppify.app.commands.scan.scan_reporting_summary import (  # noqa: F401
     show_strict_target_progress,
 )
 from desloppify.app.commands.scan.scan_orchestrator import ScanOrchestrator
+from desloppify.app.commands.scan.scan_staged import cmd_scan_staged
+from desloppify.app.commands.scan.scan_stdin import cmd_scan_stdin
+from desloppify.app.commands.scan.scan_stream import cmd_scan_stream
 from desloppify.app.commands.scan.scan_workflow import (
     merge_scan_results,
     persist_reminder_history,
@@ -48,6 +64,8 @@ from desloppify.app.commands.scan.scan_workflow import (
     resolve_noise_snapshot,
     run_scan_generation,
 )
+from desloppify.core.diagnostics import RunDiagnostics, diagnostics_scope
+from desloppify.core.progress import progress_scope
 from desloppify.core.query import write_query
 from desloppify.utils import colorize
 
@@ -112,6 +130,77 @@ def _show_coverage_preflight(runtime) -> None:
 
 def cmd_scan(args: argparse.Namespace) -> None:
     """Run all detectors, update persistent state, show diff."""
+    with progress_scope(enabled=_wants_progress(args)), diagnostics_scope(
+        cpuprofile=getattr(args, "cpuprofile", None),
+        memprofile=getattr(args, "memprofile", None),
+        trace=getattr(args, "trace", None),
+        timings=_wants_timings(args),
+    ) as diagnostics:
+        outcome = _run_scan(args)
+    if diagnostics is not None:
+        _print_diagnostics(diagnostics)
+    if getattr(args, "no_fail", False):
+        return
+    if outcome.failing:
+        sys.exit(FINDINGS_EXIT_CODE)
+    if outcome.degraded and getattr(args, "fail_on_degraded", False):
+        sys.exit(DEGRADED_EXIT_CODE)
+
+
+def _wants_timings(args: argparse.Namespace) -> bool:
+    """``--timings``, or ``--verbose`` outside ``--stdin`` (which has its own)."""
+    if getattr(args, "timings", False):
+        return True
+    return bool(getattr(args, "verbose", False) and not getattr(args, "stdin", False))
+
+
+def _wants_progress(args: argparse.Namespace) -> bool:
+    """A live progress line when stderr is a terminal, unless ``--quiet`` or ``--stdin``."""
+    if getattr(args, "quiet", False) or getattr(args, "stdin", False):
+        return False
+    return sys.stderr.isatty()
+
+
+def _print_diagnostics(diagnostics: RunDiagnostics) -> None:
+    """Report timings and written profile paths on stderr, clear of JSON stdout."""
+    if diagnostics.timings is not None:
+        print(diagnostics.timings.render(), file=sys.stderr)
+    for output in diagnostics.outputs:
+        print(colorize(f"  {output}", "dim"), file=sys.stderr)
+
+
+def _count_failing(findings: list[dict], state: dict, severity: str) -> int:
+    """Findings of this run still open in state (not ignored) at or above ``severity``."""
+    stored = state.get("findings", {}) if isinstance(state, dict) else {}
+    count = 0
+    for finding in findings:
+        current = stored.get(finding.get("id")) or finding
+        if current.get("status", "open") != "open" or current.get("suppressed"):
+            continue
+        count += is_failing(finding, severity)
+    return count
+
+
+def _show_fail_verdict(failing: int, severity: str) -> None:
+    if failing:
+        print(colorize(f"  ✗ {failing} open finding(s) at or above {severity}", "red"))
+    else:
+        print(colorize(f"  ✓ No open findings at or above {severity}", "green"))
+
+
+def _run_scan(args: argparse.Namespace) -> ScanOutcome:
+    """Run the requested scan mode; returns what decides the exit code.
+
+    ``--stdin`` always reports success; ``--staged`` exits on its own findings.
+    """
+    if getattr(args, "stdin", False):
+        cmd_scan_stdin(args)
+        return ScanOutcome()
+    if getattr(args, "staged", False):
+        cmd_scan_staged(args)
+        return ScanOutcome()
+    if getattr(args, "stream", False):
+        return cmd_scan_stream(args)
     runtime = prepare_scan_runtime(args)
     orchestrator = ScanOrchestrator(
         runtime,
@@ -143,6 +232,9 @@ def cmd_scan(args: argparse.Namespace) -> None:
     findings, potentials, codebase_metrics = orchestrator.generate()
     merge = orchestrator.merge(findings, potentials, codebase_metrics)
     _print_scan_complete_banner()
+    show_degraded_units(runtime.lang)
+    if getattr(args, "verbose", False):
+        show_finding_overlap(runtime.lang)
 
     noise = orchestrator.noise_snapshot()
 
@@ -182,23 +274,31 @@ def cmd_scan(args: argparse.Namespace) -> None:
     )
     orchestrator.persist_reminders(narrative)
 
-    write_query(
-        build_scan_query_payload(
-            runtime.state,
-            runtime.config,
-            runtime.profile,
-            merge.diff,
-            warnings,
-            narrative,
-            merge,
-            noise,
-        ),
-        query_file=QUERY_FILE,
+    payload = build_scan_query_payload(
+        runtime.state,
+        runtime.config,
+        runtime.profile,
+        merge.diff,
+        warnings,
+        narrative,
+        merge,
+        noise,
     )
+    diagnostics = diagnostics_payload(runtime.lang)
+    if diagnostics is not None:
+        payload["diagnostics"] = diagnostics
+    write_query(payload, query_file=QUERY_FILE)
 
     badge_path = emit_scorecard_badge(args, runtime.config, runtime.state)
     _print_llm_summary(runtime.state, badge_path, narrative, merge.diff)
     auto_update_skill()
+    severity = resolve_fail_severity(runtime.config)
+    failing = _count_failing(findings, runtime.state, severity)
+    _show_fail_verdict(failing, severity)
+    return ScanOutcome(
+        failing=failing,
+        degraded=len(getattr(runtime.lang, "degraded_units", None) or []),
+    )
 
 
 __all__ = [
@@ -0,0 +1,277 @@
+"""Changed-files input selection for scan (--changed / --since / --diff-base)."""
+
+from __future__ import annotations
+
+import logging
+import posixpath
+import re
+import subprocess
+import sys
+from pathlib import Path
+from typing import Any
+
+from desloppify.core._internal.text_utils import get_project_root
+from desloppify.core.file_paths import normalize_path_separators, safe_relpath
+from desloppify.file_discovery import is_reported
+from desloppify.languages._framework.runtime import LangRun
+from desloppify.utils import colorize
+
+logger = logging.getLogger(__name__)
+
+_GIT_TIMEOUT_SECONDS = 15
+# "@@ -a,b +c,d @@": the new side starts at line c and spans d lines (default 1).
+_HUNK_RE = re.compile(r"^@@ -\d+(?:,\d+)? \+(\d+)(?:,(\d+))? @@")
+
+
+class GitSelectionError(RuntimeError):
+    """Raised when git cannot provide the requested change set."""
+
+
+def _run_git(args: list[str], cwd: Path) -> str:
+    try:
+        result = subprocess.run(
+            ["git", *args],
+            cwd=str(cwd),
+            capture_output=True,
+            text=True,
+            timeout=_GIT_TIMEOUT_SECONDS,
+        )
+    except (OSError, subprocess.TimeoutExpired) as exc:
+        raise GitSelectionError(f"git {args[0]} failed: {exc}") from exc
+    if result.returncode != 0:
+        detail = result.stderr.strip() or f"exit code {result.returncode}"
+        raise GitSelectionError(f"git {args[0]} failed: {detail}")
+    return result.stdout
+
+
+def _split_nul(output: str) -> list[str]:
+    return [entry for entry in output.split("\0") if entry]
+
+
+def _parse_porcelain_z(output: str) -> list[str]:
+    """Extract paths from ``git status --porcelain -z`` output.
+
+    Rename/copy entries carry a second (source) path which is included too —
+    the package the file moved out of changed as well.
+    """
+    entries = _split_nul(output)
+    paths: list[str] = []
+    idx = 0
+    while idx < len(entries):
+        entry = entries[idx]
+        status, path = entry[:2], entry[3:]
+        paths.append(path)
+        idx += 1
+        if status[0] in {"R", "C"} and idx < len(entries):
+            paths.append(entries[idx])
+            idx += 1
+    return paths
+
+
+def git_worktree_changes(root: Path) -> list[str]:
+    """Modified, staged, and untracked files (git-toplevel relative)."""
+    output = _run_git(
+        ["status", "--porcelain", "-z", "--untracked-files=all"], cwd=root
+    )
+    return _parse_porcelain_z(output)
+
+
+def git_range_changes(root: Path, rev: str) -> list[str]:
+    """Files touched between ``rev`` and HEAD (git-toplevel relative)."""
+    output = _run_git(["diff", "--name-only", "-z", rev, "HEAD", "--"], cwd=root)
+    return _split_nul(output)
+
+
+def parse_changed_lines(output: str) -> dict[str, frozenset[int]]:
+    """Added or modified new-side lines per file from ``git diff -U0 --no-prefix``.
+
+    Deleted files are left out; a file with only removed lines maps to an
+    empty set.
+    """
+    lines: dict[str, set[int]] = {}
+    current: set[int] | None = None
+    for row in output.splitlines():
+        if row.startswith("+++ "):
+            path = row[4:].rstrip("\t")
+            current = None if path == "/dev/null" else lines.setdefault(path, set())
+            continue
+        match = _HUNK_RE.match(row)
+        if match and current is not None:
+            start = int(match.group(1))
+            count = int(match.group(2)) if match.group(2) is not None else 1
+            current.update(range(start, start + count))
+    return {path: frozenset(numbers) for path, numbers in lines.items()}
+
+
+def git_diff_base_lines(root: Path, rev: str) -> dict[str, frozenset[int] | None]:
+    """Lines changed in the working tree since ``rev`` (git-toplevel relative).
+
+    Untracked files map to ``None``: every line of them is new.
+    """
+    output = _run_git(
+        ["diff", "-U0", "--no-color", "--no-ext-diff", "--no-prefix", rev, "--"], cwd=root
+    )
+    changed: dict[str, frozenset[int] | None] = dict(parse_changed_lines(output))
+    untracked = _run_git(["ls-files", "-z", "--others", "--exclude-standard"], cwd=root)
+    for path in _split_nul(untracked):
+        changed[path] = None
+    return changed
+
+
+def _toplevel(root: Path) -> Path:
+    return Path(_run_git(["rev-parse", "--show-toplevel"], cwd=root).strip())
+
+
+def _project_path(git_path: str, *, toplevel: Path, project_root: Path) -> str | None:
+    """``git_path`` relative to the project root, or None when outside it."""
+    absolute = (toplevel / git_path).resolve()
+    rel_path = normalize_path_separators(safe_relpath(absolute, project_root))
+    if rel_path.startswith("../") or rel_path == "..":
+        return None
+    return rel_path
+
+
+def changed_dirs(
+    git_paths: list[str],
+    *,
+    toplevel: Path,
+    project_root: Path,
+) -> list[str]:
+    """Map git paths to project-root-relative parent directories."""
+    dirs: set[str] = set()
+    for git_path in git_paths:
+        rel_path = _project_path(git_path, toplevel=toplevel, project_root=project_root)
+        if rel_path is not None:
+            dirs.add(posixpath.dirname(rel_path) or ".")
+    return sorted(dirs)
+
+
+def _warn_unavailable(exc: GitSelectionError) -> None:
+    logger.debug("changed-files selection unavailable: %s", exc)
+    print(
+        colorize(
+            f"  ⚠ Changed-files selection unavailable ({exc}) — running a full scan.",
+            "yellow",
+        ),
+        file=sys.stderr,
+    )
+
+
+def resolve_changed_selection(args) -> tuple[str, ...] | None:
+    """Resolve --changed/--since into a directory selection.
+
+    Returns ``None`` when no selection was requested or when git is unavailable
+    (in which case a warning is printed and the scan falls back to a full run).
+    The selection decides which packages detectors run on (see
+    ``set_report_dirs``), not file discovery: type and module context still
+    cover every package. ``with_dependents`` adds the packages that import
+    the selected ones.
+    """
+    want_worktree = bool(getattr(args, "changed", False))
+    since = getattr(args, "since", None)
+    if not want_worktree and not since:
+        return None
+
+    project_root = get_project_root()
+    try:
+        toplevel = _toplevel(project_root)
+        git_paths: list[str] = []
+        if want_worktree:
+            git_paths.extend(git_worktree_changes(project_root))
+        if since:
+            git_paths.extend(git_range_changes(project_root, since))
+    except GitSelectionError as exc:
+        _warn_unavailable(exc)
+        return None
+
+    dirs = changed_dirs(git_paths, toplevel=toplevel, project_root=project_root)
+    label = "--changed" if want_worktree else f"--since {since}"
+    if want_worktree and since:
+        label = f"--changed + --since {since}"
+    print(
+        colorize(
+            f"  Changed-files selection ({label}): {len(dirs)} director"
+            f"{'y' if len(dirs) == 1 else 'ies'}",
+            "dim",
+        ),
+        file=sys.stderr,
+    )
+    return tuple(dirs)
+
+
+def resolve_diff_base(args) -> dict[str, frozenset[int] | None] | None:
+    """Resolve --diff-base REV into the changed lines of each project file.
+
+    Returns ``None`` when no base was given or git cannot diff against it
+    (a warning is printed and every line is reported). Files map to their
+    changed line numbers, or ``None`` when the whole file is new.
+    """
+    rev = getattr(args, "diff_base", None)
+    if not rev:
+        return None
+
+    project_root = get_project_root()
+    try:
+        toplevel = _toplevel(project_root)
+        git_lines = git_diff_base_lines(project_root, rev)
+    except GitSelectionError as exc:
+        _warn_unavailable(exc)
+        return None
+
+    changed: dict[str, frozenset[int] | None] = {}
+    for git_path, lines in git_lines.items():
+        rel_path = _project_path(git_path, toplevel=toplevel, project_root=project_root)
+        if rel_path is not None and (lines is None or lines):
+            changed[rel_path] = lines
+    print(
+        colorize(
+            f"  Diff-base selection (--diff-base {rev}): {len(changed)} "
+            f"file{'' if len(changed) == 1 else 's'} with changed lines",
+            "dim",
+        ),
+        file=sys.stderr,
+    )
+    return changed
+
+
+def with_dependents(dirs: tuple[str, ...], lang: LangRun | None, path: Path) -> tuple[str, ...]:
+    """``dirs`` plus the packages whose analysis depends on them.
+
+    A package that imports a changed one can gain or lose findings through
+    its exported API, so it is analyzed too. Languages without a
+    ``dependent_dirs`` hook only get ``dirs``.
+    """
+    resolver = getattr(lang, "dependent_dirs", None) if lang is not None else None
+    if resolver is None or not dirs:
+        return dirs
+    extra = sorted(set(resolver(list(dirs), path)) - set(dirs))
+    if extra:
+        print(
+            colorize(
+                f"  Plus {len(extra)} dependent director{'y' if len(extra) == 1 else 'ies'}",
+                "dim",
+            ),
+            file=sys.stderr,
+        )
+    return tuple(sorted({*dirs, *extra}))
+
+
+def finding_reported(finding: dict[str, Any]) -> bool:
+    """Whether ``finding`` is inside the changed-files report scope."""
+    detail = finding.get("detail") if isinstance(finding.get("detail"), dict) else {}
+    line = detail.get("line")
+    return is_reported(str(finding.get("file", "")), line if isinstance(line, int) else None)
+
+
+__all__ = [
+    "GitSelectionError",
+    "changed_dirs",
+    "finding_reported",
+    "git_diff_base_lines",
+    "git_range_changes",
+    "git_worktree_changes",
+    "parse_changed_lines",
+    "resolve_changed_selection",
+    "resolve_diff_base",
+    "with_dependents",
+]
@@ -2,6 +2,7 @@
 
 from __future__ import annotations
 
+from dataclasses import dataclass
 from typing import Any, TypedDict
 
 from desloppify.languages._framework.base.types import (
@@ -47,10 +48,20 @@ class ScanQueryPayload(TypedDict, total=False):
     zone_distribution: dict[str, int] | None
     narrative: dict[str, object]
     config: dict[str, Any]
+    diagnostics: dict[str, Any]
+
+
+@dataclass(frozen=True)
+class ScanOutcome:
+    """What a scan mode hands back to decide the exit code."""
+
+    failing: int = 0  # findings at or above the fail severity
+    degraded: int = 0  # units only partly analyzed
 
 
 __all__ = [
     "DetectorCoverageRecord",
     "ScanCoverageRecord",
+    "ScanOutcome",
     "ScanQueryPayload",
 ]
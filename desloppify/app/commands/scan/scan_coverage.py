@@ -5,9 +5,11 @@ from __future__ import annotations
 from typing import Any
 
 from desloppify import state as state_mod
+from desloppify.app.commands.helpers.exit_codes import DEGRADED_EXIT_CODE
+from desloppify.core.diagnostics import current_diagnostics
 from desloppify.languages._framework.base.types import DetectorCoverageRecord
 from desloppify.languages._framework.runtime import LangRun
-
+from desloppify.utils import colorize
 
 def coerce_int(value: object, *, default: int) -> int:
     """Best-effort int coercion for config values."""
@@ -144,14 +146,67 @@ def persist_scan_coverage(
         "confidence": round(max(0.0, min(1.0, confidence)), 2),
         "detectors": detectors,
         "warnings": warnings,
+        "degraded": [dict(record) for record in lang.degraded_units],
         "updated_at": state_mod.utc_now(),
     }
 
 
+def diagnostics_payload(lang: LangRun | None) -> dict[str, Any] | None:
+    """The ``diagnostics`` section of JSON scan output, or None when empty."""
+    payload: dict[str, Any] = {}
+    run = current_diagnostics()
+    if run is not None and run.timings is not None:
+        payload["timings"] = run.timings.as_dict()
+    degraded = getattr(lang, "degraded_units", None) or []
+    if degraded:
+        payload["degraded"] = [dict(record) for record in degraded]
+    overlap = getattr(lang, "finding_overlap", None) or {}
+    if overlap.get("before", 0) != overlap.get("after", 0):
+        payload["overlap"] = dict(overlap)
+    return payload or None
+
+
+def show_finding_overlap(lang: LangRun | None) -> None:
+    """Print the pre-merge finding count next to what merging removed."""
+    overlap = getattr(lang, "finding_overlap", None) or {}
+    if not overlap:
+        return
+    print(
+        colorize(
+            f"  * Findings: {overlap['after']} reported, {overlap['before']} before merging "
+            f"({overlap['duplicates']} duplicate(s) collapsed, "
+            f"{overlap['subsumed']} folded into a more specific rule)",
+            "dim",
+        )
+    )
+
+
+def show_degraded_units(lang: LangRun | None) -> None:
+    """Print the files and packages that were analyzed with fewer rules."""
+    units = getattr(lang, "degraded_units", None) or []
+    if not units:
+        return
+    print(
+        colorize(
+            f"  * Partial analysis: {len(units)} unit(s) did not build; "
+            "the rules they could still run did",
+            "yellow",
+        )
+    )
+    for record in units:
+        skipped = "parse-level rules" if record["skipped"] == "syntax" else "type-level rules"
+        print(colorize(f"    {record['unit']} ({record['phase']}, skipped {skipped})", "dim"))
+        print(colorize(f"      {record['error']}", "dim"))
+
+
 __all__ = [
+    "DEGRADED_EXIT_CODE",
     "coerce_float",
     "coerce_int",
+    "diagnostics_payload",
     "normalize_coverage_warning",
     "persist_scan_coverage",
     "seed_runtime_coverage_warnings",
+    "show_degraded_units",
+    "show_finding_overlap",
 ]
@@ -0,0 +1,189 @@
+"""Scan input selection (``scan ./...``, ``--tags``, ``--module``, ``--include-vendor``,
+``--category``, ``--only``, ``--skip``)."""
+
+from __future__ import annotations
+
+import re
+import sys
+from pathlib import Path
+from typing import TYPE_CHECKING
+
+from desloppify.file_discovery import rel, set_build_tags, set_path_globs, set_vendored_paths
+from desloppify.utils import colorize
+
+if TYPE_CHECKING:
+    from desloppify.languages._framework.runtime import LangRun
+
+
+def apply_build_tags(args, lang: LangRun | None) -> None:
+    """Carry ``--tags`` into the ``build_tags`` runtime option and file discovery."""
+    tags = getattr(args, "tags", None)
+    if lang is None:
+        return
+    if "build_tags" not in (lang.runtime_option_specs or {}):
+        if tags:
+            print(
+                colorize(f"  --tags has no effect for {lang.name}; ignoring it.", "yellow"),
+                file=sys.stderr,
+            )
+        return
+    if tags:
+        lang.state.runtime_options["build_tags"] = tags
+    value = str(lang.runtime_option("build_tags") or "")
+    set_build_tags([tag for tag in re.split(r"[,\s]+", value) if tag])
+
+
+def apply_vendoring(args, lang: LangRun | None) -> None:
+    """Carry ``third_party_paths`` and ``--include-vendor`` into file discovery."""
+    include = bool(getattr(args, "include_vendor", False))
+    if lang is None:
+        return
+    if "include_vendor" not in (lang.runtime_option_specs or {}):
+        if include:
+            print(
+                colorize(
+                    f"  --include-vendor has no effect for {lang.name}; ignoring it.", "yellow"
+                ),
+                file=sys.stderr,
+            )
+        return
+    if include:
+        lang.state.runtime_options["include_vendor"] = True
+    set_vendored_paths(
+        [str(p) for p in lang.runtime_setting("third_party_paths", []) or []],
+        include=bool(lang.runtime_option("include_vendor")),
+    )
+
+
+def _globs(values: list[str] | None) -> list[str]:
+    return [glob for value in values or [] for glob in value.split(",") if glob.strip()]
+
+
+def apply_path_globs(args) -> None:
+    """Carry ``--only`` and ``--skip`` into file discovery, relative to the scan path."""
+    set_path_globs(
+        [g.strip() for g in _globs(getattr(args, "only", None))],
+        [g.strip() for g in _globs(getattr(args, "skip", None))],
+        root=rel(str(getattr(args, "path", None) or ".")),
+    )
+
+
+def _category_names(values: list[str] | None) -> list[str]:
+    return [name for value in values or [] for name in re.split(r"[,\s]+", value) if name]
+
+
+def apply_rule_categories(args, lang: LangRun | None) -> None:
+    """Carry ``--category`` and ``--exclude-category`` into the language's settings.
+
+    ``--category`` replaces the configured ``categories``; ``--exclude-category``
+    adds to ``exclude_categories``. Exits with status 2 on a name the
+    language does not know.
+    """
+    chosen = _category_names(getattr(args, "category", None))
+    excluded = _category_names(getattr(args, "exclude_category", None))
+    if lang is None or not (chosen or excluded):
+        return
+    known = tuple(getattr(lang, "rule_categories", ()) or ())
+    if not known:
+        print(
+            colorize(
+                f"  --category and --exclude-category have no effect for {lang.name}; "
+                "ignoring them.",
+                "yellow",
+            ),
+            file=sys.stderr,
+        )
+        return
+    unknown = [name for name in chosen + excluded if name not in known]
+    if unknown:
+        print(
+            colorize(
+                f"  Unknown rule categor{'y' if len(unknown) == 1 else 'ies'}: "
+                f"{', '.join(unknown)} (categories: {', '.join(known)})",
+                "red",
+            ),
+            file=sys.stderr,
+        )
+        sys.exit(2)
+    if chosen:
+        lang.state.runtime_settings["categories"] = chosen
+    if excluded:
+        configured = list(lang.runtime_setting("exclude_categories", []) or [])
+        lang.state.runtime_settings["exclude_categories"] = configured + excluded
+
+
+def resolve_pattern_selection(
+    args, lang: LangRun | None
+) -> tuple[tuple[str, ...], tuple[str, ...]] | None:
+    """Resolve ``scan PATTERN...`` into (package dirs, files).
+
+    Returns ``None`` when no patterns were given. Exits with status 2 when
+    the language has no pattern loader or the patterns do not resolve.
+    """
+    patterns = list(getattr(args, "patterns", None) or [])
+    if not patterns:
+        return None
+    resolver = getattr(lang, "resolve_patterns", None) if lang is not None else None
+    if resolver is None:
+        name = lang.name if lang is not None else "this project"
+        print(
+            colorize(f"  Package patterns are not supported for {name}; use --path.", "red"),
+            file=sys.stderr,
+        )
+        sys.exit(2)
+    try:
+        dirs, files = resolver(patterns, dict(lang.state.runtime_options))
+    except ValueError as exc:
+        print(colorize(f"  Cannot resolve package patterns: {exc}", "red"), file=sys.stderr)
+        sys.exit(2)
+    print(
+        colorize(
+            f"  Package selection ({' '.join(patterns)}): {len(dirs)} "
+            f"package{'' if len(dirs) == 1 else 's'}, {len(files)} files",
+            "dim",
+        ),
+        file=sys.stderr,
+    )
+    return tuple(dirs), tuple(files)
+
+
+def resolve_module_selection(
+    args, lang: LangRun | None
+) -> tuple[tuple[str, ...], tuple[str, ...]] | None:
+    """Resolve ``scan --module NAME...`` into (package dirs, files).
+
+    Returns ``None`` when no module was named. Exits with status 2 when the
+    language has no module loader or a name matches no module.
+    """
+    names = list(getattr(args, "module", None) or [])
+    if not names:
+        return None
+    resolver = getattr(lang, "resolve_modules", None) if lang is not None else None
+    if resolver is None:
+        name = lang.name if lang is not None else "this project"
+        print(colorize(f"  --module is not supported for {name}.", "red"), file=sys.stderr)
+        sys.exit(2)
+    try:
+        dirs, files = resolver(names, Path(args.path))
+    except ValueError as exc:
+        print(colorize(f"  Cannot resolve --module: {exc}", "red"), file=sys.stderr)
+        sys.exit(2)
+    print(
+        colorize(
+            f"  Module selection ({', '.join(names)}): {len(dirs)} "
+            f"package{'' if len(dirs) == 1 else 's'}, {len(files)} files",
+            "dim",
+        ),
+        file=sys.stderr,
+    )
+    return tuple(dirs), tuple(files)
+
+
+__all__ = [
+    "apply_build_tags",
+    "apply_path_globs",
+    "apply_rule_categories",
+    "apply_vendoring",
+    "resolve_module_selection",
+    "resolve_pattern_selection",
+]
@@ -0,0 +1,254 @@
+"""Staged-content analysis for pre-commit hooks (scan --staged)."""
+
+from __future__ import annotations
+
+import argparse
+import contextlib
+import io
+import posixpath
+import re
+import sys
+import tempfile
+from pathlib import Path
+from typing import Any
+
+from desloppify.app.commands.helpers.exit_codes import (
+    ERROR_EXIT_CODE,
+    FINDINGS_EXIT_CODE,
+    finding_severity,
+    is_failing,
+    resolve_fail_severity,
+)
+from desloppify.app.commands.helpers.lang import resolve_lang, resolve_lang_settings
+from desloppify.app.commands.helpers.runtime import command_runtime
+from desloppify.app.commands.helpers.runtime_options import resolve_lang_runtime_options
+from desloppify.app.commands.scan.scan_changed import (
+    GitSelectionError,
+    _run_git,
+    _split_nul,
+    _toplevel,
+)
+from desloppify.app.commands.scan.scan_stdin import (
+    _nearest_marker,
+    _PathRemapper,
+    findings_for_file,
+)
+from desloppify.core._internal.text_utils import get_project_root
+from desloppify.core.file_paths import normalize_path_separators, rel, safe_relpath
+from desloppify.engine.planning import core as plan_mod
+from desloppify.engine.planning.scan import PlanScanOptions
+from desloppify.languages._framework.base.types import LangConfig
+from desloppify.languages._framework.runtime import (
+    LangRun,
+    LangRunOverrides,
+    make_lang_run,
+)
+from desloppify.utils import colorize
+
+_SEVERITY_COLORS = {"high": "red", "medium": "yellow", "low": "dim"}
+# Aggregated summaries end in "(N occurrences in M files)"; rows are per match.
+_OCCURRENCES_SUFFIX_RE = re.compile(r"\s*\(\d+ occurrences? in \d+ files?\)$")
+
+
+def git_staged_changes(root: Path) -> list[str]:
+    """Added/copied/modified/renamed paths in the index (git-toplevel relative)."""
+    output = _run_git(
+        ["diff", "--cached", "--name-only", "-z", "--diff-filter=ACMR"], cwd=root
+    )
+    return _split_nul(output)
+
+
+def index_tree_files(toplevel: Path, directory: str) -> list[str]:
+    """Index entries directly inside ``directory`` (git-toplevel relative)."""
+    spec = f"{directory}/" if directory not in {"", "."} else "."
+    output = _run_git(["ls-files", "-z", "--cached", "--", spec], cwd=toplevel)
+    base = "" if directory in {"", "."} else directory
+    return [p for p in _split_nul(output) if posixpath.dirname(p) == base]
+
+
+def index_blob(toplevel: Path, git_path: str) -> str:
+    """Content of ``git_path`` as staged (``git show :path``)."""
+    return _run_git(["show", f":{git_path}"], cwd=toplevel)
+
+
+def stage_index_package(
+    toplevel: Path,
+    directory: str,
+    overlay_dir: Path,
+    lang: LangConfig,
+) -> None:
+    """Write the staged version of every source file in ``directory`` to the overlay."""
+    for git_path in index_tree_files(toplevel, directory):
+        if Path(git_path).suffix in lang.extensions:
+            (overlay_dir / posixpath.basename(git_path)).write_text(
+                index_blob(toplevel, git_path)
+            )
+    marker = _nearest_marker(
+        toplevel / directory, list(lang.detect_markers), get_project_root()
+    )
+    if marker is not None and not (overlay_dir / marker.name).exists():
+        (overlay_dir / marker.name).write_text(marker.read_text())
+
+
+def analyze_staged(
+    git_paths: list[str],
+    lang_run: LangRun,
+    *,
+    toplevel: Path,
+) -> list[dict[str, Any]]:
+    """Analyze staged content of ``git_paths``, one package overlay at a time."""
+    by_dir: dict[str, list[str]] = {}
+    for git_path in git_paths:
+        by_dir.setdefault(posixpath.dirname(git_path), []).append(git_path)
+
+    selected: list[dict[str, Any]] = []
+    for directory, paths in sorted(by_dir.items()):
+        real_dir = (toplevel / directory).resolve()
+        with tempfile.TemporaryDirectory(prefix="desloppify-staged-") as tmp:
+            overlay_dir = Path(tmp)
+            stage_index_package(toplevel, directory, overlay_dir, lang_run.config)
+            with contextlib.redirect_stderr(io.StringIO()):
+                findings, _potentials = plan_mod.generate_findings(
+                    overlay_dir,
+                    lang=lang_run,
+                    options=PlanScanOptions(include_slow=False, profile="objective"),
+                )
+            remap = _PathRemapper(overlay_dir, real_dir)
+            findings = [remap(finding) for finding in findings]
+        for git_path in sorted(paths):
+            target = (toplevel / git_path).resolve()
+            selected.extend(findings_for_file(findings, target, rel(str(target))))
+    return selected
+
+
+def _locations(finding: dict[str, Any]) -> list[int]:
+    detail = finding.get("detail") if isinstance(finding.get("detail"), dict) else {}
+    matches = detail.get("matches")
+    if isinstance(matches, list) and matches and isinstance(matches[0], dict):
+        return [int(m.get("line") or 0) for m in matches]
+    return [int(detail.get("line") or 0)]
+
+
+def suppressed_note(count: int) -> str:
+    """The line that stands in for findings left out by ``--max-findings``."""
+    return f"  ... and {count} more findings suppressed"
+
+
+def render_staged(
+    findings: list[dict[str, Any]],
+    *,
+    fail_severity: str,
+    max_findings: int | None = None,
+) -> int:
+    """Print findings as ``file:line [severity] rule summary``; return failures.
+
+    With ``max_findings``, only the first that many rows (in file and line
+    order) are printed; failures still count every finding.
+    """
+    failing = 0
+    rows: list[tuple[str, int, str, str, str]] = []
+    for finding in findings:
+        severity = finding_severity(finding)
+        detail = finding.get("detail") if isinstance(finding.get("detail"), dict) else {}
+        rule = str(detail.get("smell_id") or finding.get("detector", ""))
+        for line in _locations(finding):
+            summary = _OCCURRENCES_SUFFIX_RE.sub("", str(finding.get("summary", "")))
+            rows.append((str(finding.get("file", "")), line, severity, rule, summary))
+            if is_failing(finding, fail_severity):
+                failing += 1
+    rows.sort()
+    shown = rows if max_findings is None else rows[:max_findings]
+    for file, line, severity, rule, summary in shown:
+        location = f"{file}:{line}" if line else file
+        print(
+            f"  {location}  "
+            + colorize(f"[{severity}]", _SEVERITY_COLORS[severity])
+            + f" {rule}  {summary}"
+        )
+    if len(shown) < len(rows):
+        print(colorize(suppressed_note(len(rows) - len(shown)), "dim"))
+    if rows:
+        print()
+    verdict = (
+        colorize(f"  ✗ {failing} finding(s) at or above {fail_severity}", "red")
+        if failing
+        else colorize(f"  ✓ No staged findings at or above {fail_severity}", "green")
+    )
+    print(verdict)
+    return failing
+
+
+def _requested_paths(
+    args: argparse.Namespace, *, toplevel: Path, project_root: Path
+) -> list[str]:
+    explicit = list(getattr(args, "files", None) or [])
+    if not explicit:
+        return git_staged_changes(project_root)
+    paths = []
+    for name in explicit:
+        absolute = Path(name)
+        if not absolute.is_absolute():
+            absolute = Path.cwd() / absolute
+        paths.append(
+            normalize_path_separators(safe_relpath(absolute.resolve(), toplevel))
+        )
+    return paths
+
+
+def cmd_scan_staged(args: argparse.Namespace) -> None:
+    """Analyze the index version of staged files and exit non-zero on findings."""
+    lang_cfg = resolve_lang(args)
+    if lang_cfg is None:
+        print(
+            colorize("Could not determine a language. Use --lang <name>.", "red"),
+            file=sys.stderr,
+        )
+        sys.exit(ERROR_EXIT_CODE)
+
+    runtime = command_runtime(args)
+    project_root = get_project_root()
+    try:
+        toplevel = _toplevel(project_root)
+        git_paths = [
+            p
+            for p in _requested_paths(args, toplevel=toplevel, project_root=project_root)
+            if Path(p).suffix in lang_cfg.extensions and not p.startswith("../")
+        ]
+    except GitSelectionError as exc:
+        print(colorize(f"  scan --staged needs git: {exc}", "red"), file=sys.stderr)
+        sys.exit(ERROR_EXIT_CODE)
+
+    if not git_paths:
+        print(colorize(f"  No staged {lang_cfg.name} files.", "dim"))
+        return
+
+    lang_run = make_lang_run(
+        lang_cfg,
+        overrides=LangRunOverrides(
+            runtime_settings=resolve_lang_settings(runtime.config, lang_cfg),
+            runtime_options=resolve_lang_runtime_options(args, lang_cfg),
+        ),
+    )
+    findings = analyze_staged(git_paths, lang_run, toplevel=toplevel)
+    threshold = resolve_fail_severity(runtime.config, "staged_fail_severity")
+    print(colorize(f"  desloppify: {len(git_paths)} staged file(s)", "dim"))
+    failing = render_staged(
+        findings,
+        fail_severity=threshold,
+        max_findings=getattr(args, "max_findings", None),
+    )
+    if failing and not getattr(args, "no_fail", False):
+        sys.exit(FINDINGS_EXIT_CODE)
+
+
+__all__ = [
+    "analyze_staged",
+    "cmd_scan_staged",
+    "finding_severity",
+    "git_staged_changes",
+    "index_blob",
+    "index_tree_files",
+    "render_staged",
+    "stage_index_package",
+    "suppressed_note",
+]
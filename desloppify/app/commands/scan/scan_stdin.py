@@ -0,0 +1,310 @@
+"""Stdin single-file analysis for editor integration (scan --stdin)."""
+
+from __future__ import annotations
+
+import argparse
+import contextlib
+import io
+import json
+import shutil
+import sys
+import tempfile
+import time
+from dataclasses import dataclass
+from pathlib import Path
+from typing import Any
+
+from desloppify import languages as lang_api
+from desloppify.app.commands.helpers.exit_codes import ERROR_EXIT_CODE
+from desloppify.app.commands.helpers.lang import resolve_lang, resolve_lang_settings
+from desloppify.app.commands.helpers.runtime import command_runtime
+from desloppify.app.commands.helpers.runtime_options import resolve_lang_runtime_options
+from desloppify.app.commands.scan.scan_coverage import diagnostics_payload
+from desloppify.core._internal.text_utils import get_project_root
+from desloppify.core.file_paths import normalize_path_separators, rel
+from desloppify.engine.planning import core as plan_mod
+from desloppify.engine.planning.scan import PlanScanOptions
+from desloppify.languages._framework.base.types import LangConfig
+from desloppify.languages._framework.runtime import (
+    LangRun,
+    LangRunOverrides,
+    make_lang_run,
+)
+from desloppify.utils import colorize
+
+
+def lang_for_filename(args: argparse.Namespace, filename: str) -> LangConfig | None:
+    """Prefer --lang, then the plugin owning the file extension, then auto-detect."""
+    if getattr(args, "lang", None):
+        return resolve_lang(args)
+    suffix = Path(filename).suffix
+    for name in lang_api.available_langs():
+        cfg = lang_api.get_lang(name)
+        if suffix and suffix in cfg.extensions:
+            return cfg
+    return resolve_lang(args)
+
+
+def _nearest_marker(start: Path, markers: list[str], stop: Path) -> Path | None:
+    """Walk upward from ``start`` (to ``stop``) looking for a project marker file."""
+    current = start
+    while True:
+        for marker in markers:
+            candidate = current / marker
+            if candidate.is_file():
+                return candidate
+        if current == stop or current.parent == current:
+            return None
+        current = current.parent
+
+
+def _overlay_package_dir(target: Path, marker: Path | None) -> Path:
+    """Where the target's directory sits under the overlay root.
+
+    The overlay root stands for the module root (the marker's directory) or,
+    without one, the project root, so package-level rules see the real
+    directory names rather than the temp directory's.
+    """
+    root = marker.parent if marker is not None else get_project_root()
+    try:
+        relative = target.parent.relative_to(root)
+    except ValueError:
+        return Path(target.parent.name)
+    if relative == Path(".") and marker is None:
+        return Path(target.parent.name)
+    return relative
+
+
+def stage_overlay(
+    target: Path,
+    source: str,
+    overlay_dir: Path,
+    lang: LangConfig,
+    *,
+    package_context: bool,
+) -> Path:
+    """Mirror the target's package into ``overlay_dir`` with stdin content swapped in.
+
+    The package keeps its path below the module root. In package mode,
+    sibling source files and the nearest project marker (e.g. go.mod) are
+    copied so package-level phases see the real package. Returns the path
+    of the overlaid target file.
+    """
+    marker = None
+    if package_context:
+        marker = _nearest_marker(
+            target.parent, list(lang.detect_markers), get_project_root()
+        )
+    package_dir = overlay_dir / _overlay_package_dir(target, marker)
+    package_dir.mkdir(parents=True, exist_ok=True)
+    if package_context:
+        for sibling in sorted(target.parent.iterdir()):
+            if sibling.is_file() and sibling.suffix in lang.extensions:
+                shutil.copy2(sibling, package_dir / sibling.name)
+        if marker is not None and not (overlay_dir / marker.name).exists():
+            shutil.copy2(marker, overlay_dir / marker.name)
+    overlay_target = package_dir / target.name
+    overlay_target.write_text(source)
+    return overlay_target
+
+
+class _PathRemapper:
+    """Rewrite overlay paths in findings back to the real on-disk location."""
+
+    def __init__(self, overlay_dir: Path, real_dir: Path) -> None:
+        real_rel = rel(str(real_dir))
+        real_rel = "" if real_rel == "." else real_rel
+        # Relative form first: it contains the absolute form as a substring.
+        self._pairs = [
+            (rel(str(overlay_dir)), real_rel),
+            (str(overlay_dir.resolve()), str(real_dir)),
+        ]
+
+    def __call__(self, value: Any) -> Any:
+        if isinstance(value, str):
+            for old, new in self._pairs:
+                if old in value:
+                    value = value.replace(old + "/", f"{new}/" if new else "")
+                    value = value.replace(old, new)
+            return value
+        if isinstance(value, list):
+            return [self(item) for item in value]
+        if isinstance(value, dict):
+            return {key: self(item) for key, item in value.items()}
+        return value
+
+
+def _match_targets(match_file: str, target: Path, target_rel: str) -> bool:
+    normalized = normalize_path_separators(match_file)
+    return normalized in {target_rel, normalize_path_separators(str(target))}
+
+
+def findings_for_file(
+    findings: list[dict[str, Any]], target: Path, target_rel: str
+) -> list[dict[str, Any]]:
+    """Keep findings for the target file, narrowing aggregated match lists."""
+    selected: list[dict[str, Any]] = []
+    for finding in findings:
+        detail = finding.get("detail")
+        matches = detail.get("matches") if isinstance(detail, dict) else None
+        if isinstance(matches, list) and matches and isinstance(matches[0], dict):
+            own = [
+                m
+                for m in matches
+                if _match_targets(str(m.get("file", "")), target, target_rel)
+            ]
+            if not own:
+                continue
+            finding = {
+                **finding,
+                "file": target_rel,
+                "detail": {**detail, "matches": own},
+            }
+            selected.append(finding)
+            continue
+        if finding.get("file") == target_rel:
+            selected.append(finding)
+    return selected
+
+
+def _fail(message: str) -> None:
+    print(colorize(message, "red"), file=sys.stderr)
+    sys.exit(ERROR_EXIT_CODE)
+
+
+@dataclass
+class BufferAnalysis:
+    """Findings for one in-memory buffer analyzed in place of its on-disk file."""
+
+    file: str
+    mode: str
+    findings: list[dict[str, Any]]
+
+
+def resolve_buffer_target(filename: str) -> Path:
+    target = Path(filename)
+    if not target.is_absolute():
+        target = get_project_root() / target
+    return target.resolve()
+
+
+def _analyze_overlay(
+    target: Path, source: str, lang_run: LangRun, package_context: bool, verbose: bool
+) -> list[dict[str, Any]]:
+    progress = io.StringIO()
+    with tempfile.TemporaryDirectory(prefix="desloppify-stdin-") as tmp:
+        overlay_dir = Path(tmp)
+        overlay_target = stage_overlay(
+            target, source, overlay_dir, lang_run.config, package_context=package_context
+        )
+        redirect = (
+            contextlib.nullcontext()
+            if verbose
+            else contextlib.redirect_stderr(progress)
+        )
+        with redirect:
+            findings, _potentials = plan_mod.generate_findings(
+                overlay_dir,
+                lang=lang_run,
+                options=PlanScanOptions(
+                    include_slow=False,
+                    profile="objective",
+                    syntax_only=lang_run.syntax_only,
+                ),
+            )
+        remap = _PathRemapper(overlay_target.parent, target.parent)
+        return [remap(finding) for finding in findings]
+
+
+def analyze_buffer(
+    target: Path,
+    source: str,
+    lang_run: LangRun,
+    *,
+    verbose: bool = False,
+) -> BufferAnalysis:
+    """Analyze ``source`` as if it were saved at ``target``.
+
+    Files that already exist are analyzed inside a mirror of their package;
+    new files degrade to syntax-only phases and rules, as with ``--fast``.
+    """
+    target_rel = rel(str(target))
+    package_context = target.is_file()
+    syntax_only = lang_run.syntax_only
+    # A lone new file has no package or module to load.
+    lang_run.state.syntax_only = syntax_only or not package_context
+    try:
+        findings = _analyze_overlay(target, source, lang_run, package_context, verbose)
+    finally:
+        lang_run.state.syntax_only = syntax_only
+
+    return BufferAnalysis(
+        file=target_rel,
+        mode="package" if package_context else "syntax_only",
+        findings=findings_for_file(findings, target, target_rel),
+    )
+
+
+def cmd_scan_stdin(args: argparse.Namespace) -> None:
+    """Analyze stdin as ``--stdin-filename`` and print its findings as JSON."""
+    filename = getattr(args, "stdin_filename", None)
+    if not filename:
+        _fail("--stdin requires --stdin-filename PATH (the buffer's real location).")
+        return
+
+    started = time.perf_counter()
+    verbose = bool(getattr(args, "verbose", False))
+    source = sys.stdin.read()
+
+    lang_cfg = lang_for_filename(args, filename)
+    if lang_cfg is None:
+        _fail(f"Could not determine a language for {filename}. Use --lang <name>.")
+        return
+
+    runtime = command_runtime(args)
+    lang_run = make_lang_run(
+        lang_cfg,
+        overrides=LangRunOverrides(
+            runtime_settings=resolve_lang_settings(runtime.config, lang_cfg),
+            runtime_options=resolve_lang_runtime_options(args, lang_cfg),
+        ),
+    )
+    result = analyze_buffer(
+        resolve_buffer_target(filename), source, lang_run, verbose=verbose
+    )
+    payload: dict[str, object] = {
+        "file": result.file,
+        "mode": result.mode,
+        "findings": result.findings,
+    }
+    diagnostics = diagnostics_payload(lang_run)
+    if diagnostics is not None:
+        payload["diagnostics"] = diagnostics
+    print(
+        json.dumps(
+            payload,
+            indent=2 if sys.stdout.isatty() else None,
+            default=str,
+        )
+    )
+    if verbose:
+        elapsed_ms = (time.perf_counter() - started) * 1000
+        print(
+            colorize(
+                f"  stdin analysis: {elapsed_ms:.0f} ms ({result.mode}, "
+                f"{len(result.findings)} finding(s) for {result.file})",
+                "dim",
+            ),
+            file=sys.stderr,
+        )
+
+
+__all__ = [
+    "BufferAnalysis",
+    "analyze_buffer",
+    "cmd_scan_stdin",
+    "findings_for_file",
+    "lang_for_filename",
+    "resolve_buffer_target",
+    "stage_overlay",
+]
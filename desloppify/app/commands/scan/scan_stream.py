@@ -0,0 +1,83 @@
+"""Streaming scan (scan --stream): findings as JSONL on stdout, no state update."""
+
+from __future__ import annotations
+
+import argparse
+import json
+import sys
+
+from desloppify.app.commands.helpers.exit_codes import (
+    ERROR_EXIT_CODE,
+    is_failing,
+    resolve_fail_severity,
+)
+from desloppify.app.commands.scan.scan_changed import finding_reported
+from desloppify.app.commands.scan.scan_contracts import ScanOutcome
+from desloppify.app.commands.scan.scan_coverage import diagnostics_payload
+from desloppify.app.commands.scan.scan_staged import suppressed_note
+from desloppify.app.commands.scan.scan_workflow import prepare_scan_runtime
+from desloppify.engine.planning.scan import PlanScanOptions, iter_phase_results
+from desloppify.engine.planning.spill import FindingCounters, finding_sort_key
+from desloppify.file_discovery import disable_file_cache, enable_file_cache
+from desloppify.utils import colorize
+
+
+def cmd_scan_stream(args: argparse.Namespace) -> ScanOutcome:
+    """Print each finding as one JSON line as soon as its phase finishes.
+
+    Nothing is kept past its phase: the closing ``{"summary": ...}`` line is
+    built from streaming counters, and the state file is left untouched.
+    Each phase's findings are printed in file order. With ``--max-findings
+    N`` only the first N of the run are; the summary still counts them all
+    and gives the number left out as ``suppressed``.
+    Returns how many findings reached the fail severity and how many units
+    were only partly analyzed.
+    """
+    from desloppify.languages._framework.treesitter import (
+        disable_parse_cache,
+        enable_parse_cache,
+    )
+
+    runtime = prepare_scan_runtime(args)
+    if runtime.lang is None:
+        print(colorize("No language detected; pass --lang <name>.", "red"), file=sys.stderr)
+        sys.exit(ERROR_EXIT_CODE)
+
+    counters = FindingCounters()
+    severity = resolve_fail_severity(getattr(runtime, "config", None))
+    failing = 0
+    max_findings = getattr(args, "max_findings", None)
+    options = PlanScanOptions(
+        include_slow=runtime.effective_include_slow,
+        zone_overrides=runtime.zone_overrides,
+        profile=runtime.profile,
+        syntax_only=runtime.lang.syntax_only,
+    )
+    enable_file_cache()
+    enable_parse_cache()
+    try:
+        for result in iter_phase_results(runtime.path, runtime.lang, options=options):
+            findings = [f for f in result.findings if finding_reported(f)]
+            for finding in sorted(findings, key=finding_sort_key):
+                counters.add(finding)
+                failing += is_failing(finding, severity)
+                if max_findings is None or counters.total <= max_findings:
+                    sys.stdout.write(json.dumps(finding, default=str) + "\n")
+            sys.stdout.flush()
+    finally:
+        disable_parse_cache()
+        disable_file_cache()
+    summary: dict[str, object] = {"summary": counters.as_dict()}
+    suppressed = counters.total - max_findings if max_findings is not None else 0
+    if suppressed > 0:
+        summary["suppressed"] = suppressed
+        print(colorize(suppressed_note(suppressed), "dim"), file=sys.stderr)
+    diagnostics = diagnostics_payload(runtime.lang)
+    if diagnostics is not None:
+        summary["diagnostics"] = diagnostics
+    sys.stdout.write(json.dumps(summary) + "\n")
+    sys.stdout.flush()
+    return ScanOutcome(failing=failing, degraded=len(runtime.lang.degraded_units))
+
+
+__all__ = ["cmd_scan_stream"]
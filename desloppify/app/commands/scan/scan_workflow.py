@@ -3,6 +3,8 @@
 from __future__ import annotations
 
 import argparse
+import posixpath
+import sys
 from dataclasses import dataclass, field
 from pathlib import Path
 from typing import TYPE_CHECKING, Any
@@ -11,10 +13,28 @@ if TYPE_CHECKING:
     from desloppify.languages._framework.runtime import LangRun
 
 from desloppify import state as state_mod
-from desloppify.app.commands.helpers.lang import resolve_lang, resolve_lang_settings
+from desloppify.app.commands.helpers.lang import (
+    check_lang_settings,
+    resolve_lang,
+    resolve_lang_settings,
+)
 from desloppify.app.commands.helpers.runtime import command_runtime
 from desloppify.app.commands.helpers.runtime_options import resolve_lang_runtime_options
 from desloppify.app.commands.helpers.score import target_strict_score_from_config
+from desloppify.app.commands.scan.scan_changed import (
+    finding_reported,
+    resolve_changed_selection,
+    resolve_diff_base,
+    with_dependents,
+)
+from desloppify.app.commands.scan.scan_patterns import (
+    apply_build_tags,
+    apply_path_globs,
+    apply_rule_categories,
+    apply_vendoring,
+    resolve_module_selection,
+    resolve_pattern_selection,
+)
 from desloppify.app.commands.scan.scan_coverage import (
     coerce_int as _coerce_int,
     persist_scan_coverage as _persist_scan_coverage,
@@ -31,6 +51,8 @@ from desloppify.app.commands.scan.scan_wontfix import (
     augment_with_stale_wontfix_findings as _augment_stale_wontfix_impl,
 )
 from desloppify.core._internal.text_utils import PROJECT_ROOT
+from desloppify.core.config import ConfigError
+from desloppify.core.result_cache import ResultCache
 from desloppify.engine import work_queue as issues_mod
 from desloppify.engine.planning import core as plan_mod
 from desloppify.engine.planning.scan import PlanScanOptions
@@ -38,7 +60,14 @@ from desloppify.file_discovery import (
     disable_file_cache,
     enable_file_cache,
     get_exclusions,
+    get_path_globs,
+    get_report_dirs,
+    get_report_lines,
     rel,
+    set_report_dirs,
+    set_report_lines,
+    set_selected_dirs,
+    set_selected_files,
 )
 from desloppify.languages._framework.base.types import DetectorCoverageRecord
 from desloppify.languages._framework.runtime import LangRunOverrides, make_lang_run
@@ -73,6 +102,7 @@ class ScanRuntime:
     reset_subjective_count: int = 0
     expired_manual_override_count: int = 0
     coverage_warnings: list[DetectorCoverageRecord] = field(default_factory=list)
+    selected_dirs: tuple[str, ...] | None = None
 
 
 @dataclass
@@ -119,6 +149,10 @@ def _configure_lang_runtime(
             runtime_options=lang_options,
             large_threshold_override=config.get("large_files_threshold", 0),
             props_threshold_override=config.get("props_threshold", 0),
+            jobs=getattr(args, "jobs", None) or 0,
+            result_cache=None if getattr(args, "no_cache", False) else ResultCache(),
+            syntax_only=bool(getattr(args, "fast", False)),
+            full_matches=bool(getattr(args, "all_matches", False)),
         ),
     )
 
@@ -129,6 +163,21 @@ def _configure_lang_runtime(
     return runtime_lang
 
 
+def _check_lang_config(
+    args: argparse.Namespace, config: dict[str, object], lang: LangRun | None
+) -> None:
+    """Stop on unknown or invalid ``languages.<lang>`` config; ``--lenient-config`` warns."""
+    if lang is None:
+        return
+    problems = check_lang_settings(config, lang)
+    if not problems:
+        return
+    if not getattr(args, "lenient_config", False):
+        raise ConfigError("\n  ".join(problems))
+    for problem in problems:
+        print(colorize(f"  Config warning: {problem}", "yellow"), file=sys.stderr)
+
+
 def _reset_subjective_assessments_for_scan_reset(
     state: state_mod.StateModel,
     *,
@@ -221,7 +270,47 @@ def prepare_scan_runtime(args) -> ScanRuntime:
     profile = _resolve_scan_profile(getattr(args, "profile", None), lang_config)
     effective_include_slow = _effective_include_slow(include_slow, profile)
 
+    changed_dirs = resolve_changed_selection(args)
+    changed_lines = resolve_diff_base(args)
+    selected_dirs: tuple[str, ...] | None = None
+
     lang = _configure_lang_runtime(args, config, state, lang_config)
+    _check_lang_config(args, config, lang)
+    apply_build_tags(args, lang)
+    apply_vendoring(args, lang)
+    apply_rule_categories(args, lang)
+    apply_path_globs(args)
+    selected_files: set[str] | None = None
+    for selection in (
+        resolve_module_selection(args, lang),
+        resolve_pattern_selection(args, lang),
+    ):
+        if selection is None:
+            continue
+        dirs, files = selection
+        if selected_dirs is not None:
+            selected_dirs = tuple(sorted(set(selected_dirs) & set(dirs)))
+        else:
+            selected_dirs = dirs
+        selected_files = set(files) if selected_files is None else selected_files & set(files)
+    if selected_files is not None:
+        set_selected_files(sorted(selected_files))
+    if selected_dirs is not None:
+        set_selected_dirs(list(selected_dirs))
+    if changed_dirs is not None:
+        changed_dirs = with_dependents(changed_dirs, lang, path)
+    if changed_lines is not None:
+        set_report_lines(changed_lines)
+        diff_dirs = {posixpath.dirname(f) or "." for f in changed_lines}
+        changed_dirs = tuple(sorted(diff_dirs & set(changed_dirs or diff_dirs)))
+    if changed_dirs is not None:
+        # Changed-files scans discover everything but only analyze and report
+        # the selected packages.
+        set_report_dirs(list(changed_dirs))
+        if selected_dirs is not None:
+            selected_dirs = tuple(sorted(set(selected_dirs) & set(changed_dirs)))
+        else:
+            selected_dirs = changed_dirs
     coverage_warnings = _seed_runtime_coverage_warnings(lang)
     zone_overrides_raw = config.get("zone_overrides")
     zone_overrides = zone_overrides_raw if isinstance(zone_overrides_raw, dict) else None
@@ -240,6 +329,7 @@ def prepare_scan_runtime(args) -> ScanRuntime:
         reset_subjective_count=reset_subjective_count,
         expired_manual_override_count=expired_manual_override_count,
         coverage_warnings=coverage_warnings,
+        selected_dirs=selected_dirs,
     )
 
 
@@ -301,12 +391,21 @@ def run_scan_generation(
                 include_slow=runtime.effective_include_slow,
                 zone_overrides=runtime.zone_overrides,
                 profile=runtime.profile,
+                syntax_only=bool(runtime.lang and runtime.lang.syntax_only),
+                max_memory_mb=getattr(runtime.args, "max_memory", None),
+                subsumes=runtime.config.get("finding_subsumes"),
             ),
         )
     finally:
         disable_parse_cache()
         disable_file_cache()
 
+    globs = get_path_globs()
+    if globs is not None:
+        # Phases that do not walk files themselves (go vet) report on every file.
+        findings = [f for f in findings if globs.selects(str(f.get("file", "")))]
+    if get_report_dirs() is not None:
+        findings = [f for f in findings if finding_reported(f)]
     codebase_metrics = _collect_codebase_metrics(runtime.lang, runtime.path)
     _warn_explicit_lang_with_no_files(
         runtime.args, runtime.lang, runtime.path, codebase_metrics
@@ -358,6 +457,9 @@ def merge_scan_results(
             scan_path=scan_path_rel,
             force_resolve=getattr(runtime.args, "force_resolve", False),
             exclude=get_exclusions(),
+            selected_dirs=runtime.selected_dirs,
+            path_globs=get_path_globs(),
+            changed_lines=get_report_lines(),
             potentials=potentials,
             codebase_metrics=codebase_metrics,
             include_slow=runtime.effective_include_slow,
@@ -15,6 +15,7 @@ from desloppify.app.commands.scan import (
 )
 from desloppify.app.commands.status_parts.render import (
     print_open_scope_breakdown,
+    print_owner_breakdown,
     print_scan_completeness,
     print_scan_metrics,
     score_summary_lines,
@@ -41,6 +42,9 @@ def cmd_status(args: argparse.Namespace) -> None:
     runtime = command_runtime(args)
     state = runtime.state
     config = runtime.config
+    owner = getattr(args, "owner", None)
+    if owner:
+        state = state_mod.owner_scoped_state(state, owner)
 
     stats = state.get("stats", {})
     dim_scores = state.get("dimension_scores", {}) or {}
@@ -94,8 +98,17 @@ def cmd_status(args: argparse.Namespace) -> None:
     ):
         print(colorize(line, style))
     print_scan_metrics(state)
+    if owner:
+        print(
+            colorize(
+                f"  Owner {owner}: {stats.get('open', 0)} open findings "
+                "(scores are for the whole scan)",
+                "cyan",
+            )
+        )
     print_open_scope_breakdown(state)
     print_scan_completeness(state)
+    print_owner_breakdown(stats)
 
     if dim_scores:
         show_dimension_table(state, dim_scores)
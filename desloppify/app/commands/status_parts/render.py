@@ -14,6 +14,7 @@ from desloppify.app.commands.scan import (
 from desloppify.app.commands.scan.scan_reporting_presentation import dimension_bar
 from desloppify.app.commands.status_parts.summary import (
     print_open_scope_breakdown,
+    print_owner_breakdown,
     print_scan_completeness,
     print_scan_metrics,
     score_summary_lines,
@@ -521,6 +522,7 @@ def show_review_summary(state: dict):
 
 __all__ = [
     "print_open_scope_breakdown",
+    "print_owner_breakdown",
     "print_scan_completeness",
     "print_scan_metrics",
     "score_summary_lines",
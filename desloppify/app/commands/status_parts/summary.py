@@ -3,7 +3,7 @@
 from __future__ import annotations
 
 from desloppify import state as state_mod
-from desloppify.utils import LOC_COMPACT_THRESHOLD, colorize
+from desloppify.utils import LOC_COMPACT_THRESHOLD, colorize, print_table
 
 
 def score_summary_lines(
@@ -90,8 +90,21 @@ def print_open_scope_breakdown(state: dict) -> None:
     )
 
 
+def print_owner_breakdown(stats: dict, *, limit: int = 10) -> None:
+    """Open findings per CODEOWNERS owner, when the scan attributed any."""
+    by_owner = stats.get("by_owner") or {}
+    if not by_owner:
+        return
+    print(colorize("\n  Open findings by owner", "bold"))
+    rows = [[owner, str(count)] for owner, count in list(by_owner.items())[:limit]]
+    print_table(["Owner", "Open"], rows, [40, 6])
+    if len(by_owner) > limit:
+        print(colorize(f"  … {len(by_owner) - limit} more owners", "dim"))
+
+
 __all__ = [
     "print_open_scope_breakdown",
+    "print_owner_breakdown",
     "print_scan_completeness",
     "print_scan_metrics",
     "score_summary_lines",
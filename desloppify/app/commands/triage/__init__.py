@@ -0,0 +1 @@
+"""triage command package."""
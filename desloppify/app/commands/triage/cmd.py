@@ -0,0 +1,202 @@
+"""triage command: step through open findings and decide on each one."""
+
+from __future__ import annotations
+
+import argparse
+import os
+import shlex
+import subprocess
+import sys
+from collections.abc import Callable
+from pathlib import Path
+
+from desloppify import state as state_mod
+from desloppify.app.commands.helpers.lang import resolve_lang
+from desloppify.app.commands.helpers.state import state_path
+from desloppify.app.commands.lsp.diagnostics import suppression_directive
+from desloppify.app.commands.triage.session import (
+    TRIAGE_FILE,
+    TriageItem,
+    TriageLog,
+    locate,
+    triage_items,
+)
+from desloppify.core._internal.text_utils import get_project_root
+from desloppify.core.file_paths import safe_write_text
+from desloppify.languages._framework.fixers import line_edit
+from desloppify.utils import colorize, read_code_snippet
+
+_BASELINE_NOTE = "baselined during triage"
+
+
+def cmd_triage(args: argparse.Namespace) -> None:
+    """Walk open findings one at a time; decisions persist as they are made."""
+    state_file = state_path(args)
+    state = state_mod.load_state(state_file)
+    log = TriageLog(get_project_root() / TRIAGE_FILE)
+    if getattr(args, "reset", False):
+        log.reset()
+    lang = resolve_lang(args)
+    items = triage_items(state.get("findings", {}))
+    pending = [item for item in items if not log.decided(item.key)]
+    if not pending:
+        print(colorize(f"Nothing left to triage ({len(items)} decided).", "green"))
+        return
+    if len(pending) < len(items):
+        print(colorize(f"  Resuming: {len(items) - len(pending)} already decided.", "dim"))
+    run_triage(
+        pending,
+        log,
+        state=state,
+        save_state=lambda: state_mod.save_state(state, state_file),
+        lang_name=lang.name if lang else "",
+    )
+
+
+def run_triage(
+    items: list[TriageItem],
+    log: TriageLog,
+    *,
+    state: dict,
+    save_state: Callable[[], None],
+    lang_name: str,
+    ask: Callable[[str], str] = input,
+) -> None:
+    """The prompt loop; ``ask`` reads one answer (``input`` unless testing)."""
+    root = get_project_root()
+    for index, item in enumerate(items, 1):
+        if log.decided(item.key):
+            continue
+        _show(item, root, index, len(items))
+        while True:
+            try:
+                choice = ask(_prompt(item, lang_name)).strip().lower()[:1]
+            except (EOFError, KeyboardInterrupt):
+                choice = "q"
+            if choice == "q":
+                print(colorize("\n  Progress saved; run `desloppify triage` to resume.", "dim"))
+                return
+            if choice == "n":
+                log.record(item, "skip")
+                break
+            if choice == "e":
+                _open_editor(root / item.file, item, root)
+                continue
+            if choice == "f" and item.fix is not None:
+                if _apply_fix(root / item.file, item):
+                    log.record(item, "fix")
+                    break
+                continue
+            if choice == "s" and suppression_directive(lang_name, item.detector):
+                try:
+                    reason = ask("  Reason: ").strip()
+                except (EOFError, KeyboardInterrupt):
+                    reason = ""
+                if not reason:
+                    print(colorize("  A suppression needs a reason.", "yellow"))
+                    continue
+                directive = suppression_directive(lang_name, item.detector)
+                if _suppress(root / item.file, item, f"{directive} {item.rule} // {reason}"):
+                    log.record(item, "suppress", reason=reason)
+                    break
+                continue
+            if choice == "b":
+                _baseline(item, items, log, state)
+                save_state()
+                break
+            print(colorize("  Unknown action.", "yellow"))
+    print(colorize("\n  Triage complete.", "green"))
+
+
+def _prompt(item: TriageItem, lang_name: str) -> str:
+    actions = []
+    if item.fix is not None:
+        actions.append("[f]ix")
+    if suppression_directive(lang_name, item.detector):
+        actions.append("[s]uppress")
+    actions += ["[b]aseline", "[e]dit", "[n]ext", "[q]uit"]
+    return f"  {', '.join(actions)}? "
+
+
+def _show(item: TriageItem, root: Path, index: int, total: int) -> None:
+    print(colorize(f"\n  [{index}/{total}] {item.rule}  {item.file}:{item.line}", "bold"))
+    print(f"    {item.summary}")
+    line = _current_line(root / item.file, item)
+    snippet = read_code_snippet(item.file, line, context=2) if line else None
+    if snippet:
+        print(snippet)
+    else:
+        print(colorize("    (source line moved or gone)", "dim"))
+    if item.fix is not None:
+        print(colorize(f"    fix: {item.fix.get('title', 'apply suggested edit')}", "dim"))
+
+
+def _current_line(path: Path, item: TriageItem) -> int | None:
+    try:
+        return locate(path.read_text(errors="replace").splitlines(), item)
+    except OSError:
+        return None
+
+
+def _apply_fix(path: Path, item: TriageItem) -> bool:
+    """Apply the item's edit where its line is now; False if it no longer applies."""
+    assert item.fix is not None
+    try:
+        content = path.read_text()
+    except (OSError, UnicodeDecodeError) as ex:
+        print(colorize(f"  Cannot read {item.file}: {ex}", "yellow"))
+        return False
+    line = locate(content.splitlines(), item)
+    edit = None
+    if line is not None:
+        fix_line = int(item.fix.get("line") or item.line) + line - item.line
+        edit = line_edit(
+            content, fix_line, str(item.fix.get("old", "")), str(item.fix.get("new", "")), item.rule
+        )
+    if edit is None:
+        print(colorize("  The fix no longer applies here.", "yellow"))
+        return False
+    safe_write_text(path, content[: edit.start] + edit.new + content[edit.end :])
+    print(colorize(f"  Fixed {item.file}:{line}", "green"))
+    return True
+
+
+def _suppress(path: Path, item: TriageItem, comment: str) -> bool:
+    """Insert ``comment`` on its own line above the item, at the item's indent."""
+    try:
+        content = path.read_text()
+    except (OSError, UnicodeDecodeError) as ex:
+        print(colorize(f"  Cannot read {item.file}: {ex}", "yellow"))
+        return False
+    lines = content.splitlines(keepends=True)
+    line = locate([text.rstrip("\r\n") for text in lines], item)
+    if line is None:
+        print(colorize("  The source line moved or is gone.", "yellow"))
+        return False
+    text = lines[line - 1]
+    indent = text[: len(text) - len(text.lstrip())]
+    lines.insert(line - 1, f"{indent}{comment}\n")
+    safe_write_text(path, "".join(lines))
+    print(colorize(f"  Suppressed at {item.file}:{line}", "green"))
+    return True
+
+
+def _baseline(item: TriageItem, items: list[TriageItem], log: TriageLog, state: dict) -> None:
+    """Accept the whole finding as ``wontfix``; its other locations are decided too."""
+    state_mod.resolve_findings(state, item.finding_id, "wontfix", _BASELINE_NOTE)
+    for other in items:
+        if other.finding_id == item.finding_id and not log.decided(other.key):
+            log.record(other, "baseline")
+    print(colorize(f"  Baselined {item.finding_id}", "green"))
+
+
+def _open_editor(path: Path, item: TriageItem, root: Path) -> None:
+    editor = os.environ.get("VISUAL") or os.environ.get("EDITOR") or "vi"
+    line = _current_line(path, item) or item.line
+    try:
+        subprocess.run([*shlex.split(editor), f"+{line}", str(path)], cwd=root)
+    except OSError as ex:
+        print(colorize(f"  Could not start {editor}: {ex}", "yellow"), file=sys.stderr)
+
+
+__all__ = ["cmd_triage", "run_triage"]
@@ -0,0 +1,122 @@
+"""Triage items and the decisions log an interrupted session resumes from."""
+
+from __future__ import annotations
+
+import json
+from collections import Counter
+from dataclasses import dataclass
+from pathlib import Path
+from typing import Any
+
+from desloppify.core.file_paths import safe_write_text
+from desloppify.state import utc_now
+
+# Lives at the project root; scans never read it.
+TRIAGE_FILE = ".desloppify-triage.json"
+_VERSION = 1
+_TIER_ORDER = 9
+
+
+@dataclass(frozen=True)
+class TriageItem:
+    """One location to decide on; aggregated findings yield one per match."""
+
+    key: str
+    finding_id: str
+    detector: str
+    rule: str
+    file: str
+    line: int
+    content: str
+    summary: str
+    fix: dict[str, Any] | None = None
+
+
+def triage_items(findings: dict[str, dict]) -> list[TriageItem]:
+    """Open findings as items, most severe tier first.
+
+    Keys leave out line numbers, so a decision still applies after edits
+    above the location move it.
+    """
+    items: list[TriageItem] = []
+    counts: Counter[str] = Counter()
+    ordered = sorted(
+        (f for f in findings.values() if f.get("status") == "open" and not f.get("suppressed")),
+        key=lambda f: (int(f.get("tier") or _TIER_ORDER), f.get("id", "")),
+    )
+    for finding in ordered:
+        detail = finding.get("detail") if isinstance(finding.get("detail"), dict) else {}
+        matches = detail.get("matches")
+        if not (isinstance(matches, list) and matches and isinstance(matches[0], dict)):
+            matches = [{"file": finding.get("file", ""), "line": detail.get("line") or 1}]
+        rule = str(detail.get("smell_id") or finding.get("detector", ""))
+        for match in matches:
+            content = str(match.get("content", ""))
+            base = f"{finding['id']}::{match.get('file', '')}::{content}"
+            counts[base] += 1
+            items.append(
+                TriageItem(
+                    key=f"{base}#{counts[base]}",
+                    finding_id=finding["id"],
+                    detector=str(finding.get("detector", "")),
+                    rule=rule,
+                    file=str(match.get("file", "")),
+                    line=int(match.get("line") or 1),
+                    content=content,
+                    summary=str(finding.get("summary", "")),
+                    fix=match.get("fix") if isinstance(match.get("fix"), dict) else None,
+                )
+            )
+    return items
+
+
+def locate(lines: list[str], item: TriageItem) -> int | None:
+    """Current 1-based line of ``item``: where recorded, else the nearest line with its text.
+
+    Items without recorded text are trusted to be where they were.
+    """
+    if not item.content:
+        return item.line if 1 <= item.line <= len(lines) else None
+    candidates = [
+        i + 1 for i, text in enumerate(lines) if text.strip()[:100] == item.content
+    ]
+    if not candidates:
+        return None
+    return min(candidates, key=lambda n: abs(n - item.line))
+
+
+class TriageLog:
+    """Decisions keyed by item, saved after every one so a session can resume."""
+
+    def __init__(self, path: Path) -> None:
+        self.path = path
+        self.decisions: dict[str, dict[str, Any]] = {}
+        try:
+            data = json.loads(path.read_text())
+        except (OSError, ValueError):
+            return
+        if isinstance(data, dict) and isinstance(data.get("decisions"), dict):
+            self.decisions = data["decisions"]
+
+    def decided(self, key: str) -> bool:
+        return key in self.decisions
+
+    def record(self, item: TriageItem, action: str, **extra: Any) -> None:
+        self.decisions[item.key] = {
+            "action": action,
+            "finding": item.finding_id,
+            "at": utc_now(),
+            **extra,
+        }
+        self.save()
+
+    def reset(self) -> None:
+        self.decisions = {}
+        self.save()
+
+    def save(self) -> None:
+        payload = {"version": _VERSION, "decisions": self.decisions}
+        safe_write_text(self.path, json.dumps(payload, indent=2, sort_keys=True) + "\n")
+
+
+__all__ = ["TRIAGE_FILE", "TriageItem", "TriageLog", "locate", "triage_items"]
@@ -0,0 +1,21 @@
+"""vettool command: build the go vet shim and print its path."""
+
+from __future__ import annotations
+
+import argparse
+import sys
+
+from desloppify.languages.go.vettool import build_vettool
+from desloppify.utils import colorize
+
+
+def cmd_vettool(args: argparse.Namespace) -> None:
+    """Print the shim's path, for ``go vet -vettool=$(desloppify vettool)``."""
+    binary = build_vettool()
+    if binary is None:
+        print(
+            colorize("  Could not build the go vet shim (is `go` on PATH?)", "red"),
+            file=sys.stderr,
+        )
+        sys.exit(1)
+    print(binary)
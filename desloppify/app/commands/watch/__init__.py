@@ -0,0 +1 @@
+"""watch command package."""
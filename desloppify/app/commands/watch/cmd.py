@@ -0,0 +1,78 @@
+"""watch command: re-analyze changed packages and print finding deltas."""
+
+from __future__ import annotations
+
+import argparse
+import sys
+from pathlib import Path
+
+from desloppify import languages as lang_api
+from desloppify.app.commands.helpers.lang import resolve_lang, resolve_lang_settings
+from desloppify.app.commands.helpers.runtime import command_runtime
+from desloppify.app.commands.helpers.runtime_options import resolve_lang_runtime_options
+from desloppify.app.commands.watch.render import emit_jsonl, render_tty
+from desloppify.app.commands.watch.session import WatchSession
+from desloppify.app.commands.watch.snapshot import ChangeWatcher, take_snapshot
+from desloppify.core._internal.text_utils import get_project_root
+from desloppify.core.file_paths import rel
+from desloppify.file_discovery import clear_source_file_cache
+from desloppify.languages._framework.runtime import LangRunOverrides, make_lang_run
+from desloppify.utils import colorize
+
+
+def cmd_watch(args: argparse.Namespace) -> None:
+    """Watch source files and re-analyze touched packages on change."""
+    lang_cfg = resolve_lang(args)
+    if not lang_cfg or not lang_cfg.file_finder:
+        langs = ", ".join(lang_api.available_langs()) or "registered language plugins"
+        print(
+            colorize(
+                f"No language specified. Use --lang <name> (available: {langs}).", "red"
+            ),
+            file=sys.stderr,
+        )
+        sys.exit(1)
+
+    runtime = command_runtime(args)
+    settings = resolve_lang_settings(runtime.config, lang_cfg)
+    options = resolve_lang_runtime_options(args, lang_cfg)
+    path = Path(args.path)
+    root = get_project_root()
+
+    def make_lang():
+        return make_lang_run(
+            lang_cfg,
+            overrides=LangRunOverrides(
+                runtime_settings=settings, runtime_options=options
+            ),
+        )
+
+    def list_files() -> list[str]:
+        clear_source_file_cache()
+        return lang_cfg.file_finder(path)
+
+    session = WatchSession(path, make_lang, root=root)
+    watcher = ChangeWatcher(
+        lambda: take_snapshot(list_files(), root),
+        interval=max(0.05, float(args.interval)),
+        debounce=max(0.0, float(args.debounce)),
+    )
+    tty = sys.stdout.isatty()
+    target = rel(str(path))
+
+    def emit(delta) -> None:
+        if tty:
+            render_tty(delta, sys.stdout, target=target, total=len(session.items))
+        else:
+            emit_jsonl(delta, sys.stdout)
+
+    try:
+        emit(session.initial(list_files()))
+        while True:
+            emit(session.refresh(watcher.wait()))
+    except KeyboardInterrupt:
+        if tty:
+            print(colorize("\n  Stopped watching.", "dim"))
+
+
+__all__ = ["cmd_watch"]
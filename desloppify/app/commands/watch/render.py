@@ -0,0 +1,75 @@
+"""Output for the watch command: TTY redraw or JSONL events."""
+
+from __future__ import annotations
+
+import json
+import time
+from typing import Any, TextIO
+
+from desloppify.app.commands.watch.session import FindingsDelta
+from desloppify.utils import colorize
+
+_CLEAR_SCREEN = "\033[2J\033[H"
+_MAX_LISTED = 20
+
+
+def findings_event(delta: FindingsDelta) -> dict[str, Any]:
+    """JSONL event payload for one analysis cycle."""
+    return {
+        "event": "findings",
+        "added": delta.added,
+        "removed": delta.removed,
+        "unchanged": delta.unchanged,
+        "dirs": delta.dirs,
+    }
+
+
+def emit_jsonl(delta: FindingsDelta, out: TextIO) -> None:
+    out.write(json.dumps(findings_event(delta), default=str) + "\n")
+    out.flush()
+
+
+def _item_line(item: dict[str, Any]) -> str:
+    location = item["file"]
+    if item.get("line"):
+        location = f"{location}:{item['line']}"
+    return f"{location}  [{item['kind']}] {item['summary']}"
+
+
+def render_tty(
+    delta: FindingsDelta,
+    out: TextIO,
+    *,
+    target: str,
+    total: int,
+    clock=time.localtime,
+) -> None:
+    """Clear the terminal and redraw a compact summary of the last cycle."""
+    stamp = time.strftime("%H:%M:%S", clock())
+    lines = [
+        _CLEAR_SCREEN
+        + colorize(f"Desloppify watch — {target}", "bold")
+        + colorize(f"  (updated {stamp}, {total} findings)", "dim")
+    ]
+    if delta.dirs:
+        lines.append(colorize(f"  Re-analyzed: {', '.join(delta.dirs)}", "dim"))
+    lines.append("")
+    for item in delta.added[:_MAX_LISTED]:
+        lines.append(colorize(f"  + {_item_line(item)}", "yellow"))
+    if len(delta.added) > _MAX_LISTED:
+        lines.append(colorize(f"  + … {len(delta.added) - _MAX_LISTED} more", "yellow"))
+    for item in delta.removed[:_MAX_LISTED]:
+        lines.append(colorize(f"  ✓ resolved: {_item_line(item)}", "green"))
+    if len(delta.removed) > _MAX_LISTED:
+        lines.append(
+            colorize(f"  ✓ … {len(delta.removed) - _MAX_LISTED} more resolved", "green")
+        )
+    if not delta.added and not delta.removed:
+        lines.append(colorize("  No changes in findings.", "dim"))
+    lines.append(colorize(f"\n  {delta.unchanged} unchanged", "dim"))
+    lines.append(colorize("  Watching for changes (Ctrl+C to stop)…", "dim"))
+    out.write("\n".join(lines) + "\n")
+    out.flush()
+
+
+__all__ = ["emit_jsonl", "findings_event", "render_tty"]
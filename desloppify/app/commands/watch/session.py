@@ -0,0 +1,208 @@
+"""Incremental re-analysis state for the watch command."""
+
+from __future__ import annotations
+
+import contextlib
+import io
+import posixpath
+import re
+from collections import Counter
+from collections.abc import Callable, Iterable
+from dataclasses import dataclass, field
+from pathlib import Path
+from typing import Any
+
+from desloppify.core.file_paths import normalize_path_separators, rel
+from desloppify.engine.planning import core as plan_mod
+from desloppify.engine.planning.scan import PlanScanOptions
+from desloppify.file_discovery import clear_source_file_cache, set_selected_dirs
+from desloppify.languages._framework.runtime import LangRun, LangRunOverrides, make_lang_run
+
+# Go-style exported top-level declarations (capitalized identifiers).
+_EXPORTED_DECL_RE = re.compile(
+    r"^(?:func\s+(?:\([^)]*\)\s*)?[A-Z]\w*\s*(?:\[[^\]]*\])?\(.*"
+    r"|type\s+[A-Z]\w*\b.*"
+    r"|(?:var|const)\s+[A-Z]\w*\b.*)$",
+    re.MULTILINE,
+)
+
+
+def exported_surface(content: str) -> frozenset[str]:
+    """Exported declaration lines — a cheap stand-in for an API signature."""
+    return frozenset(
+        " ".join(m.group(0).split()).rstrip("{").strip()
+        for m in _EXPORTED_DECL_RE.finditer(content)
+    )
+
+
+def _dir_of(filepath: str) -> str:
+    return posixpath.dirname(filepath) or "."
+
+
+def _project_rel(filepath: str) -> str:
+    if Path(filepath).is_absolute():
+        return rel(filepath)
+    return normalize_path_separators(filepath)
+
+
+def finding_items(findings: Iterable[dict[str, Any]]) -> dict[str, dict[str, Any]]:
+    """Flatten findings into stable per-location items keyed for diffing.
+
+    Aggregated findings (one finding carrying many ``detail.matches``) are
+    exploded so a partial re-analysis can replace just the touched packages.
+    Keys omit line numbers so edits above a finding do not churn it.
+    """
+    items: dict[str, dict[str, Any]] = {}
+    counts: Counter[str] = Counter()
+    for finding in findings:
+        detail = finding.get("detail") if isinstance(finding.get("detail"), dict) else {}
+        matches = detail.get("matches")
+        if isinstance(matches, list) and matches and isinstance(matches[0], dict):
+            kind = str(detail.get("smell_id") or finding.get("detector", ""))
+            entries = [
+                {
+                    "detector": finding.get("detector", ""),
+                    "kind": kind,
+                    "file": str(m.get("file", "")),
+                    "line": m.get("line"),
+                    "summary": str(m.get("content", "")),
+                }
+                for m in matches
+            ]
+        else:
+            entries = [
+                {
+                    "detector": finding.get("detector", ""),
+                    "kind": str(finding.get("id", "")),
+                    "file": str(finding.get("file", "")),
+                    "line": detail.get("line"),
+                    "summary": str(finding.get("summary", "")),
+                }
+            ]
+        for entry in entries:
+            base = f"{entry['kind']}::{entry['file']}::{entry['summary']}"
+            counts[base] += 1
+            items[f"{base}#{counts[base]}"] = entry
+    return items
+
+
+@dataclass
+class FindingsDelta:
+    added: list[dict[str, Any]] = field(default_factory=list)
+    removed: list[dict[str, Any]] = field(default_factory=list)
+    unchanged: int = 0
+    dirs: list[str] = field(default_factory=list)
+
+
+class WatchSession:
+    """Hold the current finding set and refresh it one package at a time."""
+
+    def __init__(
+        self,
+        path: Path,
+        make_lang: Callable[[], LangRun],
+        *,
+        root: Path,
+    ) -> None:
+        self._path = path
+        self._make_lang = make_lang
+        self._root = root
+        self._items: dict[str, dict[str, Any]] = {}
+        self._surfaces: dict[str, frozenset[str]] = {}
+
+    @property
+    def items(self) -> dict[str, dict[str, Any]]:
+        return self._items
+
+    def _analyze(self, dirs: list[str] | None) -> dict[str, dict[str, Any]]:
+        # Sampled match lists would drop different matches on a scoped re-run.
+        lang = make_lang_run(self._make_lang(), LangRunOverrides(full_matches=True))
+        clear_source_file_cache()
+        set_selected_dirs(dirs)
+        try:
+            with contextlib.redirect_stderr(io.StringIO()):
+                findings, _potentials = plan_mod.generate_findings(
+                    self._path,
+                    lang=lang,
+                    options=PlanScanOptions(include_slow=False, profile="objective"),
+                )
+        finally:
+            set_selected_dirs(None)
+        return finding_items(findings)
+
+    def _surface(self, filepath: str) -> frozenset[str]:
+        path = Path(filepath)
+        if not path.is_absolute():
+            path = self._root / path
+        try:
+            return exported_surface(path.read_text(errors="replace"))
+        except OSError:
+            return frozenset()
+
+    def initial(self, files: Iterable[str]) -> FindingsDelta:
+        """Full analysis; every finding is reported as added."""
+        self._surfaces = {f: self._surface(f) for f in files}
+        self._items = self._analyze(None)
+        return FindingsDelta(added=list(self._items.values()))
+
+    def _dependent_dirs(self, signature_changed: set[str]) -> set[str]:
+        if not signature_changed:
+            return set()
+        lang = self._make_lang()
+        builder = getattr(lang, "build_dep_graph", None)
+        if builder is None:
+            return set()
+        graph = builder(self._path) or {}
+        dirs: set[str] = set()
+        for key, node in graph.items():
+            if _project_rel(str(key)) not in signature_changed:
+                continue
+            for importer in node.get("importers", ()) or ():
+                dirs.add(_dir_of(_project_rel(str(importer))))
+        return dirs
+
+    def affected_dirs(self, changed_files: Iterable[str]) -> list[str]:
+        """Packages to re-analyze: changed ones, plus dependents on API changes."""
+        dirs: set[str] = set()
+        signature_changed: set[str] = set()
+        for filepath in changed_files:
+            dirs.add(_dir_of(filepath))
+            surface = self._surface(filepath)
+            if surface != self._surfaces.get(filepath, frozenset()):
+                signature_changed.add(filepath)
+            self._surfaces[filepath] = surface
+        dirs |= self._dependent_dirs(signature_changed)
+        return sorted(dirs)
+
+    def refresh(self, changed_files: Iterable[str]) -> FindingsDelta:
+        """Re-analyze the affected packages and diff against the previous run."""
+        dirs = self.affected_dirs(changed_files)
+        selected = set(dirs)
+        fresh = self._analyze(dirs)
+        previous = self._items
+        kept = {
+            key: item
+            for key, item in previous.items()
+            if _dir_of(item["file"]) not in selected
+        }
+        scoped_fresh = {
+            key: item
+            for key, item in fresh.items()
+            if _dir_of(item["file"]) in selected
+        }
+        self._items = {**kept, **scoped_fresh}
+        added = [item for key, item in scoped_fresh.items() if key not in previous]
+        removed = [
+            item
+            for key, item in previous.items()
+            if _dir_of(item["file"]) in selected and key not in scoped_fresh
+        ]
+        return FindingsDelta(
+            added=added,
+            removed=removed,
+            unchanged=len(scoped_fresh) - len(added),
+            dirs=dirs,
+        )
+
+
+__all__ = ["FindingsDelta", "WatchSession", "exported_surface", "finding_items"]
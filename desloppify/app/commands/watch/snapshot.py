@@ -0,0 +1,79 @@
+"""Polling file watcher with debounce for the watch command.
+
+Polls ``(mtime_ns, size)`` stamps rather than relying on OS notification
+APIs, so it works everywhere without extra dependencies.
+"""
+
+from __future__ import annotations
+
+import os
+import time
+from collections.abc import Callable, Iterable
+from pathlib import Path
+
+FileSnapshot = dict[str, tuple[int, int]]
+
+
+def take_snapshot(files: Iterable[str], root: Path) -> FileSnapshot:
+    """Stamp each file (project-relative or absolute) with mtime and size."""
+    snapshot: FileSnapshot = {}
+    for filepath in files:
+        path = Path(filepath)
+        if not path.is_absolute():
+            path = root / path
+        try:
+            stat = os.stat(path)
+        except OSError:
+            continue
+        snapshot[filepath] = (stat.st_mtime_ns, stat.st_size)
+    return snapshot
+
+
+def diff_snapshots(previous: FileSnapshot, current: FileSnapshot) -> set[str]:
+    """Return files that were added, removed, or modified between snapshots."""
+    changed = set(previous) ^ set(current)
+    changed.update(
+        path
+        for path in set(previous) & set(current)
+        if previous[path] != current[path]
+    )
+    return changed
+
+
+class ChangeWatcher:
+    """Block until files change, then wait for a quiet period (debounce)."""
+
+    def __init__(
+        self,
+        poll: Callable[[], FileSnapshot],
+        *,
+        interval: float,
+        debounce: float,
+        sleep: Callable[[float], None] = time.sleep,
+        clock: Callable[[], float] = time.monotonic,
+    ) -> None:
+        self._poll = poll
+        self._interval = interval
+        self._debounce = debounce
+        self._sleep = sleep
+        self._clock = clock
+        self._snapshot = poll()
+
+    def wait(self) -> set[str]:
+        """Return the set of changed files once edits have settled."""
+        pending: set[str] = set()
+        last_change = 0.0
+        while True:
+            self._sleep(self._interval)
+            current = self._poll()
+            changed = diff_snapshots(self._snapshot, current)
+            self._snapshot = current
+            if changed:
+                pending |= changed
+                last_change = self._clock()
+                continue
+            if pending and self._clock() - last_change >= self._debounce:
+                return pending
+
+
+__all__ = ["ChangeWatcher", "FileSnapshot", "diff_snapshots", "take_snapshot"]
@@ -4,15 +4,21 @@ from __future__ import annotations
 
 import logging
 import sys
+import traceback
 
 from desloppify import file_discovery as file_discovery_mod
 from desloppify import utils as utils_mod
 from desloppify.app.cli_support.parser import create_parser as _create_parser
+from desloppify.app.cli_support.parser import expand_scan_shorthand
+from desloppify.app.commands.helpers.exit_codes import (
+    ERROR_EXIT_CODE,
+    INTERRUPTED_EXIT_CODE,
+)
 from desloppify.app.commands.helpers.lang import LangResolutionError, resolve_lang
 from desloppify.app.commands.helpers.runtime import CommandRuntime
 from desloppify.app.commands.helpers.state import state_path
 from desloppify.core._internal.text_utils import PROJECT_ROOT
-from desloppify.core.config import load_config
+from desloppify.core.config import ConfigError, load_config
 from desloppify.core.runtime_state import runtime_scope
 from desloppify.languages import available_langs
 from desloppify.state import load_state
@@ -87,7 +93,7 @@ def _resolve_default_path(args) -> None:
 
 def _load_shared_runtime(args) -> None:
     """Load config/state and attach shared objects to parsed args."""
-    config = load_config()
+    config = load_config(strict=True)
 
     state_file = state_path(args)
     state = load_state(state_file)
@@ -124,7 +130,7 @@ def main() -> None:
                 )
 
     parser = create_parser()
-    args = parser.parse_args()
+    args = parser.parse_args(expand_scan_shorthand(sys.argv[1:]))
     if args.command == "help":
         _handle_help_command(args, parser)
         return
@@ -138,10 +144,17 @@ def main() -> None:
             handler(args)
     except LangResolutionError as exc:
         print(colorize(f"  {exc.message}", "red"), file=sys.stderr)
-        sys.exit(1)
+        sys.exit(ERROR_EXIT_CODE)
+    except ConfigError as exc:
+        print(colorize(f"  Invalid config: {exc}", "red"), file=sys.stderr)
+        sys.exit(ERROR_EXIT_CODE)
     except KeyboardInterrupt:
         print("\nInterrupted.")
-        sys.exit(1)
+        sys.exit(INTERRUPTED_EXIT_CODE)
+    except Exception as exc:  # noqa: BLE001 - exit 2, never the findings code
+        traceback.print_exc()
+        print(colorize(f"  Internal error: {exc}", "red"), file=sys.stderr)
+        sys.exit(ERROR_EXIT_CODE)
 
 
 if __name__ == "__main__":
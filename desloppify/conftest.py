@@ -6,6 +6,7 @@ from pathlib import Path
 
 import pytest
 
+from desloppify.core.result_cache import CACHE_DIR_ENV
 from desloppify.core.runtime_state import RuntimeContext, runtime_scope
 from desloppify.file_discovery import _clear_source_file_cache
 
@@ -18,3 +19,9 @@ def set_project_root(tmp_path: Path):
         _clear_source_file_cache()
         yield tmp_path
         _clear_source_file_cache()
+
+
+@pytest.fixture(autouse=True)
+def _isolated_result_cache(tmp_path_factory, monkeypatch):
+    """Keep scans run by tests out of the user's real result cache."""
+    monkeypatch.setenv(CACHE_DIR_ENV, str(tmp_path_factory.mktemp("result-cache")))
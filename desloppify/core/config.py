@@ -17,6 +17,11 @@ CONFIG_FILE = PROJECT_ROOT / ".desloppify" / "config.json"
 logger = logging.getLogger(__name__)
 MIN_TARGET_STRICT_SCORE = 0
 MAX_TARGET_STRICT_SCORE = 100
+SEVERITY_LEVELS = ("low", "medium", "high")
+
+
+class ConfigError(ValueError):
+    """An invalid config value; the message points at the config file line."""
 
 
 @dataclass(frozen=True)
@@ -81,6 +86,22 @@ CONFIG_SCHEMA: dict[str, ConfigKey] = {
     "languages": ConfigKey(
         dict, {}, "Language-specific settings {lang_name: {key: value}}"
     ),
+    "finding_subsumes": ConfigKey(
+        dict,
+        {},
+        "Rule subsumption overrides {rule: [rules it covers]} for merging "
+        "findings on the same lines ([] = drop a built-in entry)",
+    ),
+    "fail_severity": ConfigKey(
+        str,
+        "medium",
+        "Lowest severity (low/medium/high) of an open finding that makes scan exit 1",
+    ),
+    "staged_fail_severity": ConfigKey(
+        str,
+        "medium",
+        "Lowest severity (low/medium/high) that makes scan --staged fail",
+    ),
 }
 
 
@@ -99,17 +120,24 @@ def _coerce_target_strict_score(value: object) -> tuple[int, bool]:
     return parsed, valid
 
 
-def load_config(path: Path | None = None) -> dict[str, Any]:
+def load_config(path: Path | None = None, *, strict: bool = False) -> dict[str, Any]:
     """Load config from disk, auto-migrating from state files if needed.
 
     Fills missing keys with defaults. If no config.json exists, attempts
-    migration from state-*.json files.
+    migration from state-*.json files. An unreadable file loads as defaults,
+    or raises ConfigError with ``strict`` (the CLI, which then exits 2).
     """
     p = path or CONFIG_FILE
     if p.exists():
         try:
             config = json.loads(p.read_text())
-        except (json.JSONDecodeError, UnicodeDecodeError, OSError):
+        except (json.JSONDecodeError, UnicodeDecodeError, OSError) as exc:
+            if strict:
+                raise ConfigError(f"{p}: cannot be read as JSON ({exc})") from exc
+            config = {}
+        if not isinstance(config, dict):
+            if strict:
+                raise ConfigError(f"{p}: expected a JSON object")
             config = {}
     else:
         # First run — try migrating from state files
@@ -147,6 +175,87 @@ def save_config(config: dict, path: Path | None = None) -> None:
     safe_write_text(p, json.dumps(config, indent=2) + "\n")
 
 
+def config_line(keys: tuple[str | int, ...], path: Path | None = None) -> int | None:
+    """1-based line of the value at ``keys`` (object keys, list indexes) in the file.
+
+    None when the file is unreadable or has no such value.
+    """
+    p = path or CONFIG_FILE
+    try:
+        text = p.read_text()
+    except (OSError, UnicodeDecodeError):
+        return None
+    try:
+        index = _json_value_offset(text, keys)
+    except (ValueError, IndexError):
+        return None
+    return None if index is None else text.count("\n", 0, index) + 1
+
+
+def did_you_mean(name: str, known: object) -> str:
+    """``"; did you mean 'x'?"`` for the ``known`` name closest to ``name``, else ``""``.
+
+    Only names within a third of ``name``'s length in edit distance count, and
+    never one that would need every character changed (``q`` is no typo of ``s``).
+    """
+    scored = sorted((_edit_distance(name, k), k) for k in known if isinstance(k, str))
+    if not scored or scored[0][0] > max(1, len(name) // 3) or scored[0][0] >= len(name):
+        return ""
+    return f"; did you mean {scored[0][1]!r}?"
+
+
+def _edit_distance(a: str, b: str) -> int:
+    """Levenshtein distance: single-character inserts, deletes and substitutions."""
+    previous = list(range(len(b) + 1))
+    for i, ca in enumerate(a, 1):
+        current = [i]
+        for j, cb in enumerate(b, 1):
+            current.append(min(previous[j] + 1, current[j - 1] + 1, previous[j - 1] + (ca != cb)))
+        previous = current
+    return previous[-1]
+
+
+def _json_value_offset(text: str, keys: tuple[str | int, ...]) -> int | None:
+    decoder = json.JSONDecoder()
+
+    def skip(index: int) -> int:
+        while index < len(text) and text[index] in " \t\r\n":
+            index += 1
+        return index
+
+    def next_item(index: int) -> int:
+        _value, index = decoder.raw_decode(text, index)
+        index = skip(index)
+        return skip(index + 1) if text[index] == "," else index
+
+    index = skip(0)
+    for key in keys:
+        if text[index] == "{" and isinstance(key, str):
+            index = skip(index + 1)
+            found = None
+            while found is None and text[index] != "}":
+                name, index = json.decoder.scanstring(text, index + 1)
+                index = skip(skip(index) + 1)
+                if name == key:
+                    found = index
+                else:
+                    index = next_item(index)
+            if found is None:
+                return None
+            index = found
+        elif text[index] == "[" and isinstance(key, int):
+            index = skip(index + 1)
+            for _ in range(key):
+                if text[index] == "]":
+                    return None
+                index = next_item(index)
+            if text[index] == "]":
+                return None
+        else:
+            return None
+    return index
+
+
 def add_ignore_pattern(config: dict, pattern: str) -> None:
     """Append a pattern to the ignore list (deduplicates)."""
     ignores = config.setdefault("ignore", [])
@@ -214,6 +323,10 @@ def set_config_value(config: dict, key: str, raw: str) -> None:
     elif schema.type is str:
         if key == "badge_path":
             config[key] = _validate_badge_path(raw)
+        elif key in ("fail_severity", "staged_fail_severity") and raw not in SEVERITY_LEVELS:
+            raise ValueError(
+                f"Expected one of {', '.join(SEVERITY_LEVELS)} for {key}, got: {raw}"
+            )
         else:
             config[key] = raw
     elif schema.type is list:
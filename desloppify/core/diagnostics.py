@@ -0,0 +1,193 @@
+"""Self-diagnostics for slow runs: profiles, traces, and per-rule timings.
+
+``diagnostics_scope`` wraps a command run. Depending on what was asked for
+it records a CPU profile (cProfile/pstats, readable with ``python -m pstats``,
+snakeviz, or ``gprof2dot``), a memory snapshot (``tracemalloc.Snapshot``,
+reloadable with ``tracemalloc.Snapshot.load``), and a Chrome trace-event
+JSON of phases and packages (open in Perfetto or ``chrome://tracing``).
+With ``timings`` on, detectors report wall time per rule and per package
+through ``current_diagnostics()``; ``RunTimings.as_dict()`` is what JSON
+output carries under ``diagnostics``.
+"""
+
+from __future__ import annotations
+
+import cProfile
+import json
+import os
+import threading
+import time
+import tracemalloc
+from collections import defaultdict
+from collections.abc import Iterator, Mapping
+from contextlib import contextmanager
+from contextvars import ContextVar
+from dataclasses import dataclass, field
+from pathlib import Path
+from typing import Any
+
+TOP_N = 20
+
+
+class RunTimings:
+    """Accumulated wall time per rule, per package, and per phase."""
+
+    def __init__(self) -> None:
+        self.rules: defaultdict[str, float] = defaultdict(float)
+        self.packages: defaultdict[str, float] = defaultdict(float)
+        self.phases: defaultdict[str, float] = defaultdict(float)
+
+    def add_rules(self, seconds: Mapping[str, float]) -> None:
+        for rule, secs in seconds.items():
+            self.rules[rule] += secs
+
+    def add_package(self, package: str, seconds: float) -> None:
+        self.packages[package] += seconds
+
+    def add_phase(self, label: str, seconds: float) -> None:
+        self.phases[label] += seconds
+
+    @staticmethod
+    def _top(values: Mapping[str, float], n: int) -> list[dict[str, Any]]:
+        ranked = sorted(values.items(), key=lambda item: (-item[1], item[0]))[:n]
+        return [{"name": name, "seconds": round(secs, 6)} for name, secs in ranked]
+
+    def as_dict(self, top: int = TOP_N) -> dict[str, Any]:
+        return {
+            "phases": self._top(self.phases, len(self.phases)),
+            "rules": self._top(self.rules, top),
+            "packages": self._top(self.packages, top),
+        }
+
+    def render(self, top: int = TOP_N) -> str:
+        """Plain-text report of phase times and the ``top`` rules and packages."""
+        report = self.as_dict(top)
+        sections = [
+            ("Phases", report["phases"]),
+            (f"Rules (top {top})", report["rules"]),
+            (f"Packages (top {top})", report["packages"]),
+        ]
+        lines = ["Timings"]
+        for title, rows in sections:
+            if not rows:
+                continue
+            lines.append(f"  {title}:")
+            lines.extend(f"    {row['seconds'] * 1000:10.1f} ms  {row['name']}" for row in rows)
+        return "\n".join(lines)
+
+
+class TraceRecorder:
+    """Collects complete ("X") events in the Chrome trace-event format."""
+
+    def __init__(self) -> None:
+        self.origin = time.perf_counter()
+        self.events: list[dict[str, Any]] = []
+        self._lock = threading.Lock()
+
+    def complete(
+        self, name: str, category: str, start: float, seconds: float, *, tid: int = 0
+    ) -> None:
+        """Record a span that began at ``perf_counter()`` value ``start``."""
+        event = {
+            "name": name,
+            "cat": category,
+            "ph": "X",
+            "ts": round((start - self.origin) * 1e6, 1),
+            "dur": round(seconds * 1e6, 1),
+            "pid": os.getpid(),
+            "tid": tid or threading.get_ident(),
+        }
+        with self._lock:
+            self.events.append(event)
+
+    def write(self, path: Path) -> None:
+        path.write_text(json.dumps({"traceEvents": self.events, "displayTimeUnit": "ms"}))
+
+
+@dataclass
+class RunDiagnostics:
+    """What a run records; each part is None when its flag was not given."""
+
+    timings: RunTimings | None = None
+    trace: TraceRecorder | None = None
+    outputs: list[str] = field(default_factory=list)
+
+    @contextmanager
+    def span(self, name: str, category: str) -> Iterator[None]:
+        """Time a block into the phase timings and the trace, as enabled."""
+        if self.timings is None and self.trace is None:
+            yield
+            return
+        start = time.perf_counter()
+        try:
+            yield
+        finally:
+            seconds = time.perf_counter() - start
+            if self.timings is not None and category == "phase":
+                self.timings.add_phase(name, seconds)
+            if self.trace is not None:
+                self.trace.complete(name, category, start, seconds)
+
+
+_DIAGNOSTICS: ContextVar[RunDiagnostics | None] = ContextVar(
+    "desloppify_diagnostics", default=None
+)
+
+
+def current_diagnostics() -> RunDiagnostics | None:
+    """The active run's diagnostics, or None outside ``diagnostics_scope``."""
+    return _DIAGNOSTICS.get()
+
+
+@contextmanager
+def diagnostics_scope(
+    *,
+    cpuprofile: str | None = None,
+    memprofile: str | None = None,
+    trace: str | None = None,
+    timings: bool = False,
+) -> Iterator[RunDiagnostics | None]:
+    """Record the requested profiles around a run and write them on exit.
+
+    Yields None, and costs nothing, when no diagnostic was requested.
+    """
+    if not (cpuprofile or memprofile or trace or timings):
+        yield None
+        return
+    diagnostics = RunDiagnostics(
+        timings=RunTimings() if timings else None,
+        trace=TraceRecorder() if trace else None,
+    )
+    profiler = cProfile.Profile() if cpuprofile else None
+    started_tracemalloc = bool(memprofile) and not tracemalloc.is_tracing()
+    if started_tracemalloc:
+        tracemalloc.start()
+    token = _DIAGNOSTICS.set(diagnostics)
+    if profiler is not None:
+        profiler.enable()
+    try:
+        yield diagnostics
+    finally:
+        if profiler is not None:
+            profiler.disable()
+            profiler.dump_stats(cpuprofile)
+            diagnostics.outputs.append(f"CPU profile: {cpuprofile}")
+        if memprofile:
+            tracemalloc.take_snapshot().dump(memprofile)
+            diagnostics.outputs.append(f"Memory snapshot: {memprofile}")
+            if started_tracemalloc:
+                tracemalloc.stop()
+        if diagnostics.trace is not None:
+            diagnostics.trace.write(Path(trace))
+            diagnostics.outputs.append(f"Trace: {trace}")
+        _DIAGNOSTICS.reset(token)
+
+
+__all__ = [
+    "TOP_N",
+    "RunDiagnostics",
+    "RunTimings",
+    "TraceRecorder",
+    "current_diagnostics",
+    "diagnostics_scope",
+]
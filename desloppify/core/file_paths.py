@@ -2,8 +2,12 @@
 
 from __future__ import annotations
 
+import functools
 import os
+import posixpath
+import re
 import tempfile
+from dataclasses import dataclass
 from pathlib import Path
 
 from desloppify.core._internal.text_utils import get_project_root
@@ -27,6 +31,67 @@ def matches_exclusion(rel_path: str, exclusion: str) -> bool:
     return False
 
 
+def _glob_segment(segment: str) -> str:
+    out: list[str] = []
+    index = 0
+    while index < len(segment):
+        ch = segment[index]
+        close = segment.find("}", index) if ch == "{" else -1
+        if ch == "*":
+            out.append("[^/]*")
+        elif ch == "?":
+            out.append("[^/]")
+        elif close > index:
+            choices = segment[index + 1 : close].split(",")
+            out.append("(?:" + "|".join(_glob_segment(c) for c in choices) + ")")
+            index = close
+        else:
+            out.append(re.escape(ch))
+        index += 1
+    return "".join(out)
+
+
+@functools.lru_cache(maxsize=256)
+def glob_regex(pattern: str) -> re.Pattern[str]:
+    """The regex for a ``doublestar`` glob over slash-separated relative paths.
+
+    ``*`` and ``?`` stay within one path segment, a whole ``**`` segment
+    matches any number of them (none included), and ``{a,b}`` matches
+    either alternative. The glob must match the whole path.
+    """
+    segments = normalize_path_separators(pattern).removeprefix("./").strip("/").split("/")
+    parts: list[str] = []
+    for index, segment in enumerate(segments):
+        last = index == len(segments) - 1
+        if segment == "**":
+            parts.append(".*" if last else "(?:[^/]+/)*")
+        else:
+            parts.append(_glob_segment(segment) + ("" if last else "/"))
+    return re.compile("^" + "".join(parts) + "$")
+
+
+@dataclass(frozen=True)
+class PathGlobs:
+    """``--only`` and ``--skip`` globs, matched against paths relative to ``root``.
+
+    ``root`` is the scan path, relative to the project root (``.`` for
+    the project itself).
+    """
+
+    only: tuple[str, ...] = ()
+    skip: tuple[str, ...] = ()
+    root: str = "."
+
+    def selects(self, rel_path: str) -> bool:
+        """Whether a project-relative path passes both filters."""
+        path = normalize_path_separators(rel_path)
+        if self.root not in ("", "."):
+            path = posixpath.relpath(path, self.root)
+        if self.only and not any(glob_regex(g).match(path) for g in self.only):
+            return False
+        return not any(glob_regex(g).match(path) for g in self.skip)
+
+
 def normalize_path_separators(path: str) -> str:
     return path.replace("\\", "/")
 
@@ -71,6 +136,8 @@ def safe_write_text(filepath: str | Path, content: str) -> None:
 
 
 __all__ = [
+    "PathGlobs",
+    "glob_regex",
     "matches_exclusion",
     "normalize_path_separators",
     "rel",
@@ -0,0 +1,98 @@
+"""A live progress line on stderr while a scan works through its files.
+
+``progress_scope`` wraps a command run; detectors that go package by
+package find the line through ``current_progress()``, start it with the
+number of files they are about to analyze, advance it as each package
+finishes, and clear it when they are done, so nothing of it is left
+between the phase headers and the results. The line is redrawn in place
+at most every ``interval`` seconds. Only stderr is written; stdout stays
+clean for JSON and the other formatters.
+"""
+
+from __future__ import annotations
+
+import shutil
+import sys
+import time
+from collections.abc import Callable, Iterator
+from contextlib import contextmanager
+from contextvars import ContextVar
+from typing import TextIO
+
+_CLEAR_LINE = "\r\x1b[K"
+
+
+class ScanProgress:
+    """Files done out of files to do, and the package last finished."""
+
+    def __init__(
+        self,
+        stream: TextIO,
+        *,
+        interval: float = 0.1,
+        clock: Callable[[], float] = time.monotonic,
+    ) -> None:
+        self.stream = stream
+        self.interval = interval
+        self.clock = clock
+        self.done = 0
+        self.total = 0
+        self._drawn_at: float | None = None
+
+    def start(self, total: int) -> None:
+        """Begin counting toward ``total`` files."""
+        self.clear()
+        self.total = total
+
+    def advance(self, files: int, package: str) -> None:
+        """Mark ``files`` of ``package`` analyzed, redrawing when it is time to."""
+        self.done += files
+        now = self.clock()
+        if self._drawn_at is not None and now - self._drawn_at < self.interval:
+            return
+        self._drawn_at = now
+        line = f"  {self.done}/{self.total} files  {package or '.'}"
+        width = shutil.get_terminal_size().columns - 1
+        if len(line) > width > 0:
+            line = line[: width - 1] + "…"
+        self.stream.write(_CLEAR_LINE + line)
+        self.stream.flush()
+
+    def clear(self) -> None:
+        """Erase the line, if one was drawn, and reset the counts."""
+        if self._drawn_at is not None:
+            self.stream.write(_CLEAR_LINE)
+            self.stream.flush()
+        self.done = self.total = 0
+        self._drawn_at = None
+
+
+_PROGRESS: ContextVar[ScanProgress | None] = ContextVar("desloppify_progress", default=None)
+
+
+def current_progress() -> ScanProgress | None:
+    """The active run's progress line, or None when it is not shown."""
+    return _PROGRESS.get()
+
+
+@contextmanager
+def progress_scope(
+    *, enabled: bool, stream: TextIO | None = None
+) -> Iterator[ScanProgress | None]:
+    """Show a progress line on ``stream`` (stderr) for the run when ``enabled``.
+
+    Yields None, and nothing is written, when it is not.
+    """
+    if not enabled:
+        yield None
+        return
+    progress = ScanProgress(stream if stream is not None else sys.stderr)
+    token = _PROGRESS.set(progress)
+    try:
+        yield progress
+    finally:
+        progress.clear()
+        _PROGRESS.reset(token)
+
+
+__all__ = ["ScanProgress", "current_progress", "progress_scope"]
@@ -0,0 +1,115 @@
+"""On-disk cache of per-package detector results.
+
+Entries live under ``user_cache_dir()`` as ``<namespace>/<key[:2]>/<key>.json``.
+Keys are content hashes built by the caller, so an entry never needs to be
+invalidated in place: any change to an input produces a different key.
+The cache is best-effort; unreadable or unwritable entries count as misses.
+"""
+
+from __future__ import annotations
+
+import hashlib
+import json
+import os
+import shutil
+import sys
+import tempfile
+from collections.abc import Iterable
+from dataclasses import dataclass
+from importlib import metadata as importlib_metadata
+from pathlib import Path
+from typing import Any
+
+CACHE_DIR_ENV = "DESLOPPIFY_CACHE_DIR"
+
+
+def user_cache_dir() -> Path:
+    """Per-user cache root, laid out like Go's ``os.UserCacheDir()/desloppify``.
+
+    ``DESLOPPIFY_CACHE_DIR`` overrides the location entirely.
+    """
+    override = os.environ.get(CACHE_DIR_ENV)
+    if override:
+        return Path(override)
+    if sys.platform == "win32":
+        base = Path(os.environ.get("LOCALAPPDATA") or Path.home() / "AppData" / "Local")
+    elif sys.platform == "darwin":
+        base = Path.home() / "Library" / "Caches"
+    else:
+        base = Path(os.environ.get("XDG_CACHE_HOME") or Path.home() / ".cache")
+    return base / "desloppify"
+
+
+def tool_version() -> str:
+    try:
+        return importlib_metadata.version("desloppify")
+    except importlib_metadata.PackageNotFoundError:
+        return "dev"
+
+
+def content_hash(data: str | bytes) -> str:
+    raw = data.encode("utf-8", "surrogateescape") if isinstance(data, str) else data
+    return hashlib.sha256(raw).hexdigest()
+
+
+@dataclass
+class CacheStats:
+    hits: int = 0
+    misses: int = 0
+
+
+class ResultCache:
+    """JSON results keyed by caller-supplied content hashes."""
+
+    def __init__(self, root: Path | None = None) -> None:
+        self.root = root if root is not None else user_cache_dir()
+        self.stats = CacheStats()
+
+    def key(self, namespace: str, parts: Iterable[str]) -> str:
+        """Hash ``parts`` together with the namespace and tool version."""
+        digest = hashlib.sha256()
+        for part in (tool_version(), namespace, *parts):
+            digest.update(part.encode("utf-8", "surrogateescape"))
+            digest.update(b"\0")
+        return digest.hexdigest()
+
+    def _path(self, namespace: str, key: str) -> Path:
+        return self.root / namespace / key[:2] / f"{key}.json"
+
+    def get(self, namespace: str, key: str) -> Any | None:
+        try:
+            value = json.loads(self._path(namespace, key).read_text())
+        except (OSError, ValueError):
+            self.stats.misses += 1
+            return None
+        self.stats.hits += 1
+        return value
+
+    def put(self, namespace: str, key: str, value: Any) -> None:
+        path = self._path(namespace, key)
+        try:
+            path.parent.mkdir(parents=True, exist_ok=True)
+            fd, tmp = tempfile.mkstemp(dir=path.parent, suffix=".tmp")
+            with os.fdopen(fd, "w") as handle:
+                json.dump(value, handle)
+            os.replace(tmp, path)
+        except OSError:
+            return
+
+    def clean(self) -> int:
+        """Delete every entry; return how many were removed."""
+        if not self.root.is_dir():
+            return 0
+        removed = sum(1 for _ in self.root.rglob("*.json"))
+        shutil.rmtree(self.root, ignore_errors=True)
+        return removed
+
+
+__all__ = [
+    "CACHE_DIR_ENV",
+    "CacheStats",
+    "ResultCache",
+    "content_hash",
+    "tool_version",
+    "user_cache_dir",
+]
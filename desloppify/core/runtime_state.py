@@ -2,10 +2,19 @@
 
 from __future__ import annotations
 
+import threading
 from contextlib import contextmanager
 from contextvars import ContextVar
 from dataclasses import dataclass, field
 from pathlib import Path
+from typing import TYPE_CHECKING
+
+if TYPE_CHECKING:
+    from desloppify.core.file_paths import PathGlobs
+
+
+class ScanCancelled(RuntimeError):
+    """Raised when a run's cancel event is set before it completes."""
 
 
 class FileTextCache:
@@ -60,12 +69,21 @@ class RuntimeContext:
     """Mutable runtime container for exclusion and cache state."""
 
     exclusions: tuple[str, ...] = ()
+    selected_dirs: tuple[str, ...] | None = None
+    report_dirs: tuple[str, ...] | None = None
+    report_lines: dict[str, frozenset[int] | None] | None = None
+    selected_files: frozenset[str] | None = None
+    build_tags: tuple[str, ...] = ()
+    vendored_paths: tuple[str, ...] = ()
+    include_vendored: bool = False
+    path_globs: PathGlobs | None = None
     project_root: Path | None = None
     file_text_cache: FileTextCache = field(default_factory=FileTextCache)
     cache_enabled: bool = False
     source_file_cache: SourceFileCache = field(
         default_factory=lambda: SourceFileCache(max_entries=16)
     )
+    cancel: threading.Event | None = None
 
 
 _PROCESS_RUNTIME_CONTEXT = RuntimeContext()
@@ -90,6 +108,16 @@ def current_runtime_context() -> RuntimeContext:
     return _PROCESS_RUNTIME_CONTEXT
 
 
+def raise_if_cancelled() -> None:
+    """Raise ``ScanCancelled`` once the active run's cancel event is set.
+
+    Long phases call this between units of work (a package, a module).
+    """
+    cancel = current_runtime_context().cancel
+    if cancel is not None and cancel.is_set():
+        raise ScanCancelled("scan cancelled")
+
+
 @contextmanager
 def runtime_scope(runtime: RuntimeContext | None = None):
     """Run code with an isolated runtime context."""
@@ -104,8 +132,10 @@ def runtime_scope(runtime: RuntimeContext | None = None):
 __all__ = [
     "FileTextCache",
     "RuntimeContext",
+    "ScanCancelled",
     "SourceFileCache",
     "current_runtime_context",
     "make_runtime_context",
+    "raise_if_cancelled",
     "runtime_scope",
 ]
@@ -5,6 +5,8 @@ from __future__ import annotations
 from dataclasses import dataclass
 from typing import Any
 
+from desloppify.core.file_paths import PathGlobs
+
 __all__ = [
     "MergeScanOptions",
     "merge_scan",
@@ -40,6 +42,9 @@ class MergeScanOptions:
     scan_path: str | None = None
     force_resolve: bool = False
     exclude: tuple[str, ...] = ()
+    selected_dirs: tuple[str, ...] | None = None
+    path_globs: PathGlobs | None = None
+    changed_lines: dict[str, frozenset[int] | None] | None = None
     potentials: dict[str, int] | None = None
     merge_potentials: bool = False
     codebase_metrics: dict[str, Any] | None = None
@@ -111,6 +116,9 @@ def merge_scan(
         lang=resolved_options.lang,
         scan_path=resolved_options.scan_path,
         exclude=resolved_options.exclude,
+        selected_dirs=resolved_options.selected_dirs,
+        path_globs=resolved_options.path_globs,
+        changed_lines=resolved_options.changed_lines,
     )
 
     _recompute_stats(
@@ -2,6 +2,9 @@
 
 from __future__ import annotations
 
+import posixpath
+
+from desloppify.core.file_paths import PathGlobs
 from desloppify.engine._state.filtering import matched_ignore_pattern
 from desloppify.file_discovery import matches_exclusion
 
@@ -46,6 +49,17 @@ def find_suspect_detectors(
     return suspect
 
 
+def _on_changed_line(
+    finding: dict, changed_lines: dict[str, frozenset[int] | None]
+) -> bool:
+    if finding["file"] not in changed_lines:
+        return False
+    lines = changed_lines[finding["file"]]
+    detail = finding.get("detail") if isinstance(finding.get("detail"), dict) else {}
+    line = detail.get("line")
+    return lines is None or not isinstance(line, int) or not line or line in lines
+
+
 def auto_resolve_disappeared(
     existing: dict,
     current_ids: set[str],
@@ -55,9 +69,18 @@ def auto_resolve_disappeared(
     lang: str | None,
     scan_path: str | None,
     exclude: tuple[str, ...] = (),
+    selected_dirs: tuple[str, ...] | None = None,
+    path_globs: PathGlobs | None = None,
+    changed_lines: dict[str, frozenset[int] | None] | None = None,
 ) -> tuple[int, int, int]:
     """Auto-resolve open/wontfix/fixed/false_positive findings absent from scan.
 
+    When ``selected_dirs`` is set (changed-files scans), findings outside those
+    directories were not re-analyzed and count as out of scope, as are
+    findings in files that ``path_globs`` (``--only``, ``--skip``) leaves out.
+    With ``changed_lines`` (``--diff-base``), only findings on a changed line
+    of a changed file, or file-level ones in it, are in scope.
+
     Returns (resolved, skipped_other_lang, skipped_out_of_scope).
     """
     resolved = skipped_other_lang = skipped_out_of_scope = 0
@@ -84,6 +107,20 @@ def auto_resolve_disappeared(
                 skipped_out_of_scope += 1
                 continue
 
+        if selected_dirs is not None and (
+            posixpath.dirname(previous["file"]) or "."
+        ) not in selected_dirs:
+            skipped_out_of_scope += 1
+            continue
+
+        if path_globs is not None and not path_globs.selects(previous["file"]):
+            skipped_out_of_scope += 1
+            continue
+
+        if changed_lines is not None and not _on_changed_line(previous, changed_lines):
+            skipped_out_of_scope += 1
+            continue
+
         if exclude and any(matches_exclusion(previous["file"], ex) for ex in exclude):
             continue
 
@@ -158,8 +195,9 @@ def upsert_findings(
             summary=finding["summary"],
             detail=finding.get("detail", {}),
         )
-        if "zone" in finding:
-            previous["zone"] = finding["zone"]
+        for key in ("zone", "module", "owners", "fingerprint", "snippet_hash"):
+            if key in finding:
+                previous[key] = finding[key]
         if lang and not previous.get("lang"):
             previous["lang"] = lang
 
@@ -67,6 +67,7 @@ class Finding(TypedDict):
     resolution_attestation: NotRequired[dict[str, str | bool | None]]
     lang: NotRequired[str]
     zone: NotRequired[str]
+    owners: NotRequired[list[str]]
 
 
 class TierStats(TypedDict, total=False):
@@ -85,6 +86,7 @@ class StateStats(TypedDict, total=False):
     wontfix: int
     false_positive: int
     by_tier: dict[str, TierStats]
+    by_owner: dict[str, int]
 
 
 class DimensionScore(TypedDict, total=False):
@@ -5,12 +5,14 @@ from __future__ import annotations
 from copy import deepcopy
 
 __all__ = [
+    "owner_scoped_state",
     "suppression_metrics",
 ]
 
 from desloppify.engine._scoring.policy.core import matches_target_score
 from desloppify.engine._state.filtering import path_scoped_findings
 from desloppify.engine._state.schema import StateModel, ensure_state_defaults
+from desloppify.engine.policy.owners import owned_by, owner_counts
 from desloppify.languages._framework.base.types import ScanCoverageRecord
 
 _EMPTY_COUNTERS = ("open", "fixed", "auto_resolved", "wontfix", "false_positive")
@@ -356,6 +358,38 @@ def _update_objective_health(
     state.update(_aggregate_scores(state["dimension_scores"], compute_health_score))
 
 
+def _finding_stats(findings: dict) -> dict:
+    counters, tier_stats = _count_findings(findings)
+    stats = {
+        "total": sum(counters.values()),
+        **counters,
+        "by_tier": {
+            str(tier): tier_counts for tier, tier_counts in sorted(tier_stats.items())
+        },
+    }
+    by_owner = owner_counts(findings.values())
+    if by_owner:
+        stats["by_owner"] = by_owner
+    return stats
+
+
+def owner_scoped_state(state: StateModel, owner: str) -> StateModel:
+    """A shallow copy of ``state`` with only ``owner``'s findings, and their stats.
+
+    The copy records the filter as ``owner_scope`` for renderers.
+    Scores stay those of the whole scan. ``owner`` matches as in ``owned_by``
+    (``unowned`` selects findings no CODEOWNERS rule owns).
+    """
+    findings = {
+        finding_id: finding
+        for finding_id, finding in (state.get("findings") or {}).items()
+        if owned_by(finding, owner)
+    }
+    scoped = {**state, "findings": findings, "owner_scope": owner}
+    scoped["stats"] = _finding_stats(path_scoped_findings(findings, state.get("scan_path")))
+    return scoped
+
+
 def _recompute_stats(
     state: StateModel,
     scan_path: str | None = None,
@@ -365,14 +399,7 @@ def _recompute_stats(
     """Recompute stats and canonical health scores from findings."""
     ensure_state_defaults(state)
     findings = path_scoped_findings(state["findings"], scan_path)
-    counters, tier_stats = _count_findings(findings)
-    state["stats"] = {
-        "total": sum(counters.values()),
-        **counters,
-        "by_tier": {
-            str(tier): tier_counts for tier, tier_counts in sorted(tier_stats.items())
-        },
-    }
+    state["stats"] = _finding_stats(findings)
     _update_objective_health(
         state,
         findings,
@@ -0,0 +1,166 @@
+"""Compare two scan runs: which findings were introduced, resolved, or persist.
+
+A run is a state file (``.desloppify/state-<lang>.json``, whose open findings
+and scores are read) or ``scan --stream`` output (findings only, no score).
+Findings are paired in three passes:
+
+1. the same finding id;
+2. the same ``fingerprint`` (the id without its path) and the same code, or a
+   fingerprint that only one finding on each side has left: the file moved;
+3. the same detector and identical code (``snippet_hash``) under another
+   path: "probably moved", since the id itself changed.
+
+Runs written before fingerprints were stamped get them computed from the id;
+their snippets are unknown, so only passes 1 and 2 apply to them.
+"""
+
+from __future__ import annotations
+
+import json
+from dataclasses import dataclass, field
+from pathlib import Path
+from typing import Any
+
+from desloppify.engine.planning.fingerprint import finding_fingerprint
+
+MOVED = "moved"
+PROBABLY_MOVED = "probably moved"
+
+
+@dataclass
+class Run:
+    """The open findings of one run and its scores (None from stream output)."""
+
+    findings: list[dict[str, Any]]
+    overall_score: float | None = None
+    strict_score: float | None = None
+
+
+@dataclass
+class Comparison:
+    """Findings introduced in, resolved by, and persisting into the new run.
+
+    ``moved`` pairs (old, new, how) for persisting findings whose file changed;
+    they are also counted in ``persisting``.
+    """
+
+    introduced: list[dict[str, Any]] = field(default_factory=list)
+    resolved: list[dict[str, Any]] = field(default_factory=list)
+    persisting: list[dict[str, Any]] = field(default_factory=list)
+    moved: list[tuple[dict[str, Any], dict[str, Any], str]] = field(default_factory=list)
+
+
+def _score(value: object) -> float | None:
+    return float(value) if isinstance(value, int | float) and not isinstance(value, bool) else None
+
+
+def load_run(path: Path) -> Run:
+    """Read a state file or ``scan --stream`` output. Raises ValueError if neither."""
+    text = path.read_text()
+    try:
+        data = json.loads(text)
+    except json.JSONDecodeError:
+        data = None
+    if isinstance(data, dict) and isinstance(data.get("findings"), dict):
+        findings = [
+            f
+            for f in data["findings"].values()
+            if isinstance(f, dict) and f.get("status", "open") == "open" and not f.get("suppressed")
+        ]
+        return Run(findings, _score(data.get("overall_score")), _score(data.get("strict_score")))
+    findings = []
+    for number, line in enumerate(text.splitlines(), start=1):
+        if not line.strip():
+            continue
+        try:
+            record = json.loads(line)
+        except json.JSONDecodeError as exc:
+            raise ValueError(
+                f"{path}:{number}: not a state file or scan --stream output ({exc.msg})"
+            ) from exc
+        if isinstance(record, dict) and "id" in record:
+            findings.append(record)
+    return Run(findings)
+
+
+def _fingerprint(finding: dict[str, Any]) -> str:
+    return str(finding.get("fingerprint") or finding_fingerprint(finding))
+
+
+def _pair(
+    old: list[dict[str, Any]], new: list[dict[str, Any]], key, *, unique: bool = False
+) -> list[tuple[dict[str, Any], dict[str, Any]]]:
+    """Pair findings with equal non-empty ``key``, in order; ``unique`` needs one each."""
+    by_key: dict[str, list[dict[str, Any]]] = {}
+    for finding in old:
+        k = key(finding)
+        if k:
+            by_key.setdefault(k, []).append(finding)
+    counts = {k: sum(1 for f in new if key(f) == k) for k in by_key} if unique else {}
+    pairs = []
+    for finding in new:
+        candidates = by_key.get(key(finding) or "")
+        if not candidates or (unique and (len(candidates) != 1 or counts[key(finding)] != 1)):
+            continue
+        pairs.append((candidates.pop(0), finding))
+    return pairs
+
+
+def compare_runs(old: Run, new: Run) -> Comparison:
+    """Match the findings of ``old`` and ``new`` (see the module docstring)."""
+    result = Comparison()
+    old_left = list(old.findings)
+    new_left = list(new.findings)
+
+    def take(pairs, how: str | None) -> None:
+        for before, after in pairs:
+            old_left.remove(before)
+            new_left.remove(after)
+            result.persisting.append(after)
+            if how is not None and before.get("file") != after.get("file"):
+                result.moved.append((before, after, how))
+
+    take(_pair(old_left, new_left, lambda f: f.get("id")), None)
+    take(
+        _pair(
+            old_left,
+            new_left,
+            lambda f: f"{_fingerprint(f)}:{f['snippet_hash']}" if f.get("snippet_hash") else "",
+        ),
+        MOVED,
+    )
+    take(_pair(old_left, new_left, _fingerprint, unique=True), MOVED)
+    take(
+        _pair(
+            old_left,
+            new_left,
+            lambda f: f"{f.get('detector')}:{f['snippet_hash']}" if f.get("snippet_hash") else "",
+        ),
+        PROBABLY_MOVED,
+    )
+    result.introduced = new_left
+    result.resolved = old_left
+    return result
+
+
+def score_delta(old: Run, new: Run) -> dict[str, dict[str, float | None]]:
+    """``{"overall"|"strict": {"old", "new", "delta"}}``; None where a run has no score."""
+    rows = {}
+    for name, before, after in (
+        ("overall", old.overall_score, new.overall_score),
+        ("strict", old.strict_score, new.strict_score),
+    ):
+        delta = round(after - before, 1) if before is not None and after is not None else None
+        rows[name] = {"old": before, "new": after, "delta": delta}
+    return rows
+
+
+__all__ = [
+    "MOVED",
+    "PROBABLY_MOVED",
+    "Comparison",
+    "Run",
+    "compare_runs",
+    "load_run",
+    "score_delta",
+]
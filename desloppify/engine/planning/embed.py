@@ -0,0 +1,173 @@
+"""In-process scan runner for embedding desloppify in other tooling."""
+
+from __future__ import annotations
+
+import contextlib
+import io
+import json
+import threading
+from collections.abc import Callable, Iterator
+from dataclasses import dataclass, field
+from pathlib import Path
+from typing import Any, TextIO
+
+from desloppify.core.runtime_state import RuntimeContext, ScanCancelled, runtime_scope
+from desloppify.engine.planning.scan import (
+    PhaseResult,
+    PlanScanOptions,
+    iter_phase_results,
+)
+from desloppify.file_discovery import set_exclusions
+from desloppify.languages import auto_detect_lang, get_lang
+from desloppify.languages._framework.runtime import LangRunOverrides, make_lang_run
+from desloppify.state import Finding
+
+
+@dataclass(frozen=True)
+class Options:
+    """What to scan and how; mirrors the ``scan`` command's knobs."""
+
+    path: str | Path = "."
+    lang: str | None = None
+    rules: frozenset[str] | None = None
+    """Detector names or smell ids to keep; ``None`` keeps everything."""
+    config: dict[str, Any] | None = None
+    """Config in ``config.json`` shape; only ``languages.<lang>`` is read."""
+    exclusions: tuple[str, ...] = ()
+    profile: str = "full"
+    include_slow: bool = True
+    syntax_only: bool = False
+    verbose: bool = False
+    """Forward phase progress to stderr instead of discarding it."""
+
+
+@dataclass
+class Report:
+    """Findings for one project, as produced by ``scan``."""
+
+    lang: str
+    path: str
+    findings: list[Finding] = field(default_factory=list)
+    potentials: dict[str, int] = field(default_factory=dict)
+
+    def to_dict(self) -> dict[str, Any]:
+        return {
+            "lang": self.lang,
+            "path": self.path,
+            "findings": self.findings,
+            "potentials": self.potentials,
+        }
+
+
+def write_json(report: Report, stream: TextIO) -> None:
+    """Write ``report`` with the same finding shape ``scan --stdin`` emits."""
+    json.dump(report.to_dict(), stream, indent=2, default=str)
+    stream.write("\n")
+
+
+def _rule_matches(finding: Finding, rules: frozenset[str]) -> bool:
+    detail = finding.get("detail") if isinstance(finding.get("detail"), dict) else {}
+    return finding.get("detector") in rules or detail.get("smell_id") in rules
+
+
+def _lang_name(options: Options, root: Path) -> str:
+    name = options.lang or auto_detect_lang(root)
+    if name is None:
+        raise ValueError(f"could not detect a language for {root}")
+    return name
+
+
+def _make_lang_run(name: str, options: Options):
+    lang_cfg = get_lang(name)
+    languages = (options.config or {}).get("languages")
+    raw = languages.get(lang_cfg.name) if isinstance(languages, dict) else None
+    return make_lang_run(
+        lang_cfg,
+        overrides=LangRunOverrides(
+            runtime_settings=lang_cfg.normalize_settings(
+                raw if isinstance(raw, dict) else {}
+            )
+        ),
+    )
+
+
+@contextlib.contextmanager
+def _phase_scope(runtime: RuntimeContext, root: Path, *, verbose: bool):
+    progress = (
+        contextlib.nullcontext()
+        if verbose
+        else contextlib.redirect_stderr(io.StringIO())
+    )
+    with runtime_scope(runtime), contextlib.chdir(root), progress:
+        yield
+
+
+def iter_phases(
+    options: Options, *, cancel: threading.Event | None = None
+) -> Iterator[PhaseResult]:
+    """Yield each detector phase's findings as soon as that phase finishes.
+
+    ``cancel`` is checked before every phase and, inside the Go smell and
+    vet phases, before every package or module; once it is set no further
+    work starts and ``ScanCancelled`` is raised. Work already running (one
+    package's rules, an external ``go vet``) is allowed to finish first.
+
+    Detectors read project files relative to the working directory, so the
+    run temporarily changes into the project root; do not call this from
+    several threads at once.
+    """
+    root = Path(options.path).resolve()
+    lang_run = _make_lang_run(_lang_name(options, root), options)
+    scan_options = PlanScanOptions(
+        include_slow=options.include_slow,
+        profile=options.profile,
+        syntax_only=options.syntax_only,
+    )
+    runtime = RuntimeContext(project_root=root, cancel=cancel)
+    with runtime_scope(runtime):
+        set_exclusions(list(options.exclusions))
+    results = iter_phase_results(root, lang_run, options=scan_options)
+    while True:
+        if cancel is not None and cancel.is_set():
+            results.close()
+            raise ScanCancelled("scan cancelled")
+        # Scope cwd/stderr to each phase so the caller's code between
+        # yields runs in its own environment.
+        with _phase_scope(runtime, root, verbose=options.verbose):
+            try:
+                result = next(results)
+            except StopIteration:
+                return
+        if options.rules is not None:
+            result.findings = [
+                f for f in result.findings if _rule_matches(f, options.rules)
+            ]
+        yield result
+
+
+def run(
+    options: Options,
+    *,
+    cancel: threading.Event | None = None,
+    on_phase: Callable[[PhaseResult], None] | None = None,
+) -> Report:
+    """Scan ``options.path`` in-process and return every finding."""
+    root = Path(options.path).resolve()
+    report = Report(lang=_lang_name(options, root), path=str(root))
+    for result in iter_phases(options, cancel=cancel):
+        report.findings.extend(result.findings)
+        report.potentials.update(result.potentials)
+        if on_phase is not None:
+            on_phase(result)
+    return report
+
+
+__all__ = [
+    "Options",
+    "PhaseResult",
+    "Report",
+    "ScanCancelled",
+    "iter_phases",
+    "run",
+    "write_json",
+]
@@ -0,0 +1,62 @@
+"""Path-independent finding identity, for matching findings across runs.
+
+A finding id embeds its file, so a rename makes every finding in the file
+look new. ``fingerprint`` drops the path from the id; ``snippet_hash``
+hashes the code the finding points at (its line, or the whole file for a
+file-level finding), so identical code found under another path can still be
+paired up. Both are stamped on findings by the scan (see ``compare``).
+"""
+
+from __future__ import annotations
+
+import hashlib
+from typing import Any
+
+from desloppify.file_discovery import read_file_text, resolve_path
+
+
+def _digest(*parts: str) -> str:
+    return hashlib.sha1("\0".join(parts).encode("utf-8")).hexdigest()[:16]
+
+
+def finding_line(finding: dict[str, Any]) -> int:
+    """The finding's line (its first match for aggregated findings); 0 if none."""
+    detail = finding.get("detail") if isinstance(finding.get("detail"), dict) else {}
+    matches = detail.get("matches")
+    if isinstance(matches, list) and matches and isinstance(matches[0], dict):
+        return int(matches[0].get("line") or 0)
+    return int(detail.get("line") or 0)
+
+
+def finding_fingerprint(finding: dict[str, Any]) -> str:
+    """Hash of the finding id without its file segment."""
+    file = str(finding.get("file", ""))
+    parts = [p for p in str(finding.get("id", "")).split("::") if p != file]
+    return _digest(str(finding.get("detector", "")), *parts)
+
+
+def snippet_hash(finding: dict[str, Any]) -> str | None:
+    """Hash of the whitespace-normalized code at the finding, None if unreadable."""
+    file = str(finding.get("file", ""))
+    text = read_file_text(resolve_path(file)) if file else None
+    if text is None:
+        return None
+    line = finding_line(finding)
+    lines = text.splitlines()
+    if line:
+        if line > len(lines):
+            return None
+        lines = lines[line - 1 : line]
+    snippet = " ".join(" ".join(lines).split())
+    return _digest(str(finding.get("detector", "")), snippet) if snippet else None
+
+
+def stamp_fingerprints(finding: dict[str, Any]) -> None:
+    """Set ``fingerprint`` and, when the code is readable, ``snippet_hash``."""
+    finding["fingerprint"] = finding_fingerprint(finding)
+    snippet = snippet_hash(finding)
+    if snippet is not None:
+        finding["snippet_hash"] = snippet
+
+
+__all__ = ["finding_fingerprint", "finding_line", "snippet_hash", "stamp_fingerprints"]
@@ -0,0 +1,204 @@
+"""Merge findings that report the same lines through related rules.
+
+A rule is a finding's detector, or ``smells::<smell id>`` for smells. Two
+kinds of overlap are folded before findings reach state:
+
+- **Duplicates.** A finding with the same id as an earlier one, or whose
+  lines are all reported by an earlier finding of the same rule in the
+  same file, adds nothing and is dropped. This always applies.
+- **Subsumed.** ``subsumes`` maps a more specific rule to the rules it
+  covers. A covered finding whose lines all fall on lines the specific
+  finding reports is moved into that finding's ``detail["related"]``.
+
+Only findings with known lines take part: ``detail["line"]``,
+``detail["lines"]`` when it lists every occurrence, or the
+``detail["matches"]`` of an aggregated finding (Go smells) when they are
+all of its matches and all in its file. File-level findings, aggregated
+ones that reach into other files and partially covered ones are left as
+they are.
+"""
+
+from __future__ import annotations
+
+from collections.abc import Iterable, Mapping
+from dataclasses import dataclass
+from itertools import groupby
+from typing import Any
+
+from desloppify.state import Finding
+
+# Specific rule -> rules whose findings on the same lines it already explains.
+DEFAULT_SUBSUMES: dict[str, tuple[str, ...]] = {
+    # `if x {} else {}`: both branches are "duplicate" because both are empty.
+    "smells::empty_branch": ("smells::duplicate_branch",),
+    # `if true {}`: the empty body is a symptom of the constant condition.
+    "smells::constant_condition": ("smells::empty_branch",),
+    # The package-wide check of an always-nil error reports what the per-file one does.
+    "smells::always_nil_error": ("smells::useless_error_return",),
+    # A lock left held is also a resource not released; the mutex finding names the lock.
+    "smells::mutex_unlock_missing": ("smells::resource_not_released",),
+}
+
+
+@dataclass
+class OverlapStats:
+    """Finding counts around the merge pass."""
+
+    before: int = 0
+    duplicates: int = 0
+    subsumed: int = 0
+
+    @property
+    def after(self) -> int:
+        return self.before - self.duplicates - self.subsumed
+
+    def as_dict(self) -> dict[str, int]:
+        return {
+            "before": self.before,
+            "after": self.after,
+            "duplicates": self.duplicates,
+            "subsumed": self.subsumed,
+        }
+
+
+def resolve_subsumes(
+    overrides: Mapping[str, Iterable[str]] | None = None,
+) -> dict[str, frozenset[str]]:
+    """``DEFAULT_SUBSUMES`` with config overrides, closed transitively.
+
+    An override replaces a rule's default entry; an empty list removes it.
+    """
+    direct: dict[str, set[str]] = {rule: set(covered) for rule, covered in DEFAULT_SUBSUMES.items()}
+    for rule, covered in (overrides or {}).items():
+        direct[str(rule)] = set(map(str, covered)) if isinstance(covered, list | tuple) else set()
+    closed: dict[str, frozenset[str]] = {}
+    for rule in direct:
+        seen: set[str] = set()
+        pending = list(direct[rule])
+        while pending:
+            covered = pending.pop()
+            if covered in seen or covered == rule:
+                continue
+            seen.add(covered)
+            pending.extend(direct.get(covered, ()))
+        if seen:
+            closed[rule] = frozenset(seen)
+    return closed
+
+
+def finding_rule(finding: Finding) -> str:
+    detector = str(finding.get("detector", ""))
+    if detector != "smells":
+        return detector
+    smell_id = (finding.get("detail") or {}).get("smell_id")
+    if not smell_id:
+        # Tree-sitter smells carry the id in the name: "smells::<file>::<id>::<line>".
+        parts = str(finding.get("id", "")).split("::")
+        smell_id = parts[2] if len(parts) > 2 else ""
+    return f"smells::{smell_id}"
+
+
+def _finding_lines(finding: Finding) -> frozenset[int] | None:
+    """Every line ``finding`` reports, or None when that is not known."""
+    detail = finding.get("detail") or {}
+    lines = detail.get("lines")
+    if isinstance(lines, list) and lines:
+        count = detail.get("count", len(lines))
+        if isinstance(count, int) and count > len(lines):
+            return None
+        numbers = [n for n in lines if isinstance(n, int)]
+        return frozenset(numbers) if len(numbers) == len(lines) else None
+    line = detail.get("line")
+    if isinstance(line, int) and line > 0:
+        return frozenset((line,))
+    matches = detail.get("matches")
+    if isinstance(matches, list) and matches:
+        count = detail.get("count", len(matches))
+        if isinstance(count, int) and count > len(matches):
+            return None
+        if any(not isinstance(m, dict) or m.get("file") != finding.get("file") for m in matches):
+            return None
+        numbers = [m.get("line") for m in matches]
+        return frozenset(numbers) if all(isinstance(n, int) for n in numbers) else None
+    return None
+
+
+def _related_entry(finding: Finding, rule: str, lines: frozenset[int]) -> dict[str, Any]:
+    return {
+        "id": finding.get("id", ""),
+        "rule": rule,
+        "summary": finding.get("summary", ""),
+        "lines": sorted(lines),
+    }
+
+
+def _merge_file(
+    findings: list[Finding], subsumes: Mapping[str, frozenset[str]], stats: OverlapStats
+) -> list[Finding]:
+    positioned = [(f, finding_rule(f), _finding_lines(f)) for f in findings]
+
+    kept: list[tuple[Finding, str, frozenset[int] | None]] = []
+    for finding, rule, lines in positioned:
+        if any(
+            finding.get("id") == kept_finding.get("id")
+            or (
+                rule == k_rule
+                and lines is not None
+                and k_lines is not None
+                and lines <= k_lines
+            )
+            for kept_finding, k_rule, k_lines in kept
+        ):
+            stats.duplicates += 1
+            continue
+        kept.append((finding, rule, lines))
+
+    subsumed: set[int] = set()
+    for finding, rule, lines in kept:
+        if lines is None:
+            continue
+        owner = next(
+            (
+                other
+                for other, o_rule, o_lines in kept
+                if other is not finding
+                and id(other) not in subsumed
+                and rule in subsumes.get(o_rule, ())
+                and o_lines is not None
+                and lines <= o_lines
+            ),
+            None,
+        )
+        if owner is None:
+            continue
+        subsumed.add(id(finding))
+        related = owner.setdefault("detail", {}).setdefault("related", [])
+        related.append(_related_entry(finding, rule, lines))
+        # Anything this finding had already absorbed moves up with it.
+        related.extend((finding.get("detail") or {}).pop("related", []))
+        stats.subsumed += 1
+    return [finding for finding, _, _ in kept if id(finding) not in subsumed]
+
+
+def merge_overlapping(
+    findings: Iterable[Finding],
+    subsumes: Mapping[str, frozenset[str]] | None = None,
+) -> tuple[list[Finding], OverlapStats]:
+    """Drop duplicates and fold subsumed findings; input must be sorted by file."""
+    relation = resolve_subsumes() if subsumes is None else subsumes
+    stats = OverlapStats()
+    merged: list[Finding] = []
+    for _, group in groupby(findings, key=lambda f: str(f.get("file", ""))):
+        file_findings = list(group)
+        stats.before += len(file_findings)
+        merged.extend(_merge_file(file_findings, relation, stats))
+    return merged, stats
+
+
+__all__ = [
+    "DEFAULT_SUBSUMES",
+    "OverlapStats",
+    "finding_rule",
+    "merge_overlapping",
+    "resolve_subsumes",
+]
@@ -51,6 +51,10 @@ def _plan_header(state: PlanState, stats: dict) -> list[str]:
         "",
     ]
 
+    owner = state.get("owner_scope")
+    if owner:
+        lines.extend([f"**Owner:** {owner} (scores are for the whole scan)", ""])
+
     if total_files:
         loc_str = (
             f"{total_loc:,}"
@@ -260,6 +264,17 @@ def _tier_summary_lines(stats: dict) -> list[str]:
     return lines
 
 
+def _owner_section(stats: dict) -> list[str]:
+    """Open findings per CODEOWNERS owner (empty without ownership data)."""
+    by_owner = stats.get("by_owner") or {}
+    if not by_owner:
+        return []
+    lines = ["## Findings by Owner", "", "| Owner | Open |", "|-------|------|"]
+    lines.extend(f"| {owner} | {count} |" for owner, count in by_owner.items())
+    lines.append("")
+    return lines
+
+
 def _addressed_section(findings: dict) -> list[str]:
     addressed = [
         finding for finding in findings.values() if finding["status"] != "open"
@@ -296,6 +311,7 @@ def generate_plan_md(state: PlanState) -> str:
     lines = _plan_header(state, stats)
     lines.extend(_plan_dimension_table(state))
     lines.extend(_tier_summary_lines(stats))
+    lines.extend(_owner_section(stats))
     lines.extend(_plan_tier_sections(findings, state=state))
     lines.extend(_addressed_section(findings))
 
@@ -2,12 +2,19 @@
 
 from __future__ import annotations
 
+import os
 import sys
+from collections.abc import Iterator
 from dataclasses import dataclass
 from pathlib import Path
 
-from desloppify.core._internal.text_utils import PROJECT_ROOT
+from desloppify.core._internal.text_utils import PROJECT_ROOT, get_project_root
+from desloppify.core.diagnostics import current_diagnostics
 from desloppify.engine.planning.common import is_subjective_phase
+from desloppify.engine.planning.fingerprint import stamp_fingerprints
+from desloppify.engine.planning.overlap import merge_overlapping, resolve_subsumes
+from desloppify.engine.planning.spill import FindingSpill, spill_threshold_bytes
+from desloppify.engine.policy.owners import load_codeowners
 from desloppify.engine.policy.zones import ZONE_POLICIES, FileZoneMap
 from desloppify.file_discovery import rel
 from desloppify.languages import auto_detect_lang, available_langs, get_lang
@@ -24,6 +31,9 @@ class PlanScanOptions:
     include_slow: bool = True
     zone_overrides: dict[str, str] | None = None
     profile: str = "full"
+    syntax_only: bool = False
+    max_memory_mb: int | None = None
+    subsumes: dict[str, list[str]] | None = None
 
 
 def _stderr(msg: str) -> None:
@@ -70,9 +80,44 @@ def _build_zone_map(path: Path, lang: LangRun, zone_overrides: dict[str, str] |
             _stderr(f"  Not available: {', '.join(missing)}")
 
 
-def _select_phases(lang: LangRun, *, include_slow: bool, profile: str) -> list[DetectorPhase]:
+def _vendored_files(path: Path, lang: LangRun) -> frozenset[str]:
+    """Vendored files the scan leaves out, announced in one summary line."""
+    finder = getattr(lang, "vendored_finder", None)
+    vendored = frozenset(finder(path)) if finder is not None else frozenset()
+    if vendored:
+        packages = len({os.path.dirname(f) for f in vendored})
+        _stderr(
+            f"  Vendored: {len(vendored)} files in {packages} packages not analyzed "
+            "(--include-vendor to audit them)"
+        )
+    return vendored
+
+
+def _announce_codeowners() -> None:
+    """Name the CODEOWNERS file findings are attributed with and its skipped lines."""
+    codeowners = load_codeowners(get_project_root())
+    if codeowners is None:
+        return
+    _stderr(f"  Owners: {len(codeowners.rules)} CODEOWNERS rules")
+    for error in codeowners.errors:
+        _stderr(f"    skipped {error}")
+
+
+def _select_phases(
+    lang: LangRun,
+    *,
+    include_slow: bool,
+    profile: str,
+    syntax_only: bool = False,
+) -> list[DetectorPhase]:
     active_profile = profile if profile in {"objective", "full", "ci"} else "full"
     phases = lang.phases
+    if syntax_only:
+        phases = [
+            phase
+            for phase in phases
+            if not phase.needs_package and getattr(phase, "requires", "syntax") == "syntax"
+        ]
     if not include_slow or active_profile == "ci":
         phases = [phase for phase in phases if not phase.slow]
     if active_profile in {"objective", "ci"}:
@@ -80,20 +125,65 @@ def _select_phases(lang: LangRun, *, include_slow: bool, profile: str) -> list[D
     return phases
 
 
-def _run_phases(path: Path, lang: LangRun, phases: list[DetectorPhase]) -> tuple[list[Finding], dict[str, int]]:
-    findings: list[Finding] = []
-    all_potentials: dict[str, int] = {}
+@dataclass
+class PhaseResult:
+    """Findings and potentials produced by one detector phase."""
+
+    phase: DetectorPhase
+    findings: list[Finding]
+    potentials: dict[str, int]
 
+
+def _iter_phases(
+    path: Path, lang: LangRun, phases: list[DetectorPhase]
+) -> Iterator[PhaseResult]:
     total = len(phases)
+    diagnostics = current_diagnostics()
     for idx, phase in enumerate(phases, start=1):
         _stderr(f"  [{idx}/{total}] {phase.label}...")
-        phase_findings, phase_potentials = phase.run(path, lang)
-        all_potentials.update(phase_potentials)
-        findings.extend(phase_findings)
+        if diagnostics is None:
+            phase_findings, phase_potentials = phase.run(path, lang)
+        else:
+            with diagnostics.span(phase.label, "phase"):
+                phase_findings, phase_potentials = phase.run(path, lang)
+        yield PhaseResult(phase, phase_findings, phase_potentials)
+
 
+def _run_phases(path: Path, lang: LangRun, phases: list[DetectorPhase]) -> tuple[list[Finding], dict[str, int]]:
+    findings: list[Finding] = []
+    all_potentials: dict[str, int] = {}
+    for result in _iter_phases(path, lang, phases):
+        all_potentials.update(result.potentials)
+        findings.extend(result.findings)
     return findings, all_potentials
 
 
+def iter_phase_results(
+    path: Path, lang: LangRun, *, options: PlanScanOptions | None = None
+) -> Iterator[PhaseResult]:
+    """Run selected phases one at a time, yielding each result as it completes.
+
+    Findings are stamped with lang/zone context before they are yielded, so a
+    consumer that stops iterating early still holds fully-formed findings.
+    Findings located in vendored files are dropped first.
+    """
+    resolved = options or PlanScanOptions()
+    _build_zone_map(path, lang, resolved.zone_overrides)
+    vendored = _vendored_files(path, lang)
+    _announce_codeowners()
+    phases = _select_phases(
+        lang,
+        include_slow=resolved.include_slow,
+        profile=resolved.profile,
+        syntax_only=resolved.syntax_only,
+    )
+    for result in _iter_phases(path, lang, phases):
+        if vendored:
+            result.findings = [f for f in result.findings if f.get("file") not in vendored]
+        _stamp_finding_context(result.findings, lang)
+        yield result
+
+
 def _stamp_finding_context(findings: list[Finding], lang: LangRun) -> None:
     if not findings:
         return
@@ -102,8 +192,16 @@ def _stamp_finding_context(findings: list[Finding], lang: LangRun) -> None:
     if lang.zone_map is not None:
         zone_policies = ZONE_POLICIES
 
+    module_of = getattr(lang, "module_of", None)
+    codeowners = load_codeowners(get_project_root())
     for finding in findings:
         finding["lang"] = lang.name
+        stamp_fingerprints(finding)
+        module = module_of(finding.get("file", "")) if module_of is not None else None
+        if module:
+            finding["module"] = module
+        if codeowners is not None:
+            finding["owners"] = list(codeowners.owners_of(finding.get("file", "")))
         if lang.zone_map is None:
             continue
 
@@ -121,12 +219,29 @@ def _generate_findings_from_lang(
     include_slow: bool = True,
     zone_overrides: dict[str, str] | None = None,
     profile: str = "full",
+    syntax_only: bool = False,
+    max_memory_mb: int | None = None,
+    subsumes: dict[str, list[str]] | None = None,
 ) -> tuple[list[Finding], dict[str, int]]:
-    """Run detector phases from a LangRun."""
-    _build_zone_map(path, lang, zone_overrides)
-    phases = _select_phases(lang, include_slow=include_slow, profile=profile)
-    findings, all_potentials = _run_phases(path, lang, phases)
-    _stamp_finding_context(findings, lang)
+    """Run detector phases from a LangRun.
+
+    Each phase's findings go to a ``FindingSpill`` as soon as it finishes, so
+    large scans keep at most the spill budget of them in memory until the
+    final ordered pass, which also merges overlapping findings.
+    """
+    all_potentials: dict[str, int] = {}
+    options = PlanScanOptions(
+        include_slow=include_slow,
+        zone_overrides=zone_overrides,
+        profile=profile,
+        syntax_only=syntax_only,
+    )
+    with FindingSpill(spill_threshold_bytes(max_memory_mb)) as spill:
+        for result in iter_phase_results(path, lang, options=options):
+            all_potentials.update(result.potentials)
+            spill.extend(result.findings)
+        findings, overlap = merge_overlapping(spill.sorted(), resolve_subsumes(subsumes))
+    lang.finding_overlap = overlap.as_dict()
     _stderr(f"\n  Total: {len(findings)} findings")
     return findings, all_potentials
 
@@ -148,4 +263,7 @@ def generate_findings(
         include_slow=resolved_options.include_slow,
         zone_overrides=resolved_options.zone_overrides,
         profile=resolved_options.profile,
+        syntax_only=resolved_options.syntax_only,
+        max_memory_mb=resolved_options.max_memory_mb,
+        subsumes=resolved_options.subsumes,
     )
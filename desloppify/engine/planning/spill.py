@@ -0,0 +1,134 @@
+"""Bounded-memory collection of scan findings.
+
+Findings are buffered up to a byte budget (measured as their JSON size);
+past it, the buffer is sorted and written out as one JSONL run in a
+temporary directory. ``FindingSpill.sorted()`` merges the runs with whatever
+is still buffered, so ordering a whole scan never needs every finding in
+memory at once. ``FindingCounters`` keeps the summary numbers as findings pass by.
+"""
+
+from __future__ import annotations
+
+import heapq
+import json
+import tempfile
+from collections import Counter
+from collections.abc import Iterable, Iterator
+from dataclasses import dataclass, field
+from pathlib import Path
+from typing import Any
+
+DEFAULT_MAX_MEMORY_MB = 1024
+# The spill buffer gets this fraction of the --max-memory hint; detectors and
+# the interpreter itself need the rest.
+_SPILL_SHARE = 0.25
+
+
+def spill_threshold_bytes(max_memory_mb: int | None) -> int:
+    """Buffered-findings budget for a ``--max-memory`` hint (MB; None = default)."""
+    budget = max_memory_mb if max_memory_mb and max_memory_mb > 0 else DEFAULT_MAX_MEMORY_MB
+    return int(budget * 1024 * 1024 * _SPILL_SHARE)
+
+
+def finding_sort_key(finding: dict[str, Any]) -> tuple[str, str, str]:
+    return (
+        str(finding.get("file", "")),
+        str(finding.get("detector", "")),
+        str(finding.get("id", "")),
+    )
+
+
+def _read_run(path: Path) -> Iterator[dict[str, Any]]:
+    with path.open() as handle:
+        for line in handle:
+            yield json.loads(line)
+
+
+class FindingSpill:
+    """Append-only finding store that spills sorted runs to disk."""
+
+    def __init__(self, threshold_bytes: int) -> None:
+        self.threshold_bytes = threshold_bytes
+        self.count = 0
+        self._buffer: list[dict[str, Any]] = []
+        self._buffered_bytes = 0
+        self._runs: list[Path] = []
+        self._tmp: tempfile.TemporaryDirectory[str] | None = None
+
+    @property
+    def spilled_runs(self) -> int:
+        return len(self._runs)
+
+    def add(self, finding: dict[str, Any]) -> None:
+        self._buffer.append(finding)
+        self._buffered_bytes += len(json.dumps(finding, default=str))
+        self.count += 1
+        if self._buffered_bytes >= self.threshold_bytes:
+            self._flush()
+
+    def extend(self, findings: Iterable[dict[str, Any]]) -> None:
+        for finding in findings:
+            self.add(finding)
+
+    def _flush(self) -> None:
+        if not self._buffer:
+            return
+        if self._tmp is None:
+            self._tmp = tempfile.TemporaryDirectory(prefix="desloppify-spill-")
+        path = Path(self._tmp.name) / f"run{len(self._runs):05d}.jsonl"
+        self._buffer.sort(key=finding_sort_key)
+        with path.open("w") as handle:
+            for finding in self._buffer:
+                handle.write(json.dumps(finding, default=str) + "\n")
+        self._runs.append(path)
+        self._buffer = []
+        self._buffered_bytes = 0
+
+    def sorted(self) -> Iterator[dict[str, Any]]:
+        """Every finding added so far, ordered by file, detector, then id."""
+        streams: list[Iterable[dict[str, Any]]] = [_read_run(p) for p in self._runs]
+        streams.append(sorted(self._buffer, key=finding_sort_key))
+        return heapq.merge(*streams, key=finding_sort_key)
+
+    def close(self) -> None:
+        if self._tmp is not None:
+            self._tmp.cleanup()
+            self._tmp = None
+        self._runs = []
+        self._buffer = []
+
+    def __enter__(self) -> FindingSpill:
+        return self
+
+    def __exit__(self, *_exc: object) -> None:
+        self.close()
+
+
+@dataclass
+class FindingCounters:
+    """Summary counts accumulated one finding at a time."""
+
+    total: int = 0
+    by_detector: Counter[str] = field(default_factory=Counter)
+    by_tier: Counter[int] = field(default_factory=Counter)
+
+    def add(self, finding: dict[str, Any]) -> None:
+        self.total += 1
+        self.by_detector[str(finding.get("detector", ""))] += 1
+        self.by_tier[int(finding.get("tier", 0) or 0)] += 1
+
+    def as_dict(self) -> dict[str, Any]:
+        return {
+            "total": self.total,
+            "by_detector": dict(sorted(self.by_detector.items())),
+            "by_tier": {str(tier): n for tier, n in sorted(self.by_tier.items())},
+        }
+
+
+__all__ = [
+    "DEFAULT_MAX_MEMORY_MB",
+    "FindingCounters",
+    "FindingSpill",
+    "finding_sort_key",
+    "spill_threshold_bytes",
+]
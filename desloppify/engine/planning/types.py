@@ -17,6 +17,7 @@ class PlanState(TypedDict, total=False):
     stats: StateStats
     dimension_scores: dict[str, DimensionScore]
     codebase_metrics: dict[str, dict]
+    owner_scope: str
 
 
 class PlanItem(TypedDict, total=False):
@@ -0,0 +1,214 @@
+"""Code ownership from a GitHub CODEOWNERS file.
+
+The first of ``.github/CODEOWNERS``, ``CODEOWNERS`` and ``docs/CODEOWNERS``
+is read. Each line is a gitignore-style pattern followed by owners
+(``@user``, ``@org/team`` or an email). As on GitHub:
+
+- the last matching pattern wins, and a pattern with no owners leaves its
+  files unowned;
+- a pattern without a ``/`` (other than a trailing one) matches at any depth,
+  one with a leading or inner ``/`` is relative to the repository root;
+- a trailing ``/`` matches a directory and everything under it, a trailing
+  ``/*`` only the files directly in it;
+- ``*`` and ``?`` stay within one path segment, ``**`` spans segments;
+- ``!`` negation and ``[ ]`` character ranges are not supported: such lines
+  are skipped and reported, never half-applied;
+- ``#`` starts a comment, ``\\#`` is a literal ``#``.
+
+Scans stamp every finding with ``owners`` (a list, empty when unowned) while
+a CODEOWNERS file exists; reports bucket findings without owners as
+``UNOWNED``.
+"""
+
+from __future__ import annotations
+
+import functools
+import re
+from collections.abc import Iterable
+from dataclasses import dataclass, field
+from pathlib import Path
+from typing import Any
+
+CODEOWNERS_LOCATIONS = (".github/CODEOWNERS", "CODEOWNERS", "docs/CODEOWNERS")
+UNOWNED = "(unowned)"
+
+_OWNER = re.compile(r"^@[\w.-]+(/[\w.-]+)?$|^[^@\s]+@[^@\s]+$")
+
+
+@dataclass(frozen=True)
+class OwnerRule:
+    """One CODEOWNERS line: a pattern and the owners of what it matches."""
+
+    pattern: str
+    owners: tuple[str, ...]
+    line: int
+    regex: re.Pattern[str]
+
+
+@dataclass
+class CodeOwners:
+    """Parsed CODEOWNERS rules in file order, plus the lines that were skipped."""
+
+    rules: list[OwnerRule] = field(default_factory=list)
+    errors: list[str] = field(default_factory=list)
+
+    def owners_of(self, path: str) -> tuple[str, ...]:
+        """Owners of a repository-relative path; empty when nothing owns it."""
+        path = path.replace("\\", "/").lstrip("/")
+        if path.startswith("./"):
+            path = path[2:]
+        for rule in reversed(self.rules):
+            if rule.regex.match(path):
+                return rule.owners
+        return ()
+
+
+def _segment(segment: str) -> str:
+    return "".join(
+        "[^/]*" if ch == "*" else "[^/]" if ch == "?" else re.escape(ch) for ch in segment
+    )
+
+
+def pattern_regex(pattern: str) -> re.Pattern[str]:
+    """The regex matching the paths a CODEOWNERS ``pattern`` covers."""
+    anchored = "/" in pattern.rstrip("/")
+    directory_only = pattern.endswith("/")
+    segments = pattern.strip("/").split("/")
+    parts: list[str] = []
+    for index, segment in enumerate(segments):
+        last = index == len(segments) - 1
+        if segment == "**":
+            # Leading and inner ``**/`` match zero or more directories; a
+            # trailing ``/**`` everything below.
+            parts.append(".*" if last else "(?:[^/]+/)*")
+            continue
+        parts.append(_segment(segment) + ("" if last else "/"))
+    body = "".join(parts)
+    prefix = "^" if anchored else "^(?:[^/]+/)*"
+    if directory_only:
+        suffix = "/.*$"
+    elif segments[-1] in ("*", "**"):
+        suffix = "$"
+    else:
+        suffix = "(?:/.*)?$"
+    return re.compile(prefix + body + suffix)
+
+
+def _split_line(line: str) -> list[str]:
+    """Whitespace-separated tokens up to an unescaped ``#``, unescaping ``\\#`` and ``\\ ``."""
+    tokens: list[str] = []
+    current = ""
+    index = 0
+    while index < len(line):
+        ch = line[index]
+        if ch == "\\" and index + 1 < len(line) and line[index + 1] in "# ":
+            current += line[index + 1]
+            index += 2
+            continue
+        if ch == "#" and not current:
+            break
+        if ch.isspace():
+            if current:
+                tokens.append(current)
+            current = ""
+        else:
+            current += ch
+        index += 1
+    if current:
+        tokens.append(current)
+    return tokens
+
+
+def parse_codeowners(text: str, source: str = "CODEOWNERS") -> CodeOwners:
+    """Parse CODEOWNERS ``text``; unsupported lines land in ``errors``."""
+    result = CodeOwners()
+    for number, raw in enumerate(text.splitlines(), start=1):
+        tokens = _split_line(raw)
+        if not tokens:
+            continue
+        pattern, owners = tokens[0], tokens[1:]
+        where = f"{source}:{number}"
+        if pattern.startswith("!"):
+            result.errors.append(f"{where}: negation (!) is not supported in CODEOWNERS")
+            continue
+        if "[" in pattern or "]" in pattern:
+            result.errors.append(f"{where}: character ranges ([ ]) are not supported")
+            continue
+        invalid = [owner for owner in owners if not _OWNER.match(owner)]
+        if invalid:
+            result.errors.append(f"{where}: invalid owner {invalid[0]!r}")
+            continue
+        result.rules.append(OwnerRule(pattern, tuple(owners), number, pattern_regex(pattern)))
+    return result
+
+
+def find_codeowners(root: Path) -> Path | None:
+    """The CODEOWNERS file GitHub would use for ``root``, if any."""
+    for location in CODEOWNERS_LOCATIONS:
+        candidate = root / location
+        if candidate.is_file():
+            return candidate
+    return None
+
+
+@functools.lru_cache(maxsize=8)
+def _load(path: str, source: str, mtime_ns: int) -> CodeOwners:
+    del mtime_ns  # only part of the cache key
+    try:
+        text = Path(path).read_text(errors="replace")
+    except OSError:
+        return CodeOwners()
+    return parse_codeowners(text, source=source)
+
+
+def load_codeowners(root: Path) -> CodeOwners | None:
+    """Parsed CODEOWNERS for ``root``, None without one; re-read when it changes."""
+    path = find_codeowners(root)
+    if path is None:
+        return None
+    try:
+        mtime_ns = path.stat().st_mtime_ns
+    except OSError:
+        return None
+    return _load(str(path), path.relative_to(root).as_posix(), mtime_ns)
+
+
+def finding_owners(finding: dict[str, Any]) -> list[str]:
+    """A finding's owners, or ``[UNOWNED]``."""
+    return list(finding.get("owners") or []) or [UNOWNED]
+
+
+def owned_by(finding: dict[str, Any], owner: str) -> bool:
+    """Whether ``owner`` (case-insensitive; ``unowned`` for no owner) owns ``finding``."""
+    wanted = UNOWNED.casefold() if owner.casefold() == "unowned" else owner.casefold()
+    return any(o.casefold() == wanted for o in finding_owners(finding))
+
+
+def owner_counts(findings: Iterable[dict[str, Any]]) -> dict[str, int]:
+    """Open findings per owner, most first; a shared finding counts for each owner.
+
+    Empty when no finding was stamped with owners (no CODEOWNERS file).
+    """
+    findings = [f for f in findings if "owners" in f]
+    counts: dict[str, int] = {}
+    for finding in findings:
+        if finding.get("status", "open") != "open" or finding.get("suppressed"):
+            continue
+        for owner in finding_owners(finding):
+            counts[owner] = counts.get(owner, 0) + 1
+    return dict(sorted(counts.items(), key=lambda item: (-item[1], item[0])))
+
+
+__all__ = [
+    "CODEOWNERS_LOCATIONS",
+    "UNOWNED",
+    "CodeOwners",
+    "OwnerRule",
+    "find_codeowners",
+    "finding_owners",
+    "load_codeowners",
+    "owned_by",
+    "owner_counts",
+    "parse_codeowners",
+    "pattern_regex",
+]
@@ -3,10 +3,12 @@
 from __future__ import annotations
 
 import os
+import posixpath
 from pathlib import Path
 
 from desloppify.core._internal.text_utils import get_project_root
 from desloppify.core.file_paths import (
+    PathGlobs,
     matches_exclusion,
     rel,
     resolve_path,
@@ -24,6 +26,22 @@ __all__ = [
     "DEFAULT_EXCLUSIONS",
     "set_exclusions",
     "get_exclusions",
+    "set_selected_dirs",
+    "get_selected_dirs",
+    "set_report_dirs",
+    "get_report_dirs",
+    "set_report_lines",
+    "get_report_lines",
+    "is_reported",
+    "set_selected_files",
+    "get_selected_files",
+    "set_build_tags",
+    "get_build_tags",
+    "set_vendored_paths",
+    "get_vendored_paths",
+    "is_vendored_included",
+    "set_path_globs",
+    "get_path_globs",
     "matches_exclusion",
     "rel",
     "resolve_path",
@@ -33,6 +51,7 @@ __all__ = [
     "is_file_cache_enabled",
     "read_file_text",
     "find_source_files",
+    "clear_source_file_cache",
     "find_ts_files",
     "find_tsx_files",
     "find_py_files",
@@ -77,6 +96,135 @@ def get_exclusions() -> tuple[str, ...]:
     return current_runtime_context().exclusions
 
 
+def set_selected_dirs(dirs: list[str] | None):
+    """Restrict discovery to files directly inside the given directories.
+
+    ``None`` clears the restriction. Directories are project-root relative.
+    """
+    runtime = current_runtime_context()
+    runtime.selected_dirs = None if dirs is None else tuple(sorted(set(dirs)))
+    runtime.source_file_cache.clear()
+
+
+def get_selected_dirs() -> tuple[str, ...] | None:
+    """Return the active directory selection (``None`` = no restriction)."""
+    return current_runtime_context().selected_dirs
+
+
+def set_report_dirs(dirs: list[str] | None):
+    """Keep only findings directly inside the given directories.
+
+    Unlike ``set_selected_dirs`` this leaves discovery alone, so detectors
+    still see uses from other packages (changed-files scans). ``None``
+    clears it. Directories are project-root relative.
+    """
+    current_runtime_context().report_dirs = None if dirs is None else tuple(sorted(set(dirs)))
+
+
+def get_report_dirs() -> tuple[str, ...] | None:
+    """Return the directories whose findings are kept (``None`` = all)."""
+    return current_runtime_context().report_dirs
+
+
+def set_report_lines(lines: dict[str, frozenset[int] | None] | None):
+    """Keep only findings on the given lines (``scan --diff-base``).
+
+    Maps project-relative files to their changed line numbers; ``None`` for
+    a file keeps all of it (new files). ``None`` clears the restriction.
+    """
+    current_runtime_context().report_lines = None if lines is None else dict(lines)
+
+
+def get_report_lines() -> dict[str, frozenset[int] | None] | None:
+    """Return the files and lines whose findings are kept (``None`` = all)."""
+    return current_runtime_context().report_lines
+
+
+def is_reported(filepath: str, line: int | None = None) -> bool:
+    """Whether a finding in ``filepath`` at ``line`` is inside the report scope.
+
+    Without a ``line`` (file-level findings), any reported line in the file
+    counts.
+    """
+    runtime = current_runtime_context()
+    dirs = runtime.report_dirs
+    if dirs is not None and (posixpath.dirname(filepath) or ".") not in dirs:
+        return False
+    if runtime.report_lines is None:
+        return True
+    if filepath not in runtime.report_lines:
+        return False
+    lines = runtime.report_lines[filepath]
+    return lines is None or not line or line in lines
+
+
+def set_selected_files(files: list[str] | None):
+    """Restrict discovery to exactly these files (e.g. a package loader's view).
+
+    ``None`` clears the restriction. Paths are project-root relative.
+    """
+    runtime = current_runtime_context()
+    runtime.selected_files = None if files is None else frozenset(files)
+    runtime.source_file_cache.clear()
+
+
+def get_selected_files() -> frozenset[str] | None:
+    """Return the active file selection (``None`` = no restriction)."""
+    return current_runtime_context().selected_files
+
+
+def set_build_tags(tags: list[str]):
+    """Set the build tags that languages with build constraints honor (``--tags``)."""
+    runtime = current_runtime_context()
+    runtime.build_tags = tuple(sorted(set(tags)))
+    runtime.source_file_cache.clear()
+
+
+def get_build_tags() -> tuple[str, ...]:
+    """Return the active build tags."""
+    return current_runtime_context().build_tags
+
+
+def set_vendored_paths(paths: list[str], *, include: bool = False):
+    """Set third-party directories skipped like ``vendor/`` (``third_party_paths``).
+
+    ``include`` analyzes vendored code after all (``--include-vendor``).
+    """
+    runtime = current_runtime_context()
+    runtime.vendored_paths = tuple(sorted({p.rstrip("/") for p in paths if p.strip("/")}))
+    runtime.include_vendored = include
+    runtime.source_file_cache.clear()
+
+
+def get_vendored_paths() -> tuple[str, ...]:
+    """Return the configured third-party directories."""
+    return current_runtime_context().vendored_paths
+
+
+def is_vendored_included() -> bool:
+    """Whether vendored code is analyzed like the rest (``--include-vendor``)."""
+    return current_runtime_context().include_vendored
+
+
+def set_path_globs(only: list[str], skip: list[str], *, root: str = "."):
+    """Keep files matching an ``only`` glob and no ``skip`` glob (``--only``, ``--skip``).
+
+    Globs are relative to ``root``, the scan path; with neither list the
+    filter is cleared.
+    """
+    runtime = current_runtime_context()
+    root = _normalize_path_separators(root).strip("/") or "."
+    runtime.path_globs = (
+        PathGlobs(only=tuple(only), skip=tuple(skip), root=root) if only or skip else None
+    )
+    runtime.source_file_cache.clear()
+
+
+def get_path_globs() -> PathGlobs | None:
+    """Return the active ``--only``/``--skip`` filter (``None`` = no filter)."""
+    return current_runtime_context().path_globs
+
+
 # ── File content cache & reading ──────────────────────────────
 
 
@@ -117,18 +265,33 @@ def _is_excluded_dir(name: str, rel_path: str, extra: tuple[str, ...]) -> bool:
     return in_default_exclusions or is_virtualenv_dir or matches_extra_exclusion
 
 
-def _clear_source_file_cache() -> None:
+def clear_source_file_cache() -> None:
+    """Drop cached discovery results (long-running callers like ``watch``)."""
     current_runtime_context().source_file_cache.clear()
 
 
+_clear_source_file_cache = clear_source_file_cache
+
+
 def _find_source_files_cached(
     path: str,
     extensions: tuple[str, ...],
     exclusions: tuple[str, ...] | None = None,
     extra_exclusions: tuple[str, ...] = (),
+    selected_dirs: tuple[str, ...] | None = None,
+    selected_files: frozenset[str] | None = None,
+    path_globs: PathGlobs | None = None,
 ) -> tuple[str, ...]:
     """Cached file discovery using os.walk — cross-platform, prunes during traversal."""
-    cache_key = (path, extensions, exclusions, extra_exclusions)
+    cache_key = (
+        path,
+        extensions,
+        exclusions,
+        extra_exclusions,
+        selected_dirs,
+        selected_files,
+        path_globs,
+    )
     cache = current_runtime_context().source_file_cache
     cached = cache.get(cache_key)
     if cached is not None:
@@ -140,6 +303,7 @@ def _find_source_files_cached(
         root = project_root / root
     all_exclusions = (exclusions or ()) + extra_exclusions
     ext_set = set(extensions)
+    selected = set(selected_dirs) if selected_dirs is not None else None
     files: list[str] = []
     for dirpath, dirnames, filenames in os.walk(root):
         rel_dir = _normalize_path_separators(_safe_relpath(dirpath, project_root))
@@ -148,14 +312,20 @@ def _find_source_files_cached(
             for d in dirnames
             if not _is_excluded_dir(d, rel_dir + "/" + d, all_exclusions)
         )
+        if selected is not None and rel_dir not in selected:
+            continue
         for fname in filenames:
             if any(fname.endswith(ext) for ext in ext_set):
                 full = os.path.join(dirpath, fname)
                 rel_file = _normalize_path_separators(_safe_relpath(full, project_root))
+                if selected_files is not None and rel_file not in selected_files:
+                    continue
                 if all_exclusions and any(
                     matches_exclusion(rel_file, ex) for ex in all_exclusions
                 ):
                     continue
+                if path_globs is not None and not path_globs.selects(rel_file):
+                    continue
                 files.append(rel_file)
     result = tuple(sorted(files))
     cache.put(cache_key, result)
@@ -173,6 +343,9 @@ def find_source_files(
             tuple(extensions),
             tuple(exclusions) if exclusions else None,
             get_exclusions(),
+            get_selected_dirs(),
+            get_selected_files(),
+            get_path_globs(),
         )
     )
 
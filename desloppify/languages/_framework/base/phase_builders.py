@@ -14,7 +14,9 @@ from .types import DetectorPhase
 
 
 def detector_phase_test_coverage() -> DetectorPhase:
-    return DetectorPhase("Test coverage", phase_test_coverage)
+    return DetectorPhase(
+        "Test coverage", phase_test_coverage, needs_package=True, requires="module"
+    )
 
 
 def detector_phase_security() -> DetectorPhase:
@@ -22,7 +24,9 @@ def detector_phase_security() -> DetectorPhase:
 
 
 def detector_phase_signature() -> DetectorPhase:
-    return DetectorPhase("Signature analysis", phase_signature)
+    return DetectorPhase(
+        "Signature analysis", phase_signature, needs_package=True, requires="module"
+    )
 
 
 def detector_phase_subjective_review() -> DetectorPhase:
@@ -30,7 +34,7 @@ def detector_phase_subjective_review() -> DetectorPhase:
 
 
 def detector_phase_duplicates() -> DetectorPhase:
-    return DetectorPhase("Duplicates", phase_dupes, slow=True)
+    return DetectorPhase("Duplicates", phase_dupes, slow=True, requires="module")
 
 
 def detector_phase_boilerplate_duplication() -> DetectorPhase:
@@ -38,6 +42,7 @@ def detector_phase_boilerplate_duplication() -> DetectorPhase:
         "Boilerplate duplication",
         phase_boilerplate_duplication,
         slow=True,
+        requires="module",
     )
 
 
@@ -23,6 +23,12 @@ FunctionExtractor = Callable[[Path], list[FunctionInfo]]
 FileFinder = Callable[[Path], list[str]]
 
 
+# What a rule or phase needs loaded, cheapest first: the file's syntax alone,
+# type information (external type-checking tools), or the whole module graph
+# (cross-file and cross-package analysis).
+RULE_LEVELS = ("syntax", "types", "module")
+
+
 @dataclass
 class DetectorPhase:
     """A single phase in the scan pipeline.
@@ -30,11 +36,18 @@ class DetectorPhase:
     Each phase runs one or more detectors and returns normalized findings.
     The `run` function handles both detection AND normalization (converting
     raw detector output to findings with tiers/confidence).
+
+    `needs_package` marks phases that depend on the surrounding package being
+    on disk (type-aware external tools, cross-file analysis); they are skipped
+    in syntax-only runs such as stdin analysis of a not-yet-saved file or
+    ``scan --fast``. `requires` is the phase's level in ``RULE_LEVELS``.
     """
 
     label: str
     run: Callable[[Path, LangRun], tuple[list[dict[str, Any]], dict[str, int]]]
     slow: bool = False
+    needs_package: bool = False
+    requires: str = "syntax"
 
 
 @dataclass
@@ -61,6 +74,21 @@ class DetectorCoverageRecord(TypedDict, total=False):
     reason: str
 
 
+class DegradedUnitRecord(TypedDict, total=False):
+    """A file or package analyzed with fewer rules because it does not build.
+
+    ``skipped`` is the ``RULE_LEVELS`` entry that could not run: "syntax" for
+    a file that does not parse (text-level rules still ran), "types" for a
+    package that does not load or type-check (syntax rules still ran).
+    """
+
+    unit: str
+    kind: Literal["file", "package"]
+    skipped: str
+    phase: str
+    error: str
+
+
 class ScanCoverageRecord(TypedDict, total=False):
     """Persisted scan-level coverage snapshot for one language run."""
 
@@ -68,6 +96,7 @@ class ScanCoverageRecord(TypedDict, total=False):
     confidence: float
     detectors: dict[str, DetectorCoverageRecord]
     warnings: list[DetectorCoverageRecord]
+    degraded: list[DegradedUnitRecord]
     updated_at: str
 
 
@@ -106,6 +135,9 @@ class FixerConfig:
     dry_verb: str = "Would fix"
     # Signature: (path, state, prev_score, dry_run, *, lang=None) -> None
     post_fix: Callable[..., None] | None = None
+    # Signature: (state, results, fixer_name) -> resolved finding ids; replaces
+    # the default ``detector::file::name`` match for aggregated findings.
+    resolve: Callable[[dict, list[dict], str], list[str]] | None = None
 
 
 @dataclass
@@ -194,6 +226,48 @@ class LangConfig:
     )
     migration_mixed_extensions: set[str] = field(default_factory=set)
 
+    # Per-rule catalog for `langs --rules`: dicts with id, label, severity,
+    # requires (a RULE_LEVELS entry), and opt_in.
+    rule_catalog: Callable[[], list[dict[str, Any]]] | None = None
+
+    # Resolve package patterns (Go's ``./...``) for `scan PATTERN...`:
+    # (patterns, runtime options) -> (package dirs, files), project-relative.
+    # Raises ValueError when the patterns cannot be resolved.
+    resolve_patterns: (
+        Callable[[list[str], dict[str, Any]], tuple[list[str], list[str]]] | None
+    ) = None
+
+    # Resolve ``scan --module NAME...`` the same way: (names, scan path) ->
+    # (package dirs, files), project-relative. Raises ValueError for a name
+    # that matches no module.
+    resolve_modules: (
+        Callable[[list[str], Path], tuple[list[str], list[str]]] | None
+    ) = None
+
+    # Packages whose findings can change when the given ones do, for
+    # ``scan --changed`` and ``--since``: (package dirs, scan path) -> dirs,
+    # project-relative (Go: same-module importers).
+    dependent_dirs: Callable[[list[str], Path], list[str]] | None = None
+
+    # The module a finding's file belongs to, stamped on it as ``module``
+    # (Go: the path of the innermost enclosing go.mod). None leaves it off.
+    module_of: Callable[[str], str | None] | None = None
+
+    # Vendored and third-party files under the scan path that the scan leaves
+    # out (Go: ``vendor/`` plus ``third_party_paths``). Findings located in
+    # them are dropped, even from rules that read them as graph nodes.
+    vendored_finder: Callable[[Path], list[str]] | None = None
+
+    # Check the raw ``languages.<name>`` config beyond its setting types
+    # (rule ids, rule options): (settings, config path) -> problem messages.
+    # Unknown setting keys are reported by the caller.
+    check_settings: Callable[[dict[str, Any], Path], list[str]] | None = None
+
+    # Categories rules are tagged with, for ``scan --category`` and
+    # ``--exclude-category``, which set the ``categories`` and
+    # ``exclude_categories`` settings. Empty when rules are not categorized.
+    rule_categories: tuple[str, ...] = ()
+
     # Zone classification rules
     zone_rules: list[ZoneRule] = field(default_factory=list)
 
@@ -0,0 +1,338 @@
+"""Shared fixer file loops: read, transform, and write back or diff.
+
+Line fixers supply a ``transform_fn(lines, file_entries) ->
+(new_lines, removed_names)``. Edit fixers supply ``find_edits(file,
+content)``, which re-runs detection on the current text; several rules'
+edits are applied together, non-overlapping ones per pass, until nothing is
+left to fix or ``max_passes`` runs out. The real run and ``fix --dry-run``
+share the whole path; a dry run skips only the write and carries the
+unified diff of what would have been written on each result instead.
+"""
+
+from __future__ import annotations
+
+import difflib
+import logging
+import sys
+from collections import Counter
+from collections.abc import Callable
+from dataclasses import dataclass, field
+from pathlib import Path
+
+from desloppify.core._internal.text_utils import get_project_root
+from desloppify.core.fallbacks import log_best_effort_failure
+from desloppify.file_discovery import rel, safe_write_text
+from desloppify.languages._framework.base.types import FixResult
+from desloppify.utils import colorize
+
+logger = logging.getLogger(__name__)
+
+TransformFn = Callable[[list[str], list[dict]], tuple[list[str], list[str]]]
+# Re-parsing means re-running detection, so one edit fixer can run many passes.
+DEFAULT_MAX_PASSES = 10
+
+
+@dataclass(frozen=True)
+class Edit:
+    """Replace ``content[start:end]`` with ``new``, on behalf of ``rule``."""
+
+    start: int
+    end: int
+    new: str
+    rule: str
+
+
+@dataclass
+class FixpointResult:
+    """Where repeated edit passes over one file's content ended up."""
+
+    content: str
+    applied: list[Edit] = field(default_factory=list)
+    skipped: list[Edit] = field(default_factory=list)
+    passes: int = 0
+
+
+FindEdits = Callable[[str], list[Edit]]
+# Reformats fixed content; None means it no longer parses.
+FormatFn = Callable[[str], str | None]
+
+
+def _group_entries(entries: list[dict], file_key: str) -> dict[str, list[dict]]:
+    grouped: dict[str, list[dict]] = {}
+    for entry in entries:
+        filepath = entry.get(file_key)
+        if not isinstance(filepath, str) or not filepath:
+            continue
+        grouped.setdefault(filepath, []).append(entry)
+    return grouped
+
+
+def unified_diff(filepath: str, before: str, after: str) -> str:
+    """Git-style unified diff of one file, labelled ``a/<rel>`` and ``b/<rel>``."""
+    label = rel(filepath)
+    lines = difflib.unified_diff(
+        before.splitlines(keepends=True),
+        after.splitlines(keepends=True),
+        fromfile=f"a/{label}",
+        tofile=f"b/{label}",
+    )
+    return "".join(
+        line if line.endswith("\n") else line + "\n\\ No newline at end of file\n"
+        for line in lines
+    )
+
+
+def line_edit(content: str, line: int, old: str, new: str, rule: str) -> Edit | None:
+    """The ``Edit`` for a ``{"line", "old", "new"}`` fix, or None if ``old`` is gone.
+
+    ``old`` starts on ``line`` and may run on over the lines below it.
+    """
+    lines = content.splitlines(keepends=True)
+    if line < 1 or line > len(lines) or not old:
+        return None
+    line_start = sum(len(text) for text in lines[: line - 1])
+    start = content.find(old, line_start)
+    if start < 0 or start >= line_start + len(lines[line - 1]):
+        return None
+    return Edit(start, start + len(old), new, rule)
+
+
+def apply_edits(content: str, edits: list[Edit]) -> tuple[str, list[Edit], list[Edit]]:
+    """Apply the edits that do not overlap, in one pass over ``content``.
+
+    Returns (new content, applied, conflicting). Edits are taken in source
+    order; one that overlaps an edit already taken, or starts at the same
+    offset, waits for the next pass. Identical replacements count once.
+    """
+    unique: dict[tuple[int, int, str], Edit] = {}
+    for edit in edits:
+        unique.setdefault((edit.start, edit.end, edit.new), edit)
+    applied: list[Edit] = []
+    conflicting: list[Edit] = []
+    for edit in sorted(unique.values(), key=lambda e: (e.start, e.end, e.rule)):
+        if applied and (edit.start < applied[-1].end or edit.start == applied[-1].start):
+            conflicting.append(edit)
+            continue
+        applied.append(edit)
+    pieces: list[str] = []
+    position = 0
+    for edit in applied:
+        pieces.extend((content[position : edit.start], edit.new))
+        position = edit.end
+    pieces.append(content[position:])
+    return "".join(pieces), applied, conflicting
+
+
+def fix_to_fixpoint(
+    content: str, find_edits: FindEdits, *, max_passes: int = DEFAULT_MAX_PASSES
+) -> FixpointResult:
+    """Apply, re-detect and repeat until no edits are left or the cap is hit.
+
+    Edits still reported at the end, because they kept conflicting or made
+    no progress, come back as ``skipped``.
+    """
+    result = FixpointResult(content)
+    for _ in range(max_passes):
+        edits = find_edits(result.content)
+        if not edits:
+            return result
+        updated, applied, _conflicting = apply_edits(result.content, edits)
+        if updated == result.content:
+            result.skipped = edits
+            return result
+        result.content = updated
+        result.applied.extend(applied)
+        result.passes += 1
+    result.skipped = find_edits(result.content)
+    return result
+
+
+def apply_fixer(
+    entries: list[dict],
+    transform_fn: TransformFn,
+    *,
+    dry_run: bool = False,
+    file_key: str = "file",
+    lang_label: str = "language",
+) -> list[dict]:
+    """Group *entries* by file, transform each file, and write back if changed.
+
+    With ``dry_run`` nothing is written and each result gets a ``diff``.
+    """
+    by_file = _group_entries(entries, file_key)
+    results = []
+    skipped_files: list[tuple[str, str]] = []
+    for filepath, file_entries in sorted(by_file.items()):
+        try:
+            changed = _process_fixer_file(
+                filepath,
+                file_entries,
+                transform_fn=transform_fn,
+                dry_run=dry_run,
+                lang_label=lang_label,
+            )
+            if changed is not None:
+                results.append(changed)
+        except (OSError, UnicodeDecodeError) as ex:
+            skipped_files.append((filepath, str(ex)))
+            print(colorize(f"  Skip {rel(filepath)}: {ex}", "yellow"), file=sys.stderr)
+
+    if skipped_files:
+        log_best_effort_failure(
+            logger,
+            f"apply {lang_label} fixer across {len(skipped_files)} skipped file(s)",
+            OSError(
+                "; ".join(f"{path}: {reason}" for path, reason in skipped_files[:5])
+            ),
+        )
+
+    return results
+
+
+def _process_fixer_file(
+    filepath: str,
+    file_entries: list[dict],
+    *,
+    transform_fn: TransformFn,
+    dry_run: bool,
+    lang_label: str,
+) -> dict[str, object] | None:
+    p = Path(filepath) if Path(filepath).is_absolute() else get_project_root() / filepath
+    original = p.read_text()
+    lines = original.splitlines(keepends=True)
+
+    new_lines, removed_names = transform_fn(lines, file_entries)
+    new_content = "".join(new_lines)
+    if new_content == original:
+        return None
+
+    lines_removed = len(original.splitlines()) - len(new_content.splitlines())
+    result: dict[str, object] = {
+        "file": filepath,
+        "removed": removed_names,
+        "lines_removed": lines_removed,
+    }
+    if dry_run:
+        result["diff"] = unified_diff(filepath, original, new_content)
+    else:
+        _write_fixer_content(p, new_content, lang_label)
+    return result
+
+
+def apply_edit_fixer(
+    entries: list[dict],
+    find_edits: Callable[[str, str], list[Edit]],
+    *,
+    dry_run: bool = False,
+    file_key: str = "file",
+    lang_label: str = "language",
+    max_passes: int = DEFAULT_MAX_PASSES,
+    format_fn: FormatFn | None = None,
+) -> FixResult:
+    """Fix each file named in *entries* to a fixpoint of ``find_edits(file, content)``.
+
+    Edits that still conflict when the passes end are skipped with a
+    warning and counted in ``skip_reasons``. With ``format_fn`` the fixed
+    content is reformatted before it is written or diffed; a file it
+    rejects is left untouched and counted as ``unformattable``. A file that
+    was not already in formatted shape is only checked, not reformatted,
+    so lines the fixes did not touch stay byte-identical.
+    """
+    results: list[dict] = []
+    skip_reasons: Counter[str] = Counter()
+    for filepath in sorted(_group_entries(entries, file_key)):
+        try:
+            changed = _fix_file_to_fixpoint(
+                filepath,
+                find_edits,
+                dry_run=dry_run,
+                lang_label=lang_label,
+                max_passes=max_passes,
+                skip_reasons=skip_reasons,
+                format_fn=format_fn,
+            )
+        except (OSError, UnicodeDecodeError) as ex:
+            print(colorize(f"  Skip {rel(filepath)}: {ex}", "yellow"), file=sys.stderr)
+            continue
+        if changed is not None:
+            results.append(changed)
+    return FixResult(entries=results, skip_reasons=dict(skip_reasons))
+
+
+def _fix_file_to_fixpoint(
+    filepath: str,
+    find_edits: Callable[[str, str], list[Edit]],
+    *,
+    dry_run: bool,
+    lang_label: str,
+    max_passes: int,
+    skip_reasons: Counter[str],
+    format_fn: FormatFn | None = None,
+) -> dict[str, object] | None:
+    p = Path(filepath) if Path(filepath).is_absolute() else get_project_root() / filepath
+    original = p.read_text()
+    fixed = fix_to_fixpoint(
+        original, lambda content: find_edits(filepath, content), max_passes=max_passes
+    )
+    if fixed.skipped:
+        skip_reasons["conflicting_edit"] += len(fixed.skipped)
+        rules = ", ".join(sorted({edit.rule for edit in fixed.skipped}))
+        print(
+            colorize(
+                f"  Skip {len(fixed.skipped)} conflicting edit(s) in {rel(filepath)} "
+                f"after {fixed.passes} pass(es): {rules}",
+                "yellow",
+            ),
+            file=sys.stderr,
+        )
+    if fixed.content == original:
+        return None
+    if format_fn is not None:
+        formatted = format_fn(fixed.content)
+        if formatted is None:
+            skip_reasons["unformattable"] += 1
+            print(
+                colorize(
+                    f"  Skip {rel(filepath)}: fixed source does not format, left unchanged",
+                    "yellow",
+                ),
+                file=sys.stderr,
+            )
+            return None
+        if format_fn(original) == original:
+            fixed.content = formatted
+
+    result: dict[str, object] = {
+        "file": filepath,
+        "removed": [edit.rule for edit in fixed.applied],
+        "lines_removed": len(original.splitlines()) - len(fixed.content.splitlines()),
+        "passes": fixed.passes,
+    }
+    if dry_run:
+        result["diff"] = unified_diff(filepath, original, fixed.content)
+    else:
+        _write_fixer_content(p, fixed.content, lang_label)
+    return result
+
+
+def _write_fixer_content(path: Path, content: str, lang_label: str) -> None:
+    try:
+        safe_write_text(path, content)
+    except OSError as exc:
+        log_best_effort_failure(logger, f"write {lang_label} fixer output {path}", exc)
+        raise
+
+
+__all__ = [
+    "DEFAULT_MAX_PASSES",
+    "Edit",
+    "FixpointResult",
+    "FormatFn",
+    "TransformFn",
+    "apply_edit_fixer",
+    "apply_edits",
+    "apply_fixer",
+    "fix_to_fixpoint",
+    "line_edit",
+    "unified_diff",
+]
@@ -49,7 +49,7 @@ def make_tool_phase(
         ]
         return findings, {smell_id: len(entries)}
 
-    return DetectorPhase(label, run)
+    return DetectorPhase(label, run, needs_package=True, requires="types")
 
 
 def make_detect_fn(cmd: str, parser: Callable[[str, Path], list[dict]]) -> Callable:
@@ -0,0 +1,49 @@
+"""Worker pool for running detectors package by package."""
+
+from __future__ import annotations
+
+import os
+from collections.abc import Callable, Iterator, Sequence
+from concurrent.futures import ProcessPoolExecutor
+from typing import TypeVar
+
+T = TypeVar("T")
+R = TypeVar("R")
+
+
+def default_jobs() -> int:
+    """Worker count when ``--jobs`` is not given: ``GOMAXPROCS``, else CPU count."""
+    raw = os.environ.get("GOMAXPROCS", "").strip()
+    if raw.isdigit() and int(raw) > 0:
+        return int(raw)
+    return os.cpu_count() or 1
+
+
+def resolve_jobs(requested: int | None) -> int:
+    """``requested`` when positive, otherwise ``default_jobs()``."""
+    if isinstance(requested, int) and requested > 0:
+        return requested
+    return default_jobs()
+
+
+def map_packages(
+    fn: Callable[[T], R], packages: Sequence[T], *, jobs: int
+) -> Iterator[R]:
+    """Yield ``fn(package)`` for each package, in input order.
+
+    With ``jobs > 1`` each call runs in a worker process, so detector state
+    never crosses packages and a package's sources are freed as soon as its
+    worker returns. ``fn`` must be picklable (a module-level function or a
+    ``functools.partial`` of one). Results come back in submission order
+    whatever order the workers finish in.
+    """
+    if jobs <= 1 or len(packages) < 2:
+        yield from map(fn, packages)
+        return
+    workers = min(jobs, len(packages))
+    chunksize = max(1, len(packages) // (workers * 4))
+    with ProcessPoolExecutor(max_workers=workers) as pool:
+        yield from pool.map(fn, packages, chunksize=chunksize)
+
+
+__all__ = ["default_jobs", "map_packages", "resolve_jobs"]
@@ -7,11 +7,13 @@ from dataclasses import dataclass, field, fields
 from typing import TYPE_CHECKING, Any
 
 from desloppify.languages._framework.base.types import (
+    DegradedUnitRecord,
     DetectorCoverageRecord,
     LangConfig,
 )
 
 if TYPE_CHECKING:
+    from desloppify.core.result_cache import ResultCache
     from desloppify.engine.policy.zones import FileZoneMap
 
 _UNSET = object()
@@ -30,8 +32,14 @@ class LangRuntimeState:
     runtime_options: dict[str, Any] = field(default_factory=dict)
     large_threshold_override: int = 0
     props_threshold_override: int = 0
+    jobs: int = 0
+    result_cache: ResultCache | None = None
+    syntax_only: bool = False
+    full_matches: bool = False
     detector_coverage: dict[str, DetectorCoverageRecord] = field(default_factory=dict)
     coverage_warnings: list[DetectorCoverageRecord] = field(default_factory=list)
+    degraded_units: list[DegradedUnitRecord] = field(default_factory=list)
+    finding_overlap: dict[str, int] = field(default_factory=dict)
 
 
 @dataclass
@@ -47,6 +55,10 @@ class LangRunOverrides:
     runtime_options: dict[str, Any] | None = _UNSET
     large_threshold_override: int | None = _UNSET
     props_threshold_override: int | None = _UNSET
+    jobs: int | None = _UNSET
+    result_cache: ResultCache | None = _UNSET
+    syntax_only: bool | None = _UNSET
+    full_matches: bool | None = _UNSET
     detector_coverage: dict[str, DetectorCoverageRecord] | None = _UNSET
     coverage_warnings: list[DetectorCoverageRecord] | None = _UNSET
 
@@ -119,6 +131,26 @@ class LangRun:
             return override
         return 14
 
+    @property
+    def jobs(self) -> int:
+        """Requested package worker count; 0 means pick a default."""
+        return self.state.jobs
+
+    @property
+    def result_cache(self) -> ResultCache | None:
+        """Per-package result cache, or ``None`` when caching is off."""
+        return self.state.result_cache
+
+    @property
+    def syntax_only(self) -> bool:
+        """Whether only syntax-level rules run (``scan --fast``)."""
+        return self.state.syntax_only
+
+    @property
+    def full_matches(self) -> bool:
+        """Whether aggregated findings keep every match instead of a sample."""
+        return self.state.full_matches
+
     def runtime_setting(self, key: str, default: Any = None) -> Any:
         if key in self.state.runtime_settings:
             return self.state.runtime_settings[key]
@@ -151,6 +183,31 @@ class LangRun:
     def coverage_warnings(self, value: list[DetectorCoverageRecord]) -> None:
         self.state.coverage_warnings = value
 
+    @property
+    def finding_overlap(self) -> dict[str, int]:
+        """Finding counts before and after overlapping findings were merged."""
+        return self.state.finding_overlap
+
+    @finding_overlap.setter
+    def finding_overlap(self, value: dict[str, int]) -> None:
+        self.state.finding_overlap = value
+
+    @property
+    def degraded_units(self) -> list[DegradedUnitRecord]:
+        """Files and packages that were analyzed with fewer rules this run."""
+        return self.state.degraded_units
+
+    def record_degraded(
+        self, unit: str, *, kind: str, skipped: str, phase: str, error: str
+    ) -> None:
+        """Note that ``unit`` skipped the ``skipped`` rule level (once per unit/level)."""
+        for record in self.state.degraded_units:
+            if record["unit"] == unit and record["skipped"] == skipped:
+                return
+        self.state.degraded_units.append(
+            {"unit": unit, "kind": kind, "skipped": skipped, "phase": phase, "error": error}
+        )
+
 
 def make_lang_run(
     lang: LangConfig | LangRun,
@@ -193,6 +250,14 @@ def make_lang_run(
         runtime.state.props_threshold_override = int(
             resolved.props_threshold_override or 0
         )
+    if resolved.jobs is not _UNSET:
+        runtime.state.jobs = int(resolved.jobs or 0)
+    if resolved.result_cache is not _UNSET:
+        runtime.state.result_cache = resolved.result_cache
+    if resolved.syntax_only is not _UNSET:
+        runtime.state.syntax_only = bool(resolved.syntax_only)
+    if resolved.full_matches is not _UNSET:
+        runtime.state.full_matches = bool(resolved.full_matches)
     if resolved.detector_coverage is not _UNSET:
         runtime.state.detector_coverage = resolved.detector_coverage or {}
     if resolved.coverage_warnings is not _UNSET:
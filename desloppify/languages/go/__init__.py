@@ -16,19 +16,39 @@ from desloppify.languages._framework.base.phase_builders import (
     detector_phase_test_coverage,
     shared_subjective_duplicates_tail,
 )
-from desloppify.languages._framework.base.types import DetectorPhase, LangConfig
+from desloppify.languages._framework.base.types import (
+    DetectorPhase,
+    FixerConfig,
+    LangConfig,
+    LangValueSpec,
+)
 from desloppify.languages._framework.generic import make_tool_phase
 from desloppify.languages._framework.treesitter.phases import all_treesitter_phases
 from desloppify.languages.go import test_coverage as go_test_coverage_hooks
 from desloppify.languages.go.commands import get_detect_commands
+from desloppify.languages.go.detectors.custom_rules import (
+    custom_rule_catalog,
+    load_custom_rules,
+)
 from desloppify.languages.go.detectors.deps import build_dep_graph as build_go_dep_graph
 from desloppify.languages.go.detectors.security import detect_go_security
+from desloppify.languages.go.detectors.smells import smell_rule_catalog
 from desloppify.languages.go.extractors import (
     GO_FILE_EXCLUSIONS,
     extract_functions,
     find_go_files,
+    find_vendored_go_files,
+)
+from desloppify.languages.go.modules import module_of, resolve_modules
+from desloppify.languages.go.package_keys import dependent_dirs
+from desloppify.languages.go.packages import resolve_package_patterns
+from desloppify.languages.go.rule_options import check_settings
+from desloppify.languages.go.rules import CATEGORIES, load_configured_plugins
+from desloppify.languages.go.phases import (
+    _phase_smells,
+    _phase_structural,
+    _phase_vet,
 )
-from desloppify.languages.go.phases import _phase_smells, _phase_structural
 from desloppify.languages.go.review import (
     HOLISTIC_REVIEW_DIMENSIONS,
     LOW_VALUE_PATTERN,
@@ -48,6 +68,53 @@ GO_ZONE_RULES = [
 register_lang_hooks("go", test_coverage=go_test_coverage_hooks)
 
 
+def _get_go_fixers() -> dict[str, FixerConfig]:
+    """Build the Go fixer registry; fix modules load when the fixer runs."""
+
+    def _fixer(rules):
+        def detect(path):
+            from desloppify.languages.go.fixers.smell_edits import detect_fixable
+
+            return detect_fixable(path, rules)
+
+        def fix(entries, *, dry_run=False):
+            from desloppify.languages.go.fixers.smell_edits import fix_smells
+
+            return fix_smells(entries, dry_run=dry_run, rules=rules)
+
+        return detect, fix
+
+    def _resolve(state, results, fixer_name):
+        from desloppify.languages.go.fixers.smell_edits import resolve_smell_fixes
+
+        return resolve_smell_fixes(state, results, fixer_name)
+
+    return {
+        "smells": FixerConfig(
+            "fixable smells",
+            *_fixer(None),
+            "smells",
+            "Fixed",
+            "Would fix",
+            resolve=_resolve,
+        ),
+        "yoda-conditions": FixerConfig(
+            "yoda conditions",
+            *_fixer(frozenset({"yoda_condition"})),
+            "smells",
+            "Rewrote",
+            "Would rewrite",
+            resolve=_resolve,
+        ),
+    }
+
+
+def _rule_catalog() -> list[dict]:
+    """Built-in smells, plugin rules, and the project's ``custom_rules``."""
+    load_configured_plugins()
+    return smell_rule_catalog() + custom_rule_catalog(load_custom_rules())
+
+
 @register_lang("go")
 class GoConfig(LangConfig):
     """Go language configuration."""
@@ -74,8 +141,8 @@ class GoConfig(LangConfig):
                     "golangci_lint",
                     tier=2,
                 ),
-                make_tool_phase(
-                    "go vet", "go vet ./...", "gnu", "vet_error", tier=3
+                DetectorPhase(
+                    "go vet", _phase_vet, needs_package=True, requires="types"
                 ),
                 *all_treesitter_phases("go"),
                 detector_phase_signature(),
@@ -83,7 +150,7 @@ class GoConfig(LangConfig):
                 detector_phase_security(),
                 *shared_subjective_duplicates_tail(),
             ],
-            fixers={},
+            fixers=_get_go_fixers(),
             get_area=get_area,
             detect_commands=get_detect_commands(),
             boundaries=[],
@@ -92,6 +159,60 @@ class GoConfig(LangConfig):
             large_threshold=500,
             complexity_threshold=15,
             default_scan_profile="full",
+            setting_specs={
+                "opt_in_smells": LangValueSpec(
+                    list,
+                    [],
+                    "Opt-in Go smell ids to enable (e.g. struct_field_alignment)",
+                ),
+                "custom_rules": LangValueSpec(
+                    list,
+                    [],
+                    "House rules: forbid-import, forbid-call, forbid-identifier, "
+                    "required-call-pairing (see docs/go-quality-pipeline.md)",
+                ),
+                "rule_plugins": LangValueSpec(
+                    list,
+                    [],
+                    "Modules or .py files that register Go rules through "
+                    "desloppify.languages.go.rules",
+                ),
+                "rule_options": LangValueSpec(
+                    dict,
+                    {},
+                    "Per-rule options {rule id: {option: value}} "
+                    "(e.g. too_many_params: {max: 7})",
+                ),
+                "categories": LangValueSpec(
+                    list,
+                    [],
+                    "Only run rules in these categories (also: scan --category); "
+                    "empty runs every category",
+                ),
+                "exclude_categories": LangValueSpec(
+                    list,
+                    [],
+                    "Skip rules in these categories (also: scan --exclude-category)",
+                ),
+                "third_party_paths": LangValueSpec(
+                    list,
+                    [],
+                    "Directories of third-party or forked code skipped like vendor/ "
+                    "(e.g. third_party, internal/forks)",
+                ),
+            },
+            runtime_option_specs={
+                "build_tags": LangValueSpec(
+                    str,
+                    "",
+                    "Comma-separated build tags for go list and go vet (also: scan --tags)",
+                ),
+                "include_vendor": LangValueSpec(
+                    bool,
+                    False,
+                    "Analyze vendor/ and third_party_paths too (also: scan --include-vendor)",
+                ),
+            },
             detect_markers=["go.mod"],
             external_test_dirs=[],
             test_file_extensions=[".go"],
@@ -104,4 +225,12 @@ class GoConfig(LangConfig):
             migration_mixed_extensions=MIGRATION_MIXED_EXTENSIONS,
             extract_functions=extract_functions,
             zone_rules=GO_ZONE_RULES,
+            rule_catalog=_rule_catalog,
+            resolve_patterns=resolve_package_patterns,
+            resolve_modules=resolve_modules,
+            dependent_dirs=dependent_dirs,
+            module_of=module_of,
+            vendored_finder=find_vendored_go_files,
+            check_settings=check_settings,
+            rule_categories=CATEGORIES,
         )
@@ -0,0 +1,218 @@
+"""Which Go files the active build configuration includes.
+
+Mirrors ``go/build``: a file is left out when its name ends in a
+``_GOOS``/``_GOARCH`` suffix for another platform, or when its
+``//go:build`` line (or legacy ``// +build`` lines) before the package
+clause evaluates false. The configuration is ``GOOS``/``GOARCH`` from the
+environment (defaulting to the host), ``unix`` where it applies, ``gc``,
+``cgo`` unless ``CGO_ENABLED=0``, every ``go1.N`` release tag, and the
+tags given with ``--tags``. ``ignore`` is never set, so generator scripts
+marked ``//go:build ignore`` are skipped as the go command skips them.
+"""
+
+from __future__ import annotations
+
+import os
+import platform
+import re
+import sys
+from dataclasses import dataclass
+
+KNOWN_OS = frozenset(
+    "aix android darwin dragonfly freebsd hurd illumos ios js linux nacl netbsd"
+    " openbsd plan9 solaris wasip1 windows zos".split()
+)
+KNOWN_ARCH = frozenset(
+    "386 amd64 amd64p32 arm armbe arm64 arm64be loong64 mips mipsle mips64"
+    " mips64le mips64p32 mips64p32le ppc ppc64 ppc64le riscv riscv64 s390 s390x"
+    " sparc sparc64 wasm".split()
+)
+_UNIX_OS = frozenset(
+    "aix android darwin dragonfly freebsd hurd illumos ios linux netbsd openbsd"
+    " solaris".split()
+)
+# GOOS values that also satisfy another GOOS tag and file suffix.
+_IMPLIED_OS = {"android": "linux", "illumos": "solaris", "ios": "darwin"}
+_HOST_ARCH = {
+    "x86_64": "amd64",
+    "amd64": "amd64",
+    "aarch64": "arm64",
+    "arm64": "arm64",
+    "i386": "386",
+    "i686": "386",
+    "x86": "386",
+    "armv7l": "arm",
+    "armv6l": "arm",
+    "ppc64le": "ppc64le",
+    "s390x": "s390x",
+    "riscv64": "riscv64",
+}
+
+_GO_BUILD_RE = re.compile(r"^//go:build[ \t]+(.+?)[ \t]*$")
+_PLUS_BUILD_RE = re.compile(r"^//[ \t]*\+build(?:[ \t]+(.*?))?[ \t]*$")
+_TOKEN_RE = re.compile(r"\s*(\(|\)|!|&&|\|\||[\w.]+)")
+_RELEASE_RE = re.compile(r"^go1\.\d+$")
+
+
+def _host_os() -> str:
+    if sys.platform.startswith("win"):
+        return "windows"
+    for goos in KNOWN_OS:
+        if sys.platform.startswith(goos):
+            return goos
+    return "linux"
+
+
+@dataclass(frozen=True)
+class BuildContext:
+    """The target platform and tags that decide file inclusion."""
+
+    goos: str
+    goarch: str
+    tags: frozenset[str] = frozenset()
+
+    @classmethod
+    def from_environment(cls, tags: tuple[str, ...] | list[str] = ()) -> BuildContext:
+        goos = os.environ.get("GOOS") or _host_os()
+        goarch = os.environ.get("GOARCH") or _HOST_ARCH.get(
+            platform.machine().lower(), "amd64"
+        )
+        active = {goos, goarch, "gc", *tags}
+        if goos in _IMPLIED_OS:
+            active.add(_IMPLIED_OS[goos])
+        if goos in _UNIX_OS:
+            active.add("unix")
+        if os.environ.get("CGO_ENABLED", "1") != "0":
+            active.add("cgo")
+        return cls(goos, goarch, frozenset(active))
+
+    def has_tag(self, tag: str) -> bool:
+        return tag in self.tags or bool(_RELEASE_RE.match(tag))
+
+    def matches_filename(self, filename: str) -> bool:
+        """Whether a ``_GOOS``/``_GOARCH`` file name suffix admits this platform."""
+        stem = os.path.basename(filename).split(".", 1)[0].removesuffix("_test")
+        parts = stem.split("_")
+        if len(parts) < 2:
+            return True
+        last, previous = parts[-1], parts[-2] if len(parts) >= 3 else None
+        if previous in KNOWN_OS and last in KNOWN_ARCH:
+            return self.has_tag(previous) and last == self.goarch
+        if last in KNOWN_OS:
+            return self.has_tag(last)
+        if last in KNOWN_ARCH:
+            return last == self.goarch
+        return True
+
+    def includes(self, filename: str, content: str) -> bool:
+        """Whether the go command would build ``filename`` in this context."""
+        if not self.matches_filename(filename):
+            return False
+        expression = build_constraint(content)
+        return expression is None or evaluate(expression, self.has_tag)
+
+
+def build_constraint(content: str) -> str | None:
+    """The file's constraint as a ``//go:build`` expression, or None.
+
+    Only the leading comment block counts. Legacy ``// +build`` lines are
+    translated (space = or, comma = and, lines and-ed) when no
+    ``//go:build`` line is present.
+    """
+    go_build: str | None = None
+    plus_build: list[str] = []
+    in_block_comment = False
+    for raw in content.splitlines():
+        line = raw.strip()
+        if in_block_comment:
+            in_block_comment = "*/" not in line
+            continue
+        if not line:
+            continue
+        if line.startswith("/*"):
+            in_block_comment = "*/" not in line[2:]
+            continue
+        if not line.startswith("//"):
+            break
+        if go_build is None and (m := _GO_BUILD_RE.match(line)):
+            go_build = m.group(1)
+        elif (m := _PLUS_BUILD_RE.match(line)) and m.group(1):
+            plus_build.append(_translate_plus_build(m.group(1)))
+    if go_build is not None:
+        return go_build
+    if plus_build:
+        return " && ".join(f"({clause})" for clause in plus_build)
+    return None
+
+
+def _translate_plus_build(line: str) -> str:
+    options = [
+        "(" + " && ".join(term for term in option.split(",") if term) + ")"
+        for option in line.split()
+    ]
+    return " || ".join(options)
+
+
+def evaluate(expression: str, has_tag) -> bool:
+    """Evaluate a ``//go:build`` expression; malformed ones evaluate false."""
+    tokens: list[str] = []
+    position = 0
+    while position < len(expression):
+        match = _TOKEN_RE.match(expression, position)
+        if match is None:
+            if expression[position:].strip():
+                return False
+            break
+        tokens.append(match.group(1))
+        position = match.end()
+    parser = _Parser(tokens, has_tag)
+    try:
+        value = parser.parse_or()
+    except (IndexError, ValueError):
+        return False
+    return value and parser.index == len(tokens)
+
+
+class _Parser:
+    def __init__(self, tokens: list[str], has_tag) -> None:
+        self.tokens = tokens
+        self.index = 0
+        self.has_tag = has_tag
+
+    def _next(self) -> str:
+        token = self.tokens[self.index]
+        self.index += 1
+        return token
+
+    def _peek(self) -> str | None:
+        return self.tokens[self.index] if self.index < len(self.tokens) else None
+
+    def parse_or(self) -> bool:
+        value = self.parse_and()
+        while self._peek() == "||":
+            self._next()
+            value = self.parse_and() or value
+        return value
+
+    def parse_and(self) -> bool:
+        value = self.parse_not()
+        while self._peek() == "&&":
+            self._next()
+            value = self.parse_not() and value
+        return value
+
+    def parse_not(self) -> bool:
+        token = self._next()
+        if token == "!":
+            return not self.parse_not()
+        if token == "(":
+            value = self.parse_or()
+            if self._next() != ")":
+                raise ValueError("unbalanced parenthesis")
+            return value
+        if token in {")", "&&", "||"}:
+            raise ValueError(f"unexpected {token}")
+        return self.has_tag(token)
+
+
+__all__ = ["BuildContext", "KNOWN_ARCH", "KNOWN_OS", "build_constraint", "evaluate"]
@@ -0,0 +1,173 @@
+"""Deciding whether a Go expression is a compile-time constant.
+
+Works on masked source (literal contents blanked, quotes kept), so it sees
+the shape of an expression, not its value. Constants are literals, ``true``,
+``false``, ``iota``, names declared with ``const`` anywhere in the package,
+and operators, parentheses, conversions and the ``len``/``min``/``max``
+builtins applied to constants.
+
+``deterministic`` also accepts a call chain that starts at an imported
+package and passes only constants, such as ``template.New("x").Parse(page)``:
+like a constant it gives the same result on every run, so wrapping it in a
+``Must`` helper fails at the first run or never.
+"""
+
+from __future__ import annotations
+
+import re
+
+from desloppify.languages.go.detectors._inspector import GoFile
+
+_TOKEN_RE = re.compile(
+    r'\s*(?:("[^"\n]*"|`[^`]*`|\'[^\'\n]*\')'
+    r"|(\d[\w.]*|\.\d\w*)"
+    r"|([A-Za-z_]\w*)"
+    r"|(<<|>>|&\^|&&|\|\||==|!=|<=|>=|[-+*/%&|^<>!(),.]))"
+)
+_BINARY = {
+    "<<", ">>", "&^", "&&", "||", "==", "!=", "<=", ">=",
+    "+", "-", "*", "/", "%", "&", "|", "^", "<", ">",
+}
+_UNARY = {"-", "+", "!", "^"}
+_BUILTINS = {
+    "len", "min", "max", "complex", "real", "imag",
+    "string", "bool", "byte", "rune", "int", "int8", "int16", "int32", "int64",
+    "uint", "uint8", "uint16", "uint32", "uint64", "uintptr",
+    "float32", "float64", "complex64", "complex128",
+}
+_PREDECLARED = {"true", "false", "iota"}
+
+_CONST_SINGLE_RE = re.compile(r"\bconst[ \t]+(\w+(?:[ \t]*,[ \t]*\w+)*)")
+_CONST_BLOCK_RE = re.compile(r"\bconst[ \t]*\(")
+_CONST_SPEC_RE = re.compile(r"^[ \t]*(\w+(?:[ \t]*,[ \t]*\w+)*)", re.MULTILINE)
+
+
+def file_constants(file: GoFile) -> frozenset[str]:
+    """Names declared with ``const`` in ``file``, at package level or in a function."""
+    def build() -> frozenset[str]:
+        masked = file.masked
+        names: set[str] = set()
+        for m in _CONST_SINGLE_RE.finditer(masked):
+            names.update(n.strip() for n in m.group(1).split(","))
+        for m in _CONST_BLOCK_RE.finditer(masked):
+            end = masked.find(")", m.end())
+            for spec in _CONST_SPEC_RE.finditer(masked, m.end(), len(masked) if end < 0 else end):
+                names.update(n.strip() for n in spec.group(1).split(","))
+        return frozenset(names - {"_"})
+
+    return file.memo("constexpr:constants", build)
+
+
+class _Parser:
+    """Recursive descent over the tokens of one expression."""
+
+    def __init__(
+        self, tokens: list[str], constants: frozenset[str], packages: frozenset[str], calls: bool
+    ) -> None:
+        self.tokens = tokens
+        self.constants = constants
+        self.packages = packages
+        self.calls = calls
+        self.pos = 0
+
+    def peek(self) -> str | None:
+        return self.tokens[self.pos] if self.pos < len(self.tokens) else None
+
+    def take(self, token: str) -> bool:
+        if self.peek() == token:
+            self.pos += 1
+            return True
+        return False
+
+    def expression(self) -> bool:
+        if not self.unary():
+            return False
+        while self.peek() in _BINARY:
+            self.pos += 1
+            if not self.unary():
+                return False
+        return True
+
+    def unary(self) -> bool:
+        while self.peek() in _UNARY:
+            self.pos += 1
+        return self.primary()
+
+    def arguments(self) -> bool:
+        """``(a, b)`` with every argument constant; the ``(`` is next."""
+        if not self.take("("):
+            return False
+        if self.take(")"):
+            return True
+        while True:
+            if not self.expression():
+                return False
+            if self.take(")"):
+                return True
+            if not self.take(","):
+                return False
+            if self.take(")"):  # trailing comma
+                return True
+
+    def primary(self) -> bool:
+        token = self.peek()
+        if token is None:
+            return False
+        if token == "(":
+            self.pos += 1
+            return self.expression() and self.take(")")
+        if token[0] in "\"`'" or token[0].isdigit() or token[0] == ".":
+            self.pos += 1
+            return token != "."
+        if not (token[0].isalpha() or token[0] == "_"):
+            return False
+        self.pos += 1
+        if self.peek() == "(":
+            return token in _BUILTINS and self.arguments()
+        if self.peek() != ".":
+            return token in _PREDECLARED or token in self.constants
+        # A selector: only a call chain rooted at an imported package qualifies.
+        if not self.calls or token not in self.packages:
+            return False
+        while self.take("."):
+            name = self.peek()
+            if name is None or not (name[0].isalpha() or name[0] == "_"):
+                return False
+            self.pos += 1
+            if not self.arguments():
+                return False
+        return True
+
+
+def _tokens(expr: str) -> list[str] | None:
+    tokens: list[str] = []
+    pos = 0
+    expr = expr.rstrip()
+    while pos < len(expr):
+        m = _TOKEN_RE.match(expr, pos)
+        if m is None or m.end() == pos:
+            return None
+        tokens.append(next(g for g in m.groups() if g is not None))
+        pos = m.end()
+    return tokens
+
+
+def _check(expr: str, constants: frozenset[str], packages: frozenset[str], calls: bool) -> bool:
+    tokens = _tokens(expr)
+    if not tokens:
+        return False
+    parser = _Parser(tokens, constants, packages, calls)
+    return parser.expression() and parser.pos == len(tokens)
+
+
+def is_constant(expr: str, constants: frozenset[str]) -> bool:
+    """Whether masked ``expr`` is a constant expression, given the package's constants."""
+    return _check(expr, constants, frozenset(), calls=False)
+
+
+def deterministic(expr: str, constants: frozenset[str], packages: frozenset[str]) -> bool:
+    """``is_constant``, or a call chain from one of ``packages`` taking only constants."""
+    return _check(expr, constants, packages, calls=True)
+
+
+__all__ = ["deterministic", "file_constants", "is_constant"]
@@ -0,0 +1,381 @@
+"""Shared per-file context and single-pass dispatch for Go detectors.
+
+``GoFile`` masks, splits, and indexes a file once, so detectors stop redoing
+that work for every rule. Every detector takes a ``Pass``, as x/tools
+analyzers take an ``analysis.Pass``: the shared ``GoFile`` (standing in for
+``*ast.File`` and its ``token.FileSet``), the package's ``PackageTypes``
+when the package is analyzed past syntax level, and ``report`` for the
+rule's diagnostics. ``PackageTypes.info`` type-checks the package with
+``go/types`` the first time a rule asks for it, so only scans running a
+type-aware rule pay for it. ``Inspector`` walks the masked source's
+keywords once and hands each occurrence to the visitors that declared its
+kind, in the spirit of x/tools' ``inspector.Preorder``; whole-file detectors
+then run against the same ``Pass``. ``run`` can also time each callback and
+charge it to the rule that registered it, for ``--timings``.
+"""
+
+from __future__ import annotations
+
+import bisect
+import os
+import re
+import time
+from collections.abc import Callable, Collection
+from functools import cached_property
+from pathlib import Path
+from typing import TYPE_CHECKING, Any
+
+from desloppify.languages.go.detectors._source import (
+    mask_go_source,
+    module_go_version,
+    source_line,
+)
+
+if TYPE_CHECKING:
+    from desloppify.languages.go.typeinfo import TypesInfo
+
+NODE_KINDS = (
+    "case",
+    "defer",
+    "else",
+    "for",
+    "func",
+    "go",
+    "if",
+    "return",
+    "select",
+    "switch",
+)
+_NODE_RE = re.compile(rf"(?<![\w.])({'|'.join(NODE_KINDS)})\b")
+_NEWLINE_RE = re.compile("\n")
+# Timing bucket for the keyword walk itself, net of the visitors it calls.
+WALK_RULE = "(walk)"
+
+_TYPE_DECL_RE = re.compile(r"(?<![\w.])type[ \t]+(\w+)")
+_PACKAGE_RE = re.compile(r"^package[ \t]+(\w+)", re.MULTILINE)
+
+SmellCounts = dict[str, list]
+NodeVisitor = Callable[["Pass", str, int], None]
+FileDetector = Callable[["Pass"], None]
+
+
+class GoFile:
+    """One source file plus the derived views detectors share."""
+
+    # Files parsed by this process, for benchmarks.
+    parses = 0
+
+    def __init__(self, path: str, content: str) -> None:
+        GoFile.parses += 1
+        self.path = path
+        self.content = content
+        self.masked = mask_go_source(content)
+        self.lines = content.splitlines()
+        self._line_starts = [0, *(m.end() for m in _NEWLINE_RE.finditer(content))]
+        self._memo: dict[str, Any] = {}
+
+    @cached_property
+    def masked_lines(self) -> list[str]:
+        return self.masked.splitlines()
+
+    @cached_property
+    def go_version(self) -> tuple[int, int] | None:
+        """(major, minor) from the enclosing module's go.mod, if there is one."""
+        return module_go_version(os.path.dirname(self.path))
+
+    @cached_property
+    def _blocks(self) -> tuple[list[int], dict[int, int], dict[int, int | None]]:
+        """(sorted ``{`` offsets, close offset per open, enclosing open per open)."""
+        opens: list[int] = []
+        closes: dict[int, int] = {}
+        parents: dict[int, int | None] = {}
+        stack: list[int] = []
+        for i, ch in enumerate(self.masked):
+            if ch == "{":
+                parents[i] = stack[-1] if stack else None
+                opens.append(i)
+                stack.append(i)
+            elif ch == "}" and stack:
+                closes[stack.pop()] = i
+        return opens, closes, parents
+
+    def enclosing_blocks(self, pos: int) -> list[int]:
+        """Offsets of the ``{`` of every block containing ``pos``, innermost first."""
+        opens, closes, parents = self._blocks
+        index = bisect.bisect_left(opens, pos) - 1
+        brace = opens[index] if index >= 0 else None
+        found: list[int] = []
+        while brace is not None:
+            if closes.get(brace, len(self.masked)) > pos:
+                found.append(brace)
+            brace = parents[brace]
+        return found
+
+    @cached_property
+    def _opens_by_close(self) -> dict[int, int]:
+        return {close: open_ for open_, close in self._blocks[1].items()}
+
+    def block_open(self, close: int) -> int | None:
+        """Offset of the ``{`` matching the ``}`` at ``close``."""
+        return self._opens_by_close.get(close)
+
+    def line_at(self, pos: int) -> int:
+        """1-based line containing offset ``pos``."""
+        return bisect.bisect_right(self._line_starts, pos)
+
+    def line_offset(self, line: int) -> int:
+        """Offset of the first character on 1-based ``line``."""
+        return self._line_starts[line - 1]
+
+    def line_start(self, pos: int) -> int:
+        """Offset of the first character on ``pos``'s line."""
+        return self._line_starts[self.line_at(pos) - 1]
+
+    def match(self, line: int, **extra: Any) -> dict[str, Any]:
+        """A smell match entry for ``line``."""
+        return {
+            "file": self.path,
+            "line": line,
+            "content": source_line(self.lines, line),
+            **extra,
+        }
+
+    def memo(self, key: str, build: Callable[[], Any]) -> Any:
+        """Compute a per-file fact once and share it across detectors."""
+        if key not in self._memo:
+            self._memo[key] = build()
+        return self._memo[key]
+
+
+class PackageTypes:
+    """Type-level facts shared by every file of one package.
+
+    ``files`` are the package's parsed files; ``declaration(name)`` finds
+    where a type is declared in any of them. ``tests`` are its ``_test.go``
+    files of the same package (not ``_test`` packages), parsed on first use;
+    findings are never reported in them. ``info`` is the package's
+    ``go/types`` result, computed on first use; None unless ``type_check``
+    (some enabled rule requires types).
+    """
+
+    def __init__(
+        self,
+        files: tuple[GoFile, ...],
+        *,
+        type_check: bool = False,
+        test_paths: tuple[str, ...] = (),
+    ) -> None:
+        self.files = files
+        self.type_check = type_check
+        self.test_paths = test_paths
+        self._memo: dict[str, Any] = {}
+
+    def memo(self, key: str, build: Callable[[], Any]) -> Any:
+        """Compute a per-package fact once and share it across files and rules."""
+        if key not in self._memo:
+            self._memo[key] = build()
+        return self._memo[key]
+
+    @cached_property
+    def tests(self) -> tuple[GoFile, ...]:
+        package = next(
+            (m.group(1) for f in self.files if (m := _PACKAGE_RE.search(f.masked))), None
+        )
+        found = []
+        for path in self.test_paths:
+            try:
+                test = GoFile(path, Path(path).read_text(errors="replace"))
+            except OSError:
+                continue
+            m = _PACKAGE_RE.search(test.masked)
+            if m is not None and m.group(1) == package:
+                found.append(test)
+        return tuple(found)
+
+    @cached_property
+    def _declarations(self) -> dict[str, tuple[GoFile, int]]:
+        found: dict[str, tuple[GoFile, int]] = {}
+        for file in self.files:
+            for m in _TYPE_DECL_RE.finditer(file.masked):
+                found.setdefault(m.group(1), (file, m.start()))
+        return found
+
+    def declaration(self, name: str) -> tuple[GoFile, int] | None:
+        """(file, offset of ``type``) declaring ``name`` in this package."""
+        return self._declarations.get(name)
+
+    @cached_property
+    def info(self) -> TypesInfo | None:
+        if not self.type_check:
+            return None
+        from desloppify.languages.go import typeinfo
+
+        return typeinfo.check_package({file.path: file.content for file in self.files})
+
+
+class Pass:
+    """What one rule gets for one file: the shared parse, types, and a reporter."""
+
+    __slots__ = ("file", "rule", "types", "options", "matches")
+
+    def __init__(
+        self,
+        file: GoFile,
+        rule: str,
+        matches: list,
+        types: PackageTypes | None = None,
+        options: dict[str, Any] | None = None,
+    ) -> None:
+        self.file = file
+        self.rule = rule
+        self.types = types
+        # The rule's option values, defaults filled in.
+        self.options = options or {}
+        self.matches = matches
+
+    def report(self, line: int, content: str | None = None, **extra: Any) -> dict[str, Any]:
+        """Record a diagnostic on 1-based ``line``; returns the entry."""
+        entry = self.file.match(line, **extra)
+        if content is not None:
+            entry["content"] = content.strip()[:100]
+        self.matches.append(entry)
+        return entry
+
+    @property
+    def types_info(self) -> TypesInfo | None:
+        """The package's ``go/types`` info, type-checked on first use.
+
+        None at syntax level and when no enabled rule requires types.
+        """
+        return self.types.info if self.types is not None else None
+
+    def type_of(self, start: int, end: int | None = None) -> str | None:
+        """Type of the expression at ``start`` (to ``end``) in this file, if known."""
+        info = self.types_info
+        return info.type_of(self.file.path, start, end) if info is not None else None
+
+
+def visits(*kinds: str) -> Callable[[NodeVisitor], NodeVisitor]:
+    """Declare the keyword kinds a node visitor wants to receive."""
+    unknown = set(kinds) - set(NODE_KINDS)
+    if unknown:
+        raise ValueError(f"unknown node kinds: {sorted(unknown)}")
+
+    def mark(visitor: NodeVisitor) -> NodeVisitor:
+        visitor.node_kinds = kinds  # type: ignore[attr-defined]
+        return visitor
+
+    return mark
+
+
+class Inspector:
+    """Runs node visitors in one keyword walk, then whole-file detectors."""
+
+    def __init__(
+        self,
+        options: dict[str, dict[str, Any]] | None = None,
+        enabled: Collection[str] | None = None,
+    ) -> None:
+        # Option values per rule, handed to each rule's ``Pass``.
+        self.options = options or {}
+        # Rules that get registered; None registers every rule added.
+        self.enabled = enabled
+        self._visitors: dict[str, list[tuple[str, NodeVisitor]]] = {}
+        self._file_detectors: list[tuple[str, FileDetector]] = []
+        self._registered: list[tuple[str, str, Any]] = []
+        self.walks = 0
+
+    def add_visitor(self, visitor: NodeVisitor, rule: str | None = None) -> None:
+        """Register a node visitor; ``rule`` names it in timings (default: its name)."""
+        rule = rule or visitor.__name__
+        if self.enabled is not None and rule not in self.enabled:
+            return
+        for kind in visitor.node_kinds:  # type: ignore[attr-defined]
+            self._visitors.setdefault(kind, []).append((rule, visitor))
+        self._registered.append(("visitor", rule, visitor))
+
+    def add_file(self, detector: FileDetector, rule: str | None = None) -> None:
+        rule = rule or detector.__name__
+        if self.enabled is not None and rule not in self.enabled:
+            return
+        self._file_detectors.append((rule, detector))
+        self._registered.append(("file", rule, detector))
+
+    def passes(
+        self, source: GoFile, smell_counts: SmellCounts, types: PackageTypes | None = None
+    ) -> dict[str, Pass]:
+        """One ``Pass`` per registered rule over ``source``, reporting into ``smell_counts``."""
+        return {
+            rule: Pass(
+                source, rule, smell_counts.setdefault(rule, []), types, self.options.get(rule)
+            )
+            for _style, rule, _detector in self._registered
+        }
+
+    def run(
+        self,
+        source: GoFile,
+        smell_counts: SmellCounts,
+        timings: dict[str, float] | None = None,
+        *,
+        types: PackageTypes | None = None,
+    ) -> None:
+        """Dispatch one file; with ``timings``, add each rule's seconds to it."""
+        passes = self.passes(source, smell_counts, types)
+        if timings is not None:
+            self._run_timed(source, passes, timings)
+            return
+        if self._visitors:
+            self.walks += 1
+            for m in _NODE_RE.finditer(source.masked):
+                for rule, visitor in self._visitors.get(m.group(1), ()):
+                    visitor(passes[rule], m.group(1), m.start())
+        for rule, detector in self._file_detectors:
+            detector(passes[rule])
+
+    def _run_timed(
+        self, source: GoFile, passes: dict[str, Pass], timings: dict[str, float]
+    ) -> None:
+        clock = time.perf_counter
+        if self._visitors:
+            self.walks += 1
+            walk_start = clock()
+            in_visitors = 0.0
+            for m in _NODE_RE.finditer(source.masked):
+                for rule, visitor in self._visitors.get(m.group(1), ()):
+                    start = clock()
+                    visitor(passes[rule], m.group(1), m.start())
+                    elapsed = clock() - start
+                    timings[rule] = timings.get(rule, 0.0) + elapsed
+                    in_visitors += elapsed
+            walk = clock() - walk_start - in_visitors
+            timings[WALK_RULE] = timings.get(WALK_RULE, 0.0) + walk
+        for rule, detector in self._file_detectors:
+            start = clock()
+            detector(passes[rule])
+            timings[rule] = timings.get(rule, 0.0) + clock() - start
+
+    @property
+    def rules(self) -> list[str]:
+        """Rule names in registration order."""
+        return [rule for _style, rule, _detector in self._registered]
+
+    def split(self) -> list[Inspector]:
+        """One single-detector inspector per registration (for benchmarking)."""
+        parts: list[Inspector] = []
+        for style, rule, detector in self._registered:
+            part = Inspector(self.options)
+            (part.add_visitor if style == "visitor" else part.add_file)(detector, rule)
+            parts.append(part)
+        return parts
+
+
+__all__ = [
+    "NODE_KINDS",
+    "WALK_RULE",
+    "GoFile",
+    "Inspector",
+    "PackageTypes",
+    "Pass",
+    "visits",
+]
@@ -0,0 +1,145 @@
+"""Shared Go source-text helpers for regex-based detectors.
+
+Detectors match against a *masked* copy of the file where comments and the
+contents of string/rune literals are blanked out (offsets and newlines are
+preserved), so braces or keywords inside literals never confuse matching.
+Literal text can always be recovered from the original content at the same
+offsets.
+"""
+
+from __future__ import annotations
+
+import re
+from pathlib import Path
+
+# `//desloppify:ignore` or `//desloppify:ignore rule_a, rule_b`
+SUPPRESS_DIRECTIVE = "desloppify:ignore"
+_SUPPRESS_RE = re.compile(r"//\s*desloppify:ignore\b([ \t]+[\w, \t-]+)?")
+
+
+_GO_DIRECTIVE_RE = re.compile(r"^go[ \t]+(\d+)\.(\d+)", re.MULTILINE)
+
+
+def module_go_version(directory: str) -> tuple[int, int] | None:
+    """(major, minor) of the ``go`` directive in the nearest go.mod above ``directory``."""
+    current = Path(directory or ".").resolve()
+    for candidate in (current, *current.parents):
+        go_mod = candidate / "go.mod"
+        if go_mod.is_file():
+            try:
+                match = _GO_DIRECTIVE_RE.search(go_mod.read_text(errors="replace"))
+            except OSError:
+                return None
+            return (int(match.group(1)), int(match.group(2))) if match else None
+    return None
+
+
+def _blanked_spans(content: str):
+    """Yield ``(kind, start, end)`` for each comment and literal body.
+
+    ``kind`` is ``"comment"`` (the whole comment) or ``"literal"`` (the text
+    between the quotes of a string, raw string, or rune).
+    """
+    i = 0
+    length = len(content)
+    while i < length:
+        ch = content[i]
+        if ch == "/" and i + 1 < length and content[i + 1] == "/":
+            end = content.find("\n", i)
+            end = length if end == -1 else end
+            yield "comment", i, end
+            i = end
+            continue
+        if ch == "/" and i + 1 < length and content[i + 1] == "*":
+            end = content.find("*/", i + 2)
+            end = length if end == -1 else end + 2
+            yield "comment", i, end
+            i = end
+            continue
+        if ch == "`":
+            j = content.find("`", i + 1)
+            j = length if j == -1 else j
+            yield "literal", i + 1, j
+            i = j + 1
+            continue
+        if ch in {'"', "'"}:
+            j = i + 1
+            while j < length and content[j] != ch and content[j] != "\n":
+                j += 2 if content[j] == "\\" and j + 1 < length else 1
+            yield "literal", i + 1, min(j, length)
+            i = j + 1
+            continue
+        i += 1
+
+
+def mask_go_source(content: str) -> str:
+    """Blank comments and literal contents while preserving offsets/newlines."""
+    out = list(content)
+    for _kind, start, end in _blanked_spans(content):
+        for j in range(start, end):
+            if content[j] != "\n":
+                out[j] = " "
+    return "".join(out)
+
+
+def go_comments(content: str) -> list[str]:
+    """The text of every comment in ``content``, in source order."""
+    return [content[a:b] for kind, a, b in _blanked_spans(content) if kind == "comment"]
+
+
+def line_at(content: str, pos: int) -> int:
+    """Return the 1-based line number containing offset ``pos``."""
+    return content.count("\n", 0, pos) + 1
+
+
+def matching_brace(masked: str, open_pos: int) -> int | None:
+    """Return the offset of the ``}`` closing the ``{`` at ``open_pos``."""
+    depth = 0
+    for i in range(open_pos, len(masked)):
+        ch = masked[i]
+        if ch == "{":
+            depth += 1
+        elif ch == "}":
+            depth -= 1
+            if depth == 0:
+                return i
+    return None
+
+
+def source_line(lines: list[str], line: int) -> str:
+    """Return the stripped, length-capped source line used in match entries."""
+    if 1 <= line <= len(lines):
+        return lines[line - 1].strip()[:100]
+    return ""
+
+
+def is_suppressed(lines: list[str], line: int, rule: str) -> bool:
+    """Whether a ``//desloppify:ignore`` directive covers ``rule`` at ``line``.
+
+    The directive applies to its own line (trailing comment) or the line
+    directly below it; with no rule list it suppresses every rule.
+    """
+    for candidate in (line, line - 1):
+        if not 1 <= candidate <= len(lines):
+            continue
+        m = _SUPPRESS_RE.search(lines[candidate - 1])
+        if m is None:
+            continue
+        if candidate == line - 1 and not lines[candidate - 1].strip().startswith("//"):
+            continue
+        rules = {r for r in re.split(r"[\s,]+", m.group(1) or "") if r}
+        if not rules or rule in rules:
+            return True
+    return False
+
+
+__all__ = [
+    "SUPPRESS_DIRECTIVE",
+    "go_comments",
+    "is_suppressed",
+    "line_at",
+    "mask_go_source",
+    "matching_brace",
+    "module_go_version",
+    "source_line",
+]
@@ -0,0 +1,129 @@
+"""Slice copies and appends that quietly lose data.
+
+``slice_overlap_append``: ``append(s[:i], s[i+1:]...)`` deletes element ``i`` by shifting the tail
+of ``s`` left, in place: the result shares ``s``'s backing array. That is
+fine as ``s = append(s[:i], s[i+1:]...)``. Assigned to another name, or
+passed along, it leaves ``s`` itself with its tail shifted and its last
+element duplicated, and code that reads ``s`` afterwards sees the damage.
+
+Only the provable shape is reported: the destination is ``s[lo:hi]`` with
+an upper bound, so the append writes inside ``s``; the appended slice is
+``s[...]...``, so both sides are the same array; the result is not
+assigned back to ``s`` or returned; and ``s`` is read later in the
+function before it is assigned again.
+
+``copy_length_ignored``: ``copy(dst, src)`` copies only as many elements
+as the shorter of the two holds and returns that count. As a statement,
+with ``dst`` made with a fixed length that is not ``len(src)``, the tail
+of ``src`` can be dropped without anyone noticing.
+"""
+
+from __future__ import annotations
+
+import re
+
+from desloppify.languages.go.detectors._inspector import GoFile, Pass
+from desloppify.languages.go.detectors.concurrency import _enclosing_spans
+from desloppify.languages.go.detectors.error_flow import _closing_paren
+from desloppify.languages.go.detectors.logic import _split_top_level
+
+_NAME = r"\w+(?:\.\w+)*"
+# `t := append(s[lo:hi], s[j:k]...)`; the assignment is optional.
+_OVERLAP_APPEND_RE = re.compile(
+    rf"(?:({_NAME})[ \t]*:?=[ \t]*)?(?<![\w.])append\("
+    rf"[ \t]*({_NAME})\[([^\[\]:\n]*):([^\[\]:\n]+)\][ \t]*,"
+    rf"[ \t]*({_NAME})\[[^\[\]\n]*\][ \t]*\.\.\.[ \t]*\)"
+)
+_RETURN_RE = re.compile(r"[ \t]*return\b")
+
+
+def detect_slice_overlap_append(pass_: Pass) -> None:
+    """Detect ``append(s[:i], s[j:]...)`` whose result is not ``s`` while ``s`` is still read."""
+    source = pass_.file
+    masked = source.masked
+    for m in _OVERLAP_APPEND_RE.finditer(masked):
+        target, base, source_base = m.group(1), m.group(2), m.group(5)
+        if base != source_base or target == base:
+            continue
+        if target is None and _RETURN_RE.match(masked, source.line_start(m.start())):
+            continue  # the caller gets the result, and decides what to do with its slice
+        spans = _enclosing_spans(source, m.start())
+        if not spans:
+            continue
+        name = re.escape(base)
+        later = re.compile(rf"(?<![\w.]){name}\b(?:[ \t]*(:?=)(?!=))?").search(
+            masked, m.end(), spans[0][2]
+        )
+        if later is None or later.group(1):
+            continue
+        pass_.report(
+            source.line_at(m.start()),
+            slice=base,
+            assigned_to=target,
+            read_at=source.line_at(later.start()),
+            hint=f"assign the result back to {base}, or build a new slice "
+            f"with slices.Concat({base}[:{m.group(4).strip()}], ...)",
+        )
+
+
+_COPY_STATEMENT_RE = re.compile(r"^[ \t]*copy\(", re.MULTILINE)
+_MAKE_RE = re.compile(r"make\([ \t]*\[\][^,]+,[ \t]*([^,)]+?)[ \t]*(?:,[^)]*)?\)$")
+
+
+def _fixed_length(file: GoFile, offset: int, dst: str) -> str | None:
+    """The length expression ``dst`` was made with, if it is ``make([]T, n)``."""
+    made = _MAKE_RE.fullmatch(dst)
+    if made:
+        return made.group(1)
+    if not re.fullmatch(r"\w+", dst):
+        return None
+    spans = _enclosing_spans(file, offset)
+    if not spans:
+        return None
+    masked = file.masked
+    declared = None
+    pattern = re.compile(rf"(?<![\w.]){re.escape(dst)}[ \t]*:?=(?!=)[ \t]*([^\n;]+)")
+    for m in pattern.finditer(masked, spans[0][1], offset):
+        declared = _MAKE_RE.fullmatch(m.group(1).strip())
+    return declared.group(1) if declared else None
+
+
+def detect_copy_length_ignored(pass_: Pass) -> None:
+    """Detect ``copy`` statements into a slice made with a fixed length other than ``len(src)``.
+
+    The destination is ``make([]T, n)`` itself or a name last assigned one
+    in the function. Not reported when ``n`` is ``len(src)`` or a
+    ``max`` including it, or when ``src`` is a reslice ``x[:m]`` with the
+    same bound, since then the lengths match.
+    """
+    source = pass_.file
+    masked = source.masked
+    for m in _COPY_STATEMENT_RE.finditer(masked):
+        open_paren = m.end() - 1
+        close = _closing_paren(masked, open_paren)
+        if close is None or masked[close + 1 : masked.find("\n", close)].strip():
+            continue
+        spans = _split_top_level(masked, open_paren + 1, close, ",")
+        args = [masked[a:b].strip() for a, b in spans]
+        if len(args) != 2:
+            continue
+        dst, src = args
+        length = _fixed_length(source, m.start(), dst)
+        if length is None:
+            continue
+        if re.search(rf"\blen\([ \t]*{re.escape(src)}[ \t]*\)", length):
+            continue
+        bound = re.fullmatch(r"[\w.]+\[[^\[\]:]*:([^\[\]:]+)\]", src)
+        if bound and bound.group(1).strip() == length.strip():
+            continue
+        pass_.report(
+            source.line_at(m.start()),
+            dst=dst,
+            src=src,
+            length=length,
+            hint=f"check the count copy returns against len({src}), or make the "
+            f"destination len({src}) long",
+        )
+
+
+__all__ = ["detect_copy_length_ignored", "detect_slice_overlap_append"]
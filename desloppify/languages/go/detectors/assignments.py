@@ -0,0 +1,446 @@
+"""Assignments whose value is never read.
+
+``x := compute(); x = computeAgain()`` with nothing reading ``x`` in
+between throws the first result away; so does an assignment that nothing
+reads before the variable goes out of scope. Each is its own rule:
+
+- ``ineffective_assignment``: a value assigned to a local variable and
+  overwritten, or left unread, before anything reads it.
+- ``error_overwritten``: the same for an error (``err``, ``parseErr``)
+  assigned from a call, overwritten before it was checked. The first
+  failure is silently lost, which is a bug rather than waste.
+- ``pure_result_discarded``: a call to a function that only computes its
+  result (``strings.TrimSpace(s)``) used as a statement.
+
+Definitions are followed per variable, with Go's scopes: a ``:=`` in an
+inner block or an ``if``/``for``/``switch`` header declares a new
+variable, and one in the same block as an earlier declaration assigns
+the old one. ``x += n`` reads ``x`` and then assigns it, and statements
+after a ``;`` on the same line count like those on a line of their own.
+An assignment is overwritten only by a later one in the same block with
+no ``return``, ``break``, ``continue``, ``case`` or ``panic`` in
+between, so both run one after the other on every path. A value is
+left unread when no read follows it in the variable's scope and, inside
+a loop, no read anywhere in the loop, since the next iteration may read
+it. Variables captured by a function literal, whose address is taken, or
+that are named results are not followed, nor are functions with
+``goto``. Zero values (``x := 0``, ``err = nil``) are how Go declares a
+variable to be set later and are never reported.
+"""
+
+from __future__ import annotations
+
+import re
+from dataclasses import dataclass, field
+
+from desloppify.languages.go.detectors._inspector import GoFile, Pass
+from desloppify.languages.go.detectors._source import matching_brace
+from desloppify.languages.go.detectors.concurrency import _function_spans, _header
+from desloppify.languages.go.detectors.concurrency import _statement_end as _chain_end
+from desloppify.languages.go.detectors.custom_rules import _imports
+from desloppify.languages.go.detectors.error_flow import _body_brace, _closing_paren
+from desloppify.languages.go.detectors.logic import _statement_end
+from desloppify.languages.go.detectors.panics import _functions
+from desloppify.languages.go.detectors.signatures import _params
+
+PURE_FUNCTIONS = (
+    "strings.*",
+    "bytes.*",
+    "strconv.*",
+    "path.*",
+    "unicode.*",
+    "math.*",
+    "filepath.Base",
+    "filepath.Clean",
+    "filepath.Dir",
+    "filepath.Ext",
+    "filepath.Join",
+    "filepath.Rel",
+    "filepath.ToSlash",
+    "filepath.FromSlash",
+    "fmt.Sprint",
+    "fmt.Sprintf",
+    "fmt.Sprintln",
+    "fmt.Errorf",
+    "errors.New",
+    "slices.Clip",
+    "slices.Clone",
+    "slices.Compact",
+    "slices.Delete",
+    "slices.Grow",
+    "slices.Insert",
+    "slices.Replace",
+)
+
+_NAMES = r"((?:\w+[ \t]*,[ \t]*)*\w+)"
+# `x = f()`, `x, err := f()`, `x += n` at the start of a line or after `;`.
+_ASSIGN_RE = re.compile(
+    rf"(?:^|;)[ \t]*{_NAMES}[ \t]*(:?=|(?:[-+*/%&|^]|<<|>>|&\^)=)(?!=)", re.MULTILINE
+)
+_HEADER_LINE_RE = re.compile(r"[ \t]*(?:\}[ \t]*else[ \t]+)?(?:if|for|switch|select|case)\b")
+_VAR_RE = re.compile(rf"^[ \t]*var[ \t]+{_NAMES}\b[^=\n]*(=)?", re.MULTILINE)
+# `if v, ok := m[k]; ok {`, `for i := 0; ...`, `switch x := v.(type) {`
+_HEADER_DECL_RE = re.compile(rf"\b(if|for|switch)[ \t]+{_NAMES}[ \t]*:=")
+_CASE_DECL_RE = re.compile(rf"^[ \t]*case[ \t]+{_NAMES}[ \t]*:=", re.MULTILINE)
+_JUMP_RE = re.compile(
+    r"(?<![\w.])(?:return|break|continue|goto|fallthrough|case|default[ \t]*:|panic[ \t]*\()"
+)
+_ERROR_NAME_RE = re.compile(r"err|\w*Err")
+_ZERO_RE = re.compile(r"0|0\.0|\"\"|``|nil|false|(?:\[\]|map\[[^\]]*\])?[\w.]*\{\}")
+_CALL_STATEMENT_RE = re.compile(r"^[ \t]*(\w+)\.(\w+)\(", re.MULTILINE)
+
+
+@dataclass
+class _Def:
+    start: int
+    end: int
+    rhs: str
+    # Whether it is a statement that can be reported, not a header or a parameter.
+    reportable: bool
+
+
+@dataclass
+class _Var:
+    name: str
+    # Where the variable can be referred to, and the block that declares it.
+    scope: tuple[int, int]
+    block: int
+    defs: list[_Def] = field(default_factory=list)
+    reads: list[int] = field(default_factory=list)
+    followed: bool = True
+
+
+@dataclass(frozen=True)
+class DeadStore:
+    """An assignment nobody reads: ``overwritten`` at a later one, or ``never_read``."""
+
+    name: str
+    start: int
+    reason: str
+    overwritten_at: int | None
+    from_call: bool
+
+
+def _signature_groups(
+    masked: str, start: int, body_open: int
+) -> list[tuple[tuple[str, str], ...]]:
+    """(name, type) of each parenthesized group in the signature in ``start:body_open``.
+
+    The receiver of a method comes first, then the parameters, then named results.
+    """
+    groups = []
+    i = start
+    while i < body_open:
+        if masked[i] == "(":
+            close = _closing_paren(masked, i)
+            if close is None or close > body_open:
+                break
+            groups.append(_params(masked, i) or ())
+            i = close
+        i += 1
+    return groups
+
+
+def _signature_names(
+    masked: str, start: int, body_open: int, *, method: bool
+) -> tuple[list[str], list[str]]:
+    """(receiver and parameter names, named results) of the signature in ``start:body_open``."""
+    count = 2 if method else 1
+    names = [[name for name, _ in group] for group in _signature_groups(masked, start, body_open)]
+    return sum(names[:count], []), sum(names[count:], [])
+
+
+def _clause_end(masked: str, start: int) -> int:
+    """End of the simple statement in an ``if``/``for``/``switch`` header: ``;`` or ``{``."""
+    depth = 0
+    for i in range(start, len(masked)):
+        ch = masked[i]
+        if ch in "([":
+            depth += 1
+        elif ch in ")]":
+            depth -= 1
+        elif depth == 0 and (ch in ";\n" or (ch == "{" and masked[i - 1] in " \t")):
+            return i
+    return len(masked)
+
+
+def _block_close(masked: str, open_: int) -> int:
+    close = matching_brace(masked, open_)
+    return close if close is not None else len(masked)
+
+
+def _variables(
+    source: GoFile, start: int, body_open: int, body_close: int, *, method: bool
+) -> list[_Var]:
+    """The variables one function declares, with their definitions and reads."""
+    masked = source.masked
+    nested = [
+        (masked.rfind("func", body_open, bo), bc)
+        for _, bo, bc in _function_spans(source)
+        if body_open < bo < body_close
+    ]
+
+    def own(pos: int) -> bool:
+        return not any(s <= pos <= e for s, e in nested)
+
+    def innermost(pos: int) -> int:
+        blocks = [b for b in source.enclosing_blocks(pos) if body_open <= b]
+        return blocks[0] if blocks else body_open
+
+    params, results = _signature_names(masked, start, body_open, method=method)
+    variables: list[_Var] = []
+    decl_sites: set[int] = set()
+
+    def declare(name: str, scope: tuple[int, int], block: int, definition: _Def) -> None:
+        for var in reversed(variables):
+            if var.name == name and var.block == block and var.scope[0] <= scope[0] <= var.scope[1]:
+                var.defs.append(definition)
+                return
+        variables.append(_Var(name, scope, block, [definition]))
+
+    for name in params:
+        declare(name, (body_open, body_close), body_open, _Def(body_open, body_open, "", False))
+    found: list[tuple[int, str, str, bool, int, int]] = []
+    for m in _ASSIGN_RE.finditer(masked, body_open, body_close):
+        if m.group().startswith(";") and _HEADER_LINE_RE.match(
+            masked, source.line_start(m.start())
+        ):
+            continue  # `for i := 0; i < n; i = i + 2 {` is a header, not two statements
+        if own(m.start()):
+            end = _statement_end(masked, m.end())
+            found.append((m.start(1), m.group(1), m.group(2), True, m.end(), end))
+    for m in _VAR_RE.finditer(masked, body_open, body_close):
+        if own(m.start()):
+            end = _statement_end(masked, m.end())
+            kind = "var=" if m.group(2) else "var"
+            found.append((m.start(1), m.group(1), kind, True, m.end(), end))
+    for m in _HEADER_DECL_RE.finditer(masked, body_open, body_close):
+        if own(m.start()):
+            end = _clause_end(masked, m.end())
+            found.append((m.start(2), m.group(2), m.group(1), False, m.end(), end))
+    for m in _CASE_DECL_RE.finditer(masked, body_open, body_close):
+        if own(m.start()):
+            found.append((m.start(1), m.group(1), "case", False, m.end(), m.end()))
+    for pos, names, kind, reportable, rhs_start, end in sorted(found):
+        if not kind.endswith("=") or kind in ("=", ":=", "var="):
+            decl_sites.update(range(pos, rhs_start))  # `x += n` also reads x
+        rhs = masked[rhs_start:end].strip()
+        for name in re.findall(r"\w+", names):
+            if name == "_":
+                continue
+            definition = _Def(pos, end, rhs if kind != "var" else "", reportable and kind != "var")
+            block = innermost(pos)
+            if kind in ("if", "for", "switch"):
+                brace = _body_brace(masked, end)
+                close = _block_close(masked, brace) if brace is not None else body_close
+                stop = _chain_end(masked, close) if kind == "if" else close
+                variables.append(_Var(name, (end, stop), -pos, [definition]))
+            elif kind in (":=", "var", "var=", "case"):
+                declare(name, (end, _block_close(masked, block)), block, definition)
+            else:
+                var = _binding(variables, name, pos)
+                if var is not None:
+                    var.defs.append(definition)
+    for var in variables:
+        if var.name in results:
+            var.followed = False
+    for name in {var.name for var in variables}:
+        for m in re.compile(rf"(?<![\w.]){re.escape(name)}\b").finditer(
+            masked, body_open, body_close
+        ):
+            if m.start() in decl_sites:
+                continue
+            var = _binding(variables, name, m.start())
+            if var is None:
+                continue
+            if not own(m.start()) or masked[m.start() - 1] == "&":
+                var.followed = False
+            var.reads.append(m.start())
+    return variables
+
+
+def _binding(variables: list[_Var], name: str, pos: int) -> _Var | None:
+    """The variable ``name`` refers to at ``pos``: the innermost declaration in scope."""
+    best = None
+    for var in variables:
+        if var.name == name and var.scope[0] <= pos <= var.scope[1]:
+            if best is None or var.scope[0] >= best.scope[0]:
+                best = var
+    return best
+
+
+def _loop_reads(source: GoFile, var: _Var, definition: _Def) -> bool:
+    """Whether a loop around ``definition`` that keeps the variable reads it.
+
+    A loop that started before the variable was declared makes a new one
+    each iteration, so only loops inside its scope carry the value over.
+    """
+    masked = source.masked
+    for block in source.enclosing_blocks(definition.start):
+        if block < var.scope[0]:
+            break
+        if _header(masked, block) != "for":
+            continue
+        loop_start = masked.rfind("for", 0, block)
+        loop_end = _block_close(masked, block)
+        if any(loop_start <= r <= loop_end for r in var.reads):
+            return True
+    return False
+
+
+@dataclass(frozen=True)
+class _FunctionVars:
+    start: int
+    body_open: int
+    body_close: int
+    method: bool
+    variables: list[_Var]
+
+
+def function_variables(source: GoFile) -> list[_FunctionVars]:
+    """The variables of every function and function literal in ``source``."""
+    def build() -> list[_FunctionVars]:
+        masked = source.masked
+        starts = {f.body_open: f.start for f in _functions(source)}
+        found = []
+        for _, body_open, body_close in _function_spans(source):
+            method = body_open in starts and bool(
+                re.match(r"func[ \t]*\(", masked[starts[body_open] : body_open])
+            )
+            start = starts.get(body_open, masked.rfind("func", 0, body_open))
+            variables = _variables(source, start, body_open, body_close, method=method)
+            found.append(_FunctionVars(start, body_open, body_close, method, variables))
+        return found
+
+    return source.memo("assignments:variables", build)
+
+
+def dead_stores(source: GoFile) -> list[DeadStore]:
+    """Every assignment in ``source`` whose value is never read."""
+    def build() -> list[DeadStore]:
+        masked = source.masked
+        found = []
+        for func in function_variables(source):
+            if re.search(r"(?<![\w.])goto\b", masked[func.body_open : func.body_close]):
+                continue
+            for var in func.variables:
+                if var.followed:
+                    found.extend(_var_dead_stores(source, var))
+        return sorted(set(found), key=lambda d: d.start)
+
+    return source.memo("assignments:dead", build)
+
+
+def _var_dead_stores(source: GoFile, var: _Var) -> list[DeadStore]:
+    masked = source.masked
+    dead = []
+    defs = sorted(var.defs, key=lambda d: d.start)
+    for index, definition in enumerate(defs):
+        if not definition.reportable or _ZERO_RE.fullmatch(definition.rhs):
+            continue
+        later = next((d for d in defs[index + 1 :] if d.start >= definition.end), None)
+        reads = [r for r in var.reads if r > definition.end]
+        if later is not None and not [r for r in reads if r < later.end]:
+            blocks = source.enclosing_blocks(definition.start)[:1]
+            if (
+                not later.reportable
+                or source.enclosing_blocks(later.start)[:1] != blocks
+                or _JUMP_RE.search(masked, definition.end, later.start)
+            ):
+                if reads:
+                    continue
+            else:
+                from_call = bool(re.search(r"\w\(", definition.rhs))
+                dead.append(
+                    DeadStore(var.name, definition.start, "overwritten", later.start, from_call)
+                )
+                continue
+        if reads or _loop_reads(source, var, definition):
+            continue
+        from_call = bool(re.search(r"\w\(", definition.rhs))
+        dead.append(DeadStore(var.name, definition.start, "never_read", None, from_call))
+    return dead
+
+
+def _is_error_store(store: DeadStore) -> bool:
+    return (
+        store.reason == "overwritten"
+        and store.from_call
+        and _ERROR_NAME_RE.fullmatch(store.name) is not None
+    )
+
+
+def detect_ineffective_assignment(pass_: Pass) -> None:
+    """Detect values assigned to a local variable that nothing reads.
+
+    Reported at the assignment with the ``variable``, and the ``reason``:
+    ``overwritten`` (with the line, ``overwritten_at``) or ``never_read``.
+    Errors from calls that are overwritten are left to ``error_overwritten``.
+    """
+    source = pass_.file
+    for store in dead_stores(source):
+        if _is_error_store(store):
+            continue
+        extra = {}
+        if store.overwritten_at:
+            extra["overwritten_at"] = source.line_at(store.overwritten_at)
+        pass_.report(source.line_at(store.start), variable=store.name, reason=store.reason, **extra)
+
+
+def detect_error_overwritten(pass_: Pass) -> None:
+    """Detect an error from a call overwritten before anything checked it."""
+    source = pass_.file
+    for store in dead_stores(source):
+        if _is_error_store(store):
+            at = source.line_at(store.overwritten_at)
+            pass_.report(
+                source.line_at(store.start),
+                variable=store.name,
+                overwritten_at=at,
+                hint=f"check {store.name} before line {at} assigns it again",
+            )
+
+
+def _pure(function: str, patterns: list[str]) -> bool:
+    package = function.split(".", 1)[0]
+    return function in patterns or f"{package}.*" in patterns
+
+
+def detect_pure_result_discarded(pass_: Pass) -> None:
+    """Detect calls to result-only functions whose result is thrown away.
+
+    A call statement to one of ``PURE_FUNCTIONS``, plus the ``functions``
+    option (``pkg.Name``, or ``pkg.*`` for a whole package), does nothing:
+    ``strings.TrimSpace(s)`` leaves ``s`` as it was. The package must be
+    imported by the file, so a local variable named ``path`` is not it.
+    """
+    source = pass_.file
+    masked = source.masked
+    patterns = list(PURE_FUNCTIONS) + list(pass_.options["functions"])
+    imported = {name for name, _path, _offset in _imports(source)}
+    for m in _CALL_STATEMENT_RE.finditer(masked):
+        function = f"{m.group(1)}.{m.group(2)}"
+        if m.group(1) not in imported or not _pure(function, patterns):
+            continue
+        before = masked[: source.line_start(m.start())].rstrip()
+        if before and before[-1] in "([,=+-*/%&|^<>!.":
+            continue  # an argument or operand continued from the line above
+        close = _closing_paren(masked, m.end() - 1)
+        if close is None or masked[close + 1 : _statement_end(masked, m.start())].strip():
+            continue
+        pass_.report(
+            source.line_at(m.start()),
+            function=function,
+            hint=f"assign the result of {function}, or drop the call",
+        )
+
+
+__all__ = [
+    "PURE_FUNCTIONS",
+    "DeadStore",
+    "dead_stores",
+    "function_variables",
+    "detect_error_overwritten",
+    "detect_ineffective_assignment",
+    "detect_pure_result_discarded",
+]
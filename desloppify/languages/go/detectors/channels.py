@@ -0,0 +1,373 @@
+"""Channel anti-patterns, one rule each so they can be suppressed apart.
+
+- ``channel_as_mutex``: a ``make(chan T, 1)`` only ever sent to and then
+  received from by the same function around a critical section. That is a
+  mutex spelled with a channel; ``sync.Mutex`` says so and is cheaper.
+- ``magic_channel_buffer``: ``make(chan T, 16)`` with a literal size above
+  one and no comment on the line or above it. Buffer sizes change how a
+  program behaves under load; an unexplained one is usually a guess.
+- ``goroutine_send_leak``: a goroutine sending on an unbuffered channel its
+  parent may stop reading. When the parent returns early (``case
+  <-ctx.Done(): return``, an error check) or starts senders in a loop but
+  receives once, the send blocks forever and the goroutine leaks.
+"""
+
+from __future__ import annotations
+
+import re
+
+from desloppify.languages.go.detectors._inspector import GoFile, Pass
+from desloppify.languages.go.detectors._source import matching_brace
+from desloppify.languages.go.detectors.concurrency import (
+    _GO_FUNC_RE,
+    _blocks,
+    _clauses,
+    _enclosing_spans,
+    _function_spans,
+    _header,
+    _package_vars,
+    _statements,
+)
+from desloppify.languages.go.detectors.error_flow import _closing_paren
+from desloppify.languages.go.detectors.logic import _split_top_level
+
+# `ch := make(chan`, `s.ch = make(chan`, and `sem: make(chan` in a composite literal.
+_MAKE_CHAN_RE = re.compile(
+    r"(?<![\w.])(?:((?:\w+\.)?\w+)[ \t]*(:?=)|(\w+)[ \t]*:)[ \t]*make\([ \t]*chan\b"
+)
+_BARE_MAKE_RE = re.compile(r"(?<![\w.])make\([ \t]*chan\b")
+_DEFER_FUNC = r"defer[ \t]+func[ \t]*\(\)[ \t]*\{[ \t]*"
+_DIRECTIVE_RE = re.compile(r"//(?:nolint\b|go:|desloppify:|[ \t]*want\b)")
+
+
+def _make_args(file: GoFile, make: int) -> list[str] | None:
+    """The arguments of the ``make(`` call at ``make``."""
+    masked = file.masked
+    open_paren = masked.index("(", make)
+    close = _closing_paren(masked, open_paren)
+    if close is None:
+        return None
+    return [masked[a:b].strip() for a, b in _split_top_level(masked, open_paren + 1, close, ",")]
+
+
+def _has_comment(file: GoFile, line: int) -> bool:
+    """Whether ``line`` (1-based) or the line above carries a comment that explains.
+
+    Tool directives (``//nolint``, ``//go:``, ``//desloppify:ignore``, an
+    analyzer test's ``// want``) explain nothing.
+    """
+    comments = []
+    if line > 1 and file.lines[line - 2].strip().startswith("//"):
+        comments.append(file.lines[line - 2].strip())
+    text, masked = file.lines[line - 1], file.masked_lines[line - 1]
+    for m in re.finditer(r"//", text):
+        before = masked[: m.start()]
+        if before.count('"') % 2 == 0 and before.count("`") % 2 == 0:
+            comments.append(text[m.start() :])
+            break
+    return any(not _DIRECTIVE_RE.match(comment) for comment in comments)
+
+
+def detect_magic_channel_buffer(pass_: Pass) -> None:
+    """Detect ``make(chan T, N)`` with a literal N above one and no comment saying why."""
+    source = pass_.file
+    for m in _BARE_MAKE_RE.finditer(source.masked):
+        args = _make_args(source, m.start())
+        if not args or len(args) < 2 or not re.fullmatch(r"\d+", args[1]):
+            continue
+        size = int(args[1])
+        line = source.line_at(m.start())
+        if size <= 1 or _has_comment(source, line):
+            continue
+        pass_.report(
+            line,
+            size=size,
+            hint="name the size with a constant, or comment on why it is this big",
+        )
+
+
+def _innermost(file: GoFile, offset: int) -> tuple[str, int, int] | None:
+    spans = _enclosing_spans(file, offset)
+    return spans[0] if spans else None
+
+
+def _uses(file: GoFile, start: int, end: int, name: str) -> list[tuple[int, str]]:
+    """(offset, kind) of each use of channel ``name`` in ``start``..``end``.
+
+    Kinds are ``send`` and ``receive`` for statements of their own, a
+    ``drain`` for ``defer func() { <-ch }()``, ``make`` for the assignment
+    of a new channel, and ``other`` for the rest.
+    """
+    masked = file.masked
+    field = "." in name
+    pattern = (
+        re.compile(rf"(?<![\w.])\w+\.{re.escape(name.split('.', 1)[1])}\b")
+        if field
+        else re.compile(rf"(?<![\w.]){re.escape(name)}\b")
+    )
+    uses = []
+    for m in pattern.finditer(masked, start, end):
+        line = masked[file.line_start(m.start()) : masked.find("\n", m.end())].strip()
+        expr = re.escape(m.group())
+        if re.fullmatch(rf"{expr}[ \t]*<-.*", line):
+            kind = "send"
+        elif re.fullmatch(rf"<-[ \t]*{expr}", line):
+            kind = "receive"
+        elif re.fullmatch(rf"{_DEFER_FUNC}<-[ \t]*{expr}[ \t]*\}}\(\)", line):
+            kind = "drain"
+        elif re.match(rf"{expr}[ \t]*(?::?=|:)[ \t]*make\([ \t]*chan\b", masked[m.start() :]):
+            kind = "make"
+        else:
+            kind = "other"
+        uses.append((m.start(), kind))
+    return uses
+
+
+def detect_channel_as_mutex(pass_: Pass) -> None:
+    """Detect a channel of capacity one used only to guard a critical section.
+
+    Every use must be a send statement or a receive statement, and each
+    function that sends must receive again afterwards (directly or in a
+    ``defer func() { <-ch }()``) while each receive follows a send in its
+    function. A channel sent to in one goroutine and received in another is
+    a signal, not a lock, and is left alone, as is any channel passed,
+    returned, closed or selected on. Package variables and fields are
+    followed through the package, locals through their function.
+    """
+    source = pass_.file
+    masked = source.masked
+    files = pass_.types.files if pass_.types is not None else (source,)
+    package_vars = (
+        pass_.types.memo("channels:package_vars", lambda: set().union(*map(_package_vars, files)))
+        if pass_.types is not None
+        else _package_vars(source)
+    )
+    for m in _MAKE_CHAN_RE.finditer(masked):
+        args = _make_args(source, masked.rindex("make", 0, m.end()))
+        if not args or len(args) != 2 or args[1] != "1":
+            continue
+        name = m.group(1) or "." + m.group(3)
+        span = _innermost(source, m.start())
+        if m.group(3) is None and "." not in name and name not in package_vars:
+            if span is None or m.group(2) == "=":
+                continue
+            scopes = [(source, span[1], span[2])]
+        else:
+            if "." in name:
+                name = "x." + name.rsplit(".", 1)[1]
+            scopes = [(f, 0, len(f.masked)) for f in files]
+        uses = [
+            (file, offset, kind)
+            for file, start, end in scopes
+            for offset, kind in _uses(file, start, end, name)
+            if _innermost(file, offset) is not None
+        ]
+        kinds = [kind for _, _, kind in uses]
+        if "other" in kinds or "send" not in kinds or not {"receive", "drain"} & set(kinds):
+            continue
+        by_function: dict[tuple[str, int], list[tuple[int, str]]] = {}
+        for file, offset, kind in uses:
+            if kind == "make":
+                continue
+            spans = _enclosing_spans(file, offset)
+            function = spans[1] if kind == "drain" and len(spans) > 1 else spans[0]
+            by_function.setdefault((file.path, function[1]), []).append((offset, kind))
+        if all(_brackets(sorted(found)) for found in by_function.values()):
+            pass_.report(
+                source.line_at(m.start()),
+                channel=m.group(1) or m.group(3),
+                hint="use a sync.Mutex",
+            )
+
+
+def _brackets(uses: list[tuple[int, str]]) -> bool:
+    """Whether a function's sends and receives pair up as lock and unlock, in order."""
+    held = False
+    drained = False
+    for _, kind in uses:
+        if kind == "send":
+            if held and not drained:
+                return False
+            held = True
+        elif kind == "drain":
+            if not held:
+                return False
+            drained = True
+        elif kind == "receive":
+            if not held:
+                return False
+            held = False
+    return not held or drained
+
+
+def _receives(masked: str, start: int, end: int, name: str) -> bool:
+    """Whether ``masked[start:end]``, outside its ``{ }`` blocks, receives from ``name``."""
+    text = masked[start:end]
+    for a, b in reversed(_blocks(masked, start, end)):
+        text = text[: a - start] + " " * (b - a + 1) + text[b + 1 - start :]
+    return bool(re.search(rf"<-[ \t]*{re.escape(name)}\b|\brange[ \t]+{re.escape(name)}\b", text))
+
+
+def _unsafe_exit(
+    file: GoFile, span: tuple[str, int, int], go_start: int, name: str
+) -> int | None:
+    """Offset of the first ``return`` after ``go_start`` reached without receiving from ``name``."""
+    masked = file.masked
+    _, body_open, body_close = span
+    for m in re.finditer(r"(?<![\w.])return\b", masked[go_start:body_close]):
+        offset = go_start + m.start()
+        if _innermost(file, offset) != span:
+            continue
+        end = masked.find("\n", offset)
+        if _receives(masked, offset, end if end != -1 else body_close, name):
+            continue
+        if not _received_before(file, body_open, go_start, offset, name):
+            return offset
+    return None
+
+
+def _received_before(file: GoFile, body_open: int, go_start: int, offset: int, name: str) -> bool:
+    """Whether every path from ``go_start`` to ``offset`` received from ``name`` first."""
+    masked = file.masked
+    for brace in file.enclosing_blocks(offset):
+        if brace < body_open:
+            break
+        close = matching_brace(masked, brace) or offset
+        start = brace + 1
+        if brace > go_start and _receives(masked, file.line_start(brace), brace, name):
+            return True  # `for v := range ch {`, `if v := <-ch; ... {`
+        if _header(masked, brace) in ("select", "switch"):
+            clause = [c for c in _clauses(masked, brace, close) if c[0] <= offset < c[1]]
+            if not clause:
+                continue
+            start = clause[0][0]
+            head = masked.rfind("case", brace, start)
+            if head != -1 and _receives(masked, head, start, name):
+                return True  # `case v := <-ch:`
+        for s, e in _statements(masked, start, close):
+            if e > offset or s >= offset:
+                break
+            if s >= file.line_start(go_start) and _statement_receives(masked, s, e, name):
+                return True
+    return False
+
+
+def _statement_receives(masked: str, start: int, end: int, name: str) -> bool:
+    """Whether a statement receives from ``name``, counting a ``for`` loop's body."""
+    if _receives(masked, start, end, name):
+        return True
+    if re.match(r"\s*for\b", masked[start:end]):
+        return any(
+            _receives(masked, s, e, name)
+            for a, b in _blocks(masked, start, end)[:1]
+            for s, e in _statements(masked, a + 1, b)
+        )
+    return False
+
+
+def _escapes(uses: list[tuple[int, str]], masked: str, name: str) -> bool:
+    """Whether the channel is handed anywhere the function cannot see."""
+    for offset, kind in uses:
+        if kind != "other":
+            continue
+        before = masked[:offset].rstrip()
+        after = masked[offset + len(name) :].lstrip()
+        if before.endswith("<-") or before.endswith("range") or before.endswith("close("):
+            continue
+        if after.startswith("<-"):  # a send in a select case or an expression
+            continue
+        return True
+    return False
+
+
+def detect_goroutine_send_leak(pass_: Pass) -> None:
+    """Detect goroutines that can block forever sending to a channel nobody reads.
+
+    The channel is a local ``make(chan T)`` without a buffer; a ``go func``
+    in the same function sends to it with a plain send statement (a send in
+    a ``select`` can give up). Reported at the send when, after the ``go``
+    statement, the function can return before receiving (an early
+    ``return``, or a ``select`` case other than the receive), never receives
+    at all, or starts the goroutine in a loop and receives only once
+    outside any loop. A channel that escapes the function (passed, returned
+    or stored) is left alone, since someone else may read it.
+    """
+    source = pass_.file
+    masked = source.masked
+    for span in _function_spans(source):
+        _, body_open, body_close = span
+        for make in _MAKE_CHAN_RE.finditer(masked, body_open, body_close):
+            name = make.group(1)
+            if make.group(2) != ":=" or "." in (name or ""):
+                continue
+            if _innermost(source, make.start()) != span:
+                continue
+            args = _make_args(source, masked.rindex("make", 0, make.end()))
+            if not args or (len(args) > 1 and args[1] != "0"):
+                continue
+            uses = _uses(source, make.end(), body_close, name)
+            if _escapes(uses, masked, name):
+                continue
+            for go in _GO_FUNC_RE.finditer(masked, make.end(), body_close):
+                if _innermost(source, go.start()) != span:
+                    continue
+                literal_close = matching_brace(masked, go.end() - 1)
+                if literal_close is None:
+                    continue
+                sends = [
+                    offset
+                    for offset, kind in uses
+                    if kind == "send" and go.end() <= offset < literal_close
+                    and "select" not in (
+                        _header(masked, b)
+                        for b in source.enclosing_blocks(offset)
+                        if b >= go.end() - 1
+                    )
+                ]
+                if not sends:
+                    continue
+                received = [
+                    offset
+                    for offset, kind in uses
+                    if _innermost(source, offset) == span
+                    and kind in ("receive", "other")
+                    and (kind == "receive" or masked[:offset].rstrip().endswith(("<-", "range")))
+                ]
+                in_loop = any(
+                    _header(masked, b) == "for"
+                    for b in source.enclosing_blocks(go.start())
+                    if b > body_open
+                )
+                loop_receive = any(
+                    masked[:r].rstrip().endswith("range")
+                    or any(
+                        _header(masked, b) == "for"
+                        for b in source.enclosing_blocks(r)
+                        if b > body_open
+                    )
+                    for r in received
+                )
+                exit_at = _unsafe_exit(source, span, go.start(), name)
+                if not received:
+                    reason, extra = "never_received", {}
+                elif in_loop and not loop_receive:
+                    reason, extra = "senders_in_loop", {}
+                elif exit_at is not None:
+                    reason, extra = "early_return", {"returns_at": source.line_at(exit_at)}
+                else:
+                    continue
+                pass_.report(
+                    source.line_at(sends[0]),
+                    channel=name,
+                    reason=reason,
+                    **extra,
+                    hint="give the channel a buffer for every sender, "
+                    "or send in a select with <-ctx.Done()",
+                )
+
+
+__all__ = [
+    "detect_channel_as_mutex",
+    "detect_goroutine_send_leak",
+    "detect_magic_channel_buffer",
+]
@@ -0,0 +1,203 @@
+"""Struct literals that rely on the order of the struct's fields.
+
+``Config{":8080", 30, true}`` keeps compiling when someone swaps two
+fields of the same type, and stops compiling (far from the change) when a
+field is added. ``Config{Addr: ":8080", Timeout: 30, Debug: true}`` does
+neither. A positional literal is reported when its struct has more than
+``max_fields`` fields, or when the struct comes from another package,
+which can reorder it in any release.
+
+Structs of the package are read from its source. Structs of other
+packages are known only through ``go/types``, so ``pkg.T{...}`` is
+reported when the scan type-checks anyway (some enabled rule requires
+types) and the package checks far enough to say ``pkg.T`` is a struct.
+The rule itself stays at syntax level: it is on by default, and should
+not make every scan run the type checker.
+Elements of a slice, array or map literal with their type elided
+(``[]Point{{1, 2}, {3, 4}}``) are literals of the element type too; the
+``small_elements`` exclusion skips them when every field of the struct
+is a number, string or bool, the usual shape of a table of points or
+ranges. Test files are never reported.
+
+A literal that lists every field gets a fix that names each of them.
+"""
+
+from __future__ import annotations
+
+import re
+
+from desloppify.languages.go.detectors._inspector import GoFile, Pass
+from desloppify.languages.go.detectors._source import matching_brace
+from desloppify.languages.go.detectors.concurrency import _statements
+from desloppify.languages.go.detectors.logic import _split_top_level
+
+# Structs with at most this many fields may be written positionally (the
+# ``max_fields`` option's default).
+UNKEYED_MAX_FIELDS = 2
+UNKEYED_EXCLUDES = ("small_elements",)
+
+_SMALL_TYPES = frozenset(
+    {
+        "bool",
+        "string",
+        "byte",
+        "rune",
+        "int",
+        "int8",
+        "int16",
+        "int32",
+        "int64",
+        "uint",
+        "uint8",
+        "uint16",
+        "uint32",
+        "uint64",
+        "uintptr",
+        "float32",
+        "float64",
+    }
+)
+
+_STRUCT_RE = re.compile(r"\btype[ \t]+(\w+)(?:\[[^\]\n]*\])?[ \t]+struct[ \t]*\{")
+# `T{`, `pkg.T{` or `T[int]{` with the brace right after the type, as gofmt
+# writes literals; `if x {` and `func f() T {` have a space before theirs.
+_LITERAL_RE = re.compile(r"(?<![\w.])((?:\w+\.)?[A-Za-z_]\w*)(?:\[[^\]\n]*\])?\{")
+_CONTAINER_RE = re.compile(r"(?:\[[^\]\n]*\])+\*?$")
+_KEYED_RE = re.compile(r"\s*\w+\s*:(?!=)")
+_KEYWORDS = frozenset({"struct", "interface", "map", "func", "chan"})
+
+
+def _fields_of(masked: str, open_: int, close: int) -> list[tuple[str, str]]:
+    """(name, type) of each field declared between a struct's braces."""
+    fields = []
+    for s, e in _statements(masked, open_ + 1, close):
+        decl = masked[s:e].split("`")[0].strip()
+        named = re.match(r"(\w+(?:[ \t]*,[ \t]*\w+)*)[ \t]+(\S.*)$", decl)
+        if named is not None:
+            type_ = named.group(2).strip()
+            fields += [(name, type_) for name in re.findall(r"\w+", named.group(1))]
+        elif decl:
+            fields.append((re.sub(r"\[.*", "", decl).lstrip("*").rsplit(".", 1)[-1], decl))
+    return fields
+
+
+def _local_structs(files: tuple[GoFile, ...]) -> dict[str, list[tuple[str, str]]]:
+    """Fields of each struct type the package declares, in order."""
+    structs = {}
+    for file in files:
+        for m in _STRUCT_RE.finditer(file.masked):
+            close = matching_brace(file.masked, m.end() - 1)
+            if close is not None:
+                structs[m.group(1)] = _fields_of(file.masked, m.end() - 1, close)
+    return structs
+
+
+def _type_fields(struct: str) -> list[tuple[str, str]] | None:
+    """(name, type) of the fields of a ``go/types`` struct string, e.g. ``struct{X int}``."""
+    if not struct.startswith("struct{") or not struct.endswith("}"):
+        return None
+    body = struct[len("struct{") : -1]
+    fields = []
+    for a, b in _split_top_level(body, 0, len(body), "; ") if body else ():
+        decl = re.sub(r' "(?:[^"\\]|\\.)*"$', "", body[a:b])
+        name, _, type_ = decl.partition(" ")
+        if not type_:
+            name, type_ = re.sub(r"\[.*", "", decl).lstrip("*").rsplit(".", 1)[-1], decl
+        fields.append((name, type_))
+    return fields
+
+
+def _elements(masked: str, open_: int, close: int) -> list[tuple[int, int]]:
+    """Spans of the non-empty elements between a literal's braces."""
+    return [
+        (a, b)
+        for a, b in _split_top_level(masked, open_ + 1, close, ",")
+        if masked[a:b].strip()
+    ]
+
+
+def _literals(masked: str) -> list[tuple[int, int, int, str, bool]]:
+    """(start, ``{``, ``}``, type, elided) of each struct-shaped literal in the file."""
+    found = []
+    for m in _LITERAL_RE.finditer(masked):
+        open_ = m.end() - 1
+        close = matching_brace(masked, open_)
+        name = m.group(1)
+        if close is None or name in _KEYWORDS:
+            continue
+        line_start = masked.rfind("\n", 0, m.start()) + 1
+        if not _CONTAINER_RE.search(masked, line_start, m.start()):
+            found.append((m.start(), open_, close, name, False))
+            continue
+        for a, _ in _elements(masked, open_, close):
+            inner = a + len(masked[a:close]) - len(masked[a:close].lstrip())
+            inner_close = matching_brace(masked, inner) if masked[inner] == "{" else None
+            if inner_close is not None:
+                found.append((inner, inner, inner_close, name, True))
+    return found
+
+
+def _keyed_fix(
+    source: GoFile, start: int, close: int, elements: list[tuple[int, int]], names: list[str]
+) -> dict:
+    """The fix that puts each field's name before its value."""
+    old = source.content[start : close + 1]
+    new = old
+    for (a, b), name in reversed(list(zip(elements, names))):
+        at = a + len(source.masked[a:b]) - len(source.masked[a:b].lstrip()) - start
+        new = f"{new[:at]}{name}: {new[at:]}"
+    return {
+        "title": "Name the fields",
+        "line": source.line_at(start),
+        "old": old,
+        "new": new,
+    }
+
+
+def detect_unkeyed_struct_literal(pass_: Pass) -> None:
+    """Detect struct literals that list their values without field names.
+
+    Reported with the ``type``, how many values it lists and how many
+    fields it has, and whether the struct is ``foreign`` (declared in
+    another package).
+    """
+    source = pass_.file
+    masked = source.masked
+    files = pass_.types.files if pass_.types is not None else (source,)
+    local = (
+        pass_.types.memo("composites:structs", lambda: _local_structs(files))
+        if pass_.types is not None
+        else _local_structs(files)
+    )
+    max_fields = pass_.options["max_fields"]
+    skip_small = "small_elements" in pass_.options["exclude"]
+    for start, open_, close, name, elided in _literals(masked):
+        elements = _elements(masked, open_, close)
+        if not elements or any(_KEYED_RE.match(masked, a, b) for a, b in elements):
+            continue
+        foreign = "." in name
+        if foreign:
+            struct = pass_.type_of(open_, close + 1)
+            fields = _type_fields(struct) if struct is not None else None
+        else:
+            fields = local.get(name)
+        if not fields or (not foreign and len(fields) <= max_fields):
+            continue
+        if elided and skip_small and all(t in _SMALL_TYPES for _, t in fields):
+            continue
+        entry = pass_.report(
+            source.line_at(start),
+            type=name,
+            values=len(elements),
+            fields=len(fields),
+            foreign=foreign,
+        )
+        if len(elements) == len(fields):
+            entry["fix"] = _keyed_fix(source, start, close, elements, [n for n, _ in fields])
+
+
+__all__ = [
+    "UNKEYED_EXCLUDES",
+    "UNKEYED_MAX_FIELDS",
+    "detect_unkeyed_struct_literal",
+]
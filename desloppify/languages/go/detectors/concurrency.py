@@ -0,0 +1,1190 @@
+"""Go concurrency smells: goroutines that share state they should not,
+mutexes left locked or held across blocking calls, channels used after they
+are closed, loops that cannot be cancelled or that spin, lazy initialization
+that races with its readers and variables accessed both atomically and not."""
+
+from __future__ import annotations
+
+import re
+from collections import defaultdict
+from collections.abc import Callable
+from dataclasses import dataclass
+
+from desloppify.languages.go.detectors._inspector import GoFile, Pass
+from desloppify.languages.go.detectors._source import matching_brace
+from desloppify.languages.go.detectors.error_flow import _body_brace, _closing_paren
+from desloppify.languages.go.detectors.panics import _FUNC_RE, _functions
+from desloppify.languages.go.detectors.signatures import _params
+
+# `for i := range s {`, `for i, v := range s {`, `for i := 0; i < n; i++ {`
+_RANGE_LOOP_RE = re.compile(r"\bfor\s+(\w+)(?:\s*,\s*(\w+))?\s*:=\s*range\b[^{\n]*\{")
+_CLAUSE_LOOP_RE = re.compile(r"\bfor\s+(\w+)\s*:=[^;{\n]*;[^;{\n]*;[^{\n]*\{")
+_GO_FUNC_RE = re.compile(r"(?<![\w.])go\s+func\s*\(([^)]*)\)\s*\{")
+# `var wg sync.WaitGroup`, `wg *sync.WaitGroup` (params, fields), `wg := &sync.WaitGroup{}`,
+# `wg := new(sync.WaitGroup)`
+_WAITGROUP_DECL_RE = re.compile(
+    r"\b(\w+)\s+\*?sync\.WaitGroup\b"
+    r"|\b(\w+)\s*:?=\s*(?:&?sync\.WaitGroup\s*\{|new\(\s*sync\.WaitGroup\s*\))"
+)
+_ADD_CALL_RE = re.compile(r"(?<![\w.])((?:\w+\.)*(\w+))\.Add\s*\(")
+
+# Each iteration gets its own loop variable from Go 1.22.
+_PER_ITERATION_SINCE = (1, 22)
+
+
+def _index_write_re(name: str) -> re.Pattern[str]:
+    """``x[name] = …`` (or ``+=``, ``++`` …), but not ``x[name] == …``."""
+    return re.compile(
+        rf"([\w.]+)\[\s*{name}\s*\]\s*(?:(?:[-+*/%&|^]|<<|>>|&\^)?=(?!=)|\+\+|--)"
+    )
+
+
+def _loops(masked: str) -> list[tuple[int, int, list[str]]]:
+    """(body open, body close, loop variables) for every ``:=`` for loop."""
+    loops = []
+    for regex in (_RANGE_LOOP_RE, _CLAUSE_LOOP_RE):
+        for m in regex.finditer(masked):
+            close = matching_brace(masked, m.end() - 1)
+            if close is None:
+                continue
+            names = [n for n in m.groups() if n and n != "_"]
+            loops.append((m.end() - 1, close, names))
+    return loops
+
+
+def detect_goroutine_index_capture(pass_: Pass) -> None:
+    """Detect goroutines in a loop writing ``s[i]`` through the captured loop variable.
+
+    Before Go 1.22 the loop variable is shared by every iteration, so all
+    the goroutines are likely to see its last value and write the same
+    element. Copying it (``i := i``) or passing it as an argument fixes
+    that; modules on Go 1.22 or later are skipped. Files outside any module
+    are checked, since their language version is unknown.
+    """
+    source = pass_.file
+    if (source.go_version or (0, 0)) >= _PER_ITERATION_SINCE:
+        return
+    masked = source.masked
+    reported: set[int] = set()
+    for body_open, body_close, names in _loops(masked):
+        for go in _GO_FUNC_RE.finditer(masked, body_open, body_close):
+            closure_close = matching_brace(masked, go.end() - 1)
+            if closure_close is None:
+                continue
+            params = set(re.findall(r"\w+", go.group(1)))
+            before = masked[body_open + 1 : go.start()]
+            for name in names:
+                if name in params or re.search(rf"\b{name}\s*:=\s*{name}\b", before):
+                    continue
+                write = _index_write_re(name).search(masked, go.end(), closure_close)
+                if write is None or write.start() in reported:
+                    continue
+                reported.add(write.start())
+                pass_.report(source.line_at(write.start()), slice=write.group(1), var=name)
+
+
+def _waitgroups(masked: str) -> set[str]:
+    """Names declared as a ``sync.WaitGroup`` (or pointer to one) anywhere in the file."""
+    return {a or b for a, b in _WAITGROUP_DECL_RE.findall(masked)}
+
+
+def detect_waitgroup_add_in_goroutine(pass_: Pass) -> None:
+    """Detect ``wg.Add`` called inside the ``go func`` body it is counting.
+
+    The goroutine may not have started by the time ``wg.Wait`` runs, so
+    ``Wait`` can return early; ``Add`` belongs before the ``go`` statement.
+    Only names the file declares as a ``sync.WaitGroup`` are checked. A
+    goroutine that also calls ``Wait`` on the same group owns it, so its
+    ``Add`` calls (before spawning workers, say) are left alone.
+    """
+    source = pass_.file
+    masked = source.masked
+    groups = _waitgroups(masked)
+    if not groups:
+        return
+    reported: set[int] = set()
+    for go in _GO_FUNC_RE.finditer(masked):
+        close = matching_brace(masked, go.end() - 1)
+        if close is None:
+            continue
+        body = masked[go.end() : close]
+        for add in _ADD_CALL_RE.finditer(masked, go.end(), close):
+            receiver, name = add.group(1), add.group(2)
+            if name not in groups or add.start() in reported:
+                continue
+            if re.search(rf"(?<![\w.]){re.escape(receiver)}\.Wait\s*\(", body):
+                continue
+            reported.add(add.start())
+            pass_.report(source.line_at(add.start()), waitgroup=receiver)
+
+
+# `mu.Lock()` / `s.mu.RLock()` as a statement of its own.
+_LOCK_RE = re.compile(r"^[ \t]*((?:\w+\.)*\w+)\.(R?Lock)\(\)", re.MULTILINE)
+_UNLOCK_CALL_RE = re.compile(r"(?<![\w.])((?:\w+\.)*\w+)\.(R?Unlock)\(\)")
+_FUNC_LIT_RE = re.compile(r"(?<![\w.])func[ \t]*\(")
+_CLAUSE_RE = re.compile(r"^[ \t]*(?:case\b|default[ \t]*:)", re.MULTILINE)
+# `lockAll`, `LockShards`, `acquire`, `flushLocked`; not `AddBlock` or `UpdateClock`.
+_LOCK_HELPER_RE = re.compile(r"^(?:[lL]ock|[aA]cquire)(?![a-z])|Locked$")
+# `s.unlockAndNotify()`, `unlock(mu)`, `UnlockAll()`, but not a plain `x.Unlock()`.
+_UNLOCK_HELPER_RE = re.compile(r"(?<![\w.])(?:\w+\.)*(?:unlock\w*|R?Unlock\w+)\(")
+_CLAUSE_COLON_RE = re.compile(r":(?!=)")
+_LABEL_RE = re.compile(r"(\w+)[ \t]*:(?!=)\s*")
+_HEADER_RE = re.compile(r"\s*(?:\w+[ \t]*:(?!=)\s*)?(?:if|for|switch)\b")
+
+_UNLOCKED, _EXIT, _HOLDING = "unlocked", "exit", "holding"
+
+
+def _function_spans(file: GoFile) -> list[tuple[str, int, int]]:
+    """(name, body open, body close) for declared functions and function literals."""
+    def build() -> list[tuple[str, int, int]]:
+        masked = file.masked
+        spans = [(f.name, f.body_open, f.body_close) for f in _functions(file)]
+        for m in _FUNC_LIT_RE.finditer(masked):
+            if m.start() == 0 or masked[m.start() - 1] == "\n":
+                continue  # a method declaration
+            params_close = _closing_paren(masked, m.end() - 1)
+            body_open = _body_brace(masked, params_close + 1) if params_close else None
+            body_close = matching_brace(masked, body_open) if body_open is not None else None
+            if body_close is not None:
+                spans.append(("", body_open, body_close))
+        return spans
+
+    return file.memo("concurrency:functions", build)
+
+
+def _mutex_key(receiver: str) -> str:
+    """``s.mu`` and ``c.mu`` in two methods name the same field; compare them as ``.mu``."""
+    return "." + receiver.split(".", 1)[1] if "." in receiver else receiver
+
+
+def _handed_off(files: tuple[GoFile, ...]) -> frozenset[str]:
+    """Mutexes a declared function unlocks without locking them: released for a caller."""
+    keys: set[str] = set()
+    for file in files:
+        masked = file.masked
+        for func in _functions(file):
+            for unlock in _UNLOCK_CALL_RE.finditer(masked, func.body_open, func.body_close):
+                receiver = unlock.group(1)
+                lock = re.compile(rf"(?<![\w.]){re.escape(receiver)}\.R?Lock\(\)")
+                if not lock.search(masked, func.body_open, unlock.start()):
+                    keys.add(_mutex_key(receiver))
+    return frozenset(keys)
+
+
+def _statements(masked: str, start: int, end: int) -> list[tuple[int, int]]:
+    """Spans of the statements in ``masked[start:end]`` at its own nesting level."""
+    spans = []
+    depth = 0
+    begin = start
+    for i in range(start, end):
+        ch = masked[i]
+        if ch in "([{":
+            depth += 1
+        elif ch in ")]}":
+            depth -= 1
+        elif depth == 0 and ch in "\n;":
+            if ch == ";" and _HEADER_RE.match(masked, begin):
+                continue  # `if v, err := f(); err != nil {`: the init is part of the header
+            if masked[begin:i].strip():
+                spans.append((begin, i))
+            begin = i + 1
+    if masked[begin:end].strip():
+        spans.append((begin, end))
+    return spans
+
+
+def _blocks(masked: str, start: int, end: int) -> list[tuple[int, int]]:
+    """(open, close) of each ``{ }`` block in a statement outside parentheses."""
+    blocks = []
+    depth = 0
+    i = start
+    while i < end:
+        ch = masked[i]
+        if ch in "([":
+            depth += 1
+        elif ch in ")]":
+            depth -= 1
+        elif ch == "{" and depth == 0:
+            close = matching_brace(masked, i)
+            if close is None or close >= end:
+                break
+            blocks.append((i, close))
+            i = close
+        i += 1
+    return blocks
+
+
+def _clauses(masked: str, open_brace: int, close: int) -> list[tuple[int, int, bool]]:
+    """(start, end, is default) of each clause in a switch or select body."""
+    heads = [
+        m
+        for m in _CLAUSE_RE.finditer(masked, open_brace + 1, close)
+        if not masked[open_brace + 1 : m.start()].count("{")
+        - masked[open_brace + 1 : m.start()].count("}")
+    ]
+    clauses = []
+    for index, head in enumerate(heads):
+        is_default = "default" in head.group(0)
+        colon = None if is_default else _CLAUSE_COLON_RE.search(masked, head.end(), close)
+        start = head.end() if colon is None else colon.end()
+        end = heads[index + 1].start() if index + 1 < len(heads) else close
+        clauses.append((start, end, is_default))
+    return clauses
+
+
+def _walk(
+    masked: str,
+    start: int,
+    end: int,
+    unlock: re.Pattern[str],
+    *,
+    loop: bool,
+    breakable: bool,
+    labels: frozenset[str] = frozenset(),
+) -> tuple[str, int]:
+    """How the paths through ``masked[start:end]`` leave it, holding the lock at ``start``.
+
+    ``_UNLOCKED`` when every path unlocks, ``_EXIT`` (with the offset) when
+    one returns or jumps out still holding it, else ``_HOLDING``. ``loop``
+    and ``breakable`` say whether ``continue`` and ``break`` stay inside,
+    and ``labels`` names the labeled statements that ``break L`` and
+    ``continue L`` can target without leaving.
+    """
+    pending: frozenset[str] = frozenset()
+    for s, e in _statements(masked, start, end):
+        text = masked[s:e].strip()
+        label = _LABEL_RE.match(text)
+        if label:
+            pending, text = frozenset({label.group(1)}), text[label.end() :]
+            if not text:
+                continue
+        inner, pending = labels | pending, frozenset()
+        word = re.match(r"\w*", text).group(0)
+        if unlock.match(text) or (word == "defer" and unlock.search(text)):
+            return _UNLOCKED, s
+        if word == "return":
+            return _EXIT, s
+        if word in ("break", "continue"):
+            target = text[len(word) :].strip()
+            if target:
+                stays = target in labels
+            else:
+                stays = loop if word == "continue" else breakable
+            return (_HOLDING, s) if stays else (_EXIT, s)
+        blocks = _blocks(masked, s, e)
+        if not blocks:
+            continue
+        if word == "if":
+            results = [
+                _walk(masked, a + 1, b, unlock, loop=loop, breakable=breakable, labels=labels)
+                for a, b in blocks
+            ]
+            exits = [r for r in results if r[0] == _EXIT]
+            if exits:
+                return exits[0]
+            tail = masked[blocks[-2][1] + 1 : blocks[-1][0]] if len(blocks) > 1 else ""
+            has_else = bool(re.fullmatch(r"\s*else\s*", tail))
+            if has_else and all(r[0] == _UNLOCKED for r in results):
+                return _UNLOCKED, s
+        elif word == "for":
+            a, b = blocks[-1]
+            result = _walk(masked, a + 1, b, unlock, loop=True, breakable=True, labels=inner)
+            if result[0] == _EXIT:
+                return result
+        elif word in ("switch", "select"):
+            a, b = blocks[-1]
+            clauses = _clauses(masked, a, b)
+            results = [
+                _walk(masked, c, d, unlock, loop=loop, breakable=True, labels=inner)
+                for c, d, _ in clauses
+            ]
+            exits = [r for r in results if r[0] == _EXIT]
+            if exits:
+                return exits[0]
+            complete = word == "select" or any(default for _, _, default in clauses)
+            if clauses and complete and all(r[0] == _UNLOCKED for r in results):
+                return _UNLOCKED, s
+    return _HOLDING, end
+
+
+def _enclosing_blocks(masked: str, body_open: int, pos: int) -> list[int]:
+    """Opening braces of the blocks around ``pos``, outermost (the body) first."""
+    stack = []
+    for i in range(body_open, pos):
+        if masked[i] == "{":
+            stack.append(i)
+        elif masked[i] == "}" and stack:
+            stack.pop()
+    return stack
+
+
+def _header(masked: str, open_brace: int) -> str:
+    """The keyword starting the line a block opens on (``}`` stripped)."""
+    line = masked[masked.rfind("\n", 0, open_brace) + 1 : open_brace]
+    return re.match(r"\w*", line.strip().lstrip("}").strip()).group(0)
+
+
+def _statement_end(masked: str, close: int) -> int:
+    """End of the if/else chain whose block closes at ``close``."""
+    while True:
+        m = re.compile(r"[ \t]*else\b[^{\n]*\{").match(masked, close + 1)
+        if m is None:
+            return close + 1
+        close = matching_brace(masked, m.end() - 1) or close
+
+
+def _unreleased(
+    masked: str, body_open: int, lock_end: int, unlock: re.Pattern[str]
+) -> int | None:
+    """Offset of an exit that still holds the lock taken just before ``lock_end``."""
+    blocks = _enclosing_blocks(masked, body_open, lock_end)
+    start = lock_end
+    while blocks:
+        open_brace = blocks.pop()
+        close = matching_brace(masked, open_brace)
+        header = _header(masked, open_brace)
+        end = close
+        if header in ("switch", "select"):
+            ends = [d for c, d, _ in _clauses(masked, open_brace, close) if c <= start < d]
+            end = ends[0] if ends else close
+        state, offset = _walk(masked, start, end, unlock, loop=False, breakable=False)
+        if state == _UNLOCKED:
+            return None
+        if state == _EXIT:
+            return offset
+        if not blocks or header == "for":
+            return close  # falls off the end of the function, or into the next iteration
+        start = _statement_end(masked, close) if header in ("if", "else") else close + 1
+    return None
+
+
+def _lock_left_to_others(
+    pass_: Pass, receiver: str, unlock_name: str, lock_end: int, span: tuple[str, int, int]
+) -> bool:
+    """Whether the lock of ``receiver`` taken before ``lock_end`` is released elsewhere.
+
+    A deferred unlock counts too: it covers every path, so there is nothing to walk.
+    """
+    masked = pass_.file.masked
+    name, body_open, body_close = span
+    if _LOCK_HELPER_RE.search(name):
+        return True
+    if pass_.types is not None:
+        handed_off = pass_.types.memo(
+            "concurrency:handed_off", lambda: _handed_off(pass_.types.files)
+        )
+    else:
+        handed_off = _handed_off((pass_.file,))
+    mutex = re.escape(receiver)
+    after = masked[lock_end:body_close]
+    unlocks_here = re.search(rf"(?<![\w.]){mutex}\.{unlock_name}\(\)", after)
+    if not unlocks_here and _mutex_key(receiver) in handed_off:
+        return True
+    if re.search(rf"\bdefer\b[^\n]*(?<![\w.]){mutex}\.{unlock_name}\(\)", after):
+        return True
+    if re.search(
+        rf"defer[ \t]+func[ \t]*\([ \t]*\)[ \t]*\{{[^}}]*(?<![\w.]){mutex}\.{unlock_name}\(",
+        masked[body_open:body_close],
+    ):
+        return True
+    passed = re.search(rf"[(,][ \t]*&?{mutex}[ \t]*[,)]", after)
+    return bool(passed or _UNLOCK_HELPER_RE.search(after))
+
+
+def detect_mutex_unlock_missing(pass_: Pass) -> None:
+    """Detect ``mu.Lock()`` (or ``RLock``) with a path that leaves the function locked.
+
+    Paths are followed through ``if``/``else``, ``for``, ``switch`` and
+    ``select``: a ``return``, a ``break``/``continue`` out of the locked
+    region, or the end of the function reached before ``Unlock`` (or
+    ``RUnlock`` for ``RLock``) is reported at the lock. A ``defer`` of the
+    unlock anywhere in the function covers every path. Locks handed between
+    functions are left alone: a lock the function never releases when some
+    function in the package unlocks that mutex (field) without locking it, a
+    mutex passed as an argument after the lock, a call to an ``unlock*``
+    helper, and functions named for locking: starting with ``lock`` or
+    ``acquire`` as a word (``lockAll``, ``Acquire``) or ending in ``Locked``.
+    """
+    source = pass_.file
+    masked = source.masked
+    spans = _function_spans(source)
+    for lock in _LOCK_RE.finditer(masked):
+        receiver, method = lock.group(1), lock.group(2)
+        enclosing = [s for s in spans if s[1] < lock.start() < s[2]]
+        if not enclosing:
+            continue
+        span = max(enclosing, key=lambda s: s[1])
+        unlock_name = method[:-4] + "Unlock"
+        if _lock_left_to_others(pass_, receiver, unlock_name, lock.end(), span):
+            continue
+        unlock = re.compile(rf"{re.escape(receiver)}\.{unlock_name}\(\)")
+        if _unreleased(masked, span[1], lock.end(), unlock) is None:
+            continue
+        pass_.report(source.line_at(lock.start()), mutex=receiver, method=method)
+
+
+# What counts as blocking, by kind; the rule's options pick the kinds.
+BLOCKING_CALLS = {
+    "channel": r"<-",
+    "sleep": r"\btime\.Sleep\(",
+    "network": (
+        r"\bhttp\.(?:Get|Head|Post|PostForm)\(|\bnet\.(?:Dial|Listen)\w*\("
+        r"|\.(?:Do|RoundTrip|Dial\w*|Accept)\("
+        r"|\b[\w.]*[cC]lient\.(?:Get|Head|Post|PostForm)\("
+    ),
+    "sql": r"\.(?:Query|QueryRow|Exec|Ping|Prepare|Begin|BeginTx|Commit)(?:Context)?\(",
+    "exec": (
+        r"(?:\bexec\.Command(?:Context)?\([^\n]*\)|\bcmd)"
+        r"\.(?:Run|Output|CombinedOutput|Wait)\("
+    ),
+    "file": r"\bos\.(?:WriteFile|Create|OpenFile|Remove|RemoveAll|Rename|Truncate)\(|\.Sync\(\)",
+}
+BLOCKING_KINDS = tuple(BLOCKING_CALLS)
+
+
+def parse_blocking_calls(specs: list[str] | tuple[str, ...]) -> dict[str, list[str]]:
+    """``kind:pattern`` entries as more patterns per kind; malformed ones are skipped."""
+    calls: dict[str, list[str]] = defaultdict(list)
+    for spec in specs:
+        kind, _, pattern = spec.partition(":")
+        if kind.strip() not in BLOCKING_CALLS or not pattern.strip():
+            continue
+        try:
+            re.compile(pattern.strip())
+        except re.error:
+            continue
+        calls[kind.strip()].append(pattern.strip())
+    return calls
+
+
+def _held_until(
+    masked: str, body_open: int, body_close: int, lock_end: int, unlock: re.Pattern[str]
+) -> int:
+    """Where the region held by the lock taken just before ``lock_end`` ends.
+
+    At the first unlock among the statements of the lock's own block, or
+    the end of that block; a deferred unlock holds it to the end of the
+    function.
+    """
+    deferred = re.compile(rf"\bdefer\b[^\n]*{unlock.pattern}|defer[ \t]+func[ \t]*\(\)[ \t]*\{{")
+    for m in deferred.finditer(masked, lock_end, body_close):
+        if "func" not in m.group() or unlock.search(
+            masked, m.end(), matching_brace(masked, m.end() - 1) or body_close
+        ):
+            return body_close
+    block = _enclosing_blocks(masked, body_open, lock_end)[-1]
+    close = matching_brace(masked, block) or body_close
+    for s, e in _statements(masked, lock_end, close):
+        if unlock.match(masked[s:e].strip()):
+            return s
+    return close
+
+
+def detect_blocking_under_lock(pass_: Pass) -> None:
+    """Detect calls that block while a mutex is held.
+
+    The region runs from ``mu.Lock()`` to the ``Unlock`` that ends it in the
+    same block, or to the end of the function after ``defer mu.Unlock()``.
+    Calls of the kinds in the ``blocking`` option (``read_blocking`` under
+    ``RLock``, where other readers still get through) found there are
+    reported, unless an unlock in an enclosing block comes first (``if !ok
+    { mu.Unlock(); return fetch() }``). Code in function literals, goroutines
+    included, does not run under the lock. The ``calls`` option adds
+    ``kind:pattern`` entries, a regular expression for more calls of a kind
+    (``network:\\bc\\.Post\\(`` for an ``*http.Client`` named ``c``).
+    """
+    source = pass_.file
+    masked = source.masked
+    spans = _function_spans(source)
+    extra = parse_blocking_calls(pass_.options["calls"])
+    patterns = {
+        kind: re.compile("|".join([pattern, *extra.get(kind, ())]))
+        for kind, pattern in BLOCKING_CALLS.items()
+    }
+    kinds = {
+        "Lock": pass_.options["blocking"],
+        "RLock": pass_.options["read_blocking"],
+    }
+    reported: set[int] = set()
+    for lock in _LOCK_RE.finditer(masked):
+        receiver, method = lock.group(1), lock.group(2)
+        enclosing = [s for s in spans if s[1] < lock.start() < s[2]]
+        if not enclosing or not kinds[method]:
+            continue
+        _, body_open, body_close = max(enclosing, key=lambda s: s[1])
+        unlock = re.compile(rf"(?<![\w.]){re.escape(receiver)}\.{method[:-4]}Unlock\(\)")
+        end = _held_until(masked, body_open, body_close, lock.end(), unlock)
+        nested = [(a, b) for _, a, b in spans if body_open < a and lock.end() < b and a < end]
+        unlocks = [
+            m.start()
+            for m in unlock.finditer(masked, lock.end(), end)
+            if not masked[source.line_start(m.start()) : m.start()].strip()  # not deferred
+        ]
+        for kind in kinds[method]:
+            for call in patterns[kind].finditer(masked, lock.end(), end):
+                offset = call.start()
+                line = source.line_at(offset)
+                if line in reported:
+                    continue
+                if any(a < offset < b for a, b in nested):
+                    continue
+                blocks = source.enclosing_blocks(offset)
+                if any(u < offset and source.enclosing_blocks(u)[0] in blocks for u in unlocks):
+                    continue
+                reported.add(line)
+                pass_.report(
+                    line,
+                    mutex=receiver,
+                    blocking=kind,
+                    locked_at=source.line_at(lock.start()),
+                    hint="release the lock before the call, or copy what it needs first",
+                )
+
+
+# `close(ch)` as a statement of its own; `defer close(ch)` runs after every send.
+_CLOSE_RE = re.compile(r"^[ \t]*close\([ \t]*((?:\w+\.)*\w+)[ \t]*\)", re.MULTILINE)
+_FALL, _BREAK, _CONTINUE, _STOP = "fall", "break", "continue", "stop"
+
+
+def _scan_after(
+    masked: str, start: int, end: int, send: re.Pattern[str], own: Callable[[int], bool]
+) -> tuple[list[int], str]:
+    """Sends in ``masked[start:end]`` until a statement leaves it, and how it is left.
+
+    A send anywhere in a statement counts, nested blocks included, since
+    some path may run it; only ``return``, ``panic``, ``break`` and
+    ``continue`` at this level end the path through the region.
+    """
+    found: list[int] = []
+    for s, e in _statements(masked, start, end):
+        found += [m.start() for m in send.finditer(masked, s, e) if own(m.start())]
+        text = masked[s:e].strip()
+        word = re.match(r"\w*", text).group(0)
+        if word in ("return", "goto") or re.match(r"panic\s*\(", text):
+            return found, _STOP
+        if word == "break":
+            return found, _BREAK
+        if word == "continue":
+            return found, _CONTINUE
+    return found, _FALL
+
+
+def _sends_after_close(
+    masked: str, body_open: int, close_end: int, send: re.Pattern[str], own: Callable[[int], bool]
+) -> list[int]:
+    """Offsets of sends that can run after the ``close`` ending at ``close_end``."""
+    blocks = _enclosing_blocks(masked, body_open, close_end)
+    found: list[int] = []
+    start, outcome = close_end, _FALL
+    while blocks:
+        open_brace = blocks.pop()
+        close = matching_brace(masked, open_brace)
+        header = _header(masked, open_brace)
+        end = close
+        if header in ("switch", "select"):
+            ends = [d for c, d, _ in _clauses(masked, open_brace, close) if c <= start < d]
+            end = ends[0] if ends else close
+        if outcome == _FALL:
+            sends, outcome = _scan_after(masked, start, end, send, own)
+            found += sends
+        if outcome == _STOP or not blocks:
+            break
+        if header == "for":
+            if outcome in (_FALL, _CONTINUE):
+                # The next iteration runs the whole body again, sends before the close too.
+                found += _scan_after(masked, open_brace + 1, close, send, own)[0]
+            outcome = _FALL
+        elif header in ("switch", "select") and outcome == _BREAK:
+            outcome = _FALL
+        start = _statement_end(masked, close) if header in ("if", "else") else close + 1
+    return sorted(set(found))
+
+
+def detect_send_after_close(pass_: Pass) -> None:
+    """Detect ``ch <- v`` that can run after ``close(ch)`` in the same function.
+
+    From each ``close`` the statements after it are followed up through the
+    enclosing blocks: a ``return`` or ``panic`` ends the path, an ``else``
+    branch is not reachable from its ``if``, ``break`` skips to after the
+    loop or switch, and a loop body is reachable again from its end, so a
+    send earlier in the same loop counts too. Sends inside function literals
+    belong to those functions and ``defer close(ch)`` is skipped.
+    """
+    source = pass_.file
+    masked = source.masked
+    spans = _function_spans(source)
+
+    def innermost(pos: int) -> tuple[str, int, int] | None:
+        enclosing = [span for span in spans if span[1] < pos < span[2]]
+        return max(enclosing, key=lambda span: span[1]) if enclosing else None
+
+    reported: set[int] = set()
+    for closing in _CLOSE_RE.finditer(masked):
+        function = innermost(closing.start())
+        if function is None:
+            continue
+        channel = closing.group(1)
+        send = re.compile(rf"(?<![\w.]){re.escape(channel)}[ \t]*<-")
+        for offset in _sends_after_close(
+            masked,
+            function[1],
+            closing.end(),
+            send,
+            lambda pos, function=function: innermost(pos) == function,
+        ):
+            if offset in reported:
+                continue
+            reported.add(offset)
+            pass_.report(
+                source.line_at(offset), channel=channel, closed_at=source.line_at(closing.start())
+            )
+
+
+_FOR_RE = re.compile(r"^[ \t]*(?:\w+:[ \t]*)?for\b", re.MULTILINE)
+_RANGE_EXPR_RE = re.compile(r"\brange\s+(.+?)\s*$")
+# Channel operations, sleeps and the usual network and database calls.
+_BLOCKING_RE = re.compile(
+    r"<-|\bselect\b|\btime\.Sleep\(|\b(?:net|http)\.\w+\("
+    r"|\.(?:Read\w*|Write\w*|Accept|Dial\w*|Do|Get|Post|Query\w*|Exec\w*|Recv|Send)\("
+)
+
+
+def _is_channel(body: str, params: tuple[tuple[str, str], ...], expr: str) -> bool:
+    """Whether the range expression ``expr`` is (most likely) a channel."""
+    if expr.endswith(".C") or re.fullmatch(r"time\.(?:Tick|After)\(.*\)", expr):
+        return True
+    if not re.fullmatch(r"\w+", expr):
+        return False
+    if any(name == expr and "chan" in type_ for name, type_ in params):
+        return True
+    made = rf"\b{expr}\s*:?=\s*make\(\s*(?:<-\s*)?chan\b"
+    declared = rf"\bvar\s+{expr}\s+(?:<-\s*)?chan\b"
+    return bool(re.search(f"{made}|{declared}", body))
+
+
+def detect_loop_ignores_context(pass_: Pass) -> None:
+    """Detect loops in functions taking a ``context.Context`` that never look at it.
+
+    A loop is fine when its header or body mentions the context at all:
+    ``select`` on ``ctx.Done()``, ``ctx.Err()``, or ``ctx`` passed to a
+    callee that can give up. Infinite ``for {}`` loops and ranges over a
+    channel (a ``chan`` parameter or local, a ticker's ``.C``) are reported
+    as they are. Loops that end on their own, ranges over anything else and
+    counted or conditional loops, are only reported when the body blocks:
+    channel operations, ``select``, ``time.Sleep``, network or database
+    calls. Loops in function literals count for the function around them,
+    since they capture its context; a loop inside a reported one is not
+    reported again.
+    """
+    source = pass_.file
+    masked = source.masked
+    for func in _functions(source):
+        header = _FUNC_RE.match(masked, func.start)
+        params = _params(masked, header.end() - 1) if header else None
+        contexts = [name for name, type_ in params or () if type_ == "context.Context"]
+        if not contexts or contexts == ["_"]:
+            continue
+        mentions = re.compile(rf"(?<![\w.])(?:{'|'.join(map(re.escape, contexts))})\b")
+        body = masked[func.body_open : func.body_close]
+        reported_until = -1
+        for loop in _FOR_RE.finditer(masked, func.body_open, func.body_close):
+            if loop.start() < reported_until:
+                continue
+            open_brace = _body_brace(masked, loop.end())
+            close = matching_brace(masked, open_brace) if open_brace is not None else None
+            if close is None:
+                continue
+            loop_header = masked[loop.end() : open_brace].strip()
+            if mentions.search(masked, loop.end(), close):
+                continue
+            range_expr = _RANGE_EXPR_RE.search(loop_header)
+            if not loop_header:
+                kind = "infinite"
+            elif range_expr and _is_channel(body, params, range_expr.group(1)):
+                kind = "channel"
+            elif _BLOCKING_RE.search(masked, open_brace, close):
+                kind = "blocking"
+            else:
+                continue
+            reported_until = close
+            pass_.report(source.line_at(loop.start()), loop=kind, context=contexts[0])
+
+
+_SELECT_RE = re.compile(r"(?<![\w.])select\s*\{")
+_LOOP_HEADER_RE = re.compile(r"^[ \t]*(?:\w+:[ \t]*)?for\b(.*)$")
+# Anything that waits, yields or leaves the loop stops the spin.
+_STOPS_SPIN_RE = re.compile(
+    rf"{_BLOCKING_RE.pattern}|\bruntime\.Gosched\(|\.(?:Wait|R?Lock)\(|\bos\.Exit\("
+    r"|\b(?:return|goto)\b|\bpanic\(|\bbreak[ \t]+\w+"
+)
+
+
+def _unbounded_loop(file: GoFile, offset: int) -> int | None:
+    """The body ``{`` of the ``for`` loop directly around ``offset``, if it has no end.
+
+    That is ``for {`` or ``for cond {``; ranges and counted loops end on
+    their own. A function literal in between means the loop is not the one
+    that repeats the code at ``offset``.
+    """
+    masked = file.masked
+    for brace in file.enclosing_blocks(offset):
+        header = masked[file.line_start(brace) : brace]
+        loop = _LOOP_HEADER_RE.match(header)
+        if loop:
+            condition = loop.group(1)
+            bounded = ";" in condition or re.search(r"\brange\b", condition)
+            return None if bounded else brace
+        if re.search(r"\bfunc\b", header):
+            return None
+    return None
+
+
+def detect_busy_select_default(pass_: Pass) -> None:
+    """Detect a ``select`` with a ``default`` that turns an endless loop into a spin.
+
+    Inside ``for {}`` (or ``for cond {}``) a ``select`` that falls through to
+    ``default`` when nothing is ready never waits, so the loop burns a core.
+    Reported unless the ``default`` branch, or the rest of the loop body,
+    sleeps, yields (``runtime.Gosched``), blocks (a channel operation,
+    ``Wait``, ``Lock``, a network call) or leaves the loop. Other calls are
+    taken not to block.
+    """
+    source = pass_.file
+    masked = source.masked
+    for m in _SELECT_RE.finditer(masked):
+        open_brace = m.end() - 1
+        close = matching_brace(masked, open_brace)
+        loop = _unbounded_loop(source, m.start())
+        if close is None or loop is None:
+            continue
+        clauses = [
+            c for c in _CLAUSE_RE.finditer(masked, open_brace, close)
+            if source.enclosing_blocks(c.start())[0] == open_brace
+        ]
+        ends = [c.start() for c in clauses[1:]] + [close]
+        defaults = [(c, end) for c, end in zip(clauses, ends) if "default" in c.group()]
+        if not defaults:
+            continue
+        default, default_end = defaults[0]
+        loop_close = matching_brace(masked, loop) or close
+        rest = masked[loop + 1 : m.start()] + masked[close + 1 : loop_close]
+        if _STOPS_SPIN_RE.search(masked, default.end(), default_end) or _STOPS_SPIN_RE.search(rest):
+            continue
+        pass_.report(
+            source.line_at(default.start()),
+            hint="drop the default to block, or wait in it (time.Sleep, a ticker)",
+        )
+
+
+# `if instance == nil {`, `if c.client == nil {`
+_NIL_CHECK_RE = re.compile(r"(?<![\w.])if[ \t]+((?:\w+\.)?\w+)[ \t]*==[ \t]*nil[ \t]*\{")
+_VAR_DECL_RE = re.compile(r"^var[ \t]+(?:(\()|(\w+(?:[ \t]*,[ \t]*\w+)*))", re.MULTILINE)
+# `resetLocked`: by convention its caller holds the lock.
+_HOLDS_LOCK_RE = re.compile(r"[Ll]ocked")
+
+
+@dataclass(frozen=True)
+class _LazyInit:
+    """``if x == nil { ... x = ... }``, and whether a lock is held at the check and the write."""
+
+    name: str
+    key: str
+    file: GoFile
+    check: int
+    close: int
+    write: int
+    function: tuple[str, int]
+    locked_check: bool
+    locked_write: bool
+
+
+@dataclass(frozen=True)
+class _Access:
+    name: str
+    file: GoFile
+    offset: int
+    function: tuple[str, int]
+    locked: bool
+    write: bool
+
+
+def _package_vars(file: GoFile) -> set[str]:
+    """Names declared by the file's package-level ``var`` declarations."""
+    masked = file.masked
+    names: set[str] = set()
+    for m in _VAR_DECL_RE.finditer(masked):
+        if m.group(2):
+            names.update(re.findall(r"\w+", m.group(2)))
+            continue
+        close = _closing_paren(masked, m.start(1)) or m.end()
+        for s, e in _statements(masked, m.end(), close):
+            lead = re.match(r"\s*(\w+(?:[ \t]*,[ \t]*\w+)*)", masked[s:e])
+            if lead:
+                names.update(re.findall(r"\w+", lead.group(1)))
+    return names
+
+
+def _lock_regions(file: GoFile) -> dict[int, list[tuple[int, int, list[int]]]]:
+    """(start, end, unlocks inside) of each region a mutex is held, by function body."""
+    def build() -> dict[int, list[tuple[int, int, list[int]]]]:
+        masked = file.masked
+        spans = _function_spans(file)
+        regions: dict[int, list[tuple[int, int, list[int]]]] = {}
+        for lock in _LOCK_RE.finditer(masked):
+            enclosing = [s for s in spans if s[1] < lock.start() < s[2]]
+            if not enclosing:
+                continue
+            _, body_open, body_close = max(enclosing, key=lambda s: s[1])
+            receiver, method = lock.group(1), lock.group(2)
+            unlock = re.compile(rf"(?<![\w.]){re.escape(receiver)}\.{method[:-4]}Unlock\(\)")
+            end = _held_until(masked, body_open, body_close, lock.end(), unlock)
+            unlocks = [
+                m.start()
+                for m in unlock.finditer(masked, lock.end(), end)
+                if not masked[file.line_start(m.start()) : m.start()].strip()
+            ]
+            regions.setdefault(body_open, []).append((lock.end(), end, unlocks))
+        return regions
+
+    return file.memo("concurrency:lock_regions", build)
+
+
+def _enclosing_spans(file: GoFile, offset: int) -> list[tuple[str, int, int]]:
+    """The functions and function literals around ``offset``, innermost first."""
+    spans = [s for s in _function_spans(file) if s[1] < offset < s[2]]
+    return sorted(spans, key=lambda s: -s[1])
+
+
+def _locked_at(file: GoFile, offset: int) -> bool:
+    """Whether a mutex the innermost function locked is still held at ``offset``."""
+    spans = _enclosing_spans(file, offset)
+    if not spans:
+        return False
+    name, body_open, _ = spans[0]
+    if _HOLDS_LOCK_RE.search(name):
+        return True
+    blocks = file.enclosing_blocks(offset)
+    return any(
+        start <= offset < end
+        and not any(u < offset and file.enclosing_blocks(u)[0] in blocks for u in unlocks)
+        for start, end, unlocks in _lock_regions(file).get(body_open, ())
+    )
+
+
+def _local(file: GoFile, offset: int, name: str) -> bool:
+    """Whether ``name`` at ``offset`` is a parameter or local variable, not the package's."""
+    if "." in name:
+        return False
+    masked = file.masked
+    declared = re.compile(
+        rf"(?<![\w.]){name}(?:[ \t]*,[ \t]*\w+)*[ \t]*:=|\bvar[ \t]+{name}\b"
+    )
+    return any(
+        re.search(rf"(?<![\w.]){name}\b", masked[file.line_start(body_open) : body_open])
+        or declared.search(masked, body_open, body_close)
+        for _, body_open, body_close in _enclosing_spans(file, offset)
+    )
+
+
+def _in_init(file: GoFile, offset: int) -> bool:
+    """Code in ``init`` runs before any goroutine the package starts."""
+    return any(name == "init" for name, _, _ in _enclosing_spans(file, offset))
+
+
+def _lazy_inits(file: GoFile, package_vars: set[str]) -> list[_LazyInit]:
+    masked = file.masked
+    found = []
+    for m in _NIL_CHECK_RE.finditer(masked):
+        name = m.group(1)
+        if "." not in name and name not in package_vars:
+            continue
+        spans = _enclosing_spans(file, m.start())
+        close = matching_brace(masked, m.end() - 1)
+        if not spans or close is None or _in_init(file, m.start()) or _local(file, m.start(), name):
+            continue
+        assignment = re.compile(rf"(?<![\w.]){re.escape(name)}[ \t]*=(?!=)")
+        write = assignment.search(masked, m.end(), close)
+        if write is None or _enclosing_spans(file, write.start())[0] != spans[0]:
+            continue  # set in a closure (`once.Do`), not here
+        found.append(
+            _LazyInit(
+                name,
+                _mutex_key(name),
+                file,
+                m.start(),
+                close,
+                write.start(),
+                (file.path, spans[0][1]),
+                _locked_at(file, m.start()),
+                _locked_at(file, write.start()),
+            )
+        )
+    return found
+
+
+def _accesses(file: GoFile, key: str) -> list[_Access]:
+    """Reads and writes of ``key`` (a package variable, or ``.field``) in function bodies."""
+    masked = file.masked
+    if key.startswith("."):
+        pattern = re.compile(rf"(?<![\w.])\w+\.{key[1:]}\b(?![ \t]*\()")
+    else:
+        pattern = re.compile(rf"(?<![\w.]){key}\b(?![ \t]*:(?!=))")
+    found = []
+    for m in pattern.finditer(masked):
+        spans = _enclosing_spans(file, m.start())
+        if not spans or _in_init(file, m.start()) or _local(file, m.start(), key):
+            continue
+        found.append(
+            _Access(
+                m.group(),
+                file,
+                m.start(),
+                (file.path, spans[0][1]),
+                _locked_at(file, m.start()),
+                bool(re.match(r"[ \t]*=(?!=)", masked[m.end() :])),
+            )
+        )
+    return found
+
+
+def _racy_lazy_inits(files: tuple[GoFile, ...]) -> list[tuple[GoFile, int, dict]]:
+    """(file, offset, details) of each racy lazy initialization in the package."""
+    package_vars = set().union(*(_package_vars(f) for f in files))
+    sites = [site for file in files for site in _lazy_inits(file, package_vars)]
+    accesses: dict[str, list[_Access]] = defaultdict(list)
+    for key in dict.fromkeys(site.key for site in sites):
+        for file in files:
+            accesses[key].extend(_accesses(file, key))
+    found: list[tuple[GoFile, int, dict]] = []
+    reported: set[tuple[str, tuple[str, int]]] = set()
+    for site in sites:
+        if site.locked_check:
+            continue
+        if site.locked_write:
+            recheck = any(
+                other.key == site.key and other.file is site.file
+                and site.check < other.check < site.close
+                for other in sites
+            )
+            details = {"shape": "double_checked", "recheck": recheck}
+        else:
+            field = site.key.startswith(".")
+            shared = next(
+                (
+                    a for a in accesses[site.key]
+                    if a.locked or (not field and a.function != site.function and not a.write)
+                ),
+                None,
+            )
+            if shared is None:
+                continue
+            details = {
+                "shape": "unlocked",
+                "accessed_at": f"{shared.file.path}:{shared.file.line_at(shared.offset)}",
+            }
+        reported.add((site.key, site.function))
+        found.append((site.file, site.check, {"var": site.name, **details}))
+    for site in sites:
+        if not site.locked_write:
+            continue
+        for access in accesses[site.key]:
+            if access.locked or access.write or (site.key, access.function) in reported:
+                continue
+            reported.add((site.key, access.function))
+            found.append(
+                (
+                    access.file,
+                    access.offset,
+                    {
+                        "var": access.name,
+                        "shape": "unlocked_read",
+                        "written_at": f"{site.file.path}:{site.file.line_at(site.write)}",
+                    },
+                )
+            )
+    return found
+
+
+def detect_racy_lazy_init(pass_: Pass) -> None:
+    """Detect lazy initialization of a shared pointer guarded inconsistently.
+
+    The shape is ``if x == nil { x = ... }`` for a package-level variable or
+    a struct field (fields are matched by name across the package's types).
+    Three cases are reported:
+
+    - the check runs outside the lock and the write inside it, with or
+      without a second check under the lock (double-checked locking);
+    - the initialization takes no lock, while the package reads the
+      variable in another function or touches the field under a lock;
+    - the variable is initialized under a lock, and another function reads
+      it without one (reported once per function).
+
+    A mutex counts as held from ``Lock`` to its ``Unlock`` in the same
+    function, or to the end with ``defer``; functions named ``*Locked``
+    are taken to run under their caller's lock. ``init`` is left out, since
+    it runs before any goroutine. Full race detection is out of scope.
+    """
+    source = pass_.file
+    if pass_.types is None:
+        findings = _racy_lazy_inits((source,))
+    else:
+        files = pass_.types.files
+        findings = pass_.types.memo("concurrency:lazy_init", lambda: _racy_lazy_inits(files))
+    for file, offset, details in findings:
+        if file.path != source.path:
+            continue
+        pass_.report(
+            source.line_at(offset),
+            **details,
+            hint="initialize it with sync.Once, or keep it in an atomic.Pointer",
+        )
+
+
+# `atomic.AddInt64(&hits, 1)`, `atomic.LoadUint32(&s.state)`
+_ATOMIC_CALL_RE = re.compile(
+    r"(?<![\w.])atomic\.(\w+)\([ \t]*&[ \t]*((?:\w+\.)*\w+)[ \t]*[,)]"
+)
+_ATOMIC_TYPE_RE = re.compile(r"(Int32|Int64|Uint32|Uint64|Uintptr|Pointer)$")
+_STRUCT_RE = re.compile(r"\btype[ \t]+(\w+)[ \t]+struct[ \t]*\{")
+ATOMIC_EXCLUDES = ("init", "tests")
+
+
+def _struct_fields(file: GoFile) -> dict[str, list[str]]:
+    """Struct types declaring each named field in the file."""
+    masked = file.masked
+    fields: dict[str, list[str]] = defaultdict(list)
+    for m in _STRUCT_RE.finditer(masked):
+        close = matching_brace(masked, m.end() - 1)
+        if close is None:
+            continue
+        for s, e in _statements(masked, m.end(), close):
+            lead = re.match(r"\s*(\w+(?:[ \t]*,[ \t]*\w+)*)[ \t]+\S", masked[s:e])
+            for name in re.findall(r"\w+", lead.group(1)) if lead else ():
+                fields[name].append(m.group(1))
+    return fields
+
+
+def _atomic_key(operand: str) -> str:
+    """``hits`` for a package variable, ``.state`` for a field however it is reached."""
+    return "." + operand.rsplit(".", 1)[1] if "." in operand else operand
+
+
+def _atomic_accesses(
+    files: tuple[GoFile, ...], exclude: frozenset[str]
+) -> list[tuple[str, str, list[tuple[GoFile, int]], list[tuple[GoFile, int]]]]:
+    """(key, function, atomic sites, plain sites) of each variable used with sync/atomic."""
+    package_vars = set().union(*(_package_vars(f) for f in files))
+    owners: dict[str, list[str]] = defaultdict(list)
+    for file in files:
+        for name, types in _struct_fields(file).items():
+            owners[name].extend(types)
+
+    def skipped(file: GoFile, offset: int) -> bool:
+        return not _enclosing_spans(file, offset) or ("init" in exclude and _in_init(file, offset))
+
+    atomic: dict[str, list[tuple[GoFile, int]]] = defaultdict(list)
+    functions: dict[str, str] = {}
+    operands: dict[str, set[int]] = defaultdict(set)
+    for file in files:
+        for m in _ATOMIC_CALL_RE.finditer(file.masked):
+            operand, key = m.group(2), _atomic_key(m.group(2))
+            if key.startswith("."):
+                if len(owners.get(key[1:], ())) != 1:
+                    continue  # not a field of exactly one struct in the package
+            elif key not in package_vars or _local(file, m.start(), key):
+                continue
+            operands[file.path].add(m.start(2) + len(operand) - len(key.lstrip(".")))
+            if skipped(file, m.start()):
+                continue
+            atomic[key].append((file, m.start()))
+            functions.setdefault(key, m.group(1))
+    found = []
+    for key, sites in atomic.items():
+        name = key.lstrip(".")
+        if key.startswith("."):
+            pattern = re.compile(rf"(?<![\w.])(?:\w+\.)+({name})\b(?![ \t]*\()")
+        else:
+            pattern = re.compile(rf"(?<![\w.])({name})\b(?![ \t]*:(?!=))")
+        plain = []
+        for file in files:
+            masked = file.masked
+            for m in pattern.finditer(masked):
+                if m.start(1) in operands[file.path] or skipped(file, m.start()):
+                    continue
+                if masked[: m.start()].rstrip().endswith("&") or _local(file, m.start(), key):
+                    continue  # the address goes elsewhere; what happens to it is unknown
+                plain.append((file, m.start()))
+        if plain:
+            shown = f"{owners[name][0]}.{name}" if key.startswith(".") else name
+            found.append((shown, functions[key], sites, plain))
+    return found
+
+
+def detect_atomic_mixed_access(pass_: Pass) -> None:
+    """Detect variables updated through ``sync/atomic`` but also read or written plainly.
+
+    Package-level variables and struct fields passed by address to an
+    ``atomic`` function (``atomic.AddInt64(&hits, 1)``) are collected across
+    the package; any other use of them in a function body, such as a plain
+    read or ``hits = 0``, is a plain access and races with the atomic ones.
+    Fields are matched by name, so a field name declared by more than one
+    struct in the package is left alone, as is an address passed anywhere
+    else. The variable is reported once, at its first plain access, with
+    every plain site. The package's own ``_test.go`` files are searched too,
+    but a finding is placed outside them (at an atomic call when every
+    plain access is in a test); the ``exclude`` option leaves out ``init``
+    functions and the tests. The typed wrappers (``atomic.Int64``) make
+    mixing impossible.
+    """
+    source = pass_.file
+    exclude = frozenset(pass_.options["exclude"])
+    if pass_.types is None:
+        files: tuple[GoFile, ...] = (source,)
+    elif "tests" in exclude:
+        files = pass_.types.files
+    else:
+        files = pass_.types.files + pass_.types.tests
+
+    def build() -> list:
+        return _atomic_accesses(files, exclude)
+
+    findings = (
+        pass_.types.memo(f"concurrency:atomics:{sorted(exclude)}", build)
+        if pass_.types is not None
+        else build()
+    )
+    for name, function, sites, plain in findings:
+        anchor = next(
+            (site for site in (*plain, *sites) if not site[0].path.endswith("_test.go")), None
+        )
+        if anchor is None or anchor[0].path != source.path:
+            continue
+        offset = anchor[1]
+        kind = _ATOMIC_TYPE_RE.search(function)
+        wrapper = f"atomic.{kind.group(1)}" if kind else "a typed atomic"
+        if wrapper == "atomic.Pointer":
+            wrapper += "[T]"
+        pass_.report(
+            source.line_at(offset),
+            var=name,
+            atomic_calls=len(sites),
+            plain=[f"{f.path}:{f.line_at(o)}" for f, o in plain],
+            hint=f"make it {wrapper} so every access is atomic",
+        )
+
+
+__all__ = [
+    "ATOMIC_EXCLUDES",
+    "BLOCKING_CALLS",
+    "BLOCKING_KINDS",
+    "detect_atomic_mixed_access",
+    "detect_blocking_under_lock",
+    "detect_busy_select_default",
+    "detect_goroutine_index_capture",
+    "detect_loop_ignores_context",
+    "detect_mutex_unlock_missing",
+    "detect_racy_lazy_init",
+    "detect_send_after_close",
+    "detect_waitgroup_add_in_goroutine",
+    "parse_blocking_calls",
+]
@@ -0,0 +1,407 @@
+"""House rules declared in config (``languages.go.custom_rules``).
+
+Each rule is a JSON object with an ``id``, a ``kind``, a ``message`` and an
+optional ``severity`` (default ``medium``), ``categories`` (what
+``scan --category`` selects it by, e.g. ``["security"]``) and ``unless_in``
+(import path patterns of packages where the rule does not apply, e.g.
+``"example.com/app/internal/clock/..."``). The kinds:
+
+- ``forbid-import``: ``import`` is an import path pattern; ``...`` matches
+  any string, as in go package patterns.
+- ``forbid-call``: ``call`` is ``<import path>.<Func>`` (``*`` for any
+  function), matched through whatever name the file imports the package as.
+- ``forbid-identifier``: ``pattern`` is a regex searched in the names of
+  declared functions, methods, types, variables and constants.
+- ``required-call-pairing``: a function that calls ``call`` must also call
+  ``requires``. Either may be ``<import path>.<Func>`` or ``*.<Method>``;
+  when both are methods, the receiver must be the same (``mu.Lock`` needs
+  ``mu.Unlock``).
+
+Custom rules run as part of the smells pass (``rule_detector``), on the
+same parse of each file, so they are reported, cached, suppressed
+(``//desloppify:ignore <id>``) and golden-tested like built-in smells. Test
+files and files that do not parse are skipped, as for multi-line smells.
+"""
+
+from __future__ import annotations
+
+import functools
+import json
+import os
+import re
+from dataclasses import dataclass
+from pathlib import Path
+
+from desloppify.core.config import CONFIG_FILE, ConfigError, config_line
+from desloppify.file_discovery import rel, resolve_path
+from desloppify.languages.go.detectors._inspector import FileDetector, GoFile, Pass
+from desloppify.languages.go.detectors._source import is_suppressed, matching_brace
+from desloppify.languages.go.rules import CATEGORIES
+
+CONFIG_KEYS = ("languages", "go", "custom_rules")
+KINDS = ("forbid-import", "forbid-call", "forbid-identifier", "required-call-pairing")
+SEVERITIES = ("low", "medium", "high")
+_REQUIRED_FIELDS = {
+    "forbid-import": ("import",),
+    "forbid-call": ("call",),
+    "forbid-identifier": ("pattern",),
+    "required-call-pairing": ("call", "requires"),
+}
+_COMMON_FIELDS = ("id", "kind", "message", "severity", "categories", "unless_in")
+
+_ID_RE = re.compile(r"^[a-z][a-z0-9_]*$")
+_IDENT = r"[A-Za-z_]\w*"
+_FUNC_SELECTOR_RE = re.compile(rf"^(?P<package>[^\s\"*][^\s\"]*)\.(?P<name>{_IDENT}|\*)$")
+_METHOD_SELECTOR_RE = re.compile(rf"^\*\.(?P<name>{_IDENT})$")
+_IMPORT_SINGLE_RE = re.compile(r'^import[ \t]+(?:([\w.]+)[ \t]+)?"([^"]+)"', re.MULTILINE)
+_IMPORT_BLOCK_RE = re.compile(r"^import[ \t]*\(", re.MULTILINE)
+_IMPORT_SPEC_RE = re.compile(r'^[ \t]*(?:([\w.]+)[ \t]+)?"([^"]+)"', re.MULTILINE)
+_VERSION_SEGMENT_RE = re.compile(r"^v\d+$")
+_MODULE_RE = re.compile(r"^module[ \t]+\"?([^\s\"]+)", re.MULTILINE)
+_FUNC_DECL_RE = re.compile(rf"^func[ \t]*(?:\([^)]*\)[ \t]*)?({_IDENT})", re.MULTILINE)
+_TYPE_DECL_RE = re.compile(rf"^[ \t]*type[ \t]+({_IDENT})", re.MULTILINE)
+_VALUE_DECL_RE = re.compile(
+    rf"^[ \t]*(?:var|const)[ \t]+({_IDENT}(?:[ \t]*,[ \t]*{_IDENT})*)", re.MULTILINE
+)
+_SHORT_DECL_RE = re.compile(rf"(?<![\w.])({_IDENT}(?:[ \t]*,[ \t]*{_IDENT})*)[ \t]*:=")
+_GROUP_DECL_RE = re.compile(r"^[ \t]*(?:var|const|type)[ \t]*\(", re.MULTILINE)
+_GROUP_NAMES_RE = re.compile(rf"[ \t]*({_IDENT}(?:[ \t]*,[ \t]*{_IDENT})*)")
+
+
+@dataclass(frozen=True)
+class CustomRule:
+    """One validated rule from ``custom_rules``."""
+
+    id: str
+    kind: str
+    message: str
+    severity: str = "medium"
+    target: str = ""
+    requires: str = ""
+    unless_in: tuple[str, ...] = ()
+    categories: tuple[str, ...] = ()
+
+
+def parse_custom_rules(raw: object, *, source: Path | None = None) -> list[CustomRule]:
+    """Validate ``custom_rules`` from the config at ``source``.
+
+    Raises ConfigError naming the file, the line and the offending field.
+    """
+    source = source or CONFIG_FILE
+
+    def fail(problem: str, *keys: str | int) -> ConfigError:
+        line = config_line(CONFIG_KEYS + keys, source)
+        where = rel(str(source)) + (f":{line}" if line else "")
+        field = "".join(f"[{k}]" if isinstance(k, int) else f".{k}" for k in keys)
+        return ConfigError(f"{where}: custom_rules{field}: {problem}")
+
+    if raw is None:
+        return []
+    if not isinstance(raw, list):
+        raise fail("expected a list of rule objects")
+    from desloppify.languages.go.detectors.smells import _all_checks
+
+    builtin = {s["id"] for s in _all_checks()}
+    rules: list[CustomRule] = []
+    for index, entry in enumerate(raw):
+        if not isinstance(entry, dict):
+            raise fail("expected an object", index)
+        for key in ("id", "kind", "message"):
+            if not _nonempty(entry, key):
+                raise fail(f"'{key}' is required", index, *_present(entry, key))
+        rule_id, kind = entry["id"], entry["kind"]
+        if not _ID_RE.match(rule_id):
+            raise fail("ids are lower_snake_case", index, "id")
+        if rule_id in builtin or any(r.id == rule_id for r in rules):
+            raise fail(f"'{rule_id}' is already defined", index, "id")
+        if kind not in KINDS:
+            raise fail(f"unknown kind '{kind}' (expected {', '.join(KINDS)})", index, "kind")
+        allowed = _COMMON_FIELDS + _REQUIRED_FIELDS[kind]
+        for key in entry:
+            if key not in allowed:
+                raise fail(f"unknown field for {kind}", index, key)
+        for key in _REQUIRED_FIELDS[kind]:
+            if not _nonempty(entry, key):
+                raise fail(f"'{key}' is required for {kind}", index, *_present(entry, key))
+        severity = entry.get("severity", "medium")
+        if severity not in SEVERITIES:
+            raise fail(f"expected one of {', '.join(SEVERITIES)}", index, "severity")
+        categories = entry.get("categories", [])
+        if not isinstance(categories, list) or not all(c in CATEGORIES for c in categories):
+            raise fail(f"expected a list of {', '.join(CATEGORIES)}", index, "categories")
+        unless_in = entry.get("unless_in", [])
+        if not isinstance(unless_in, list) or not all(isinstance(p, str) for p in unless_in):
+            raise fail("expected a list of import path patterns", index, "unless_in")
+        target = entry.get(_REQUIRED_FIELDS[kind][0], "").strip()
+        requires = entry.get("requires", "").strip()
+        if kind == "forbid-identifier":
+            try:
+                re.compile(target)
+            except re.error as exc:
+                raise fail(f"invalid regex: {exc}", index, "pattern") from None
+        if kind == "forbid-call" and not _FUNC_SELECTOR_RE.match(target):
+            raise fail("expected <import path>.<Func> (e.g. time.Now)", index, "call")
+        if kind == "required-call-pairing":
+            for key, selector in (("call", target), ("requires", requires)):
+                if not (_METHOD_SELECTOR_RE.match(selector) or _FUNC_SELECTOR_RE.match(selector)):
+                    raise fail("expected <import path>.<Func> or *.<Method>", index, key)
+        rules.append(
+            CustomRule(
+                rule_id,
+                kind,
+                entry["message"].strip(),
+                severity,
+                target,
+                requires,
+                tuple(p.strip() for p in unless_in),
+                tuple(categories),
+            )
+        )
+    return rules
+
+
+def _nonempty(entry: dict, key: str) -> bool:
+    return isinstance(entry.get(key), str) and bool(entry[key].strip())
+
+
+def _present(entry: dict, key: str) -> tuple[str, ...]:
+    """``key`` when the entry has it, so errors point at the field, else at the rule."""
+    return (key,) if key in entry else ()
+
+
+def load_custom_rules(path: Path | None = None) -> list[CustomRule]:
+    """The rules in a config file, read as-is (no defaults are written back)."""
+    path = path or CONFIG_FILE
+    try:
+        config = json.loads(path.read_text())
+    except (OSError, UnicodeDecodeError, json.JSONDecodeError):
+        return []
+    raw: object = config
+    for key in CONFIG_KEYS:
+        raw = raw.get(key) if isinstance(raw, dict) else None
+    return parse_custom_rules(raw, source=path)
+
+
+def custom_rule_catalog(rules: list[CustomRule]) -> list[dict]:
+    """Catalog rows for ``langs --rules``, shaped like the smell catalog."""
+    return [
+        {
+            "id": rule.id,
+            "label": rule.message,
+            "severity": rule.severity,
+            "requires": "syntax",
+            "opt_in": False,
+            "fixable": False,
+            "categories": rule.categories,
+            "custom": True,
+        }
+        for rule in rules
+    ]
+
+
+def _pattern_regex(pattern: str) -> re.Pattern[str]:
+    """A go-style pattern: ``...`` matches anything; ``x/...`` also matches ``x``."""
+    body = re.escape(pattern).replace(r"\.\.\.", ".*")
+    if pattern.endswith("/..."):
+        body = body[: -len("/.*")] + "(?:/.*)?"
+    return re.compile(f"^{body}$")
+
+
+@functools.lru_cache(maxsize=1024)
+def package_import_path(directory: str) -> str | None:
+    """Import path of the package in ``directory``, from the enclosing go.mod."""
+    current = Path(directory).resolve()
+    for candidate in (current, *current.parents):
+        go_mod = candidate / "go.mod"
+        if go_mod.is_file():
+            try:
+                match = _MODULE_RE.search(go_mod.read_text(errors="replace"))
+            except OSError:
+                return None
+            if match is None:
+                return None
+            suffix = current.relative_to(candidate).as_posix()
+            return match.group(1) if suffix == "." else f"{match.group(1)}/{suffix}"
+    return None
+
+
+def _in_scope(rule: CustomRule, filepath: str) -> bool:
+    if not rule.unless_in:
+        return True
+    package = package_import_path(os.path.dirname(resolve_path(filepath)))
+    return package is None or not any(_pattern_regex(p).match(package) for p in rule.unless_in)
+
+
+def _imports(source: GoFile) -> list[tuple[str, str, int]]:
+    """(local name, import path, offset) for each import; ``_`` and ``.`` are kept."""
+    found: list[tuple[str, str, int]] = []
+    masked = source.masked
+    for m in _IMPORT_SINGLE_RE.finditer(source.content):
+        if masked.startswith("import", m.start()) and masked[m.start(2) - 1] == '"':
+            found.append((m.group(1) or _default_name(m.group(2)), m.group(2), m.start(2)))
+    for block in _IMPORT_BLOCK_RE.finditer(masked):
+        end = masked.find(")", block.end())
+        end = len(masked) if end == -1 else end
+        for m in _IMPORT_SPEC_RE.finditer(source.content, block.end(), end):
+            if masked[m.start(2) - 1] == '"':
+                found.append(
+                    (m.group(1) or _default_name(m.group(2)), m.group(2), m.start(2))
+                )
+    return found
+
+
+def _default_name(path: str) -> str:
+    segments = path.split("/")
+    name = segments[-1]
+    if _VERSION_SEGMENT_RE.match(name) and len(segments) > 1:
+        name = segments[-2]
+    return re.split(r"[.\-]", name.removeprefix("go-"))[0]
+
+
+def _func_call_re(source: GoFile, selector: str) -> re.Pattern[str] | None:
+    """Calls of ``<import path>.<Func>`` through this file's import name, or None."""
+    m = _FUNC_SELECTOR_RE.match(selector)
+    if m is None:
+        return None
+    names = [local for local, path, _ in _imports(source) if path == m.group("package")]
+    names = [n for n in names if n not in {"_", "."}]
+    if not names:
+        return None
+    func = _IDENT if m.group("name") == "*" else re.escape(m.group("name"))
+    alternatives = "|".join(re.escape(n) for n in names)
+    return re.compile(rf"(?<![\w.])(?:{alternatives})[ \t]*\.[ \t]*{func}[ \t]*\(")
+
+
+def _method_call_re(selector: str, receiver: str | None = None) -> re.Pattern[str]:
+    name = re.escape(selector.removeprefix("*."))
+    recv = re.escape(receiver) if receiver else r"[\w.\[\]()*]*[\w\])]"
+    return re.compile(rf"(?<![\w.])({recv})[ \t]*\.[ \t]*{name}[ \t]*\(")
+
+
+def _check_import(rule: CustomRule, source: GoFile) -> list[tuple[int, str]]:
+    pattern = _pattern_regex(rule.target)
+    return [
+        (source.line_at(offset), path)
+        for _local, path, offset in _imports(source)
+        if pattern.match(path)
+    ]
+
+
+def _check_call(rule: CustomRule, source: GoFile) -> list[tuple[int, str]]:
+    call_re = _func_call_re(source, rule.target)
+    if call_re is None:
+        return []
+    return [(source.line_at(m.start()), rule.target) for m in call_re.finditer(source.masked)]
+
+
+def _declared_names(source: GoFile) -> list[tuple[str, int]]:
+    masked = source.masked
+    found: list[tuple[str, int]] = []
+    for regex in (_FUNC_DECL_RE, _TYPE_DECL_RE, _VALUE_DECL_RE, _SHORT_DECL_RE):
+        for m in regex.finditer(masked):
+            found += _split_names(m.group(1), m.start(1))
+    for group in _GROUP_DECL_RE.finditer(masked):
+        depth, line_start = 0, group.end()
+        for i in range(group.end(), len(masked)):
+            ch = masked[i]
+            if ch in "({[":
+                depth += 1
+            elif ch in ")}]":
+                if depth == 0:
+                    break
+                depth -= 1
+            elif ch == "\n" and depth == 0:
+                line_start = i + 1
+                if m := _GROUP_NAMES_RE.match(masked, line_start):
+                    found += _split_names(m.group(1), m.start(1))
+    return found
+
+
+def _split_names(names: str, offset: int) -> list[tuple[str, int]]:
+    return [
+        (m.group(), offset + m.start())
+        for m in re.finditer(_IDENT, names)
+        if m.group() != "_"
+    ]
+
+
+def _check_identifier(rule: CustomRule, source: GoFile) -> list[tuple[int, str]]:
+    pattern = re.compile(rule.target)
+    seen: set[tuple[int, str]] = set()
+    for name, offset in _declared_names(source):
+        if pattern.search(name):
+            seen.add((source.line_at(offset), name))
+    return sorted(seen)
+
+
+def _check_pairing(rule: CustomRule, source: GoFile) -> list[tuple[int, str]]:
+    masked = source.masked
+    methods = rule.target.startswith("*.") and rule.requires.startswith("*.")
+    if rule.target.startswith("*."):
+        call_re: re.Pattern[str] | None = _method_call_re(rule.target)
+    else:
+        call_re = _func_call_re(source, rule.target)
+    if call_re is None:
+        return []
+    found: list[tuple[int, str]] = []
+    for m in call_re.finditer(masked):
+        blocks = source.enclosing_blocks(m.start())
+        if not blocks:
+            continue
+        body_start = blocks[-1]
+        body_end = matching_brace(masked, body_start) or len(masked)
+        if methods:
+            required_re = _method_call_re(rule.requires, m.group(1))
+        elif rule.requires.startswith("*."):
+            required_re = _method_call_re(rule.requires)
+        else:
+            required_re = _func_call_re(source, rule.requires)
+        if required_re is None or not required_re.search(masked, body_start, body_end):
+            found.append((source.line_at(m.start()), rule.target))
+    return found
+
+
+_CHECKERS = {
+    "forbid-import": _check_import,
+    "forbid-call": _check_call,
+    "forbid-identifier": _check_identifier,
+    "required-call-pairing": _check_pairing,
+}
+
+
+def check_source(rules: list[CustomRule], source: GoFile) -> dict[str, list[dict]]:
+    """Matches of every in-scope rule in one file, by rule id."""
+    found: dict[str, list[dict]] = {}
+    for rule in rules:
+        if not _in_scope(rule, source.path):
+            continue
+        matches = [
+            source.match(line, subject=subject)
+            for line, subject in _CHECKERS[rule.kind](rule, source)
+            if not is_suppressed(source.lines, line, rule.id)
+        ]
+        if matches:
+            found[rule.id] = matches
+    return found
+
+
+def rule_detector(rule: CustomRule) -> FileDetector:
+    """``rule`` as an inspector detector, so it runs on the smells' parse of each file."""
+    checker = _CHECKERS[rule.kind]
+
+    def detect(pass_: Pass) -> None:
+        if _in_scope(rule, pass_.file.path):
+            for line, subject in checker(rule, pass_.file):
+                pass_.report(line, subject=subject)
+
+    return detect
+
+
+__all__ = [
+    "CustomRule",
+    "check_source",
+    "custom_rule_catalog",
+    "load_custom_rules",
+    "parse_custom_rules",
+    "rule_detector",
+]
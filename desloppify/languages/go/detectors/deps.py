@@ -2,6 +2,8 @@
 
 Originally contributed by tinker495 (KyuSeok Jung) in PR #128.
 Go import resolution is not yet implemented — returns an empty graph.
+A builder should list files with ``find_go_files(path, vendored=True)`` so
+vendored packages count as nodes (fan-in); the scan drops findings in them.
 """
 
 from __future__ import annotations
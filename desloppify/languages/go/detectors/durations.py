@@ -0,0 +1,258 @@
+"""Durations written as bare integers, or given their unit twice.
+
+``time.Sleep(5)`` sleeps five nanoseconds: an untyped constant converts
+to ``time.Duration`` silently, and the unit is nanoseconds. Every call
+below that takes a duration is checked, and reported when the argument is
+made only of integer literals (``5``, ``100 * 10``) with no ``time.``
+unit in it. Zero is left alone, since ``time.Sleep(0)`` and a zero timer
+are deliberate. A ``time.Duration`` variable or constant is never
+reported, whatever its value.
+
+``duration_double_unit`` is the opposite mistake: ``timeout *
+time.Second`` where ``timeout`` is already a ``time.Duration`` compiles,
+since a Duration times a Duration is a Duration, and multiplies a
+duration of seconds by a billion. An operand is a Duration when it is
+declared as one (a parameter, variable or struct field of type
+``time.Duration``, or a variable assigned from ``time.Since``,
+``time.ParseDuration`` or ``n * time.Second``), or when ``go/types``
+says so and it is not an untyped constant, which takes the type it is
+multiplied by. ``time.Duration(timeout) * time.Second`` is the same
+mistake and is reported too.
+"""
+
+from __future__ import annotations
+
+import re
+
+from desloppify.languages.go.detectors._inspector import GoFile, Pass
+from desloppify.languages.go.detectors._source import matching_brace
+from desloppify.languages.go.detectors.custom_rules import _imports
+from desloppify.languages.go.detectors.error_flow import _closing_paren
+from desloppify.languages.go.detectors.logic import _split_top_level
+from desloppify.languages.go.detectors.naming import _group_specs
+
+# Index of the duration argument of each call.
+DURATION_CALLS = {
+    "time.Sleep": 0,
+    "time.After": 0,
+    "time.AfterFunc": 0,
+    "time.Tick": 0,
+    "time.NewTicker": 0,
+    "time.NewTimer": 0,
+    "context.WithTimeout": 1,
+    "context.WithTimeoutCause": 1,
+}
+
+_DURATION_CALL_RE = re.compile(
+    r"(?<![\w.])(" + "|".join(re.escape(name) for name in DURATION_CALLS) + r")\("
+)
+_BARE_INT_RE = re.compile(r"[\d_ \t()*+\-/%<>]+")
+_UNIT = r"time\.(?:Nanosecond|Microsecond|Millisecond|Second|Minute|Hour)\b"
+_UNIT_RE = re.compile(rf"(?<![\w.]){_UNIT}")
+_CLOSERS = {")": "(", "]": "[", "}": "{"}
+# `d time.Duration` in a signature, `var` or struct; group 1 is the names.
+_DURATION_DECL_RE = re.compile(r"((?:\w+[ \t]*,[ \t]*)*\w+)[ \t]+time\.Duration\b")
+_DURATION_VALUE_RE = re.compile(
+    rf"(?:time\.(?:Since|Until|ParseDuration|Duration)\(.*"
+    rf"|(?:[\w.]+[ \t]*\*[ \t]*)?{_UNIT}(?:[ \t]*\*[ \t]*[\w.]+)?)$"
+)
+_ASSIGNED_RE = re.compile(
+    r"(?:^|;)[ \t]*(?:var[ \t]+)?(\w+)(?:[ \t]*,[ \t]*[\w.]+)*[ \t]*:?=(?!=)[ \t]*([^\n;]*)",
+    re.MULTILINE,
+)
+_CONVERSION_RE = re.compile(r"time\.Duration\((.*)\)$", re.DOTALL)
+
+
+def detect_bare_duration(pass_: Pass) -> None:
+    """Detect duration arguments written as a bare integer, which Go reads as nanoseconds."""
+    source = pass_.file
+    masked = source.masked
+    for call in _DURATION_CALL_RE.finditer(masked):
+        close = _closing_paren(masked, call.end() - 1)
+        if close is None:
+            continue
+        args = _split_top_level(masked, call.end(), close, ",")
+        index = DURATION_CALLS[call.group(1)]
+        if len(args) <= index:
+            continue
+        arg = masked[args[index][0] : args[index][1]].strip()
+        if not _BARE_INT_RE.fullmatch(arg) or not re.search(r"[1-9]", arg):
+            continue
+        pass_.report(
+            source.line_at(call.start()),
+            function=call.group(1),
+            duration=arg,
+            hint="a bare integer is nanoseconds; multiply by a unit such as time.Second",
+        )
+
+
+def _operand_before(masked: str, end: int) -> int:
+    """Start of the operand (name, selector, call, index) ending at ``end``."""
+    i = end
+    while i > 0:
+        ch = masked[i - 1]
+        if ch in _CLOSERS:
+            depth = 0
+            j = i - 1
+            while j >= 0:
+                if masked[j] == ch:
+                    depth += 1
+                elif masked[j] == _CLOSERS[ch]:
+                    depth -= 1
+                    if depth == 0:
+                        break
+                j -= 1
+            if j < 0:
+                break
+            i = j
+        elif ch.isalnum() or ch in "_.":
+            i -= 1
+        else:
+            break
+    return i
+
+
+def _operand_after(masked: str, start: int) -> int:
+    """End of the operand (name, selector, call, index) starting at ``start``."""
+    i = start
+    while i < len(masked):
+        ch = masked[i]
+        if ch == "{" and masked.startswith("{}", i):
+            i += 2
+        elif ch in "([":
+            close = _closing_paren(masked, i) if ch == "(" else matching_brace(masked, i)
+            if close is None:
+                break
+            i = close + 1
+        elif ch.isalnum() or ch in "_.":
+            i += 1
+        else:
+            break
+    return i
+
+
+def _duration_fields(files: tuple[GoFile, ...]) -> set[str]:
+    """Names declared ``time.Duration`` at the start of a line: struct fields and specs."""
+    line_decl = re.compile(rf"^[ \t]*{_DURATION_DECL_RE.pattern}", re.MULTILINE)
+    return {
+        name
+        for file in files
+        for m in line_decl.finditer(file.masked)
+        for name in re.findall(r"\w+", m.group(1))
+    }
+
+
+def _constants(source: GoFile) -> set[str]:
+    """Names declared by ``const`` in ``source``."""
+    masked = source.masked
+    names: set[str] = set()
+    for m in re.finditer(r"\bconst[ \t]+(?:(\()|((?:\w+[ \t]*,[ \t]*)*\w+))", masked):
+        specs = _group_specs(masked, m.start(1)) if m.group(1) else [(m.start(2), m.group(2))]
+        names.update(name for _, group in specs for name in re.findall(r"\w+", group))
+    return names
+
+
+def _top_level_durations(source: GoFile) -> set[str]:
+    """Package-level ``var`` and ``const`` names of ``source`` that are Durations."""
+    masked = source.masked
+    names: set[str] = set()
+    for m in re.finditer(
+        r"^(?:var|const)[ \t]+(?:(\()|((?:\w+[ \t]*,[ \t]*)*\w+))", masked, re.MULTILINE
+    ):
+        specs = _group_specs(masked, m.start(1)) if m.group(1) else [(m.start(2), m.group(2))]
+        for offset, group in specs:
+            line_end = masked.find("\n", offset)
+            rest = masked[offset + len(group) : line_end if line_end != -1 else None].strip()
+            if rest.startswith("time.Duration") or (
+                rest.startswith("=") and _DURATION_VALUE_RE.match(rest[1:].strip())
+            ):
+                names.update(re.findall(r"\w+", group))
+    return names
+
+
+def _declared_duration(source: GoFile, name: str, pos: int) -> bool:
+    """Whether ``name`` is declared a Duration before ``pos`` in its function or at top level."""
+    masked = source.masked
+    enclosing = source.enclosing_blocks(pos)
+    if enclosing:
+        region = masked[max(masked.rfind("func", 0, enclosing[-1]), 0) : pos]
+        for m in _DURATION_DECL_RE.finditer(region):
+            if name in re.findall(r"\w+", m.group(1)):
+                return True
+        for m in _ASSIGNED_RE.finditer(region):
+            if m.group(1) == name and _DURATION_VALUE_RE.match(m.group(2).strip()):
+                return True
+    return name in source.memo("durations:top_level", lambda: _top_level_durations(source))
+
+
+def _is_duration(pass_: Pass, start: int, end: int) -> bool:
+    source = pass_.file
+    text = source.masked[start:end]
+    if not text or text[0].isdigit():
+        return False
+    if re.match(r"time\.(?:Since|Until)\(", text):
+        return True
+    type_ = pass_.type_of(start, end)
+    if type_ is not None and type_ != "time.Duration":
+        return False
+    head, _, field = text.rpartition(".")
+    if not head:
+        if _declared_duration(source, text, start):
+            return True
+    elif re.fullmatch(r"\w+(?:\.\w+)*", head):
+        files = pass_.types.files if pass_.types is not None else (source,)
+        fields = (
+            pass_.types.memo("durations:fields", lambda: _duration_fields(files))
+            if pass_.types is not None
+            else _duration_fields(files)
+        )
+        if field in fields:
+            return True
+    if type_ is None:
+        return False
+    # go/types gives an untyped constant the type it is multiplied by.
+    if not head:
+        return text not in source.memo("durations:constants", lambda: _constants(source))
+    packages = {name or path.rsplit("/", 1)[-1] for name, path, _ in _imports(source)}
+    return head not in packages
+
+
+def detect_duration_double_unit(pass_: Pass) -> None:
+    """Detect a ``time.Duration`` multiplied by a unit such as ``time.Second``.
+
+    Reported with the ``duration`` operand and the ``unit``.
+    """
+    source = pass_.file
+    masked = source.masked
+    for unit in _UNIT_RE.finditer(masked):
+        before = re.search(r"\*[ \t]*$", masked[max(0, unit.start() - 8) : unit.start()])
+        after = re.match(r"[ \t]*\*(?!=)[ \t]*", masked[unit.end() :])
+        if before is not None:
+            end = unit.start() - len(before.group())
+            end = len(masked[:end].rstrip())
+            start = _operand_before(masked, end)
+        elif after is not None:
+            start = unit.end() + after.end()
+            end = _operand_after(masked, start)
+        else:
+            continue
+        operand = masked[start:end]
+        conversion = _CONVERSION_RE.match(operand)
+        if conversion is not None:
+            value = conversion.group(1)
+            inner = start + conversion.start(1) + len(value) - len(value.lstrip())
+            if not _is_duration(pass_, inner, start + conversion.start(1) + len(value.rstrip())):
+                continue
+        elif not _is_duration(pass_, start, end):
+            continue
+        written = source.content[start:end]
+        pass_.report(
+            source.line_at(unit.start()),
+            duration=written,
+            unit=unit.group(),
+            hint=f"{written} is already a time.Duration, so multiplying it by "
+            f"{unit.group()} scales it again; use it as it is",
+        )
+
+
+__all__ = ["DURATION_CALLS", "detect_bare_duration", "detect_duration_double_unit"]
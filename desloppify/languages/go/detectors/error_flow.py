@@ -0,0 +1,881 @@
+"""Go error-flow smells: error results that are always nil (in one file, or
+package-wide with call sites and interface checks), come with a value or
+come before other results, errors checked only to be logged, panics that
+carry a string where an error belongs, ``fmt.Errorf`` calls that format an
+error without ``%w`` or use several ``%w`` before Go 1.20, deferred calls
+whose error nobody sees, deferred calls on a value before its error is
+checked, and ``errors.As`` targets that are not pointers."""
+
+from __future__ import annotations
+
+import re
+
+from desloppify.languages.go.detectors._inspector import GoFile, Pass, visits
+from desloppify.languages.go.detectors._source import matching_brace
+from desloppify.languages.go.detectors.logic import _split_top_level, _statement_end
+
+# Plain functions only: a method's signature is usually fixed by the
+# interface it implements (io.Writer, driver.Valuer, ...).
+_FUNC_DECL_RE = re.compile(r"^func[ \t]+(\w+)[ \t]*(?:\[[^\]\n]*\])?\(", re.MULTILINE)
+# Functions and methods: group 1 is the receiver list, group 2 the name.
+_DECL_RE = re.compile(
+    r"^func[ \t]*(?:\(([^)\n]*)\)[ \t]*)?(\w+)[ \t]*(?:\[[^\]\n]*\])?\(", re.MULTILINE
+)
+_TODO_RE = re.compile(r"\b(?:TODO|FIXME)\b")
+_DECL_PREFIX_RE = re.compile(r"func[ \t]*(?:\([^)\n]*\)[ \t]*)?$")
+# `err := `, `v, err = x.` before a call: group 1 is the last name bound.
+_BINDING_RE = re.compile(r"([\w.]+)[ \t]*:?=[ \t]*[\w.]*$")
+_FUNC_LITERAL_RE = re.compile(r"\bfunc[ \t]*\(")
+_RETURN_RE = re.compile(r"\breturn\b")
+_NAMED_ERROR_RE = re.compile(r"^(\w+)[ \t]+error$")
+_MAIN_PACKAGE_RE = re.compile(r"^package[ \t]+main\b", re.MULTILINE)
+_ASSIGNMENT_RE = re.compile(r"(?<![=!<>:])(?::=|=)(?!=)")
+# Per-platform variants must keep the signature of their counterparts.
+_BUILD_CONSTRAINT_RE = re.compile(r"^//(?:go:build|[ \t]*\+build)\b", re.MULTILINE)
+_GOOS = (
+    "aix|android|darwin|dragonfly|freebsd|hurd|illumos|ios|js|linux|nacl|netbsd"
+    "|openbsd|plan9|solaris|wasip1|windows|zos"
+)
+_GOARCH = (
+    "386|amd64|arm|arm64|loong64|mips|mipsle|mips64|mips64le|ppc64|ppc64le"
+    "|riscv64|s390x|wasm"
+)
+_PLATFORM_FILE_RE = re.compile(rf"_(?:(?:{_GOOS})(?:_(?:{_GOARCH}))?|{_GOARCH})\.go$")
+
+
+def _closing_paren(masked: str, open_paren: int) -> int | None:
+    depth = 0
+    for i in range(open_paren, len(masked)):
+        if masked[i] == "(":
+            depth += 1
+        elif masked[i] == ")":
+            depth -= 1
+            if depth == 0:
+                return i
+    return None
+
+
+def _body_brace(masked: str, start: int) -> int | None:
+    """Offset of the body ``{`` after a signature, or None for a bodyless decl."""
+    depth = 0
+    for i in range(start, len(masked)):
+        ch = masked[i]
+        if ch in "([":
+            depth += 1
+        elif ch in ")]":
+            depth -= 1
+        elif depth == 0 and ch == "{" and masked[i - 1] in " \t":
+            return i
+        elif depth == 0 and ch == "\n":
+            return None
+    return None
+
+
+def _error_result(masked: str, start: int, end: int) -> tuple[int, str | None] | None:
+    """(result count, error result name) when the last result is ``error``."""
+    results = masked[start:end].strip()
+    if results == "error":
+        return 1, None
+    if not (results.startswith("(") and results.endswith(")")):
+        return None
+    inner_start = masked.index("(", start) + 1
+    inner_end = masked.rindex(")", start, end)
+    items = [masked[a:b].strip() for a, b in _split_top_level(masked, inner_start, inner_end, ",")]
+    last = items[-1]
+    if last == "error":
+        return len(items), None
+    named = _NAMED_ERROR_RE.match(last)
+    return (len(items), named.group(1)) if named else None
+
+
+def _closure_spans(masked: str, start: int, end: int) -> list[tuple[int, int]]:
+    spans: list[tuple[int, int]] = []
+    for m in _FUNC_LITERAL_RE.finditer(masked, start, end):
+        signature_end = _closing_paren(masked, m.end() - 1)
+        brace = _body_brace(masked, signature_end + 1) if signature_end else None
+        close = matching_brace(masked, brace) if brace is not None else None
+        if close is not None:
+            spans.append((brace, close))
+    return spans
+
+
+def _assigns(masked: str, start: int, end: int, name: str) -> bool:
+    """Whether ``name`` is assigned (or has its address taken) in the span."""
+    name_re = re.compile(rf"(?<![\w.])&?{re.escape(name)}\b")
+    for line in masked[start:end].splitlines():
+        if f"&{name}" in line and re.search(rf"&{re.escape(name)}\b", line):
+            return True
+        assignment = _ASSIGNMENT_RE.search(line)
+        if assignment and name_re.search(line, 0, assignment.start()):
+            return True
+    return False
+
+
+def _error_is_always_nil(
+    masked: str, body_start: int, body_end: int, results: int, name: str | None
+) -> bool:
+    if name is not None and _assigns(masked, body_start, body_end, name):
+        return False  # set somewhere, possibly by a deferred closure
+    closures = _closure_spans(masked, body_start, body_end)
+    returns = 0
+    for m in _RETURN_RE.finditer(masked, body_start, body_end):
+        if any(a < m.start() < b for a, b in closures):
+            continue
+        returns += 1
+        end = _statement_end(masked, m.end())
+        values = _split_top_level(masked, m.end(), end, ",")
+        if not masked[m.end() : end].strip():
+            if name is None:
+                return False
+            continue
+        if len(values) != results:
+            return False  # `return f()` forwards a multi-value call
+        last = masked[values[-1][0] : values[-1][1]].strip()
+        if last not in {"nil", name}:
+            return False
+    return returns > 0
+
+
+def _used_as_value(masked: str, name: str, decl: int) -> bool:
+    """Whether ``name`` is referenced other than by a call (``Run: run``).
+
+    A function passed as a value must match the func type it is assigned
+    to, so its error result is not the function's own choice.
+    """
+    for m in re.finditer(rf"(?<![\w.]){re.escape(name)}\b(?![ \t]*[(\[])", masked):
+        if m.start() != decl:
+            return True
+    return False
+
+
+def _skips_file(source: GoFile) -> bool:
+    return bool(
+        _PLATFORM_FILE_RE.search(source.path) or _BUILD_CONSTRAINT_RE.search(source.content)
+    )
+
+
+@visits("func")
+def visit_useless_error_return(pass_: Pass, kind: str, offset: int) -> None:
+    """Detect functions declared to return ``error`` that only ever return nil.
+
+    With the ``functions`` option at ``unexported``, exported functions are
+    left alone: their signature may be reserving the error for later.
+    """
+    source = pass_.file
+    masked = source.masked
+    m = _FUNC_DECL_RE.match(masked, offset)
+    if m is None or source.memo("error_flow.skip", lambda: _skips_file(source)):
+        return
+    if pass_.options["functions"] == "unexported" and m.group(1)[:1].isupper():
+        return
+    is_main = source.memo("package_main", lambda: bool(_MAIN_PACKAGE_RE.search(masked)))
+    if is_main and m.group(1) == "run":
+        return  # `func main() { if err := run(); ... }` entry-point idiom
+    params_end = _closing_paren(masked, m.end() - 1)
+    if params_end is None:
+        return
+    brace = _body_brace(masked, params_end + 1)
+    if brace is None:
+        return
+    result = _error_result(masked, params_end + 1, brace)
+    close = matching_brace(masked, brace)
+    if result is None or close is None:
+        return
+    if not _error_is_always_nil(masked, brace + 1, close, *result):
+        return
+    if _used_as_value(masked, m.group(1), m.start(1)):
+        return
+    pass_.report(source.line_at(offset), function=m.group(1))
+
+
+def detect_always_nil_error(pass_: Pass) -> None:
+    """Detect functions and methods whose error result is nil on every path.
+
+    Package-wide and type-checked: a method is reported only when no
+    interface from another package requires it, and each finding counts the
+    package's ``calls`` and how many bind the error to check it
+    (``checked_calls``). Functions with a TODO or FIXME in the body, used as
+    values, or in platform-specific files are left alone.
+    """
+    source = pass_.file
+    masked = source.masked
+    info = pass_.types_info
+    if pass_.types is None or source.memo("error_flow.skip", lambda: _skips_file(source)):
+        return
+    is_main = source.memo("package_main", lambda: bool(_MAIN_PACKAGE_RE.search(masked)))
+    for m in _DECL_RE.finditer(masked):
+        receiver, name = m.group(1), m.group(2)
+        if is_main and name == "run" and receiver is None:
+            continue
+        params_end = _closing_paren(masked, m.end() - 1)
+        brace = _body_brace(masked, params_end + 1) if params_end is not None else None
+        close = matching_brace(masked, brace) if brace is not None else None
+        if close is None:
+            continue
+        result = _error_result(masked, params_end + 1, brace)
+        if result is None or not _error_is_always_nil(masked, brace + 1, close, *result):
+            continue
+        if _TODO_RE.search(source.content, brace, close):
+            continue
+        if receiver is not None and (
+            "[" in receiver
+            or info is None
+            or not info.spans.get(source.path)
+            or info.interfaces_of(source.path, m.start(2))
+        ):
+            continue  # a generic receiver, or no types to rule interfaces out
+        sites = _call_sites(pass_.types.files, name, method=receiver is not None)
+        if sites is None:
+            continue  # used as a value, so its signature may be required
+        calls, checked = sites
+        label = f"{_receiver_type(receiver)}.{name}" if receiver else name
+        pass_.report(
+            source.line_at(m.start()),
+            function=label,
+            calls=calls,
+            checked_calls=checked,
+            hint=f"{label} never returns a non-nil error; drop the error result"
+            + (f" and the checks at {checked} call site{'s' * (checked > 1)}" if checked else ""),
+        )
+
+
+def _receiver_type(receiver: str) -> str:
+    return re.findall(r"\w+", receiver)[-1]
+
+
+def _call_sites(files: tuple[GoFile, ...], name: str, *, method: bool) -> tuple[int, int] | None:
+    """(calls, calls that bind the error) of ``name`` across ``files``.
+
+    None when ``name`` is used as a value (``Run: run``, ``x.Close``): a
+    func value's signature is dictated by whatever it is assigned to.
+    """
+    prefix = r"\." if method else r"(?<![\w.])"
+    use_re = re.compile(rf"{prefix}{re.escape(name)}\b")
+    calls = checked = 0
+    for file in files:
+        masked = file.masked
+        for m in use_re.finditer(masked):
+            line_start = file.line_start(m.start())
+            if _DECL_PREFIX_RE.match(masked, line_start, m.start()):
+                continue
+            if masked[m.end() : m.end() + 1] not in ("(", "["):
+                return None
+            calls += 1
+            binding = _BINDING_RE.search(masked, line_start, m.start())
+            if binding is not None and binding.group(1) != "_":
+                checked += 1
+    return calls, checked
+
+
+# Values a caller can safely treat as "no result", plus the -1 sentinel.
+_ZERO_VALUE_RE = re.compile(
+    r'(?:nil|false|-1|0|0\.0*|""|``|[\w.\[\]*]+\{\s*\}|\*new\(.*\))$'
+)
+_ZERO_VAR_RE = r"^[ \t]*var[ \t]+{name}[ \t]+[^=\n]+$"
+_NEW_ERROR_RE = re.compile(r"(?:errors\.New|fmt\.Errorf)\(")
+_IF_HEAD_RE = re.compile(r"[ \t]*(?:\}[ \t]*else[ \t]+)?if\b(.*)$")
+_FUNC_HEAD_RE = re.compile(
+    r"(?<![\w.])func\b[ \t]*(?:\([^()]*\)[ \t]*)?(\w+)?[ \t]*(?:\[[^\]\n]*\])?\("
+)
+_ERROR_LAST_RE = re.compile(r"\berror\)?$")
+# io contracts: a Read/Write that fails part-way still reports the bytes done.
+_PARTIAL_RESULT_FUNCS = frozenset(
+    {"Read", "ReadAt", "ReadFrom", "Write", "WriteAt", "WriteString", "WriteTo"}
+)
+
+
+def _func_bodies(masked: str) -> dict[int, tuple[str | None, str]]:
+    """Body ``{`` offset -> (function name or None for a literal, result list)."""
+    bodies: dict[int, tuple[str | None, str]] = {}
+    for m in _FUNC_HEAD_RE.finditer(masked):
+        params_end = _closing_paren(masked, m.end() - 1)
+        brace = _body_brace(masked, params_end + 1) if params_end is not None else None
+        if brace is not None:
+            bodies[brace] = (m.group(1), masked[params_end + 1 : brace].strip())
+    return bodies
+
+
+def _known_non_nil(source: GoFile, offset: int, err: str) -> bool:
+    """Whether ``err`` is an error result that is non-nil at ``offset``.
+
+    It is when freshly built, or when an enclosing ``if`` checked
+    ``err != nil``; the search stops at the enclosing function.
+    """
+    bodies = source.memo("error_flow.bodies", lambda: _func_bodies(source.masked))
+    guard = (
+        re.compile(rf"(?<![\w.]){re.escape(err)}[ \t]*!=[ \t]*nil\b")
+        if re.fullmatch(r"\w+", err)
+        else None
+    )
+    guarded = bool(_NEW_ERROR_RE.match(err))
+    for brace in source.enclosing_blocks(offset):
+        if brace in bodies:
+            name, results = bodies[brace]
+            return (
+                guarded
+                and bool(_ERROR_LAST_RE.search(results))
+                and name not in _PARTIAL_RESULT_FUNCS
+            )
+        head = _IF_HEAD_RE.match(source.masked[source.line_start(brace) : brace])
+        if guarded or guard is None or head is None:
+            continue
+        condition = head.group(1).rsplit(";", 1)[-1]
+        guarded = "||" not in condition and bool(guard.search(condition))
+    return False
+
+
+def _is_zero_var(masked: str, value: str) -> bool:
+    """Whether ``value`` names a ``var zero T`` declared without an initializer."""
+    if not re.fullmatch(r"\w+", value):
+        return False
+    return bool(re.search(_ZERO_VAR_RE.format(name=value), masked, re.MULTILINE))
+
+
+@visits("return")
+def visit_value_with_error(pass_: Pass, kind: str, offset: int) -> None:
+    """Detect ``return value, err`` where err is non-nil and value is not a zero value.
+
+    Partial-result APIs (``io.Reader``'s ``n, err``) are legitimate and can
+    be silenced with ``//desloppify:ignore value_with_error``.
+    """
+    source = pass_.file
+    masked = source.masked
+    start = offset + len(kind)
+    end = _statement_end(masked, start)
+    values = [
+        source.content[a:b].strip() for a, b in _split_top_level(masked, start, end, ",")
+    ]
+    if len(values) < 2:
+        return
+    *results, err = values
+    meaningful = [
+        v
+        for v in results
+        if not _ZERO_VALUE_RE.match(v) and not _is_zero_var(source.masked, v)
+    ]
+    if not meaningful or not _known_non_nil(source, offset, err):
+        return
+    pass_.report(source.line_at(offset), value=meaningful[0])
+
+
+_ERROR_ITEM_RE = re.compile(r"^(?:\w+[ \t]+)?error$")
+
+
+def detect_error_not_last(pass_: Pass) -> None:
+    """Detect declared functions and methods with an ``error`` result before another.
+
+    Reported with the ``function`` and the 1-based ``position`` of the error
+    among its ``results``.
+    """
+    source = pass_.file
+    masked = source.masked
+    for m in _DECL_RE.finditer(masked):
+        close = _closing_paren(masked, m.end() - 1)
+        if close is None:
+            continue
+        brace = _body_brace(masked, close + 1)
+        end = brace if brace is not None else masked.find("\n", close)
+        results = masked[close + 1 : end if end != -1 else len(masked)].strip()
+        if not results.startswith("("):
+            continue
+        inner = masked.index("(", close + 1)
+        result_close = _closing_paren(masked, inner)
+        if result_close is None:
+            continue
+        items = [
+            " ".join(masked[a:b].split())
+            for a, b in _split_top_level(masked, inner + 1, result_close, ",")
+        ]
+        positions = [i for i, item in enumerate(items) if _ERROR_ITEM_RE.match(item)]
+        if not positions or positions[-1] == len(items) - 1:
+            continue
+        position = positions[0]
+        reordered = items[:position] + items[position + 1 :] + items[position : position + 1]
+        pass_.report(
+            source.line_at(m.start()),
+            function=m.group(2),
+            position=position + 1,
+            results=len(items),
+            hint=f"return ({', '.join(reordered)}); callers expect the error last",
+        )
+
+
+# Masking keeps the quotes, so a literal argument still starts with one.
+_PANIC_STRING_RE = re.compile(r'(?<![\w.])panic\(\s*(?:(["`])|fmt\.Sprintf\()')
+
+
+def detect_panic_string(pass_: Pass) -> None:
+    """Detect ``panic`` called with a string literal or ``fmt.Sprintf`` result."""
+    source = pass_.file
+    for m in _PANIC_STRING_RE.finditer(source.masked):
+        suggestion = "panic(errors.New(...))" if m.group(1) else "panic(fmt.Errorf(...))"
+        pass_.report(source.line_at(m.start()), suggestion=suggestion)
+
+
+_ERRORF_RE = re.compile(r"(?<![\w.])fmt\.Errorf\(")
+# %[flags][width][.precision]verb; explicit indexes and ``*`` widths are rare
+# enough that calls using them are left alone.
+_FORMAT_VERB_RE = re.compile(r"%(?:%|([-+# 0]*\d*(?:\.\d*)?)([a-zA-Z])|[^%]*?[\[*])")
+_ERROR_NAME_RE = re.compile(r"(?:err|\w+Err)$")
+
+
+def _format_verbs(literal: str) -> list[str] | None:
+    """Verbs in a format string, one per operand; None if operands can't be mapped."""
+    verbs: list[str] = []
+    for m in _FORMAT_VERB_RE.finditer(literal):
+        if m.group(0) == "%%":
+            continue
+        if m.group(2) is None:
+            return None
+        verbs.append(m.group(2))
+    return verbs
+
+
+def _is_error_value(masked: str, arg: str) -> bool:
+    """Whether ``arg`` names an error: ``err``/``xErr``, or declared as one in the file."""
+    name = arg.rsplit(".", 1)[-1]
+    if not re.fullmatch(r"[\w.]+", arg):
+        return False
+    if _ERROR_NAME_RE.fullmatch(name):
+        return True
+    word = re.escape(name)
+    return bool(
+        re.search(rf"(?<![\w.]){word}[ \t]+error\b", masked)
+        or re.search(
+            rf"(?<![\w.]){word}[ \t]*:?=[ \t]*(?:errors\.New|fmt\.Errorf)\(", masked
+        )
+    )
+
+
+def _errorf_calls(
+    masked: str, content: str
+) -> list[tuple[int, list[str], list[str], int]]:
+    """(offset, verbs, operands, format offset) of each ``fmt.Errorf`` with a literal, mappable format."""
+    calls: list[tuple[int, list[str], list[str], int]] = []
+    for m in _ERRORF_RE.finditer(masked):
+        close = _closing_paren(masked, m.end() - 1)
+        if close is None:
+            continue
+        spans = _split_top_level(masked, m.end(), close, ",")
+        if len(spans) < 2:
+            continue
+        raw = content[spans[0][0] : spans[0][1]]
+        first = raw.strip()
+        if len(first) < 2 or first[0] not in "\"`" or first[-1] != first[0]:
+            continue
+        verbs = _format_verbs(first[1:-1])
+        if verbs is not None:
+            calls.append(
+                (
+                    m.start(),
+                    verbs,
+                    [content[a:b].strip() for a, b in spans[1:]],
+                    spans[0][0] + len(raw) - len(raw.lstrip()),
+                )
+            )
+    return calls
+
+
+def _source_errorf_calls(
+    source: GoFile,
+) -> list[tuple[int, list[str], list[str], int]]:
+    return source.memo(
+        "error_flow.errorf", lambda: _errorf_calls(source.masked, source.content)
+    )
+
+
+# %w arrived in Go 1.13; before that fmt.Errorf printed it as %!w.
+_WRAP_SINCE = (1, 13)
+
+
+def _wrap_fix(source: GoFile, format_start: int, index: int) -> dict | None:
+    """Edit rewriting the ``index``-th verb of the format literal to ``%w``.
+
+    Only a bare ``%v``/``%s`` in a one-line interpreted literal is rewritten;
+    ``%w`` takes no flags, width or precision.
+    """
+    close = source.masked.find('"', format_start + 1)
+    if (
+        source.content[format_start] != '"'
+        or close < 0
+        or "\n" in source.content[format_start:close]
+        or (source.go_version or _WRAP_SINCE) < _WRAP_SINCE
+    ):
+        return None
+    literal = source.content[format_start : close + 1]
+    verbs = [
+        m
+        for m in _FORMAT_VERB_RE.finditer(literal, 1, len(literal) - 1)
+        if m.group(0) != "%%"
+    ]
+    verb = verbs[index]
+    if verb.group(1):
+        return None
+    new = literal[: verb.start(2)] + "w" + literal[verb.end(2) :]
+    return {
+        "title": f"Wrap with {new}",
+        "line": source.line_at(format_start),
+        "old": literal,
+        "new": new,
+    }
+
+
+def detect_error_not_wrapped(pass_: Pass) -> None:
+    """Detect ``fmt.Errorf`` formatting an error with ``%v``/``%s`` instead of ``%w``.
+
+    When that is the call's only error operand and it has no ``%w`` yet, the
+    match carries a fix switching the verb to ``%w``. The ``verbs`` option
+    narrows which verbs count.
+    """
+    source = pass_.file
+    flagged = pass_.options["verbs"]
+    for offset, verbs, args, format_start in _source_errorf_calls(source):
+        errors = [
+            i
+            for i, (verb, arg) in enumerate(zip(verbs, args))
+            if verb in flagged and _is_error_value(source.masked, arg)
+        ]
+        if not errors:
+            continue
+        index = errors[0]
+        entry = pass_.report(
+            source.line_at(offset), verb=f"%{verbs[index]}", arg=args[index]
+        )
+        if len(errors) == 1 and "w" not in verbs:
+            fix = _wrap_fix(source, format_start, index)
+            if fix is not None:
+                entry["fix"] = fix
+
+
+# fmt.Errorf accepts more than one %w from Go 1.20; before that vet rejects
+# it and fmt prints %!w for all but the first.
+_MULTI_WRAP_SINCE = (1, 20)
+
+
+def detect_multiple_wrap_verbs(pass_: Pass) -> None:
+    """Detect ``fmt.Errorf`` with several ``%w`` in a module older than Go 1.20.
+
+    Files outside any module (no ``go.mod``, so no known version) are
+    reported too, since the call is only legal from 1.20 on.
+    """
+    source = pass_.file
+    calls = [c for c in _source_errorf_calls(source) if c[1].count("w") > 1]
+    if not calls or (source.go_version or (0, 0)) >= _MULTI_WRAP_SINCE:
+        return
+    version = ".".join(map(str, source.go_version)) if source.go_version else "unknown"
+    for offset, verbs, _args, _format in calls:
+        pass_.report(source.line_at(offset), wraps=verbs.count("w"), go_version=version)
+
+
+_DEFER_CALL_RE = re.compile(r"^[ \t]*defer[ \t]+((?:\w+\.)*\w+)\.(\w+)\(", re.MULTILINE)
+_DEFER_FUNC_RE = re.compile(r"^[ \t]*defer[ \t]+func[ \t]*\(\)[ \t]*\{", re.MULTILINE)
+_BARE_CALL_RE = re.compile(r"^[ \t]*((?:\w+\.)*\w+)\.(\w+)\(", re.MULTILINE)
+# `os.Create(`, `os.OpenFile(name, os.O_WRONLY…`, `bufio.NewWriter(`, `gzip.NewWriterLevel(`
+_WRITER_SOURCE = (
+    r"(?:os\.Create(?:Temp)?\(|os\.OpenFile\([^\n]*\bos\.O_(?:WRONLY|RDWR|APPEND|CREATE)\b"
+    r"|[\w.]*\bNew\w*Writer\w*\()"
+)
+
+
+def _opened_for_writing(masked: str, start: int, end: int, receiver: str) -> bool:
+    """Whether ``receiver`` is assigned a file or writer for writing in the span."""
+    opened = re.compile(
+        rf"(?<![\w.]){re.escape(receiver)}\s*(?:,\s*\w+\s*)?:?=\s*{_WRITER_SOURCE}"
+    )
+    return bool(opened.search(masked, start, end))
+
+
+def detect_deferred_error_ignored(pass_: Pass) -> None:
+    """Detect deferred calls that drop an error worth checking.
+
+    ``defer tx.Commit()`` and ``defer w.Flush()`` throw away the one error
+    that says whether the work happened. Reported are bare deferred calls,
+    and bare calls inside a deferred closure, to a method in the ``methods``
+    option, or in ``writer_methods`` when the function opened the receiver
+    for writing (``os.Create``, ``os.OpenFile`` with a write flag, a
+    ``New*Writer``): closing a file that was only read may drop its error. An
+    error assigned to anything, even ``_``, has been looked at.
+    """
+    source = pass_.file
+    masked = source.masked
+    methods = set(pass_.options["methods"])
+    writer_methods = set(pass_.options["writer_methods"])
+    calls = [(m.start(), m.group(1), m.group(2)) for m in _DEFER_CALL_RE.finditer(masked)]
+    for m in _DEFER_FUNC_RE.finditer(masked):
+        close = matching_brace(masked, m.end() - 1)
+        if close is not None:
+            calls.extend(
+                (call.start(), call.group(1), call.group(2))
+                for call in _BARE_CALL_RE.finditer(masked, m.end(), close)
+            )
+    for offset, receiver, method in sorted(calls):
+        if method not in methods:
+            enclosing = source.enclosing_blocks(offset)
+            if method not in writer_methods or not enclosing:
+                continue
+            body = enclosing[-1]
+            end = matching_brace(masked, body) or len(masked)
+            if not _opened_for_writing(masked, body, end, receiver):
+                continue
+        call = f"{receiver}.{method}"
+        pass_.report(
+            source.line_at(offset),
+            call=call,
+            hint=f"defer func() {{ if cerr := {call}(); cerr != nil && err == nil "
+            "{ err = cerr } }() with a named err result",
+        )
+
+
+_ERRORS_AS_RE = re.compile(r"(?<![\w.])errors\.As\(")
+_COMPOSITE_RE = re.compile(r"(?:\w+\.)?\w+[ \t]*\{")
+
+
+def _declared_pointer(
+    source: GoFile, start: int, end: int, name: str, *, top_level: bool = False
+) -> bool | None:
+    """Whether the last declaration of ``name`` in ``start``..``end`` makes it a pointer.
+
+    None when there is none, or when its type cannot be read off the source.
+    """
+    masked = source.masked
+    word = re.escape(name)
+    found: list[tuple[int, bool | None]] = []
+    for m in re.finditer(
+        rf"(?<![\w.]){word}(?:[ \t]*,[ \t]*\w+)*[ \t]+(\*|[\w.\[\]]+)", masked[start:end]
+    ):
+        before = masked[: start + m.start()].rstrip()
+        if before.endswith(("var", "(", ",")) and m.group(1) not in ("chan", "func"):
+            found.append((start + m.start(), m.group(1) == "*"))  # `var x T`, `(x *T)`
+    for m in re.finditer(rf"(?<![\w.]){word}[ \t]*:?=(?!=)[ \t]*", masked[start:end]):
+        value = masked[start + m.end() : end]
+        if value.startswith(("&", "new(")):
+            found.append((start + m.start(), True))
+        elif value.startswith("nil") or _COMPOSITE_RE.match(value):
+            found.append((start + m.start(), False))
+        else:
+            found.append((start + m.start(), None))
+    if top_level:
+        found = [f for f in found if not source.enclosing_blocks(f[0])]
+    return max(found)[1] if found else None
+
+
+def detect_errors_as_target(pass_: Pass) -> None:
+    """Detect ``errors.As`` whose target is not a pointer, which panics at run time.
+
+    The target must be ``&x`` or a pointer. Reported when it is ``nil``, a
+    composite literal, or a name whose last declaration before the call
+    (in the function, its parameters or at package level) gives it a
+    non-pointer type or value. Names of unknown type are left alone.
+    """
+    source = pass_.file
+    masked = source.masked
+    for call in _ERRORS_AS_RE.finditer(masked):
+        close = _closing_paren(masked, call.end() - 1)
+        args = _split_top_level(masked, call.end(), close, ",") if close is not None else []
+        if len(args) != 2:
+            continue
+        target = masked[args[1][0] : args[1][1]].strip()
+        if target == "nil" or _COMPOSITE_RE.match(target):
+            pointer = False
+        elif re.fullmatch(r"\w+", target):
+            enclosing = source.enclosing_blocks(call.start())
+            func = masked.rfind("func", 0, enclosing[-1]) if enclosing else 0
+            pointer = _declared_pointer(source, max(func, 0), call.start(), target)
+            if pointer is None:
+                pointer = _declared_pointer(source, 0, call.start(), target, top_level=True)
+        else:
+            continue
+        if pointer is False:
+            pass_.report(
+                source.line_at(call.start()),
+                target=target,
+                hint="pass the address of a variable of the error type: errors.As(err, &target)",
+            )
+
+
+# `f, err := os.Open(p)` / `resp, err = client.Do(req)`: a value and its error.
+_VALUE_AND_ERROR_RE = re.compile(
+    r"^[ \t]*(\w+)[ \t]*,[ \t]*(\w+)[ \t]*:?=[ \t]*[^\n]*\w\(", re.MULTILINE
+)
+
+
+def detect_defer_on_maybe_nil(pass_: Pass) -> None:
+    """Detect ``defer x.Close()`` placed between ``x, err := ...`` and the check of ``err``.
+
+    When the call fails, ``x`` is usually nil and the deferred method
+    panics (or its error hides the real one) as the function returns.
+    The statements after the assignment, in its block, are scanned up to
+    the first one that mentions ``err``; a ``defer`` calling a method on
+    ``x`` (``x.Close()``, ``x.Body.Close()``) before it is reported.
+    """
+    source = pass_.file
+    masked = source.masked
+    for m in _VALUE_AND_ERROR_RE.finditer(masked):
+        value, err = m.group(1), m.group(2)
+        if value == "_" or not _ERROR_NAME_RE.search(err):
+            continue
+        enclosing = source.enclosing_blocks(m.start())
+        if not enclosing:
+            continue
+        close = matching_brace(masked, enclosing[0]) or len(masked)
+        uses_err = re.compile(rf"(?<![\w.]){re.escape(err)}\b")
+        defer_call = re.compile(rf"defer[ \t]+{re.escape(value)}\.(?:\w+\.)*\w+\(")
+        pos = _statement_end(masked, m.start() + len(m.group()) - len(m.group().lstrip()))
+        while True:
+            pos += len(masked[pos:close]) - len(masked[pos:close].lstrip(" \t\n;"))
+            if pos >= close:
+                break
+            end = _statement_end(masked, pos)
+            text = masked[pos:end]
+            if end == pos or uses_err.search(text):
+                break
+            if defer_call.match(text):
+                pass_.report(
+                    source.line_at(pos),
+                    value=value,
+                    error=err,
+                    hint=f"check {err} first, then defer",
+                )
+                break
+            pos = end
+
+
+# Default of ``error_only_logged``'s ``best_effort`` option: name prefixes.
+BEST_EFFORT_PREFIXES = ("try", "bestEffort", "maybe")
+
+_ERR_CHECK_RE = re.compile(
+    r"^[ \t]*if[ \t]+(?:([^;{\n]*);[ \t]*)?(\w+)[ \t]*!=[ \t]*nil[ \t]*\{", re.MULTILINE
+)
+# log.Printf, logger.Warn, s.log.Error, slog.Info, fmt.Fprintln(os.Stderr, ...);
+# not Fatal or Panic, which do not come back. Error and Errorf count only on
+# a logger: compilers and parsers have Errorf methods that record the failure.
+_LOG_CALL_RE = re.compile(
+    r"(?:\w+\.)*(?:Print|Debug|Info|Warn|Warning|Log)(?:f|ln|w|Context)?\("
+    r"|(?:\w+\.)*\w*(?:log|Log)\w*(?:\(\))?\.Error(?:f|w|Context)?\("
+    r"|fmt\.Fprint(?:f|ln)?\([ \t]*os\.Std(?:err|out)\b"
+)
+_BOUND_RE = re.compile(r"^[ \t]*((?:\w+[ \t]*,[ \t]*)+)(\w+)[ \t]*:?=[^=]")
+_COMMENT_RE = re.compile(r"//|/\*")
+_BEST_EFFORT_DOC_RE = re.compile(r"\bbest[- ]effort\b", re.IGNORECASE)
+
+
+def _only_logs(masked: str, open_: int, close: int) -> bool:
+    """Whether the block ``open_..close`` holds nothing but logging calls."""
+    pos = open_ + 1
+    statements = 0
+    while True:
+        pos += len(masked[pos:close]) - len(masked[pos:close].lstrip(" \t\n;"))
+        if pos >= close:
+            return statements > 0
+        end = _statement_end(masked, pos)
+        text = masked[pos:end].rstrip()
+        m = _LOG_CALL_RE.match(text)
+        if m is None or _closing_paren(masked, pos + m.end() - 1) != pos + len(text) - 1:
+            return False
+        statements += 1
+        pos = end
+
+
+def _best_effort(source: GoFile, name: str | None, brace: int, prefixes: list[str]) -> bool:
+    """Whether the function named ``name`` says it is best-effort, by name or doc comment."""
+    if name and any(
+        name.startswith(prefix)
+        and (len(name) == len(prefix) or not name[len(prefix)].islower())
+        for prefix in {q for p in prefixes for q in (p, p[:1].upper() + p[1:])}
+    ):
+        return True
+    lines = source.lines[: source.line_at(brace) - 1]
+    while lines and lines[-1].lstrip().startswith("//"):
+        if _BEST_EFFORT_DOC_RE.search(lines.pop()):
+            return True
+    return False
+
+
+def detect_error_only_logged(pass_: Pass) -> None:
+    """Detect ``if err != nil`` blocks that only log and then carry on.
+
+    Reported when the enclosing function returns an error, which the block
+    should have passed up, or when a result bound with the error is used
+    after the block as if the call had worked. Blocks that also
+    ``return``, ``continue``, ``break`` or do anything else are handling
+    the error, and a comment in the block explains why logging is enough.
+    Reported with the ``error`` and, for the second case, the ``value``
+    used after the block.
+    """
+    source = pass_.file
+    masked = source.masked
+    bodies = _func_bodies(masked)
+    prefixes = pass_.options["best_effort"]
+    for m in _ERR_CHECK_RE.finditer(masked):
+        err = m.group(2)
+        if not _ERROR_NAME_RE.search(err):
+            continue
+        open_ = m.end() - 1
+        close = matching_brace(masked, open_)
+        if close is None or re.match(r"[ \t]*else\b", masked[close + 1 : close + 16]):
+            continue
+        if not _only_logs(masked, open_, close) or _COMMENT_RE.search(
+            source.content, masked.find("\n", open_), close
+        ):
+            continue
+        enclosing = source.enclosing_blocks(m.start())
+        function = next((b for b in enclosing if b in bodies), None)
+        if function is None:
+            continue
+        name, results = bodies[function]
+        if _best_effort(source, name, function, prefixes):
+            continue
+        if _ERROR_LAST_RE.search(results):
+            pass_.report(
+                source.line_at(m.start()),
+                error=err,
+                hint=f"return {err} (wrapped if it helps) instead of only logging it; "
+                "callers cannot tell the call failed",
+            )
+            continue
+        value = _used_after(source, m, err, close, enclosing[0])
+        if value is not None:
+            pass_.report(
+                source.line_at(m.start()),
+                error=err,
+                value=value,
+                hint=f"{value} is used after the failed call; return, continue or "
+                f"fall back to a default when {err} is not nil",
+            )
+
+
+def _used_after(
+    source: GoFile, check: re.Match, err: str, close: int, block: int
+) -> str | None:
+    """The first value bound with ``err`` just before ``check`` that is used after ``close``."""
+    masked = source.masked
+    if check.group(1) is not None:
+        return None  # `if v, err := f(); err != nil`: v is gone after the if
+    before = masked[: source.line_start(check.start())].rstrip("\n")
+    previous = before[before.rfind("\n") + 1 :]
+    bound = _BOUND_RE.match(previous)
+    if bound is None or bound.group(2) != err:
+        return None
+    block_close = matching_brace(masked, block) or len(masked)
+    for value in re.findall(r"\w+", bound.group(1)):
+        if value != "_" and re.search(
+            rf"(?<![\w.]){value}\b", masked[close + 1 : block_close]
+        ):
+            return value
+    return None
+
+
+__all__ = [
+    "BEST_EFFORT_PREFIXES",
+    "detect_always_nil_error",
+    "detect_defer_on_maybe_nil",
+    "detect_deferred_error_ignored",
+    "detect_error_not_last",
+    "detect_error_only_logged",
+    "detect_error_not_wrapped",
+    "detect_errors_as_target",
+    "detect_multiple_wrap_verbs",
+    "detect_panic_string",
+    "visit_useless_error_return",
+    "visit_value_with_error",
+]
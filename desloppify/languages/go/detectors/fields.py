@@ -0,0 +1,143 @@
+"""Unexported struct fields that nothing in the package uses.
+
+An unexported field can only be used by its own package, so when no
+file of the package selects it (``x.name``, including through an
+embedding struct) or sets it in a keyed literal (``T{name: v}``), it is
+dead weight: memory in every value and a line every reader has to
+puzzle over. ``unused_field`` reports such fields; it needs every file
+of the package. Uses count by name, in the package's tests and in its
+files for other platforms and build tags too (``conn_windows.go`` may be
+the only reader of a field).
+
+Fields that code outside the package's source may still reach are left
+alone:
+
+- fields with a struct tag, which marks them for ``encoding/json``,
+  a database mapper or another reflection-driven library;
+- every field of a struct built with an unkeyed literal (``T{a, b}``),
+  which sets them all by position;
+- blank fields (``_``), zero-length arrays and ``noCopy`` markers, which
+  exist for their type, not their value;
+- packages importing ``reflect`` outside their tests, whose structs may
+  be walked field by field, and files importing ``unsafe``, ``syscall`` or ``C`` or
+  generated, whose structs mirror a memory layout.
+"""
+
+from __future__ import annotations
+
+import re
+from pathlib import Path
+
+from desloppify.languages.go.detectors._inspector import _PACKAGE_RE, GoFile, Pass
+from desloppify.languages.go.detectors._source import matching_brace
+from desloppify.languages.go.detectors.concurrency import _statements
+from desloppify.languages.go.detectors.custom_rules import _imports
+from desloppify.languages.go.detectors.naming import _GENERATED_RE
+from desloppify.languages.go.detectors.struct_layout import _parse_field
+
+_STRUCT_RE = re.compile(r"\btype[ \t]+(\w+)(?:\[[^\]\n]*\])?[ \t]+struct[ \t]*\{")
+_LAYOUT_IMPORTS = frozenset({"C", "syscall", "unsafe"})
+_MARKER_TYPE_RE = re.compile(r"\[0\].*|(?:\w+\.)?(?:noCopy|DoNotCopy|HostLayout|NoUnkeyedLiterals)")
+# On masked text: a string literal ending the declaration (comments are blanked).
+_TAG_RE = re.compile(r"(?:`[^`]*`|\"[^\"\n]*\")\s*$")
+_KEYED_RE = re.compile(r"\s*(?:\}|\w+\s*:(?!=))")
+
+
+def _imported(files: tuple[GoFile, ...]) -> set[str]:
+    return {path for f in files for _, path, _ in _imports(f)}
+
+
+def _other_builds(files: tuple[GoFile, ...]) -> tuple[GoFile, ...]:
+    """The package's files that the active build constraints leave out."""
+    if not files:
+        return ()
+    package = _PACKAGE_RE.search(files[0].masked)
+    known = {Path(f.path).resolve() for f in files}
+    found = []
+    for path in sorted(Path(files[0].path).parent.glob("*.go")):
+        if path.resolve() in known or path.name.endswith("_test.go"):
+            continue
+        try:
+            other = GoFile(str(path), path.read_text(errors="replace"))
+        except OSError:
+            continue
+        m = _PACKAGE_RE.search(other.masked)
+        if package is not None and m is not None and m.group(1) == package.group(1):
+            found.append(other)
+    return tuple(found)
+
+
+def _used_names(files: tuple[GoFile, ...]) -> set[str]:
+    """Names selected (``.name``) or used as a literal key (``name:``) in ``files``."""
+    names: set[str] = set()
+    for f in files:
+        names.update(re.findall(r"\.[ \t]*([a-z_]\w*)", f.masked))
+        names.update(re.findall(r"(?<![\w.])([a-z_]\w*)[ \t]*:(?!=)", f.masked))
+    return names
+
+
+def _unkeyed(files: tuple[GoFile, ...], struct: str) -> bool:
+    """Whether any file builds ``struct`` with an unkeyed (positional) literal."""
+    literal = re.compile(rf"(?<![\w.]){re.escape(struct)}(?:\[[^\]\n]*\])?\{{")
+    for f in files:
+        for m in literal.finditer(f.masked):
+            if not _KEYED_RE.match(f.masked, m.end()):
+                return True
+    return False
+
+
+def _fields(source: GoFile, open_brace: int, close: int) -> list[tuple[int, str, str, bool]]:
+    """(offset, name, type, tagged) of each named field of the struct at ``open_brace``."""
+    masked = source.masked
+    fields = []
+    for start, end in _statements(masked, open_brace + 1, close):
+        start += len(masked[start:end]) - len(masked[start:end].lstrip())
+        names, type_ = _parse_field(masked[start:end].strip())
+        tagged = _TAG_RE.search(masked, start, end) is not None
+        fields.extend((start, name, type_, tagged) for name in names)
+    return fields
+
+
+def detect_unused_field(pass_: Pass) -> None:
+    """Detect unexported struct fields that no file of the package uses.
+
+    Reported with the ``field`` and its ``struct``.
+    """
+    source = pass_.file
+    if pass_.types is None or _GENERATED_RE.search(source.content):
+        return
+    if _imported((source,)) & _LAYOUT_IMPORTS:
+        return
+    files = pass_.types.files
+    if "reflect" in pass_.types.memo("fields:imports", lambda: _imported(files)):
+        return
+    every = pass_.types.memo(
+        "fields:files", lambda: files + pass_.types.tests + _other_builds(files)
+    )
+    used = pass_.types.memo("fields:used", lambda: _used_names(every))
+    masked = source.masked
+    for m in _STRUCT_RE.finditer(masked):
+        close = matching_brace(masked, m.end() - 1)
+        if close is None:
+            continue
+        struct = m.group(1)
+        unused = [
+            (offset, name)
+            for offset, name, type_, tagged in _fields(source, m.end() - 1, close)
+            if name[0].islower()
+            and name not in used
+            and not tagged
+            and not _MARKER_TYPE_RE.fullmatch(type_)
+        ]
+        if not unused or _unkeyed(every, struct):
+            continue
+        for offset, name in unused:
+            pass_.report(
+                source.line_at(offset),
+                field=name,
+                struct=struct,
+                hint=f"nothing in the package reads or sets {struct}.{name}; remove it",
+            )
+
+
+__all__ = ["detect_unused_field"]
@@ -0,0 +1,231 @@
+"""Go functions too long to read in one go, with where to split them.
+
+Length is counted in statements rather than lines: blank lines, comments and
+lines that only close a block are free, and by default so are the element
+lines of composite literals (lookup tables) and ``case`` clauses of one
+statement (marshaling switches), which are long without being hard to follow.
+
+The finding also proposes extraction points. The body's top-level statements
+are grouped into sections, a new one starting after a blank line or at a
+comment header; the largest sections are the candidates, titled by their
+header comment. When the body is one unbroken section (say, a single big
+loop) the analysis descends into its largest block and splits that instead.
+"""
+
+from __future__ import annotations
+
+import re
+from dataclasses import dataclass
+
+from desloppify.languages.go.detectors._inspector import GoFile, Pass
+from desloppify.languages.go.detectors.concurrency import _blocks, _statements
+from desloppify.languages.go.detectors.panics import _functions
+
+COMPOSITE_LITERALS = "composite_literals"
+CASE_CLAUSES = "case_clauses"
+
+# Lines that only close what earlier lines opened: `}`, `})`, `},`, `} else {`.
+_CLOSER_RE = re.compile(r"^[\s})\],;]*(?:else\s*\{)?[\s]*$")
+_CASE_RE = re.compile(r"^\s*(?:case\b|default\s*:)")
+_TYPE_LITERAL_RE = re.compile(r"\b(?:struct|interface)\s*$")
+
+# Sections smaller than this are not worth a function of their own.
+_MIN_SECTION = 5
+_MAX_SUGGESTIONS = 3
+
+
+@dataclass(frozen=True)
+class Section:
+    """A run of statements that could move into a function of its own."""
+
+    start: int
+    end: int
+    title: str
+    statements: int
+
+    def describe(self) -> str:
+        title = f", '{self.title}'" if self.title else ""
+        return f"lines {self.start}–{self.end}{title}"
+
+
+def _is_code_block(file: GoFile, brace: int) -> bool:
+    """Whether the ``{`` at ``brace`` opens statements rather than a composite literal.
+
+    gofmt puts a space before a block's brace (``if x {``, ``func() {``) and
+    none before a literal's (``T{``, ``[]int{``); a brace starting its line is
+    whatever its parent is (a bare block, or an element of a literal).
+    """
+    known: dict[int, bool] = file.memo("function_length:code_blocks", dict)
+    if brace not in known:
+        before = file.masked[file.line_start(brace) : brace]
+        if not before.strip():
+            parents = file.enclosing_blocks(brace)
+            known[brace] = _is_code_block(file, parents[0]) if parents else True
+        else:
+            known[brace] = before[-1] in " \t" and not _TYPE_LITERAL_RE.search(before)
+    return known[brace]
+
+
+def _in_literal(file: GoFile, offset: int) -> bool:
+    enclosing = file.enclosing_blocks(offset)
+    return bool(enclosing) and not _is_code_block(file, enclosing[0])
+
+
+def _single_statement_cases(masked_lines: list[str], first: int, last: int) -> set[int]:
+    """Lines that are the only statement of a ``case`` clause in ``first``..``last``."""
+    found: set[int] = set()
+    for number in range(first, last + 1):
+        text = masked_lines[number - 1]
+        if not _CASE_RE.match(text):
+            continue
+        indent = len(text) - len(text.lstrip())
+        body = []
+        for following in range(number + 1, last + 1):
+            line = masked_lines[following - 1]
+            if not line.strip():
+                continue
+            if len(line) - len(line.lstrip()) <= indent:
+                break
+            body.append(following)
+        if len(body) == 1:
+            found.add(body[0])
+    return found
+
+
+def count_statements(file: GoFile, first: int, last: int, exclude: frozenset[str]) -> int:
+    """Statement lines among 1-based ``first``..``last`` of ``file``.
+
+    With ``case_clauses`` excluded, ``case``/``default`` labels and a body
+    that is a single line (``case A: return "a"`` spelled over two lines) are
+    free; longer clause bodies still count.
+    """
+    masked_lines = file.masked_lines
+    last = min(last, len(masked_lines))
+    skipped = (
+        _single_statement_cases(masked_lines, first, last) if CASE_CLAUSES in exclude else set()
+    )
+    count = 0
+    for number in range(first, last + 1):
+        text = masked_lines[number - 1]
+        if not text.strip() or _CLOSER_RE.match(text) or number in skipped:
+            continue
+        if CASE_CLAUSES in exclude and _CASE_RE.match(text):
+            continue
+        if COMPOSITE_LITERALS in exclude:
+            indent = len(text) - len(text.lstrip())
+            if _in_literal(file, file.line_offset(number) + indent):
+                continue
+        count += 1
+    return count
+
+
+def _header(file: GoFile, first: int, previous_end: int) -> tuple[bool, str]:
+    """(section break, comment title) for a statement on line ``first``.
+
+    Looks at the lines between the previous statement (ending on line
+    ``previous_end``) and this one: a blank line breaks the section, and a
+    comment directly above the statement is its header.
+    """
+    between = file.lines[previous_end : first - 1]
+    comments: list[str] = []
+    for line in reversed(between):
+        stripped = line.strip()
+        if not stripped.startswith("//"):
+            break
+        comments.append(stripped[2:].strip())
+    blank = any(not line.strip() for line in between)
+    title = comments[-1] if comments else ""
+    return blank or bool(comments), title.rstrip(".:")
+
+
+def sections(
+    file: GoFile, open_brace: int, close: int, exclude: frozenset[str]
+) -> list[Section]:
+    """The sections of the block ``{ }`` at ``open_brace``..``close``."""
+    masked = file.masked
+    found: list[Section] = []
+    start = title = None
+    previous_end = file.line_at(open_brace)
+    end = previous_end
+    for s, e in _statements(masked, open_brace + 1, close):
+        offset = s + len(masked[s:e]) - len(masked[s:e].lstrip())
+        first = file.line_at(offset)
+        breaks, header = _header(file, first, previous_end)
+        if start is not None and breaks:
+            found.append(Section(start, end, title, count_statements(file, start, end, exclude)))
+            start = None
+        if start is None:
+            start, title = first, header
+        end = previous_end = file.line_at(e - 1 if e > s else e)
+    if start is not None:
+        found.append(Section(start, end, title, count_statements(file, start, end, exclude)))
+    return found
+
+
+def _largest_block(file: GoFile, section: Section) -> tuple[int, int] | None:
+    """The biggest ``{ }`` directly inside the statements of ``section``."""
+    masked = file.masked
+    start = file.line_offset(section.start)
+    end = file.line_offset(section.end + 1) if section.end < len(file.lines) else len(masked)
+    candidates = [
+        (a, b)
+        for s, e in _statements(masked, start, end)
+        for a, b in _blocks(masked, s, e)
+        if _is_code_block(file, a)
+    ]
+    return max(candidates, key=lambda block: block[1] - block[0], default=None)
+
+
+def extraction_points(
+    file: GoFile, open_brace: int, close: int, exclude: frozenset[str]
+) -> list[Section]:
+    """The largest sections worth extracting from a function body, in line order."""
+    parts = sections(file, open_brace, close, exclude)
+    # One unbroken run of statements: look for the seams inside its biggest block.
+    while len(parts) == 1:
+        block = _largest_block(file, parts[0])
+        if block is None:
+            break
+        parts = sections(file, block[0], block[1], exclude)
+    worth = [p for p in parts if p.statements >= _MIN_SECTION]
+    if len(parts) < 2:
+        return []
+    best = sorted(worth, key=lambda p: (-p.statements, p.start))[:_MAX_SUGGESTIONS]
+    return sorted(best, key=lambda p: p.start)
+
+
+def detect_long_function(pass_: Pass) -> None:
+    """Detect functions with more statements than the ``max_statements`` option."""
+    source = pass_.file
+    limit = pass_.options["max_statements"]
+    exclude = frozenset(pass_.options["exclude"])
+    for func in _functions(source):
+        first = source.line_at(func.body_open) + 1
+        last = source.line_at(func.body_close) - 1
+        statements = count_statements(source, first, last, exclude)
+        if statements <= limit:
+            continue
+        points = extraction_points(source, func.body_open, func.body_close, exclude)
+        hint = "; ".join(f"consider extracting {p.describe()}" for p in points)
+        pass_.report(
+            source.line_at(func.start),
+            function=func.name,
+            lines=source.line_at(func.body_close) - source.line_at(func.start) + 1,
+            statements=statements,
+            extract=[
+                {"start": p.start, "end": p.end, "title": p.title, "statements": p.statements}
+                for p in points
+            ],
+            **({"hint": hint} if hint else {}),
+        )
+
+
+__all__ = [
+    "CASE_CLAUSES",
+    "COMPOSITE_LITERALS",
+    "Section",
+    "count_statements",
+    "detect_long_function",
+    "extraction_points",
+    "sections",
+]
@@ -0,0 +1,204 @@
+"""Package names and file organization.
+
+Go names a package once, in lower case, after the directory that holds it:
+``package stringutil`` in ``stringutil/``. ``package_name_style``
+reports names with underscores (``string_utils``) or mixed caps
+(``utilsHelpers``), and, when ``reasons`` includes ``plural``, a plural
+form (``models``). Plurals are off by default: the standard library uses
+many (``strings``, ``errors``, ``windows``); the ``allow`` option lists
+those that stay allowed when they are on. ``package_dir_mismatch``
+reports a package whose name is not its directory's, ignoring ``-``,
+``_`` and ``.``, a ``go-`` prefix or ``-go`` suffix, and a ``/vN``
+major-version directory; at a module root the module path's last element
+counts as the directory.
+``main`` packages are exempt from both.
+
+``package_too_many_files`` reports a package with more than ``max_files``
+non-test files, ``file_too_long`` any file with more than ``max_lines``
+lines, and ``package_test_heavy`` a package whose same-package
+``_test.go`` files have more lines than its non-test files combined.
+Package findings go on the ``package`` clause of the package's first file;
+all but ``file_too_long`` need the whole package, so ``scan --fast`` skips
+them.
+"""
+
+from __future__ import annotations
+
+import os
+import re
+from pathlib import Path
+
+from desloppify.languages.go.detectors._inspector import _PACKAGE_RE, GoFile, Pass
+
+# Defaults of the ``reasons``, ``allow``, ``max_files`` and ``max_lines`` options.
+NAME_STYLE_REASONS = ("underscore", "mixed_caps", "plural")
+DEFAULT_NAME_STYLE_REASONS = ("underscore", "mixed_caps")
+PLURAL_PACKAGES = ("bytes", "errors", "maps", "slices", "strings", "types")
+MAX_PACKAGE_FILES = 30
+MAX_FILE_LINES = 1500
+
+_MIXED_CAPS_RE = re.compile(r"[a-z0-9][A-Z]|^[A-Z]")
+_SINGULAR_ENDINGS = ("ss", "us", "is", "as", "os")
+_VERSION_RE = re.compile(r"^v[0-9]+$")
+_MODULE_RE = re.compile(r"^module[ \t]+(\S+)", re.MULTILINE)
+
+
+def _package_clause(file: GoFile) -> tuple[str, int] | None:
+    """(name, line) of ``file``'s ``package`` clause."""
+    m = _PACKAGE_RE.search(file.masked)
+    return (m.group(1), file.line_at(m.start())) if m is not None else None
+
+
+def _package_site(pass_: Pass) -> tuple[str, int] | None:
+    """(name, line) for a package finding, when ``pass_`` is the package's first file.
+
+    None for every other file, and when the files disagree on the name.
+    """
+    if pass_.types is None:
+        return None
+    files = sorted(pass_.types.files, key=lambda f: f.path)
+    if not files or files[0].path != pass_.file.path:
+        return None
+    names = {clause[0] for f in files if (clause := _package_clause(f)) is not None}
+    clause = _package_clause(pass_.file)
+    if clause is None or names != {clause[0]}:
+        return None
+    return clause
+
+
+def _is_plural(name: str) -> bool:
+    return len(name) > 3 and name.endswith("s") and not name.endswith(_SINGULAR_ENDINGS)
+
+
+def detect_package_name_style(pass_: Pass) -> None:
+    """Detect package names with underscores, mixed caps or a plural form."""
+    site = _package_site(pass_)
+    if site is None or site[0] == "main":
+        return
+    name, line = site
+    reasons = pass_.options["reasons"]
+    if "_" in name and "underscore" in reasons:
+        reason, better = "underscore", name.replace("_", "").lower()
+    elif _MIXED_CAPS_RE.search(name) and "mixed_caps" in reasons:
+        reason, better = "mixed_caps", name.lower()
+    elif "plural" in reasons and _is_plural(name) and name not in pass_.options["allow"]:
+        reason, better = "plural", name[:-1]
+    else:
+        return
+    pass_.report(
+        line,
+        package=name,
+        reason=reason,
+        hint=f"name the package {better}: short, lower case, one word, singular",
+    )
+
+
+def _directory_name(path: str) -> str | None:
+    """The last element of the import path of the directory holding ``path``."""
+    directory = os.path.dirname(path)
+    if not directory:
+        return None
+    gomod = Path(directory) / "go.mod"
+    if gomod.is_file():
+        try:
+            m = _MODULE_RE.search(gomod.read_text(errors="replace"))
+        except OSError:
+            m = None
+        if m is not None:
+            elements = m.group(1).split("/")
+            if len(elements) > 1 and _VERSION_RE.match(elements[-1]):
+                return elements[-2]
+            return elements[-1]
+    name = os.path.basename(directory)
+    if _VERSION_RE.match(name):
+        name = os.path.basename(os.path.dirname(directory))
+    return name or None
+
+
+def _normalize(name: str) -> str:
+    name = name.lower()
+    name = name.removeprefix("go-").removesuffix("-go").removesuffix(".go")
+    return re.sub(r"[-_.]", "", name)
+
+
+def detect_package_dir_mismatch(pass_: Pass) -> None:
+    """Detect packages not named after their directory."""
+    site = _package_site(pass_)
+    if site is None or site[0] == "main":
+        return
+    name, line = site
+    directory = _directory_name(pass_.file.path)
+    if directory is None or _normalize(directory) == _normalize(name):
+        return
+    pass_.report(
+        line,
+        package=name,
+        directory=directory,
+        hint=f"package {name} lives in {directory}/; rename one so importers "
+        "see the name they import",
+    )
+
+
+def detect_package_too_many_files(pass_: Pass) -> None:
+    """Detect packages with more than the ``max_files`` option's non-test files."""
+    site = _package_site(pass_)
+    if site is None:
+        return
+    limit = pass_.options["max_files"]
+    count = len(pass_.types.files)
+    if count > limit:
+        pass_.report(
+            site[1],
+            package=site[0],
+            files=count,
+            hint=f"{count} files, more than {limit}; split the package by concern",
+        )
+
+
+def detect_file_too_long(pass_: Pass) -> None:
+    """Detect files with more than the ``max_lines`` option's lines."""
+    limit = pass_.options["max_lines"]
+    lines = len(pass_.file.lines)
+    if lines <= limit:
+        return
+    clause = _package_clause(pass_.file)
+    pass_.report(
+        clause[1] if clause is not None else 1,
+        lines=lines,
+        hint=f"{lines} lines, more than {limit}; move related declarations to their "
+        "own file",
+    )
+
+
+def detect_package_test_heavy(pass_: Pass) -> None:
+    """Detect packages whose same-package tests outweigh their code."""
+    site = _package_site(pass_)
+    if site is None:
+        return
+    tests = pass_.types.tests
+    test_lines = sum(len(f.lines) for f in tests)
+    code_lines = sum(len(f.lines) for f in pass_.types.files)
+    if test_lines > code_lines:
+        pass_.report(
+            site[1],
+            package=site[0],
+            test_lines=test_lines,
+            code_lines=code_lines,
+            hint=f"{test_lines} test lines against {code_lines} of code; move "
+            "black-box tests to a package "
+            f"{site[0]}_test and shared fixtures to testdata",
+        )
+
+
+__all__ = [
+    "DEFAULT_NAME_STYLE_REASONS",
+    "MAX_FILE_LINES",
+    "MAX_PACKAGE_FILES",
+    "NAME_STYLE_REASONS",
+    "PLURAL_PACKAGES",
+    "detect_file_too_long",
+    "detect_package_dir_mismatch",
+    "detect_package_name_style",
+    "detect_package_test_heavy",
+    "detect_package_too_many_files",
+]
@@ -0,0 +1,105 @@
+"""Imports that hide what a package does or where its names come from.
+
+``import _ "github.com/lib/pq"`` runs the driver's ``init``, which
+registers it in a process-wide table, in every program that links the
+importing package. In package main that is a choice the program makes; in
+a library it is a side effect every importer inherits without seeing it.
+``blank_import`` reports blank imports outside package main, except:
+
+- ``embed`` and ``unsafe``, which ``//go:embed`` and ``//go:linkname``
+  need and which have no ``init``;
+- the paths of the ``allow`` option (``path`` or ``path/...``);
+- ``net/http/pprof`` and ``expvar``, which register debug handlers, in a
+  package whose name or directory says ``debug``;
+- a registration file: one that only has blank imports and declares
+  nothing, so the package exists to be imported for its side effects.
+
+``dot_import`` reports ``import . "path"``: the package's exported names
+become unqualified identifiers, so readers cannot tell where they come
+from, and a name added upstream can collide with a local one. Test files
+are never scanned; the ``allow`` option (Ginkgo and Gomega by default)
+covers DSL suites kept in ordinary files, as e2e packages do.
+"""
+
+from __future__ import annotations
+
+import os
+import re
+
+from desloppify.languages.go.detectors._inspector import _PACKAGE_RE, Pass
+from desloppify.languages.go.detectors.custom_rules import _imports
+
+# Default of ``dot_import``'s ``allow`` option.
+DOT_IMPORT_ALLOWED = ("github.com/onsi/ginkgo/...", "github.com/onsi/gomega/...")
+
+_DIRECTIVE_IMPORTS = frozenset({"embed", "unsafe"})
+_DEBUG_IMPORTS = frozenset({"net/http/pprof", "expvar"})
+_DECLARATION_RE = re.compile(r"^(?:func|type|var|const)\b", re.MULTILINE)
+
+
+def _allowed(path: str, patterns: list[str]) -> bool:
+    """Whether ``path`` is in ``patterns``, where ``x/...`` covers ``x`` and below."""
+    for pattern in patterns:
+        if pattern.endswith("/..."):
+            prefix = pattern[: -len("/...")]
+            if path == prefix or path.startswith(prefix + "/"):
+                return True
+        elif path == pattern:
+            return True
+    return False
+
+
+def _debug_package(pass_: Pass, package: str) -> bool:
+    directory = os.path.basename(os.path.dirname(pass_.file.path))
+    return "debug" in package.lower() or "debug" in directory.lower()
+
+
+def detect_blank_import(pass_: Pass) -> None:
+    """Detect blank imports in packages other than main.
+
+    Reported with the import ``path``.
+    """
+    source = pass_.file
+    m = _PACKAGE_RE.search(source.masked)
+    package = m.group(1) if m is not None else ""
+    if package == "main":
+        return
+    imports = _imports(source)
+    blank = [(path, offset) for name, path, offset in imports if name == "_"]
+    if not blank:
+        return
+    if len(blank) == len(imports) and not _DECLARATION_RE.search(source.masked):
+        return
+    for path, offset in blank:
+        if path in _DIRECTIVE_IMPORTS or _allowed(path, pass_.options["allow"]):
+            continue
+        if path in _DEBUG_IMPORTS and _debug_package(pass_, package):
+            continue
+        pass_.report(
+            source.line_at(offset),
+            path=path,
+            hint=f"this runs {path}'s init, and whatever it registers, in every program "
+            f"that imports package {package}; import it in package main (or a package "
+            "that exists to register it) so programs opt in",
+        )
+
+
+def detect_dot_import(pass_: Pass) -> None:
+    """Detect ``import . "path"``; reported with the import ``path``."""
+    source = pass_.file
+    for name, path, offset in _imports(source):
+        if name != "." or _allowed(path, pass_.options["allow"]):
+            continue
+        pass_.report(
+            source.line_at(offset),
+            path=path,
+            hint=f"{path}'s names read as if this package declared them, and a name it "
+            "adds later can collide with one here; import it by name",
+        )
+
+
+__all__ = [
+    "DOT_IMPORT_ALLOWED",
+    "detect_blank_import",
+    "detect_dot_import",
+]
@@ -0,0 +1,306 @@
+"""encoding/json mistakes that compile and then misbehave at run time.
+
+Each one is its own rule:
+
+- ``json_tag_unexported``: an unexported field with a ``json`` tag.
+  encoding/json never sees unexported fields, so the tag does nothing and
+  the field is silently left out.
+- ``json_unmarshal_non_pointer``: ``json.Unmarshal(data, v)`` or
+  ``Decode(v)`` with a ``v`` that is not a pointer; both return an
+  ``InvalidUnmarshalError`` and fill nothing.
+- ``json_tag_invalid``: a tag option encoding/json does not know
+  (``omitempy``), or a ``json`` key it cannot read (``json: "name"``).
+- ``json_tag_duplicate``: two fields of a struct that encode to the same
+  name. encoding/json drops both.
+- ``json_tag_missing`` (opt-in): an exported field without a tag in a
+  struct the package marshals or unmarshals, which is then encoded under
+  its Go name.
+
+Tags are read the way encoding/json reads them: ``json:"-"`` leaves the
+field out, ``json:"-,"`` names it ``-``, an empty name keeps the Go
+name, and the options after the first comma are ``omitempty``,
+``omitzero`` and ``string``.
+"""
+
+from __future__ import annotations
+
+import difflib
+import re
+from dataclasses import dataclass
+
+from desloppify.languages.go.detectors._inspector import GoFile, Pass
+from desloppify.languages.go.detectors._source import matching_brace
+from desloppify.languages.go.detectors.concurrency import _enclosing_spans, _statements
+from desloppify.languages.go.detectors.error_flow import _closing_paren, _declared_pointer
+from desloppify.languages.go.detectors.logic import _split_top_level
+
+JSON_OPTIONS = ("omitempty", "omitzero", "string")
+
+_STRUCT_RE = re.compile(r"\btype[ \t]+(\w+)(?:\[[^\]\n]*\])?[ \t]+struct[ \t]*\{")
+_FIELD_RE = re.compile(r"\s*(\w+(?:[ \t]*,[ \t]*\w+)*)[ \t]+([^`\n]+?)[ \t]*(?:`|$)")
+_EMBEDDED_RE = re.compile(r"\s*\*?(?:\w+\.)?\w+[ \t]*(?:`|$)")
+_JSON_KEY_RE = re.compile(r'(?:^|[ \t])json:"((?:[^"\\]|\\.)*)"')
+_BAD_JSON_KEY_RE = re.compile(r'(?:^|[ \t])json(?::[ \t]+"|:[^"\s]|=")')
+# `json.Marshal(v)`, `json.Unmarshal(data, &v)`, `json.NewDecoder(r).Decode(&v)`,
+# and `enc.Encode(v)` on a name assigned from json.NewEncoder.
+_JSON_CALL_RE = re.compile(
+    r"(?<![\w.])json\.(Marshal|MarshalIndent|Unmarshal)\("
+    r"|(?<![\w.])json\.New(?:En|De)coder\([^\n]*?\)\.(Encode|Decode)\("
+    r"|(?<![\w.])(\w+)\.(Encode|Decode)\("
+)
+_CODER_DECL_RE = r"(?<![\w.]){name}[ \t]*:?=[ \t]*json\.New(?:En|De)coder\("
+_TYPE_NAME_RE = re.compile(r"(?:\*|\[\]|\[\d+\]|map\[[^\]]*\])*(\w+)$")
+
+
+@dataclass(frozen=True)
+class JSONField:
+    """One named field of a struct, with its ``json`` tag if it has one."""
+
+    name: str
+    type_: str
+    offset: int
+    tag: str | None
+    raw_tag: str
+
+    @property
+    def exported(self) -> bool:
+        return self.name[:1].isupper()
+
+
+def parse_json_tag(tag: str) -> tuple[str | None, list[str]]:
+    """(name, options) of a ``json`` tag value; the name is None for ``json:"-"``."""
+    if tag == "-":
+        return None, []
+    name, *options = tag.split(",")
+    return name, [o for o in options if o]
+
+
+def _structs(file: GoFile) -> list[tuple[str, list[JSONField]]]:
+    """(type name, fields) of each struct type the file declares."""
+    def build() -> list[tuple[str, list[JSONField]]]:
+        masked, content = file.masked, file.content
+        structs = []
+        for m in _STRUCT_RE.finditer(masked):
+            close = matching_brace(masked, m.end() - 1)
+            if close is None:
+                continue
+            fields = []
+            for s, e in _statements(masked, m.end(), close):
+                if _EMBEDDED_RE.fullmatch(masked[s:e]):
+                    continue
+                field = _FIELD_RE.match(masked, s, e)
+                if field is None:
+                    continue
+                tick = masked.find("`", field.start(2), e)
+                raw_tag = content[tick + 1 : masked.find("`", tick + 1)] if tick != -1 else ""
+                key = _JSON_KEY_RE.search(raw_tag)
+                for name in re.findall(r"\w+", field.group(1)):
+                    offset = s + len(masked[s:e]) - len(masked[s:e].lstrip())
+                    fields.append(
+                        JSONField(
+                            name,
+                            field.group(2).strip(),
+                            offset,
+                            key.group(1) if key else None,
+                            raw_tag,
+                        )
+                    )
+            structs.append((m.group(1), fields))
+        return structs
+
+    return file.memo("jsontags:structs", build)
+
+
+def detect_json_tag_unexported(pass_: Pass) -> None:
+    """Detect unexported struct fields with a ``json`` tag encoding/json will ignore."""
+    for struct, fields in _structs(pass_.file):
+        for field in fields:
+            if field.exported or field.tag is None or parse_json_tag(field.tag)[0] is None:
+                continue
+            pass_.report(
+                pass_.file.line_at(field.offset),
+                struct=struct,
+                field=field.name,
+                hint=f"export the field, or drop the tag if {field.name} is not to be encoded",
+            )
+
+
+def detect_json_tag_invalid(pass_: Pass) -> None:
+    """Detect ``json`` tags with an unknown option or a key encoding/json cannot read."""
+    for struct, fields in _structs(pass_.file):
+        for field in fields:
+            details: dict = {}
+            if field.tag is None:
+                if _BAD_JSON_KEY_RE.search(field.raw_tag):
+                    details = {"problem": "malformed", "tag": field.raw_tag}
+            else:
+                _, options = parse_json_tag(field.tag)
+                unknown = [o for o in options if o not in JSON_OPTIONS]
+                if unknown:
+                    close = difflib.get_close_matches(unknown[0], JSON_OPTIONS, n=1)
+                    details = {"problem": "unknown_option", "option": unknown[0]}
+                    if close:
+                        details["suggestion"] = close[0]
+            if details:
+                pass_.report(
+                    pass_.file.line_at(field.offset), struct=struct, field=field.name, **details
+                )
+
+
+def detect_json_tag_duplicate(pass_: Pass) -> None:
+    """Detect struct fields that encode to the same JSON name, which drops them all."""
+    for struct, fields in _structs(pass_.file):
+        seen: dict[str, JSONField] = {}
+        for field in fields:
+            if not field.exported:
+                continue
+            name = parse_json_tag(field.tag)[0] if field.tag is not None else ""
+            if name is None:
+                continue
+            name = name or field.name
+            first = seen.setdefault(name, field)
+            if first is not field:
+                pass_.report(
+                    pass_.file.line_at(field.offset),
+                    struct=struct,
+                    field=field.name,
+                    json_name=name,
+                    same_as=first.name,
+                )
+
+
+def _value_type(file: GoFile, offset: int, arg: str) -> str | None:
+    """The type name of a JSON call argument, when the source spells it out."""
+    arg = arg.lstrip("&").strip()
+    literal = re.match(r"(?:\[\])?(\w+)[ \t]*\{", arg)
+    if literal:
+        return literal.group(1)
+    new = re.fullmatch(r"new\((\w+)\)", arg)
+    if new:
+        return new.group(1)
+    if not re.fullmatch(r"\w+", arg):
+        return None
+    spans = _enclosing_spans(file, offset)
+    start = file.masked.rfind("func", 0, spans[-1][1]) if spans else 0
+    masked = file.masked[max(start, 0) : offset]
+    found = None
+    word = re.escape(arg)
+    for m in re.finditer(
+        rf"(?<![\w.]){word}[ \t]+((?:\*|\[\]|map\[[^\]]*\])*\w+)[ \t]*(?:[,)=\n]|$)"
+        rf"|(?<![\w.]){word}[ \t]*:=[ \t]*&?(?:\[\])?(\w+)[ \t]*\{{"
+        rf"|(?<![\w.]){word}[ \t]*:=[ \t]*new\((\w+)\)",
+        masked,
+    ):
+        spelled = m.group(1) or m.group(2) or m.group(3)
+        found = _TYPE_NAME_RE.search(spelled).group(1)
+    return found
+
+
+def _json_calls(file: GoFile) -> list[tuple[int, str, str, int]]:
+    """(offset, function, value argument, value start) of each encoding/json call."""
+    masked = file.masked
+    calls = []
+    for m in _JSON_CALL_RE.finditer(masked):
+        function = m.group(1) or m.group(2) or m.group(4)
+        if m.group(3):
+            declared = re.compile(_CODER_DECL_RE.format(name=re.escape(m.group(3))))
+            if not declared.search(masked, 0, m.start()):
+                continue
+        close = _closing_paren(masked, m.end() - 1)
+        if close is None:
+            continue
+        args = _split_top_level(masked, m.end(), close, ",")
+        index = 1 if function == "Unmarshal" else 0
+        if len(args) <= index:
+            continue
+        a, b = args[index]
+        calls.append((m.start(), function, masked[a:b].strip(), a))
+    return calls
+
+
+def detect_json_unmarshal_non_pointer(pass_: Pass) -> None:
+    """Detect ``json.Unmarshal`` and ``Decode`` into a value that is not a pointer.
+
+    The target is reported when it is ``nil``, a composite literal, or a
+    name whose last declaration gives it a non-pointer type: a struct, a
+    map or a slice all need ``&``.
+    """
+    source = pass_.file
+    for offset, function, arg, _ in _json_calls(source):
+        if function not in ("Unmarshal", "Decode"):
+            continue
+        if arg == "nil" or re.match(r"(?:\[\]|map\[[^\]]*\])?\w+[ \t]*\{", arg):
+            pointer = False
+        elif re.fullmatch(r"\w+", arg):
+            spans = _enclosing_spans(source, offset)
+            start = source.masked.rfind("func", 0, spans[-1][1]) if spans else 0
+            pointer = _declared_pointer(source, max(start, 0), offset, arg)
+            if pointer is None:
+                pointer = _declared_pointer(source, 0, offset, arg, top_level=True)
+        else:
+            continue
+        if pointer is False:
+            pass_.report(
+                source.line_at(offset),
+                function=function,
+                target=arg,
+                hint=f"pass a pointer: &{arg}" if arg != "nil" else "pass a pointer to a value",
+            )
+
+
+def _encoded_types(files: tuple[GoFile, ...]) -> set[str]:
+    """Struct types of the package that reach encoding/json, with the structs they contain."""
+    structs = {name: fields for file in files for name, fields in _structs(file)}
+    pending = [
+        t
+        for file in files
+        for offset, _, arg, _ in _json_calls(file)
+        if (t := _value_type(file, offset, arg)) in structs
+    ]
+    seen: set[str] = set()
+    while pending:
+        name = pending.pop()
+        if name in seen:
+            continue
+        seen.add(name)
+        for field in structs[name]:
+            inner = _TYPE_NAME_RE.search(field.type_)
+            if inner and inner.group(1) in structs and field.exported:
+                pending.append(inner.group(1))
+    return seen
+
+
+def detect_json_tag_missing(pass_: Pass) -> None:
+    """Detect exported fields without a ``json`` tag in structs the package encodes.
+
+    A struct counts when a value of its type is passed to ``json.Marshal``,
+    ``Unmarshal``, or an encoder's ``Encode``/``Decode`` anywhere in the
+    package, or is a field of such a struct. Types are known when the
+    argument is a composite literal, ``new(T)``, or a name declared in the
+    function with its type or a literal.
+    """
+    source = pass_.file
+    files = pass_.types.files if pass_.types is not None else (source,)
+    encoded = (
+        pass_.types.memo("jsontags:encoded", lambda: _encoded_types(files))
+        if pass_.types is not None
+        else _encoded_types(files)
+    )
+    for struct, fields in _structs(source):
+        if struct not in encoded:
+            continue
+        for field in fields:
+            if field.exported and field.tag is None:
+                pass_.report(source.line_at(field.offset), struct=struct, field=field.name)
+
+
+__all__ = [
+    "JSONField",
+    "JSON_OPTIONS",
+    "detect_json_tag_duplicate",
+    "detect_json_tag_invalid",
+    "detect_json_tag_missing",
+    "detect_json_tag_unexported",
+    "detect_json_unmarshal_non_pointer",
+    "parse_json_tag",
+]
@@ -0,0 +1,865 @@
+"""Go logic smells: control flow that is fixed, dead, or empty regardless of input."""
+
+from __future__ import annotations
+
+import re
+from collections.abc import Callable
+
+from desloppify.languages.go.detectors._inspector import GoFile, Pass, visits
+from desloppify.languages.go.detectors._source import go_comments, matching_brace
+
+_LEN_CALL_RE = re.compile(r"(?<![\w.])len\(")
+_COMPARISON_RE = re.compile(r"\s*(==|!=|<=|>=|<(?![-=])|>(?!=))\s*")
+_ZERO_RE = re.compile(r"0(?![\w.])")
+_ZERO_BEFORE_RE = re.compile(r"(?<![\w.])0\s*(==|!=|<=|>=|<|>)\s*$")
+_LOOKBEHIND = 16
+# Tokens that bind tighter than a comparison; an operand next to one of these
+# is part of a larger expression (`n + len(s) < 0`), not the comparison itself.
+_ARITHMETIC_TAIL_RE = re.compile(r"(?:[-+*/%^]|<<|>>|&\^|(?<!&)&|(?<!\|)\|)\s*$")
+_ARITHMETIC_HEAD_RE = re.compile(r"\s*(?:[-+*/%^.\[(]|<<|>>|&(?!&)|\|(?!\|))")
+
+# len(x) OP 0  /  0 OP len(x)  →  outcome
+_LEN_VS_ZERO = {"<": False, ">=": True}
+_ZERO_VS_LEN = {">": False, "<=": True}
+_SELF_COMPARISON = {
+    "==": True,
+    "<=": True,
+    ">=": True,
+    "!=": False,
+    "<": False,
+    ">": False,
+}
+
+
+def _len_call_end(masked: str, open_paren: int) -> int | None:
+    """Return the offset just past the ``)`` closing ``len(`` at ``open_paren``."""
+    depth = 0
+    for i in range(open_paren, len(masked)):
+        if masked[i] == "(":
+            depth += 1
+        elif masked[i] == ")":
+            depth -= 1
+            if depth == 0:
+                return i + 1
+        elif masked[i] == "\n":
+            return None
+    return None
+
+
+def _standalone(masked: str, start: int, end: int) -> bool:
+    """Whether masked[start:end] is a whole comparison operand."""
+    tail = masked[max(0, start - _LOOKBEHIND) : start].rstrip()[-2:]
+    return not _ARITHMETIC_TAIL_RE.search(tail) and not _ARITHMETIC_HEAD_RE.match(
+        masked, end
+    )
+
+
+def _comparison_outcome(
+    masked: str, content: str, m: re.Match
+) -> tuple[int, bool] | None:
+    """Return (offset, outcome) when the comparison around ``m`` is constant."""
+    start = m.start()
+    end = _len_call_end(masked, m.end() - 1)
+    if end is None:
+        return None
+    arg = content[m.end() : end - 1].strip()
+
+    before = _ZERO_BEFORE_RE.search(masked, max(0, start - _LOOKBEHIND), start)
+    if before and before.group(1) in _ZERO_VS_LEN:
+        if _standalone(masked, before.start(), end):
+            return before.start(), _ZERO_VS_LEN[before.group(1)]
+        return None
+
+    op = _COMPARISON_RE.match(masked, end)
+    if op is None:
+        return None
+    rhs = op.end()
+    if op.group(1) in _LEN_VS_ZERO and _ZERO_RE.match(masked, rhs):
+        if _standalone(masked, start, rhs + 1):
+            return start, _LEN_VS_ZERO[op.group(1)]
+        return None
+
+    other = _LEN_CALL_RE.match(masked, rhs)
+    if other is None or "(" in arg:
+        return None
+    other_end = _len_call_end(masked, other.end() - 1)
+    if other_end is None or not _standalone(masked, start, other_end):
+        return None
+    if content[other.end() : other_end - 1].strip() != arg:
+        return None
+    return start, _SELF_COMPARISON[op.group(1)]
+
+
+def detect_len_comparison(pass_: Pass) -> None:
+    """Detect len() comparisons that are always true or always false."""
+    source = pass_.file
+    seen: set[int] = set()
+    for m in _LEN_CALL_RE.finditer(source.masked):
+        outcome = _comparison_outcome(source.masked, source.content, m)
+        if outcome is None:
+            continue
+        pos, always = outcome
+        line = source.line_at(pos)
+        if line in seen:
+            continue
+        seen.add(line)
+        pass_.report(line, always="true" if always else "false")
+
+
+# What may precede a checked `if`/`for` on its line: `if cond {`,
+# `} else if cond {`, `for cond {`.
+_CONDITION_LEAD_RE = re.compile(r"[ \t]*(?:\}[ \t]*else[ \t]+)?")
+_BOOL_LITERALS = {"true": True, "false": False}
+_SIMPLE_OPERAND_RE = re.compile(r"[A-Za-z_][\w.]*(?:\[[\w.]+\])?|\d+")
+_EQUALITY_RE = re.compile(r"^(.+?)\s*(==|!=)\s*(.+)$")
+# Types whose every value equals itself: not floats or complex numbers.
+_REFLEXIVE_TYPE_RE = re.compile(r"u?int(?:8|16|32|64)?|uintptr|byte|rune|string|bool|\*.+")
+_NEGATED_EQUALITY = {"==": "!=", "!=": "=="}
+
+
+def _condition_span(masked: str, start: int) -> tuple[int, int] | None:
+    """Return the (start, end) of the header text before its opening ``{``."""
+    depth = 0
+    for i in range(start, len(masked)):
+        ch = masked[i]
+        if ch in "([":
+            depth += 1
+        elif ch in ")]":
+            depth -= 1
+        elif ch == "{" and depth == 0:
+            return start, i
+        elif ch == "}" and depth == 0:
+            return None
+    return None
+
+
+def _split_top_level(masked: str, start: int, end: int, sep: str) -> list[tuple[int, int]]:
+    """Split masked[start:end] on ``sep`` outside brackets; returns spans."""
+    spans: list[tuple[int, int]] = []
+    depth = 0
+    begin = start
+    i = start
+    while i < end:
+        ch = masked[i]
+        if ch in "([{":
+            depth += 1
+        elif ch in ")]}":
+            depth -= 1
+        elif depth == 0 and masked.startswith(sep, i):
+            spans.append((begin, i))
+            i += len(sep)
+            begin = i
+            continue
+        i += 1
+    spans.append((begin, end))
+    return spans
+
+
+def _strip_parens(expr: str) -> str:
+    expr = expr.strip()
+    while expr.startswith("(") and expr.endswith(")"):
+        depth = 0
+        for i, ch in enumerate(expr):
+            depth += ch == "("
+            depth -= ch == ")"
+            if depth == 0 and i < len(expr) - 1:
+                return expr
+        expr = expr[1:-1].strip()
+    return expr
+
+
+def _normalize(expr: str) -> str:
+    return re.sub(r"\s+", "", _strip_parens(expr))
+
+
+def _literal(expr: str) -> bool | None:
+    expr = _normalize(expr)
+    negated = False
+    while expr.startswith("!"):
+        negated = not negated
+        expr = _normalize(expr[1:])
+    value = _BOOL_LITERALS.get(expr)
+    return None if value is None else value != negated
+
+
+def _complement(expr: str) -> str | None:
+    """Textual negation of a side-effect-free operand (``a`` ↔ ``!a``)."""
+    expr = _normalize(expr)
+    if "(" in expr:
+        return None
+    if expr.startswith("!"):
+        return _normalize(expr[1:])
+    m = _EQUALITY_RE.match(expr)
+    if m:
+        return f"{m.group(1)}{_NEGATED_EQUALITY[m.group(2)]}{m.group(3)}"
+    return f"!{expr}"
+
+
+def _self_comparison(expr: str, operand_type: Callable[[str], str | None]) -> bool | None:
+    m = _EQUALITY_RE.match(_normalize(expr))
+    if m is None or m.group(1) != m.group(3):
+        return None
+    if not _SIMPLE_OPERAND_RE.fullmatch(m.group(1)):
+        return None
+    # NaN is unequal to itself (`f != f` is the NaN test), so only operands
+    # go/types says are not floating point are folded.
+    if not m.group(1).isdigit() and not _REFLEXIVE_TYPE_RE.fullmatch(
+        operand_type(m.group(1)) or ""
+    ):
+        return None
+    return m.group(2) == "=="
+
+
+def _has_complement_pair(operands: list[str]) -> bool:
+    normalized = {_normalize(op) for op in operands}
+    return any(_complement(op) in normalized for op in operands)
+
+
+def _constant_value(
+    content: str,
+    masked: str,
+    start: int,
+    end: int,
+    operand_type: Callable[[str], str | None],
+) -> bool | None:
+    """Fold the condition at content[start:end] to a constant if possible."""
+    text = content[start:end]
+    value = _literal(text)
+    if value is None:
+        value = _self_comparison(text, operand_type)
+    if value is not None:
+        return value
+    disjuncts = [content[a:b] for a, b in _split_top_level(masked, start, end, "||")]
+    if len(disjuncts) > 1:
+        if any(_literal(d) is True for d in disjuncts) or _has_complement_pair(disjuncts):
+            return True
+        return None
+    conjuncts = [content[a:b] for a, b in _split_top_level(masked, start, end, "&&")]
+    if len(conjuncts) > 1:
+        if any(_literal(c) is False for c in conjuncts) or _has_complement_pair(conjuncts):
+            return False
+    return None
+
+
+@visits("if", "for")
+def visit_constant_condition(pass_: Pass, kind: str, offset: int) -> None:
+    """Detect if/for conditions that always evaluate to true or false.
+
+    ``x == x`` and ``x != x`` only count when go/types gives ``x`` a type
+    that is not floating point: for a float, ``x != x`` tests for NaN.
+    """
+    source = pass_.file
+    masked = source.masked
+    lead = source.line_start(offset)
+    if _CONDITION_LEAD_RE.match(masked, lead).end() != offset:
+        return
+    span = _condition_span(masked, offset + len(kind))
+    if span is None:
+        return
+    clauses = _split_top_level(masked, *span, ";")
+    if kind == "for":
+        if len(clauses) == 3:
+            clauses = clauses[1:2]
+        elif len(clauses) != 1 or re.search(r"\brange\b", masked[span[0] : span[1]]):
+            return
+    start, end = clauses[-1]
+    if not masked[start:end].strip():
+        return
+
+    def operand_type(operand: str) -> str | None:
+        m = re.compile(rf"(?<![\w.]){re.escape(operand)}(?![\w.\[])").search(masked, start, end)
+        return pass_.type_of(m.start(), m.end()) if m is not None else None
+
+    value = _constant_value(source.content, masked, start, end, operand_type)
+    if value is None:
+        return
+    pass_.report(source.line_at(offset), always="true" if value else "false")
+
+
+# Statements that never fall through to the next one in their block.
+_TERMINATOR_RE = re.compile(
+    r"^[ \t]*(?:(return)\b|(panic|os\.Exit|log\.(?:Fatal|Panic)(?:f|ln)?)\s*\()",
+    re.MULTILINE,
+)
+_INFINITE_LOOP_RE = re.compile(r"^[ \t]*for[ \t]*\{", re.MULTILINE)
+_CONTINUATION_CHARS = frozenset(",+-*/%&|^<>=!.:")
+# The compiler does not know os.Exit/log.Fatal never return, so a trailing
+# return or panic after them is required, not dead.
+_NON_TERMINATING_TO_COMPILER = frozenset(
+    {"os.Exit", "log.Fatal", "log.Fatalf", "log.Fatalln"}
+)
+_LOOP_ESCAPE_RE = re.compile(r"\b(?:break|goto)\b")
+# What may legitimately follow a terminator: the block end, the next case
+# clause, or a label that goto can jump to.
+_REACHABLE_NEXT_RE = re.compile(r"\}|(?:case|default)\b|\w+[ \t]*:(?!=)")
+
+
+def _statement_end(masked: str, start: int) -> int:
+    """Offset just past the simple statement starting at ``start``."""
+    depth = 0
+    i = start
+    while i < len(masked):
+        ch = masked[i]
+        if ch == "`":
+            # Raw strings may span lines; their (masked) body is never a break.
+            close = masked.find("`", i + 1)
+            i = len(masked) if close == -1 else close + 1
+            continue
+        if ch in "([{":
+            depth += 1
+        elif ch in ")]}":
+            depth -= 1
+            if depth < 0:
+                return i
+        elif depth == 0 and ch == ";":
+            return i
+        elif depth == 0 and ch == "\n" and not _continues(masked, start, i):
+            return i
+        i += 1
+    return i
+
+
+def _continues(masked: str, start: int, newline: int) -> bool:
+    """Whether the line ending at ``newline`` continues onto the next one.
+
+    Mirrors Go's semicolon insertion: a trailing binary operator or comma
+    keeps the statement open.
+    """
+    tail = masked[start:newline].rstrip()
+    if not tail or tail.endswith(("++", "--")):
+        return False
+    return tail[-1] in _CONTINUATION_CHARS
+
+
+def _next_statement(masked: str, pos: int) -> int | None:
+    """Offset of the next statement after ``pos`` if it can never run."""
+    m = re.compile(r"[\s;]*").match(masked, pos)
+    nxt = m.end()
+    if nxt >= len(masked) or _REACHABLE_NEXT_RE.match(masked, nxt):
+        return None
+    return nxt
+
+
+def _terminators(masked: str) -> list[tuple[str, int]]:
+    found: list[tuple[str, int]] = []
+    for m in _TERMINATOR_RE.finditer(masked):
+        kind = m.group(1) or m.group(2)
+        # Call terminators match through their "("; restart there so it nests.
+        start = m.end() if m.group(1) else m.end() - 1
+        found.append((kind, _statement_end(masked, start)))
+    for m in _INFINITE_LOOP_RE.finditer(masked):
+        close = matching_brace(masked, m.end() - 1)
+        if close is None or _LOOP_ESCAPE_RE.search(masked, m.end(), close):
+            continue
+        found.append(("for {}", close + 1))
+    return found
+
+
+def detect_unreachable_code(pass_: Pass) -> None:
+    """Detect statements that follow return/panic/os.Exit/log.Fatal or an endless loop."""
+    source = pass_.file
+    masked = source.masked
+    for kind, end in sorted(_terminators(masked), key=lambda t: t[1]):
+        nxt = _next_statement(masked, end)
+        if nxt is None:
+            continue
+        if kind in _NON_TERMINATING_TO_COMPILER and _TERMINATOR_RE.match(
+            masked, masked.rfind("\n", 0, nxt) + 1
+        ):
+            continue
+        pass_.report(source.line_at(nxt), after=kind)
+
+
+_SWITCH_RE = re.compile(r"\bswitch\b")
+_CASE_RE = re.compile(r"\bcase\b")
+_CHAIN_START_RE = re.compile(r"(?<!else)[ \t]\bif\b|^[ \t]*if\b", re.MULTILINE)
+_ELSE_IF_RE = re.compile(r"\s*else\s+if\b")
+# Calls other than these builtins may have side effects, so two textually
+# equal calls are not necessarily the same value.
+_PURE_CALL_RE = re.compile(r"(?<![\w.])(?:len|cap)\(")
+_LITERAL_TOKEN_RE = re.compile(r'"(?:\\.|[^"\\\n])*"|`[^`]*`|\'(?:\\.|[^\'\\\n])*\'')
+# Literals are kept verbatim; everything else is compared token by token.
+_TOKEN_RE = re.compile(_LITERAL_TOKEN_RE.pattern + r"|[^\s\"`']+")
+
+
+def _comparable(text: str) -> str | None:
+    """Whitespace-normalized expression text, or None if it may differ per call."""
+    if "(" in _PURE_CALL_RE.sub("", _LITERAL_TOKEN_RE.sub('""', text)):
+        return None
+    normalized = " ".join(m.group(0) for m in _TOKEN_RE.finditer(text))
+    return normalized or None
+
+
+def _case_clauses(masked: str, open_brace: int, close: int) -> list[tuple[int, int]]:
+    """Spans of the expression lists of a switch body's own ``case`` clauses."""
+    clauses: list[tuple[int, int]] = []
+    depth = 0
+    i = open_brace + 1
+    while i < close:
+        ch = masked[i]
+        if ch in "([{":
+            depth += 1
+        elif ch in ")]}":
+            depth -= 1
+        elif depth == 0 and (m := _CASE_RE.match(masked, i)) and not (
+            masked[i - 1].isalnum() or masked[i - 1] == "_"
+        ):
+            start = m.end()
+            inner = 0
+            j = start
+            while j < close and not (masked[j] == ":" and inner == 0):
+                if masked[j] in "([{":
+                    inner += 1
+                elif masked[j] in ")]}":
+                    inner -= 1
+                j += 1
+            clauses.append((start, j))
+            i = j
+        i += 1
+    return clauses
+
+
+def _duplicate_cases(masked: str, content: str) -> list[tuple[int, int]]:
+    """(duplicate offset, first offset) pairs for repeated case expressions."""
+    found: list[tuple[int, int]] = []
+    for m in _SWITCH_RE.finditer(masked):
+        span = _condition_span(masked, m.end())
+        if span is None:
+            continue
+        open_brace = span[1]
+        close = matching_brace(masked, open_brace)
+        if close is None:
+            continue
+        seen: dict[str, int] = {}
+        for start, end in _case_clauses(masked, open_brace, close):
+            for a, b in _split_top_level(masked, start, end, ","):
+                key = _comparable(content[a:b])
+                if key is None:
+                    continue
+                offset = a + len(content[a:b]) - len(content[a:b].lstrip())
+                if key in seen:
+                    found.append((offset, seen[key]))
+                else:
+                    seen[key] = offset
+    return found
+
+
+def _duplicate_conditions(masked: str, content: str) -> list[tuple[int, int]]:
+    """(duplicate offset, first offset) pairs for repeated if/else-if conditions."""
+    found: list[tuple[int, int]] = []
+    for m in _CHAIN_START_RE.finditer(masked):
+        header = masked.rfind("if", m.start(), m.end())
+        seen: dict[str, int] = {}
+        pos = header + 2
+        while True:
+            span = _condition_span(masked, pos)
+            if span is None:
+                break
+            key = _comparable(content[span[0] : span[1]])
+            offset = header
+            if key is not None:
+                if key in seen:
+                    found.append((offset, seen[key]))
+                else:
+                    seen[key] = offset
+            close = matching_brace(masked, span[1])
+            if close is None:
+                break
+            nxt = _ELSE_IF_RE.match(masked, close + 1)
+            if nxt is None:
+                break
+            header = nxt.end() - 2
+            pos = nxt.end()
+    return found
+
+
+def detect_duplicate_branch(pass_: Pass) -> None:
+    """Detect repeated switch case values and repeated if/else-if conditions."""
+    source = pass_.file
+    masked, content = source.masked, source.content
+    pairs = _duplicate_cases(masked, content) + _duplicate_conditions(masked, content)
+    for offset, first in sorted(pairs):
+        pass_.report(source.line_at(offset), first_line=source.line_at(first))
+
+
+_BREAK_RE = re.compile(r"\bbreak\b(?=[ \t]*(?:[;}\n]|$))")
+_BLOCK_KIND_RE = re.compile(r"(?:\w+[ \t]*:[ \t]*)?(for|switch|select|if|else)\b")
+_BARE_FOR_RE = re.compile(r"(?:\w+[ \t]*:[ \t]*)?for[ \t]*$")
+_CLAUSE_END_RE = re.compile(r"[\s;]*(?:\}|(?:case|default)\b)")
+_DEFAULT_RE = re.compile(r"^[ \t]*default[ \t]*:", re.MULTILINE)
+# Names of conditions and channels after which the loop, not just the case,
+# is done: `err == io.EOF`, `<-ctx.Done()`, `if isClosed {`.
+_TERMINATING_RE = re.compile(
+    r"(?:(?<![\w.])(?:is|Is)?|(?<=\.))(?:[Dd]one|[Ss]top(?:ped)?|[Qq]uit|[Ff]inished"
+    r"|[Ee]xit(?:ed)?|[Cc]losed|EOF)(?!\w)"
+)
+
+
+def _block_kind(header: str) -> str | None:
+    """``for``/``switch``/``select``/``if``/``else``/``func`` for a block's header, or None."""
+    header = header[header.rfind("{") + 1 :].lstrip(" \t}")
+    if re.search(r"\bfunc\b", header):
+        return "func"
+    m = _BLOCK_KIND_RE.match(header)
+    return m.group(1) if m is not None else None
+
+
+def _clause_header(source: GoFile, open_brace: int, pos: int) -> str:
+    """The expressions of the ``case`` holding ``pos``; empty for ``default``."""
+    masked = source.masked
+    close = matching_brace(masked, open_brace) or len(masked)
+    before = [span for span in _case_clauses(masked, open_brace, close) if span[1] < pos]
+    if not before or _DEFAULT_RE.search(masked, before[-1][1], pos):
+        return ""
+    start, end = before[-1]
+    return source.content[start:end]
+
+
+def _ineffective_break(source: GoFile, pos: int) -> tuple[str, str] | None:
+    """(statement, reason) when the break at ``pos`` leaves a switch/select inside a loop."""
+    masked = source.masked
+    statement = None
+    inner: list[tuple[str, str]] = []
+    for brace in source.enclosing_blocks(pos):
+        header = masked[source.line_start(brace) : brace]
+        kind = _block_kind(header)
+        if kind in (None, "func"):
+            return None
+        if statement is None:
+            if kind == "for":
+                return None  # the break leaves the loop, as intended
+            if kind in ("switch", "select"):
+                statement, target = kind, brace
+            else:
+                inner.append((kind, header))
+        elif kind == "for":
+            loop = header[header.rfind("{") + 1 :].strip()
+            break
+    else:
+        return None
+    checks = [header for kind, header in inner[:1] if kind == "if"]
+    checks.append(_clause_header(source, target, pos))
+    if any(_TERMINATING_RE.search(check) for check in checks):
+        return statement, "terminating_check"
+    if not inner and _BARE_FOR_RE.match(loop) and _CLAUSE_END_RE.match(masked, pos + 5):
+        return statement, "clause_end"
+    return None
+
+
+def detect_ineffective_break(pass_: Pass) -> None:
+    """Detect unlabeled breaks that leave a switch/select when the loop around it looks meant.
+
+    Reported with the ``statement`` (``switch`` or ``select``) and the
+    ``reason``: ``terminating_check``, a break under a case or ``if`` such
+    as ``<-ctx.Done()`` or ``err == io.EOF``, or ``clause_end``, one that
+    ends its case in a ``for {}`` loop, where it does nothing.
+    """
+    source = pass_.file
+    for m in _BREAK_RE.finditer(source.masked):
+        found = _ineffective_break(source, m.start())
+        if found is None:
+            continue
+        statement, reason = found
+        pass_.report(
+            source.line_at(m.start()),
+            statement=statement,
+            reason=reason,
+            hint=f"this break leaves the {statement}, not the loop around it; label the "
+            "loop and break the label, or drop the break if the case is done",
+        )
+
+
+_CONTINUE_RE = re.compile(r"\bcontinue\b(?=[ \t]*(?:[;}\n]|$))")
+_NEXT_TOKEN_RE = re.compile(r"[\s;]*")
+_ELSE_NEXT_RE = re.compile(r"[ \t]*else\b")
+_LEAVES_RE = re.compile(r"^[ \t]*(?:return|goto)\b|^[ \t]*panic\(", re.MULTILINE)
+
+
+def _clause_leaves(source: GoFile, open_brace: int, close: int) -> bool:
+    """Whether a case of the switch/select at ``open_brace`` ends in return, goto or panic.
+
+    Next to those, a ``continue`` in another case says "loop again" on purpose.
+    """
+    for m in _LEAVES_RE.finditer(source.masked, open_brace, close):
+        if source.enclosing_blocks(m.end())[0] == open_brace:
+            return True
+    return False
+
+
+def _ends_loop_body(source: GoFile, pos: int) -> bool:
+    """Whether nothing runs between ``pos`` and the end of the innermost loop's body."""
+    masked = source.masked
+    while True:
+        pos = _NEXT_TOKEN_RE.match(masked, pos).end()
+        if masked.startswith("}", pos):
+            brace = source.block_open(pos)
+        else:
+            enclosing = source.enclosing_blocks(pos)
+            brace = enclosing[0] if enclosing else None
+        if brace is None:
+            return False
+        kind = _block_kind(masked[source.line_start(brace) : brace])
+        if kind == "for" and masked.startswith("}", pos):
+            return True
+        if kind in ("switch", "select") and _CLAUSE_END_RE.match(masked, pos):
+            pos = matching_brace(masked, brace)
+            if pos is None or _clause_leaves(source, brace, pos):
+                return False
+        elif kind not in ("if", "else") or not masked.startswith("}", pos):
+            return False
+        if pos is None:
+            return False
+        pos += 1
+        # Skip the rest of an if/else chain: only one branch runs.
+        while _ELSE_NEXT_RE.match(masked, pos):
+            open_ = masked.find("{", pos)
+            close = matching_brace(masked, open_) if open_ != -1 else None
+            if close is None:
+                return False
+            pos = close + 1
+
+
+def detect_redundant_continue(pass_: Pass) -> None:
+    """Detect an unlabeled ``continue`` with nothing after it in the loop body.
+
+    A ``continue`` in a switch or select case next to cases that return is
+    left alone: there it marks the case that loops again.
+    """
+    source = pass_.file
+    for m in _CONTINUE_RE.finditer(source.masked):
+        if _ends_loop_body(source, m.end()):
+            pass_.report(
+                source.line_at(m.start()),
+                hint="the loop body ends here anyway, so this continue does nothing; "
+                "remove it",
+            )
+
+_ELSE_BRACE_RE = re.compile(r"[ \t]*\{")
+_RANGE_VARS_RE = re.compile(r"^.*?(?::=|=)\s*range\b", re.DOTALL)
+
+
+def _block_brace(masked: str, start: int) -> int | None:
+    """Offset of the ``{`` opening the block after a header starting at ``start``.
+
+    gofmt puts a space before a block's brace; a ``{`` glued to the previous
+    token (``[32]byte{}``) opens a composite literal and is skipped.
+    """
+    pos = start
+    while (span := _condition_span(masked, pos)) is not None:
+        brace = span[1]
+        if masked[brace - 1] in " \t":
+            return brace
+        close = matching_brace(masked, brace)
+        if close is None:
+            return None
+        pos = close + 1
+    return None
+
+
+def _empty_loop_is_idle(header: str) -> bool:
+    """Whether an empty-bodied ``for`` header does no work of its own.
+
+    Calls, receives, and post statements (``for ; p < n && ok(p); p++ {}``)
+    give an empty body a purpose, as does ``for range ch {}`` draining a
+    channel; a range that binds variables it never uses does not.
+    """
+    if re.search(r"\brange\b", header):
+        return bool(_RANGE_VARS_RE.match(header))
+    clauses = header.split(";")
+    if len(clauses) == 3 and clauses[2].strip():
+        return False
+    return "(" not in clauses[-2 if len(clauses) == 3 else 0] and "<-" not in header
+
+
+@visits("if", "else", "for", "switch")
+def visit_empty_branch(pass_: Pass, kind: str, offset: int) -> None:
+    """Detect if/else/for/switch blocks with nothing (not even a comment) inside.
+
+    A bare ``for {}`` blocks forever on purpose and is left alone.
+    """
+    source = pass_.file
+    masked = source.masked
+    end = offset + len(kind)
+    if kind == "else":
+        m = _ELSE_BRACE_RE.match(masked, end)
+        open_brace = m.end() - 1 if m else None
+    else:
+        open_brace = _block_brace(masked, end)
+    if open_brace is None:
+        return
+    close = matching_brace(masked, open_brace)
+    if close is None or source.content[open_brace + 1 : close].strip():
+        return
+    header = masked[end:open_brace]
+    if kind == "for" and (not header.strip() or not _empty_loop_is_idle(header)):
+        return
+    pass_.report(source.line_at(offset), kind=kind)
+
+
+_PLAIN_IF_RE = re.compile(r"[ \t]*if\b[^;]*")
+_ENDS_IN_RETURN_RE = re.compile(r"(?:^|\n)[ \t]*return\b[^\n]*$")
+_DECLARATION_RE = re.compile(r":=|^[ \t]*(?:var|const|type)\b", re.MULTILINE)
+
+
+def _top_level(masked: str, open_brace: int, close: int) -> str:
+    """A block's own text with every nested block blanked out."""
+    out: list[str] = []
+    depth = 0
+    for ch in masked[open_brace + 1 : close]:
+        if ch == "{":
+            depth += 1
+        elif ch == "}":
+            depth -= 1
+        out.append(ch if depth == 0 or ch == "\n" else " ")
+    return "".join(out)
+
+
+def _outdent_fix(source: GoFile, if_close: int, else_open: int) -> dict | None:
+    """Edit turning ``} else {`` + body + ``}`` into ``}`` + the body, one tab out.
+
+    Refused when the body declares names (they would move into the
+    enclosing scope), holds a raw string (outdenting would change it), the
+    braces do not sit at the ends of their lines, or a comment sits between
+    ``}`` and ``else`` (it would be dropped).
+    """
+    masked, content = source.masked, source.content
+    else_close = matching_brace(masked, else_open)
+    if else_close is None or content[if_close + 1 : else_open].strip() != "else":
+        return None
+    body = content[else_open + 1 : else_close]
+    lines = body.split("\n")
+    if (
+        len(lines) < 3
+        or lines[0].strip()
+        or lines[-1].strip()
+        or "`" in body
+        or _DECLARATION_RE.search(_top_level(masked, else_open, else_close))
+    ):
+        return None
+    outdented = [line[1:] if line.startswith("\t") else line for line in lines[1:-1]]
+    return {
+        "title": "Drop the else and outdent its body",
+        "line": source.line_at(if_close),
+        "old": content[if_close : else_close + 1],
+        "new": "}\n" + "\n".join(outdented),
+    }
+
+
+@visits("else")
+def visit_else_after_return(pass_: Pass, kind: str, offset: int) -> None:
+    """Detect ``else`` after an if block whose last statement is ``return``.
+
+    Only a plain ``if cond {`` is reported: after ``else if`` or an
+    ``if x := f(); ...`` header the else is still needed.
+    """
+    source = pass_.file
+    masked = source.masked
+    m = _ELSE_BRACE_RE.match(masked, offset + len(kind))
+    if_close = masked.rfind("}", 0, offset)
+    if m is None or if_close < 0 or masked[if_close + 1 : offset].strip():
+        return
+    if_open = source.block_open(if_close)
+    if if_open is None or not _PLAIN_IF_RE.fullmatch(
+        masked, source.line_start(if_open), if_open
+    ):
+        return
+    if not _ENDS_IN_RETURN_RE.search(masked[if_open + 1 : if_close].rstrip()):
+        return
+    entry = pass_.report(source.line_at(offset))
+    fix = _outdent_fix(source, if_close, m.end() - 1)
+    if fix is not None:
+        entry["fix"] = fix
+
+
+_RETURN_BOOL_RE = re.compile(r"\s*return[ \t]+(true|false)\s*")
+_ELSE_BLOCK_RE = re.compile(r"[ \t]*else[ \t]*\{")
+_TRAILING_RETURN_BOOL_RE = re.compile(r"[ \t]*\n[ \t]*return[ \t]+(true|false)[ \t]*(?=\n|$)")
+_COMPARISON_OPS = ("==", "!=", "<=", ">=", "<", ">")
+
+
+def _negated(masked: str, content: str, start: int, end: int) -> str:
+    """Source text for ``!(cond)``, simplified where that is exact."""
+    text = content[start:end]
+    if len(_split_top_level(masked, start, end, "||")) > 1 or len(
+        _split_top_level(masked, start, end, "&&")
+    ) > 1:
+        return f"!({text})"
+    equality = _split_top_level(masked, start, end, "==")
+    inequality = _split_top_level(masked, start, end, "!=")
+    if len(equality) + len(inequality) == 3 and not any(
+        op in masked[start:end] for op in ("<", ">")
+    ):
+        (a, b), (c, d) = equality if len(equality) == 2 else inequality
+        op = "!=" if len(equality) == 2 else "=="
+        return f"{content[a:b].rstrip()} {op} {content[c:d].lstrip()}"
+    if text.startswith("!") and _SIMPLE_OPERAND_RE.fullmatch(text[1:]):
+        return text[1:]
+    if _SIMPLE_OPERAND_RE.fullmatch(text) or re.fullmatch(r"[\w.]+\([^()]*\)", text):
+        return f"!{text}"
+    return f"!({text})"
+
+
+@visits("if")
+def visit_bool_literal_return(pass_: Pass, kind: str, offset: int) -> None:
+    """Detect ``if c { return true }`` followed by ``return false`` (or the reverse).
+
+    The else form (``} else { return false }``) counts too; both become
+    ``return c`` (or its negation). A comment on a line of its own means
+    the branches are deliberate and nothing is reported; a trailing comment
+    only withholds the fix, since the rewrite would drop it.
+    """
+    source = pass_.file
+    masked, content = source.masked, source.content
+    if masked[source.line_start(offset) : offset].strip():
+        return
+    span = _condition_span(masked, offset + len(kind))
+    if span is None or ";" in masked[span[0] : span[1]]:
+        return
+    open_brace = span[1]
+    close = matching_brace(masked, open_brace)
+    if close is None:
+        return
+    then = _RETURN_BOOL_RE.fullmatch(masked, open_brace + 1, close)
+    if then is None:
+        return
+    otherwise = None
+    if (m := _ELSE_BLOCK_RE.match(masked, close + 1)) is not None:
+        else_close = matching_brace(masked, m.end() - 1)
+        if else_close is not None:
+            otherwise = _RETURN_BOOL_RE.fullmatch(masked, m.end(), else_close)
+            end = else_close + 1
+    else:
+        otherwise = _TRAILING_RETURN_BOOL_RE.match(masked, close + 1)
+        end = otherwise.end() if otherwise else close
+    if otherwise is None or otherwise.group(1) == then.group(1):
+        return
+    if any(line.lstrip().startswith(("//", "/*")) for line in content[offset:end].splitlines()):
+        return
+    entry = pass_.report(source.line_at(offset))
+    header = content[span[0] : span[1]]
+    start = span[0] + len(header) - len(header.lstrip())
+    stop = span[0] + len(header.rstrip())
+    if not go_comments(content[offset:end]):
+        if then.group(1) == "true":
+            value = content[start:stop]
+        else:
+            value = _negated(masked, content, start, stop)
+        entry["fix"] = {
+            "title": f"Replace with return {value}",
+            "line": source.line_at(offset),
+            "old": content[offset:end],
+            "new": f"return {value}",
+        }
+
+
+__all__ = [
+    "detect_duplicate_branch",
+    "detect_ineffective_break",
+    "detect_len_comparison",
+    "detect_redundant_continue",
+    "detect_unreachable_code",
+    "visit_bool_literal_return",
+    "visit_constant_condition",
+    "visit_else_after_return",
+    "visit_empty_branch",
+]
@@ -0,0 +1,255 @@
+"""Loops that cannot loop.
+
+Refactors leave loops behind whose shape says "repeat" while their body
+says otherwise. Two rules cover three shapes:
+
+- ``loop_runs_once``: the body's last statement always leaves the loop
+  (``return``, ``break``, ``panic``, ``os.Exit``, ``log.Fatal``, or an
+  ``if``/``else`` chain whose every branch does) and no ``continue``
+  reaches the loop, so the body never runs a second time. Reported for a
+  bare ``for {}``, where the loop is a block in disguise (a single-pass
+  ``for { ... break }`` used as a goto is reported on purpose), and for a
+  loop with a header, where the condition and post statement run once and
+  the loop is an ``if``. A ``range`` loop is reported only when
+  ``go/types`` says it ranges over a slice, array or string: taking one
+  element of a map or channel this way is the idiom.
+- ``loop_condition_unchanged``: ``for cond {}`` (or a three-clause loop
+  with no post statement) whose condition reads only local variables,
+  ``len`` and ``cap``, with no receive or pointer dereference, none of
+  which the body assigns, increments, takes the address of, passes to a
+  call or calls a method on, and whose body has no way out (``return``,
+  ``break``, ``goto``, ``panic``). The loop then runs zero times or
+  forever.
+
+Generated files are skipped: generators such as the compiler's rewrite
+rules emit single-pass ``for`` blocks by design.
+"""
+
+from __future__ import annotations
+
+import re
+
+from desloppify.languages.go.detectors._inspector import GoFile, Pass, visits
+from desloppify.languages.go.detectors._source import matching_brace
+from desloppify.languages.go.detectors.assignments import _binding, _Var, function_variables
+from desloppify.languages.go.detectors.concurrency import _statements
+from desloppify.languages.go.detectors.logic import _block_brace, _block_kind, _split_top_level
+from desloppify.languages.go.detectors.naming import _GENERATED_RE
+
+_LABEL_RE = re.compile(r"(\w+)[ \t]*:\s*$")
+_EXIT_RE = re.compile(
+    r"(?:(return|break)\b|(panic|os\.Exit|log\.(?:Fatal|Panic)(?:f|ln)?)[ \t]*\()"
+)
+_IF_RE = re.compile(r"if\b")
+_HEADER_RE = re.compile(r"(?:\}[ \t]*else[ \t]+)?(?:if|for|switch|select)\b")
+_ELSE_RE = re.compile(r"[ \t]*else[ \t]*(if\b|\{)")
+_CONTINUE_RE = re.compile(r"\bcontinue\b(?:[ \t]+(\w+))?")
+_GOTO_RE = re.compile(r"(?<![\w.])goto\b")
+_RANGE_RE = re.compile(r"\brange\b[ \t]*")
+_LEAVES_RE = re.compile(
+    r"(?<![\w.])(?:return|break|goto)\b"
+    r"|(?<![\w.])(?:panic|os\.Exit|log\.(?:Fatal|Panic)(?:f|ln)?)\("
+)
+_CONDITION_NAME_RE = re.compile(r"(?<![\w.])([A-Za-z_]\w*)")
+_CALL_AFTER_RE = re.compile(r"(?:\.\w+)*[ \t]*\(")
+# After a variable: writes to or through it (`x.n = 1`, `x[i]++`, `x, y.ok = f()`)
+# or calls on it (`x.next()`).
+_CHANGED_AFTER_RE = re.compile(
+    r"(?:\.\w+|\[[^\]\n]*\])*[ \t]*(?:(?:[-+*/%&|^]|<<|>>|&\^)?=(?!=)|\+\+|--)"
+    r"|(?:[ \t]*,[ \t]*[\w.\[\]*]+)+[ \t]*:?=(?!=)"
+    r"|(?:\.\w+|\[[^\]\n]*\])*\.\w+\("
+)
+_ARGUMENT_BEFORE_RE = re.compile(r"[(,][ \t]*$")
+
+
+def _leaves(masked: str, open_brace: int, close: int) -> str | None:
+    """How the block at ``open_brace`` always leaves the loop around it, or None."""
+    spans = _statements(masked, open_brace + 1, close)
+    if not spans:
+        return None
+    start, end = spans[-1]
+    start += len(masked[start:end]) - len(masked[start:end].lstrip())
+    m = _EXIT_RE.match(masked, start)
+    if m is not None:
+        return m.group(1) or m.group(2)
+    if masked.startswith("{", start):
+        if len(spans) > 1 and _HEADER_RE.match(masked[spans[-2][0] : spans[-2][1]].lstrip()):
+            return None  # the body of `switch x := f(); {`, split at its `;`
+        inner = matching_brace(masked, start)
+        return _leaves(masked, start, inner) if inner is not None else None
+    if not _IF_RE.match(masked, start):
+        return None
+    # Every branch of an if/else chain, ending in a plain else, has to leave.
+    pos = start
+    while True:
+        brace = _block_brace(masked, pos + 2)
+        inner = matching_brace(masked, brace) if brace is not None else None
+        if inner is None:
+            return None
+        exit_ = _leaves(masked, brace, inner)
+        if exit_ is None:
+            return None
+        m = _ELSE_RE.match(masked, inner + 1)
+        if m is None:
+            return None
+        if m.group(1) == "{":
+            else_close = matching_brace(masked, m.end() - 1)
+            return _leaves(masked, m.end() - 1, else_close) if else_close is not None else None
+        pos = m.start(1)
+
+
+def _continues(pass_: Pass, body: int, close: int, label: str | None) -> bool:
+    """Whether a ``continue`` in the body at ``body`` starts another pass of this loop."""
+    source = pass_.file
+    masked = source.masked
+    for m in _CONTINUE_RE.finditer(masked, body, close):
+        if m.group(1) is not None:
+            if m.group(1) == label:
+                return True
+            continue
+        for brace in source.enclosing_blocks(m.start()):
+            kind = _block_kind(masked[source.line_start(brace) : brace])
+            if kind in ("for", "func"):
+                if brace == body:
+                    return True
+                break
+    return False
+
+
+def _ranges_over_sequence(pass_: Pass, header_start: int, header_end: int) -> bool:
+    masked = pass_.file.masked
+    m = _RANGE_RE.search(masked, header_start, header_end)
+    if m is None:
+        return False
+    end = len(masked[:header_end].rstrip())
+    type_ = pass_.type_of(m.end(), end)
+    return type_ is not None and (type_.startswith("[") or type_ == "string")
+
+
+def _generated(source: GoFile) -> bool:
+    return source.memo("loops:generated", lambda: bool(_GENERATED_RE.search(source.content)))
+
+
+def _loop(pass_: Pass, offset: int) -> tuple[int, int, str, str | None] | None:
+    """(body open, body close, header, label) of the ``for`` at ``offset``."""
+    source = pass_.file
+    masked = source.masked
+    if _generated(source):
+        return None  # generators emit `for { ... break }` blocks by design
+    body = _block_brace(masked, offset + 3)
+    close = matching_brace(masked, body) if body is not None else None
+    if body is None or close is None:
+        return None
+    # The label may sit on the line above: `outer:\n\tfor {`.
+    line = source.line_start(offset)
+    label = _LABEL_RE.search(masked, source.line_start(max(line - 1, 0)), offset)
+    return body, close, masked[offset + 3 : body].strip(), label.group(1) if label else None
+
+
+@visits("for")
+def visit_loop_runs_once(pass_: Pass, kind: str, offset: int) -> None:
+    """Detect loops whose body always leaves the loop on its first pass.
+
+    Reported with the ``loop`` form (``bare``, ``condition``, ``counted``
+    or ``range``) and the ``exit`` that ends the body.
+    """
+    source = pass_.file
+    masked = source.masked
+    loop = _loop(pass_, offset)
+    if loop is None:
+        return
+    body, close, header, label = loop
+    exit_ = _leaves(masked, body, close)
+    if exit_ is None or _GOTO_RE.search(masked, body, close):
+        return
+    if _continues(pass_, body, close, label):
+        return
+    if not header:
+        form = "bare"
+    elif _RANGE_RE.search(header):
+        if not _ranges_over_sequence(pass_, offset + 3, body):
+            return
+        form = "range"
+    else:
+        clauses = _split_top_level(masked, offset + 3, body, ";")
+        form = "counted" if len(clauses) == 3 else "condition"
+    if form == "bare":
+        hint = (
+            f"nothing loops back before the {exit_}, so this for {{}} runs once; "
+            "use a plain block, or a function with early returns"
+        )
+    else:
+        hint = (
+            f"the body always ends in {exit_} on its first pass, so the loop never "
+            "repeats; write it as an if"
+        )
+    pass_.report(source.line_at(offset), loop=form, exit=exit_, hint=hint)
+
+
+def _changed_in(masked: str, var: _Var, body: int, close: int) -> bool:
+    """Whether the loop body at ``body`` can change ``var``."""
+    if any(body < d.start < close for d in var.defs):
+        return True
+    for m in re.compile(rf"(?<![\w.]){re.escape(var.name)}\b").finditer(masked, body, close):
+        if _CHANGED_AFTER_RE.match(masked, m.end()):
+            return True
+        if _ARGUMENT_BEFORE_RE.search(masked, max(body, m.start() - 40), m.start()):
+            return True
+    return False
+
+
+@visits("for")
+def visit_loop_condition_unchanged(pass_: Pass, kind: str, offset: int) -> None:
+    """Detect loop conditions over local variables the loop never changes.
+
+    Reported with the ``condition`` and the ``variables`` it reads.
+    """
+    source = pass_.file
+    masked = source.masked
+    loop = _loop(pass_, offset)
+    if loop is None:
+        return
+    body, close, header, _label = loop
+    clauses = _split_top_level(masked, offset + 3, body, ";")
+    if len(clauses) == 3 and masked[clauses[2][0] : clauses[2][1]].strip():
+        return
+    if len(clauses) not in (1, 3) or _RANGE_RE.search(header):
+        return
+    start, end = clauses[1] if len(clauses) == 3 else clauses[0]
+    condition = masked[start:end].strip()
+    if not condition or "<-" in condition or "*" in condition:
+        return  # a receive or what a pointer points at changes outside the loop
+    if _LEAVES_RE.search(masked, body, close):
+        return
+    functions = [f for f in function_variables(source) if f.body_open < offset < f.body_close]
+    if not functions:
+        return
+    variables = functions[-1].variables
+    names = []
+    for m in _CONDITION_NAME_RE.finditer(masked, start, end):
+        name = m.group(1)
+        if name in ("nil", "true", "false"):
+            continue
+        if _CALL_AFTER_RE.match(masked, m.end()):
+            if name in ("len", "cap"):
+                continue
+            return  # a call may return something else each time
+        var = _binding(variables, name, m.start())
+        if var is None or not var.followed:
+            return  # package-level, captured or address-taken: changed elsewhere
+        if _changed_in(masked, var, body, close):
+            return
+        names.append(name)
+    if not names:
+        return
+    names = sorted(set(names), key=names.index)
+    pass_.report(
+        source.line_at(offset),
+        condition=source.content[start:end].strip(),
+        variables=names,
+        hint=f"nothing in the loop changes {', '.join(names)}, so the condition never "
+        "changes either: the loop runs zero times or forever",
+    )
+
+
+__all__ = ["visit_loop_condition_unchanged", "visit_loop_runs_once"]
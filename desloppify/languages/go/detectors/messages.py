@@ -0,0 +1,140 @@
+"""Error and log messages that spell out a function's name by hand.
+
+``fmt.Errorf("ProcessOrder: failed to save: %w", err)`` repeats the
+enclosing function's name in a string, where no rename will find it. The
+messages checked are the literal first argument of ``errors.New``,
+``fmt.Errorf``, ``log.Fatal``/``log.Panic`` and the log calls
+``error_only_logged`` knows (``log.Printf``, ``logger.Info``, ...), when
+it starts with ``Name:``, optionally qualified (``orders.ProcessOrder:``,
+``(*Service).ProcessOrder:``). A prefix qualified with an imported
+package, ``os.Open:``, names the call that failed and is left alone.
+
+- ``message_function_name``: ``Name`` is the enclosing function's name,
+  compared case-insensitively; function literals count as part of the
+  function they are in.
+- ``message_stale_name``: ``Name`` is written like a Go identifier
+  (mixed caps, or qualified) but nothing in the package is called that,
+  in any case: not a function, method, type, variable or anything the
+  package calls. The function it named has most likely been renamed,
+  and the message now points at code that does not exist. This needs
+  every file of the package.
+"""
+
+from __future__ import annotations
+
+import re
+
+from desloppify.languages.go.detectors._inspector import GoFile, Pass
+from desloppify.languages.go.detectors.custom_rules import _imports
+from desloppify.languages.go.detectors.error_flow import (
+    _DECL_RE,
+    _LOG_CALL_RE,
+    _body_brace,
+    _closing_paren,
+)
+
+_MESSAGE_CALL_RE = re.compile(
+    r"(?<![\w.])(?:errors\.New|fmt\.Errorf|log\.(?:Fatal|Panic)(?:f|ln)?)\("
+)
+_LITERAL_RE = re.compile(r'[ \t]*(?:"((?:[^"\\\n]|\\.)*)"|`([^`]*)`)')
+# `Name: `, `pkg.Name: `, `(*T).Name: `; group 1 is the whole prefix, 2 the name.
+_PREFIX_RE = re.compile(r"((?:(?:\(\*?\w+\)|\w+)\.)*(\w+)):(?:[ \t]|$)")
+_MIXED_CAPS_RE = re.compile(r"[a-z0-9][A-Z]")
+_NAME_CHAR_RE = re.compile(r"[\w.]")
+
+
+def _messages(source: GoFile) -> list[tuple[int, str, str]]:
+    """(offset, prefix, name) of each checked message that starts with a name prefix."""
+    def build() -> list[tuple[int, str, str]]:
+        masked, content = source.masked, source.content
+        opens = {m.end() - 1 for m in _MESSAGE_CALL_RE.finditer(masked)}
+        opens.update(
+            m.end() - 1
+            for m in _LOG_CALL_RE.finditer(masked)
+            if m.group().endswith("(") and not _NAME_CHAR_RE.match(masked, m.start() - 1)
+        )
+        packages = {name or path.rsplit("/", 1)[-1] for name, path, _ in _imports(source)}
+        found = []
+        for paren in sorted(opens):
+            literal = _LITERAL_RE.match(content, paren + 1)
+            if literal is None:
+                continue
+            text = literal.group(1) if literal.group(1) is not None else literal.group(2)
+            prefix = _PREFIX_RE.match(text)
+            if prefix is None or prefix.group(1).split(".", 1)[0] in packages:
+                continue  # `os.Open:` names the call that failed, in another package
+            found.append((paren, prefix.group(1), prefix.group(2)))
+        return found
+
+    return source.memo("messages:prefixed", build)
+
+
+def _functions(source: GoFile) -> dict[int, str]:
+    """Body ``{`` offset -> name of each declared function and method."""
+    def build() -> dict[int, str]:
+        masked = source.masked
+        bodies = {}
+        for m in _DECL_RE.finditer(masked):
+            params_end = _closing_paren(masked, m.end() - 1)
+            brace = _body_brace(masked, params_end + 1) if params_end is not None else None
+            if brace is not None:
+                bodies[brace] = m.group(2)
+        return bodies
+
+    return source.memo("messages:functions", build)
+
+
+def _enclosing_function(source: GoFile, pos: int) -> str | None:
+    enclosing = source.enclosing_blocks(pos)
+    return _functions(source).get(enclosing[-1]) if enclosing else None
+
+
+def detect_message_function_name(pass_: Pass) -> None:
+    """Detect messages prefixed with the enclosing function's name.
+
+    Reported with the ``function`` and the ``prefix`` as written.
+    """
+    source = pass_.file
+    for offset, prefix, name in _messages(source):
+        function = _enclosing_function(source, offset)
+        if function is None or name.lower() != function.lower():
+            continue
+        pass_.report(
+            source.line_at(offset),
+            function=function,
+            prefix=prefix,
+            hint=f'"{prefix}:" names {function} by hand, so it goes stale when the '
+            "function is renamed; drop it and let callers add context when they wrap",
+        )
+
+
+def _identifiers(files: tuple[GoFile, ...]) -> set[str]:
+    """Every identifier in the code of ``files``, lower-cased."""
+    return {word.lower() for f in files for word in re.findall(r"[A-Za-z_]\w*", f.masked)}
+
+
+def detect_message_stale_name(pass_: Pass) -> None:
+    """Detect message prefixes naming something the package does not have.
+
+    Reported with the ``prefix`` and the ``name`` that no longer exists.
+    """
+    source = pass_.file
+    if pass_.types is None:
+        return
+    files = pass_.types.files
+    for offset, prefix, name in _messages(source):
+        if not _MIXED_CAPS_RE.search(name) and "." not in prefix:
+            continue
+        names = pass_.types.memo("messages:identifiers", lambda: _identifiers(files))
+        if name.lower() in names:
+            continue
+        pass_.report(
+            source.line_at(offset),
+            prefix=prefix,
+            name=name,
+            hint=f"nothing in this package is called {name}, so the message points at "
+            "code that was renamed or removed; fix or drop the prefix",
+        )
+
+
+__all__ = ["detect_message_function_name", "detect_message_stale_name"]
@@ -0,0 +1,262 @@
+"""Names too short for how far they reach, or too long anywhere.
+
+``n`` is clear when it is declared and used within three lines, and a
+puzzle when it is read 80 lines after its declaration. A variable or
+parameter of one or two characters is reported when it spans more than
+``max_scope_lines`` lines, from its declaration to its last use (a
+parameter is declared on the signature line). Scopes follow Go's rules,
+as ``ineffective_assignment`` does: a ``:=`` in an inner block or an
+``if``/``for``/``switch`` header declares a new variable, so the same
+short name can be fine in one place and reported in another.
+
+Idiomatic short names are never reported: ``i``, ``j`` and ``k``
+declared by a ``for``, method receivers, ``t``/``b``/``f``/``tb`` of the
+``testing`` types, ``w`` and ``r`` of an HTTP handler, and the names of
+the ``allow`` option (``ok``, ``id``, ``db``, ...). Any variable,
+parameter, function, type or package-level name longer than
+``max_length`` characters is reported wherever it is.
+
+``builtin_shadow`` reports a variable, parameter, constant or type named
+after one of Go's predeclared identifiers (``len``, ``string``, ``true``,
+``error``, ...): the declaration compiles, and the builtin is gone for the
+rest of its scope. ``err`` and the other conventional names are not
+predeclared, so they never match.
+
+``getter_prefix`` reports exported methods named ``GetX`` that take no
+arguments and return a value: Go getters are ``obj.Name()``, not
+``obj.GetName()``. ``Get(key)`` and other lookups with arguments are
+left alone, as are generated files (protobuf getters) and types that
+already have a field or method ``X``, where the short name is taken.
+"""
+
+from __future__ import annotations
+
+import re
+
+from desloppify.languages.go.detectors._inspector import GoFile, Pass
+from desloppify.languages.go.detectors._source import matching_brace
+from desloppify.languages.go.detectors.assignments import (
+    _signature_groups,
+    function_variables,
+)
+from desloppify.languages.go.detectors.error_flow import _closing_paren
+
+# Defaults of the ``max_scope_lines``, ``max_length`` and ``allow`` options.
+NAME_MAX_SCOPE_LINES = 25
+NAME_MAX_LENGTH = 40
+NAME_ALLOWED = ("ok", "err", "id", "db", "tx", "mu", "wg")
+
+_SHORT = 2
+_LOOP_INDEXES = frozenset({"i", "j", "k"})
+_LOOP_HEADER_RE = re.compile(r"\bfor[ \t]+(?:\w+[ \t]*,[ \t]*)*$")
+# Parameter types whose conventional names are short.
+_CONVENTIONAL = {
+    "*testing.T": ("t",),
+    "*testing.B": ("b",),
+    "*testing.F": ("f",),
+    "testing.TB": ("t", "tb"),
+    "http.ResponseWriter": ("w",),
+    "*http.Request": ("r",),
+}
+# Go's universe block: predeclared types, constants, zero value and functions.
+PREDECLARED = frozenset({
+    "any", "bool", "byte", "comparable", "complex64", "complex128", "error",
+    "float32", "float64", "int", "int8", "int16", "int32", "int64", "rune",
+    "string", "uint", "uint8", "uint16", "uint32", "uint64", "uintptr",
+    "true", "false", "iota", "nil",
+    "append", "cap", "clear", "close", "complex", "copy", "delete", "imag",
+    "len", "make", "max", "min", "new", "panic", "print", "println", "real",
+    "recover",
+})
+_DECL_RE = re.compile(r"\b(var|const|type)[ \t]+(?:(\()|((?:\w+[ \t]*,[ \t]*)*\w+))")
+_SPEC_RE = re.compile(r"^[ \t]*((?:\w+[ \t]*,[ \t]*)*\w+)", re.MULTILINE)
+# `func (r *T) GetName() string {`; group 1 is the receiver type, 2 the name.
+_GETTER_RE = re.compile(
+    r"^func[ \t]*\([^)\n]*?\**[ \t]*(\w+)(?:\[[^\]\n]*\])?[ \t]*\)[ \t]*"
+    r"(Get[A-Z0-9]\w*)[ \t]*\([ \t]*\)[ \t]*([^{\n]*)\{",
+    re.MULTILINE,
+)
+_GENERATED_RE = re.compile(r"^// Code generated .* DO NOT EDIT\.$", re.MULTILINE)
+_TOP_LEVEL_RE = re.compile(
+    r"^(?:func(?:[ \t]*\([^)\n]*\))?|type|var|const)[ \t]+([A-Za-z_]\w*)", re.MULTILINE
+)
+
+
+def detect_name_length(pass_: Pass) -> None:
+    """Detect short names that reach far and names that are too long.
+
+    Reported with the ``name`` and the ``reason`` (``short`` or ``long``);
+    a short name also with the lines it spans (``scope``, e.g. ``12-140``)
+    and how many they are (``scope_lines``), a long one with its ``length``.
+    """
+    source = pass_.file
+    masked = source.masked
+    max_lines = pass_.options["max_scope_lines"]
+    max_length = pass_.options["max_length"]
+    allowed = set(pass_.options["allow"])
+    for m in _TOP_LEVEL_RE.finditer(masked):
+        if len(m.group(1)) > max_length:
+            _report_long(pass_, m.group(1), m.start(1), max_length)
+    for func in function_variables(source):
+        groups = _signature_groups(masked, func.start, func.body_open)
+        idiomatic = set(allowed)
+        if func.method and groups:
+            idiomatic.update(name for name, _ in groups[0])
+        for group in groups:
+            idiomatic.update(
+                name for name, type_ in group if name in _CONVENTIONAL.get(type_, ())
+            )
+        for var in func.variables:
+            declared = min(d.start for d in var.defs)
+            if len(var.name) > max_length:
+                _report_long(pass_, var.name, declared, max_length)
+                continue
+            if len(var.name) > _SHORT or var.name in idiomatic:
+                continue
+            line_start = source.line_start(declared)
+            if var.name in _LOOP_INDEXES and _LOOP_HEADER_RE.search(
+                masked, line_start, declared
+            ):
+                continue
+            first = source.line_at(declared)
+            last = source.line_at(max(var.reads, default=declared))
+            if last - first + 1 > max_lines:
+                pass_.report(
+                    first,
+                    name=var.name,
+                    reason="short",
+                    scope=f"{first}-{last}",
+                    scope_lines=last - first + 1,
+                    hint=f"{var.name} is used across lines {first}-{last} "
+                    f"({last - first + 1} lines, more than {max_lines}); give it a "
+                    "descriptive name",
+                )
+
+
+def _report_long(pass_: Pass, name: str, pos: int, max_length: int) -> None:
+    pass_.report(
+        pass_.file.line_at(pos),
+        name=name,
+        reason="long",
+        length=len(name),
+        hint=f"{name} is {len(name)} characters, more than {max_length}; shorten it",
+    )
+
+
+def _group_specs(masked: str, open_paren: int) -> list[tuple[int, str]]:
+    """(offset, names) of each spec in the ``var``/``const``/``type`` group at ``open_paren``."""
+    close = _closing_paren(masked, open_paren)
+    end = close if close is not None else len(masked)
+    specs = []
+    depth = 0
+    line_start = open_paren + 1
+    for i in range(open_paren + 1, end):
+        ch = masked[i]
+        if ch in "([{":
+            depth += 1
+        elif ch in ")]}":
+            depth -= 1
+        elif ch == "\n" and depth == 0:
+            line_start = i + 1
+            if m := _SPEC_RE.match(masked, line_start, end):
+                specs.append((m.start(1), m.group(1)))
+    return specs
+
+
+def detect_builtin_shadow(pass_: Pass) -> None:
+    """Detect declarations named after a predeclared identifier.
+
+    Reported with the ``name`` and the ``kind`` of declaration
+    (``parameter``, ``variable``, ``const`` or ``type``).
+    """
+    source = pass_.file
+    masked = source.masked
+    found: dict[tuple[str, int], str] = {}
+
+    def declare(name: str, pos: int, kind: str) -> None:
+        if name in PREDECLARED:
+            found.setdefault((name, source.line_at(pos)), kind)
+
+    functions = function_variables(source)
+    for func in functions:
+        for group in _signature_groups(masked, func.start, func.body_open):
+            for name, _ in group:
+                if name in PREDECLARED:
+                    m = re.search(rf"\b{name}\b", masked[func.start : func.body_open])
+                    declare(name, func.start + (m.start() if m else 0), "parameter")
+        for var in func.variables:
+            first = min(d.start for d in var.defs)
+            if first != func.body_open:
+                declare(var.name, first, "variable")
+    for m in _DECL_RE.finditer(masked):
+        kind = m.group(1)
+        if kind == "var" and any(f.body_open < m.start() < f.body_close for f in functions):
+            continue
+        specs = _group_specs(masked, m.start(2)) if m.group(2) else [(m.start(3), m.group(3))]
+        for pos, names in specs:
+            for name in re.findall(r"\w+", names):
+                declare(name, pos, "variable" if kind == "var" else kind)
+    for (name, line), kind in sorted(found.items(), key=lambda item: item[0][1]):
+        pass_.report(
+            line,
+            name=name,
+            kind=kind,
+            hint=f"rename the {kind} {name}; it hides the builtin {name} for the rest "
+            "of its scope",
+        )
+
+
+def _member_taken(pass_: Pass, type_name: str, name: str) -> bool:
+    """Whether ``type_name`` already has a field or method called ``name``."""
+    files = pass_.types.files if pass_.types is not None else (pass_.file,)
+    method_re = re.compile(
+        rf"^func[ \t]*\([^)\n]*\b{type_name}\b[^)\n]*\)[ \t]*{name}[ \t]*[\[(]",
+        re.MULTILINE,
+    )
+    if any(method_re.search(f.masked) for f in files):
+        return True
+    declared = _declaration(files, type_name)
+    if declared is None:
+        return False
+    file, m = declared
+    close = matching_brace(file.masked, m.end() - 1)
+    body = file.masked[m.end() : close]
+    return re.search(rf"^[ \t]*(?:\w+[ \t]*,[ \t]*)*{name}\b", body, re.MULTILINE) is not None
+
+
+def _declaration(files: tuple[GoFile, ...], type_name: str) -> tuple[GoFile, re.Match] | None:
+    """The file declaring struct ``type_name``, and the match ending at its ``{``."""
+    struct_re = re.compile(rf"(?<![\w.])type[ \t]+{type_name}\b[^{{\n]*?struct[ \t]*\{{")
+    for file in files:
+        if m := struct_re.search(file.masked):
+            return file, m
+    return None
+
+
+def detect_getter_prefix(pass_: Pass) -> None:
+    """Detect exported no-argument ``GetX`` methods that return a value."""
+    source = pass_.file
+    if _GENERATED_RE.search(source.content):
+        return
+    for m in _GETTER_RE.finditer(source.masked):
+        type_name, name, results = m.group(1), m.group(2), m.group(3).strip()
+        short = name[3:]
+        if not results or _member_taken(pass_, type_name, short):
+            continue
+        pass_.report(
+            source.line_at(m.start()),
+            name=name,
+            suggestion=short,
+            hint=f"name the getter {short}; Go getters drop the Get prefix",
+        )
+
+
+__all__ = [
+    "NAME_ALLOWED",
+    "NAME_MAX_LENGTH",
+    "NAME_MAX_SCOPE_LINES",
+    "PREDECLARED",
+    "detect_builtin_shadow",
+    "detect_getter_prefix",
+    "detect_name_length",
+]
@@ -0,0 +1,274 @@
+"""Nil checks that change nothing.
+
+``len`` of a nil slice, map or channel is 0, and ranging over a nil
+slice or map runs no iterations, so a nil check next to either is
+clutter. Each shape gets its own rule:
+
+- ``redundant_nil_check``: ``s != nil && len(s) > 0`` (or ``s == nil ||
+  len(s) == 0``), where the length test alone decides; and ``if m != nil
+  { for k := range m { ... } }``, where the loop alone does. Ranging over
+  a nil channel blocks forever, so that nil check is kept.
+- ``redundant_error_check``: ``if err != nil { return err }`` followed by
+  ``return nil`` (or an ``else`` that returns it), which is ``return
+  err``.
+
+Whether an operand is a slice, map or channel comes from ``go/types``
+when the scan type-checks, and otherwise from its declaration in the
+function (``s []T``, ``m := make(map[K]V)``, ``var ch chan T``) or at
+package level; slice, map and channel types the package declares count
+too. Operands of any other or unknown type, pointers to slices and
+arrays included, are left alone: there the nil check decides something.
+``len(s) >= 0`` and ``len(s) < 0`` are ``len_comparison``'s.
+
+Returning ``err`` where the function returned ``nil`` is only the same
+when ``err`` is an interface: a nil ``*MyError`` returned as an
+``error`` is not a nil error. The rule needs ``err`` to be of type
+``error`` when the scan type-checks, and to be named like one (``err``,
+``parseErr``) when it does not.
+"""
+
+from __future__ import annotations
+
+import re
+
+from desloppify.languages.go.detectors._inspector import GoFile, Pass, visits
+from desloppify.languages.go.detectors._source import go_comments, matching_brace
+from desloppify.languages.go.detectors.assignments import _ERROR_NAME_RE
+from desloppify.languages.go.detectors.logic import _condition_span, _split_top_level
+
+_OPERAND = r"[A-Za-z_]\w*(?:\.[A-Za-z_]\w*)*"
+# `x != nil && len(x) > 0`, `x == nil || len(x) == 0`, either with the
+# usual spellings of "not empty" and "empty".
+_NIL_THEN_LEN_RE = re.compile(
+    rf"(?<![\w.*&!])(?P<x>{_OPERAND})[ \t]*(?P<nil>!=|==)[ \t]*nil[ \t]*"
+    r"(?P<op>&&|\|\|)[ \t]*(?P<len>len\([ \t]*(?P=x)[ \t]*\)[ \t]*"
+    r"(?:>[ \t]*0|!=[ \t]*0|>=[ \t]*1|==[ \t]*0|<[ \t]*1|<=[ \t]*0))(?![\w.])"
+)
+_NOT_EMPTY_RE = re.compile(r"len\([^)]*\)[ \t]*(?:>|!=|>=)")
+_ARITHMETIC_HEAD_RE = re.compile(r"[ \t]*(?:[-+*/%^.\[(]|<<|>>|&(?!&)|\|(?!\|))")
+_PLAIN_NIL_IF_RE = re.compile(rf"if[ \t]+({_OPERAND})[ \t]*!=[ \t]*nil[ \t]*")
+_RANGE_RE = re.compile(rf"\s*for[ \t]+(?:[^{{}}\n]*?:?=[ \t]*)?range[ \t]+({_OPERAND})[ \t]*\{{")
+_RETURN_RE = re.compile(r"\s*return[ \t]+([^\n]+?)\s*")
+_ELSE_BLOCK_RE = re.compile(r"[ \t]*else[ \t]*\{")
+_TRAILING_RETURN_RE = re.compile(r"[ \t]*\n[ \t]*return[ \t]+([^\n]+?)[ \t]*(?=\n|$)")
+
+_KIND_PREFIXES = (("[]", "slice"), ("map[", "map"), ("chan ", "chan"), ("<-chan ", "chan"))
+_KIND_RE = re.compile(r"(\[\])|(map\[)|((?:<-[ \t]*)?chan\b)")
+_MAKE_RE = re.compile(r"make\([ \t]*")
+_NAMED_TYPE_RE = re.compile(r"^type[ \t]+(\w+)[ \t]+(\[\]|map\[|(?:<-[ \t]*)?chan\b)", re.MULTILINE)
+
+
+def _named_kinds(files: tuple[GoFile, ...]) -> dict[str, str]:
+    """Slice, map and channel types the package declares, by name."""
+    kinds = {}
+    for file in files:
+        for m in _NAMED_TYPE_RE.finditer(file.masked):
+            kinds[m.group(1)] = _kind_of_type(m.group(2), {})
+    return {name: kind for name, kind in kinds.items() if kind is not None}
+
+
+def _kind_of_type(type_: str, named: dict[str, str]) -> str | None:
+    """``slice``, ``map`` or ``chan`` for a type written out, else None."""
+    type_ = type_.strip()
+    m = _KIND_RE.match(type_)
+    if m is not None:
+        return "slice" if m.group(1) else "map" if m.group(2) else "chan"
+    name = re.match(r"\w+", type_)
+    return named.get(name.group(0)) if name is not None and name.group(0) == type_ else None
+
+
+def _declared_kind(
+    source: GoFile, start: int, end: int, name: str, named: dict[str, str], *, top_level: bool
+) -> tuple[bool, str | None]:
+    """(declared, kind) from the last declaration of ``name`` in ``start``..``end``."""
+    masked = source.masked
+    word = re.escape(name)
+    found: list[tuple[int, str | None]] = []
+    typed = re.compile(rf"(?<![\w.]){word}(?:[ \t]*,[ \t]*\w+)*[ \t]+(?=[\w\[*<])")
+    for m in typed.finditer(masked[start:end]):
+        before = masked[: start + m.start()].rstrip()
+        if before.endswith(("var", "(", ",")):
+            type_ = masked[start + m.end() : masked.find("\n", start + m.end())]
+            type_ = re.split(r"[ \t]*[=,)]", type_, maxsplit=1)[0]
+            found.append((start + m.start(), _kind_of_type(type_, named)))
+    for m in re.finditer(rf"(?<![\w.]){word}[ \t]*:?=(?!=)[ \t]*", masked[start:end]):
+        value = masked[start + m.end() : end]
+        made = _MAKE_RE.match(value)
+        value = value[made.end() :] if made is not None else value
+        kind = _KIND_RE.match(value)
+        found.append((start + m.start(), _kind_of_type(kind.group(0), {}) if kind else None))
+    if top_level:
+        found = [f for f in found if not source.enclosing_blocks(f[0])]
+    return (True, max(found)[1]) if found else (False, None)
+
+
+def _operand_kind(pass_: Pass, start: int, end: int) -> str | None:
+    """``slice``, ``map`` or ``chan`` for the operand at ``start``..``end``, if known."""
+    source = pass_.file
+    files = pass_.types.files if pass_.types is not None else (source,)
+    named = (
+        pass_.types.memo("nilchecks:named", lambda: _named_kinds(files))
+        if pass_.types is not None
+        else _named_kinds(files)
+    )
+    type_ = pass_.type_of(start, end)
+    if type_ is not None:
+        kinds = [kind for prefix, kind in _KIND_PREFIXES if type_.startswith(prefix)]
+        return kinds[0] if kinds else named.get(type_)
+    name = source.masked[start:end]
+    if "." in name:
+        return None
+    enclosing = source.enclosing_blocks(start)
+    func = source.masked.rfind("func", 0, enclosing[-1]) if enclosing else 0
+    declared, kind = _declared_kind(source, max(func, 0), start, name, named, top_level=False)
+    if not declared:
+        _, kind = _declared_kind(source, 0, len(source.masked), name, named, top_level=True)
+    return kind
+
+
+def _checks_before_len(pass_: Pass) -> None:
+    source = pass_.file
+    masked, content = source.masked, source.content
+    for m in _NIL_THEN_LEN_RE.finditer(masked):
+        guards = (m["nil"], m["op"])
+        not_empty = _NOT_EMPTY_RE.match(m["len"]) is not None
+        if guards != (("!=", "&&") if not_empty else ("==", "||")):
+            continue
+        if _ARITHMETIC_HEAD_RE.match(masked, m.end()):
+            continue
+        if m["op"] == "||" and masked[: m.start()].rstrip().endswith("&&"):
+            continue  # `a && x == nil || ...` groups the nil check with `a`
+        if _operand_kind(pass_, m.start("x"), m.end("x")) is None:
+            continue
+        entry = pass_.report(
+            source.line_at(m.start()),
+            kind="len",
+            operand=m["x"],
+            hint=f"len({m['x']}) is 0 when {m['x']} is nil; drop the nil check",
+        )
+        entry["fix"] = {
+            "title": "Drop the nil check",
+            "line": source.line_at(m.start()),
+            "old": content[m.start() : m.end()],
+            "new": content[m.start("len") : m.end()],
+        }
+
+
+def _outdented(text: str) -> str:
+    return "\n".join(line[1:] if line.startswith("\t") else line for line in text.split("\n"))
+
+
+def _checks_around_range(pass_: Pass) -> None:
+    source = pass_.file
+    masked, content = source.masked, source.content
+    for m in _PLAIN_NIL_IF_RE.finditer(masked):
+        if masked[source.line_start(m.start()) : m.start()].strip() or masked[m.end()] != "{":
+            continue
+        close = matching_brace(masked, m.end())
+        loop = _RANGE_RE.match(masked, m.end() + 1)
+        if close is None or loop is None or loop.group(1) != m.group(1):
+            continue
+        loop_close = matching_brace(masked, loop.end() - 1)
+        if loop_close is None or masked[loop_close + 1 : close].strip():
+            continue
+        if _ELSE_BLOCK_RE.match(masked, close + 1):
+            continue
+        if _operand_kind(pass_, m.start(1), m.end(1)) not in ("slice", "map"):
+            continue
+        entry = pass_.report(
+            source.line_at(m.start()),
+            kind="range",
+            operand=m.group(1),
+            hint=f"ranging over a nil {m.group(1)} runs no iterations; drop the if",
+        )
+        body = content[m.end() + 1 : close]
+        if "`" in body:
+            continue  # outdenting would change a raw string
+        if go_comments(content[m.start() : loop.start(1)] + content[loop_close + 1 : close]):
+            continue  # comments around the loop would be dropped
+        entry["fix"] = {
+            "title": "Drop the nil check around the loop",
+            "line": source.line_at(m.start()),
+            "old": content[m.start() : close + 1],
+            "new": _outdented(body.strip()),
+        }
+
+
+def detect_redundant_nil_check(pass_: Pass) -> None:
+    """Detect nil checks that ``len`` or ``range`` already make.
+
+    Reported with the ``kind`` (``len`` or ``range``) and the ``operand``,
+    and a fix that drops the check.
+    """
+    _checks_before_len(pass_)
+    _checks_around_range(pass_)
+
+
+def _returns(masked: str, start: int, end: int) -> list[str]:
+    return [masked[a:b].strip() for a, b in _split_top_level(masked, start, end, ",")]
+
+
+def _is_error(pass_: Pass, name: str, start: int) -> bool:
+    type_ = pass_.type_of(start, start + len(name))
+    if type_ is not None:
+        return type_ == "error"
+    return _ERROR_NAME_RE.fullmatch(name) is not None
+
+
+@visits("if")
+def visit_redundant_error_check(pass_: Pass, kind: str, offset: int) -> None:
+    """Detect ``if err != nil { return err }`` then ``return nil``: ``return err``.
+
+    The returns may carry other results, which must be the same in both:
+    ``return nil, err`` and ``return nil, nil``. A comment on a line of its
+    own means the branches are deliberate and nothing is reported; a
+    trailing comment only withholds the fix, since the rewrite would drop it.
+    """
+    source = pass_.file
+    masked, content = source.masked, source.content
+    if masked[source.line_start(offset) : offset].strip():
+        return
+    header = _PLAIN_NIL_IF_RE.match(masked, offset)
+    span = _condition_span(masked, offset + len(kind))
+    if header is None or span is None or span[1] != header.end():
+        return
+    name = header.group(1)
+    close = matching_brace(masked, span[1])
+    then = _RETURN_RE.fullmatch(masked, span[1] + 1, close) if close is not None else None
+    if then is None:
+        return
+    otherwise = None
+    if (m := _ELSE_BLOCK_RE.match(masked, close + 1)) is not None:
+        else_close = matching_brace(masked, m.end() - 1)
+        if else_close is not None:
+            otherwise = _RETURN_RE.fullmatch(masked, m.end(), else_close)
+            end = else_close + 1
+    else:
+        otherwise = _TRAILING_RETURN_RE.match(masked, close + 1)
+        end = otherwise.end() if otherwise else close
+    if otherwise is None:
+        return
+    kept = _returns(masked, then.start(1), then.end(1))
+    dropped = _returns(masked, otherwise.start(1), otherwise.end(1))
+    if len(kept) != len(dropped):
+        return
+    differ = [(a, b) for a, b in zip(kept, dropped) if a != b]
+    if differ != [(name, "nil")] or not _is_error(pass_, name, header.start(1)):
+        return
+    if any(line.lstrip().startswith(("//", "/*")) for line in content[offset:end].splitlines()):
+        return
+    entry = pass_.report(source.line_at(offset), variable=name)
+    if not go_comments(content[offset:end]):
+        value = content[then.start(1) : then.end(1)]
+        entry["fix"] = {
+            "title": f"Replace with return {value}",
+            "line": source.line_at(offset),
+            "old": content[offset:end],
+            "new": f"return {value}",
+        }
+
+
+__all__ = [
+    "detect_redundant_nil_check",
+    "visit_redundant_error_check",
+]
@@ -0,0 +1,236 @@
+"""Go library panics, graded by whether the package's API can reach them.
+
+A panic in library code takes down whoever imports the package, but one in
+an unexported helper that only guards an internal invariant is usually an
+assertion, not an API hazard. ``package_reach`` builds the package's call
+graph from every non-test file (a reference to a function counts as a call,
+so callbacks are followed too); its roots are the exported functions and
+methods, ``init`` and package-level initializers. A panic reachable from a
+root is ``panic_in_lib`` at full severity, naming the root it is reached
+from; one only reachable from unexported helpers (or from nothing) is the
+milder ``panic_in_lib_helper``. ``Must*`` functions whose doc comment says
+they panic follow the ``regexp.MustCompile`` convention and are not flagged.
+At syntax level (``scan --fast``) the graph covers only the file itself.
+
+``must_call_in_function`` is the other side of the ``Must`` convention: such
+helpers are meant for package-level initializers and ``init``, where a bad
+argument fails at startup. Inside an ordinary function a non-constant
+argument turns a recoverable error into a crash at some later request.
+"""
+
+from __future__ import annotations
+
+import re
+from dataclasses import dataclass
+
+from desloppify.languages.go.detectors._constexpr import deterministic, file_constants
+from desloppify.languages.go.detectors._inspector import GoFile, Pass
+from desloppify.languages.go.detectors._source import matching_brace
+from desloppify.languages.go.detectors.custom_rules import _imports
+from desloppify.languages.go.detectors.error_flow import _body_brace, _closing_paren
+from desloppify.languages.go.detectors.logic import _split_top_level
+
+PANIC_EXPLAIN = (
+    "Builds the package's call graph (a reference to a function counts as a call). "
+    "panic_in_lib: the panic is reachable from an exported function or method, "
+    "init, or a package-level initializer, so callers of the package can trigger it. "
+    "panic_in_lib_helper: only unexported helpers reach it, which usually means an "
+    "internal invariant check. Must* functions documented to panic are not flagged."
+)
+
+# `func Name(`, `func (r *T) Name(`, `func Name[T any](`
+_FUNC_RE = re.compile(
+    r"^func[ \t]+(?:\([^)\n]*\)[ \t]*)?(\w+)[ \t]*(?:\[[^\]\n]*\])?\(", re.MULTILINE
+)
+_PANIC_RE = re.compile(r"(?<![\w.])panic\s*\(")
+_IDENT_RE = re.compile(r"(?<![\w])[A-Za-z_]\w*")
+_MUST_RE = re.compile(r"^[Mm]ust(?:[A-Z0-9_]\w*)?$")
+_MAIN_PACKAGE_RE = re.compile(r"^package[ \t]+main\b", re.MULTILINE)
+# `MustX(`, `regexp.MustCompile(`, `c.cfg.MustGet(`; group 1 is the receiver chain.
+_CALL_RE = re.compile(r"(?<![\w.])((?:\w+[ \t]*\.[ \t]*)*)(\w+)[ \t]*\(")
+
+
+@dataclass(frozen=True)
+class _Func:
+    name: str
+    start: int
+    body_open: int
+    body_close: int
+    documented_panic: bool
+
+
+def _doc_comment(file: GoFile, offset: int) -> str:
+    """The ``//`` comment lines directly above the declaration at ``offset``."""
+    index = file.line_at(offset) - 2
+    lines: list[str] = []
+    while index >= 0 and file.lines[index].strip().startswith("//"):
+        lines.append(file.lines[index])
+        index -= 1
+    return "\n".join(reversed(lines))
+
+
+def _functions(file: GoFile) -> list[_Func]:
+    """Every function and method declared in ``file`` that has a body."""
+    def build() -> list[_Func]:
+        masked = file.masked
+        found = []
+        for m in _FUNC_RE.finditer(masked):
+            params_close = _closing_paren(masked, m.end() - 1)
+            body_open = _body_brace(masked, params_close + 1) if params_close else None
+            body_close = matching_brace(masked, body_open) if body_open is not None else None
+            if body_close is None:
+                continue
+            name = m.group(1)
+            documented = bool(_MUST_RE.match(name)) and "panic" in _doc_comment(
+                file, m.start()
+            ).lower()
+            found.append(_Func(name, m.start(), body_open, body_close, documented))
+        return found
+
+    return file.memo("panics:functions", build)
+
+
+def _is_root(name: str) -> bool:
+    return name[:1].isupper() or name == "init"
+
+
+def package_reach(files: tuple[GoFile, ...]) -> dict[str, str]:
+    """``{function name: the root it is reachable from}`` over ``files``."""
+    edges: dict[str, set[str]] = {}
+    declared: set[str] = set()
+    top_level: set[str] = set()
+    for file in files:
+        functions = _functions(file)
+        declared.update(f.name for f in functions)
+        masked = file.masked
+        previous = 0
+        for func in sorted(functions, key=lambda f: f.start):
+            # Package-level var initializers run at import time.
+            top_level.update(_IDENT_RE.findall(masked, previous, func.start))
+            body = masked[func.body_open : func.body_close]
+            edges.setdefault(func.name, set()).update(_IDENT_RE.findall(body))
+            previous = func.body_close + 1
+        top_level.update(_IDENT_RE.findall(masked, previous))
+    reach: dict[str, str] = {}
+    queue = [(name, name) for name in sorted(declared) if _is_root(name)]
+    queue += [(name, "package initialization") for name in sorted(top_level & declared)]
+    while queue:
+        name, root = queue.pop(0)
+        if name in reach:
+            continue
+        reach[name] = root
+        queue.extend((callee, root) for callee in sorted(edges.get(name, ()) & declared))
+    return reach
+
+
+def _classify(pass_: Pass) -> list[tuple[int, str, str | None, str]]:
+    """(line, rule, root, function) for each flagged panic in the pass's file."""
+    file = pass_.file
+
+    def build() -> list[tuple[int, str, str | None, str]]:
+        if _MAIN_PACKAGE_RE.search(file.masked):
+            return []
+        files = pass_.types.files if pass_.types is not None else (file,)
+        key = "panics:reach"
+        reach = (
+            pass_.types.memo(key, lambda: package_reach(files))
+            if pass_.types is not None
+            else package_reach(files)
+        )
+        functions = _functions(file)
+        flagged = []
+        for m in _PANIC_RE.finditer(file.masked):
+            owner = next(
+                (f for f in functions if f.body_open < m.start() < f.body_close), None
+            )
+            if owner is None:
+                flagged.append(
+                    (file.line_at(m.start()), "panic_in_lib", "package initialization", "")
+                )
+                continue
+            if owner.documented_panic:
+                continue
+            root = reach.get(owner.name)
+            rule = "panic_in_lib" if root is not None else "panic_in_lib_helper"
+            flagged.append((file.line_at(m.start()), rule, root, owner.name))
+        return flagged
+
+    return file.memo("panics:classified", build)
+
+
+def detect_panic_in_lib(pass_: Pass) -> None:
+    """Detect library panics reachable from the package's exported API."""
+    for line, rule, root, function in _classify(pass_):
+        if rule == "panic_in_lib":
+            pass_.report(line, function=function, reached_from=root)
+
+
+def detect_panic_in_lib_helper(pass_: Pass) -> None:
+    """Detect library panics that only unexported helpers reach."""
+    for line, rule, _root, function in _classify(pass_):
+        if rule == "panic_in_lib_helper":
+            pass_.report(line, function=function)
+
+
+def _package_constants(pass_: Pass) -> frozenset[str]:
+    if pass_.types is None:
+        return file_constants(pass_.file)
+    files = pass_.types.files
+    return pass_.types.memo(
+        "constexpr:package", lambda: frozenset().union(*(file_constants(f) for f in files))
+    )
+
+
+def detect_must_call_in_function(pass_: Pass) -> None:
+    """Detect ``Must*`` calls with non-constant arguments inside function bodies.
+
+    ``init``, ``main`` in package main and other ``Must*`` functions (which
+    wrap the convention) are exempt, as are package-level initializers.
+    Calls whose arguments are all constant (``regexp.MustCompile("^a+$")``)
+    or constant-fed call chains on an imported package
+    (``template.Must(template.New("t").Parse(page))``) are allowed anywhere,
+    since they succeed or fail the same way on every run. A method on a
+    value (``cfg.MustGet("k")``) depends on the receiver, so it is checked
+    like a non-constant argument. The ``functions`` option adds helpers by
+    name (``mustParse``) or qualified name (``lo.Must``).
+    """
+    source = pass_.file
+    masked = source.masked
+    extra = set(pass_.options["functions"])
+    is_main = bool(_MAIN_PACKAGE_RE.search(masked))
+    packages = frozenset(
+        name for name, _path, _offset in _imports(source) if name not in {"_", "."}
+    )
+    constants = _package_constants(pass_)
+    for func in _functions(source):
+        if func.name == "init" or (is_main and func.name == "main") or _MUST_RE.match(func.name):
+            continue
+        for call in _CALL_RE.finditer(masked, func.body_open, func.body_close):
+            receiver = re.sub(r"\s+", "", call.group(1)).rstrip(".")
+            name = call.group(2)
+            qualified = f"{receiver}.{name}" if receiver else name
+            if not (_MUST_RE.match(name) or name in extra or qualified in extra):
+                continue
+            close = _closing_paren(masked, call.end() - 1)
+            if close is None:
+                continue
+            on_value = bool(receiver) and receiver not in packages
+            arguments = [
+                masked[a:b]
+                for a, b in _split_top_level(masked, call.end(), close, ",")
+                if masked[a:b].strip()
+            ]
+            if not on_value and all(
+                deterministic(arg, constants, packages) for arg in arguments
+            ):
+                continue
+            pass_.report(source.line_at(call.start()), function=qualified, enclosing=func.name)
+
+
+__all__ = [
+    "PANIC_EXPLAIN",
+    "detect_must_call_in_function",
+    "detect_panic_in_lib",
+    "detect_panic_in_lib_helper",
+    "package_reach",
+]
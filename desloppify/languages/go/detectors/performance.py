@@ -0,0 +1,190 @@
+"""Go performance smells: avoidable allocations and redundant work."""
+
+from __future__ import annotations
+
+import re
+
+from desloppify.languages.go.detectors._inspector import Pass
+from desloppify.languages.go.detectors._source import matching_brace
+
+# fmt.Sprintf("%d", n) and friends: a lone verb with exactly one simple operand.
+_SPRINTF_SINGLE_VERB_RE = re.compile(
+    r"\bfmt\.Sprintf\(\s*\"%([dft])\"\s*,\s*"
+    r"([A-Za-z_][\w.]*(?:\[[^\[\]]*\])?(?:\([^()]*\))?)\s*\)"
+)
+
+_STRCONV_IMPORT_RE = re.compile(r'^\s*(?:import\s+)?"strconv"', re.MULTILINE)
+
+_STRCONV_SUGGESTIONS = {
+    "d": "strconv.Itoa({arg}) for int",
+    "f": "strconv.FormatFloat({arg}, 'f', 6, 64)",
+    "t": "strconv.FormatBool({arg})",
+}
+
+# %d's conversion once go/types knows the operand's type; narrower integers widen first.
+_INT_SUGGESTIONS = {
+    "int": "strconv.Itoa({arg})",
+    "int64": "strconv.FormatInt({arg}, 10)",
+    "uint64": "strconv.FormatUint({arg}, 10)",
+    **dict.fromkeys(("int8", "int16", "int32", "rune"), "strconv.FormatInt(int64({arg}), 10)"),
+    **dict.fromkeys(
+        ("uint", "uint8", "uint16", "uint32", "byte", "uintptr"),
+        "strconv.FormatUint(uint64({arg}), 10)",
+    ),
+}
+
+
+def _suggestion(pass_: Pass, line: int, m: re.Match[str]) -> str:
+    template = _STRCONV_SUGGESTIONS[m.group(1)]
+    if m.group(1) == "d":
+        start = pass_.file.line_offset(line) + m.start(2)
+        type_ = pass_.type_of(start, start + len(m.group(2)))
+        template = _INT_SUGGESTIONS.get(type_ or "", template)
+    return template.format(arg=m.group(2))
+
+
+def detect_sprintf_strconv(pass_: Pass) -> None:
+    """Detect fmt.Sprintf calls that are a single strconv conversion.
+
+    The ``suggestion`` for ``%d`` follows the operand's type when the
+    package is type-checked (``strconv.FormatInt`` for an ``int64``);
+    otherwise it is ``strconv.Itoa``, which takes an ``int``.
+    """
+    source = pass_.file
+    masked_lines = source.masked_lines
+    imports_strconv = bool(_STRCONV_IMPORT_RE.search(source.content))
+    for i, line in enumerate(source.lines):
+        if i >= len(masked_lines) or "fmt.Sprintf(" not in masked_lines[i]:
+            continue
+        for m in _SPRINTF_SINGLE_VERB_RE.finditer(line):
+            if "fmt.Sprintf(" not in masked_lines[i][m.start() : m.end()]:
+                continue
+            suggestion = _suggestion(pass_, i + 1, m)
+            entry = pass_.report(i + 1, suggestion=suggestion)
+            # %t only formats bools, so the rewrite is exact once strconv is imported.
+            if m.group(1) == "t" and imports_strconv:
+                entry["fix"] = {
+                    "title": f"Replace with {suggestion}",
+                    "line": i + 1,
+                    "old": m.group(0),
+                    "new": suggestion,
+                }
+
+
+# `for k, v := range src {` — the iteration count is len(src) up front.
+_RANGE_LOOP_RE = re.compile(
+    r"^[ \t]*for\s+(?:[\w\s,]+:?=\s*)?range\s+([\w.]+(?:\([^()]*\))?)\s*\{[ \t]*$",
+    re.MULTILINE,
+)
+_ANY_LOOP_RE = re.compile(r"^[ \t]*for\b[^{\n]*\{", re.MULTILINE)
+_SELF_APPEND_RE = re.compile(r"\b(\w+)\s*=\s*append\(\s*\1\s*,")
+
+
+def _empty_slice_decls(
+    masked_lines: list[str], loop_line: int, lookback: int
+) -> dict[str, str]:
+    """Map slice names declared empty in the ``lookback`` non-blank lines above
+    ``loop_line`` to element types."""
+    decls: dict[str, str] = {}
+    seen = 0
+    idx = loop_line - 2
+    while idx >= 0 and seen < lookback:
+        text = masked_lines[idx].strip()
+        idx -= 1
+        if not text:
+            continue
+        seen += 1
+        m = re.match(r"^var\s+(\w+)\s+\[\]([\w.*\[\]]+)$", text) or re.match(
+            r"^(\w+)\s*:=\s*\[\]([\w.*\[\]]+)\{\s*\}$", text
+        )
+        if m:
+            decls.setdefault(m.group(1), m.group(2))
+    return decls
+
+
+def _is_channel(masked: str, name: str) -> bool:
+    escaped = re.escape(name)
+    return bool(
+        re.search(rf"\b{escaped}\s*(?::=|=)\s*make\(\s*(?:<-\s*)?chan\b", masked)
+        or re.search(rf"\b{escaped}\s+(?:<-\s*)?chan\b", masked)
+    )
+
+
+def _nested_loop_spans(masked: str, start: int, end: int) -> list[tuple[int, int]]:
+    spans: list[tuple[int, int]] = []
+    for m in _ANY_LOOP_RE.finditer(masked, start, end):
+        close = matching_brace(masked, m.end() - 1)
+        if close is not None:
+            spans.append((m.end(), close))
+    return spans
+
+
+def detect_append_no_prealloc(pass_: Pass) -> None:
+    """Detect appends in a range loop to a slice that could be preallocated."""
+    source = pass_.file
+    masked = source.masked
+    for loop in _RANGE_LOOP_RE.finditer(masked):
+        ranged = loop.group(1)
+        if _is_channel(masked, ranged):
+            continue
+        decls = _empty_slice_decls(
+            source.masked_lines, source.line_at(loop.start()), pass_.options["lookback_lines"]
+        )
+        if not decls:
+            continue
+        open_pos = loop.end() - 1
+        while masked[open_pos] != "{":
+            open_pos -= 1
+        close = matching_brace(masked, open_pos)
+        if close is None:
+            continue
+        nested = _nested_loop_spans(masked, open_pos + 1, close)
+        reported: set[str] = set()
+        for m in _SELF_APPEND_RE.finditer(masked, open_pos + 1, close):
+            name = m.group(1)
+            if name not in decls or name in reported:
+                continue
+            if any(lo <= m.start() < hi for lo, hi in nested):
+                continue
+            reported.add(name)
+            size = ranged if ranged.isdigit() else f"len({ranged})"
+            pass_.report(
+                source.line_at(m.start()),
+                suggestion=f"{name} := make([]{decls[name]}, 0, {size})",
+            )
+
+
+# `if _, ok := m[k]; ok {` — membership test that discards the value.
+_MEMBERSHIP_CHECK_RE = re.compile(
+    r"\bif\s+_\s*,\s*(\w+)\s*:=\s*([\w.]+)\[([^\[\]\n]+)\]\s*;\s*\1\s*\{"
+)
+
+
+def detect_double_map_lookup(pass_: Pass) -> None:
+    """Detect comma-ok membership checks followed by a re-read of the same key."""
+    source = pass_.file
+    masked, content = source.masked, source.content
+    for m in _MEMBERSHIP_CHECK_RE.finditer(masked):
+        ok_var, map_expr = m.group(1), m.group(2)
+        # Compare keys in the original text so distinct string literals differ.
+        key = content[m.start(3) : m.end(3)].strip()
+        close = matching_brace(masked, m.end() - 1)
+        if close is None:
+            continue
+        reread = re.compile(
+            rf"(?<![\w.]){re.escape(map_expr)}\[\s*{re.escape(key)}\s*\](?!\s*=[^=])"
+        )
+        hits = reread.finditer(content, m.end(), close)
+        if not any(masked[h.start()] == content[h.start()] for h in hits):
+            continue
+        pass_.report(
+            source.line_at(m.start()),
+            suggestion=f"if v, {ok_var} := {map_expr}[{key}]; {ok_var} {{",
+        )
+
+
+__all__ = [
+    "detect_append_no_prealloc",
+    "detect_double_map_lookup",
+    "detect_sprintf_strconv",
+]
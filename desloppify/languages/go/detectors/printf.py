@@ -0,0 +1,301 @@
+"""Printf-family calls whose format string does not fit their arguments.
+
+``go vet`` has the full check; this one puts the common cases in the same
+report as the rest of desloppify. The format must be a single string
+literal. Each directive is parsed the way ``fmt`` does, explicit argument
+indexes (``%[2]d``) and ``*`` widths included, and the call is reported
+when it has too few arguments, has arguments no verb uses (unless indexes
+are in play, where fmt ignores extras), refers to a missing index, uses a
+verb fmt does not know, or passes an argument whose type is obvious
+without type checking and wrong for its verb: a string for ``%d``, a
+number for ``%s``. Types are known for literals, ``len``/``cap``, a few
+string-returning calls, and names the enclosing function declares from a
+literal or with an explicit basic type. A call ending in ``args...`` is
+only checked for indexes past the fixed arguments.
+"""
+
+from __future__ import annotations
+
+import re
+from dataclasses import dataclass
+
+from desloppify.languages.go.detectors._inspector import GoFile, Pass
+from desloppify.languages.go.detectors.error_flow import _closing_paren
+from desloppify.languages.go.detectors.logic import _split_top_level
+from desloppify.languages.go.detectors.panics import _CALL_RE, _functions
+
+# Format argument index of each built-in Printf-like function.
+PRINTF_FUNCTIONS = {"fmt.Printf": 0, "fmt.Sprintf": 0, "fmt.Errorf": 0, "fmt.Fprintf": 1}
+
+# Which argument types each verb accepts; %v, %T and %p take anything.
+_ACCEPTS = {
+    "b": {"int", "float"},
+    "c": {"int"},
+    "d": {"int"},
+    "o": {"int"},
+    "O": {"int"},
+    "U": {"int"},
+    "x": {"int", "float", "string"},
+    "X": {"int", "float", "string"},
+    "e": {"float"},
+    "E": {"float"},
+    "f": {"float"},
+    "F": {"float"},
+    "g": {"float"},
+    "G": {"float"},
+    "s": {"string"},
+    "q": {"string", "int"},
+    "t": {"bool"},
+}
+_ANY = {"v", "T", "p", "w"}
+
+_FLAGS = "+-# 0"
+# `func Logf(` or `func (l *Logger) Logf(`: the declaration, not a call.
+_DECLARATION_RE = re.compile(r"[ \t]*func[ \t]*(?:\([^)]*\)[ \t]*)?$")
+_INDEX_RE = re.compile(r"\[(\d+)\]")
+_INT_RE = re.compile(r"-?(?:0[xXoObB][\da-fA-F_]+|\d[\d_]*)")
+_FLOAT_RE = re.compile(r"-?(?:\d[\d_]*\.[\d_]*(?:[eE][-+]?\d+)?|\d[\d_]*[eE][-+]?\d+|\.\d[\d_]*)")
+# Calls known to return a string; the call must be the whole argument.
+_STRING_CALL_RE = re.compile(
+    r"(?:fmt\.Sprint[fln]?|strconv\.(?:Itoa|Quote\w*|Format\w+)"
+    r"|strings\.(?:Join|Repeat|Replace\w*|Title|To\w+|Trim\w*)"
+    r"|\w+(?:\.\w+)*\.(?:String|Error))\("
+)
+_BASIC_TYPES = {
+    "string": "string",
+    "bool": "bool",
+    "float32": "float",
+    "float64": "float",
+    **{
+        t: "int"
+        for t in (
+            "int", "int8", "int16", "int32", "int64", "rune",
+            "uint", "uint8", "uint16", "uint32", "uint64", "uintptr", "byte",
+        )
+    },
+}
+
+
+@dataclass(frozen=True)
+class Directive:
+    """One ``%`` directive: the verb (``*`` for a star width) and the argument it takes."""
+
+    verb: str
+    arg: int
+    text: str
+
+
+def parse_format(literal: str) -> tuple[list[Directive], bool, list[str]]:
+    """(directives, whether any uses an explicit index, unknown verbs) of ``literal``.
+
+    Argument numbers are 0-based among the arguments after the format.
+    """
+    directives: list[Directive] = []
+    unknown: list[str] = []
+    indexed = False
+    arg = 0
+    i = 0
+    while i < len(literal):
+        if literal[i] != "%":
+            i += 1
+            continue
+        start = i
+        i += 1
+        while i < len(literal) and literal[i] in _FLAGS:
+            i += 1
+        for part in ("width", "precision"):
+            if part == "precision":
+                if i >= len(literal) or literal[i] != ".":
+                    break
+                i += 1
+            m = _INDEX_RE.match(literal, i)
+            if m:
+                indexed, arg, i = True, int(m.group(1)) - 1, m.end()
+            if i < len(literal) and literal[i] == "*":
+                directives.append(Directive("*", arg, literal[start : i + 1]))
+                arg, i = arg + 1, i + 1
+            else:
+                while i < len(literal) and literal[i].isdigit():
+                    i += 1
+        m = _INDEX_RE.match(literal, i)
+        if m:
+            indexed, arg, i = True, int(m.group(1)) - 1, m.end()
+        if i >= len(literal):
+            break
+        verb = literal[i]
+        i += 1
+        if verb == "%" and i - start == 2:
+            continue
+        if verb not in _ACCEPTS and verb not in _ANY:
+            unknown.append(literal[start:i])
+            continue
+        directives.append(Directive(verb, arg, literal[start:i]))
+        arg += 1
+    return directives, indexed, unknown
+
+
+def _literal_text(raw: str) -> str | None:
+    """The text of a single Go string literal, or None if ``raw`` is not one."""
+    raw = raw.strip()
+    if len(raw) < 2 or raw[0] not in "\"`" or raw[-1] != raw[0]:
+        return None
+    body = raw[1:-1]
+    if raw[0] == '"' and re.search(r'(?<!\\)(?:\\\\)*"', body):
+        return None  # "a" + "b": more than one literal
+    return body
+
+
+def _declared_types(file: GoFile, offset: int) -> dict[str, str | None]:
+    """Basic types of the names the function around ``offset`` declares; None if ambiguous."""
+    func = next((f for f in _functions(file) if f.start < offset < f.body_close), None)
+    if func is None:
+        return {}
+    masked, content = file.masked, file.content
+
+    def build() -> dict[str, str | None]:
+        found: dict[str, str | None] = {}
+
+        def add(name: str, type_: str | None) -> None:
+            found[name] = type_ if found.get(name, type_) == type_ else None
+
+        header = masked[func.start : func.body_open]
+        for names, type_ in re.findall(r"(\w+(?:[ \t]*,[ \t]*\w+)*)[ \t]+(\w+)[ \t]*[,)]", header):
+            for name in re.findall(r"\w+", names):
+                add(name, _BASIC_TYPES.get(type_))
+        body = range(func.body_open, func.body_close)
+        for m in re.finditer(r"\bvar[ \t]+(\w+)[ \t]+(\w+)\b", masked[body.start : body.stop]):
+            add(m.group(1), _BASIC_TYPES.get(m.group(2)))
+        for m in re.finditer(r"(?<![\w.])(\w+)[ \t]*:=[ \t]*", masked[body.start : body.stop]):
+            start = body.start + m.end()
+            end = masked.find("\n", start)
+            value = content[start : start + len(masked[start:end].rstrip())]
+            add(m.group(1), _literal_type(value))
+        return found
+
+    return file.memo(f"printf:declared:{func.body_open}", build)
+
+
+def _literal_type(arg: str) -> str | None:
+    if _literal_text(arg) is not None:
+        return "string"
+    if arg in ("true", "false"):
+        return "bool"
+    if re.fullmatch(r"'(?:[^'\\]|\\.[^']*)'", arg) or _INT_RE.fullmatch(arg):
+        return "int"
+    if _FLOAT_RE.fullmatch(arg):
+        return "float"
+    return None
+
+
+def arg_type(file: GoFile, start: int, end: int) -> str | None:
+    """The basic type of the argument at ``start``..``end``, if obvious without types."""
+    raw = file.content[start:end]
+    arg = raw.strip()
+    start += len(raw) - len(raw.lstrip())
+    known = _literal_type(arg)
+    if known is not None:
+        return known
+    masked = file.masked
+    call = re.compile(r"(?:len|cap)\(").match(masked, start) or _STRING_CALL_RE.match(masked, start)
+    if call is not None:
+        if _closing_paren(masked, call.end() - 1) != start + len(arg) - 1:
+            return None
+        return "int" if call.group().startswith(("len", "cap")) else "string"
+    if re.fullmatch(r"\w+", arg):
+        return _declared_types(file, start).get(arg)
+    return None
+
+
+def _wrappers(specs: list[str]) -> dict[str, int]:
+    """``name`` or ``name:index`` entries of the ``functions`` option, by name."""
+    found = {}
+    for spec in specs:
+        name, _, index = spec.partition(":")
+        if index and not index.isdigit():
+            continue
+        found[name] = int(index or 0)
+    return found
+
+
+def _problem(
+    file: GoFile,
+    function: str,
+    literal: str,
+    args: list[tuple[int, int]],
+    variadic: bool,
+) -> dict | None:
+    """What is wrong with one call whose format is ``literal``, or None."""
+    directives, indexed, unknown = parse_format(literal)
+    if unknown:
+        return {"problem": "unknown_verb", "verb": unknown[0]}
+    for d in directives:
+        if d.arg < 0 or (indexed and d.arg >= len(args) and not variadic):
+            return {"problem": "bad_index", "verb": d.text, "args": len(args)}
+        if d.arg >= len(args):
+            if variadic:
+                continue
+            needed = max(x.arg for x in directives) + 1
+            return {"problem": "too_few_args", "needs": needed, "args": len(args)}
+        if d.verb == "w" and function in PRINTF_FUNCTIONS and function != "fmt.Errorf":
+            return {"problem": "unknown_verb", "verb": d.text}
+        accepts = {"int"} if d.verb == "*" else _ACCEPTS.get(d.verb)
+        type_ = arg_type(file, *args[d.arg]) if accepts else None
+        if type_ is not None and type_ not in accepts:
+            return {
+                "problem": "type_mismatch",
+                "verb": d.text,
+                "arg": file.content[args[d.arg][0] : args[d.arg][1]].strip(),
+                "arg_type": type_,
+            }
+    used = max((d.arg for d in directives), default=-1) + 1
+    if not indexed and not variadic and used < len(args):
+        return {"problem": "too_many_args", "needs": used, "args": len(args)}
+    return None
+
+
+def detect_printf_mismatch(pass_: Pass) -> None:
+    """Detect Printf-family calls whose format does not fit their arguments.
+
+    ``fmt.Printf``, ``Sprintf``, ``Errorf`` and ``Fprintf`` are checked, and
+    the ``functions`` option adds wrappers as ``name`` or ``pkg.Name``, with
+    ``:index`` for a format that is not the first argument
+    (``"Logf:1"``). One problem is reported per call.
+    """
+    source = pass_.file
+    masked, content = source.masked, source.content
+    functions = {**PRINTF_FUNCTIONS, **_wrappers(pass_.options["functions"])}
+    for call in _CALL_RE.finditer(masked):
+        receiver = re.sub(r"\s+", "", call.group(1)).rstrip(".")
+        name = call.group(2)
+        qualified = f"{receiver}.{name}" if receiver else name
+        function = qualified if qualified in functions else name
+        index = functions.get(function)
+        line_start = source.line_start(call.start())
+        if index is None or _DECLARATION_RE.match(masked, line_start, call.start()):
+            continue
+        close = _closing_paren(masked, call.end() - 1)
+        if close is None:
+            continue
+        spans = [
+            (a, b) for a, b in _split_top_level(masked, call.end(), close, ",")
+            if masked[a:b].strip()
+        ]
+        if len(spans) <= index:
+            continue
+        literal = _literal_text(content[spans[index][0] : spans[index][1]])
+        if literal is None:
+            continue
+        args = spans[index + 1 :]
+        variadic = bool(args) and masked[args[-1][0] : args[-1][1]].rstrip().endswith("...")
+        problem = _problem(source, function, literal, args, variadic)
+        if problem is not None:
+            pass_.report(source.line_at(call.start()), function=qualified, **problem)
+
+
+__all__ = [
+    "Directive",
+    "PRINTF_FUNCTIONS",
+    "arg_type",
+    "detect_printf_mismatch",
+    "parse_format",
+]
@@ -0,0 +1,303 @@
+"""Resources acquired in a function and not released on every path out of it.
+
+``f, err := os.Open(p)`` followed by an early ``return`` before
+``f.Close()`` leaks the file on that path; ``defer f.Close()`` right after
+the error check cannot. The paths are walked the way
+``mutex_unlock_missing`` walks them (``if``/``else``, loops, ``switch``,
+``select``, labeled ``break`` and ``continue``), from the acquisition, or
+from the end of the ``if err != nil`` check right after it, since a failed
+acquisition has nothing to release.
+
+Which calls acquire and what releases them is a table of
+``acquire:release`` pairs. The acquire side is a function or method name,
+qualified (``os.Open``) or not (``Begin`` matches ``db.Begin``); the
+release side is a method, or a path such as ``Body.Close``, called on the
+first value the acquire returns. Alternatives are
+separated by ``|``: a transaction is done with by ``Rollback`` or
+``Commit``. An acquire called as a statement of its own (``s.mu.Lock()``)
+returns nothing to release, so a release method is called on its receiver,
+and the lock is left alone where ``mutex_unlock_missing`` leaves it: a
+deferred unlock, a mutex passed on or unlocked by another function, and
+functions named for locking.
+
+A path that returns the resource, or returns what its release returns
+(``return tx.Commit()``), hands it on; a ``defer``
+that mentions it is taken to release it. A resource stored in a field,
+a map, a slice or a composite literal, sent on a channel or captured by
+a goroutine has another owner and is left alone; passing it to a call
+(``io.ReadAll(f)``) does not.
+
+Query rows have a contract of their own, checked by ``sql_rows_misuse``:
+close them, check ``rows.Err()`` after the ``for rows.Next()`` loop, and
+do not use them after ``Close``.
+"""
+
+from __future__ import annotations
+
+import re
+from dataclasses import dataclass
+
+from desloppify.languages.go.detectors._inspector import GoFile, Pass
+from desloppify.languages.go.detectors._source import matching_brace
+from desloppify.languages.go.detectors.concurrency import (
+    _enclosing_spans,
+    _function_spans,
+    _lock_left_to_others,
+    _statement_end,
+    _unreleased,
+)
+from desloppify.languages.go.detectors.error_flow import _closing_paren
+from desloppify.languages.go.detectors.reuse import _packages
+
+RELEASE_PAIRS = (
+    "os.Open:Close",
+    "os.OpenFile:Close",
+    "os.Create:Close",
+    "os.CreateTemp:Close",
+    "Begin:Rollback|Commit",
+    "BeginTx:Rollback|Commit",
+    "http.Get:Body.Close",
+    "http.Head:Body.Close",
+    "http.Post:Body.Close",
+    "http.PostForm:Body.Close",
+    "Do:Body.Close",
+    "Lock:Unlock",
+    "RLock:RUnlock",
+)
+
+# `f, err :=` / `tx, err =` before the call.
+_ASSIGNED_RE = re.compile(r"[ \t]*(\w+)(?:[ \t]*,[ \t]*(\w+))?[ \t]*:?=[ \t]*$")
+
+
+@dataclass(frozen=True)
+class ReleasePair:
+    """One row of the table: a call that acquires, and the methods that release."""
+
+    acquire: str
+    releases: tuple[str, ...]
+
+    @property
+    def pattern(self) -> re.Pattern[str]:
+        """The acquire call with its receiver, up to the ``(``."""
+        if "." in self.acquire:
+            return re.compile(rf"(?<![\w.]){re.escape(self.acquire)}\(")
+        return re.compile(rf"(?<![\w.])(?:(?:\w+\.)*\w+\.)?{re.escape(self.acquire)}\(")
+
+
+def parse_pairs(specs: list[str] | tuple[str, ...]) -> list[ReleasePair]:
+    """``acquire:release|release`` entries as pairs; malformed ones are skipped."""
+    pairs = []
+    for spec in specs:
+        acquire, _, releases = spec.partition(":")
+        names = tuple(r.strip() for r in releases.split("|") if r.strip())
+        if re.fullmatch(r"(?:\w+\.)?\w+", acquire.strip()) and names:
+            pairs.append(ReleasePair(acquire.strip(), names))
+    return pairs
+
+
+def _release_re(resource: str, releases: tuple[str, ...]) -> re.Pattern[str]:
+    """A statement that releases ``resource``, returns it, or defers something with it."""
+    name = re.escape(resource)
+    calls = "|".join(re.escape(r) for r in releases)
+    return re.compile(
+        rf"(?:(?:if[ \t]+)?[\w, \t]*:?=[ \t]*)?{name}\.(?:{calls})\("
+        rf"|return\b[^\n]*(?<![\w.]){name}\b(?![ \t]*\.)"
+        rf"|return\b[^\n]*(?<![\w.]){name}\.(?:{calls})\("
+        rf"|defer\b[^\n]*(?<![\w.]){name}\b"
+    )
+
+
+def _escapes(body: str, resource: str) -> bool:
+    """Whether ``body`` hands ``resource`` to an owner other than the function."""
+    name = re.escape(resource)
+    return bool(
+        re.search(rf"(?<![=!<>:])=[ \t]*&?{name}[ \t]*(?:$|[,;)}}])", body, re.MULTILINE)
+        or re.search(rf"\w[ \t]*:[ \t]*&?{name}[ \t]*[,}}\n]", body)
+        or re.search(rf"\bappend\([^\n]*(?<![\w.]){name}\b", body)
+        or re.search(rf"<-[ \t]*{name}\b", body)
+        or re.search(rf"^[ \t]*go\b[^\n]*(?<![\w.]){name}\b", body, re.MULTILINE)
+    )
+
+
+def detect_resource_not_released(pass_: Pass) -> None:
+    """Detect resources with a path out of the function that skips their release.
+
+    Reported at the acquisition, with the line of the first path that
+    leaks it (``leaks_at``): a ``return``, a ``break`` or ``continue`` out
+    of the code after it, or the end of the function or of the loop body
+    it was acquired in. The ``pairs`` option adds rows to the table.
+    """
+    source = pass_.file
+    masked = source.masked
+    pairs = parse_pairs(RELEASE_PAIRS) + parse_pairs(pass_.options["pairs"])
+    seen: set[int] = set()
+    for pair in pairs:
+        for call in pair.pattern.finditer(masked):
+            if call.start() in seen:
+                continue
+            spans = _enclosing_spans(source, call.start())
+            close = _closing_paren(masked, call.end() - 1)
+            if not spans or close is None:
+                continue
+            _, body_open, body_close = spans[0]
+            line_end = masked.find("\n", close)
+            if masked[close + 1 : line_end].strip():
+                continue  # `os.Open(p).Close()`, or part of a larger expression
+            lead = masked[source.line_start(call.start()) : call.start()]
+            assigned = _ASSIGNED_RE.fullmatch(lead)
+            receiver = call.group(0)[: -len(pair.acquire) - 1].rstrip(".")
+            if assigned:
+                resource, err = assigned.group(1), assigned.group(2)
+            elif (
+                lead.strip()
+                or not receiver
+                or receiver in _packages(source)
+                or any("." in release for release in pair.releases)
+            ):
+                continue
+            else:
+                resource, err = receiver, None
+                if any(
+                    _lock_left_to_others(pass_, receiver, release, close + 1, spans[0])
+                    for release in pair.releases
+                ):
+                    continue
+            if resource == "_" or _escapes(masked[line_end:body_close], resource):
+                continue
+            seen.add(call.start())
+            start = line_end
+            if err is not None:
+                check = re.compile(
+                    rf"\s*if[ \t]+{re.escape(err)}[ \t]*!=[ \t]*nil[ \t]*\{{"
+                ).match(masked, start)
+                close_check = matching_brace(masked, check.end() - 1) if check else None
+                if close_check is not None:
+                    start = _statement_end(masked, close_check)
+            leak = _unreleased(masked, body_open, start, _release_re(resource, pair.releases))
+            if leak is None:
+                continue
+            release = f"{resource}.{pair.releases[0]}()"
+            when = f"once {resource} is known to be valid" if assigned else "right after the lock"
+            pass_.report(
+                source.line_at(call.start()),
+                resource=resource,
+                acquire=pair.acquire,
+                release=release,
+                leaks_at=source.line_at(leak),
+                hint=f"defer {release} {when}",
+            )
+
+
+ROWS_TYPES = ("sql.Rows",)
+
+# `rows, err := db.QueryContext(ctx, q)`: the two results of a query.
+_QUERY_RE = re.compile(
+    r"^[ \t]*(\w+)[ \t]*,[ \t]*\w+[ \t]*:?=[ \t]*(?:\w+\.)+Query(?:Context)?\(", re.MULTILINE
+)
+
+
+def _row_params(header: str, types: list[str]) -> list[str]:
+    """Parameters in ``header`` declared with one of the row ``types``."""
+    names = []
+    for type_ in types:
+        pattern = rf"(\w+(?:[ \t]*,[ \t]*\w+)*)[ \t]+\*?{re.escape(type_)}\b"
+        for m in re.finditer(pattern, header):
+            names.extend(re.findall(r"\w+", m.group(1)))
+    return names
+
+
+def _handed_over(body: str, name: str) -> bool:
+    """Whether ``body`` passes ``name`` to a call, returns it or stores it."""
+    word = re.escape(name)
+    return _escapes(body, name) or bool(
+        re.search(rf"[(,][ \t]*{word}[ \t]*[,)]", body)
+        or re.search(rf"\breturn\b[^\n]*(?<![\w.]){word}\b", body)
+    )
+
+
+def _rows_problems(
+    source: GoFile, span: tuple[str, int, int], name: str, start: int, *, owned: bool
+) -> list[tuple[int, str]]:
+    """(offset, problem) for each broken part of the rows contract after ``start``."""
+    masked = source.masked
+    body_close = span[2]
+    rows = re.escape(name)
+    problems = []
+    closes = list(re.finditer(rf"(?<![\w.]){rows}\.Close\(\)", masked[:body_close]))
+    closes = [m for m in closes if m.start() > start]
+    if owned and not closes:
+        problems.append((start, "not_closed"))
+    loops = re.compile(rf"^[ \t]*for[ \t]+{rows}\.Next\(\)[ \t]*\{{", re.MULTILINE)
+    for loop in loops.finditer(masked, start, body_close):
+        end = matching_brace(masked, loop.end() - 1)
+        checked = re.compile(rf"(?<![\w.]){rows}\.Err\(\)").search(masked, end or start, body_close)
+        if end is not None and checked is None:
+            problems.append((loop.start(), "err_unchecked"))
+    for close in closes:
+        line = masked[source.line_start(close.start()) : close.start()]
+        if "defer" in line or _enclosing_spans(source, close.start())[0] != span:
+            continue  # deferred, or closed in a deferred function literal
+        use = re.compile(rf"(?<![\w.]){rows}\.(?!Close\(|Err\()\w+").search(
+            masked, close.end(), body_close
+        )
+        if use is not None:
+            problems.append((use.start(), "used_after_close"))
+            break
+    return problems
+
+
+def detect_sql_rows_misuse(pass_: Pass) -> None:
+    """Detect query rows that are not closed, not checked with Err, or used after Close.
+
+    Rows come from a two-value ``Query``/``QueryContext`` assignment, or
+    from a parameter of one of the ``types`` (``sql.Rows`` by default; add
+    ``pgx.Rows``). Each broken piece is its own finding, with a
+    ``problem``: ``not_closed`` at the query when the function never calls
+    or defers ``Close``; ``err_unchecked`` at a ``for rows.Next()`` loop
+    with no ``rows.Err()`` after it, since the loop also stops on errors;
+    ``used_after_close`` at a use of the rows after an undeferred
+    ``Close``. Rows passed to a call, returned or stored belong to someone
+    else, and a parameter is never expected to be closed by its function.
+    """
+    source = pass_.file
+    masked = source.masked
+    hints = {
+        "not_closed": "defer {rows}.Close() after the error check",
+        "err_unchecked": "check {rows}.Err() after the loop",
+        "used_after_close": "finish with {rows} before closing it",
+    }
+    found: list[tuple[int, str, str]] = []
+    for span in _function_spans(source):
+        _, body_open, body_close = span
+        header = masked[masked.rfind("func", 0, body_open) : body_open]
+        for name in _row_params(header, pass_.options["types"]):
+            problems = _rows_problems(source, span, name, body_open, owned=False)
+            found += [(offset, name, problem) for offset, problem in problems]
+        for query in _QUERY_RE.finditer(masked, body_open, body_close):
+            name = query.group(1)
+            if _enclosing_spans(source, query.start())[0] != span:
+                continue
+            if _handed_over(masked[query.end() : body_close], name):
+                continue  # a helper or the caller owns the rows
+            problems = _rows_problems(source, span, name, query.end(), owned=True)
+            found += [
+                (query.start() if problem == "not_closed" else offset, name, problem)
+                for offset, problem in problems
+            ]
+    for offset, name, problem in sorted(set(found)):
+        pass_.report(
+            source.line_at(offset),
+            rows=name,
+            problem=problem,
+            hint=hints[problem].format(rows=name),
+        )
+
+
+__all__ = [
+    "RELEASE_PAIRS",
+    "ROWS_TYPES",
+    "ReleasePair",
+    "detect_resource_not_released",
+    "detect_sql_rows_misuse",
+    "parse_pairs",
+]
@@ -0,0 +1,208 @@
+"""Local variables reused for unrelated values.
+
+``data`` that first holds a request body, then a decoded name, then a row
+count is three variables sharing one name, and every reader has to work
+out which one a line means. ``variable_reuse`` (opt-in) reports a local
+variable or parameter given a fresh value more than ``max_reassignments``
+times after its first, when at least two of those values come from
+different places: calls into different imported packages
+(``io.ReadAll``, ``strconv.Itoa``), or expressions of different types
+before any conversion to the variable's interface type. A value's type
+comes from ``go/types`` when the package is type-checked, otherwise from
+its shape: a string literal, a composite literal (``T{}``, ``&T{}``), a
+conversion (``string(b)``) or ``make``/``new``.
+
+Only full assignments count. Updates that read the variable
+(``x += n``, ``s = append(s, v)``, ``s = strings.TrimSpace(s)``) and
+resets to a zero value (``x = nil``) do not; ``var x T`` gives no value,
+so counting starts at the first assignment. Errors (``err``, ``ok``),
+loop counters (anything incremented, or declared in an ``if``, ``for`` or
+``switch`` header or a ``case``) and generated files are left alone.
+"""
+
+from __future__ import annotations
+
+import re
+from dataclasses import dataclass
+
+from desloppify.languages.go.detectors._inspector import GoFile, Pass
+from desloppify.languages.go.detectors.assignments import (
+    _ERROR_NAME_RE,
+    _NAMES,
+    _ZERO_RE,
+    _Def,
+    function_variables,
+)
+from desloppify.languages.go.detectors.custom_rules import _imports
+from desloppify.languages.go.detectors.logic import _split_top_level
+from desloppify.languages.go.detectors.naming import _GENERATED_RE
+
+_NAMES_RE = re.compile(_NAMES)
+# The character before `=` in `x += n`, `x <<= n`, `x &^= n`.
+_COMPOUND_OPERATORS = frozenset("+-*/%&|^<>")
+_DECLARE_RE = re.compile(rf"{_NAMES}[ \t]*:=")
+_VAR_BEFORE_RE = re.compile(r"\bvar[ \t]+$")
+_CASE_RE = re.compile(r"^[ \t]*(?:case\b|default[ \t]*:)", re.MULTILINE)
+_PACKAGE_CALL_RE = re.compile(r"(\w+)\.\w+(?:\[[^\]\n]*\])?\(")
+_COMPOSITE_RE = re.compile(r"(&?(?:\[\d*\]|map\[[^\]\n]*\])*\*?[\w.]+)(?:\[[^\]\n]*\])?\{")
+_MAKE_RE = re.compile(r"(make|new)\((\*?(?:\[\d*\]|map\[[^\]\n]*\])*\*?[\w.]+)")
+_CONVERSION_RE = re.compile(
+    r"((?:\[\])?(?:string|byte|rune|bool|u?int(?:8|16|32|64)?|float(?:32|64)|uintptr))\("
+)
+_STRING_RE = re.compile(r"[\"`]")
+_KEYWORDS = frozenset({"func", "struct", "interface", "map", "chan"})
+
+
+@dataclass(frozen=True)
+class _Value:
+    offset: int
+    package: str | None
+    type_: str | None
+
+    def differs(self, other: _Value) -> bool:
+        if self.package and other.package and self.package != other.package:
+            return True
+        return bool(self.type_ and other.type_ and self.type_ != other.type_)
+
+
+def _packages(source: GoFile) -> set[str]:
+    return source.memo(
+        "reuse:packages",
+        lambda: {name or path.rsplit("/", 1)[-1] for name, path, _ in _imports(source)},
+    )
+
+
+def _rhs_span(masked: str, name: str, definition: _Def) -> tuple[int, int] | None:
+    """Where the value ``definition`` gives ``name`` is, or None for ``x op= v``."""
+    end = len(masked[: definition.end].rstrip())
+    start = end - len(definition.rhs)
+    operator = masked[:start].rstrip()
+    if not operator.endswith("=") or operator[-2:-1] in _COMPOUND_OPERATORS:
+        return None
+    names = re.findall(r"\w+", _NAMES_RE.match(masked, definition.start).group())
+    values = _split_top_level(masked, start, end, ",")
+    if len(values) == len(names) > 1:
+        start, end = values[names.index(name)]
+        text = masked[start:end]
+        start += len(text) - len(text.lstrip())
+        end = start + len(text.strip())
+    return (start, end) if start < end else None
+
+
+def _value(pass_: Pass, start: int, end: int) -> _Value:
+    """Where the expression at ``start``..``end`` comes from: its package and type."""
+    masked = pass_.file.masked
+    package = None
+    m = _PACKAGE_CALL_RE.match(masked, start)
+    if m is not None and m.group(1) in _packages(pass_.file):
+        package = m.group(1)
+    type_ = pass_.type_of(start, end)
+    if type_ is not None and type_.startswith("("):
+        type_ = None  # the tuple of a call with several results
+    elif type_ is None:
+        if _STRING_RE.match(masked, start):
+            type_ = "string"
+        elif (m := _MAKE_RE.match(masked, start)) is not None:
+            type_ = ("*" if m.group(1) == "new" else "") + m.group(2)
+        elif (m := _CONVERSION_RE.match(masked, start)) is not None:
+            type_ = m.group(1)
+        elif (m := _COMPOSITE_RE.match(masked, start)) is not None:
+            if m.group(1).lstrip("&*") not in _KEYWORDS:
+                type_ = m.group(1).replace("&", "*", 1)
+    return _Value(start, package, type_)
+
+
+def _counter(masked: str, name: str, body_open: int, body_close: int) -> bool:
+    return bool(
+        re.search(rf"(?<![\w.]){re.escape(name)}[ \t]*(?:\+\+|--)", masked[body_open:body_close])
+    )
+
+
+def _declares(masked: str, previous: _Def, definition: _Def) -> bool:
+    """Whether ``definition`` declares a new variable rather than assigning ``previous``'s."""
+    if _VAR_BEFORE_RE.search(masked, previous.end, definition.start):
+        return True
+    m = _DECLARE_RE.match(masked, definition.start)
+    if m is None:
+        return False
+    return "," not in m.group(1) or bool(_CASE_RE.search(masked, previous.end, definition.start))
+
+
+def _runs(masked: str, defs: list[_Def]) -> list[list[_Def]]:
+    """``defs`` split where ``x := v`` or ``var x`` declares the name again.
+
+    Case clauses have no braces, so ``x :=`` in two of them looks like one
+    variable declared twice; each is its own.
+    """
+    runs: list[list[_Def]] = [[]]
+    for definition in defs:
+        if runs[-1] and _declares(masked, runs[-1][-1], definition):
+            runs.append([])
+        runs[-1].append(definition)
+    return runs
+
+
+def _error_slot(masked: str, name: str, definition: _Def) -> bool:
+    """Whether ``definition`` gives ``name`` the last result of a call: ``v, e := f()``."""
+    names = _NAMES_RE.match(masked, definition.start)
+    listed = re.findall(r"\w+", names.group()) if names is not None else []
+    return len(listed) > 1 and listed[-1] == name and "(" in definition.rhs and (
+        len(_split_top_level(definition.rhs, 0, len(definition.rhs), ",")) == 1
+    )
+
+
+def _report_reuse(pass_: Pass, name: str, defs: list[_Def], name_re: re.Pattern[str]) -> None:
+    source = pass_.file
+    masked = source.masked
+    values = []
+    for definition in defs:
+        if not definition.reportable or _ZERO_RE.fullmatch(definition.rhs):
+            continue
+        span = _rhs_span(masked, name, definition)
+        if span is None or name_re.search(masked, *span):
+            continue  # an update, not a new value
+        values.append((definition, _value(pass_, *span)))
+    reassigned = len(values) - (1 if values and values[0][0] is defs[0] else 0)
+    if reassigned <= pass_.options["max_reassignments"]:
+        return
+    derived = [value for _, value in values]
+    if not any(a.differs(b) for i, a in enumerate(derived) for b in derived[i + 1 :]):
+        return
+    sites = [source.line_at(value.offset) for value in derived]
+    pass_.report(
+        sites[0],
+        variable=name,
+        sites=sites,
+        hint=f"{name} holds {len(values)} unrelated values (lines "
+        f"{', '.join(map(str, sites))}); give each its own name",
+    )
+
+
+def detect_variable_reuse(pass_: Pass) -> None:
+    """Detect local variables given several values of unrelated origin.
+
+    Reported at the first value with the ``variable`` and the ``sites``,
+    the lines of every value it is given.
+    """
+    source = pass_.file
+    if _GENERATED_RE.search(source.content):
+        return
+    masked = source.masked
+    for func in function_variables(source):
+        for var in func.variables:
+            if var.name == "ok" or _ERROR_NAME_RE.fullmatch(var.name):
+                continue
+            defs = sorted(var.defs, key=lambda d: d.start)
+            first = defs[0]
+            if var.block < 0 or _CASE_RE.match(masked, source.line_start(first.start)):
+                continue  # declared in an if/for/switch header or a case
+            if _counter(masked, var.name, func.body_open, func.body_close):
+                continue
+            if any(_error_slot(masked, var.name, d) for d in defs):
+                continue  # an error or a found flag under another name
+            name_re = re.compile(rf"(?<![\w.]){re.escape(var.name)}\b")
+            for run in _runs(masked, defs):
+                _report_reuse(pass_, var.name, run, name_re)
+
+
+__all__ = ["detect_variable_reuse"]
@@ -0,0 +1,163 @@
+"""Parameters that travel together across a package's functions.
+
+``too_many_params`` counts parameters one function at a time. This rule
+looks at every signature in the package and finds runs of three or more
+parameters (same names, same types, same order, though other parameters may
+sit between them) shared by three or more functions:
+``Dial(host string, port int, timeout time.Duration)`` and its siblings.
+Such a group wants to be an options struct. Candidates are the longest
+common subsequences of each pair of signatures, refined against every other
+signature; a group is reported once, at its first function, listing the
+rest. ``context.Context`` parameters are left out, since Go keeps contexts
+out of structs. It needs the whole package, so ``scan --fast`` skips it.
+"""
+
+from __future__ import annotations
+
+import re
+from dataclasses import dataclass
+
+from desloppify.languages.go.detectors._inspector import GoFile, Pass
+from desloppify.languages.go.detectors.error_flow import _closing_paren
+from desloppify.languages.go.detectors.logic import _split_top_level
+from desloppify.languages.go.detectors.panics import _FUNC_RE
+
+MIN_PARAMS = 3
+MIN_FUNCTIONS = 3
+
+Param = tuple[str, str]
+
+
+@dataclass(frozen=True)
+class Signature:
+    """A function's named parameters, in order."""
+
+    name: str
+    path: str
+    line: int
+    params: tuple[Param, ...]
+
+
+@dataclass(frozen=True)
+class Cluster:
+    """Parameters shared, in order, by several functions."""
+
+    params: tuple[Param, ...]
+    functions: tuple[Signature, ...]
+
+
+def _params(masked: str, open_paren: int) -> tuple[Param, ...] | None:
+    """(name, type) pairs of the list at ``open_paren``; None if any is unnamed."""
+    close = _closing_paren(masked, open_paren)
+    if close is None:
+        return None
+    params: list[Param] = []
+    pending: list[str] = []
+    for start, end in _split_top_level(masked, open_paren + 1, close, ","):
+        text = " ".join(masked[start:end].split())
+        if not text:
+            continue
+        name, _, type_ = text.partition(" ")
+        if not re.fullmatch(r"\w+", name):
+            return None
+        if not type_:
+            pending.append(name)  # `a, b string`: the type comes with the last name
+            continue
+        params.extend((n, type_) for n in (*pending, name))
+        pending = []
+    return None if pending else tuple(params)
+
+
+def file_signatures(file: GoFile) -> list[Signature]:
+    """Signatures of the functions and methods declared in ``file``."""
+    def build() -> list[Signature]:
+        masked = file.masked
+        found = []
+        for m in _FUNC_RE.finditer(masked):
+            params = _params(masked, m.end() - 1)
+            if params is None:
+                continue
+            params = tuple(p for p in params if p[1] != "context.Context")
+            found.append(Signature(m.group(1), file.path, file.line_at(m.start()), params))
+        return found
+
+    return file.memo("signatures:functions", build)
+
+
+def _lcs(a: tuple[Param, ...], b: tuple[Param, ...]) -> tuple[Param, ...]:
+    """Longest common subsequence of two parameter lists."""
+    table = [[()] * (len(b) + 1) for _ in range(len(a) + 1)]
+    for i in range(len(a) - 1, -1, -1):
+        for j in range(len(b) - 1, -1, -1):
+            if a[i] == b[j]:
+                table[i][j] = (a[i], *table[i + 1][j + 1])
+            else:
+                table[i][j] = max(table[i + 1][j], table[i][j + 1], key=len)
+    return table[0][0]
+
+
+def _contains(params: tuple[Param, ...], group: tuple[Param, ...]) -> bool:
+    remaining = iter(params)
+    return all(param in remaining for param in group)
+
+
+def clusters(signatures: list[Signature]) -> list[Cluster]:
+    """Groups of ``MIN_PARAMS``+ parameters shared by ``MIN_FUNCTIONS``+ functions."""
+    candidates = {
+        group
+        for i, a in enumerate(signatures)
+        for b in signatures[i + 1 :]
+        if len(group := _lcs(a.params, b.params)) >= MIN_PARAMS
+    }
+    # A group three functions share need not be any pair's longest one.
+    candidates |= {
+        group
+        for candidate in candidates
+        for sig in signatures
+        if len(group := _lcs(candidate, sig.params)) >= MIN_PARAMS
+    }
+    found: list[Cluster] = []
+    for group in sorted(candidates, key=lambda g: (-len(g), g)):
+        members = tuple(s for s in signatures if _contains(s.params, group))
+        if len(members) < MIN_FUNCTIONS:
+            continue
+        if any(
+            _contains(kept.params, group) and set(members) <= set(kept.functions)
+            for kept in found
+        ):
+            continue
+        found.append(Cluster(group, members))
+    return found
+
+
+def detect_shared_param_group(pass_: Pass) -> None:
+    """Report each shared parameter group at its first function, if that is in this file."""
+    source = pass_.file
+    if pass_.types is None:
+        return
+    files = pass_.types.files
+
+    def build() -> list[Cluster]:
+        signatures = [s for f in files for s in file_signatures(f)]
+        return clusters(sorted(signatures, key=lambda s: (s.path, s.line)))
+
+    for cluster in pass_.types.memo("signatures:clusters", build):
+        first = cluster.functions[0]
+        if first.path != source.path:
+            continue
+        pass_.report(
+            first.line,
+            params=[f"{name} {type_}" for name, type_ in cluster.params],
+            functions=[s.name for s in cluster.functions],
+            hint="group " + ", ".join(name for name, _ in cluster.params)
+            + " into an options struct",
+        )
+
+
+__all__ = [
+    "Cluster",
+    "Signature",
+    "clusters",
+    "detect_shared_param_group",
+    "file_signatures",
+]
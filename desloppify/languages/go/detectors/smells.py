@@ -6,168 +6,1741 @@ that standard Go linters (staticcheck, revive, gosec) don't cover.
 
 from __future__ import annotations
 
+import functools
+import heapq
+import json
+import os
 import re
+import time
+from collections.abc import Callable
 from pathlib import Path
 
+from desloppify.core.diagnostics import RunDiagnostics
+from desloppify.core.progress import ScanProgress
+from desloppify.core.result_cache import ResultCache
+from desloppify.core.runtime_state import raise_if_cancelled
+from desloppify.languages._framework.parallel import map_packages
+from desloppify.languages.go.detectors._inspector import (
+    GoFile,
+    Inspector,
+    PackageTypes,
+    Pass,
+)
+from desloppify.languages.go.detectors._source import is_suppressed
+from desloppify.languages.go.detectors.aliasing import (
+    detect_copy_length_ignored,
+    detect_slice_overlap_append,
+)
+from desloppify.languages.go.detectors.assignments import (
+    detect_error_overwritten,
+    detect_ineffective_assignment,
+    detect_pure_result_discarded,
+)
+from desloppify.languages.go.detectors.channels import (
+    detect_channel_as_mutex,
+    detect_goroutine_send_leak,
+    detect_magic_channel_buffer,
+)
+from desloppify.languages.go.detectors.composites import (
+    UNKEYED_EXCLUDES,
+    UNKEYED_MAX_FIELDS,
+    detect_unkeyed_struct_literal,
+)
+from desloppify.languages.go.detectors.concurrency import (
+    ATOMIC_EXCLUDES,
+    BLOCKING_KINDS,
+    detect_atomic_mixed_access,
+    detect_blocking_under_lock,
+    detect_busy_select_default,
+    detect_goroutine_index_capture,
+    detect_loop_ignores_context,
+    detect_mutex_unlock_missing,
+    detect_racy_lazy_init,
+    detect_send_after_close,
+    detect_waitgroup_add_in_goroutine,
+)
+from desloppify.languages.go.detectors.custom_rules import CustomRule, rule_detector
+from desloppify.languages.go.detectors.durations import (
+    detect_bare_duration,
+    detect_duration_double_unit,
+)
+from desloppify.languages.go.detectors.error_flow import (
+    BEST_EFFORT_PREFIXES,
+    detect_always_nil_error,
+    detect_defer_on_maybe_nil,
+    detect_deferred_error_ignored,
+    detect_error_not_last,
+    detect_error_only_logged,
+    detect_error_not_wrapped,
+    detect_errors_as_target,
+    detect_multiple_wrap_verbs,
+    detect_panic_string,
+    visit_useless_error_return,
+    visit_value_with_error,
+)
+from desloppify.languages.go.detectors.fields import detect_unused_field
+from desloppify.languages.go.detectors.function_length import (
+    CASE_CLAUSES,
+    COMPOSITE_LITERALS,
+    detect_long_function,
+)
+from desloppify.languages.go.detectors.hygiene import (
+    DEFAULT_NAME_STYLE_REASONS,
+    MAX_FILE_LINES,
+    MAX_PACKAGE_FILES,
+    NAME_STYLE_REASONS,
+    PLURAL_PACKAGES,
+    detect_file_too_long,
+    detect_package_dir_mismatch,
+    detect_package_name_style,
+    detect_package_test_heavy,
+    detect_package_too_many_files,
+)
+from desloppify.languages.go.detectors.imports import (
+    DOT_IMPORT_ALLOWED,
+    detect_blank_import,
+    detect_dot_import,
+)
+from desloppify.languages.go.detectors.jsontags import (
+    detect_json_tag_duplicate,
+    detect_json_tag_invalid,
+    detect_json_tag_missing,
+    detect_json_tag_unexported,
+    detect_json_unmarshal_non_pointer,
+)
+from desloppify.languages.go.detectors.logic import (
+    detect_duplicate_branch,
+    detect_ineffective_break,
+    detect_len_comparison,
+    detect_redundant_continue,
+    detect_unreachable_code,
+    visit_bool_literal_return,
+    visit_constant_condition,
+    visit_else_after_return,
+    visit_empty_branch,
+)
+from desloppify.languages.go.detectors.loops import (
+    visit_loop_condition_unchanged,
+    visit_loop_runs_once,
+)
+from desloppify.languages.go.detectors.messages import (
+    detect_message_function_name,
+    detect_message_stale_name,
+)
+from desloppify.languages.go.detectors.naming import (
+    NAME_ALLOWED,
+    NAME_MAX_LENGTH,
+    NAME_MAX_SCOPE_LINES,
+    detect_builtin_shadow,
+    detect_getter_prefix,
+    detect_name_length,
+)
+from desloppify.languages.go.detectors.nilchecks import (
+    detect_redundant_nil_check,
+    visit_redundant_error_check,
+)
+from desloppify.languages.go.detectors.panics import (
+    PANIC_EXPLAIN,
+    detect_must_call_in_function,
+    detect_panic_in_lib,
+    detect_panic_in_lib_helper,
+)
+from desloppify.languages.go.detectors.performance import (
+    detect_append_no_prealloc,
+    detect_double_map_lookup,
+    detect_sprintf_strconv,
+)
+from desloppify.languages.go.detectors.printf import detect_printf_mismatch
+from desloppify.languages.go.detectors.resources import (
+    ROWS_TYPES,
+    detect_resource_not_released,
+    detect_sql_rows_misuse,
+)
+from desloppify.languages.go.detectors.reuse import detect_variable_reuse
+from desloppify.languages.go.detectors.signatures import detect_shared_param_group
+from desloppify.languages.go.detectors.struct_layout import (
+    SMALL_TYPE_MAX_BYTES,
+    STRUCT_ALIGNMENT_MIN_BYTES,
+    detect_pointer_to_small_type,
+    detect_struct_field_alignment,
+)
+from desloppify.languages.go.detectors.structured_sprintf import (
+    detect_sprintf_duration,
+    detect_sprintf_path,
+    detect_sprintf_url,
+    detect_sprintf_url_param,
+)
+from desloppify.languages.go.detectors.time_layout import detect_time_layout
+from desloppify.languages.go.detectors.time_values import (
+    detect_time_equal,
+    detect_time_not_utc,
+)
 from desloppify.languages.go.extractors import find_go_files
+from desloppify.languages.go.package_keys import GoPackageKeyer
+from desloppify.languages.go.rule_options import (
+    ENABLED,
+    Option,
+    enum_option,
+    int_option,
+    resolve_options,
+    rule_switch,
+    str_list_option,
+)
+from desloppify.languages.go.rules import (
+    ensure_sources,
+    fingerprint,
+    registered_rules,
+)
+
+_CACHE_NAMESPACE = "go-smells"
+# Matches kept per smell entry; counts always cover every match.
+MATCH_SAMPLE = 50
+# Timing bucket for reading and masking files, which every rule shares.
+PARSE_RULE = "(parse)"
 
 
-def _smell(id: str, label: str, severity: str, pattern: str | None = None) -> dict:
-    return {"id": id, "label": label, "pattern": pattern, "severity": severity}
+def _smell(
+    id: str,
+    label: str,
+    severity: str,
+    pattern: str | None = None,
+    *,
+    opt_in: bool = False,
+    requires: str = "syntax",
+    fixable: bool = False,
+    options: tuple[Option, ...] = (),
+    explain: str = "",
+    categories: tuple[str, ...] = (),
+) -> dict:
+    return {
+        "id": id,
+        "label": label,
+        "pattern": pattern,
+        "severity": severity,
+        "opt_in": opt_in,
+        "requires": requires,
+        "fixable": fixable,
+        "options": options,
+        "explain": explain,
+        "categories": categories,
+    }
 
 
 SMELL_CHECKS = [
     _smell(
         "panic_in_lib",
-        "panic() in library code (non-main package)",
+        "panic() in library code reachable from the exported API",
         "high",
-        r"\bpanic\s*\(",
+        None,
+        explain=PANIC_EXPLAIN,
+        categories=("correctness",),
+    ),
+    _smell(
+        "panic_in_lib_helper",
+        "panic() in library code only reachable from unexported helpers",
+        "medium",
+        None,
+        explain=PANIC_EXPLAIN,
+        categories=("correctness",),
+    ),
+    _smell(
+        "must_call_in_function",
+        "Must* call with non-constant arguments inside a function (handle the error)",
+        "medium",
+        None,
+        options=(
+            str_list_option(
+                "functions",
+                (),
+                description="More helpers that panic on error, by name or as pkg.Name",
+            ),
+        ),
+        categories=("correctness", "security"),
     ),
     _smell(
         "fire_and_forget_goroutine",
         "Fire-and-forget goroutine (no sync mechanism)",
         "medium",
         r"^\s*go\s+(?:func\b|\w+\()",
+        categories=("concurrency",),
+    ),
+    _smell(
+        "goroutine_index_capture",
+        "Goroutine in a loop writes s[i] through the shared loop variable (before Go 1.22)",
+        "high",
+        None,
+        categories=("concurrency", "correctness"),
+    ),
+    _smell(
+        "mutex_unlock_missing",
+        "Mutex locked with a path that never unlocks it (defer the Unlock)",
+        "high",
+        None,
+        categories=("concurrency", "correctness"),
+    ),
+    _smell(
+        "resource_not_released",
+        "Resource with a path that never releases it (defer the release)",
+        "high",
+        None,
+        options=(
+            str_list_option(
+                "pairs",
+                (),
+                description="More acquire:release pairs, e.g. AcquireConn:Release, "
+                "with | between alternative releases",
+            ),
+        ),
+        categories=("correctness",),
+    ),
+    _smell(
+        "sql_rows_misuse",
+        "Query rows not closed, not checked with Err, or used after Close",
+        "high",
+        None,
+        options=(
+            str_list_option(
+                "types",
+                ROWS_TYPES,
+                description="Row types whose parameters are checked, e.g. pgx.Rows",
+            ),
+        ),
+        categories=("correctness",),
+    ),
+    _smell(
+        "racy_lazy_init",
+        "Lazy initialization racing with its readers (use sync.Once or atomic.Pointer)",
+        "high",
+        None,
+        categories=("concurrency", "correctness"),
+    ),
+    _smell(
+        "atomic_mixed_access",
+        "Variable accessed both through sync/atomic and plainly (use a typed atomic)",
+        "high",
+        None,
+        options=(
+            str_list_option(
+                "exclude",
+                (),
+                choices=ATOMIC_EXCLUDES,
+                description="Plain accesses not reported: in init functions, in _test.go files",
+            ),
+        ),
+        categories=("concurrency", "correctness"),
+    ),
+    _smell(
+        "blocking_under_lock",
+        "Blocking call while holding a mutex (every caller waits on its latency)",
+        "medium",
+        None,
+        options=(
+            str_list_option(
+                "blocking",
+                BLOCKING_KINDS,
+                choices=BLOCKING_KINDS,
+                description="Kinds of call reported under Lock",
+            ),
+            str_list_option(
+                "read_blocking",
+                ("channel", "sleep"),
+                choices=BLOCKING_KINDS,
+                description="Kinds of call reported under RLock",
+            ),
+            str_list_option(
+                "calls",
+                (),
+                description="More blocking calls as kind:regex, e.g. network:\\bc\\.Post\\(",
+            ),
+        ),
+        categories=("concurrency", "performance"),
+    ),
+    _smell(
+        "send_after_close",
+        "Send on a channel this function may already have closed (panics)",
+        "high",
+        None,
+        categories=("concurrency", "correctness"),
+    ),
+    _smell(
+        "loop_ignores_context",
+        "Loop never checks its function's context (select on ctx.Done())",
+        "medium",
+        None,
+        categories=("concurrency",),
+    ),
+    _smell(
+        "busy_select_default",
+        "select default inside an endless loop never waits (busy loop burning CPU)",
+        "medium",
+        None,
+        categories=("concurrency", "performance"),
+    ),
+    _smell(
+        "channel_as_mutex",
+        "Channel used as a mutex (use sync.Mutex)",
+        "low",
+        None,
+        categories=("concurrency", "style"),
+    ),
+    _smell(
+        "magic_channel_buffer",
+        "Channel buffer size without explanation (name it or comment on it)",
+        "low",
+        None,
+        categories=("concurrency", "style"),
+    ),
+    _smell(
+        "goroutine_send_leak",
+        "Goroutine can block forever sending to a channel its parent stops reading",
+        "high",
+        None,
+        categories=("concurrency", "correctness"),
+    ),
+    _smell(
+        "waitgroup_add_in_goroutine",
+        "WaitGroup.Add inside the goroutine races with Wait (call Add before go)",
+        "high",
+        None,
+        categories=("concurrency", "correctness"),
     ),
     _smell(
         "time_tick_leak",
         "time.Tick leaks ticker (use time.NewTicker with Stop)",
         "high",
         r"\btime\.Tick\s*\(",
+        categories=("concurrency", "performance"),
     ),
     _smell(
         "unbuffered_signal",
         "Unbuffered signal channel (may miss signals)",
         "high",
         None,
+        categories=("concurrency", "correctness"),
     ),
     _smell(
         "single_case_select",
         "Single-case select (unnecessary overhead)",
         "low",
         None,
+        categories=("concurrency", "style"),
     ),
     _smell(
         "nil_map_write",
         "Potential write to nil map (runtime panic)",
         "high",
         None,
+        categories=("correctness",),
     ),
     _smell(
         "string_concat_loop",
         "String concatenation in loop (O(n²) allocations)",
         "medium",
         None,
+        categories=("performance",),
     ),
     _smell(
         "yoda_condition",
         "Yoda condition (constant on left side of ==)",
         "low",
         None,
+        fixable=True,
+        categories=("style",),
     ),
     _smell(
         "todo_fixme",
         "TODO/FIXME/HACK comments",
         "low",
         r"//\s*(?:TODO|FIXME|HACK|XXX)",
+        categories=("style",),
     ),
     _smell(
         "dogsledding",
         "Excessive blank identifiers (3+ underscores on LHS)",
         "low",
         r"_\s*,\s*_\s*,\s*_",
+        categories=("style",),
     ),
     _smell(
         "too_many_params",
         "Too many function parameters (>5)",
         "medium",
         None,
+        options=(
+            int_option(
+                "max", 5, minimum=1, maximum=32, description="Most parameters allowed"
+            ),
+        ),
+        categories=("style",),
+    ),
+    _smell(
+        "name_length",
+        "Short name used far from its declaration, or overlong name",
+        "low",
+        None,
+        options=(
+            int_option(
+                "max_scope_lines",
+                NAME_MAX_SCOPE_LINES,
+                minimum=1,
+                maximum=1000,
+                description="Most lines a one- or two-character name may span",
+            ),
+            int_option(
+                "max_length",
+                NAME_MAX_LENGTH,
+                minimum=8,
+                maximum=200,
+                description="Longest name allowed",
+            ),
+            str_list_option(
+                "allow",
+                NAME_ALLOWED,
+                description="Short names never reported, whatever their scope",
+            ),
+        ),
+        categories=("style",),
+    ),
+    _smell(
+        "builtin_shadow",
+        "Declaration shadows a builtin identifier",
+        "low",
+        None,
+        categories=("style",),
+    ),
+    _smell(
+        "getter_prefix",
+        "Getter named GetX (Go getters drop the Get prefix)",
+        "low",
+        None,
+        categories=("style",),
+    ),
+    _smell(
+        "blank_import",
+        "Blank import outside package main (hidden init side effects)",
+        "low",
+        None,
+        options=(
+            str_list_option(
+                "allow",
+                (),
+                description="Import paths whose blank import is fine anywhere, as path or path/...",
+            ),
+        ),
+        categories=("style",),
+    ),
+    _smell(
+        "dot_import",
+        "Dot import (names read as if declared locally)",
+        "low",
+        None,
+        options=(
+            str_list_option(
+                "allow",
+                DOT_IMPORT_ALLOWED,
+                description="Import paths that may be dot-imported, as path or path/...",
+            ),
+        ),
+        categories=("style",),
+    ),
+    _smell(
+        "shared_param_group",
+        "Three or more functions share a parameter group (use an options struct)",
+        "low",
+        None,
+        requires="module",
+        categories=("style",),
+    ),
+    _smell(
+        "package_name_style",
+        "Package name with underscores or mixed caps (or, optionally, plural)",
+        "low",
+        None,
+        requires="module",
+        options=(
+            str_list_option(
+                "reasons",
+                DEFAULT_NAME_STYLE_REASONS,
+                choices=NAME_STYLE_REASONS,
+                description="Name forms reported",
+            ),
+            str_list_option(
+                "allow",
+                PLURAL_PACKAGES,
+                description="Plural package names that are not reported",
+            ),
+        ),
+        categories=("style",),
+    ),
+    _smell(
+        "package_dir_mismatch",
+        "Package name does not match its directory",
+        "low",
+        None,
+        requires="module",
+        categories=("style",),
+    ),
+    _smell(
+        "package_too_many_files",
+        "Package spread across too many files (>30)",
+        "low",
+        None,
+        requires="module",
+        options=(
+            int_option(
+                "max_files",
+                MAX_PACKAGE_FILES,
+                minimum=2,
+                maximum=1000,
+                description="Most non-test files allowed in one package",
+            ),
+        ),
+        categories=("style",),
+    ),
+    _smell(
+        "file_too_long",
+        "File too long (>1500 lines)",
+        "low",
+        None,
+        options=(
+            int_option(
+                "max_lines",
+                MAX_FILE_LINES,
+                minimum=100,
+                maximum=100000,
+                description="Most lines allowed in one file",
+            ),
+        ),
+        categories=("style",),
+    ),
+    _smell(
+        "package_test_heavy",
+        "Same-package tests larger than all the package's code",
+        "low",
+        None,
+        requires="module",
+        categories=("style",),
+    ),
+    _smell(
+        "long_function",
+        "Function too long (see the suggested extraction points)",
+        "medium",
+        None,
+        options=(
+            int_option(
+                "max_statements",
+                80,
+                minimum=10,
+                maximum=1000,
+                description="Most statements a function may have",
+            ),
+            str_list_option(
+                "exclude",
+                (COMPOSITE_LITERALS, CASE_CLAUSES),
+                choices=(COMPOSITE_LITERALS, CASE_CLAUSES),
+                description="Lines not counted as statements",
+            ),
+        ),
+        categories=("style",),
+    ),
+    _smell(
+        "printf_mismatch",
+        "Printf-style format that does not match its arguments",
+        "high",
+        None,
+        options=(
+            str_list_option(
+                "functions",
+                (),
+                description="More Printf-like functions, as name or pkg.Name, "
+                "with :index when the format is not the first argument",
+            ),
+        ),
+        categories=("correctness",),
+    ),
+    _smell(
+        "json_tag_unexported",
+        "json tag on an unexported field (encoding/json ignores the field)",
+        "high",
+        None,
+        categories=("correctness",),
+    ),
+    _smell(
+        "json_unmarshal_non_pointer",
+        "json Unmarshal or Decode into a non-pointer (fails at run time)",
+        "high",
+        None,
+        categories=("correctness",),
+    ),
+    _smell(
+        "json_tag_invalid",
+        "json tag with an unknown option or malformed key",
+        "medium",
+        None,
+        categories=("correctness",),
+    ),
+    _smell(
+        "json_tag_duplicate",
+        "Struct fields with the same JSON name (encoding/json drops both)",
+        "medium",
+        None,
+        categories=("correctness",),
+    ),
+    _smell(
+        "json_tag_missing",
+        "Exported field of a JSON-encoded struct without a json tag",
+        "low",
+        None,
+        opt_in=True,
+        categories=("style",),
+    ),
+    _smell(
+        "slice_overlap_append",
+        "append through a reslice overwrites the slice it came from (assign back to it)",
+        "medium",
+        None,
+        categories=("correctness",),
+    ),
+    _smell(
+        "copy_length_ignored",
+        "copy into a fixed-length slice with its count ignored (may copy only part)",
+        "low",
+        None,
+        categories=("correctness",),
+    ),
+    _smell(
+        "unkeyed_struct_literal",
+        "Struct literal with positional fields (breaks when the struct changes; name them)",
+        "medium",
+        None,
+        fixable=True,
+        options=(
+            int_option(
+                "max_fields",
+                UNKEYED_MAX_FIELDS,
+                minimum=0,
+                maximum=1000,
+                description="Most fields a struct of the package may have and still be "
+                "written positionally",
+            ),
+            str_list_option(
+                "exclude",
+                (),
+                choices=UNKEYED_EXCLUDES,
+                description="Literals not reported: small_elements, elided elements of "
+                "slices and maps whose fields are all numbers, strings or bools",
+            ),
+        ),
+        categories=("correctness",),
+    ),
+    _smell(
+        "bare_duration",
+        "Duration given as a bare integer, which is nanoseconds (multiply by a time unit)",
+        "high",
+        None,
+        categories=("correctness",),
+    ),
+    _smell(
+        "duration_double_unit",
+        "time.Duration multiplied by a time unit again (timeout * time.Second)",
+        "high",
+        None,
+        categories=("correctness",),
+    ),
+    _smell(
+        "time_layout",
+        "Time layout in another notation (YYYY-MM-DD); Go layouts use 2006-01-02",
+        "high",
+        None,
+        categories=("correctness",),
+    ),
+    _smell(
+        "time_equal",
+        "time.Time compared with == (compares location and monotonic clock; use Equal)",
+        "medium",
+        None,
+        categories=("correctness",),
+    ),
+    _smell(
+        "time_not_utc",
+        "time.Now() formatted, stored or logged in the server's local time zone",
+        "low",
+        None,
+        opt_in=True,
+        categories=("correctness",),
+    ),
+    _smell(
+        "sprintf_strconv",
+        "fmt.Sprintf for a single conversion (use strconv)",
+        "low",
+        None,
+        fixable=True,
+        categories=("performance",),
+    ),
+    _smell(
+        "sprintf_path",
+        "fmt.Sprintf joining path segments (use filepath.Join)",
+        "low",
+        None,
+        categories=("style",),
+    ),
+    _smell(
+        "sprintf_url",
+        "Query value formatted into a URL unescaped (use url.Values)",
+        "medium",
+        None,
+        categories=("correctness", "security"),
+    ),
+    _smell(
+        "sprintf_url_param",
+        "Parameter formatted into a URL query unescaped (use url.Values)",
+        "high",
+        None,
+        categories=("correctness", "security"),
+    ),
+    _smell(
+        "sprintf_duration",
+        "Duration built with fmt.Sprintf and parsed back (use time.Duration arithmetic)",
+        "low",
+        None,
+        categories=("style",),
+    ),
+    _smell(
+        "append_no_prealloc",
+        "append in range loop without preallocation (use make with capacity)",
+        "low",
+        None,
+        options=(
+            int_option(
+                "lookback_lines",
+                3,
+                minimum=1,
+                maximum=20,
+                description="Non-blank lines above the loop searched for the empty slice",
+            ),
+        ),
+        categories=("performance",),
+    ),
+    _smell(
+        "double_map_lookup",
+        "Map membership check followed by a second lookup (use v, ok := m[k])",
+        "low",
+        None,
+        categories=("performance",),
+    ),
+    _smell(
+        "len_comparison",
+        "len() comparison that is always true or always false",
+        "low",
+        None,
+        categories=("correctness",),
+    ),
+    _smell(
+        "constant_condition",
+        "if/for condition that is always true or always false",
+        "medium",
+        None,
+        categories=("correctness",),
+    ),
+    _smell(
+        "unreachable_code",
+        "Unreachable code after return/panic/os.Exit/log.Fatal",
+        "medium",
+        None,
+        categories=("correctness",),
+    ),
+    _smell(
+        "duplicate_branch",
+        "Duplicate switch case or repeated if/else-if condition (dead branch)",
+        "medium",
+        None,
+        categories=("correctness",),
+    ),
+    _smell(
+        "ineffective_break",
+        "Unlabeled break in a switch/select inside a loop (leaves the switch, not the loop)",
+        "low",
+        None,
+        categories=("correctness",),
+    ),
+    _smell(
+        "redundant_continue",
+        "continue as the last statement of a loop body (does nothing)",
+        "low",
+        None,
+        categories=("style",),
+    ),
+    _smell(
+        "loop_runs_once",
+        "Loop whose body always leaves on its first pass (never repeats)",
+        "medium",
+        None,
+        categories=("correctness",),
+    ),
+    _smell(
+        "loop_condition_unchanged",
+        "Loop condition over variables the loop never changes (runs zero times or forever)",
+        "high",
+        None,
+        categories=("correctness",),
+    ),
+    _smell(
+        "empty_branch",
+        "Empty if/else/for/switch body (incomplete code?)",
+        "low",
+        None,
+        categories=("correctness",),
+    ),
+    _smell(
+        "useless_error_return",
+        "Function returns error but only ever returns nil",
+        "low",
+        None,
+        options=(
+            enum_option(
+                "functions",
+                "all",
+                ("all", "unexported"),
+                description="unexported: leave exported API signatures alone",
+            ),
+        ),
+        categories=("style",),
+    ),
+    _smell(
+        "always_nil_error",
+        "Error result is nil on every path (callers check an error that never comes)",
+        "low",
+        None,
+        opt_in=True,
+        requires="types",
+        categories=("style",),
+    ),
+    _smell(
+        "error_not_last",
+        "Error result declared before another result (Go puts error last)",
+        "low",
+        None,
+        categories=("style",),
+    ),
+    _smell(
+        "error_only_logged",
+        "Error checked, logged and then ignored (the code carries on as if it worked)",
+        "medium",
+        None,
+        options=(
+            str_list_option(
+                "best_effort",
+                BEST_EFFORT_PREFIXES,
+                description="Name prefixes of best-effort functions, e.g. try for tryClose",
+            ),
+        ),
+        categories=("correctness",),
+    ),
+    _smell(
+        "message_function_name",
+        "Error or log message prefixed with its function's name by hand (stale on rename)",
+        "low",
+        None,
+        categories=("style",),
+    ),
+    _smell(
+        "message_stale_name",
+        "Error or log message prefixed with a name nothing in the package has",
+        "medium",
+        None,
+        requires="module",
+        categories=("correctness",),
+    ),
+    _smell(
+        "value_with_error",
+        "Returns a non-zero value together with a non-nil error",
+        "low",
+        None,
+        categories=("style",),
+    ),
+    _smell(
+        "panic_string",
+        "panic() with a string instead of an error value",
+        "low",
+        None,
+        categories=("style",),
+    ),
+    _smell(
+        "error_not_wrapped",
+        "fmt.Errorf formats an error with %v/%s instead of wrapping it with %w",
+        "low",
+        None,
+        fixable=True,
+        options=(
+            str_list_option(
+                "verbs",
+                ("v", "s"),
+                choices=("v", "s"),
+                description="Verbs that count as formatting the error instead of wrapping",
+            ),
+        ),
+        categories=("correctness",),
+    ),
+    _smell(
+        "multiple_wrap_verbs",
+        "fmt.Errorf with more than one %w in a module before Go 1.20",
+        "medium",
+        None,
+        categories=("correctness",),
+    ),
+    _smell(
+        "deferred_error_ignored",
+        "Deferred call discards an error that matters (check it in a deferred closure)",
+        "medium",
+        None,
+        options=(
+            str_list_option(
+                "methods",
+                ("Commit", "Flush", "Sync"),
+                description="Methods whose error a defer must not discard",
+            ),
+            str_list_option(
+                "writer_methods",
+                ("Close",),
+                description="Methods reported only on receivers opened for writing",
+            ),
+        ),
+        categories=("correctness",),
+    ),
+    _smell(
+        "defer_on_maybe_nil",
+        "defer on a value before its error is checked (panics when the call failed)",
+        "high",
+        None,
+        categories=("correctness",),
+    ),
+    _smell(
+        "errors_as_target",
+        "errors.As target is not a pointer (panics at run time)",
+        "high",
+        None,
+        categories=("correctness",),
+    ),
+    _smell(
+        "error_overwritten",
+        "Error assigned again before it was checked (the first failure is lost)",
+        "high",
+        None,
+        categories=("correctness",),
+    ),
+    _smell(
+        "ineffective_assignment",
+        "Value assigned and never read before it is overwritten or out of scope",
+        "medium",
+        None,
+        categories=("correctness",),
+    ),
+    _smell(
+        "pure_result_discarded",
+        "Result of a function with no side effects thrown away (the call does nothing)",
+        "medium",
+        None,
+        options=(
+            str_list_option(
+                "functions",
+                (),
+                description="More functions whose result must be used, as pkg.Name or pkg.*",
+            ),
+        ),
+        categories=("correctness",),
+    ),
+    _smell(
+        "variable_reuse",
+        "Local variable reused for unrelated values (one name, several meanings)",
+        "low",
+        None,
+        opt_in=True,
+        options=(
+            int_option(
+                "max_reassignments",
+                3,
+                minimum=1,
+                maximum=100,
+                description="Most fresh values a variable may be given after its first",
+            ),
+        ),
+        categories=("style",),
+    ),
+    _smell(
+        "else_after_return",
+        "else after an if block that ends in return (outdent the else body)",
+        "low",
+        None,
+        fixable=True,
+        categories=("style",),
+    ),
+    _smell(
+        "bool_literal_return",
+        "if/else returning true/false literals (return the condition)",
+        "low",
+        None,
+        fixable=True,
+        categories=("style",),
+    ),
+    _smell(
+        "redundant_nil_check",
+        "Nil check that len() or range already makes (drop it)",
+        "low",
+        None,
+        fixable=True,
+        categories=("style",),
+    ),
+    _smell(
+        "redundant_error_check",
+        "if err != nil { return err } followed by return nil (return err)",
+        "low",
+        None,
+        fixable=True,
+        categories=("style",),
+    ),
+    _smell(
+        "pointer_to_small_type",
+        "Pointer to a small value type (pass or store the value)",
+        "low",
+        None,
+        options=(
+            int_option(
+                "max_bytes",
+                SMALL_TYPE_MAX_BYTES,
+                minimum=1,
+                maximum=1 << 10,
+                description="Largest pointed-to size (bytes) worth copying instead",
+            ),
+        ),
+        categories=("performance", "style"),
+    ),
+    _smell(
+        "unused_field",
+        "Unexported struct field nothing in the package reads or sets",
+        "low",
+        None,
+        requires="module",
+        categories=("style",),
+    ),
+    _smell(
+        "struct_field_alignment",
+        "Struct field order wastes padding (reorder by alignment)",
+        "low",
+        None,
+        opt_in=True,
+        requires="types",
+        options=(
+            int_option(
+                "min_bytes",
+                STRUCT_ALIGNMENT_MIN_BYTES,
+                minimum=1,
+                maximum=1 << 20,
+                description="Smallest struct size (bytes) worth reordering",
+            ),
+        ),
+        categories=("performance",),
     ),
 ]
 
 
-def detect_smells(path: Path) -> tuple[list[dict], int]:
-    """Detect Go code smell patterns. Returns (entries, total_files_checked)."""
-    smell_counts: dict[str, list[dict]] = {s["id"]: [] for s in SMELL_CHECKS}
-    files = find_go_files(path)
+def detect_smells(
+    path: Path,
+    *,
+    opt_in: set[str] | frozenset[str] = frozenset(),
+    jobs: int = 1,
+    cache: ResultCache | None = None,
+    syntax_only: bool = False,
+    diagnostics: RunDiagnostics | None = None,
+    unparsable: frozenset[str] = frozenset(),
+    untyped: frozenset[str] = frozenset(),
+    custom_rules: tuple[CustomRule, ...] | list[CustomRule] = (),
+    rule_options: dict[str, dict] | None = None,
+    files: list[str] | tuple[str, ...] | None = None,
+    progress: ScanProgress | None = None,
+    reported: Callable[[str, int | None], bool] | None = None,
+    sample: int | None = MATCH_SAMPLE,
+) -> tuple[list[dict], int]:
+    """Detect Go code smell patterns. Returns (entries, total_files_checked).
 
+    Checks marked ``opt_in`` only run when their id is listed in ``opt_in``,
+    and ``enabled`` in ``rule_options`` switches any rule on or off;
+    ``syntax_only`` drops every check that needs more than the file's syntax.
+    Packages are analyzed independently on up to ``jobs`` workers; matches
+    are sorted by file then line so the result does not depend on ``jobs``.
+    With a ``cache``, a package whose key is unchanged is not re-analyzed.
+    ``diagnostics`` collects per-rule and per-package time for analyzed
+    (not cached) packages, and a trace span per package.
+    Files in ``unparsable`` (they have syntax errors) only get the line-pattern
+    checks, and packages whose directory is in ``untyped`` (they do not load)
+    only get syntax-level checks. ``custom_rules`` run in the same pass as
+    the smells, on the same parsed files. ``rule_options`` is the configured
+    ``{rule id: {option: value}}``; invalid values fall back to defaults.
+    ``files`` narrows the scan to those Go files (one module of a monorepo).
+    ``progress`` is advanced by each package's files as it is folded in.
+    With ``reported`` (changed-files scans), only packages with a file it
+    accepts are analyzed, and only matches it accepts at their line are
+    kept. Each entry keeps its first ``sample`` matches, or all of them when
+    ``sample`` is ``None``.
+    """
+    custom_rules = tuple(custom_rules)
+    checks = _enabled_checks(
+        opt_in, syntax_only=syntax_only, custom_rules=custom_rules, rule_options=rule_options
+    )
+    # Worker processes import the plugins that provide enabled rules.
+    plugins = tuple(sorted({s["plugin"] for s in checks if s.get("plugin")}))
+    files = find_go_files(path) if files is None else list(files)
+    packages: dict[str, list[str]] = {}
     for filepath in files:
-        if filepath.endswith("_test.go"):
-            continue
-        try:
-            content = Path(filepath).read_text(errors="replace")
-            lines = content.splitlines()
-        except (OSError, UnicodeDecodeError):
-            continue
+        packages.setdefault(os.path.dirname(filepath), []).append(filepath)
+    if reported is not None:
+        packages = {
+            d: fs for d, fs in packages.items() if any(reported(f, None) for f in fs)
+        }
+        files = [f for fs in packages.values() for f in fs]
 
-        is_main_pkg = _is_main_package(lines)
+    # Package results are folded in as they arrive and then dropped; only
+    # counters and each smell's first matches outlive their package.
+    tallies = {s["id"]: _SmellTally(sample) for s in checks}
 
-        for check in SMELL_CHECKS:
-            if check["pattern"] is None:
-                continue
-            # Skip panic_in_lib for main packages
-            if check["id"] == "panic_in_lib" and is_main_pkg:
-                continue
-            pat = re.compile(check["pattern"])
-            for i, line in enumerate(lines):
-                # Don't skip comment lines for TODO detection
-                if _is_comment_line(line) and check["id"] != "todo_fixme":
-                    continue
-                if pat.search(line):
-                    smell_counts[check["id"]].append(
-                        {
-                            "file": filepath,
-                            "line": i + 1,
-                            "content": line.strip()[:100],
-                        }
-                    )
-
-        # Multi-line detectors
-        _detect_unbuffered_signal(filepath, lines, smell_counts)
-        _detect_single_case_select(filepath, content, smell_counts)
-        _detect_nil_map_write(filepath, lines, smell_counts)
-        _detect_string_concat_loop(filepath, lines, smell_counts)
-        _detect_yoda_condition(filepath, lines, smell_counts)
-        _detect_too_many_params(filepath, content, smell_counts)
+    def fold(package_counts: dict[str, list[dict]]) -> None:
+        for smell_id, matches in package_counts.items():
+            if smell_id in tallies:
+                if reported is not None:
+                    matches = [m for m in matches if reported(m["file"], m["line"])]
+                tallies[smell_id].add(matches)
+
+    keys: dict[str, str] = {}
+    pending: list[str] = []
+    if progress is not None:
+        progress.start(len(files))
+    if cache is not None:
+        keyer = GoPackageKeyer()
+        rules = [f"rules:{','.join(sorted(s['id'] for s in checks))}"]
+        rules += fingerprint([r for r in registered_rules() if r.id in tallies])
+        rules += [f"custom:{rule!r}" for rule in custom_rules]
+        options = resolve_options(rule_options, checks)
+        rules += [f"options:{json.dumps(options, sort_keys=True)}"] if options else []
+    for directory in sorted(packages):
+        if cache is None:
+            pending.append(directory)
+            continue
+        degraded = [f"unparsable:{f}" for f in packages[directory] if f in unparsable]
+        if directory in untyped:
+            degraded.append("untyped")
+        key = cache.key(
+            _CACHE_NAMESPACE,
+            keyer.parts(directory, packages[directory]) + rules + degraded,
+        )
+        hit = cache.get(_CACHE_NAMESPACE, key)
+        if hit is None:
+            keys[directory] = key
+            pending.append(directory)
+        else:
+            fold(hit)
+            if progress is not None:
+                progress.advance(len(packages[directory]), directory)
+
+    timed = diagnostics is not None and (
+        diagnostics.timings is not None or diagnostics.trace is not None
+    )
+    groups = (
+        (syntax_only, [d for d in pending if d not in untyped]),
+        (True, [d for d in pending if d in untyped]),
+    )
+    for group_syntax_only, directories in groups:
+        if not directories:
+            continue
+        scan = functools.partial(
+            _scan_package_timed if timed else _scan_package,
+            opt_in=frozenset(opt_in),
+            syntax_only=group_syntax_only,
+            unparsable=unparsable,
+            plugins=plugins,
+            custom_rules=custom_rules,
+            rule_options=rule_options,
+        )
+        for directory, result in zip(
+            directories,
+            map_packages(scan, [packages[d] for d in directories], jobs=jobs),
+        ):
+            raise_if_cancelled()
+            if timed:
+                package_counts, rule_seconds, start, seconds = result
+                _record_package(diagnostics, directory, rule_seconds, start, seconds)
+            else:
+                package_counts = result
+            fold(package_counts)
+            if cache is not None:
+                cache.put(_CACHE_NAMESPACE, keys[directory], package_counts)
+            if progress is not None:
+                progress.advance(len(packages[directory]), directory)
+    if progress is not None:
+        progress.clear()
 
     severity_order = {"high": 0, "medium": 1, "low": 2}
     entries = []
-    for check in SMELL_CHECKS:
-        matches = smell_counts[check["id"]]
-        if matches:
+    for check in checks:
+        tally = tallies[check["id"]]
+        if tally.count:
             entries.append(
                 {
                     "id": check["id"],
                     "label": check["label"],
                     "severity": check["severity"],
-                    "count": len(matches),
-                    "files": len(set(m["file"] for m in matches)),
-                    "matches": matches[:50],
+                    "count": tally.count,
+                    "files": len(tally.files),
+                    "matches": tally.first_matches(),
                 }
             )
     entries.sort(key=lambda e: (severity_order.get(e["severity"], 9), -e["count"]))
     return entries, len(files)
 
 
+def _record_package(
+    diagnostics: RunDiagnostics,
+    directory: str,
+    rule_seconds: dict[str, float],
+    start: float,
+    seconds: float,
+) -> None:
+    package = directory or "."
+    if diagnostics.timings is not None:
+        diagnostics.timings.add_rules(rule_seconds)
+        diagnostics.timings.add_package(package, seconds)
+    if diagnostics.trace is not None:
+        diagnostics.trace.complete(package, "package", start, seconds)
+
+
+class _SmellTally:
+    """Streaming count, file set, and first ``limit`` matches of one smell."""
+
+    def __init__(self, limit: int | None = MATCH_SAMPLE) -> None:
+        self.limit = limit
+        self.count = 0
+        self.files: set[str] = set()
+        # Max-heap of the lowest (file, line, arrival) ranks seen so far.
+        self._sample: list[tuple[_Reversed, dict]] = []
+
+    def add(self, matches: list[dict]) -> None:
+        for match in matches:
+            self.count += 1
+            self.files.add(match["file"])
+            rank = (match["file"], match["line"], self.count)
+            if self.limit is None or len(self._sample) < self.limit:
+                heapq.heappush(self._sample, (_Reversed(rank), match))
+            elif rank < self._sample[0][0].rank:
+                heapq.heapreplace(self._sample, (_Reversed(rank), match))
+
+    def first_matches(self) -> list[dict]:
+        return [match for _key, match in sorted(self._sample, key=lambda e: e[0].rank)]
+
+
+class _Reversed:
+    """Inverts ordering so ``heapq``'s min-heap keeps the largest rank on top."""
+
+    __slots__ = ("rank",)
+
+    def __init__(self, rank: tuple[str, int, int]) -> None:
+        self.rank = rank
+
+    def __lt__(self, other: _Reversed) -> bool:
+        return self.rank > other.rank
+
+    def __eq__(self, other: object) -> bool:
+        return isinstance(other, _Reversed) and self.rank == other.rank
+
+
+def _all_checks(custom_rules: tuple[CustomRule, ...] = ()) -> list[dict]:
+    """Built-in smells, then rules registered through ``go.rules``, then ``custom_rules``."""
+    return (
+        SMELL_CHECKS
+        + [
+            {
+                **_smell(
+                    r.id,
+                    r.label,
+                    r.severity,
+                    opt_in=r.opt_in,
+                    requires=r.requires,
+                    options=r.options,
+                    categories=r.categories,
+                ),
+                "plugin": r.source,
+            }
+            for r in registered_rules()
+        ]
+        + [
+            {**_smell(r.id, r.message, r.severity, categories=r.categories), "custom": True}
+            for r in custom_rules
+        ]
+    )
+
+
+def _enabled_checks(
+    opt_in: set[str] | frozenset[str],
+    *,
+    syntax_only: bool = False,
+    custom_rules: tuple[CustomRule, ...] = (),
+    rule_options: dict[str, dict] | None = None,
+) -> list[dict]:
+    def enabled(check: dict) -> bool:
+        switch = rule_switch(rule_options, check["id"])
+        if switch is not None:
+            return switch
+        return not check["opt_in"] or check["id"] in opt_in
+
+    return [
+        s
+        for s in _all_checks(custom_rules)
+        if enabled(s) and not (syntax_only and s["requires"] != "syntax")
+    ]
+
+
+def select_categories(
+    rule_options: dict[str, dict] | None,
+    categories: list[str] | tuple[str, ...] = (),
+    exclude: list[str] | tuple[str, ...] = (),
+    custom_rules: tuple[CustomRule, ...] = (),
+) -> dict[str, dict] | None:
+    """``rule_options`` with every rule outside ``categories``, or in ``exclude``, off.
+
+    No ``categories`` keeps every category. Rules are switched off through
+    their ``enabled`` option, so the selection reaches worker processes and
+    cache keys like any other option, and wins over ``enabled: true``; it
+    never turns on an opt-in rule.
+    """
+    if not categories and not exclude:
+        return rule_options
+    selected = dict(rule_options or {})
+    for check in _all_checks(custom_rules):
+        tags = set(check["categories"])
+        if (categories and not tags & set(categories)) or tags & set(exclude):
+            values = selected.get(check["id"])
+            selected[check["id"]] = {**(values if isinstance(values, dict) else {}), ENABLED: False}
+    return selected
+
+
+def smell_rule_catalog() -> list[dict]:
+    """Every Go smell with its requirement level, for ``langs --rules``."""
+    return [
+        {
+            key: s[key]
+            for key in (
+                "id",
+                "label",
+                "severity",
+                "categories",
+                "requires",
+                "opt_in",
+                "fixable",
+                "plugin",
+                "explain",
+            )
+            if key in s and (key != "explain" or s[key])
+        }
+        for s in _all_checks()
+    ]
+
+
+def _build_inspector(
+    enabled: set[str],
+    custom_rules: tuple[CustomRule, ...] = (),
+    rule_options: dict[str, dict] | None = None,
+) -> Inspector:
+    """Register the multi-line detectors of ``enabled`` rules; each file is then walked once.
+
+    Each rule's ``Pass`` carries its options from ``rule_options``, defaults
+    filled in.
+    """
+    inspector = Inspector(resolve_options(rule_options, _all_checks(custom_rules)), enabled)
+    inspector.add_file(_detect_unbuffered_signal, "unbuffered_signal")
+    inspector.add_file(_detect_single_case_select, "single_case_select")
+    inspector.add_file(_detect_nil_map_write, "nil_map_write")
+    inspector.add_file(_detect_string_concat_loop, "string_concat_loop")
+    inspector.add_file(_detect_yoda_condition, "yoda_condition")
+    inspector.add_file(_detect_too_many_params, "too_many_params")
+    inspector.add_file(detect_name_length, "name_length")
+    inspector.add_file(detect_builtin_shadow, "builtin_shadow")
+    inspector.add_file(detect_getter_prefix, "getter_prefix")
+    inspector.add_file(detect_blank_import, "blank_import")
+    inspector.add_file(detect_dot_import, "dot_import")
+    inspector.add_file(detect_shared_param_group, "shared_param_group")
+    inspector.add_file(detect_package_name_style, "package_name_style")
+    inspector.add_file(detect_package_dir_mismatch, "package_dir_mismatch")
+    inspector.add_file(detect_package_too_many_files, "package_too_many_files")
+    inspector.add_file(detect_file_too_long, "file_too_long")
+    inspector.add_file(detect_package_test_heavy, "package_test_heavy")
+    inspector.add_file(detect_printf_mismatch, "printf_mismatch")
+    inspector.add_file(detect_unkeyed_struct_literal, "unkeyed_struct_literal")
+    inspector.add_file(detect_bare_duration, "bare_duration")
+    inspector.add_file(detect_json_tag_unexported, "json_tag_unexported")
+    inspector.add_file(detect_json_unmarshal_non_pointer, "json_unmarshal_non_pointer")
+    inspector.add_file(detect_json_tag_invalid, "json_tag_invalid")
+    inspector.add_file(detect_json_tag_duplicate, "json_tag_duplicate")
+    inspector.add_file(detect_json_tag_missing, "json_tag_missing")
+    inspector.add_file(detect_slice_overlap_append, "slice_overlap_append")
+    inspector.add_file(detect_copy_length_ignored, "copy_length_ignored")
+    inspector.add_file(detect_duration_double_unit, "duration_double_unit")
+    inspector.add_file(detect_time_layout, "time_layout")
+    inspector.add_file(detect_time_equal, "time_equal")
+    inspector.add_file(detect_time_not_utc, "time_not_utc")
+    inspector.add_file(detect_sprintf_strconv, "sprintf_strconv")
+    inspector.add_file(detect_sprintf_path, "sprintf_path")
+    inspector.add_file(detect_sprintf_url, "sprintf_url")
+    inspector.add_file(detect_sprintf_url_param, "sprintf_url_param")
+    inspector.add_file(detect_sprintf_duration, "sprintf_duration")
+    inspector.add_file(detect_append_no_prealloc, "append_no_prealloc")
+    inspector.add_file(detect_double_map_lookup, "double_map_lookup")
+    inspector.add_file(detect_len_comparison, "len_comparison")
+    inspector.add_visitor(visit_constant_condition, "constant_condition")
+    inspector.add_file(detect_unreachable_code, "unreachable_code")
+    inspector.add_file(detect_duplicate_branch, "duplicate_branch")
+    inspector.add_file(detect_ineffective_break, "ineffective_break")
+    inspector.add_file(detect_redundant_continue, "redundant_continue")
+    inspector.add_visitor(visit_loop_runs_once, "loop_runs_once")
+    inspector.add_visitor(visit_loop_condition_unchanged, "loop_condition_unchanged")
+    inspector.add_visitor(visit_empty_branch, "empty_branch")
+    inspector.add_visitor(visit_useless_error_return, "useless_error_return")
+    inspector.add_file(detect_always_nil_error, "always_nil_error")
+    inspector.add_visitor(visit_value_with_error, "value_with_error")
+    inspector.add_file(detect_error_not_last, "error_not_last")
+    inspector.add_file(detect_error_only_logged, "error_only_logged")
+    inspector.add_file(detect_message_function_name, "message_function_name")
+    inspector.add_file(detect_message_stale_name, "message_stale_name")
+    inspector.add_file(detect_panic_string, "panic_string")
+    inspector.add_file(detect_panic_in_lib, "panic_in_lib")
+    inspector.add_file(detect_panic_in_lib_helper, "panic_in_lib_helper")
+    inspector.add_file(detect_must_call_in_function, "must_call_in_function")
+    inspector.add_file(detect_error_not_wrapped, "error_not_wrapped")
+    inspector.add_file(detect_multiple_wrap_verbs, "multiple_wrap_verbs")
+    inspector.add_file(detect_deferred_error_ignored, "deferred_error_ignored")
+    inspector.add_file(detect_errors_as_target, "errors_as_target")
+    inspector.add_file(detect_defer_on_maybe_nil, "defer_on_maybe_nil")
+    inspector.add_file(detect_error_overwritten, "error_overwritten")
+    inspector.add_file(detect_ineffective_assignment, "ineffective_assignment")
+    inspector.add_file(detect_pure_result_discarded, "pure_result_discarded")
+    inspector.add_file(detect_variable_reuse, "variable_reuse")
+    inspector.add_file(detect_goroutine_index_capture, "goroutine_index_capture")
+    inspector.add_file(detect_waitgroup_add_in_goroutine, "waitgroup_add_in_goroutine")
+    inspector.add_file(detect_mutex_unlock_missing, "mutex_unlock_missing")
+    inspector.add_file(detect_resource_not_released, "resource_not_released")
+    inspector.add_file(detect_sql_rows_misuse, "sql_rows_misuse")
+    inspector.add_file(detect_blocking_under_lock, "blocking_under_lock")
+    inspector.add_file(detect_racy_lazy_init, "racy_lazy_init")
+    inspector.add_file(detect_atomic_mixed_access, "atomic_mixed_access")
+    inspector.add_file(detect_send_after_close, "send_after_close")
+    inspector.add_file(detect_loop_ignores_context, "loop_ignores_context")
+    inspector.add_file(detect_busy_select_default, "busy_select_default")
+    inspector.add_file(detect_channel_as_mutex, "channel_as_mutex")
+    inspector.add_file(detect_magic_channel_buffer, "magic_channel_buffer")
+    inspector.add_file(detect_goroutine_send_leak, "goroutine_send_leak")
+    inspector.add_file(detect_long_function, "long_function")
+    inspector.add_visitor(visit_else_after_return, "else_after_return")
+    inspector.add_visitor(visit_bool_literal_return, "bool_literal_return")
+    inspector.add_file(detect_redundant_nil_check, "redundant_nil_check")
+    inspector.add_visitor(visit_redundant_error_check, "redundant_error_check")
+    inspector.add_file(detect_pointer_to_small_type, "pointer_to_small_type")
+    inspector.add_file(detect_unused_field, "unused_field")
+    inspector.add_file(detect_struct_field_alignment, "struct_field_alignment")
+    for plugin_rule in registered_rules():
+        inspector.add_file(plugin_rule.run, plugin_rule.id)
+    for custom_rule in custom_rules:
+        inspector.add_file(rule_detector(custom_rule), custom_rule.id)
+    return inspector
+
+
+def _scan_package(
+    files: list[str],
+    *,
+    opt_in: frozenset[str],
+    syntax_only: bool = False,
+    unparsable: frozenset[str] = frozenset(),
+    plugins: tuple[str, ...] = (),
+    custom_rules: tuple[CustomRule, ...] = (),
+    rule_options: dict[str, dict] | None = None,
+) -> dict[str, list[dict]]:
+    """Run every enabled check over one package's files.
+
+    Module-level and self-contained so it can run in a worker process;
+    ``plugins`` are the rule plugins that process has to import.
+    """
+    ensure_sources(plugins)
+    return _scan_files(
+        files,
+        opt_in=opt_in,
+        syntax_only=syntax_only,
+        unparsable=unparsable,
+        custom_rules=custom_rules,
+        rule_options=rule_options,
+    )
+
+
+def _scan_package_timed(
+    files: list[str],
+    *,
+    opt_in: frozenset[str],
+    syntax_only: bool = False,
+    unparsable: frozenset[str] = frozenset(),
+    plugins: tuple[str, ...] = (),
+    custom_rules: tuple[CustomRule, ...] = (),
+    rule_options: dict[str, dict] | None = None,
+) -> tuple[dict[str, list[dict]], dict[str, float], float, float]:
+    """``_scan_package`` plus (seconds per rule, start, wall seconds) for ``--timings``.
+
+    ``start`` is a ``perf_counter()`` reading, which on the platforms we trace
+    on shares one clock across worker processes.
+    """
+    ensure_sources(plugins)
+    rule_seconds: dict[str, float] = {}
+    start = time.perf_counter()
+    counts = _scan_files(
+        files,
+        opt_in=opt_in,
+        syntax_only=syntax_only,
+        unparsable=unparsable,
+        rule_seconds=rule_seconds,
+        custom_rules=custom_rules,
+        rule_options=rule_options,
+    )
+    return counts, rule_seconds, start, time.perf_counter() - start
+
+
+def _scan_files(
+    files: list[str],
+    *,
+    opt_in: frozenset[str],
+    syntax_only: bool,
+    unparsable: frozenset[str] = frozenset(),
+    rule_seconds: dict[str, float] | None = None,
+    custom_rules: tuple[CustomRule, ...] = (),
+    rule_options: dict[str, dict] | None = None,
+) -> dict[str, list[dict]]:
+    """Parse each file once, then hand that parse to every enabled rule.
+
+    Past syntax level, rules also get the package's ``PackageTypes``; it is
+    type-checked (lazily) only when an enabled rule requires types.
+    """
+    clock = time.perf_counter
+    checks = _enabled_checks(
+        opt_in, syntax_only=syntax_only, custom_rules=custom_rules, rule_options=rule_options
+    )
+    inspector = _build_inspector({s["id"] for s in checks}, custom_rules, rule_options)
+    smell_counts: dict[str, list[dict]] = {s["id"]: [] for s in _all_checks(custom_rules)}
+    sources: list[GoFile] = []
+    tests = tuple(f for f in files if f.endswith("_test.go"))
+    for filepath in files:
+        if filepath.endswith("_test.go"):
+            continue
+        parse_start = clock()
+        try:
+            sources.append(GoFile(filepath, Path(filepath).read_text(errors="replace")))
+        except (OSError, UnicodeDecodeError):
+            continue
+        if rule_seconds is not None:
+            rule_seconds[PARSE_RULE] = rule_seconds.get(PARSE_RULE, 0.0) + clock() - parse_start
+    type_check = any(s["requires"] == "types" for s in checks)
+    types = (
+        None
+        if syntax_only
+        else PackageTypes(tuple(sources), type_check=type_check, test_paths=tests)
+    )
+    for source in sources:
+        _scan_source(
+            source,
+            checks,
+            inspector,
+            smell_counts,
+            parses=source.path not in unparsable,
+            rule_seconds=rule_seconds,
+            types=types,
+        )
+
+    return {smell_id: m for smell_id, m in smell_counts.items() if m}
+
+
+def smells_in_source(
+    filepath: str, content: str, *, opt_in: frozenset[str] = frozenset()
+) -> dict[str, list[dict]]:
+    """Every match in one file's ``content``, by smell id, without reading disk."""
+    checks = _enabled_checks(opt_in, syntax_only=False)
+    smell_counts: dict[str, list[dict]] = {s["id"]: [] for s in _all_checks()}
+    source = GoFile(filepath, content)
+    _scan_source(
+        source,
+        checks,
+        _build_inspector({s["id"] for s in checks}),
+        smell_counts,
+        types=PackageTypes((source,), type_check=any(s["requires"] == "types" for s in checks)),
+    )
+    return {smell_id: m for smell_id, m in smell_counts.items() if m}
+
+
+def _scan_source(
+    source: GoFile,
+    checks: list[dict],
+    inspector: Inspector,
+    smell_counts: dict[str, list[dict]],
+    *,
+    parses: bool = True,
+    rule_seconds: dict[str, float] | None = None,
+    types: PackageTypes | None = None,
+) -> None:
+    clock = time.perf_counter
+    filepath = source.path
+    lines = source.lines
+
+    counts_before = {smell_id: len(m) for smell_id, m in smell_counts.items()}
+
+    for check in checks:
+        if check["pattern"] is None:
+            continue
+        check_start = clock()
+        pat = re.compile(check["pattern"])
+        for i, line in enumerate(lines):
+            # Don't skip comment lines for TODO detection
+            if _is_comment_line(line) and check["id"] != "todo_fixme":
+                continue
+            if pat.search(line):
+                smell_counts[check["id"]].append(
+                    {
+                        "file": filepath,
+                        "line": i + 1,
+                        "content": line.strip()[:100],
+                    }
+                )
+        if rule_seconds is not None:
+            rule_seconds[check["id"]] = (
+                rule_seconds.get(check["id"], 0.0) + clock() - check_start
+            )
+
+    # Multi-line detectors need a file that parses.
+    if parses:
+        inspector.run(source, smell_counts, rule_seconds, types=types)
+    _drop_suppressed(lines, smell_counts, counts_before)
+
+
+def _drop_suppressed(
+    lines: list[str], smell_counts: dict[str, list[dict]], counts_before: dict[str, int]
+) -> None:
+    """Remove this file's matches covered by a //desloppify:ignore directive."""
+    for smell_id, matches in smell_counts.items():
+        start = counts_before[smell_id]
+        if len(matches) == start:
+            continue
+        matches[start:] = [
+            m for m in matches[start:] if not is_suppressed(lines, m["line"], smell_id)
+        ]
+
+
 def _is_comment_line(line: str) -> bool:
     stripped = line.strip()
     return stripped.startswith("//") or stripped.startswith("/*")
 
 
-def _is_main_package(lines: list[str]) -> bool:
-    for line in lines:
-        stripped = line.strip()
-        if stripped.startswith("package "):
-            return stripped == "package main"
-    return False
-
-
-def _detect_unbuffered_signal(
-    filepath: str, lines: list[str], smell_counts: dict[str, list]
-):
+def _detect_unbuffered_signal(pass_: Pass) -> None:
     """Detect signal.Notify with unbuffered channel."""
     chan_vars: set[str] = set()
-    for i, line in enumerate(lines):
+    for i, line in enumerate(pass_.file.lines):
         stripped = line.strip()
         if _is_comment_line(line):
             continue
@@ -179,19 +1752,12 @@ def _detect_unbuffered_signal(
         if "signal.Notify" in stripped:
             for var in chan_vars:
                 if var in stripped:
-                    smell_counts["unbuffered_signal"].append(
-                        {
-                            "file": filepath,
-                            "line": i + 1,
-                            "content": stripped[:100],
-                        }
-                    )
-
-
-def _detect_single_case_select(
-    filepath: str, content: str, smell_counts: dict[str, list]
-):
+                    pass_.report(i + 1)
+
+
+def _detect_single_case_select(pass_: Pass) -> None:
     """Detect select statements with only one case."""
+    content = pass_.file.content
     select_re = re.compile(r"\bselect\s*\{")
     for m in select_re.finditer(content):
         start = m.end()
@@ -208,23 +1774,17 @@ def _detect_single_case_select(
         default_count = len(re.findall(r"^\s*default\s*:", block, re.MULTILINE))
         total = case_count + default_count
         if total == 1:
-            line_num = content[: m.start()].count("\n") + 1
-            smell_counts["single_case_select"].append(
-                {
-                    "file": filepath,
-                    "line": line_num,
-                    "content": content[m.start() : m.start() + 80].strip(),
-                }
+            pass_.report(
+                pass_.file.line_at(m.start()),
+                content=content[m.start() : m.start() + 80].strip(),
             )
 
 
-def _detect_nil_map_write(
-    filepath: str, lines: list[str], smell_counts: dict[str, list]
-):
+def _detect_nil_map_write(pass_: Pass) -> None:
     """Detect potential writes to nil maps (var m map[...] without make)."""
     uninit_maps: dict[str, int] = {}
 
-    for i, line in enumerate(lines):
+    for i, line in enumerate(pass_.file.lines):
         stripped = line.strip()
         if _is_comment_line(line):
             continue
@@ -241,26 +1801,18 @@ def _detect_nil_map_write(
 
         for var_name in list(uninit_maps):
             if re.search(rf"\b{re.escape(var_name)}\s*\[.+\]\s*=", stripped):
-                smell_counts["nil_map_write"].append(
-                    {
-                        "file": filepath,
-                        "line": i + 1,
-                        "content": stripped[:100],
-                    }
-                )
+                pass_.report(i + 1)
                 del uninit_maps[var_name]
 
 
-def _detect_string_concat_loop(
-    filepath: str, lines: list[str], smell_counts: dict[str, list]
-):
+def _detect_string_concat_loop(pass_: Pass) -> None:
     """Detect string concatenation with += inside loops."""
     in_loop = False
     loop_depth = 0
     brace_depth = 0
     loop_brace_depth = 0
 
-    for i, line in enumerate(lines):
+    for i, line in enumerate(pass_.file.lines):
         stripped = line.strip()
         if _is_comment_line(line):
             continue
@@ -281,13 +1833,7 @@ def _detect_string_concat_loop(
         if in_loop and "+=" in stripped:
             m = re.match(r"(\w+)\s*\+=", stripped)
             if m:
-                smell_counts["string_concat_loop"].append(
-                    {
-                        "file": filepath,
-                        "line": i + 1,
-                        "content": stripped[:100],
-                    }
-                )
+                pass_.report(i + 1)
 
 
 _YODA_RE = re.compile(
@@ -303,22 +1849,48 @@ _YODA_RE = re.compile(
 )
 
 
-def _detect_yoda_condition(
-    filepath: str, lines: list[str], smell_counts: dict[str, list]
-):
+# The rewrite: literal and operand swapped, with the operator mirrored. Only
+# simple operands (names, selectors, one index or call) are moved.
+_YODA_FIX_RE = re.compile(
+    r"""(?P<lead>(?:\bif|&&|\|\|)\s+)
+    (?P<lit>true|false|nil|\d+(?:\.\d+)?|"[^"\\]*")
+    \s*(?P<op>==|!=|>=|<=|>|<)\s*
+    (?P<rhs>[A-Za-z_][\w.]*(?:\[[^\[\]]*\]|\([^()]*\))?)
+    (?=\s*(?:&&|\|\||\{|\)|;|$))""",
+    re.VERBOSE,
+)
+
+_FLIPPED = {"==": "==", "!=": "!=", "<": ">", ">": "<", "<=": ">=", ">=": "<="}
+
+
+def _swap_yoda(match: re.Match[str]) -> str:
+    literal, op = match.group("lit"), match.group("op")
+    if op not in ("==", "!=") and not literal[0].isdigit():
+        return match.group(0)
+    return f"{match.group('lead')}{match.group('rhs')} {_FLIPPED[op]} {literal}"
+
+
+def _yoda_fix(line: str, lineno: int) -> dict | None:
+    """One edit swapping every Yoda comparison on ``line``, if any can be."""
+    spans = [m for m in _YODA_FIX_RE.finditer(line) if _swap_yoda(m) != m.group(0)]
+    if not spans:
+        return None
+    old = line[spans[0].start() : spans[-1].end()]
+    new = _YODA_FIX_RE.sub(_swap_yoda, old)
+    return {"title": f"Rewrite as {new.strip()}", "line": lineno, "old": old, "new": new}
+
+
+def _detect_yoda_condition(pass_: Pass) -> None:
     """Detect Yoda conditions (literal on left side of comparison)."""
-    for i, line in enumerate(lines):
+    for i, line in enumerate(pass_.file.lines):
         stripped = line.strip()
         if _is_comment_line(line):
             continue
         if _YODA_RE.search(stripped):
-            smell_counts["yoda_condition"].append(
-                {
-                    "file": filepath,
-                    "line": i + 1,
-                    "content": stripped[:100],
-                }
-            )
+            entry = pass_.report(i + 1)
+            fix = _yoda_fix(line, i + 1)
+            if fix is not None:
+                entry["fix"] = fix
 
 
 _FUNC_PARAMS_RE = re.compile(
@@ -326,29 +1898,14 @@ _FUNC_PARAMS_RE = re.compile(
     re.MULTILINE,
 )
 
-_MAX_PARAMS = 5
-
 
-def _detect_too_many_params(
-    filepath: str, content: str, smell_counts: dict[str, list]
-):
-    """Detect functions with more than 5 parameters."""
-    lines = content.splitlines()
-    for m in _FUNC_PARAMS_RE.finditer(content):
+def _detect_too_many_params(pass_: Pass) -> None:
+    """Detect functions with more than the ``max`` option's parameters."""
+    limit = pass_.options["max"]
+    for m in _FUNC_PARAMS_RE.finditer(pass_.file.content):
         param_list = m.group(1).strip()
         if not param_list:
             continue
         param_count = param_list.count(",") + 1
-        if param_count > _MAX_PARAMS:
-            line_num = content[: m.start()].count("\n") + 1
-            smell_counts["too_many_params"].append(
-                {
-                    "file": filepath,
-                    "line": line_num,
-                    "content": (
-                        lines[line_num - 1].strip()[:100]
-                        if line_num <= len(lines)
-                        else ""
-                    ),
-                }
-            )
+        if param_count > limit:
+            pass_.report(pass_.file.line_at(m.start()))
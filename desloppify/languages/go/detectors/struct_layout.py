@@ -0,0 +1,462 @@
+"""Go struct layout analysis: padding waste from field ordering, and
+pointers to values too small to be worth one.
+
+Sizes and alignments follow the gc compiler on 64-bit targets (amd64/arm64).
+Structs containing a field whose layout cannot be resolved from the file
+itself or the well-known table below are skipped rather than guessed.
+
+``pointer_to_small_type`` reports parameters and struct fields typed
+``*T`` where ``T`` is a plain value (booleans, numbers, strings, arrays
+and structs of them) of at most ``max_bytes`` bytes: copying it costs
+less than the indirection, and the pointer adds a nil to handle. The
+pointer is left alone wherever it may be needed:
+
+- a parameter that is compared with nil, written through, passed on,
+  read in a loop (which may be watching it change), has its address
+  taken or a method called, or is not used at all;
+- a field with an ``omitempty`` tag or a comment that says it is
+  optional, or that the package compares with nil, sets to nil, writes
+  through, passes on or points at other storage;
+- types holding locks or atomics, which must not be copied, ``*byte``
+  and ``*uint16``, which point into buffers, and empty structs;
+- generated files, and files importing ``unsafe``, ``syscall``, ``C`` or
+  ``golang.org/x/sys``, whose pointers mirror C structures.
+"""
+
+from __future__ import annotations
+
+import re
+
+from desloppify.languages.go.detectors._inspector import GoFile, Pass
+from desloppify.languages.go.detectors._source import matching_brace
+from desloppify.languages.go.detectors.assignments import _signature_groups
+from desloppify.languages.go.detectors.custom_rules import _imports
+from desloppify.languages.go.detectors.error_flow import _DECL_RE, _body_brace, _closing_paren
+
+# Structs smaller than this rarely matter enough to reorder (the ``min_bytes``
+# option's default).
+STRUCT_ALIGNMENT_MIN_BYTES = 32
+# Default of ``pointer_to_small_type``'s ``max_bytes`` option: two words.
+SMALL_TYPE_MAX_BYTES = 16
+
+_WORD = 8
+
+_BASIC_LAYOUTS: dict[str, tuple[int, int]] = {
+    "bool": (1, 1),
+    "int8": (1, 1),
+    "uint8": (1, 1),
+    "byte": (1, 1),
+    "int16": (2, 2),
+    "uint16": (2, 2),
+    "int32": (4, 4),
+    "uint32": (4, 4),
+    "rune": (4, 4),
+    "float32": (4, 4),
+    "int": (8, 8),
+    "uint": (8, 8),
+    "int64": (8, 8),
+    "uint64": (8, 8),
+    "uintptr": (8, 8),
+    "float64": (8, 8),
+    "complex64": (8, 4),
+    "complex128": (16, 8),
+    "string": (16, 8),
+    "error": (16, 8),
+    "any": (16, 8),
+    "unsafe.Pointer": (8, 8),
+    "time.Time": (24, 8),
+    "time.Duration": (8, 8),
+    "time.Month": (8, 8),
+    "sync.Mutex": (8, 4),
+    "sync.RWMutex": (24, 4),
+    "sync.Once": (12, 4),
+    "sync.WaitGroup": (16, 8),
+    "atomic.Bool": (4, 4),
+    "atomic.Int32": (4, 4),
+    "atomic.Uint32": (4, 4),
+    "atomic.Int64": (8, 8),
+    "atomic.Uint64": (8, 8),
+    "atomic.Value": (16, 8),
+    "context.Context": (16, 8),
+}
+
+_TYPE_DECL_RE = re.compile(r"\btype\s+(\w+)\s+")
+_ARRAY_RE = re.compile(r"^\[\s*(\d+)\s*\](.+)$", re.DOTALL)
+
+
+def _align_up(offset: int, align: int) -> int:
+    return (offset + align - 1) // align * align
+
+
+def _layout_of(fields: list[tuple[int, int]]) -> tuple[int, int]:
+    offset = 0
+    max_align = 1
+    for size, align in fields:
+        offset = _align_up(offset, align) + size
+        max_align = max(max_align, align)
+    return _align_up(offset, max_align), max_align
+
+
+def _split_top_level(body: str) -> list[str]:
+    """Split a masked struct body into field declarations (newline/; separated)."""
+    decls: list[str] = []
+    depth = 0
+    current: list[str] = []
+    for ch in body:
+        if ch in "{([":
+            depth += 1
+        elif ch in "})]":
+            depth -= 1
+        if depth == 0 and ch in "\n;":
+            decls.append("".join(current).strip())
+            current = []
+            continue
+        current.append(ch)
+    decls.append("".join(current).strip())
+    return [decl for decl in decls if decl]
+
+
+def _strip_tag(decl: str) -> str:
+    # Tags are masked string literals: a pair of quotes/backticks at the end.
+    return re.sub(r"\s*(?:`\s*`|\"\s*\")\s*$", "", decl).strip()
+
+
+def _parse_field(decl: str) -> tuple[list[str], str]:
+    """Return (names, type_expr) for a field declaration; embedded → ([], type)."""
+    decl = _strip_tag(decl)
+    m = re.match(r"^([A-Za-z_]\w*(?:\s*,\s*[A-Za-z_]\w*)*)\s+(\S.*)$", decl, re.DOTALL)
+    if m and not m.group(1).endswith("."):
+        names = [name.strip() for name in m.group(1).split(",")]
+        return names, m.group(2).strip()
+    return [], decl
+
+
+class _Resolver:
+    def __init__(self, masked: str) -> None:
+        self._decls = self._collect_type_decls(masked)
+        self._resolving: set[str] = set()
+
+    @staticmethod
+    def _collect_type_decls(masked: str) -> dict[str, str]:
+        decls: dict[str, str] = {}
+        for m in _TYPE_DECL_RE.finditer(masked):
+            rest = masked[m.end() :]
+            if rest.startswith("struct") and re.match(r"struct\s*\{", rest):
+                open_pos = m.end() + rest.index("{")
+                close = matching_brace(masked, open_pos)
+                if close is None:
+                    continue
+                decls[m.group(1)] = masked[m.end() : close + 1]
+                continue
+            type_expr = rest.split("\n", 1)[0].strip()
+            if type_expr and not type_expr.startswith("="):
+                decls[m.group(1)] = type_expr
+        return decls
+
+    def struct_fields(self, struct_expr: str) -> list[tuple[str, int, int]] | None:
+        body = struct_expr[struct_expr.index("{") + 1 : struct_expr.rindex("}")]
+        fields: list[tuple[str, int, int]] = []
+        for decl in _split_top_level(body):
+            names, type_expr = _parse_field(decl)
+            layout = self.layout(type_expr)
+            if layout is None:
+                return None
+            for name in names or [type_expr.lstrip("*").split(".")[-1]]:
+                fields.append((name, *layout))
+        return fields
+
+    def layout(self, type_expr: str) -> tuple[int, int] | None:
+        type_expr = type_expr.strip()
+        if type_expr in _BASIC_LAYOUTS:
+            return _BASIC_LAYOUTS[type_expr]
+        if type_expr.startswith(("*", "map[", "chan", "<-chan", "func")):
+            return (_WORD, _WORD)
+        if type_expr.startswith("[]"):
+            return (3 * _WORD, _WORD)
+        if type_expr.startswith("interface"):
+            return (2 * _WORD, _WORD)
+        array = _ARRAY_RE.match(type_expr)
+        if array:
+            elem = self.layout(array.group(2))
+            if elem is None:
+                return None
+            return (int(array.group(1)) * elem[0], elem[1])
+        if re.match(r"struct\s*\{", type_expr):
+            fields = self.struct_fields(type_expr)
+            if fields is None:
+                return None
+            return _layout_of([(size, align) for _name, size, align in fields])
+        if type_expr in self._decls and type_expr not in self._resolving:
+            self._resolving.add(type_expr)
+            try:
+                return self.layout(self._decls[type_expr])
+            finally:
+                self._resolving.discard(type_expr)
+        return None
+
+
+def optimal_size(fields: list[tuple[int, int]]) -> int:
+    """Minimum struct size achievable by reordering (alignment-descending)."""
+    ordered = sorted(fields, key=lambda f: (-f[1], -f[0]))
+    return _layout_of(ordered)[0]
+
+
+def detect_struct_field_alignment(pass_: Pass) -> None:
+    """Detect structs whose field order wastes padding bytes."""
+    source = pass_.file
+    masked = source.masked
+    resolver = _Resolver(masked)
+    for m in re.finditer(r"\btype\s+(\w+)\s+struct\s*\{", masked):
+        close = matching_brace(masked, m.end() - 1)
+        if close is None:
+            continue
+        fields = resolver.struct_fields(masked[m.end() - 1 : close + 1])
+        if not fields:
+            continue
+        layouts = [(size, align) for _name, size, align in fields]
+        current, _align = _layout_of(layouts)
+        best = optimal_size(layouts)
+        if current < pass_.options["min_bytes"] or best >= current:
+            continue
+        pass_.report(
+            source.line_at(m.start()),
+            struct=m.group(1),
+            current_size=current,
+            optimal_size=best,
+        )
+
+
+_PLAIN_TYPES = frozenset({
+    "bool", "byte", "rune", "string", "int", "int8", "int16", "int32", "int64",
+    "uint", "uint8", "uint16", "uint32", "uint64", "uintptr", "float32", "float64",
+    "complex64", "complex128", "time.Duration", "time.Month",
+})
+# `*byte` and `*uint16` point into buffers and C strings, not at one value.
+_BUFFER_TYPES = frozenset({"byte", "uint8", "uint16"})
+# Files that mirror C or kernel structures, whose pointers are the ABI.
+_ABI_IMPORTS = frozenset({"C", "syscall", "unsafe"})
+_GENERATED_RE = re.compile(r"^// Code generated .* DO NOT EDIT\.$", re.MULTILINE)
+_STRUCT_RE = re.compile(r"\btype\s+(\w+)\s+struct\s*\{")
+# What follows a write: `= v`, `+= v`, `++`, but not `==`.
+_WRITE_RE = re.compile(r"\s*(?:(?:<<|>>|&\^|[-+*/%&|^])?=(?!=)|\+\+|--)")
+_FOR_RE = re.compile(r"[ \t]*for\b")
+_KEYWORDS = frozenset({"case", "defer", "else", "go", "range", "return"})
+_NIL_RE = re.compile(r"\s*(?:[!=]=|=)\s*nil\b")
+_ADDRESS_OF_LOCAL_RE = re.compile(r"[ \t]*&[ \t]*\w+\b(?![ \t]*[.\[({])")
+_OPTIONAL_RE = re.compile(r"\b(?:optional|nil|unset)\b", re.IGNORECASE)
+
+
+def _plain(resolver: _Resolver, type_expr: str, seen: frozenset[str] = frozenset()) -> bool:
+    """Whether ``type_expr`` is a value type with no pointers, locks or atomics."""
+    type_expr = type_expr.strip()
+    if type_expr in _PLAIN_TYPES:
+        return True
+    array = _ARRAY_RE.match(type_expr)
+    if array:
+        return _plain(resolver, array.group(2), seen)
+    if re.match(r"struct\s*\{.*\}$", type_expr, re.DOTALL):
+        body = type_expr[type_expr.index("{") + 1 : type_expr.rindex("}")]
+        fields = [_parse_field(decl) for decl in _split_top_level(body)]
+        return all(names and _plain(resolver, t, seen) for names, t in fields)
+    underlying = resolver._decls.get(type_expr)
+    if underlying is None or type_expr in seen:
+        return False
+    return _plain(resolver, underlying, seen | {type_expr})
+
+
+def _small(resolver: _Resolver, pointer: str, max_bytes: int) -> int | None:
+    """Size of the value ``pointer`` (``*T``) points to, when it is plain and small."""
+    if not pointer.startswith("*"):
+        return None
+    target = pointer[1:].strip()
+    if target in _BUFFER_TYPES or not _plain(resolver, target):
+        return None
+    layout = resolver.layout(target)
+    return layout[0] if layout is not None and 0 < layout[0] <= max_bytes else None
+
+
+def _chain_start(masked: str, pos: int) -> int:
+    """Start of the selector chain (``a.b.c``) that ends at ``pos``."""
+    while pos > 0 and (masked[pos - 1].isalnum() or masked[pos - 1] in "_."):
+        pos -= 1
+    return pos
+
+
+def _dereferenced(masked: str, start: int) -> bool:
+    """Whether the operand at ``start`` is preceded by a unary ``*``."""
+    before = masked[max(0, start - 80) : start].rstrip(" \t")
+    if not before.endswith("*"):
+        return False
+    before = before[:-1].rstrip(" \t")
+    word = re.search(r"\w+$", before)
+    if word is not None:
+        return word.group() in _KEYWORDS
+    return not before.endswith((")", "]"))
+
+
+def _operand_end(masked: str, pos: int, parenthesized: bool) -> int:
+    """End of the operand at ``pos`` with its selectors and indexes: ``(*p)[i].x``."""
+    if parenthesized:
+        m = re.match(r"[ \t]*\)", masked[pos : pos + 8])
+        pos += m.end() if m is not None else 0
+    while pos < len(masked):
+        if masked[pos] == "[":
+            close = _closing_bracket(masked, pos)
+            if close is None:
+                break
+            pos = close + 1
+        elif m := re.match(r"\.\w+", masked[pos : pos + 80]):
+            pos += m.end()
+        else:
+            break
+    return pos
+
+
+def _closing_bracket(masked: str, open_: int) -> int | None:
+    depth = 0
+    for i in range(open_, len(masked)):
+        if masked[i] == "[":
+            depth += 1
+        elif masked[i] == "]":
+            depth -= 1
+            if depth == 0:
+                return i
+    return None
+
+
+def _escapes(masked: str, pos: int, end: int) -> bool:
+    """Whether the operand ending at ``end`` is written, called or has its address taken."""
+    if _WRITE_RE.match(masked, end) or re.match(r"[ \t]*\(", masked[end : end + 8]):
+        return True
+    return masked[max(0, pos - 80) : pos].rstrip(" \t(").endswith("&")
+
+
+def _in_loop(source: GoFile, pos: int) -> bool:
+    """Whether ``pos`` is in a ``for`` header or body."""
+    starts = [source.line_start(pos)]
+    starts.extend(source.line_start(brace) for brace in source.enclosing_blocks(pos))
+    return any(_FOR_RE.match(source.masked, start) for start in starts)
+
+
+def _param_needs_pointer(source: GoFile, name: str, start: int, end: int) -> bool:
+    """Whether the body ``start:end`` uses parameter ``name`` as more than a value.
+
+    Reads in a loop count too: the loop may be watching the value change.
+    """
+    masked = source.masked
+    uses = list(re.finditer(rf"(?<![\w.]){name}\b", masked[start:end]))
+    if not uses:
+        return True
+    for m in uses:
+        pos, after = start + m.start(), start + m.end()
+        dereferenced = _dereferenced(masked, pos)
+        if not dereferenced and not masked.startswith(".", after) or _in_loop(source, pos):
+            return True
+        parenthesized = dereferenced and masked[max(0, pos - 80) : pos].rstrip(" \t*").endswith("(")
+        if _escapes(masked, pos, _operand_end(masked, after, parenthesized)):
+            return True
+    return False
+
+
+def _field_needs_pointer(files: tuple[GoFile, ...], name: str) -> bool:
+    """Whether the package uses field ``name`` as more than an optional value."""
+    for file in files:
+        masked = file.masked
+        if re.search(rf"(?<![\w.]){name}\s*:\s*nil\b", masked):
+            return True
+        for m in re.finditer(rf"\.{name}\b", masked):
+            if _NIL_RE.match(masked, m.end()):
+                return True
+            start = _chain_start(masked, m.start())
+            if _dereferenced(masked, start):
+                parenthesized = masked[max(0, start - 80) : start].rstrip(" \t*").endswith("(")
+                if _escapes(masked, start, _operand_end(masked, m.end(), parenthesized)):
+                    return True
+                continue
+            # Only `x.F = &v` may take the field bare, and not to alias other storage.
+            write = _WRITE_RE.match(masked, m.end())
+            if write is None or not _ADDRESS_OF_LOCAL_RE.match(masked, write.end()):
+                return True
+    return False
+
+
+def _documented_optional(content: str, line_start: int) -> bool:
+    """Whether the field at ``line_start``, or the comment above it, says nil means something."""
+    end = content.find("\n", line_start)
+    lines = [content[line_start : end if end != -1 else len(content)]]
+    above = content[max(0, line_start - 2000) : line_start].splitlines()
+    while above and above[-1].lstrip().startswith("//"):
+        lines.append(above.pop())
+    return _OPTIONAL_RE.search("\n".join(lines)) is not None
+
+
+def detect_pointer_to_small_type(pass_: Pass) -> None:
+    """Detect parameters and struct fields that point to small plain values.
+
+    Reported with the ``name``, its ``kind`` (``parameter`` or ``field``),
+    the pointer ``type`` and the ``size`` in bytes of what it points to.
+    """
+    source = pass_.file
+    if _GENERATED_RE.search(source.content) or any(
+        path in _ABI_IMPORTS or path.startswith("golang.org/x/sys/")
+        for _, path, _ in _imports(source)
+    ):
+        return
+    masked = source.masked
+    files = pass_.types.files if pass_.types is not None else (source,)
+    resolver = _Resolver("\n".join(f.masked for f in files))
+    max_bytes = pass_.options["max_bytes"]
+    found = []
+    for m in _DECL_RE.finditer(masked):
+        close = _closing_paren(masked, m.end() - 1)
+        brace = _body_brace(masked, close + 1) if close is not None else None
+        body_close = matching_brace(masked, brace) if brace is not None else None
+        if body_close is None:
+            continue
+        groups = _signature_groups(masked, m.start(), brace)
+        index = 1 if m.group(1) is not None else 0
+        for name, type_ in groups[index] if len(groups) > index else ():
+            size = _small(resolver, type_, max_bytes)
+            if size is None or name == "_":
+                continue
+            if not _param_needs_pointer(source, name, brace, body_close):
+                found.append((m.start(), name, "parameter", type_, size))
+    for m in _STRUCT_RE.finditer(masked):
+        close = matching_brace(masked, m.end() - 1)
+        if close is None:
+            continue
+        line_start = m.end()
+        for decl in masked[m.end() : close].split("\n"):
+            tag = source.content[line_start : line_start + len(decl)]
+            names, type_ = _parse_field(decl.strip()) if decl.strip() else ([], "")
+            size = _small(resolver, type_, max_bytes) if names else None
+            if (
+                size is not None
+                and "omitempty" not in tag
+                and not _documented_optional(source.content, line_start)
+            ):
+                found.extend(
+                    (line_start, name, "field", type_, size)
+                    for name in names
+                    if not _field_needs_pointer(files, name)
+                )
+            line_start += len(decl) + 1
+    for pos, name, kind, type_, size in sorted(found):
+        pass_.report(
+            source.line_at(pos),
+            name=name,
+            kind=kind,
+            type=type_,
+            size=size,
+            hint=f"{type_[1:]} is {size} bytes; pass the {kind} {name} by value, "
+            "and use the zero value or a second result where nil meant unset",
+        )
+
+
+__all__ = [
+    "SMALL_TYPE_MAX_BYTES",
+    "STRUCT_ALIGNMENT_MIN_BYTES",
+    "detect_pointer_to_small_type",
+    "detect_struct_field_alignment",
+    "optimal_size",
+]
@@ -0,0 +1,258 @@
+"""``fmt.Sprintf`` building values that have their own constructors.
+
+A format string is the wrong tool for a structured value, and three shapes
+of it are reported, keyed on the format and what is formatted into it:
+
+- ``sprintf_path``: ``fmt.Sprintf("%s/%s", dir, name)``, a format of
+  ``/``-separated verbs and plain segments with at least one argument named
+  like a path (``dir``, ``root``, ``path``, ...) or built by ``filepath``
+  or ``os``. ``filepath.Join`` cleans the separators and uses the
+  platform's; the suggestion spells it out.
+- ``sprintf_url`` and ``sprintf_url_param``: a query value formatted in
+  unescaped, ``fmt.Sprintf("%s?q=%s", base, q)``. A ``&`` or ``#`` in the
+  value changes the query; ``url.Values`` or ``url.QueryEscape`` escape
+  it. ``%d`` values and arguments already escaped (``url.QueryEscape``,
+  ``url.PathEscape``, ``template.URLQueryEscaper``, ``.Encode()``) are
+  fine. When a value comes from a parameter of the enclosing functions,
+  directly or through a local assigned from one, the caller controls it,
+  and the finding is ``sprintf_url_param``, at higher severity.
+- ``sprintf_duration``: ``time.ParseDuration(fmt.Sprintf("%ds", n))``,
+  directly or through a variable, formats a number only to parse it back;
+  ``time.Duration(n) * time.Second`` says the same without the error.
+"""
+
+from __future__ import annotations
+
+import re
+
+from desloppify.languages.go.detectors._inspector import GoFile, Pass
+from desloppify.languages.go.detectors.assignments import (
+    _signature_groups,
+    function_variables,
+)
+from desloppify.languages.go.detectors.custom_rules import _imports
+from desloppify.languages.go.detectors.error_flow import _closing_paren, _format_verbs
+from desloppify.languages.go.detectors.logic import _split_top_level
+
+_SPRINTF_RE = re.compile(r"(?<![\w.])fmt\.Sprintf\(")
+_VERB = r"%[-+# 0]*\d*(?:\.\d*)?[a-zA-Z]"
+_SEGMENT_RE = re.compile(rf"(?:{_VERB}|[\w.\-~]+)+")
+_PATH_ARG_RE = re.compile(r"(?i)[\w.]*?(?:dir|path|root|base|folder|file|home|cwd|wd)s?")
+_PATH_CALL_RE = re.compile(r"^(?:filepath\.\w+|os\.(?:TempDir|Getwd|UserHomeDir)|t\.TempDir)\(")
+# `?q=%s` or `&page=%v`: a query value formatted in.
+_QUERY_VALUE_RE = re.compile(rf"[?&][\w.\-\[\]]+=({_VERB})")
+_ESCAPED_RE = re.compile(
+    r"(?:url\.(?:Query|Path)Escape\(|template\.URLQueryEscaper\(|\.Encode\(\)$)"
+)
+_UNITS = ("ns", "us", "µs", "ms", "s", "m", "h")
+_DURATION_FORMAT_RE = re.compile(rf"^(%[dv]|%\.?\d*f)({'|'.join(_UNITS)})$")
+_UNIT_NAMES = {
+    "ns": "Nanosecond",
+    "us": "Microsecond",
+    "µs": "Microsecond",
+    "ms": "Millisecond",
+    "s": "Second",
+    "m": "Minute",
+    "h": "Hour",
+}
+_PARSE_DURATION_RE = re.compile(r"(?<![\w.])time\.ParseDuration\([ \t]*$")
+
+
+def _sprintf_calls(source: GoFile) -> list[tuple[int, int, str, list[str]]]:
+    """(offset, close, format, arguments) of each ``fmt.Sprintf`` with a literal format."""
+    def build() -> list[tuple[int, int, str, list[str]]]:
+        masked, content = source.masked, source.content
+        calls = []
+        for m in _SPRINTF_RE.finditer(masked):
+            close = _closing_paren(masked, m.end() - 1)
+            if close is None:
+                continue
+            spans = _split_top_level(masked, m.end(), close, ",")
+            first = content[spans[0][0] : spans[0][1]].strip() if spans else ""
+            if len(first) < 2 or first[0] not in "\"`" or first[-1] != first[0]:
+                continue
+            args = [content[a:b].strip() for a, b in spans[1:]]
+            calls.append((m.start(), close, first[1:-1], args))
+        return calls
+
+    return source.memo("structured_sprintf:calls", build)
+
+
+def _mappable(fmt: str, args: list[str]) -> list[str] | None:
+    verbs = _format_verbs(fmt)
+    return verbs if verbs is not None and len(verbs) == len(args) else None
+
+
+def _segment_expr(pieces: list[str], values: list[str]) -> str:
+    """A Go expression for one path segment: literal pieces around verbs."""
+    if len(values) > 1 or len(values) == 1 and pieces[1] not in ("%s", "%v", "%d"):
+        return f'fmt.Sprintf("{"".join(pieces)}", {", ".join(values)})'
+    if not values:
+        return f'"{pieces[0]}"'
+    before, verb, after = pieces
+    terms = [f"strconv.Itoa({values[0]})" if verb == "%d" else values[0]]
+    if before:
+        terms.insert(0, f'"{before}"')
+    if after:
+        terms.append(f'"{after}"')
+    return "+".join(terms)
+
+
+def _path_join(source: GoFile, fmt: str, args: list[str]) -> str | None:
+    """The ``Join`` call a path-shaped format stands for, or None."""
+    if "://" in fmt or "?" in fmt or fmt.count("/") == 0 or fmt.endswith("/"):
+        return None
+    segments = fmt.split("/")
+    leading = segments[0] == ""
+    if leading:
+        segments = segments[1:]
+    if not all(_SEGMENT_RE.fullmatch(s) for s in segments):
+        return None
+    if not any(_PATH_ARG_RE.fullmatch(a) or _PATH_CALL_RE.match(a) for a in args):
+        return None  # "%s/%s" joins many things; only paths are reported
+    parts = ['"/"'] if leading else []
+    remaining = iter(args)
+    for segment in segments:
+        pieces = re.split(f"({_VERB})", segment)
+        values = [next(remaining) for _ in pieces[1::2]]
+        parts.append(_segment_expr(pieces, values))
+    imports = {path for _, path, _ in _imports(source)}
+    package = "path" if "path" in imports and "path/filepath" not in imports else "filepath"
+    return f"{package}.Join({', '.join(parts)})"
+
+
+def detect_sprintf_path(pass_: Pass) -> None:
+    """Detect ``fmt.Sprintf`` joining path segments; reported with a ``suggestion``."""
+    source = pass_.file
+    for offset, _close, fmt, args in _sprintf_calls(source):
+        if _mappable(fmt, args) is None:
+            continue
+        suggestion = _path_join(source, fmt, args)
+        if suggestion is not None:
+            pass_.report(
+                source.line_at(offset),
+                suggestion=suggestion,
+                hint=f"use {suggestion}: it cleans the separators and uses the platform's",
+            )
+
+
+def _tainted(source: GoFile, pos: int) -> set[str]:
+    """Parameters of the functions around ``pos``, and the locals assigned from them."""
+    masked = source.masked
+    names: set[str] = set()
+    enclosing = [f for f in function_variables(source) if f.body_open < pos < f.body_close]
+    for func in enclosing:
+        groups = _signature_groups(masked, func.start, func.body_open)
+        index = 1 if func.method else 0
+        if len(groups) > index:
+            names.update(name for name, _ in groups[index] if name != "_")
+    changed = True
+    while changed:
+        changed = False
+        for func in enclosing:
+            for var in func.variables:
+                if var.name in names:
+                    continue
+                if any(
+                    d.start < pos and set(re.findall(r"(?<![\w.])\w+", d.rhs)) & names
+                    for d in var.defs
+                ):
+                    names.add(var.name)
+                    changed = True
+    return names
+
+
+def _url_findings(pass_: Pass) -> list[tuple[int, str, bool]]:
+    """(offset, argument, from a parameter) of each unescaped query value."""
+    source = pass_.file
+
+    def build() -> list[tuple[int, str, bool]]:
+        found = []
+        for offset, _close, fmt, args in _sprintf_calls(source):
+            verbs = _mappable(fmt, args)
+            if verbs is None or "?" not in fmt:
+                continue
+            positions = [m.start() for m in re.finditer(_VERB, fmt)]
+            if len(positions) != len(verbs):
+                continue
+            unescaped = []
+            for m in _QUERY_VALUE_RE.finditer(fmt):
+                index = positions.index(m.start(1))
+                arg = args[index]
+                if verbs[index] in "sqv" and not _ESCAPED_RE.search(arg):
+                    unescaped.append(arg)
+            if not unescaped:
+                continue
+            tainted = _tainted(source, offset)
+            from_param = next(
+                (a for a in unescaped if set(re.findall(r"(?<![\w.])\w+", a)) & tainted), None
+            )
+            found.append((offset, from_param or unescaped[0], from_param is not None))
+        return found
+
+    return source.memo("structured_sprintf:urls", build)
+
+
+def _report_url(pass_: Pass, offset: int, arg: str) -> None:
+    pass_.report(
+        pass_.file.line_at(offset),
+        value=arg,
+        hint=f"{arg} goes into the query unescaped, where & or # change its meaning; "
+        f"build it with url.Values and Encode, or url.QueryEscape({arg})",
+    )
+
+
+def detect_sprintf_url(pass_: Pass) -> None:
+    """Detect query values formatted into a URL unescaped; reported with the ``value``."""
+    for offset, arg, from_param in _url_findings(pass_):
+        if not from_param:
+            _report_url(pass_, offset, arg)
+
+
+def detect_sprintf_url_param(pass_: Pass) -> None:
+    """Detect unescaped query values that come from a parameter."""
+    for offset, arg, from_param in _url_findings(pass_):
+        if from_param:
+            _report_url(pass_, offset, arg)
+
+
+def detect_sprintf_duration(pass_: Pass) -> None:
+    """Detect durations formatted only to be parsed back with ``time.ParseDuration``.
+
+    Reported with a ``suggestion``.
+    """
+    source = pass_.file
+    masked = source.masked
+    for offset, close, fmt, args in _sprintf_calls(source):
+        m = _DURATION_FORMAT_RE.match(fmt)
+        if m is None or len(args) != 1:
+            continue
+        before = masked[max(0, offset - 80) : offset]
+        parsed = _PARSE_DURATION_RE.search(before) is not None
+        if not parsed:
+            bound = re.search(r"(?<![\w.])(\w+)[ \t]*:?=[ \t]*$", before)
+            following = masked[close : close + 400]
+            parsed = bound is not None and re.search(
+                rf"(?<![\w.])time\.ParseDuration\([ \t]*{bound.group(1)}[ \t]*\)", following
+            ) is not None
+        if not parsed:
+            continue
+        value = args[0]
+        if m.group(1) != "%d":
+            value = f"{value} * float64(time.{_UNIT_NAMES[m.group(2)]})"
+            suggestion = f"time.Duration({value})"
+        else:
+            suggestion = f"time.Duration({value}) * time.{_UNIT_NAMES[m.group(2)]}"
+        pass_.report(
+            source.line_at(offset),
+            suggestion=suggestion,
+            hint=f"use {suggestion}: no string round trip, and no error to handle",
+        )
+
+
+__all__ = [
+    "detect_sprintf_duration",
+    "detect_sprintf_path",
+    "detect_sprintf_url",
+    "detect_sprintf_url_param",
+]
@@ -0,0 +1,134 @@
+"""Time layouts written in another language's notation.
+
+Go spells a layout with its reference time, ``Mon Jan 2 15:04:05 MST
+2006``: ``"2006-01-02"``, not ``"YYYY-MM-DD"``. Anything else in a layout
+is literal text, so ``time.Parse("YYYY-MM-DD", s)`` fails on every input
+and ``t.Format("YYYY-MM-DD")`` returns ``"YYYY-MM-DD"``. ``time_layout``
+reports the layouts of ``time.Parse`` and ``time.ParseInLocation``, and
+of ``.Format`` and ``.AppendFormat`` in files that import ``time``, that
+contain Java/.NET tokens (``YYYY``, ``MM``, ``dd``, ``HH``, ``mm``,
+``ss``, ``SSS``) or strftime verbs (``%Y``, ``%m``); a ``Format`` called
+on another package, such as ``strftime.Format``, takes its own notation.
+A layout given as a constant declared in the file is followed to its
+value.
+"""
+
+from __future__ import annotations
+
+import re
+
+from desloppify.languages.go.detectors._inspector import GoFile, Pass
+from desloppify.languages.go.detectors.custom_rules import _imports
+
+# Foreign tokens and their Go reference components, longest first.
+FOREIGN_TOKENS = {
+    "YYYY": "2006",
+    "yyyy": "2006",
+    "YY": "06",
+    "yy": "06",
+    "MMMM": "January",
+    "MMM": "Jan",
+    "MM": "01",
+    "DD": "02",
+    "dd": "02",
+    "HH": "15",
+    "hh": "03",
+    "mm": "04",
+    "ss": "05",
+    "SSS": "000",
+    "%Y": "2006",
+    "%y": "06",
+    "%m": "01",
+    "%d": "02",
+    "%H": "15",
+    "%I": "03",
+    "%M": "04",
+    "%S": "05",
+    "%p": "PM",
+    "%b": "Jan",
+    "%a": "Mon",
+}
+
+_TOKEN_RE = re.compile(
+    r"(?<![A-Za-z%])(?:"
+    + "|".join(re.escape(t) for t in FOREIGN_TOKENS if not t.startswith("%"))
+    + r")(?![A-Za-z])|%[YymdHIMSpba]"
+)
+_PARSE_RE = re.compile(r"(?<![\w.])time\.(?:Parse|ParseInLocation)\([ \t]*")
+_FORMAT_RE = re.compile(r"\.(?:Format|AppendFormat)\([ \t]*")
+_STRING_RE = re.compile(r'"((?:[^"\\\n]|\\.)*)"|`([^`]*)`')
+_CONST_RE = r"(?<![\w.]){name}[ \t]*(?:string[ \t]*)?=[ \t]*(\"(?:[^\"\\\n]|\\.)*\"|`[^`]*`)"
+
+
+def _layout_argument(source: GoFile, start: int, skip: int) -> tuple[str, str] | None:
+    """(argument as written, layout value) of the literal or constant at ``start``.
+
+    ``skip`` is how many arguments come before the layout (``AppendFormat``'s buffer).
+    """
+    content = source.content
+    for _ in range(skip):
+        comma = source.masked.find(",", start)
+        if comma == -1:
+            return None
+        start = comma + 1
+        start += len(content[start:]) - len(content[start:].lstrip(" \t"))
+    m = _STRING_RE.match(content, start)
+    if m is not None:
+        return m.group(), m.group(1) if m.group(1) is not None else m.group(2)
+    name = re.match(r"\w+", content[start : start + 80])
+    if name is None:
+        return None
+    const = re.search(_CONST_RE.format(name=re.escape(name.group())), source.masked)
+    if const is None:
+        return None
+    literal = content[const.start(1) : const.end(1)]
+    return name.group(), literal[1:-1]
+
+
+def _receiver(masked: str, dot: int) -> str:
+    m = re.search(r"(?<![\w.])(\w+)$", masked[max(0, dot - 80) : dot])
+    return m.group(1) if m is not None else ""
+
+
+def _suggestion(layout: str) -> str:
+    return _TOKEN_RE.sub(lambda m: FOREIGN_TOKENS[m.group()], layout)
+
+
+def detect_time_layout(pass_: Pass) -> None:
+    """Detect time layouts spelled with foreign tokens.
+
+    Reported with the ``layout``, the foreign ``tokens`` and the Go
+    layout they stand for (``suggestion``).
+    """
+    source = pass_.file
+    masked = source.masked
+    calls = [(m, 0) for m in _PARSE_RE.finditer(masked)]
+    imports = _imports(source)
+    if any(path == "time" for _, path, _ in imports):
+        # strftime.Format("%Y", t) and other packages' Format take their own notation.
+        packages = {name or path.rsplit("/", 1)[-1] for name, path, _ in imports}
+        calls.extend(
+            (m, 1 if m.group().startswith(".AppendFormat") else 0)
+            for m in _FORMAT_RE.finditer(masked)
+            if _receiver(masked, m.start()) not in packages
+        )
+    for m, skip in sorted(calls, key=lambda call: call[0].start()):
+        argument = _layout_argument(source, m.end(), skip)
+        if argument is None:
+            continue
+        written, layout = argument
+        tokens = sorted(set(_TOKEN_RE.findall(layout)), key=layout.index)
+        if not tokens:
+            continue
+        suggestion = _suggestion(layout)
+        pass_.report(
+            source.line_at(m.start()),
+            layout=layout,
+            tokens=tokens,
+            suggestion=suggestion,
+            hint=f"Go layouts use the reference time, so {written} is read as literal "
+            f'text; write "{suggestion}"',
+        )
+
+
+__all__ = ["FOREIGN_TOKENS", "detect_time_layout"]
@@ -0,0 +1,218 @@
+"""``time.Time`` values compared or written as if they were plain values.
+
+A ``time.Time`` carries a location and, when it came from ``time.Now``,
+a monotonic clock reading, and ``==`` compares both: the same instant in
+UTC and in local time, or before and after a round trip through a
+database, is not ``==``. ``time_equal`` reports ``==`` and ``!=`` between
+times, with ``a.Equal(b)`` (or ``a.IsZero()`` for ``time.Time{}``) as the
+suggestion. An operand is a time when ``go/types`` says so; without types,
+when it is declared ``time.Time`` (a parameter, variable or struct field),
+assigned from ``time.Now``, ``time.Date``, ``time.Unix`` or
+``time.Parse``, or is such a call, or ends in ``.UTC()``, ``.Local()``
+or ``.AddDate(...)``.
+
+``time_not_utc`` (opt-in) reports ``time.Now()`` written out in the
+server's local time: formatted (``.Format``, ``.AppendFormat``,
+``.String()``), or passed to an SQL call (``Exec``, ``Query``,
+``QueryRow``) or a log call, directly or through a variable assigned
+from it. The value then depends on the machine's time zone; ``.UTC()``
+or ``.In(loc)`` right after ``time.Now()`` says which zone is meant.
+"""
+
+from __future__ import annotations
+
+import re
+
+from desloppify.languages.go.detectors._inspector import GoFile, Pass
+from desloppify.languages.go.detectors._source import matching_brace
+from desloppify.languages.go.detectors.custom_rules import _imports
+from desloppify.languages.go.detectors.durations import _operand_after, _operand_before
+from desloppify.languages.go.detectors.error_flow import _LOG_CALL_RE
+
+_COMPARISON_RE = re.compile(r"(?<![<>:=!])(==|!=)(?!=)")
+_CALL_ARGS = r"\((?:[^()]|\([^()]*\))*\)"
+_TIME_EXPR_RE = re.compile(
+    rf"(?:time\.(?:Now|Date|Unix|UnixMilli|UnixMicro){_CALL_ARGS}"
+    rf"|[\w.()]*?\.(?:UTC|Local|AddDate){_CALL_ARGS}|time\.Time\{{\}})"
+)
+_TIME_DECL_RE = re.compile(r"((?:\w+[ \t]*,[ \t]*)*\w+)[ \t]+time\.Time\b")
+_TIME_ASSIGN_RE = re.compile(
+    r"(?:^|;)[ \t]*(?:var[ \t]+)?(\w+)(?:[ \t]*,[ \t]*[\w.]+)*[ \t]*:?=(?!=)[ \t]*"
+    r"time\.(?:Now|Date|Unix|UnixMilli|UnixMicro|Parse|ParseInLocation)\(",
+    re.MULTILINE,
+)
+_NOW_RE = re.compile(r"(?<![\w.])time\.Now\(\)")
+_ZONED_RE = re.compile(r"\.(?:UTC|In)\(")
+_FORMATTED_RE = re.compile(r"\.(?:Format|AppendFormat)\(|\.String\(\)")
+_SQL_CALL_RE = re.compile(r"\.(?:Exec|Query|QueryRow)(?:Context)?$")
+_NOW_BOUND_RE = re.compile(r"(?<![\w.])(\w+)[ \t]*:?=[ \t]*$")
+_STATEMENT_END_RE = re.compile(r"[ \t]*(?:[\n;]|$)")
+
+
+def _packages(source: GoFile) -> set[str]:
+    return source.memo(
+        "time_values:packages",
+        lambda: {name or path.rsplit("/", 1)[-1] for name, path, _ in _imports(source)},
+    )
+
+
+def _time_fields(files: tuple[GoFile, ...]) -> set[str]:
+    """Names declared ``time.Time`` at the start of a line: struct fields and specs."""
+    line_decl = re.compile(rf"^[ \t]*{_TIME_DECL_RE.pattern}", re.MULTILINE)
+    return {
+        name
+        for file in files
+        for m in line_decl.finditer(file.masked)
+        for name in re.findall(r"\w+", m.group(1))
+    }
+
+
+def _declared_time(source: GoFile, name: str, pos: int) -> bool:
+    """Whether ``name`` is declared a ``time.Time`` before ``pos`` in its function."""
+    masked = source.masked
+    enclosing = source.enclosing_blocks(pos)
+    if not enclosing:
+        return False
+    region = masked[max(masked.rfind("func", 0, enclosing[-1]), 0) : pos]
+    if any(name in re.findall(r"\w+", m.group(1)) for m in _TIME_DECL_RE.finditer(region)):
+        return True
+    return any(m.group(1) == name for m in _TIME_ASSIGN_RE.finditer(region))
+
+
+def _is_time(pass_: Pass, start: int, end: int) -> bool:
+    source = pass_.file
+    text = source.masked[start:end]
+    if not text:
+        return False
+    type_ = pass_.type_of(start, end)
+    if type_ is not None:
+        return type_ == "time.Time"
+    if _TIME_EXPR_RE.fullmatch(text):
+        # gover.Local() is not a time; a chain starting at another package may not be.
+        root = re.match(r"\w+", text)
+        return root is None or root.group() == "time" or root.group() not in _packages(source)
+    head, _, field = text.rpartition(".")
+    if not head:
+        return re.fullmatch(r"\w+", text) is not None and _declared_time(source, text, start)
+    if not re.fullmatch(r"\w+(?:\.\w+)*", head) or not re.fullmatch(r"\w+", field):
+        return False
+    files = pass_.types.files if pass_.types is not None else (source,)
+    fields = (
+        pass_.types.memo("time_values:fields", lambda: _time_fields(files))
+        if pass_.types is not None
+        else _time_fields(files)
+    )
+    return field in fields
+
+
+def detect_time_equal(pass_: Pass) -> None:
+    """Detect ``==`` and ``!=`` between ``time.Time`` values.
+
+    Reported with the ``left`` and ``right`` operands and a ``suggestion``.
+    """
+    source = pass_.file
+    masked, content = source.masked, source.content
+    for m in _COMPARISON_RE.finditer(masked):
+        left_end = len(masked[: m.start()].rstrip())
+        left_start = _operand_before(masked, left_end)
+        right_start = m.end() + len(masked[m.end() :]) - len(masked[m.end() :].lstrip(" \t"))
+        right_end = _operand_after(masked, right_start)
+        if not (_is_time(pass_, left_start, left_end) or _is_time(pass_, right_start, right_end)):
+            continue
+        left, right = content[left_start:left_end], content[right_start:right_end]
+        if not left or not right:
+            continue
+        negate = "!" if m.group(1) == "!=" else ""
+        if right == "time.Time{}":
+            suggestion = f"{negate}{left}.IsZero()"
+        elif left == "time.Time{}":
+            suggestion = f"{negate}{right}.IsZero()"
+        else:
+            suggestion = f"{negate}{left}.Equal({right})"
+        pass_.report(
+            source.line_at(m.start()),
+            left=left,
+            right=right,
+            suggestion=suggestion,
+            hint=f"== also compares the location and monotonic clock, so equal instants "
+            f"can differ; use {suggestion}",
+        )
+
+
+def _callee(masked: str, start: int, end: int) -> str | None:
+    """The function the expression at ``start``..``end`` is a whole argument of."""
+    if not re.match(r"[ \t\n]*[,)]", masked[end:]):
+        return None
+    depth = 0
+    i = start - 1
+    while i >= 0:
+        ch = masked[i]
+        if ch in ")]}":
+            depth += 1
+        elif ch in "([{":
+            if depth == 0:
+                break
+            depth -= 1
+        elif ch == ";" or ch == "\n" and depth == 0 and masked[i - 1 : i] not in (",", "("):
+            return None
+        i -= 1
+    if i < 0 or masked[i] != "(":
+        return None
+    name = re.search(r"[\w.]+$", masked[:i])
+    return name.group() if name is not None else None
+
+
+def _local_use(masked: str, start: int, end: int) -> str | None:
+    """How the local time at ``start``..``end`` is written out: ``format``, ``sql`` or ``log``."""
+    chain = re.match(r"(?:\.Local\(\))?", masked[end:])
+    end += chain.end()
+    if _ZONED_RE.match(masked, end):
+        return None
+    if _FORMATTED_RE.match(masked, end):
+        return "format"
+    callee = _callee(masked, start, end)
+    if callee is None:
+        return None
+    if _SQL_CALL_RE.search(callee):
+        return "sql"
+    if _LOG_CALL_RE.fullmatch(callee + "("):
+        return "log"
+    return None
+
+
+def _report_local(pass_: Pass, pos: int, expr: str, use: str) -> None:
+    pass_.report(
+        pass_.file.line_at(pos),
+        use=use,
+        hint=f"{expr} is in the server's local time zone, so what is written depends on "
+        "where it runs; use time.Now().UTC()",
+    )
+
+
+def detect_time_not_utc(pass_: Pass) -> None:
+    """Detect ``time.Now()`` formatted, stored or logged in local time.
+
+    Reported with the ``use`` (``format``, ``sql`` or ``log``).
+    """
+    source = pass_.file
+    masked = source.masked
+    for m in _NOW_RE.finditer(masked):
+        use = _local_use(masked, m.start(), m.end())
+        if use is not None:
+            _report_local(pass_, m.start(), "time.Now()", use)
+            continue
+        bound = _NOW_BOUND_RE.search(masked, source.line_start(m.start()), m.start())
+        enclosing = source.enclosing_blocks(m.start())
+        if bound is None or not enclosing or not _STATEMENT_END_RE.match(masked, m.end()):
+            continue
+        name = bound.group(1)
+        scope_end = matching_brace(masked, enclosing[0]) or len(masked)
+        ref_re = re.compile(rf"(?<![\w.]){re.escape(name)}\b(?![ \t]*:?=[^=])")
+        for ref in ref_re.finditer(masked, m.end(), scope_end):
+            start = ref.start()
+            use = _local_use(masked, start, start + len(name))
+            if use is not None:
+                _report_local(pass_, start, name, use)
+
+
+__all__ = ["detect_time_equal", "detect_time_not_utc"]
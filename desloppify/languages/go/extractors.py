@@ -10,9 +10,20 @@ import re
 from pathlib import Path
 
 from desloppify.engine.detectors.base import FunctionInfo
-from desloppify.file_discovery import find_source_files, resolve_path
+from desloppify.core.runtime_state import current_runtime_context
+from desloppify.file_discovery import (
+    find_source_files,
+    get_build_tags,
+    get_vendored_paths,
+    is_vendored_included,
+    matches_exclusion,
+    read_file_text,
+    resolve_path,
+)
+from desloppify.languages.go.build_constraints import BuildContext
 
-GO_FILE_EXCLUSIONS = ["vendor", "testdata", ".git", "node_modules"]
+VENDOR_DIR = "vendor"
+GO_FILE_EXCLUSIONS = [VENDOR_DIR, "testdata", ".git", "node_modules"]
 
 _FUNC_DECL_RE = re.compile(
     r"(?m)^func\s+"
@@ -23,9 +34,49 @@ _FUNC_DECL_RE = re.compile(
 )
 
 
-def find_go_files(path: Path | str) -> list[str]:
-    """Find Go source files under path."""
-    return find_source_files(path, [".go"], exclusions=GO_FILE_EXCLUSIONS)
+def is_vendored(filepath: str) -> bool:
+    """Whether ``filepath`` is under ``vendor/`` or a configured ``third_party_paths`` dir."""
+    return matches_exclusion(filepath, VENDOR_DIR) or any(
+        matches_exclusion(filepath, p) for p in get_vendored_paths()
+    )
+
+
+def find_go_files(path: Path | str, *, vendored: bool = False) -> list[str]:
+    """Find Go source files under path that the active build constraints include.
+
+    Vendored code (``is_vendored``) is left out, as the Go toolchain does,
+    unless ``vendored`` asks for it (graph builders that need those packages
+    as nodes) or the scan runs with ``--include-vendor``.
+    """
+    if vendored or is_vendored_included():
+        exclusions = [e for e in GO_FILE_EXCLUSIONS if e != VENDOR_DIR]
+        files = tuple(find_source_files(path, [".go"], exclusions=exclusions))
+    else:
+        files = tuple(
+            f
+            for f in find_source_files(path, [".go"], exclusions=GO_FILE_EXCLUSIONS)
+            if not is_vendored(f)
+        )
+    context = BuildContext.from_environment(get_build_tags())
+    cache_key = ("go_build", context, files)
+    cache = current_runtime_context().source_file_cache
+    included = cache.get(cache_key)
+    if included is None:
+        included = tuple(
+            f
+            for f in files
+            if context.matches_filename(f)
+            and context.includes(f, read_file_text(resolve_path(f)) or "")
+        )
+        cache.put(cache_key, included)
+    return list(included)
+
+
+def find_vendored_go_files(path: Path | str) -> list[str]:
+    """Vendored Go files under path that a scan leaves out (none with ``--include-vendor``)."""
+    if is_vendored_included():
+        return []
+    return [f for f in find_go_files(path, vendored=True) if is_vendored(f)]
 
 
 def _find_matching_brace(content: str, open_pos: int) -> int | None:
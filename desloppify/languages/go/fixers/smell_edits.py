@@ -0,0 +1,157 @@
+"""Apply the machine fixes Go smells attach to their matches.
+
+A match's ``fix`` is ``{"title", "line", "old", "new"}``, the same edit the
+LSP quick fix offers; ``old`` starts on ``line`` and may span several lines.
+Every rule's edits for a file are applied together; each pass re-runs
+detection on the edited text, so a fix that overlapped another one is
+retried once the other has landed. An edit that would drop or alter a
+comment is never applied. The result goes through ``gofmt`` before it is
+written (only as a parse check when the file was not gofmt-clean to begin
+with, so unrelated lines keep their bytes), and a file ``gofmt`` rejects is
+left as it was.
+"""
+
+from __future__ import annotations
+
+import subprocess
+from collections import Counter
+from pathlib import Path
+
+from desloppify.file_discovery import rel
+from desloppify.languages._framework.base.types import FixResult
+from desloppify.languages._framework.fixers import Edit, apply_edit_fixer, line_edit
+from desloppify.languages.go.detectors._source import go_comments
+from desloppify.languages.go.detectors.smells import SMELL_CHECKS, smells_in_source
+from desloppify.languages.go.extractors import find_go_files
+
+FIXABLE_RULES = frozenset(s["id"] for s in SMELL_CHECKS if s["fixable"])
+_GOFMT_TIMEOUT = 30
+
+
+def _fixable(matches: dict[str, list[dict]], rules: frozenset[str] | None):
+    for smell_id, found in matches.items():
+        if smell_id not in FIXABLE_RULES or (rules is not None and smell_id not in rules):
+            continue
+        for match in found:
+            if isinstance(match.get("fix"), dict):
+                yield smell_id, match
+
+
+def detect_fixable(path: Path, rules: frozenset[str] | None = None) -> list[dict]:
+    """Every match with a machine fix under ``path``, as fixer entries."""
+    entries: list[dict] = []
+    for filepath in find_go_files(path):
+        if filepath.endswith("_test.go"):
+            continue
+        try:
+            content = Path(filepath).read_text()
+        except (OSError, UnicodeDecodeError):
+            continue
+        for smell_id, match in _fixable(smells_in_source(filepath, content), rules):
+            entries.append({**match, "smell": smell_id, "name": smell_id})
+    return entries
+
+
+def gofmt_source(content: str) -> str | None:
+    """``content`` as gofmt prints it; None if it does not parse.
+
+    Without a gofmt binary the content comes back unchanged.
+    """
+    try:
+        result = subprocess.run(
+            ["gofmt"],
+            input=content,
+            capture_output=True,
+            text=True,
+            timeout=_GOFMT_TIMEOUT,
+        )
+    except (FileNotFoundError, OSError, subprocess.TimeoutExpired):
+        return content
+    return result.stdout if result.returncode == 0 else None
+
+
+def _keeps_comments(content: str, edit: Edit) -> bool:
+    """Whether applying ``edit`` leaves every comment in ``content`` intact."""
+    before = go_comments(content[edit.start : edit.end])
+    if not before:
+        return True
+    return Counter(go_comments(edit.new)) == Counter(before)
+
+
+def fix_smells(
+    entries: list[dict],
+    *,
+    dry_run: bool = False,
+    rules: frozenset[str] | None = None,
+) -> FixResult:
+    """Apply the fixes of the rules ``entries`` were detected for, in their files.
+
+    ``rules`` narrows that further (all fixable smells when None), so
+    filtering ``entries`` by rule, as ``fix --fix-rule`` does, also limits
+    which rules the re-analysis passes may fix.
+    """
+    selected = frozenset(e["smell"] for e in entries if "smell" in e)
+    if rules is not None:
+        selected &= rules
+
+    def find_edits(filepath: str, content: str) -> list[Edit]:
+        edits = []
+        for smell_id, match in _fixable(smells_in_source(filepath, content), selected):
+            fix = match["fix"]
+            edit = line_edit(
+                content, int(fix["line"]), str(fix["old"]), str(fix["new"]), smell_id
+            )
+            if edit is not None and _keeps_comments(content, edit):
+                edits.append(edit)
+        return edits
+
+    return apply_edit_fixer(
+        entries, find_edits, dry_run=dry_run, lang_label="Go", format_fn=gofmt_source
+    )
+
+
+def resolve_smell_fixes(state: dict, results: list[dict], fixer_name: str) -> list[str]:
+    """Record applied fixes on the aggregated ``go_smell::<id>`` findings.
+
+    One finding covers every match of a smell, so a fix lowers its count
+    and is listed under ``detail["fixed"]``; the finding itself turns
+    ``fixed`` once no match is left. Returns the ids that turned fixed.
+    """
+    by_rule: dict[str, Counter[str]] = {}
+    for result in results:
+        for rule in result["removed"]:
+            by_rule.setdefault(rule, Counter())[rel(result["file"])] += 1
+    resolved: list[str] = []
+    for finding_id, finding in state["findings"].items():
+        rule = finding.get("detail", {}).get("smell_id")
+        if (
+            finding.get("detector") != "smells"
+            or finding.get("status") != "open"
+            or not finding_id.endswith(f"::go_smell::{rule}")
+            or rule not in by_rule
+        ):
+            continue
+        detail = finding["detail"]
+        fixed_files = by_rule[rule]
+        detail["count"] = max(0, int(detail.get("count", 0)) - sum(fixed_files.values()))
+        detail["matches"] = [
+            m for m in detail.get("matches", []) if rel(m.get("file", "")) not in fixed_files
+        ]
+        detail.setdefault("fixed", []).extend(
+            {"file": file, "count": count, "by": f"desloppify fix {fixer_name}"}
+            for file, count in sorted(fixed_files.items())
+        )
+        if detail["count"] == 0:
+            finding["status"] = "fixed"
+            finding["note"] = f"auto-fixed by desloppify fix {fixer_name}"
+            resolved.append(finding_id)
+    return resolved
+
+
+__all__ = [
+    "FIXABLE_RULES",
+    "detect_fixable",
+    "fix_smells",
+    "gofmt_source",
+    "resolve_smell_fixes",
+]
@@ -0,0 +1,166 @@
+"""Which Go files parse and which packages build, for partial analysis.
+
+A file ``gofmt -e`` cannot parse still gets the text-level smells, but not
+the structural ones. A package that ``go list`` cannot load (a missing
+dependency, an import cycle) or that ``go vet`` cannot type-check is left
+out of vet and falls back to syntax-level smells. Each such unit is
+recorded on the ``LangRun`` with the toolchain's own error message. Without
+a Go toolchain nothing is classified, and the scan runs as before.
+"""
+
+from __future__ import annotations
+
+import json
+import re
+import subprocess
+from pathlib import Path
+
+from desloppify.file_discovery import rel
+
+# gofmt and go vet positions: "path/file.go:3:12: expected ')', found '{'".
+_POSITION_RE = re.compile(r"^(.+?\.go):(\d+)(?::(\d+))?:\s*(.+)$")
+_VET_PACKAGE_RE = re.compile(r"^# (\S+)$")
+_GOFMT_BATCH = 200
+_TIMEOUT = 120
+
+
+def _run(argv: list[str], cwd: Path) -> subprocess.CompletedProcess[str] | None:
+    try:
+        return subprocess.run(
+            argv, cwd=str(cwd), capture_output=True, text=True, timeout=_TIMEOUT
+        )
+    except (FileNotFoundError, OSError, subprocess.TimeoutExpired):
+        return None
+
+
+def parse_errors(files: list[str]) -> dict[str, list[dict]]:
+    """File -> every error ``gofmt -e`` reports, for files it cannot parse.
+
+    ``-e`` parses with ``parser.AllErrors``, so a file lists each error
+    (``line``, ``column``, ``message``) rather than just the first ten.
+    """
+    errors: dict[str, list[dict]] = {}
+    for start in range(0, len(files), _GOFMT_BATCH):
+        batch = files[start : start + _GOFMT_BATCH]
+        result = _run(["gofmt", "-e", "-l", *batch], Path.cwd())
+        if result is None:
+            return {}
+        for line in result.stderr.splitlines():
+            match = _POSITION_RE.match(line)
+            if match:
+                errors.setdefault(match.group(1), []).append(
+                    {
+                        "line": int(match.group(2)),
+                        "column": int(match.group(3) or 0),
+                        "message": match.group(4).strip(),
+                    }
+                )
+    return errors
+
+
+def describe_parse_error(filepath: str, error: dict) -> str:
+    """``gofmt``'s own ``file:line:column: message`` form of one parse error."""
+    return f"{filepath}:{error['line']}:{error['column']}: {error['message']}"
+
+
+def syntax_errors(files: list[str]) -> dict[str, str]:
+    """File -> first parse error, for files ``gofmt -e`` rejects."""
+    return {
+        filepath: describe_parse_error(filepath, errors[0])
+        for filepath, errors in parse_errors(files).items()
+    }
+
+
+def _decode_stream(text: str) -> list[dict]:
+    """Parse the concatenated JSON objects ``go list -json`` prints."""
+    decoder = json.JSONDecoder()
+    objects: list[dict] = []
+    index = 0
+    while index < len(text):
+        while index < len(text) and text[index].isspace():
+            index += 1
+        if index >= len(text):
+            break
+        try:
+            value, index = decoder.raw_decode(text, index)
+        except json.JSONDecodeError:
+            break
+        if isinstance(value, dict):
+            objects.append(value)
+    return objects
+
+
+def load_errors(
+    path: Path, tags: list[str] | None = None
+) -> tuple[dict[str, str], dict[str, str]] | None:
+    """(loadable package dir -> import path, broken package dir -> error).
+
+    Dirs are project-relative; ``tags`` are extra go flags (``-tags x,y``).
+    None when ``go list`` cannot run at all.
+    """
+    result = _run(
+        ["go", "list", "-e", "-json=Dir,ImportPath,Error,DepsErrors", *(tags or []), "./..."],
+        path,
+    )
+    if result is None or not result.stdout.strip():
+        return None
+    healthy: dict[str, str] = {}
+    broken: dict[str, str] = {}
+    for package in _decode_stream(result.stdout):
+        directory = rel(package.get("Dir", ""))
+        problem = package.get("Error") or next(iter(package.get("DepsErrors") or []), None)
+        if problem:
+            broken[directory] = str(problem.get("Err", "")).strip() or "package does not load"
+        else:
+            healthy[directory] = str(package.get("ImportPath", ""))
+    return healthy, broken
+
+
+def split_vet_output(output: str) -> tuple[list[dict], dict[str, str]]:
+    """(vet diagnostics as gnu entries, import path -> type-check error).
+
+    ``go vet`` reports a package it cannot type-check as ``# <import path>``
+    followed by ``vet: <position>: <error>``; those are not vet findings.
+    """
+    entries: list[dict] = []
+    type_errors: dict[str, str] = {}
+    package = ""
+    for line in output.splitlines():
+        header = _VET_PACKAGE_RE.match(line)
+        if header:
+            package = header.group(1)
+            continue
+        if line.startswith("vet: "):
+            if package and package not in type_errors:
+                type_errors[package] = line[len("vet: ") :].strip()
+            continue
+        match = _POSITION_RE.match(line)
+        if match:
+            entries.append(
+                {
+                    "file": match.group(1).strip(),
+                    "line": int(match.group(2)),
+                    "message": match.group(4).strip(),
+                }
+            )
+    return entries, type_errors
+
+
+def vet_packages(
+    path: Path, import_paths: list[str], tags: list[str] | None = None
+) -> str | None:
+    """Combined ``go vet`` output for ``import_paths``; None if vet cannot run."""
+    result = _run(["go", "vet", *(tags or []), *import_paths], path)
+    if result is None:
+        return None
+    return (result.stdout or "") + (result.stderr or "")
+
+
+__all__ = [
+    "describe_parse_error",
+    "load_errors",
+    "parse_errors",
+    "split_vet_output",
+    "syntax_errors",
+    "vet_packages",
+]
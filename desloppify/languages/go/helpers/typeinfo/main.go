@@ -0,0 +1,211 @@
+// Command typeinfo type-checks one Go package with go/types and prints the
+// type of every expression and declared name as JSON, for desloppify's
+// type-aware rules.
+//
+// Usage:
+//
+//	typeinfo FILE...
+//
+// The files must form a single package. Output:
+//
+//	{"files": {"FILE": [[start, end, "type"], ...]},
+//	 "implements": {"FILE": [[start, end, "interface"], ...]},
+//	 "errors": ["..."]}
+//
+// Offsets are byte offsets into each file, sorted by start then end. Types
+// from the checked package are unqualified; others carry their import path.
+// The braces of a struct literal, from "{" to "}", span its underlying
+// struct type, so rules can see field names of structs from other packages.
+// "implements" spans the name of each method that an interface from another
+// package requires of the receiver's type (the interfaces of the packages
+// imported, directly or not, and any other interface type the package uses).
+// Parse and type errors are reported but do not stop the check, so whatever
+// go/types could still infer is printed.
+package main
+
+import (
+	"encoding/json"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"sort"
+)
+
+type span struct {
+	start, end int
+	typ        string
+}
+
+func (s span) MarshalJSON() ([]byte, error) {
+	return json.Marshal([]any{s.start, s.end, s.typ})
+}
+
+type output struct {
+	Files      map[string][]span `json:"files"`
+	Implements map[string][]span `json:"implements"`
+	Errors     []string          `json:"errors"`
+}
+
+func main() {
+	fset := token.NewFileSet()
+	out := output{
+		Files:      map[string][]span{},
+		Implements: map[string][]span{},
+		Errors:     []string{},
+	}
+	var files []*ast.File
+	for _, name := range os.Args[1:] {
+		f, err := parser.ParseFile(fset, name, nil, parser.SkipObjectResolution)
+		if err != nil {
+			out.Errors = append(out.Errors, err.Error())
+			continue
+		}
+		files = append(files, f)
+		out.Files[name] = []span{}
+	}
+	info := &types.Info{
+		Types: map[ast.Expr]types.TypeAndValue{},
+		Defs:  map[*ast.Ident]types.Object{},
+	}
+	conf := types.Config{
+		Importer: importer.ForCompiler(fset, "source", nil),
+		Error:    func(err error) { out.Errors = append(out.Errors, err.Error()) },
+	}
+	var pkg *types.Package
+	if len(files) > 0 {
+		pkg, _ = conf.Check(files[0].Name.Name, fset, files, info)
+	}
+	qualifier := types.RelativeTo(pkg)
+	add := func(node ast.Node, t types.Type) {
+		if t == nil {
+			return
+		}
+		start, end := fset.Position(node.Pos()), fset.Position(node.End())
+		out.Files[start.Filename] = append(
+			out.Files[start.Filename],
+			span{start.Offset, end.Offset, types.TypeString(t, qualifier)},
+		)
+	}
+	for expr, tv := range info.Types {
+		add(expr, tv.Type)
+		lit, ok := expr.(*ast.CompositeLit)
+		if !ok || tv.Type == nil {
+			continue
+		}
+		if st, ok := tv.Type.Underlying().(*types.Struct); ok {
+			start, end := fset.Position(lit.Lbrace), fset.Position(lit.Rbrace)
+			out.Files[start.Filename] = append(
+				out.Files[start.Filename],
+				span{start.Offset, end.Offset + 1, types.TypeString(st, qualifier)},
+			)
+		}
+	}
+	for ident, obj := range info.Defs {
+		if obj != nil {
+			add(ident, obj.Type())
+		}
+	}
+	if pkg != nil {
+		implements(fset, files, info, pkg, qualifier, out.Implements)
+	}
+	for _, byFile := range []map[string][]span{out.Files, out.Implements} {
+		for _, spans := range byFile {
+			sort.Slice(spans, func(i, j int) bool {
+				if spans[i].start != spans[j].start {
+					return spans[i].start < spans[j].start
+				}
+				if spans[i].end != spans[j].end {
+					return spans[i].end < spans[j].end
+				}
+				return spans[i].typ < spans[j].typ
+			})
+		}
+	}
+	if err := json.NewEncoder(os.Stdout).Encode(out); err != nil {
+		os.Exit(1)
+	}
+}
+
+// implements records, for each method declared in files, the interfaces of
+// other packages that require it of its receiver's type.
+func implements(
+	fset *token.FileSet,
+	files []*ast.File,
+	info *types.Info,
+	pkg *types.Package,
+	qualifier types.Qualifier,
+	out map[string][]span,
+) {
+	byMethod := map[string][]*types.Named{}
+	seen := map[*types.Named]bool{}
+	addInterface := func(t types.Type) {
+		named, ok := t.(*types.Named)
+		if !ok || seen[named] || named.Obj().Pkg() == nil || named.Obj().Pkg() == pkg {
+			return
+		}
+		seen[named] = true
+		iface, ok := named.Underlying().(*types.Interface)
+		if !ok {
+			return
+		}
+		for i := 0; i < iface.NumMethods(); i++ {
+			name := iface.Method(i).Name()
+			byMethod[name] = append(byMethod[name], named)
+		}
+	}
+	visited := map[*types.Package]bool{}
+	var walk func(p *types.Package)
+	walk = func(p *types.Package) {
+		if visited[p] {
+			return
+		}
+		visited[p] = true
+		scope := p.Scope()
+		for _, name := range scope.Names() {
+			if tn, ok := scope.Lookup(name).(*types.TypeName); ok && tn.Exported() {
+				addInterface(tn.Type())
+			}
+		}
+		for _, imported := range p.Imports() {
+			walk(imported)
+		}
+	}
+	for _, imported := range pkg.Imports() {
+		walk(imported)
+	}
+	for _, tv := range info.Types {
+		addInterface(tv.Type)
+	}
+	for _, f := range files {
+		for _, decl := range f.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Recv == nil {
+				continue
+			}
+			obj, ok := info.Defs[fn.Name].(*types.Func)
+			if !ok {
+				continue
+			}
+			recv := obj.Type().(*types.Signature).Recv().Type()
+			if ptr, ok := recv.(*types.Pointer); ok {
+				recv = ptr.Elem()
+			}
+			named, ok := recv.(*types.Named)
+			if !ok {
+				continue
+			}
+			for _, iface := range byMethod[fn.Name.Name] {
+				if types.Implements(types.NewPointer(named), iface.Underlying().(*types.Interface)) {
+					start, end := fset.Position(fn.Name.Pos()), fset.Position(fn.Name.End())
+					out[start.Filename] = append(
+						out[start.Filename],
+						span{start.Offset, end.Offset, types.TypeString(iface, qualifier)},
+					)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,232 @@
+// Command desloppify-vet lets go vet report desloppify's Go smells.
+//
+// Usage:
+//
+//	go vet -vettool=$(desloppify vettool) ./...
+//
+// It speaks the protocol go vet uses with unitchecker tools: "-V=full"
+// prints a version with a build ID, "-flags" lists no extra flags, and any
+// other run gets one JSON config file per package. For each package it runs
+//
+//	desloppify --lang go scan --stream --all-matches --skip-slow --profile objective ./DIR
+//
+// from the package's module root and prints the smells found in the
+// package's files as "file:line: label (rule)". Other desloppify findings
+// need the whole module and are left to desloppify scan. The DESLOPPIFY
+// environment variable overrides the command (default "desloppify"); it is
+// split on spaces, so "python3 -m desloppify" works.
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// config is the part of go vet's per-package config the shim reads.
+type config struct {
+	Dir        string
+	ImportPath string
+	GoFiles    []string
+	VetxOnly   bool
+	VetxOutput string
+}
+
+type match struct {
+	File string `json:"file"`
+	Line int    `json:"line"`
+}
+
+type finding struct {
+	Detector string `json:"detector"`
+	File     string `json:"file"`
+	Summary  any    `json:"summary"`
+	Detail   struct {
+		SmellID string  `json:"smell_id"`
+		Line    int     `json:"line"`
+		Matches []match `json:"matches"`
+	} `json:"detail"`
+}
+
+type diagnostic struct {
+	file    string
+	line    int
+	message string
+}
+
+// Aggregated summaries end in "(N occurrences in M files)".
+var occurrencesRE = regexp.MustCompile(`\s*\(\d+ occurrences? in \d+ files?\)$`)
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("desloppify-vet: ")
+	args := os.Args[1:]
+	if len(args) == 1 && args[0] == "-V=full" {
+		fmt.Printf("desloppify-vet version devel buildID=%s\n", buildID())
+		return
+	}
+	if len(args) == 1 && args[0] == "-flags" {
+		fmt.Println("[]")
+		return
+	}
+	if len(args) == 0 || !strings.HasSuffix(args[len(args)-1], ".cfg") {
+		log.Fatal(`run as "go vet -vettool=$(desloppify vettool) ./..."`)
+	}
+	cfg, err := readConfig(args[len(args)-1])
+	if err != nil {
+		log.Fatal(err)
+	}
+	// go vet caches analysis facts per package; the shim has none to pass on.
+	if cfg.VetxOutput != "" {
+		if err := os.WriteFile(cfg.VetxOutput, nil, 0o666); err != nil {
+			log.Fatal(err)
+		}
+	}
+	if cfg.VetxOnly || len(cfg.GoFiles) == 0 {
+		return
+	}
+	diagnostics, err := analyze(cfg)
+	if err != nil {
+		log.Fatalf("%s: %v", cfg.ImportPath, err)
+	}
+	for _, d := range diagnostics {
+		if d.line > 0 {
+			fmt.Fprintf(os.Stderr, "%s:%d: %s\n", d.file, d.line, d.message)
+		} else {
+			fmt.Fprintf(os.Stderr, "%s: %s\n", d.file, d.message)
+		}
+	}
+	if len(diagnostics) > 0 {
+		os.Exit(1)
+	}
+}
+
+// buildID hashes the executable, so go vet's cache follows rebuilds.
+func buildID() string {
+	exe, err := os.Executable()
+	if err != nil {
+		return "unknown"
+	}
+	f, err := os.Open(exe)
+	if err != nil {
+		return "unknown"
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "unknown"
+	}
+	return fmt.Sprintf("%x", h.Sum(nil))
+}
+
+func readConfig(path string) (*config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	cfg := new(config)
+	if err := json.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("cannot decode vet config %s: %v", path, err)
+	}
+	return cfg, nil
+}
+
+// moduleRoot is the nearest directory at or above dir with a go.mod, else dir.
+func moduleRoot(dir string) string {
+	for current := dir; ; {
+		if _, err := os.Stat(filepath.Join(current, "go.mod")); err == nil {
+			return current
+		}
+		parent := filepath.Dir(current)
+		if parent == current {
+			return dir
+		}
+		current = parent
+	}
+}
+
+func command() []string {
+	if fields := strings.Fields(os.Getenv("DESLOPPIFY")); len(fields) > 0 {
+		return fields
+	}
+	return []string{"desloppify"}
+}
+
+// analyze runs desloppify on the package and keeps the smells in its files.
+func analyze(cfg *config) ([]diagnostic, error) {
+	root := moduleRoot(cfg.Dir)
+	rel, err := filepath.Rel(root, cfg.Dir)
+	if err != nil {
+		return nil, err
+	}
+	argv := append(command(), "--lang", "go", "scan", "--stream", "--all-matches",
+		"--skip-slow", "--profile", "objective", "./"+filepath.ToSlash(rel))
+	cmd := exec.Command(argv[0], argv[1:]...)
+	cmd.Dir = root
+	cmd.Env = append(os.Environ(), "DESLOPPIFY_ROOT="+root)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, runErr := cmd.Output()
+
+	files := map[string]bool{}
+	for _, name := range cfg.GoFiles {
+		if !filepath.IsAbs(name) {
+			name = filepath.Join(cfg.Dir, name)
+		}
+		files[filepath.Clean(name)] = true
+	}
+	var diagnostics []diagnostic
+	finished := false
+	for _, line := range bytes.Split(out, []byte("\n")) {
+		var f finding
+		if len(bytes.TrimSpace(line)) == 0 || json.Unmarshal(line, &f) != nil {
+			continue
+		}
+		if _, ok := f.Summary.(map[string]any); ok {
+			finished = true // the closing {"summary": {...}} line
+			continue
+		}
+		summary, _ := f.Summary.(string)
+		if f.Detector != "smells" || f.Detail.SmellID == "" {
+			continue
+		}
+		label := occurrencesRE.ReplaceAllString(summary, "")
+		message := fmt.Sprintf("%s (%s)", label, f.Detail.SmellID)
+		locations := f.Detail.Matches
+		if len(locations) == 0 {
+			locations = []match{{File: f.File, Line: f.Detail.Line}}
+		}
+		for _, m := range locations {
+			path := filepath.FromSlash(m.File)
+			if !filepath.IsAbs(path) {
+				path = filepath.Join(root, path)
+			}
+			if path = filepath.Clean(path); files[path] {
+				diagnostics = append(diagnostics, diagnostic{path, m.Line, message})
+			}
+		}
+	}
+	if !finished {
+		detail := strings.TrimSpace(stderr.String())
+		if runErr != nil && detail == "" {
+			detail = runErr.Error()
+		}
+		return nil, fmt.Errorf("%s did not finish: %s", strings.Join(argv, " "), detail)
+	}
+	sort.Slice(diagnostics, func(i, j int) bool {
+		if diagnostics[i].file != diagnostics[j].file {
+			return diagnostics[i].file < diagnostics[j].file
+		}
+		return diagnostics[i].line < diagnostics[j].line
+	})
+	return diagnostics, nil
+}
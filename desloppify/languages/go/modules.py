@@ -0,0 +1,256 @@
+"""Go modules under the scan path, for monorepos with several ``go.mod`` files.
+
+``go list``, ``go vet`` and ``go/types`` only see the module they run in, so
+a scan runs them once per module, from that module's directory: each module
+gets its own dependencies and ``go`` version. ``scan_units`` splits the scan
+path into those units; files belong to the innermost module that contains
+them. Paths stay relative to the project root, and every finding is stamped
+with its module's path (``module_of``).
+
+Any directory below the project root, a module's included, can layer
+settings over the root config with its own ``.desloppify/config.json``.
+Only the per-rule settings are taken from it (``OVERLAY_KEYS``). Overlays
+cascade: a file gets every overlay from the root down to its directory,
+nearest last. ``rule_options`` merges rule by rule and option by option, the
+others replace the value from further up.
+"""
+
+from __future__ import annotations
+
+import functools
+import json
+import os
+import re
+from dataclasses import dataclass
+from pathlib import Path
+from typing import Any
+
+from desloppify.core._internal.text_utils import get_project_root
+from desloppify.file_discovery import (
+    _find_source_files_cached,
+    get_exclusions,
+    is_vendored_included,
+    rel,
+    resolve_path,
+)
+from desloppify.languages.go.extractors import (
+    GO_FILE_EXCLUSIONS,
+    find_go_files,
+    is_vendored,
+)
+
+OVERLAY_FILE = Path(".desloppify") / "config.json"
+OVERLAY_KEYS = ("opt_in_smells", "custom_rules", "rule_options")
+
+_MODULE_RE = re.compile(r"^module[ \t]+\"?([^\s\"]+)", re.MULTILINE)
+
+
+@dataclass(frozen=True)
+class GoModule:
+    """One ``go.mod``: its directory (project-relative, ``.`` for the root) and path."""
+
+    dir: str
+    path: str
+
+    @property
+    def root(self) -> Path:
+        return Path(resolve_path(self.dir))
+
+    @property
+    def overlay(self) -> Path:
+        return self.root / OVERLAY_FILE
+
+
+@dataclass(frozen=True)
+class ScanUnit:
+    """Where one module-aware tool run happens and the Go files it covers."""
+
+    root: Path
+    module: GoModule | None
+    files: tuple[str, ...]
+
+
+@functools.lru_cache(maxsize=256)
+def _module_path(go_mod: str) -> str | None:
+    """The ``module`` directive of the go.mod at absolute path ``go_mod``."""
+    try:
+        match = _MODULE_RE.search(Path(go_mod).read_text(errors="replace"))
+    except OSError:
+        return None
+    return match.group(1) if match else None
+
+
+def _read_module(go_mod: str) -> GoModule:
+    directory = os.path.dirname(go_mod) or "."
+    return GoModule(directory, _module_path(resolve_path(go_mod)) or directory)
+
+
+def discover_modules(path: Path | str) -> list[GoModule]:
+    """Every module whose ``go.mod`` is under ``path``, skipping excluded and vendored dirs."""
+    found = _find_source_files_cached(
+        str(path), ("go.mod",), tuple(GO_FILE_EXCLUSIONS), get_exclusions()
+    )
+    return [
+        _read_module(f)
+        for f in found
+        if os.path.basename(f) == "go.mod" and (is_vendored_included() or not is_vendored(f))
+    ]
+
+
+@functools.lru_cache(maxsize=1024)
+def _enclosing_go_mod(directory: str, project_root: str) -> str | None:
+    current = Path(directory).resolve()
+    root = Path(project_root).resolve()
+    for candidate in (current, *current.parents):
+        if (candidate / "go.mod").is_file():
+            return str(candidate / "go.mod")
+        if candidate == root:
+            return None
+    return None
+
+
+def enclosing_module(directory: str) -> GoModule | None:
+    """The innermost module containing ``directory`` (project-relative or absolute)."""
+    go_mod = _enclosing_go_mod(resolve_path(directory or "."), str(get_project_root()))
+    return _read_module(rel(go_mod)) if go_mod else None
+
+
+def module_of(filepath: str) -> str | None:
+    """Module path stamped on findings for ``filepath``."""
+    module = enclosing_module(os.path.dirname(filepath)) if filepath else None
+    return module.path if module else None
+
+
+def _owner(directory: str, modules: list[GoModule]) -> GoModule | None:
+    """The innermost of ``modules`` containing ``directory``."""
+    for module in modules:
+        if module.dir in (".", directory) or directory.startswith(module.dir + "/"):
+            return module
+    return None
+
+
+def scan_units(path: Path | str) -> list[ScanUnit]:
+    """The scan path split into one unit per module, innermost module wins.
+
+    Files under ``path`` outside every discovered module (``path`` lies inside
+    a module, or there is no ``go.mod`` at all) form a unit rooted at ``path``
+    itself, which is how single-module scans ran before.
+    """
+    modules = sorted(discover_modules(path), key=lambda m: len(m.dir), reverse=True)
+    grouped: dict[GoModule | None, list[str]] = {}
+    for filepath in find_go_files(path):
+        owner = _owner(os.path.dirname(filepath) or ".", modules)
+        grouped.setdefault(owner, []).append(filepath)
+    units = []
+    if None in grouped or not modules:
+        root = Path(resolve_path(str(path)))
+        units.append(ScanUnit(root, enclosing_module(str(root)), tuple(grouped.get(None, ()))))
+    for module in sorted(modules, key=lambda m: m.dir):
+        units.append(ScanUnit(module.root, module, tuple(grouped.get(module, ()))))
+    return units
+
+
+def read_overlay(target: GoModule | str | None) -> dict[str, Any]:
+    """The ``languages.go`` settings a module's or directory's own config sets.
+
+    Only ``OVERLAY_KEYS`` are read; the project root's config is not an overlay.
+    """
+    directory = target.dir if isinstance(target, GoModule) else target
+    if directory in (None, "", "."):
+        return {}
+    try:
+        config = json.loads((Path(resolve_path(directory)) / OVERLAY_FILE).read_text())
+    except (OSError, UnicodeDecodeError, json.JSONDecodeError):
+        return {}
+    raw = ((config.get("languages") or {}).get("go") or {}) if isinstance(config, dict) else {}
+    return {k: raw[k] for k in OVERLAY_KEYS if isinstance(raw, dict) and k in raw}
+
+
+@functools.lru_cache(maxsize=1024)
+def _overlay_dirs(directory: str, project_root: str) -> tuple[str, ...]:
+    current = Path(directory).resolve()
+    root = Path(project_root).resolve()
+    if current != root and root not in current.parents:
+        return ()
+    found = []
+    for candidate in (current, *current.parents):
+        if candidate == root:
+            break
+        if (candidate / OVERLAY_FILE).is_file():
+            found.append(rel(str(candidate)))
+    return tuple(reversed(found))
+
+
+def overlay_chain(directory: str) -> tuple[str, ...]:
+    """Directories with an overlay from below the project root down to ``directory``."""
+    return _overlay_dirs(resolve_path(directory or "."), str(get_project_root()))
+
+
+def layered_settings(base: dict[str, Any], overlay: dict[str, Any]) -> dict[str, Any]:
+    """``base`` settings with an ``overlay`` on top (``rule_options`` merged per option)."""
+    merged = dict(base)
+    for key, value in overlay.items():
+        if key == "rule_options" and isinstance(value, dict):
+            options = dict(base.get(key) or {})
+            for rule_id, values in value.items():
+                below = options.get(rule_id)
+                both = isinstance(below, dict) and isinstance(values, dict)
+                options[rule_id] = {**below, **values} if both else values
+            merged[key] = options
+        else:
+            merged[key] = value
+    return merged
+
+
+def directory_settings(
+    base: dict[str, Any], chain: tuple[str, ...]
+) -> tuple[dict[str, Any], Path | None]:
+    """``base`` with the overlays of ``chain`` (see ``overlay_chain``) cascaded on top.
+
+    Also returns the overlay file the effective ``custom_rules`` came from
+    (None for the root config), for error messages.
+    """
+    settings, rules_source = base, None
+    for directory in chain:
+        overlay = read_overlay(directory)
+        settings = layered_settings(settings, overlay)
+        if "custom_rules" in overlay:
+            rules_source = Path(resolve_path(directory)) / OVERLAY_FILE
+    return settings, rules_source
+
+
+def resolve_modules(names: list[str], path: Path | str) -> tuple[list[str], list[str]]:
+    """(package dirs, Go files) of the modules ``names`` picks, for ``scan --module``.
+
+    A name is a module path or a module directory (project-relative).
+    Raises ValueError for a name that matches no module under ``path``.
+    """
+    units = [u for u in scan_units(path) if u.module is not None]
+    picked: list[ScanUnit] = []
+    for name in names:
+        wanted = name.strip().rstrip("/") or "."
+        matches = [u for u in units if wanted in (u.module.path, u.module.dir)]
+        if not matches:
+            known = ", ".join(sorted({u.module.path for u in units})) or "none"
+            raise ValueError(f"no module {name!r} under {path} (modules: {known})")
+        picked.extend(matches)
+    files = sorted({f for unit in picked for f in unit.files})
+    dirs = sorted({os.path.dirname(f) or "." for f in files})
+    return dirs, files
+
+
+__all__ = [
+    "OVERLAY_FILE",
+    "OVERLAY_KEYS",
+    "GoModule",
+    "ScanUnit",
+    "directory_settings",
+    "discover_modules",
+    "enclosing_module",
+    "layered_settings",
+    "module_of",
+    "overlay_chain",
+    "read_overlay",
+    "resolve_modules",
+    "scan_units",
+]
@@ -0,0 +1,160 @@
+"""Cache keys for Go packages.
+
+A package's key covers its own file paths and contents, the ``go.mod`` and
+``go.sum`` of its module, and the exported API of every package it imports
+from the same module. Editing a dependency's unexported code keeps the key;
+changing its exported declarations (or struct fields) produces a new one.
+The same import reading finds a package's dependents for changed-files scans.
+"""
+
+from __future__ import annotations
+
+import os
+import posixpath
+import re
+from pathlib import Path
+
+from desloppify.core.result_cache import content_hash
+from desloppify.languages.go.detectors._source import mask_go_source
+from desloppify.languages.go.extractors import find_go_files
+
+_MODULE_RE = re.compile(r"^module\s+(\S+)", re.MULTILINE)
+_IMPORT_SINGLE_RE = re.compile(r'^import\s+(?:[\w.]+\s+)?"([^"]+)"', re.MULTILINE)
+_IMPORT_BLOCK_RE = re.compile(r"^import\s*\((.*?)^\)", re.MULTILINE | re.DOTALL)
+_IMPORT_PATH_RE = re.compile(r'"([^"]+)"')
+# Top-level exported declarations, plus one-tab-indented exported names
+# (grouped declarations, struct fields, interface methods). The latter also
+# catches some statements in function bodies, which only over-invalidates.
+_EXPORTED_RE = re.compile(
+    r"^(?:func(?:[ \t]*\([^)]*\))?[ \t]+[A-Z]\w*.*"
+    r"|(?:type|var|const)[ \t]+[A-Z]\w*.*"
+    r"|\t[A-Z]\w*\b.*)$",
+    re.MULTILINE,
+)
+
+
+def _source_files(directory: str) -> list[str]:
+    try:
+        names = sorted(os.listdir(directory or "."))
+    except OSError:
+        return []
+    return [
+        posixpath.join(directory, name) if directory else name
+        for name in names
+        if name.endswith(".go") and not name.endswith("_test.go")
+    ]
+
+
+def _read(path: str) -> str:
+    try:
+        return Path(path).read_text(errors="replace")
+    except OSError:
+        return ""
+
+
+def _imports(content: str) -> set[str]:
+    found = set(_IMPORT_SINGLE_RE.findall(content))
+    for block in _IMPORT_BLOCK_RE.findall(content):
+        found.update(_IMPORT_PATH_RE.findall(block))
+    return found
+
+
+class GoPackageKeyer:
+    """Build cache-key parts for package directories (project-relative)."""
+
+    def __init__(self) -> None:
+        self._modules: dict[str, tuple[str, str, str] | None] = {}
+        self._api_hashes: dict[str, str] = {}
+
+    def _module(self, directory: str) -> tuple[str, str, str] | None:
+        """(module dir, module path, go.mod+go.sum hash) for ``directory``."""
+        if directory in self._modules:
+            return self._modules[directory]
+        go_mod = posixpath.join(directory, "go.mod") if directory else "go.mod"
+        if os.path.isfile(go_mod):
+            text = _read(go_mod)
+            match = _MODULE_RE.search(text)
+            go_sum = _read(posixpath.join(directory, "go.sum") if directory else "go.sum")
+            meaningful = "\n".join(
+                line for line in text.splitlines() if not line.lstrip().startswith("//")
+            )
+            found = (
+                (directory, match.group(1), content_hash(meaningful + "\0" + go_sum))
+                if match
+                else None
+            )
+        elif directory and posixpath.dirname(directory) != directory:
+            found = self._module(posixpath.dirname(directory))
+        else:
+            found = None
+        self._modules[directory] = found
+        return found
+
+    def api_hash(self, directory: str) -> str:
+        """Hash of the exported declarations of the package in ``directory``."""
+        if directory not in self._api_hashes:
+            lines: list[str] = []
+            for path in _source_files(directory):
+                masked = mask_go_source(_read(path))
+                lines.extend(m.group(0).rstrip(" \t{") for m in _EXPORTED_RE.finditer(masked))
+            self._api_hashes[directory] = content_hash("\n".join(sorted(lines)))
+        return self._api_hashes[directory]
+
+    def _module_deps(self, directory: str, imports: set[str]) -> list[tuple[str, str]]:
+        """(import path, package dir) of each of ``imports`` from ``directory``'s module."""
+        module = self._module(directory)
+        if module is None:
+            return []
+        module_dir, module_path, _module_hash = module
+        deps: list[tuple[str, str]] = []
+        for imported in sorted(imports):
+            if imported != module_path and not imported.startswith(module_path + "/"):
+                continue
+            suffix = imported[len(module_path) :].lstrip("/")
+            deps.append((imported, posixpath.normpath(posixpath.join(module_dir, suffix))))
+        return deps
+
+    def local_deps(self, directory: str, files: list[str]) -> set[str]:
+        """Dirs of the same-module packages that ``files`` import (``.`` = root)."""
+        imports: set[str] = set()
+        for path in files:
+            imports |= _imports(_read(path))
+        return {dep_dir for _imported, dep_dir in self._module_deps(directory, imports)}
+
+    def parts(self, directory: str, files: list[str]) -> list[str]:
+        """Strings that together identify ``directory``'s analysis inputs."""
+        parts: list[str] = []
+        imports: set[str] = set()
+        for path in sorted(files):
+            content = _read(path)
+            parts.append(f"file:{path}:{content_hash(content)}")
+            imports |= _imports(content)
+        module = self._module(directory)
+        if module is None:
+            return parts
+        _module_dir, module_path, module_hash = module
+        parts.append(f"module:{module_path}:{module_hash}")
+        for imported, dep_dir in self._module_deps(directory, imports):
+            parts.append(f"dep:{imported}:{self.api_hash(dep_dir)}")
+        return parts
+
+
+def dependent_dirs(dirs: list[str], path: Path | str) -> list[str]:
+    """Packages under ``path`` that import one of ``dirs`` from their own module.
+
+    Dirs are project-relative, ``.`` for the root. Imports are read from
+    source, so no Go toolchain is needed.
+    """
+    wanted = set(dirs)
+    packages: dict[str, list[str]] = {}
+    for filepath in find_go_files(path):
+        packages.setdefault(os.path.dirname(filepath), []).append(filepath)
+    keyer = GoPackageKeyer()
+    return sorted(
+        directory or "."
+        for directory, files in packages.items()
+        if (directory or ".") not in wanted and keyer.local_deps(directory, files) & wanted
+    )
+
+
+__all__ = ["GoPackageKeyer", "dependent_dirs"]
@@ -0,0 +1,71 @@
+"""Resolve Go package patterns (``./...``, ``./internal/...``, import paths).
+
+``go list`` is the driver ``golang.org/x/tools/go/packages`` itself uses, so
+patterns mean what they mean to the go command: build constraints and
+``-tags`` decide which files belong to a package, generated files are part
+of it, and ``vendor``/``testdata`` are left out.
+"""
+
+from __future__ import annotations
+
+from pathlib import Path
+from typing import Any
+
+from desloppify.core._internal.text_utils import get_project_root
+from desloppify.file_discovery import rel
+from desloppify.languages.go.health import _decode_stream, _run
+
+_FILE_FIELDS = ("GoFiles", "CgoFiles", "TestGoFiles", "XTestGoFiles")
+_LIST_FIELDS = ",".join(("Dir", "ImportPath", "Error", "Match", *_FILE_FIELDS))
+
+
+def is_package_pattern(arg: str) -> bool:
+    """Whether ``arg`` reads as a Go package pattern rather than a plain path."""
+    return "..." in arg
+
+
+def tag_flags(tags: str) -> list[str]:
+    """``go`` command flags for a comma-separated build tag list."""
+    tags = ",".join(t.strip() for t in tags.replace(" ", ",").split(",") if t.strip())
+    return ["-tags", tags] if tags else []
+
+
+def resolve_package_patterns(
+    patterns: list[str], options: dict[str, Any]
+) -> tuple[list[str], list[str]]:
+    """(package dirs, Go files) matched by ``patterns``, project-relative.
+
+    ``options["build_tags"]`` is passed to ``go list -tags``. Raises
+    ValueError when ``go list`` cannot run, a pattern matches nothing, or a
+    package lies outside the project.
+    """
+    root = get_project_root()
+    tags = tag_flags(str(options.get("build_tags") or ""))
+    result = _run(["go", "list", "-e", f"-json={_LIST_FIELDS}", *tags, *patterns], root)
+    if result is None:
+        raise ValueError("go list is not available; install a Go toolchain to scan patterns")
+    packages = _decode_stream(result.stdout)
+    if not packages:
+        detail = result.stderr.strip().splitlines()
+        raise ValueError(detail[-1] if detail else f"no packages match {' '.join(patterns)}")
+    dirs: set[str] = set()
+    files: set[str] = set()
+    for package in packages:
+        directory = package.get("Dir")
+        if not directory:
+            problem = (package.get("Error") or {}).get("Err") or "no such package"
+            raise ValueError(f"{package.get('ImportPath', '?')}: {problem}")
+        try:
+            Path(directory).resolve().relative_to(root.resolve())
+        except ValueError:
+            raise ValueError(
+                f"{package.get('ImportPath', directory)} is outside the project"
+            ) from None
+        rel_dir = rel(directory)
+        dirs.add(rel_dir)
+        for key in _FILE_FIELDS:
+            files.update(rel(str(Path(directory) / name)) for name in package.get(key) or ())
+    return sorted(dirs), sorted(files)
+
+
+__all__ = ["is_package_pattern", "resolve_package_patterns", "tag_flags"]
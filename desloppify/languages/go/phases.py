@@ -10,10 +10,19 @@ import os
 import re
 from pathlib import Path
 
+from desloppify.core.diagnostics import current_diagnostics
+from desloppify.core.progress import current_progress
+from desloppify.core.runtime_state import raise_if_cancelled
 from desloppify.engine.detectors.base import ComplexitySignal
 from desloppify.engine.policy.zones import adjust_potential
+from desloppify.file_discovery import get_report_dirs, get_selected_dirs, is_reported
 from desloppify.languages._framework.base.shared_phases import run_structural_phase
+from desloppify.languages._framework.parallel import resolve_jobs
 from desloppify.languages._framework.runtime import LangRun
+from desloppify.languages.go import health
+from desloppify.languages.go.extractors import find_go_files
+from desloppify.languages.go.modules import directory_settings, overlay_chain, scan_units
+from desloppify.languages.go.packages import tag_flags
 from desloppify.state import make_finding
 from desloppify.utils import log
 
@@ -156,10 +165,103 @@ def _phase_structural(path: Path, lang: LangRun) -> tuple[list[dict], dict[str,
 
 
 def _phase_smells(path: Path, lang: LangRun) -> tuple[list[dict], dict[str, int]]:
-    """Run Go-specific smell detectors."""
-    from desloppify.languages.go.detectors.smells import detect_smells
+    """Run Go-specific smell detectors, once per module under ``path``.
 
-    entries, total_files = detect_smells(path)
+    Files that do not parse only get the text-level smells, plus one
+    ``parse_error`` finding listing every error the parser reported;
+    packages that do not load skip the type-level ones. Both are recorded
+    as degraded units. Files get the config overlays of their directory and
+    its ancestors (see ``modules``), so each overlay chain is a separate run.
+    Rules outside the selected categories (``categories``, or ``scan
+    --category``) or in ``exclude_categories`` are switched off.
+    """
+    from desloppify.languages.go.detectors.custom_rules import parse_custom_rules
+    from desloppify.languages.go.detectors.smells import (
+        MATCH_SAMPLE,
+        _enabled_checks,
+        detect_smells,
+        select_categories,
+    )
+    from desloppify.languages.go.rules import load_plugins
+
+    load_plugins(lang.runtime_setting("rule_plugins", []))
+    base = {
+        "opt_in_smells": lang.runtime_setting("opt_in_smells", []) or [],
+        "custom_rules": lang.runtime_setting("custom_rules", []),
+        "rule_options": lang.runtime_setting("rule_options", {}),
+    }
+    categories = lang.runtime_setting("categories", []) or []
+    exclude_categories = lang.runtime_setting("exclude_categories", []) or []
+    parse_errors = health.parse_errors(
+        [f for f in find_go_files(path) if not f.endswith("_test.go")]
+    )
+    unparsable = {
+        filepath: health.describe_parse_error(filepath, errors[0])
+        for filepath, errors in parse_errors.items()
+    }
+    for filepath, error in sorted(unparsable.items()):
+        lang.record_degraded(
+            filepath, kind="file", skipped="syntax", phase="Go smells", error=error
+        )
+    tags = tag_flags(str(lang.runtime_option("build_tags", "") or ""))
+    cache = lang.result_cache
+    before = (cache.stats.hits, cache.stats.misses) if cache is not None else (0, 0)
+    entries: list[dict] = []
+    total_files = 0
+    for unit in scan_units(path):
+        raise_if_cancelled()
+        if not unit.files:
+            continue
+        groups: dict[tuple[str, ...], list[str]] = {}
+        for filepath in unit.files:
+            groups.setdefault(overlay_chain(os.path.dirname(filepath)), []).append(filepath)
+        runs = []
+        for chain, files in groups.items():
+            settings, rules_source = directory_settings(base, chain)
+            custom_rules = parse_custom_rules(settings["custom_rules"], source=rules_source)
+            rule_options = select_categories(
+                settings["rule_options"], categories, exclude_categories, tuple(custom_rules)
+            )
+            settings = {**settings, "rule_options": rule_options}
+            runs.append((set(settings["opt_in_smells"] or []), custom_rules, settings, files))
+        untyped: dict[str, str] = {}
+        if not lang.syntax_only and any(
+            s["requires"] == "types"
+            for opt_in, custom_rules, settings, _ in runs
+            for s in _enabled_checks(
+                opt_in, custom_rules=custom_rules, rule_options=settings["rule_options"]
+            )
+        ):
+            loaded = health.load_errors(unit.root, tags)
+            untyped = loaded[1] if loaded is not None else {}
+            for directory, error in sorted(untyped.items()):
+                lang.record_degraded(
+                    directory, kind="package", skipped="types", phase="Go smells", error=error
+                )
+        for opt_in, custom_rules, settings, files in runs:
+            unit_entries, unit_files = detect_smells(
+                path,
+                opt_in=opt_in,
+                jobs=resolve_jobs(lang.jobs),
+                cache=cache,
+                syntax_only=lang.syntax_only,
+                diagnostics=current_diagnostics(),
+                unparsable=frozenset(unparsable),
+                untyped=frozenset(untyped),
+                custom_rules=custom_rules,
+                rule_options=settings["rule_options"],
+                files=files,
+                progress=current_progress(),
+                reported=is_reported if get_report_dirs() is not None else None,
+                sample=None if lang.full_matches else MATCH_SAMPLE,
+            )
+            entries.extend(unit_entries)
+            total_files += unit_files
+    if cache is not None:
+        log(
+            f"         go smells cache: {cache.stats.hits - before[0]} hit(s), "
+            f"{cache.stats.misses - before[1]} miss(es)"
+        )
 
     results = []
     for entry in entries:
@@ -177,12 +279,105 @@ def _phase_smells(path: Path, lang: LangRun) -> tuple[list[dict], dict[str, int]
                     "severity": entry["severity"],
                     "count": entry["count"],
                     "files": entry["files"],
-                    "matches": entry["matches"][:10],
+                    "matches": entry["matches"] if lang.full_matches else entry["matches"][:10],
                 },
             )
         )
 
     if results:
         log(f"         go smells: {len(results)} smell types detected")
+    results += _parse_error_findings(parse_errors)
 
     return results, {"smells": adjust_potential(lang.zone_map, total_files)}
+
+
+def _parse_error_findings(parse_errors: dict[str, list[dict]]) -> list[dict]:
+    """One ``parse_error`` finding per file, at its first error, listing them all."""
+    findings = []
+    for filepath, errors in sorted(parse_errors.items()):
+        first = errors[0]
+        more = f" (+{len(errors) - 1} more)" if len(errors) > 1 else ""
+        findings.append(
+            make_finding(
+                "parse_error",
+                filepath,
+                "",
+                tier=2,
+                confidence="high",
+                summary=f"Does not parse: {first['message']} at "
+                f"{first['line']}:{first['column']}{more}",
+                detail={"line": first["line"], "column": first["column"], "errors": errors},
+            )
+        )
+    if findings:
+        log(f"         parse errors: {len(findings)} files do not parse")
+    return findings
+
+
+def _phase_vet(path: Path, lang: LangRun) -> tuple[list[dict], dict[str, int]]:
+    """Run ``go vet`` on the packages that load, one invocation per module.
+
+    Only the selected packages are vetted, and in changed-files scans only
+    the reported ones. A package that fails to load would otherwise abort
+    vet for the whole module; it is left out and recorded as degraded
+    instead, as is any package vet cannot type-check.
+    """
+    tags = tag_flags(str(lang.runtime_option("build_tags", "") or ""))
+    selected = get_selected_dirs()
+    report = get_report_dirs()
+    if report is not None:
+        selected = report if selected is None else tuple(d for d in selected if d in report)
+    entries: list[dict] = []
+    for unit in scan_units(path):
+        raise_if_cancelled()
+        if unit.files:
+            entries += _vet_unit(unit.root, lang, tags, selected)
+    if not entries:
+        return [], {}
+    findings = [
+        make_finding(
+            "vet_error",
+            entry["file"],
+            f"vet_error::{entry['line']}",
+            tier=3,
+            confidence="medium",
+            summary=entry["message"],
+        )
+        for entry in entries
+    ]
+    return findings, {"vet_error": len(entries)}
+
+
+def _vet_unit(
+    root: Path, lang: LangRun, tags: list[str], selected: tuple[str, ...] | None
+) -> list[dict]:
+    """Vet entries for the module at ``root``, file paths made absolute."""
+    loaded = health.load_errors(root, tags)
+    if loaded is None:
+        return []
+    healthy, broken = loaded
+    if selected is not None:
+        healthy = {d: p for d, p in healthy.items() if d in selected}
+        broken = {d: e for d, e in broken.items() if d in selected}
+    for directory, error in sorted(broken.items()):
+        lang.record_degraded(
+            directory, kind="package", skipped="types", phase="go vet", error=error
+        )
+    output = (
+        health.vet_packages(root, sorted(healthy.values()), tags) if healthy else None
+    )
+    if not output:
+        return []
+    entries, type_errors = health.split_vet_output(output)
+    directories = {import_path: d for d, import_path in healthy.items()}
+    for import_path, error in sorted(type_errors.items()):
+        lang.record_degraded(
+            directories.get(import_path, import_path),
+            kind="package",
+            skipped="types",
+            phase="go vet",
+            error=error,
+        )
+    for entry in entries:
+        entry["file"] = str(root / entry["file"])
+    return entries
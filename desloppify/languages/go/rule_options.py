@@ -0,0 +1,223 @@
+"""Typed per-rule options and strict checking of the ``languages.go`` config.
+
+A rule declares its options with ``int_option`` (a range), ``enum_option``
+(fixed choices) or ``str_list_option`` (strings, optionally from fixed
+choices); users set them per rule id under ``languages.go.rule_options``::
+
+    "rule_options": {"too_many_params": {"max": 7}}
+
+Every rule also takes ``enabled``: ``false`` turns a rule off, ``true`` turns
+an opt-in rule on (as listing it in ``opt_in_smells`` does). With nested
+config overlays (see ``modules``) this lets a directory re-enable a rule its
+ancestors disabled.
+
+``check_settings`` reports unknown rule ids (in ``opt_in_smells`` and
+``rule_options``), unknown categories, unknown option keys and out-of-range
+or mistyped values, each with its config line and, for a misspelling, the
+closest known name.
+``resolve_options`` turns whatever is configured into the values detectors
+see: every option of every rule, defaults filling in for missing or invalid
+values (``scan --lenient-config`` carries on past the errors).
+"""
+
+from __future__ import annotations
+
+import json
+from collections.abc import Iterable
+from dataclasses import dataclass
+from pathlib import Path
+from typing import Any
+
+from desloppify.core.config import CONFIG_FILE, config_line, did_you_mean
+from desloppify.file_discovery import rel
+
+CONFIG_KEYS = ("languages", "go")
+KINDS = ("int", "enum", "str_list")
+ENABLED = "enabled"
+
+
+@dataclass(frozen=True)
+class Option:
+    """One setting a rule accepts; build with the ``*_option`` helpers."""
+
+    name: str
+    kind: str
+    default: Any
+    description: str = ""
+    minimum: int = 0
+    maximum: int = 0
+    choices: tuple[str, ...] = ()
+
+    def problem(self, value: object) -> str | None:
+        """Why ``value`` is not acceptable, or None when it is."""
+        if self.kind == "int":
+            allowed = f"{self.minimum}..{self.maximum}"
+            if isinstance(value, bool) or not isinstance(value, int):
+                return f"expected an integer in {allowed}, got {json.dumps(value)}"
+            if not self.minimum <= value <= self.maximum:
+                return f"{value} is out of range (allowed: {allowed})"
+            return None
+        if self.kind == "enum":
+            if not isinstance(value, str):
+                return f"expected one of {', '.join(self.choices)}, got {json.dumps(value)}"
+            if value not in self.choices:
+                return _not_a_choice(value, self.choices)
+            return None
+        if not isinstance(value, list) or not all(isinstance(v, str) for v in value):
+            return f"expected a list of strings, got {json.dumps(value)}"
+        for item in value:
+            if self.choices and item not in self.choices:
+                return _not_a_choice(item, self.choices)
+        return None
+
+
+def _not_a_choice(value: str, choices: tuple[str, ...]) -> str:
+    hint = did_you_mean(value, choices) or f" (allowed: {', '.join(choices)})"
+    return f"{value!r} is not allowed{hint}"
+
+
+def int_option(
+    name: str, default: int, *, minimum: int, maximum: int, description: str = ""
+) -> Option:
+    if not minimum <= default <= maximum:
+        raise ValueError(f"option {name}: default {default} is outside {minimum}..{maximum}")
+    return Option(name, "int", default, description, minimum=minimum, maximum=maximum)
+
+
+def enum_option(
+    name: str, default: str, choices: Iterable[str], *, description: str = ""
+) -> Option:
+    choices = tuple(choices)
+    if default not in choices:
+        raise ValueError(f"option {name}: default {default!r} is not one of its choices")
+    return Option(name, "enum", default, description, choices=choices)
+
+
+def str_list_option(
+    name: str,
+    default: Iterable[str],
+    *,
+    choices: Iterable[str] = (),
+    description: str = "",
+) -> Option:
+    return Option(name, "str_list", list(default), description, choices=tuple(choices))
+
+
+def rule_switch(configured: object, rule_id: str) -> bool | None:
+    """The ``enabled`` value configured for ``rule_id``, None when it is not set."""
+    values = configured.get(rule_id) if isinstance(configured, dict) else None
+    enabled = values.get(ENABLED) if isinstance(values, dict) else None
+    return enabled if isinstance(enabled, bool) else None
+
+
+def resolve_options(configured: object, checks: list[dict]) -> dict[str, dict[str, Any]]:
+    """Option values per rule in ``checks``: configured when valid, else the default."""
+    configured = configured if isinstance(configured, dict) else {}
+    resolved: dict[str, dict[str, Any]] = {}
+    for check in checks:
+        if not check.get("options"):
+            continue
+        values = configured.get(check["id"])
+        values = values if isinstance(values, dict) else {}
+        resolved[check["id"]] = {
+            option.name: (
+                values[option.name]
+                if option.name in values and option.problem(values[option.name]) is None
+                else option.default
+            )
+            for option in check["options"]
+        }
+    return resolved
+
+
+def check_settings(raw: dict[str, Any], source: Path | None = None) -> list[str]:
+    """Every problem with the rule ids and options in ``languages.go``.
+
+    Plugins and custom rules are loaded first so their ids count as known;
+    those raise ConfigError themselves when they are broken.
+    """
+    from desloppify.languages.go.detectors.custom_rules import parse_custom_rules
+    from desloppify.languages.go.detectors.smells import _all_checks
+    from desloppify.languages.go.rules import CATEGORIES, load_plugins
+
+    source = source or CONFIG_FILE
+    load_plugins(raw.get("rule_plugins"), source=source)
+    custom_rules = parse_custom_rules(raw.get("custom_rules"), source=source)
+    checks = {s["id"]: s for s in _all_checks(tuple(custom_rules))}
+    problems: list[str] = []
+
+    def report(problem: str, *keys: str | int) -> None:
+        line = config_line(CONFIG_KEYS + keys, source)
+        where = rel(str(source)) + (f":{line}" if line else "")
+        field = "languages.go" + "".join(
+            f"[{k}]" if isinstance(k, int) else f".{k}" for k in keys
+        )
+        problems.append(f"{where}: {field}: {problem}")
+
+    opt_in = raw.get("opt_in_smells") or []
+    if not isinstance(opt_in, list):
+        report("expected a list of rule ids", "opt_in_smells")
+        opt_in = []
+    for index, rule_id in enumerate(opt_in):
+        if rule_id not in checks:
+            hint = did_you_mean(str(rule_id), checks)
+            report(f"unknown rule {rule_id!r}{hint}", "opt_in_smells", index)
+
+    for key in ("categories", "exclude_categories"):
+        names = raw.get(key) or []
+        if not isinstance(names, list):
+            report("expected a list of categories", key)
+            continue
+        for index, name in enumerate(names):
+            if name not in CATEGORIES:
+                hint = did_you_mean(str(name), CATEGORIES) or (
+                    f" (categories: {', '.join(CATEGORIES)})"
+                )
+                report(f"unknown category {name!r}{hint}", key, index)
+
+    configured = raw.get("rule_options") or {}
+    if not isinstance(configured, dict):
+        report("expected an object of {rule id: {option: value}}", "rule_options")
+        configured = {}
+    for rule_id, values in configured.items():
+        check = checks.get(rule_id)
+        if check is None:
+            hint = did_you_mean(rule_id, checks)
+            report(f"unknown rule {rule_id!r}{hint}", "rule_options", rule_id)
+            continue
+        if not isinstance(values, dict):
+            report("expected an object of {option: value}", "rule_options", rule_id)
+            continue
+        declared = {option.name: option for option in check.get("options", ())}
+        if not declared and set(values) - {ENABLED}:
+            report(f"{rule_id} has no options", "rule_options", rule_id)
+            continue
+        for name, value in values.items():
+            option = declared.get(name)
+            if name == ENABLED:
+                if not isinstance(value, bool):
+                    problem = f"expected true or false, got {json.dumps(value)}"
+                    report(problem, "rule_options", rule_id, name)
+            elif option is None:
+                hint = did_you_mean(name, [*declared, ENABLED]) or (
+                    f" (options: {', '.join(declared)})"
+                )
+                report(
+                    f"unknown option {name!r} for {rule_id}{hint}", "rule_options", rule_id, name
+                )
+            elif problem := option.problem(value):
+                report(problem, "rule_options", rule_id, name)
+    return problems
+
+
+__all__ = [
+    "ENABLED",
+    "KINDS",
+    "Option",
+    "check_settings",
+    "enum_option",
+    "int_option",
+    "resolve_options",
+    "rule_switch",
+    "str_list_option",
+]
@@ -0,0 +1,319 @@
+"""Public interface for Go rules written outside this repository.
+
+A rule is metadata plus a ``run(pass_)`` function taking the same ``Pass``
+every built-in detector gets. ``pass_.file`` is the file's single shared
+parse, a ``GoFile``: ``path``, ``content``, ``lines``, ``masked`` (comments
+and string contents blanked, offsets kept), ``masked_lines``,
+``line_at(offset)``, ``enclosing_blocks(offset)``, ``block_open(close)``,
+``go_version`` and ``memo(key, build)`` for facts worth sharing between
+rules. ``pass_.types`` is the package's ``PackageTypes`` (None at syntax
+level). A rule declared with ``requires="types"`` can also ask ``go/types``
+what an expression is: ``pass_.type_of(start, end=None)`` gives the type
+string (``"float64"``, ``"*bytes.Buffer"``) of the expression at those
+offsets, and ``pass_.types_info`` the whole package's ``TypesInfo``. The
+package is type-checked on first use, so scans without such a rule never
+run it. ``pass_.report(line, content=None)`` records a match on a 1-based
+line; ``content`` defaults to that line, stripped. ``pass_.options`` holds
+the rule's options (declared with ``rule_options.int_option`` and friends,
+set under ``languages.go.rule_options``), defaults filled in.
+
+::
+
+    from desloppify.languages.go.rules import rule
+
+    @rule("no_fmt_print", "fmt.Print* in library code", severity="low")
+    def no_fmt_print(pass_):
+        for i, line in enumerate(pass_.file.masked_lines, 1):
+            if "fmt.Print" in line:
+                pass_.report(i)
+
+A module registers its rules when imported. There are two ways to get it
+imported:
+
+- list it under ``languages.go.rule_plugins`` in config, as a module name
+  importable from the project root or ``sys.path``, or as a path to a
+  ``.py`` file;
+- ship a thin entry point that imports the rule modules and then calls
+  ``desloppify.cli.main()``, so the rules are built in for everyone who runs
+  it (the approach golangci-lint's module plugins take).
+
+Registered rules are Go smells like any other: listed by ``langs --rules``,
+enabled with ``opt_in_smells`` when ``opt_in``, selected by their
+``categories`` (``scan --category``), skipped by ``scan --fast`` when they
+need ``types``, timed per rule, cached per package, suppressed by
+``//desloppify:ignore <id>`` and reported as ``go_smell::<id>``.
+"""
+
+from __future__ import annotations
+
+import hashlib
+import importlib
+import importlib.util
+import json
+import os
+import re
+import sys
+from collections.abc import Callable
+from dataclasses import dataclass
+from pathlib import Path
+
+from desloppify.core._internal.text_utils import get_project_root
+from desloppify.core.config import CONFIG_FILE, ConfigError, config_line
+from desloppify.file_discovery import rel
+from desloppify.languages.go.detectors._inspector import GoFile, PackageTypes, Pass
+from desloppify.languages.go.rule_options import Option
+from desloppify.languages.go.typeinfo import TypesInfo
+
+CONFIG_KEYS = ("languages", "go", "rule_plugins")
+SEVERITIES = ("low", "medium", "high")
+REQUIREMENTS = ("syntax", "types")
+# What a rule is about; ``scan --category`` and ``languages.go.categories`` select by it.
+CATEGORIES = ("correctness", "concurrency", "performance", "security", "style")
+
+RunFn = Callable[[Pass], None]
+
+_ID_RE = re.compile(r"^[a-z][a-z0-9_]*$")
+# Module names given to plugins loaded from a file path.
+_FILE_MODULE_PREFIX = "desloppify_rule_plugin_"
+
+
+@dataclass(frozen=True)
+class Rule:
+    """An out-of-tree rule: what ``langs --rules`` shows plus the detector."""
+
+    id: str
+    label: str
+    run: RunFn
+    severity: str = "medium"
+    requires: str = "syntax"
+    opt_in: bool = False
+    # Where the rule came from: its module name, or the file a plugin was loaded from.
+    source: str = ""
+    options: tuple[Option, ...] = ()
+    categories: tuple[str, ...] = ()
+
+    def requires_types(self) -> bool:
+        """Whether ``run`` reads ``pass_.types_info``, so the package gets type-checked."""
+        return self.requires == "types"
+
+
+# id -> Rule, in registration order.
+_REGISTRY: dict[str, Rule] = {}
+# Plugin (module name or absolute .py path) -> module.
+_LOADED: dict[str, object] = {}
+
+
+def register_rule(new: Rule) -> Rule:
+    """Add ``new`` to the registry; raises ValueError on a bad or taken id."""
+    from desloppify.languages.go.detectors.smells import SMELL_CHECKS
+
+    if not _ID_RE.match(new.id):
+        raise ValueError(f"rule id {new.id!r} is not lower_snake_case")
+    if new.severity not in SEVERITIES:
+        raise ValueError(f"rule {new.id}: severity must be one of {', '.join(SEVERITIES)}")
+    if new.requires not in REQUIREMENTS:
+        raise ValueError(f"rule {new.id}: requires must be one of {', '.join(REQUIREMENTS)}")
+    if any(c not in CATEGORIES for c in new.categories):
+        raise ValueError(f"rule {new.id}: categories must be among {', '.join(CATEGORIES)}")
+    if any(s["id"] == new.id for s in SMELL_CHECKS):
+        raise ValueError(f"rule {new.id}: a built-in smell has this id")
+    existing = _REGISTRY.get(new.id)
+    if existing is not None and existing.source != new.source:
+        raise ValueError(f"rule {new.id}: already registered by {existing.source}")
+    _REGISTRY[new.id] = new
+    return new
+
+
+def rule(
+    id: str,
+    label: str,
+    *,
+    severity: str = "medium",
+    requires: str = "syntax",
+    opt_in: bool = False,
+    options: tuple[Option, ...] = (),
+    categories: tuple[str, ...] = (),
+) -> Callable[[RunFn], RunFn]:
+    """Decorator registering ``run`` as rule ``id``."""
+
+    def register(run: RunFn) -> RunFn:
+        register_rule(
+            Rule(
+                id,
+                label,
+                run,
+                severity,
+                requires,
+                opt_in,
+                _source_of(run),
+                tuple(options),
+                tuple(categories),
+            )
+        )
+        return run
+
+    return register
+
+
+def _source_of(run: RunFn) -> str:
+    """The module name, or for a plugin loaded from a path, the path."""
+    module = run.__module__
+    if module.startswith(_FILE_MODULE_PREFIX):
+        return getattr(sys.modules.get(module), "__file__", None) or module
+    return module
+
+
+def registered_rules() -> list[Rule]:
+    """Every registered rule, in registration order."""
+    return list(_REGISTRY.values())
+
+
+def unregister_rules(source: str | None = None) -> None:
+    """Forget the rules from ``source`` (all rules when None), e.g. between tests.
+
+    Plugins loaded by ``load_plugin`` are unloaded too, so loading one again
+    re-runs its registrations.
+    """
+    for rule_id in [r.id for r in _REGISTRY.values() if source in (None, r.source)]:
+        del _REGISTRY[rule_id]
+    for key in [k for k in _LOADED if source in (None, k)]:
+        module = _LOADED.pop(key)
+        name = getattr(module, "__name__", "")
+        for loaded in [m for m in sys.modules if m == name or m.startswith(name + ".")]:
+            del sys.modules[loaded]
+
+
+def load_plugin(spec: str) -> None:
+    """Import one plugin: a module name, or a path to a ``.py`` file.
+
+    Relative paths and top-level modules are looked up from the project
+    root. Loading the same plugin again does nothing. Raises ImportError
+    when it cannot be imported and ValueError when a rule is invalid.
+    """
+    key = _plugin_key(spec)
+    if key in _LOADED:
+        return
+    if key.endswith(".py"):
+        name = _FILE_MODULE_PREFIX + hashlib.sha1(key.encode()).hexdigest()[:12]
+        module_spec = importlib.util.spec_from_file_location(name, key)
+        if module_spec is None or module_spec.loader is None:
+            raise ImportError(f"cannot load {key}")
+        module = importlib.util.module_from_spec(module_spec)
+        sys.modules[name] = module
+        try:
+            module_spec.loader.exec_module(module)
+        except BaseException:
+            del sys.modules[name]
+            unregister_rules(key)
+            raise
+    else:
+        root = str(get_project_root())
+        added = root not in sys.path
+        if added:
+            sys.path.insert(0, root)
+        try:
+            module = importlib.import_module(key)
+        except BaseException:
+            unregister_rules(key)
+            raise
+        finally:
+            if added:
+                sys.path.remove(root)
+    _LOADED[key] = module
+
+
+def _plugin_key(spec: str) -> str:
+    if spec.endswith(".py") or os.sep in spec or "/" in spec:
+        path = Path(spec)
+        if not path.is_absolute():
+            path = get_project_root() / path
+        return str(path.resolve())
+    return spec
+
+
+def load_plugins(specs: object, *, source: Path | None = None) -> list[Rule]:
+    """Load the ``rule_plugins`` list from the config at ``source``.
+
+    Raises ConfigError naming the file, the line and the plugin that failed.
+    Returns every registered rule afterwards.
+    """
+    source = source or CONFIG_FILE
+
+    def fail(problem: str, *keys: int) -> ConfigError:
+        line = config_line(CONFIG_KEYS + keys, source)
+        where = rel(str(source)) + (f":{line}" if line else "")
+        field = "".join(f"[{k}]" for k in keys)
+        return ConfigError(f"{where}: rule_plugins{field}: {problem}")
+
+    if specs is None:
+        return registered_rules()
+    if not isinstance(specs, list):
+        raise fail("expected a list of module names or .py paths")
+    for index, spec in enumerate(specs):
+        if not isinstance(spec, str) or not spec.strip():
+            raise fail("expected a module name or .py path", index)
+        try:
+            load_plugin(spec.strip())
+        except ValueError as exc:
+            raise fail(str(exc), index) from None
+        except Exception as exc:  # noqa: BLE001 - any import-time failure
+            raise fail(f"cannot import {spec!r}: {exc}", index) from None
+    return registered_rules()
+
+
+def load_configured_plugins(path: Path | None = None) -> list[Rule]:
+    """Load the plugins a config file lists, read as-is."""
+    path = path or CONFIG_FILE
+    try:
+        config = json.loads(path.read_text())
+    except (OSError, UnicodeDecodeError, json.JSONDecodeError):
+        return registered_rules()
+    raw: object = config
+    for key in CONFIG_KEYS:
+        raw = raw.get(key) if isinstance(raw, dict) else None
+    return load_plugins(raw, source=path)
+
+
+def ensure_sources(sources: tuple[str, ...]) -> None:
+    """Make rules from ``sources`` available here (in a fresh worker process)."""
+    for source in sources:
+        if source == "__main__":
+            continue
+        if not any(r.source == source for r in _REGISTRY.values()):
+            load_plugin(source)
+
+
+def fingerprint(rules: list[Rule]) -> list[str]:
+    """Cache-key parts that change when a rule's module source changes."""
+    parts = []
+    for source in sorted({r.source for r in rules}):
+        path = source if source.endswith(".py") else getattr(
+            sys.modules.get(source), "__file__", None
+        )
+        try:
+            digest = hashlib.sha1(Path(path).read_bytes()).hexdigest() if path else ""
+        except OSError:
+            digest = ""
+        parts.append(f"plugin:{source}:{digest}")
+    return parts
+
+
+__all__ = [
+    "CATEGORIES",
+    "CONFIG_KEYS",
+    "GoFile",
+    "Option",
+    "PackageTypes",
+    "Pass",
+    "Rule",
+    "TypesInfo",
+    "ensure_sources",
+    "fingerprint",
+    "load_configured_plugins",
+    "load_plugin",
+    "load_plugins",
+    "register_rule",
+    "registered_rules",
+    "rule",
+    "unregister_rules",
+]
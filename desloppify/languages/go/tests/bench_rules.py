@@ -0,0 +1,295 @@
+"""Per-rule benchmarks for Go smells, in the style of ``go test -bench``.
+
+Run with ``python -m desloppify.languages.go.tests.bench_rules`` (or
+``make bench-go-rules``). Every ``benchmark_*`` function here takes a ``B``
+and loops ``b.n`` times; the runner grows ``b.n`` until a run lasts
+``-benchtime`` and prints one line per benchmark::
+
+    BenchmarkRule/yoda_condition        20000      48213 ns/op
+
+One op is one file of the corpus (the Go fixtures plus ``-files``
+synthetic files), except for ``BenchmarkDetectSmells``, whose op is a whole
+module. ``-bench REGEX`` selects benchmarks by name (``-bench Rule/`` for
+the per-rule ones). ``-benchmem`` adds ``peak-B/op``, the peak tracemalloc saw above
+the post-setup baseline over one pass of the corpus; Python keeps no count
+of allocations, so there is no allocs/op.
+``-slowest N`` ends with the N slowest rules and their share of the total.
+"""
+
+from __future__ import annotations
+
+import argparse
+import contextlib
+import functools
+import inspect
+import re
+import sys
+import tempfile
+import time
+import tracemalloc
+from collections.abc import Callable
+from dataclasses import dataclass
+from pathlib import Path
+
+from desloppify.core.runtime_state import RuntimeContext, runtime_scope
+from desloppify.languages.go.detectors._inspector import GoFile, Inspector
+from desloppify.languages.go.detectors.smells import (
+    SMELL_CHECKS,
+    _build_inspector,
+    _scan_source,
+    detect_smells,
+)
+from desloppify.languages.go.tests.bench_smells import (
+    FIXTURES_DIR,
+    make_synthetic_tree,
+    synthetic_source,
+)
+
+_MAX_N = 1_000_000_000
+_corpus_files = 200
+
+
+@dataclass
+class Result:
+    name: str
+    n: int
+    ns_per_op: float
+    peak_bytes_per_op: int | None = None
+
+    def line(self) -> str:
+        text = f"{self.name:<48}{self.n:>10}{self.ns_per_op:>14.0f} ns/op"
+        if self.peak_bytes_per_op is not None:
+            text += f"{self.peak_bytes_per_op:>12} peak-B/op"
+        return text
+
+
+class B:
+    """Loop count and timer handed to a benchmark, like Go's ``testing.B``."""
+
+    def __init__(self, n: int) -> None:
+        self.n = n
+        self.subs: list[tuple[str, Callable[[B], None]]] = []
+        self.seconds = 0.0
+        self.baseline = 0
+        self._start = time.perf_counter()
+
+    def reset_timer(self) -> None:
+        """Leave setup done so far out of the measurement."""
+        self._start = time.perf_counter()
+        if tracemalloc.is_tracing():
+            tracemalloc.reset_peak()
+            self.baseline = tracemalloc.get_traced_memory()[0]
+
+    def run(self, name: str, fn: Callable[[B], None]) -> None:
+        """Declare a sub-benchmark ``<parent>/<name>``."""
+        self.subs.append((name, fn))
+
+    def elapsed(self) -> float:
+        return time.perf_counter() - self._start
+
+
+@functools.cache
+def corpus(files: int = 200) -> list[tuple[str, str]]:
+    """(path, content) of every file one op can be run on."""
+    fixtures = [(str(p), p.read_text()) for p in sorted(FIXTURES_DIR.glob("*.go"))]
+    synthetic = [
+        (f"internal/pkg{n:04d}/file.go", synthetic_source(f"pkg{n:04d}", n))
+        for n in range(files)
+    ]
+    return fixtures + synthetic
+
+
+@functools.cache
+def _parsed_corpus(files: int) -> list[GoFile]:
+    """The corpus with every shared per-file fact already computed."""
+    sources = [GoFile(path, content) for path, content in corpus(files)]
+    inspector = _build_inspector({s["id"] for s in SMELL_CHECKS})
+    counts: dict[str, list] = {s["id"]: [] for s in SMELL_CHECKS}
+    for source in sources:
+        inspector.run(source, counts)
+    return sources
+
+
+def benchmark_parse(b: B) -> None:
+    """Reading a file into a ``GoFile``: masking, line split and index."""
+    files = corpus(_corpus_files)
+    b.reset_timer()
+    for i in range(b.n):
+        path, content = files[i % len(files)]
+        GoFile(path, content).masked_lines  # noqa: B018 - forces the split
+
+
+def benchmark_all_rules(b: B) -> None:
+    """Every rule on a freshly parsed file, as a scan runs them."""
+    files = corpus(_corpus_files)
+    checks = list(SMELL_CHECKS)
+    inspector = _build_inspector({s["id"] for s in checks})
+    b.reset_timer()
+    for i in range(b.n):
+        if i % len(files) == 0:
+            counts: dict[str, list] = {s["id"]: [] for s in checks}
+        path, content = files[i % len(files)]
+        _scan_source(GoFile(path, content), checks, inspector, counts)
+
+
+def benchmark_rule(b: B) -> None:
+    """One sub-benchmark per rule, on files whose shared facts are precomputed."""
+    detectors = {
+        part.rules[0]: part
+        for part in _build_inspector({s["id"] for s in SMELL_CHECKS}).split()
+    }
+    for check in SMELL_CHECKS:
+        b.run(check["id"], _rule_benchmark(check, detectors.get(check["id"], Inspector())))
+
+
+def _rule_benchmark(check: dict, detector: Inspector) -> Callable[[B], None]:
+    checks = [check] if check["pattern"] is not None else []
+
+    def bench(b: B) -> None:
+        sources = _parsed_corpus(_corpus_files)
+        b.reset_timer()
+        for i in range(b.n):
+            if i % len(sources) == 0:
+                counts: dict[str, list] = {s["id"]: [] for s in SMELL_CHECKS}
+            _scan_source(sources[i % len(sources)], checks, detector, counts)
+
+    return bench
+
+
+def benchmark_detect_smells(b: B) -> None:
+    """``detect_smells`` over a 20-package module on disk, one worker, no cache."""
+    with tempfile.TemporaryDirectory(prefix="desloppify-bench-") as tmp:
+        root = make_synthetic_tree(Path(tmp), packages=20, files_per_package=4)
+        with runtime_scope(RuntimeContext(project_root=root)), contextlib.chdir(root):
+            b.reset_timer()
+            for _ in range(b.n):
+                detect_smells(root, jobs=1)
+
+
+def benchmarks() -> list[tuple[str, Callable[[B], None]]]:
+    """(Go-style name, function) for every ``benchmark_*`` in this module."""
+    module = sys.modules[__name__]
+    return [
+        ("Benchmark" + "".join(part.title() for part in name.split("_")[1:]), fn)
+        for name, fn in inspect.getmembers(module, inspect.isfunction)
+        if name.startswith("benchmark_") and fn.__module__ == __name__
+    ]
+
+
+def _parse_benchtime(value: str) -> tuple[float | None, int | None]:
+    """(seconds, fixed iterations) from ``1s``, ``250ms`` or ``100x``."""
+    match = re.fullmatch(r"(\d+(?:\.\d+)?)(s|ms|x)", value.strip())
+    if match is None:
+        raise argparse.ArgumentTypeError(f"invalid benchtime {value!r}")
+    number, unit = float(match.group(1)), match.group(2)
+    if unit == "x":
+        return None, max(1, int(number))
+    return (number / 1000 if unit == "ms" else number), None
+
+
+def _measure(fn: Callable[[B], None], benchtime: tuple[float | None, int | None]) -> B:
+    seconds, fixed = benchtime
+    n = fixed or 1
+    while True:
+        b = B(n)
+        fn(b)
+        elapsed = b.elapsed()
+        if fixed or elapsed >= seconds or n >= _MAX_N:
+            b.seconds = elapsed
+            return b
+        per_op = max(elapsed / n, 1e-9)
+        n = min(_MAX_N, max(n + 1, min(100 * n, int(seconds / per_op * 1.2))))
+
+
+def _peak_bytes(fn: Callable[[B], None], n: int) -> int:
+    tracemalloc.start()
+    try:
+        b = B(n)
+        b.baseline = tracemalloc.get_traced_memory()[0]
+        fn(b)  # reset_timer moves the baseline past setup
+        return max(0, tracemalloc.get_traced_memory()[1] - b.baseline)
+    finally:
+        tracemalloc.stop()
+
+
+def run_benchmarks(
+    pattern: str = ".",
+    *,
+    benchtime: str = "1s",
+    benchmem: bool = False,
+    out=None,
+) -> list[Result]:
+    """Run the benchmarks ``pattern`` selects, printing each result as it lands."""
+    out = out or sys.stdout
+    parsed = _parse_benchtime(benchtime)
+    top = re.compile(pattern.split("/", 1)[0])
+    full = re.compile(pattern)
+    results: list[Result] = []
+
+    def record(name: str, fn: Callable[[B], None]) -> None:
+        b = _measure(fn, parsed)
+        result = Result(name, b.n, b.seconds * 1e9 / b.n)
+        if benchmem:
+            ops = min(b.n, len(corpus(_corpus_files)))
+            result.peak_bytes_per_op = _peak_bytes(fn, ops)
+        results.append(result)
+        print(result.line(), file=out, flush=True)
+
+    for name, fn in benchmarks():
+        if not top.search(name):
+            continue
+        probe = B(0)  # setup only; collects any sub-benchmarks
+        fn(probe)
+        if not probe.subs:
+            if full.search(name):
+                record(name, fn)
+            continue
+        for sub, sub_fn in probe.subs:
+            if full.search(f"{name}/{sub}"):
+                record(f"{name}/{sub}", sub_fn)
+    return results
+
+
+def slowest_rules(results: list[Result], count: int) -> list[tuple[str, float, float]]:
+    """(rule, ns/op, share of all rules' ns/op) for the ``count`` slowest rules."""
+    rules = [r for r in results if r.name.startswith("BenchmarkRule/")]
+    total = sum(r.ns_per_op for r in rules) or 1.0
+    ranked = sorted(rules, key=lambda r: (-r.ns_per_op, r.name))[:count]
+    return [
+        (r.name.removeprefix("BenchmarkRule/"), r.ns_per_op, r.ns_per_op / total)
+        for r in ranked
+    ]
+
+
+def main(argv: list[str] | None = None) -> int:
+    global _corpus_files
+    parser = argparse.ArgumentParser(description=__doc__.splitlines()[0])
+    parser.add_argument("-bench", "--bench", default=".", metavar="REGEX")
+    parser.add_argument("-benchtime", "--benchtime", default="1s", metavar="T")
+    parser.add_argument("-benchmem", "--benchmem", action="store_true")
+    parser.add_argument(
+        "-files", "--files", type=int, default=200, help="Synthetic files in the corpus"
+    )
+    parser.add_argument(
+        "-slowest",
+        "--slowest",
+        type=int,
+        default=0,
+        metavar="N",
+        help="List the N slowest rules after the results",
+    )
+    args = parser.parse_args(argv)
+    _corpus_files = args.files
+    results = run_benchmarks(args.bench, benchtime=args.benchtime, benchmem=args.benchmem)
+    if not results:
+        print(f"no benchmarks match {args.bench!r}", file=sys.stderr)
+        return 1
+    if args.slowest:
+        print(f"\nSlowest {args.slowest} rules:")
+        for rule, ns, share in slowest_rules(results, args.slowest):
+            print(f"  {rule:<32}{ns:>12.0f} ns/op {share:>6.1%}")
+    return 0
+
+
+if __name__ == "__main__":
+    raise SystemExit(main())
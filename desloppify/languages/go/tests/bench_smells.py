@@ -0,0 +1,254 @@
+"""Wall-time benchmark for Go smell detection on a synthetic module.
+
+Run with ``python -m desloppify.languages.go.tests.bench_smells`` (or
+``make bench-go``). It writes a module of ``--packages`` packages to a temp
+directory, times ``detect_smells`` once per ``--jobs`` value, and checks
+every run produced identical entries. ``--traversals`` instead compares
+parses, walks and time for the shared single pass against one parse and walk
+per rule over the Go fixtures. ``--rss-ceiling MB`` scans the module once while
+sampling resident memory and fails if any checkpoint exceeds the ceiling.
+"""
+
+from __future__ import annotations
+
+import argparse
+import contextlib
+import os
+import resource
+import sys
+import tempfile
+import threading
+import time
+from pathlib import Path
+
+from desloppify.core.runtime_state import RuntimeContext, runtime_scope
+from desloppify.languages._framework.parallel import default_jobs
+from desloppify.languages.go.detectors._inspector import GoFile, Inspector, PackageTypes
+from desloppify.languages.go.detectors.custom_rules import CustomRule
+from desloppify.languages.go.detectors.smells import (
+    SMELL_CHECKS,
+    _build_inspector,
+    _enabled_checks,
+    detect_smells,
+)
+
+FIXTURES_DIR = Path(__file__).resolve().parents[3] / "tests" / "fixtures" / "go"
+
+_FILE_TEMPLATE = """package {pkg}
+
+import (
+\t"fmt"
+\t"os"
+)
+
+func Describe{n}(x int) string {{
+\tswitch x {{
+\tcase 1:
+\t\treturn "one"
+\tcase 2:
+\t\treturn "two"
+\tcase 1:
+\t\treturn "one again"
+\t}}
+\treturn fmt.Sprintf("%d", x)
+}}
+
+func Check{n}(items []string) bool {{
+\tif len(items) >= 0 {{
+\t\treturn true
+\t}}
+\tos.Exit(1)
+\treturn false
+}}
+"""
+
+_FILLER_TEMPLATE = """
+func Filler{n}_{i}(values []int) int {{
+\ttotal := 0
+\tfor _, v := range values {{
+\t\tif v > {i} {{
+\t\t\ttotal += v
+\t\t}} else if v < 0 {{
+\t\t\ttotal -= v
+\t\t}}
+\t}}
+\treturn total
+}}
+"""
+
+
+def synthetic_source(pkg: str, n: int, *, fillers: int = 20) -> str:
+    """One smelly file of package ``pkg`` with ``fillers`` extra functions."""
+    return _FILE_TEMPLATE.format(pkg=pkg, n=n) + "".join(
+        _FILLER_TEMPLATE.format(n=n, i=i) for i in range(fillers)
+    )
+
+
+def make_synthetic_tree(
+    root: Path, *, packages: int = 500, files_per_package: int = 4, fillers: int = 20
+) -> Path:
+    """Write a ``go.mod`` plus ``packages`` packages of smelly files under ``root``."""
+    root.mkdir(parents=True, exist_ok=True)
+    (root / "go.mod").write_text("module example.com/bench\n\ngo 1.22\n")
+    for p in range(packages):
+        pkg = f"pkg{p:04d}"
+        pkg_dir = root / "internal" / pkg
+        pkg_dir.mkdir(parents=True, exist_ok=True)
+        for f in range(files_per_package):
+            source = synthetic_source(pkg, p * files_per_package + f, fillers=fillers)
+            (pkg_dir / f"file{f}.go").write_text(source)
+    return root
+
+
+def time_detect(root: Path, *, jobs: int) -> tuple[float, list[dict]]:
+    """Seconds taken by one ``detect_smells`` run, and its entries."""
+    started = time.perf_counter()
+    entries, _ = detect_smells(root, jobs=jobs)
+    return time.perf_counter() - started, entries
+
+
+def rss_mb() -> float:
+    """Current resident set size in MB (peak RSS where /proc is unavailable)."""
+    try:
+        with open("/proc/self/statm") as handle:
+            pages = int(handle.read().split()[1])
+        return pages * os.sysconf("SC_PAGE_SIZE") / (1024 * 1024)
+    except (OSError, ValueError, IndexError):
+        peak = resource.getrusage(resource.RUSAGE_SELF).ru_maxrss
+        return peak / (1024 * 1024) if sys.platform == "darwin" else peak / 1024
+
+
+def peak_rss_during(fn, *, interval: float = 0.02) -> tuple[float, object]:
+    """(highest RSS checkpoint in MB, result) while ``fn()`` runs."""
+    peak = rss_mb()
+    done = threading.Event()
+
+    def sample() -> None:
+        nonlocal peak
+        while not done.wait(interval):
+            peak = max(peak, rss_mb())
+
+    sampler = threading.Thread(target=sample, daemon=True)
+    sampler.start()
+    try:
+        result = fn()
+    finally:
+        done.set()
+        sampler.join()
+    return max(peak, rss_mb()), result
+
+
+def count_traversals(
+    paths: list[Path], *, shared: bool, custom_rules: tuple[CustomRule, ...] = ()
+) -> tuple[int, int, float, dict[str, list]]:
+    """(parses, keyword walks, seconds, matches) over ``paths``.
+
+    Every rule runs, opt-in and ``custom_rules`` included. ``shared`` parses
+    each file once and hands that ``Pass`` to every rule; otherwise each rule
+    parses the file itself, as before the inspector existed.
+    """
+    checks = _enabled_checks({s["id"] for s in SMELL_CHECKS}, custom_rules=custom_rules)
+    inspector = _build_inspector({s["id"] for s in checks}, custom_rules)
+    runs: list[Inspector] = [inspector] if shared else inspector.split()
+    packages: dict[Path, list[tuple[str, str]]] = {}
+    for path in paths:
+        packages.setdefault(path.parent, []).append((str(path), path.read_text()))
+    smell_counts: dict[str, list] = {s["id"]: [] for s in checks}
+    parses_before = GoFile.parses
+    started = time.perf_counter()
+    for contents in packages.values():
+        for run in runs:
+            files = [GoFile(path, content) for path, content in contents]
+            types = PackageTypes(tuple(files))
+            for source in files:
+                run.run(source, smell_counts, types=types)
+    seconds = time.perf_counter() - started
+    parses = GoFile.parses - parses_before
+    return parses, sum(run.walks for run in runs), seconds, smell_counts
+
+
+def _report_traversals(directory: Path) -> int:
+    paths = sorted(directory.glob("*.go"))
+    shared = count_traversals(paths, shared=True)
+    isolated = count_traversals(paths, shared=False)
+    print(f"{len(paths)} fixture files in {directory}")
+    for label, (parses, walks, seconds, _counts) in (
+        ("per-rule", isolated),
+        ("shared", shared),
+    ):
+        print(f"  {label:<12} {parses:5d} parses {walks:5d} walks {seconds:7.3f}s")
+    if shared[3] != isolated[3]:
+        print("  output differs between dispatch modes")
+        return 1
+    return 0
+
+
+def _check_rss(packages: int, files_per_package: int, ceiling: float) -> int:
+    with tempfile.TemporaryDirectory(prefix="desloppify-bench-") as tmp:
+        root = make_synthetic_tree(
+            Path(tmp), packages=packages, files_per_package=files_per_package, fillers=0
+        )
+        with (
+            runtime_scope(RuntimeContext(project_root=root)),
+            contextlib.chdir(root),
+        ):
+            peak, (entries, total) = peak_rss_during(lambda: detect_smells(root, jobs=1))
+    print(f"{total} files, {sum(e['count'] for e in entries)} matches, peak RSS {peak:.0f} MB")
+    if peak > ceiling:
+        print(f"  exceeds the {ceiling:.0f} MB ceiling")
+        return 1
+    return 0
+
+
+def main(argv: list[str] | None = None) -> int:
+    parser = argparse.ArgumentParser(description=__doc__.splitlines()[0])
+    parser.add_argument("--packages", type=int, default=500)
+    parser.add_argument("--files-per-package", type=int, default=4)
+    parser.add_argument(
+        "--jobs",
+        type=int,
+        action="append",
+        default=None,
+        help="Worker counts to compare (repeatable; default: 1 and the default pool)",
+    )
+    parser.add_argument(
+        "--traversals",
+        action="store_true",
+        help="Compare shared and per-rule parse and walk counts on the Go fixtures",
+    )
+    parser.add_argument(
+        "--rss-ceiling",
+        type=float,
+        default=None,
+        metavar="MB",
+        help="Scan once at --jobs 1 and fail if resident memory exceeds MB",
+    )
+    args = parser.parse_args(argv)
+    if args.traversals:
+        return _report_traversals(FIXTURES_DIR)
+    if args.rss_ceiling is not None:
+        return _check_rss(args.packages, args.files_per_package, args.rss_ceiling)
+    job_counts = args.jobs or sorted({1, default_jobs()})
+
+    with tempfile.TemporaryDirectory(prefix="desloppify-bench-") as tmp:
+        root = make_synthetic_tree(
+            Path(tmp), packages=args.packages, files_per_package=args.files_per_package
+        )
+        with (
+            runtime_scope(RuntimeContext(project_root=root)),
+            contextlib.chdir(root),
+        ):
+            results = [(jobs, *time_detect(root, jobs=jobs)) for jobs in job_counts]
+
+    baseline = results[0][1]
+    print(f"{args.packages} packages x {args.files_per_package} files")
+    for jobs, seconds, _entries in results:
+        print(f"  jobs={jobs:<3} {seconds:7.2f}s  x{baseline / seconds:4.1f}")
+    if any(entries != results[0][2] for _jobs, _seconds, entries in results):
+        print("  output differs between job counts")
+        return 1
+    return 0
+
+
+if __name__ == "__main__":
+    raise SystemExit(main())
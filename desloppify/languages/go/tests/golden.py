@@ -0,0 +1,278 @@
+"""Golden checks for Go rules, driven by expectations written in the fixtures.
+
+A fixture marks each line a rule should flag with a trailing comment, in the
+spirit of x/tools' analysistest::
+
+    if nil == err { // want yoda_condition "Yoda"
+
+One comment may list several ``rule "message substring"`` pairs. Findings
+that belong to a package rather than a line (``god_package``) are expected
+in the file header, before the ``package`` clause::
+
+    // want-package god_package "generic name"
+
+``check`` runs every enabled rule over the fixture module, plus the
+``custom_rules`` in a ``config.json`` at its root (same layout as
+``.desloppify/config.json``) if it has one, and reports
+expectations nothing matched and findings nothing expected; a fixture in
+``GOLDEN_FILES`` without any ``want`` asserts that it is clean. For an
+intentional change, ``python -m desloppify.languages.go.tests.golden -update``
+rewrites the expectations from the actual findings.
+"""
+
+from __future__ import annotations
+
+import argparse
+import re
+import sys
+from collections import Counter
+from dataclasses import dataclass
+from pathlib import Path
+
+from desloppify.core._internal.text_utils import get_project_root
+from desloppify.languages import get_lang
+from desloppify.languages._framework.runtime import make_lang_run
+from desloppify.languages.go.detectors.custom_rules import load_custom_rules
+from desloppify.languages.go.detectors.security import detect_go_security
+from desloppify.languages.go.detectors.smells import detect_smells
+from desloppify.languages.go.extractors import find_go_files
+from desloppify.languages.go.phases import _detect_god_packages
+
+FIXTURES_DIR = Path(__file__).resolve().parents[3] / "tests" / "fixtures" / "go"
+
+# Fixtures whose findings are pinned by their ``want`` comments.
+GOLDEN_FILES = (
+    "atomics/atomics.go",
+    "bad_concurrency.go",
+    "busyselect/busyselect.go",
+    "channels/channels.go",
+    "composites/composites.go",
+    "deadstores/deadstores.go",
+    "deferrors/deferrors.go",
+    "durations/durations.go",
+    "errorlast/errorlast.go",
+    "errorsas/errorsas.go",
+    "getters/getters.go",
+    "god_package/imaging/draw.go",
+    "god_package/string_utils/string_utils.go",
+    "god_package/utils.go",
+    "god_package/utilsHelpers/helpers.go",
+    "heldlocks/heldlocks.go",
+    "good.go",
+    "imports/codecs/codecs.go",
+    "imports/debug/debug.go",
+    "imports/imports.go",
+    "ineffectivebreak/ineffectivebreak.go",
+    "jsontags/jsontags.go",
+    "lazyinit/lazyinit.go",
+    "loggederr/loggederr.go",
+    "longfunc/longfunc.go",
+    "loopctx/loopctx.go",
+    "loops/loops.go",
+    "messagenames/orders.go",
+    "messagenames/store.go",
+    "musts/musts.go",
+    "mutexes/mutexes.go",
+    "naming/naming.go",
+    "nilchecks/nilchecks.go",
+    "panicreach/reach.go",
+    "overlap/overlap.go",
+    "paramgroups/dial.go",
+    "paramgroups/probe.go",
+    "printf/printf.go",
+    "redundantcontinue/redundantcontinue.go",
+    "resources/resources.go",
+    "sendclose/sendclose.go",
+    "shadows/shadows.go",
+    "smallptr/smallptr.go",
+    "smells.go",
+    "smells_lib.go",
+    "sqlrows/sqlrows.go",
+    "structsprintf/structsprintf.go",
+    "timelayout/timelayout.go",
+    "timevalues/timevalues.go",
+    "unusedfields/unusedfields.go",
+)
+
+# A module whose config declares custom rules, one fixture file per kind.
+CUSTOM_RULES_DIR = FIXTURES_DIR.parent / "go_custom_rules"
+CUSTOM_RULES_FILES = (
+    "internal/clock/clock.go",
+    "internal/store/store.go",
+    "legacy.go",
+    "names.go",
+)
+
+_PAIR = r'[\w-]+\s+"(?:[^"\\]|\\.)*"'
+_WANT_RE = re.compile(rf"[ \t]*//[ \t]*want[ \t]+((?:{_PAIR}[ \t]*)+)$")
+_WANT_PACKAGE_RE = re.compile(rf"^//[ \t]*want-package[ \t]+((?:{_PAIR}[ \t]*)+)$")
+_PAIR_RE = re.compile(r'([\w-]+)\s+"((?:[^"\\]|\\.)*)"')
+_PACKAGE_RE = re.compile(r"^package[ \t]+\w+")
+
+
+@dataclass(frozen=True)
+class Finding:
+    """One actual finding; ``line`` is None for package-level findings."""
+
+    file: str
+    line: int | None
+    rule: str
+    message: str
+
+
+@dataclass(frozen=True)
+class Want:
+    file: str
+    line: int | None
+    rule: str
+    substring: str
+
+    def matches(self, finding: Finding) -> bool:
+        return (
+            (finding.file, finding.line, finding.rule) == (self.file, self.line, self.rule)
+            and self.substring in finding.message
+        )
+
+
+def _pairs(text: str) -> list[tuple[str, str]]:
+    return [
+        (rule, re.sub(r"\\(.)", r"\1", message)) for rule, message in _PAIR_RE.findall(text)
+    ]
+
+
+def _quote(message: str) -> str:
+    return '"' + message.replace("\\", "\\\\").replace('"', '\\"') + '"'
+
+
+def parse_wants(file: str, content: str) -> list[Want]:
+    """The expectations written in one fixture's comments."""
+    wants: list[Want] = []
+    in_header = True
+    for lineno, line in enumerate(content.splitlines(), 1):
+        if in_header and _PACKAGE_RE.match(line):
+            in_header = False
+        if in_header and (m := _WANT_PACKAGE_RE.match(line.strip())):
+            wants += [Want(file, None, rule, text) for rule, text in _pairs(m.group(1))]
+        elif m := _WANT_RE.search(line):
+            wants += [Want(file, lineno, rule, text) for rule, text in _pairs(m.group(1))]
+    return wants
+
+
+def collect_findings(root: Path) -> list[Finding]:
+    """Every finding the enabled Go rules report under ``root``."""
+    found: list[Finding] = []
+    smells, _ = detect_smells(root, custom_rules=load_custom_rules(root / "config.json"))
+    for entry in smells:
+        if len(entry["matches"]) < entry["count"]:
+            raise RuntimeError(f"{entry['id']}: match sample truncated; split the fixture")
+        found += [
+            Finding(_rel(root, m["file"]), m["line"], entry["id"], entry["label"])
+            for m in entry["matches"]
+        ]
+    security, _ = detect_go_security(find_go_files(root), zone_map=None)
+    found += [
+        Finding(_rel(root, e["file"]), e["detail"]["line"], e["detail"]["kind"], e["summary"])
+        for e in security
+    ]
+    found += [
+        Finding(_rel(root, e["file"]), None, "god_package", e["summary"])
+        for e in _detect_god_packages(root, make_lang_run(get_lang("go")))
+    ]
+    return found
+
+
+def _rel(root: Path, file: str) -> str:
+    path = Path(file)
+    if not path.is_absolute():
+        path = get_project_root() / path
+    return path.resolve().relative_to(root).as_posix()
+
+
+def check(root: Path = FIXTURES_DIR, files: tuple[str, ...] = GOLDEN_FILES) -> list[str]:
+    """Problems with the golden fixtures: unmet expectations and unexpected findings."""
+    root = root.resolve()
+    actual = [f for f in collect_findings(root) if f.file in files]
+    problems: list[tuple[str, int, str]] = []
+    for file in files:
+        for want in parse_wants(file, (root / file).read_text()):
+            hit = next((f for f in actual if want.matches(f)), None)
+            if hit is None:
+                where = f"{file}:{want.line}" if want.line else f"{file} (package)"
+                message = f"{where}: no {want.rule} finding matching {want.substring!r}"
+                problems.append((file, want.line or 0, message))
+            else:
+                actual.remove(hit)
+    for f in actual:
+        where = f"{f.file}:{f.line}" if f.line else f"{f.file} (package)"
+        problems.append((f.file, f.line or 0, f"{where}: unexpected {f.rule} finding: {f.message}"))
+    return [message for _file, _line, message in sorted(problems)]
+
+
+def update(root: Path = FIXTURES_DIR, files: tuple[str, ...] = GOLDEN_FILES) -> list[str]:
+    """Rewrite the ``want`` comments in ``files`` from actual findings; returns changed files."""
+    root = root.resolve()
+    by_file: dict[str, list[Finding]] = {}
+    for finding in collect_findings(root):
+        by_file.setdefault(finding.file, []).append(finding)
+    changed: list[str] = []
+    for file in files:
+        path = root / file
+        old = path.read_text()
+        new = _rewrite(old, by_file.get(file, []))
+        if new != old:
+            path.write_text(new)
+            changed.append(file)
+    return changed
+
+
+def _rewrite(content: str, findings: list[Finding]) -> str:
+    per_line: dict[int | None, Counter[tuple[str, str]]] = {}
+    for f in findings:
+        per_line.setdefault(f.line, Counter())[(f.rule, f.message)] += 1
+    out: list[str] = []
+    package_at = None
+    for lineno, line in enumerate(content.splitlines(), 1):
+        if package_at is None and _WANT_PACKAGE_RE.match(line.strip()):
+            continue
+        if package_at is None and _PACKAGE_RE.match(line):
+            package_at = len(out)
+        line = _WANT_RE.sub("", line)
+        if lineno in per_line:
+            line += " // want " + " ".join(
+                f"{rule} {_quote(message)}"
+                for (rule, message), count in sorted(per_line[lineno].items())
+                for _ in range(count)
+            )
+        out.append(line)
+    header = [
+        f"// want-package {rule} {_quote(message)}"
+        for (rule, message), count in sorted(per_line.get(None, Counter()).items())
+        for _ in range(count)
+    ]
+    out[package_at or 0 : package_at or 0] = header
+    return "\n".join(out) + ("\n" if content.endswith("\n") else "")
+
+
+def main(argv: list[str] | None = None) -> int:
+    parser = argparse.ArgumentParser(description=__doc__.splitlines()[0])
+    parser.add_argument(
+        "-update",
+        "--update",
+        action="store_true",
+        help="rewrite the want comments from actual findings",
+    )
+    args = parser.parse_args(argv)
+    suites = ((FIXTURES_DIR, GOLDEN_FILES), (CUSTOM_RULES_DIR, CUSTOM_RULES_FILES))
+    if args.update:
+        for root, files in suites:
+            for file in update(root, files):
+                print(f"updated {file}")
+        return 0
+    problems = [problem for root, files in suites for problem in check(root, files)]
+    for problem in problems:
+        print(problem, file=sys.stderr)
+    return 1 if problems else 0
+
+
+if __name__ == "__main__":
+    raise SystemExit(main())
@@ -0,0 +1,106 @@
+"""Tests for honoring Go build constraints during file discovery.
+
+The fixture module under desloppify/tests/fixtures/go_build_tags/ has files
+that only build on Linux (``//go:build linux``), off Linux, on Darwin (by
+file name), outside Windows and Plan 9 (legacy ``// +build``), with the
+``integration`` tag, and never (``//go:build ignore``).
+"""
+
+from __future__ import annotations
+
+import shutil
+import subprocess
+from pathlib import Path
+from types import SimpleNamespace
+
+import pytest
+
+from desloppify.app.commands.scan.scan_patterns import apply_build_tags
+from desloppify.core.runtime_state import RuntimeContext, runtime_scope
+from desloppify.file_discovery import get_build_tags, set_build_tags
+from desloppify.languages import get_lang
+from desloppify.languages._framework.runtime import make_lang_run
+from desloppify.languages.go.build_constraints import (
+    BuildContext,
+    build_constraint,
+    evaluate,
+)
+from desloppify.languages.go.extractors import find_go_files
+
+FIXTURE = Path(__file__).resolve().parents[3] / "tests" / "fixtures" / "go_build_tags"
+
+
+@pytest.fixture()
+def build_tags_module(monkeypatch):
+    for name in ("GOOS", "GOARCH", "CGO_ENABLED"):
+        monkeypatch.delenv(name, raising=False)
+    with runtime_scope(RuntimeContext(project_root=FIXTURE)):
+        yield FIXTURE
+
+
+def _context(goos: str, *tags: str) -> BuildContext:
+    return BuildContext(goos, "amd64", frozenset({goos, "amd64", *tags}))
+
+
+def test_evaluate_follows_go_build_precedence():
+    tags = {"linux", "amd64"}.__contains__
+    assert evaluate("linux && amd64", tags)
+    assert evaluate("darwin || linux && !arm64", tags)
+    assert not evaluate("(darwin || linux) && arm64", tags)
+    assert evaluate("go1.21 && !windows", _context("linux").has_tag)
+    assert not evaluate("linux &&", tags)
+    assert not evaluate("(linux", tags)
+
+
+def test_build_constraint_reads_only_the_header():
+    assert build_constraint("//go:build linux\n\npackage x\n") == "linux"
+    assert build_constraint("/* doc */\n// +build a,b c\n// +build d\n\npackage x\n") == (
+        "((a && b) || (c)) && ((d))"
+    )
+    assert build_constraint("package x\n\n//go:build linux\n") is None
+
+
+def test_file_name_suffixes_select_a_platform():
+    linux = _context("linux")
+    assert linux.matches_filename("dir/x_linux.go")
+    assert linux.matches_filename("x_linux_amd64_test.go")
+    assert not linux.matches_filename("x_windows.go")
+    assert not linux.matches_filename("x_linux_arm64.go")
+    assert linux.matches_filename("linux.go")
+    assert linux.matches_filename("x_unix.go")
+    assert _context("android", "linux").matches_filename("x_linux.go")
+
+
+def test_files_excluded_by_active_platform_are_skipped(build_tags_module, monkeypatch):
+    monkeypatch.setenv("GOOS", "darwin")
+    assert find_go_files(FIXTURE) == ["legacy.go", "main.go", "other.go", "sysconf_darwin.go"]
+    monkeypatch.setenv("GOOS", "linux")
+    assert find_go_files(FIXTURE) == ["legacy.go", "main.go", "netlink.go"]
+    monkeypatch.setenv("GOOS", "windows")
+    assert find_go_files(FIXTURE) == ["main.go", "other.go"]
+
+
+def test_tags_flag_includes_tagged_files(build_tags_module, monkeypatch):
+    monkeypatch.setenv("GOOS", "linux")
+    lang = make_lang_run(get_lang("go"))
+    apply_build_tags(SimpleNamespace(tags="integration"), lang)
+    assert get_build_tags() == ("integration",)
+    assert lang.runtime_option("build_tags") == "integration"
+    assert "integration_test.go" in find_go_files(FIXTURE)
+    set_build_tags([])
+    assert "integration_test.go" not in find_go_files(FIXTURE)
+
+
+@pytest.mark.skipif(shutil.which("go") is None, reason="Go toolchain not installed")
+@pytest.mark.parametrize("goos", ["linux", "darwin", "windows"])
+def test_discovery_agrees_with_go_list(build_tags_module, monkeypatch, goos):
+    monkeypatch.setenv("GOOS", goos)
+    set_build_tags(["integration"])
+    listed = subprocess.run(
+        ["go", "list", "-tags", "integration", "-f", "{{.GoFiles}} {{.TestGoFiles}}", "."],
+        cwd=FIXTURE,
+        capture_output=True,
+        text=True,
+        check=True,
+    ).stdout
+    assert find_go_files(FIXTURE) == sorted(listed.replace("[", " ").replace("]", " ").split())
@@ -0,0 +1,106 @@
+"""Tests for config-declared Go rules (``languages.go.custom_rules``).
+
+The golden fixture module under desloppify/tests/fixtures/go_custom_rules/
+covers each rule kind; these tests cover validation and the registry.
+"""
+
+from __future__ import annotations
+
+import json
+from types import SimpleNamespace
+
+import pytest
+
+from desloppify.app.commands.langs import cmd_langs
+from desloppify.core.config import ConfigError
+from desloppify.languages.go.detectors._inspector import GoFile
+from desloppify.languages.go.detectors.custom_rules import (
+    check_source,
+    load_custom_rules,
+    parse_custom_rules,
+)
+from desloppify.languages.go.tests.golden import (
+    CUSTOM_RULES_DIR,
+    CUSTOM_RULES_FILES,
+    check,
+)
+
+_RULE = {"id": "no_now", "kind": "forbid-call", "call": "time.Now", "message": "use clock"}
+
+
+def _write(tmp_path, rules) -> object:
+    path = tmp_path / "config.json"
+    path.write_text(json.dumps({"languages": {"go": {"custom_rules": rules}}}, indent=2))
+    return path
+
+
+def test_custom_rule_fixtures_match_their_want_comments():
+    assert check(CUSTOM_RULES_DIR, CUSTOM_RULES_FILES) == []
+
+
+@pytest.mark.parametrize(
+    ("change", "field", "problem"),
+    [
+        ({"kind": "forbid-cal"}, ".kind", "unknown kind 'forbid-cal'"),
+        ({"call": "Now"}, ".call", "expected <import path>.<Func>"),
+        ({"severity": "urgent"}, ".severity", "expected one of low, medium, high"),
+        ({"id": "panic_in_lib"}, ".id", "'panic_in_lib' is already defined"),
+        ({"unless": ["x"]}, ".unless", "unknown field for forbid-call"),
+        ({"kind": "forbid-identifier", "call": None, "pattern": "("}, ".pattern", "invalid regex"),
+    ],
+)
+def test_validation_errors_point_at_the_config_line(tmp_path, change, field, problem):
+    rule = {k: v for k, v in {**_RULE, **change}.items() if v is not None}
+    path = _write(tmp_path, [_RULE | {"id": "first"}, rule])
+    lines = path.read_text().splitlines()
+    second_rule = [i for i, text in enumerate(lines, 1) if text == " " * 8 + "{"][1]
+    line = next(
+        i for i, text in enumerate(lines, 1) if i > second_rule and f'"{field[1:]}"' in text
+    )
+    with pytest.raises(ConfigError) as exc:
+        load_custom_rules(path)
+    message = str(exc.value)
+    assert f"config.json:{line}: custom_rules[1]{field}: {problem}" in message
+
+
+def test_missing_field_points_at_the_rule(tmp_path):
+    path = _write(tmp_path, [{"id": "no_now", "kind": "forbid-import", "message": "m"}])
+    with pytest.raises(ConfigError, match=r"config.json:5: custom_rules\[0\]: 'import' is required"):
+        load_custom_rules(path)
+
+
+def test_forbid_call_follows_import_aliases_and_skips_shadowed_names():
+    rules = parse_custom_rules([_RULE])
+    aliased = GoFile("a.go", 'package a\n\nimport t "time"\n\nvar x = t.Now()\n')
+    assert [m["line"] for m in check_source(rules, aliased)["no_now"]] == [5]
+    not_imported = GoFile("b.go", "package b\n\nfunc f(time clock) { time.Now() }\n")
+    assert check_source(rules, not_imported) == {}
+
+
+def test_pairing_needs_the_same_receiver_within_the_function():
+    rules = parse_custom_rules(
+        [
+            {
+                "id": "lock_pair",
+                "kind": "required-call-pairing",
+                "call": "*.Lock",
+                "requires": "*.Unlock",
+                "message": "unpaired",
+            }
+        ]
+    )
+    source = GoFile(
+        "c.go",
+        "package c\n\nfunc a() {\n\tmu.Lock()\n\tgo func() { mu.Unlock() }()\n}\n\n"
+        "func b() {\n\tmu.Lock()\n\tother.Unlock()\n}\n\nfunc c() {\n\tmu.Unlock()\n}\n",
+    )
+    assert [m["line"] for m in check_source(rules, source)["lock_pair"]] == [9]
+
+
+def test_custom_rules_are_listed_with_the_builtin_rules(tmp_path, monkeypatch, capsys):
+    path = _write(tmp_path, [_RULE])
+    monkeypatch.setattr("desloppify.languages.go.detectors.custom_rules.CONFIG_FILE", path)
+    cmd_langs(SimpleNamespace(rules=True, lang="go"))
+    out = capsys.readouterr().out
+    assert "panic_in_lib" in out
+    assert "no_now" in out and "medium, custom" in out
@@ -0,0 +1,241 @@
+"""Tests for the Go smell fixers, their dry-run diff and edit conflicts.
+
+The fixture module under desloppify/tests/fixtures/go_fix/ holds one file
+per fixable rule next to its golden ``.patch`` (the diff ``fix --dry-run``
+prints for that rule alone), conflict.go, where a yoda fix and a strconv
+fix overlap on one line, and commented.go, where fixable smells sit among
+comments.
+"""
+
+from __future__ import annotations
+
+import difflib
+import shutil
+import subprocess
+from collections import Counter
+from pathlib import Path
+
+import pytest
+
+from desloppify.core.runtime_state import RuntimeContext, runtime_scope
+from desloppify.languages import get_lang
+from desloppify.languages._framework.fixers import (
+    Edit,
+    apply_edit_fixer,
+    apply_edits,
+    line_edit,
+)
+from desloppify.languages.go.detectors._source import go_comments
+from desloppify.languages.go.fixers.smell_edits import resolve_smell_fixes
+
+FIXTURE = Path(__file__).resolve().parents[3] / "tests" / "fixtures" / "go_fix"
+
+
+@pytest.fixture()
+def fix_module(tmp_path, monkeypatch):
+    for source in FIXTURE.iterdir():
+        if source.suffix in (".go", ".mod"):
+            shutil.copy(source, tmp_path / source.name)
+    monkeypatch.chdir(tmp_path)
+    with runtime_scope(RuntimeContext(project_root=tmp_path)):
+        yield tmp_path
+
+
+def _run(path: Path, *, dry_run: bool, fixer_name: str = "yoda-conditions") -> list[dict]:
+    fixer = get_lang("go").fixers[fixer_name]
+    entries = [e for e in fixer.detect(path) if e["file"].endswith("yoda.go")]
+    return fixer.fix(entries, dry_run=dry_run).entries
+
+
+def test_dry_run_diff_matches_golden_patch(fix_module):
+    original = (fix_module / "yoda.go").read_text()
+    results = _run(fix_module, dry_run=True)
+    assert [r["file"] for r in results] == ["yoda.go"]
+    assert results[0]["removed"] == ["yoda_condition"] * 4
+    assert results[0]["diff"] == (FIXTURE / "yoda.go.patch").read_text()
+    assert (fix_module / "yoda.go").read_text() == original
+
+
+def test_real_run_writes_what_the_dry_run_showed(fix_module):
+    results = _run(fix_module, dry_run=False)
+    assert "diff" not in results[0]
+    content = (fix_module / "yoda.go").read_text()
+    assert "if err != nil {" in content
+    assert "if n > 0 && name == \"admin\" {" in content
+    assert "if len(name) <= 10 {" in content
+    assert _run(fix_module, dry_run=True) == []
+
+
+def test_overlapping_edits_wait_for_the_next_pass():
+    content = 'if "a" == f(x) {'
+    yoda = Edit(3, 14, 'f(x) == "a"', "yoda_condition")
+    inner = Edit(10, 14, "g(x)", "sprintf_strconv")
+    updated, applied, conflicting = apply_edits(content, [inner, yoda, yoda])
+    assert updated == 'if f(x) == "a" {'
+    assert (applied, conflicting) == ([yoda], [inner])
+
+
+def test_overlapping_fixes_from_two_rules_reach_a_clean_fixpoint(fix_module, capsys):
+    fixer = get_lang("go").fixers["smells"]
+    entries = [e for e in fixer.detect(fix_module) if e["file"].endswith("conflict.go")]
+    assert {e["smell"] for e in entries} == {"yoda_condition", "sprintf_strconv"}
+
+    raw = fixer.fix(entries, dry_run=False)
+    assert raw.skip_reasons == {}
+    [result] = raw.entries
+    assert result["passes"] == 2
+    assert sorted(result["removed"]) == [
+        "sprintf_strconv",
+        "sprintf_strconv",
+        "yoda_condition",
+    ]
+    content = (fix_module / "conflict.go").read_text()
+    assert "\tif strconv.FormatBool(ok) == \"true\" {\n" in content
+    assert "\t\treturn strconv.FormatBool(ok)\n" in content
+    assert [e for e in fixer.detect(fix_module) if e["file"].endswith("conflict.go")] == []
+
+    if shutil.which("gofmt") is not None:
+        check = subprocess.run(
+            ["gofmt", "-e", "-l", "conflict.go"], capture_output=True, text=True
+        )
+        assert (check.returncode, check.stdout, check.stderr) == (0, "", "")
+
+
+def test_edits_still_conflicting_at_the_pass_cap_are_skipped_with_a_warning(
+    fix_module, capsys
+):
+    # Two rules that always insert at the same offset: one lands per pass.
+    def find_edits(_filepath, _content):
+        return [Edit(0, 0, "// a\n", "rule_a"), Edit(0, 0, "// b\n", "rule_b")]
+
+    raw = apply_edit_fixer([{"file": "yoda.go"}], find_edits, max_passes=3)
+    [result] = raw.entries
+    assert (result["passes"], result["removed"]) == (3, ["rule_a"] * 3)
+    assert raw.skip_reasons == {"conflicting_edit": 2}
+    assert "Skip 2 conflicting edit(s) in yoda.go after 3 pass(es)" in capsys.readouterr().err
+    assert (fix_module / "yoda.go").read_text().startswith("// a\n// a\n// a\npackage main")
+
+
+GOLDEN = [
+    ("yoda.go", "yoda_condition"),
+    ("else_return.go", "else_after_return"),
+    ("bool_return.go", "bool_literal_return"),
+    ("wraperr.go", "error_not_wrapped"),
+]
+
+
+def _rule_entries(path: Path, filename: str, rule: str) -> list[dict]:
+    fixer = get_lang("go").fixers["smells"]
+    return [
+        e for e in fixer.detect(path) if e["file"].endswith(filename) and e["smell"] == rule
+    ]
+
+
+@pytest.mark.parametrize("filename,rule", GOLDEN)
+def test_each_fixable_rule_matches_its_golden_patch(fix_module, filename, rule):
+    fixer = get_lang("go").fixers["smells"]
+    entries = _rule_entries(fix_module, filename, rule)
+    assert entries
+    [result] = fixer.fix(entries, dry_run=True).entries
+    assert set(result["removed"]) == {rule}
+    assert result["diff"] == (FIXTURE / f"{filename}.patch").read_text()
+
+    fixer.fix(entries, dry_run=False)
+    assert _rule_entries(fix_module, filename, rule) == []
+    if shutil.which("gofmt") is not None:
+        check = subprocess.run(
+            ["gofmt", "-e", "-l", filename], capture_output=True, text=True
+        )
+        assert (check.returncode, check.stdout, check.stderr) == (0, "", "")
+
+
+def test_unsafe_rewrites_are_reported_without_a_fix(fix_module):
+    entries = get_lang("go").fixers["smells"].detect(fix_module)
+    fixed_lines = {(Path(e["file"]).name, e["line"]) for e in entries}
+    # `if x := ...; err != nil` scopes x to the else; `name :=` would move out.
+    assert ("else_return.go", 21) not in fixed_lines
+    assert ("else_return.go", 29) not in fixed_lines
+    # %+v carries flags that %w does not take.
+    assert ("wraperr.go", 14) not in fixed_lines
+
+
+def test_fixes_that_break_the_source_are_not_written(fix_module, capsys):
+    def find_edits(_filepath, content):
+        if "package main {" in content:
+            return []
+        return [line_edit(content, 1, "package main", "package main {", "broken")]
+
+    raw = apply_edit_fixer(
+        [{"file": "yoda.go"}],
+        find_edits,
+        format_fn=lambda content: None,
+    )
+    assert raw.entries == []
+    assert raw.skip_reasons == {"unformattable": 1}
+    assert (fix_module / "yoda.go").read_text().startswith("package main\n")
+
+
+def test_multi_line_fix_must_start_on_its_line():
+    content = "a {\n} else {\n\tb\n}\n"
+    assert line_edit(content, 2, "} else {\n\tb\n}", "}\nb", "r") == Edit(
+        4, 17, "}\nb", "r"
+    )
+    assert line_edit(content, 1, "} else {", "}", "r") is None
+
+
+def test_applied_fixes_are_recorded_on_the_aggregated_finding():
+    finding = {
+        "detector": "smells",
+        "status": "open",
+        "note": None,
+        "detail": {
+            "smell_id": "yoda_condition",
+            "count": 3,
+            "matches": [{"file": "a.go", "line": 3}, {"file": "b.go", "line": 9}],
+        },
+    }
+    state = {"findings": {"smells::a.go::go_smell::yoda_condition": finding}}
+    results = [{"file": "a.go", "removed": ["yoda_condition", "yoda_condition"]}]
+
+    assert resolve_smell_fixes(state, results, "smells") == []
+    assert finding["detail"]["count"] == 1
+    assert finding["detail"]["matches"] == [{"file": "b.go", "line": 9}]
+    assert finding["detail"]["fixed"] == [
+        {"file": "a.go", "count": 2, "by": "desloppify fix smells"}
+    ]
+
+    results = [{"file": "b.go", "removed": ["yoda_condition"]}]
+    assert resolve_smell_fixes(state, results, "smells") == [
+        "smells::a.go::go_smell::yoda_condition"
+    ]
+    assert finding["status"] == "fixed"
+
+
+def _changed_lines(before: str, after: str) -> set[int]:
+    matcher = difflib.SequenceMatcher(None, before.splitlines(), after.splitlines())
+    return {
+        line + 1
+        for tag, i1, i2, _j1, _j2 in matcher.get_opcodes()
+        if tag != "equal"
+        for line in range(i1, i2)
+    }
+
+
+@pytest.mark.parametrize("gofmt_clean", [True, False])
+def test_fixes_keep_comments_and_leave_other_lines_byte_identical(fix_module, gofmt_clean):
+    path = fix_module / "commented.go"
+    if not gofmt_clean:
+        # Misaligned trailing comments that gofmt would realign.
+        path.write_text(path.read_text().replace("minSize = 1    //", "minSize = 1 //"))
+    before = path.read_text()
+    fixer = get_lang("go").fixers["smells"]
+    entries = [e for e in fixer.detect(fix_module) if e["file"].endswith("commented.go")]
+    # Smells with a comment in the way (lines 23, 38, 55) come without a fix.
+    assert {e["line"] for e in entries} == {27, 29, 47, 63}
+    assert fixer.fix(entries, dry_run=False).skip_reasons == {}
+
+    after = path.read_text()
+    assert Counter(go_comments(after)) == Counter(go_comments(before))
+    # The yoda and %w lines, describe's else block, isSmall's if/return.
+    assert _changed_lines(before, after) == {27, 29, 47, 48, 49, 63, 64, 65, 66}
+    assert ("minSize = 1    //" in after) is gofmt_clean
@@ -0,0 +1,89 @@
+"""Golden tests: the Go fixtures' ``// want`` comments against actual findings."""
+
+from __future__ import annotations
+
+import shutil
+
+from desloppify.languages.go.tests.golden import (
+    FIXTURES_DIR,
+    GOLDEN_FILES,
+    Want,
+    check,
+    parse_wants,
+    update,
+)
+
+_MODULE = "module example.com/golden\n\ngo 1.21\n"
+_SOURCE = """package main
+
+func check(x int) bool {
+\tif 42 == x { // want yoda_condition "Yoda" bool_literal_return "stale"
+\t\treturn true
+\t}
+\treturn false
+}
+
+func pending() { // want todo_fixme "TODO"
+}
+"""
+
+
+def test_golden_fixtures_match_their_want_comments():
+    assert check() == []
+
+
+def test_good_go_is_asserted_clean():
+    assert "good.go" in GOLDEN_FILES
+    assert parse_wants("good.go", (FIXTURES_DIR / "good.go").read_text()) == []
+
+
+def test_parse_wants_reads_line_and_package_expectations():
+    content = (
+        '// want-package god_package "generic name"\n'
+        "package utils\n"
+        '\n// want-package not_header "ignored"\n'
+        'func Must(err error) { panic(err) } // want panic_in_lib "library" panic_string "a \\"quoted\\" word"\n'
+    )
+    assert parse_wants("u.go", content) == [
+        Want("u.go", None, "god_package", "generic name"),
+        Want("u.go", 5, "panic_in_lib", "library"),
+        Want("u.go", 5, "panic_string", 'a "quoted" word'),
+    ]
+
+
+def test_check_reports_missing_and_unexpected_findings(tmp_path):
+    (tmp_path / "go.mod").write_text(_MODULE)
+    (tmp_path / "main.go").write_text(_SOURCE)
+    assert check(tmp_path, ("main.go",)) == [
+        "main.go:4: no bool_literal_return finding matching 'stale'",
+        "main.go:4: unexpected bool_literal_return finding: "
+        "if/else returning true/false literals (return the condition)",
+        "main.go:10: no todo_fixme finding matching 'TODO'",
+    ]
+
+
+def test_update_rewrites_wants_until_check_passes(tmp_path):
+    (tmp_path / "go.mod").write_text(_MODULE)
+    (tmp_path / "main.go").write_text(_SOURCE)
+    utils = tmp_path / "utils"
+    utils.mkdir()
+    shutil.copy(FIXTURES_DIR / "smells_lib.go", utils / "lib.go")
+    (utils / "lib.go").write_text(
+        "\n".join(
+            line.split(" // want")[0] for line in (utils / "lib.go").read_text().splitlines()
+        )
+        + "\n"
+    )
+
+    assert update(tmp_path, ("main.go", "utils/lib.go")) == ["main.go", "utils/lib.go"]
+    assert check(tmp_path, ("main.go", "utils/lib.go")) == []
+    assert update(tmp_path, ("main.go", "utils/lib.go")) == []
+
+    main = (tmp_path / "main.go").read_text().splitlines()
+    assert main[3].endswith(
+        '// want bool_literal_return "if/else returning true/false literals (return the '
+        'condition)" yoda_condition "Yoda condition (constant on left side of ==)"'
+    )
+    assert main[9] == "func pending() {"
+    lib = (utils / "lib.go").read_text()
+    assert lib.startswith("// want-package god_package \"God package 'utils'")
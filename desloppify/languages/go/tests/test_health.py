@@ -0,0 +1,121 @@
+"""Tests for partial analysis of Go code that does not parse or build.
+
+The fixture module under desloppify/tests/fixtures/go_broken/ deliberately
+does not compile: one package has two files with syntax errors, one imports a
+module that is not required, and one refers to an undefined name.
+"""
+
+from __future__ import annotations
+
+import shutil
+from pathlib import Path
+
+import pytest
+
+from desloppify.core.runtime_state import RuntimeContext, runtime_scope
+from desloppify.languages import get_lang
+from desloppify.languages._framework.runtime import make_lang_run
+from desloppify.languages.go import health
+from desloppify.languages.go.detectors.smells import detect_smells
+from desloppify.languages.go.phases import _phase_smells, _phase_vet
+
+FIXTURE = Path(__file__).resolve().parents[3] / "tests" / "fixtures" / "go_broken"
+
+needs_go = pytest.mark.skipif(
+    shutil.which("go") is None or shutil.which("gofmt") is None,
+    reason="Go toolchain not installed",
+)
+
+
+@pytest.fixture()
+def broken_module(monkeypatch):
+    # Fail fast on the missing module instead of asking the module proxy.
+    monkeypatch.setenv("GOPROXY", "off")
+    monkeypatch.chdir(FIXTURE)
+    with runtime_scope(RuntimeContext(project_root=FIXTURE)):
+        yield FIXTURE
+
+
+def test_split_vet_output_separates_type_errors_from_findings():
+    output = "\n".join(
+        [
+            "# example.com/gobroken/typed",
+            "vet: typed/typed.go:4:25: undefined: undefinedThing",
+            "# example.com/gobroken/good",
+            "good/good.go:7:3: fmt.Printf format %d has arg s of wrong type string",
+        ]
+    )
+    entries, type_errors = health.split_vet_output(output)
+    assert entries == [
+        {
+            "file": "good/good.go",
+            "line": 7,
+            "message": "fmt.Printf format %d has arg s of wrong type string",
+        }
+    ]
+    assert type_errors == {
+        "example.com/gobroken/typed": "typed/typed.go:4:25: undefined: undefinedThing"
+    }
+
+
+def test_unparsable_files_only_get_text_level_smells(broken_module):
+    def smells_in_broken(**kwargs) -> set[str]:
+        entries, _ = detect_smells(broken_module, **kwargs)
+        return {
+            e["id"]
+            for e in entries
+            if any(m["file"] == "broken/broken.go" for m in e["matches"])
+        }
+
+    assert {"todo_fixme", "constant_condition"} <= smells_in_broken()
+    degraded = smells_in_broken(unparsable=frozenset({"broken/broken.go"}))
+    assert "todo_fixme" in degraded
+    assert "constant_condition" not in degraded
+
+
+@needs_go
+def test_syntax_errors_name_only_files_that_do_not_parse(broken_module):
+    errors = health.syntax_errors(
+        ["good/good.go", "broken/broken.go", "missing/missing.go", "typed/typed.go"]
+    )
+    assert list(errors) == ["broken/broken.go"]
+    assert errors["broken/broken.go"].startswith("broken/broken.go:4:")
+
+
+@needs_go
+def test_vet_skips_packages_that_do_not_build_and_records_why(broken_module):
+    lang = make_lang_run(get_lang("go"))
+    findings, potentials = _phase_vet(broken_module, lang)
+    assert (findings, potentials) == ([], {})
+    degraded = {r["unit"]: r for r in lang.degraded_units}
+    assert set(degraded) == {"broken", "missing", "typed"}
+    assert all(
+        r["kind"] == "package" and r["skipped"] == "types" for r in degraded.values()
+    )
+    assert "undefinedThing" in degraded["typed"]["error"]
+    assert "gobroken-nowhere" in degraded["missing"]["error"]
+
+
+@needs_go
+def test_parse_errors_collect_every_error_in_the_file(broken_module):
+    errors = health.parse_errors(["good/good.go", "broken/stray.go"])
+    assert list(errors) == ["broken/stray.go"]
+    assert [(e["line"], e["column"]) for e in errors["broken/stray.go"]][:2] == [(6, 6), (6, 18)]
+    assert {e["line"] for e in errors["broken/stray.go"]} == {6, 14}
+
+
+@needs_go
+def test_unparsable_files_get_one_parse_error_and_the_scan_goes_on(broken_module):
+    lang = make_lang_run(get_lang("go"))
+    findings, _ = _phase_smells(broken_module, lang)
+    parse = {f["file"]: f for f in findings if f["detector"] == "parse_error"}
+    assert sorted(parse) == ["broken/broken.go", "broken/stray.go"]
+    stray = parse["broken/stray.go"]
+    assert stray["id"] == "parse_error::broken/stray.go"
+    assert stray["summary"] == "Does not parse: expected '(', found Sum at 6:6 (+5 more)"
+    assert (stray["detail"]["line"], stray["detail"]["column"]) == (6, 6)
+    assert len(stray["detail"]["errors"]) == 6
+    # The rest of the module is still analyzed, and the broken file keeps its line-level smells.
+    smelly = {m["file"] for f in findings if f["detector"] == "smells" for m in f["detail"]["matches"]}
+    assert {"good/good.go", "broken/broken.go"} <= smelly
+    assert {r["unit"] for r in lang.degraded_units} == {"broken/broken.go", "broken/stray.go"}
@@ -0,0 +1,178 @@
+"""Tests for multi-module Go repositories (``desloppify.languages.go.modules``).
+
+The fixture under desloppify/tests/fixtures/go_monorepo/ has no root go.mod.
+It holds ``services/api`` (whose own config raises the ``too_many_params``
+limit), ``services/billing`` (with a vet error), ``services/billing/legacy``
+nested inside billing, ``third_party/lib`` for excluding, and
+``scripts/gen.go`` outside every module.
+"""
+
+from __future__ import annotations
+
+import json
+import shutil
+from argparse import Namespace
+from pathlib import Path
+
+import pytest
+
+from desloppify.app.commands.scan.scan_patterns import resolve_module_selection
+from desloppify.core.runtime_state import RuntimeContext, runtime_scope
+from desloppify.engine.planning.scan import _stamp_finding_context
+from desloppify.file_discovery import set_exclusions, set_selected_files
+from desloppify.languages import get_lang
+from desloppify.languages._framework.runtime import make_lang_run
+from desloppify.languages.go.modules import (
+    directory_settings,
+    discover_modules,
+    layered_settings,
+    module_of,
+    overlay_chain,
+    read_overlay,
+    resolve_modules,
+    scan_units,
+)
+from desloppify.languages.go.phases import _phase_smells, _phase_vet
+
+FIXTURE = Path(__file__).resolve().parents[3] / "tests" / "fixtures" / "go_monorepo"
+
+needs_go = pytest.mark.skipif(
+    shutil.which("go") is None or shutil.which("gofmt") is None,
+    reason="Go toolchain not installed",
+)
+
+
+@pytest.fixture()
+def monorepo(monkeypatch):
+    monkeypatch.chdir(FIXTURE)
+    with runtime_scope(RuntimeContext(project_root=FIXTURE)):
+        yield FIXTURE
+
+
+def _smells(findings: list[dict]) -> dict[str, int]:
+    return {f["file"]: f["detail"]["count"] for f in findings}
+
+
+def test_every_go_mod_is_a_module_and_excludes_apply(monorepo):
+    assert [(m.dir, m.path) for m in discover_modules(monorepo)] == [
+        ("services/api", "example.com/monorepo/api"),
+        ("services/billing", "example.com/monorepo/billing"),
+        ("services/billing/legacy", "example.com/monorepo/billing/legacy"),
+        ("third_party/lib", "example.com/thirdparty/lib"),
+    ]
+    set_exclusions(["third_party"])
+    assert "third_party/lib" not in {m.dir for m in discover_modules(monorepo)}
+
+
+def test_files_belong_to_the_innermost_module(monorepo):
+    units = {u.module.dir if u.module else None: u.files for u in scan_units(monorepo)}
+    assert units == {
+        None: ("scripts/gen.go",),
+        "services/api": ("services/api/api.go",),
+        "services/billing": ("services/billing/billing.go",),
+        "services/billing/legacy": ("services/billing/legacy/legacy.go",),
+        "third_party/lib": ("third_party/lib/lib.go",),
+    }
+    assert module_of("services/billing/legacy/legacy.go") == "example.com/monorepo/billing/legacy"
+    assert module_of("services/billing/billing.go") == "example.com/monorepo/billing"
+    assert module_of("scripts/gen.go") is None
+
+
+def test_a_module_config_overlays_the_root_settings(monorepo):
+    [api] = [m for m in discover_modules(monorepo) if m.dir == "services/api"]
+    overlay = read_overlay(api)
+    assert overlay == {"rule_options": {"too_many_params": {"max": 7}}}
+    base = {"opt_in_smells": ["x"], "rule_options": {"too_many_params": {"max": 3}, "y": {}}}
+    assert layered_settings(base, overlay) == {
+        "opt_in_smells": ["x"],
+        "rule_options": {"too_many_params": {"max": 7}, "y": {}},
+    }
+
+
+def test_smells_run_per_module_with_its_overlay_and_are_stamped(monorepo):
+    lang = make_lang_run(get_lang("go"))
+    findings, potentials = _phase_smells(monorepo, lang)
+    # api's own config allows its six parameters; the other modules use the default.
+    params = [f for f in findings if f["id"].endswith("::too_many_params")]
+    assert _smells(params) == {
+        "services/billing/billing.go": 1,
+        "services/billing/legacy/legacy.go": 1,
+        "third_party/lib/lib.go": 1,
+    }
+    # billing's Printf bug is the one go vet reports too.
+    assert [f["file"] for f in findings if f["id"].endswith("::printf_mismatch")] == [
+        "services/billing/billing.go"
+    ]
+    assert potentials == {"smells": 5}
+    _stamp_finding_context(findings, lang)
+    assert sorted(f["module"] for f in findings) == [
+        "example.com/monorepo/billing",
+        "example.com/monorepo/billing",
+        "example.com/monorepo/billing/legacy",
+        "example.com/thirdparty/lib",
+    ]
+
+
+@needs_go
+def test_vet_runs_inside_each_module(monorepo):
+    lang = make_lang_run(get_lang("go"))
+    findings, _ = _phase_vet(monorepo, lang)
+    assert [(f["file"], f["summary"]) for f in findings] == [
+        (
+            "services/billing/billing.go",
+            "fmt.Printf format %d has arg customer of wrong type string",
+        )
+    ]
+    # Only the loose script, outside every module, cannot be loaded.
+    assert [r["unit"] for r in lang.degraded_units] == ["."]
+
+
+def test_module_selection_by_path_or_directory(monorepo):
+    dirs, files = resolve_modules(["example.com/monorepo/billing", "services/api/"], monorepo)
+    assert dirs == ["services/api", "services/billing"]
+    assert files == ["services/api/api.go", "services/billing/billing.go"]
+    with pytest.raises(ValueError, match="no module 'billing'"):
+        resolve_modules(["billing"], monorepo)
+
+
+def test_scan_module_flag_narrows_the_run(monorepo, capsys):
+    lang = make_lang_run(get_lang("go"))
+    args = Namespace(module=["services/billing/legacy"], path=str(monorepo))
+    dirs, files = resolve_module_selection(args, lang)
+    assert (dirs, files) == (("services/billing/legacy",), ("services/billing/legacy/legacy.go",))
+    assert "Module selection (services/billing/legacy): 1 package, 1 files" in (
+        capsys.readouterr().err
+    )
+    set_selected_files(list(files))
+    findings, _ = _phase_smells(monorepo, lang)
+    assert _smells(findings) == {"services/billing/legacy/legacy.go": 1}
+
+
+def _overlay(directory: Path, go: dict) -> None:
+    (directory / ".desloppify").mkdir(parents=True)
+    (directory / ".desloppify" / "config.json").write_text(json.dumps({"languages": {"go": go}}))
+
+
+def test_nested_configs_cascade_with_the_nearest_winning(tmp_path, monkeypatch):
+    for directory in ("examples", "internal", "internal/strict", "internal/loose"):
+        (tmp_path / directory).mkdir(parents=True, exist_ok=True)
+        (tmp_path / directory / "run.go").write_text(
+            f"package {Path(directory).name}\n\nfunc Run(a, b, c, d, e, f int) {{}}\n"
+        )
+    # The root turns the rule off; internal/ turns it back on, with a lower
+    # limit under strict/ and a higher one under loose/.
+    _overlay(tmp_path / "internal", {"rule_options": {"too_many_params": {"enabled": True}}})
+    _overlay(tmp_path / "internal/strict", {"rule_options": {"too_many_params": {"max": 2}}})
+    _overlay(tmp_path / "internal/loose", {"rule_options": {"too_many_params": {"max": 7}}})
+    monkeypatch.chdir(tmp_path)
+    with runtime_scope(RuntimeContext(project_root=tmp_path)):
+        assert overlay_chain("internal/strict") == ("internal", "internal/strict")
+        assert overlay_chain("examples") == ()
+        base = {"rule_options": {"too_many_params": {"enabled": False, "max": 5}}}
+        settings, _ = directory_settings(base, overlay_chain("internal/strict"))
+        assert settings["rule_options"] == {"too_many_params": {"enabled": True, "max": 2}}
+
+        lang = make_lang_run(get_lang("go"))
+        lang.state.runtime_settings["rule_options"] = base["rule_options"]
+        findings, _ = _phase_smells(tmp_path, lang)
+    assert _smells(findings) == {"internal/run.go": 1, "internal/strict/run.go": 1}
@@ -0,0 +1,65 @@
+"""Tests for Go package cache keys."""
+
+from __future__ import annotations
+
+from desloppify.core.runtime_state import RuntimeContext, runtime_scope
+from desloppify.languages.go.package_keys import GoPackageKeyer, dependent_dirs
+
+_DEP = "package dep\n\nfunc Exported() int {\n\treturn helper()\n}\n\nfunc helper() int { return 1 }\n"
+_USER = 'package app\n\nimport (\n\t"fmt"\n\n\t"example.com/m/dep"\n)\n\nfunc Run() { fmt.Println(dep.Exported()) }\n'
+
+
+def _module(root):
+    (root / "go.mod").write_text("module example.com/m\n\ngo 1.22\n")
+    (root / "dep").mkdir()
+    (root / "dep" / "dep.go").write_text(_DEP)
+    (root / "app").mkdir()
+    (root / "app" / "app.go").write_text(_USER)
+
+
+def _key(directory: str, files: list[str]) -> list[str]:
+    return GoPackageKeyer().parts(directory, files)
+
+
+def test_key_tracks_dependency_exported_api_only(tmp_path, monkeypatch):
+    _module(tmp_path)
+    monkeypatch.chdir(tmp_path)
+    original = _key("app", ["app/app.go"])
+    assert any(p.startswith("dep:example.com/m/dep:") for p in original)
+    assert any(p.startswith("module:example.com/m:") for p in original)
+
+    (tmp_path / "dep" / "dep.go").write_text(_DEP.replace("return 1", "return 2"))
+    assert _key("app", ["app/app.go"]) == original
+
+    (tmp_path / "dep" / "dep.go").write_text(
+        _DEP.replace("func Exported() int", "func Exported(n int) int")
+    )
+    assert _key("app", ["app/app.go"]) != original
+
+
+def test_key_tracks_own_content_and_go_sum(tmp_path, monkeypatch):
+    _module(tmp_path)
+    monkeypatch.chdir(tmp_path)
+    original = _key("app", ["app/app.go"])
+
+    (tmp_path / "go.sum").write_text("example.org/x v1.0.0 h1:abc=\n")
+    with_sum = _key("app", ["app/app.go"])
+    assert with_sum != original
+
+    (tmp_path / "app" / "app.go").write_text(_USER + "\n// trailing\n")
+    assert _key("app", ["app/app.go"]) != with_sum
+
+
+def test_dependent_dirs_are_same_module_importers(tmp_path, monkeypatch):
+    _module(tmp_path)
+    (tmp_path / "main.go").write_text(
+        'package main\n\nimport "example.com/m/app"\n\nfunc main() { app.Run() }\n'
+    )
+    (tmp_path / "other").mkdir()
+    (tmp_path / "other" / "other.go").write_text('package other\n\nimport "fmt"\n')
+    monkeypatch.chdir(tmp_path)
+    with runtime_scope(RuntimeContext(project_root=tmp_path)):
+        assert dependent_dirs(["dep"], tmp_path) == ["app"]
+        assert dependent_dirs(["app"], tmp_path) == ["."]
+        assert dependent_dirs(["dep", "app"], tmp_path) == ["."]
+        assert dependent_dirs(["other"], tmp_path) == []
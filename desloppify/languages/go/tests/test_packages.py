@@ -0,0 +1,84 @@
+"""Tests for resolving Go package patterns and build tags.
+
+The fixture module under desloppify/tests/fixtures/go_patterns/ has a root
+package, ``pkg/api``, ``internal/store`` (with a ``debug``-tagged file) and
+``internal/store/inner``; ``testdata`` is never part of a pattern.
+"""
+
+from __future__ import annotations
+
+import shutil
+from pathlib import Path
+
+import pytest
+
+from desloppify.core.runtime_state import RuntimeContext, runtime_scope
+from desloppify.file_discovery import set_selected_files
+from desloppify.languages.go.extractors import find_go_files
+from desloppify.languages.go.packages import (
+    is_package_pattern,
+    resolve_package_patterns,
+    tag_flags,
+)
+
+FIXTURE = Path(__file__).resolve().parents[3] / "tests" / "fixtures" / "go_patterns"
+
+needs_go = pytest.mark.skipif(shutil.which("go") is None, reason="Go toolchain not installed")
+
+
+@pytest.fixture()
+def patterns_module(monkeypatch):
+    monkeypatch.chdir(FIXTURE)
+    with runtime_scope(RuntimeContext(project_root=FIXTURE)):
+        yield FIXTURE
+
+
+def test_tag_flags_normalizes_separators():
+    assert tag_flags("") == []
+    assert tag_flags(" debug, integration ") == ["-tags", "debug,integration"]
+    assert tag_flags("a b") == ["-tags", "a,b"]
+
+
+def test_is_package_pattern():
+    assert is_package_pattern("./...")
+    assert is_package_pattern("example.com/m/...")
+    assert not is_package_pattern("./internal")
+
+
+@needs_go
+def test_all_packages_pattern_covers_every_subpackage(patterns_module):
+    dirs, files = resolve_package_patterns(["./..."], {})
+    assert dirs == [".", "internal/store", "internal/store/inner", "pkg/api"]
+    assert "testdata/skipped.go" not in files
+    assert "internal/store/store_debug.go" not in files
+
+
+@needs_go
+def test_subtree_pattern_and_import_path(patterns_module):
+    dirs, _ = resolve_package_patterns(["./internal/..."], {})
+    assert dirs == ["internal/store", "internal/store/inner"]
+    dirs, files = resolve_package_patterns(["example.com/patterns/pkg/api"], {})
+    assert (dirs, files) == (["pkg/api"], ["pkg/api/api.go"])
+
+
+@needs_go
+def test_build_tags_decide_which_files_belong(patterns_module):
+    _, files = resolve_package_patterns(["./internal/store"], {"build_tags": "debug"})
+    assert files == ["internal/store/store.go", "internal/store/store_debug.go"]
+
+
+@needs_go
+def test_unknown_pattern_is_an_error(patterns_module):
+    with pytest.raises(ValueError):
+        resolve_package_patterns(["./missing/..."], {})
+
+
+@needs_go
+def test_selected_files_restrict_discovery(patterns_module):
+    _, files = resolve_package_patterns(["./internal/..."], {})
+    set_selected_files(files)
+    assert sorted(find_go_files(FIXTURE)) == [
+        "internal/store/inner/inner.go",
+        "internal/store/store.go",
+    ]
+
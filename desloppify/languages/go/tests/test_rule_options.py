@@ -0,0 +1,214 @@
+"""Tests for typed Go rule options and strict ``languages.go`` config checking.
+
+The error messages are the interface here, so most tests pin them exactly.
+"""
+
+from __future__ import annotations
+
+import json
+from argparse import Namespace
+from pathlib import Path
+
+import pytest
+
+from desloppify.app.commands.helpers.lang import check_lang_settings
+from desloppify.app.commands.scan.scan_workflow import _check_lang_config
+from desloppify.core.config import ConfigError
+from desloppify.core.result_cache import ResultCache
+from desloppify.core.runtime_state import RuntimeContext, runtime_scope
+from desloppify.languages import get_lang
+from desloppify.languages.go.detectors.smells import SMELL_CHECKS, detect_smells
+from desloppify.languages.go.rule_options import (
+    check_settings,
+    enum_option,
+    int_option,
+    resolve_options,
+    str_list_option,
+)
+from desloppify.languages.go.rules import Rule, register_rule, unregister_rules
+
+_SOURCE = """package p
+
+func f(a, b, c, d, e, g int) {}
+
+func Open() error {
+	return nil
+}
+
+func open() error {
+	return nil
+}
+"""
+
+
+def _lines(entries: list[dict]) -> dict[str, list[int]]:
+    return {e["id"]: [m["line"] for m in e["matches"]] for e in entries}
+
+
+def _problems(tmp_path: Path, go: dict) -> list[str]:
+    path = tmp_path / "config.json"
+    path.write_text(json.dumps({"languages": {"go": go}}, indent=2))
+    return [p.split("config.json", 1)[1] for p in check_settings(go, path)]
+
+
+@pytest.mark.parametrize(
+    ("go", "expected"),
+    [
+        (
+            {"opt_in_smells": ["struct_field_alignmnt"]},
+            ":5: languages.go.opt_in_smells[0]: unknown rule 'struct_field_alignmnt'; "
+            "did you mean 'struct_field_alignment'?",
+        ),
+        (
+            {"opt_in_smells": ["no_such_rule_at_all"]},
+            ":5: languages.go.opt_in_smells[0]: unknown rule 'no_such_rule_at_all'",
+        ),
+        (
+            {"categories": ["concurency"]},
+            ":5: languages.go.categories[0]: unknown category 'concurency'; "
+            "did you mean 'concurrency'?",
+        ),
+        (
+            {"exclude_categories": "style"},
+            ":4: languages.go.exclude_categories: expected a list of categories",
+        ),
+        (
+            {"rule_options": {"too_many_parms": {"max": 7}}},
+            ":5: languages.go.rule_options.too_many_parms: unknown rule 'too_many_parms'; "
+            "did you mean 'too_many_params'?",
+        ),
+        (
+            {"rule_options": {"too_many_params": {"maxx": 7}}},
+            ":6: languages.go.rule_options.too_many_params.maxx: "
+            "unknown option 'maxx' for too_many_params; did you mean 'max'?",
+        ),
+        (
+            {"rule_options": {"too_many_params": {"limit": 7}}},
+            ":6: languages.go.rule_options.too_many_params.limit: "
+            "unknown option 'limit' for too_many_params (options: max)",
+        ),
+        (
+            {"rule_options": {"too_many_params": {"max": 40}}},
+            ":6: languages.go.rule_options.too_many_params.max: "
+            "40 is out of range (allowed: 1..32)",
+        ),
+        (
+            {"rule_options": {"too_many_params": {"max": "7"}}},
+            ':6: languages.go.rule_options.too_many_params.max: '
+            'expected an integer in 1..32, got "7"',
+        ),
+        (
+            {"rule_options": {"useless_error_return": {"functions": "unexportd"}}},
+            ":6: languages.go.rule_options.useless_error_return.functions: "
+            "'unexportd' is not allowed; did you mean 'unexported'?",
+        ),
+        (
+            {"rule_options": {"useless_error_return": {"functions": "public"}}},
+            ":6: languages.go.rule_options.useless_error_return.functions: "
+            "'public' is not allowed (allowed: all, unexported)",
+        ),
+        (
+            {"rule_options": {"error_not_wrapped": {"verbs": ["v", "q"]}}},
+            ":6: languages.go.rule_options.error_not_wrapped.verbs: "
+            "'q' is not allowed (allowed: v, s)",
+        ),
+        (
+            {"rule_options": {"error_not_wrapped": {"verbs": "v"}}},
+            ':6: languages.go.rule_options.error_not_wrapped.verbs: '
+            'expected a list of strings, got "v"',
+        ),
+        (
+            {"rule_options": {"panic_in_lib": {"max": 1}}},
+            ":5: languages.go.rule_options.panic_in_lib: panic_in_lib has no options",
+        ),
+        (
+            {"rule_options": {"panic_in_lib": {"enabled": "no"}}},
+            ':6: languages.go.rule_options.panic_in_lib.enabled: expected true or false, got "no"',
+        ),
+    ],
+)
+def test_problems_name_the_line_the_field_and_a_fix(tmp_path, go, expected):
+    assert _problems(tmp_path, go) == [expected]
+
+
+def test_custom_and_plugin_rule_ids_are_known(tmp_path):
+    custom = {"id": "no_now", "kind": "forbid-call", "call": "time.Now", "message": "m"}
+    assert _problems(tmp_path, {"custom_rules": [custom], "opt_in_smells": ["no_now"]}) == []
+    register_rule(
+        Rule(
+            "house_depth",
+            "too deep",
+            lambda pass_: None,
+            options=(int_option("max", 4, minimum=1, maximum=10),),
+            source="house",
+        )
+    )
+    try:
+        assert _problems(tmp_path, {"rule_options": {"house_depth": {"max": 11}}}) == [
+            ":6: languages.go.rule_options.house_depth.max: 11 is out of range (allowed: 1..10)"
+        ]
+    finally:
+        unregister_rules("house")
+
+
+def test_unknown_settings_are_reported_with_the_language_checks(tmp_path):
+    path = tmp_path / "config.json"
+    config = {"languages": {"go": {"opt_in_smell": [], "opt_in_smells": ["yoda"]}}}
+    path.write_text(json.dumps(config, indent=2))
+    problems = check_lang_settings(config, get_lang("go"), source=path)
+    problems = [p.split("config.json", 1)[1] for p in problems]
+    assert problems == [
+        ":4: languages.go.opt_in_smell: unknown setting; did you mean 'opt_in_smells'?",
+        ":6: languages.go.opt_in_smells[0]: unknown rule 'yoda'",
+    ]
+
+
+def test_scan_fails_on_problems_unless_lenient(tmp_path, monkeypatch, capsys):
+    path = tmp_path / "config.json"
+    config = {"languages": {"go": {"rule_option": {}}}}
+    path.write_text(json.dumps(config, indent=2))
+    monkeypatch.setattr("desloppify.core.config.CONFIG_FILE", path)
+    lang = get_lang("go")
+    with pytest.raises(ConfigError, match="did you mean 'rule_options'"):
+        _check_lang_config(Namespace(lenient_config=False), config, lang)
+    _check_lang_config(Namespace(lenient_config=True), config, lang)
+    assert "Config warning: " in capsys.readouterr().err
+
+
+def test_options_reach_the_rules_and_the_cache_key(tmp_path, monkeypatch):
+    root = tmp_path / "mod"
+    root.mkdir()
+    (root / "p.go").write_text(_SOURCE)
+    monkeypatch.chdir(root)
+    cache = ResultCache(tmp_path / "cache")
+    tuned = {"too_many_params": {"max": 6}, "useless_error_return": {"functions": "unexported"}}
+    with runtime_scope(RuntimeContext(project_root=root)):
+        default, _ = detect_smells(root, cache=cache)
+        custom, _ = detect_smells(root, cache=cache, rule_options=tuned)
+    assert _lines(default) == {"too_many_params": [3], "useless_error_return": [5, 9]}
+    assert _lines(custom) == {"useless_error_return": [9]}
+    assert (cache.stats.hits, cache.stats.misses) == (0, 2)
+
+
+def test_invalid_values_fall_back_to_defaults():
+    checks = [s for s in SMELL_CHECKS if s["options"]]
+    resolved = resolve_options(
+        {
+            "too_many_params": {"max": 99},
+            "useless_error_return": {"functions": "unexported"},
+            "error_not_wrapped": {"verbs": ["q"]},
+        },
+        checks,
+    )
+    assert resolved["too_many_params"] == {"max": 5}
+    assert resolved["useless_error_return"] == {"functions": "unexported"}
+    assert resolved["error_not_wrapped"] == {"verbs": ["v", "s"]}
+    assert resolved["struct_field_alignment"] == {"min_bytes": 32}
+
+
+def test_option_defaults_must_be_valid():
+    with pytest.raises(ValueError, match="outside 1..3"):
+        int_option("n", 5, minimum=1, maximum=3)
+    with pytest.raises(ValueError, match="not one of its choices"):
+        enum_option("mode", "x", ("a", "b"))
+    assert str_list_option("names", ("a",)).problem(["a", "z"]) is None
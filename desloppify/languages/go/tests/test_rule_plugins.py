@@ -0,0 +1,173 @@
+"""Tests for out-of-tree Go rules (``desloppify.languages.go.rules``).
+
+The example plugin under desloppify/tests/fixtures/go_rule_plugin/ is an
+external package: ``house_lint`` registers its rules through the public
+interface only, and ``desloppify_house.py`` is its thin entry point.
+"""
+
+from __future__ import annotations
+
+import contextlib
+import json
+import os
+import subprocess
+import sys
+from pathlib import Path
+
+import pytest
+
+from desloppify.core.config import ConfigError
+from desloppify.core.diagnostics import RunDiagnostics, RunTimings
+from desloppify.core.runtime_state import RuntimeContext, runtime_scope
+from desloppify.languages.go.detectors.custom_rules import parse_custom_rules
+from desloppify.languages.go.detectors.smells import (
+    _enabled_checks,
+    _scan_package,
+    detect_smells,
+    smell_rule_catalog,
+)
+from desloppify.languages.go.rules import (
+    Rule,
+    load_configured_plugins,
+    load_plugin,
+    register_rule,
+    registered_rules,
+    unregister_rules,
+)
+
+PLUGIN_DIR = Path(__file__).resolve().parents[3] / "tests" / "fixtures" / "go_rule_plugin"
+API_FILE = str(PLUGIN_DIR / "internal" / "api" / "api.go")
+
+
+@pytest.fixture(autouse=True)
+def _clean_registry():
+    unregister_rules()
+    with runtime_scope(RuntimeContext(project_root=PLUGIN_DIR)), contextlib.chdir(PLUGIN_DIR):
+        yield
+    unregister_rules()
+
+
+def _matches(entries: list[dict]) -> dict[str, list[tuple[str, int]]]:
+    return {e["id"]: [(m["file"], m["line"]) for m in e["matches"]] for e in entries}
+
+
+def test_configured_plugin_rules_run_as_smells():
+    load_configured_plugins(PLUGIN_DIR / "config.json")
+    assert [r.id for r in registered_rules()] == ["fmt_print_in_lib", "context_not_first"]
+
+    entries, _ = detect_smells(PLUGIN_DIR)
+    # main.go is a main package; the commented-out and suppressed calls do not count.
+    assert _matches(entries) == {"fmt_print_in_lib": [("internal/api/api.go", 9)]}
+
+    entries, _ = detect_smells(PLUGIN_DIR, opt_in={"context_not_first"}, jobs=2)
+    assert _matches(entries) == {
+        "context_not_first": [("internal/api/api.go", 13)],
+        "fmt_print_in_lib": [("internal/api/api.go", 9)],
+    }
+
+
+def test_plugin_rules_are_catalogued_with_their_source():
+    load_plugin("house_lint")
+    rows = {row["id"]: row for row in smell_rule_catalog()}
+    assert rows["context_not_first"] == {
+        "id": "context_not_first",
+        "label": "context.Context is not the first parameter",
+        "severity": "medium",
+        "categories": ("style",),
+        "requires": "syntax",
+        "opt_in": True,
+        "fixable": False,
+        "plugin": "house_lint",
+    }
+    assert "plugin" not in rows["panic_in_lib"]
+
+
+def test_plugin_rules_are_timed_per_rule():
+    load_plugin("house_lint")
+    diagnostics = RunDiagnostics(timings=RunTimings())
+    detect_smells(PLUGIN_DIR, diagnostics=diagnostics)
+    assert "fmt_print_in_lib" in diagnostics.timings.rules
+
+
+def test_type_level_plugin_rules_are_skipped_when_syntax_only():
+    register_rule(Rule("needs_types", "Needs types", lambda pass_: None, requires="types"))
+    assert "needs_types" in {s["id"] for s in _enabled_checks(set())}
+    assert "needs_types" not in {s["id"] for s in _enabled_checks(set(), syntax_only=True)}
+
+
+def test_worker_imports_the_plugins_it_is_given():
+    load_plugin("house_lint")
+    unregister_rules()
+    counts = _scan_package([API_FILE], opt_in=frozenset(), plugins=("house_lint",))
+    assert [m["line"] for m in counts["fmt_print_in_lib"]] == [9]
+
+
+def test_plugin_loaded_from_a_file_path(tmp_path):
+    plugin = tmp_path / "todo_rules.py"
+    plugin.write_text(
+        "from desloppify.languages.go.rules import rule\n"
+        "\n"
+        "@rule('ctx_err', 'returns ctx.Err()')\n"
+        "def ctx_err(pass_):\n"
+        "    for n, line in enumerate(pass_.file.lines, 1):\n"
+        "        if 'ctx.Err()' in line:\n"
+        "            pass_.report(n, 'ctx.Err')\n"
+    )
+    load_plugin(str(plugin))
+    load_plugin(str(plugin))
+    assert [r.source for r in registered_rules()] == [str(plugin.resolve())]
+    counts = _scan_package([API_FILE], opt_in=frozenset())
+    assert [(m["line"], m["content"]) for m in counts["ctx_err"]] == [
+        (10, "ctx.Err"),
+        (15, "ctx.Err"),
+    ]
+
+
+@pytest.mark.parametrize(
+    ("kwargs", "problem"),
+    [
+        ({"id": "Bad-Id"}, "not lower_snake_case"),
+        ({"id": "panic_in_lib"}, "a built-in smell has this id"),
+        ({"severity": "urgent"}, "severity must be one of"),
+        ({"requires": "module"}, "requires must be one of"),
+    ],
+)
+def test_invalid_rules_are_rejected(kwargs, problem):
+    fields = {"id": "ok_rule", "label": "label", "run": lambda pass_: None} | kwargs
+    with pytest.raises(ValueError, match=problem):
+        register_rule(Rule(**fields))
+
+
+def test_ids_are_unique_across_plugins_and_custom_rules():
+    load_plugin("house_lint")
+    with pytest.raises(ValueError, match="already registered by house_lint"):
+        register_rule(Rule("fmt_print_in_lib", "other", lambda pass_: None, source="x"))
+    custom = {"id": "fmt_print_in_lib", "kind": "forbid-call", "call": "fmt.Println", "message": "m"}
+    with pytest.raises(ConfigError, match="'fmt_print_in_lib' is already defined"):
+        parse_custom_rules([custom])
+
+
+def test_unimportable_plugin_points_at_the_config_line(tmp_path):
+    path = tmp_path / "config.json"
+    config = {"languages": {"go": {"rule_plugins": ["house_lint", "missing_rules"]}}}
+    path.write_text(json.dumps(config, indent=2))
+    with pytest.raises(ConfigError) as exc:
+        load_configured_plugins(path)
+    message = str(exc.value)
+    assert message.endswith(
+        ":6: rule_plugins[1]: cannot import 'missing_rules': No module named 'missing_rules'"
+    )
+
+
+def test_thin_entry_point_builds_the_rules_in():
+    result = subprocess.run(
+        [sys.executable, "desloppify_house.py", "--lang", "go", "langs", "--rules"],
+        cwd=PLUGIN_DIR,
+        env={**os.environ, "PYTHONPATH": str(Path(__file__).resolve().parents[4])},
+        capture_output=True,
+        text=True,
+        check=False,
+    )
+    assert result.returncode == 0, result.stderr
+    rows = [line.split() for line in result.stdout.splitlines()]
+    assert ["fmt_print_in_lib", "syntax", "-", "low,", "plugin", "house_lint"] in rows
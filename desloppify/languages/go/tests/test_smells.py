@@ -6,11 +6,41 @@ fixture files under desloppify/tests/fixtures/go/.
 
 from __future__ import annotations
 
+import io
+import os
+import subprocess
+import sys
 from pathlib import Path
 
 import pytest
 
-from desloppify.languages.go.detectors.smells import detect_smells
+from desloppify.app.cli_support.parser import create_parser
+from desloppify.app.commands.scan.scan_patterns import apply_rule_categories
+from desloppify.core.diagnostics import RunDiagnostics, RunTimings
+from desloppify.core.result_cache import ResultCache
+from desloppify.core.runtime_state import RuntimeContext, runtime_scope
+from desloppify.languages import get_lang
+from desloppify.languages._framework.runtime import make_lang_run
+from desloppify.languages.go.detectors._constexpr import deterministic, is_constant
+from desloppify.languages.go.detectors._inspector import WALK_RULE
+from desloppify.languages.go.detectors.custom_rules import load_custom_rules
+from desloppify.languages.go.detectors.signatures import Signature, clusters
+from desloppify.languages.go.detectors.smells import (
+    PARSE_RULE,
+    SMELL_CHECKS,
+    detect_smells,
+    select_categories,
+)
+from desloppify.languages.go.tests.bench_rules import (
+    Result,
+    run_benchmarks,
+    slowest_rules,
+)
+from desloppify.languages.go.tests.bench_rules import main as bench_rules_main
+from desloppify.languages.go.tests.bench_smells import (
+    count_traversals,
+    make_synthetic_tree,
+)
 
 FIXTURES = Path(__file__).resolve().parents[3] / "tests" / "fixtures" / "go"
 
@@ -109,3 +139,1178 @@ def test_clean_file_no_smells(smell_results):
 def test_total_files_positive(smell_results):
     _, total_files = smell_results
     assert total_files > 0
+
+
+def test_struct_field_alignment_off_by_default(smell_results):
+    results, _ = smell_results
+    assert not _has_smell(results, "struct_field_alignment")
+
+
+def test_struct_field_alignment_opt_in():
+    entries, _ = detect_smells(FIXTURES, opt_in={"struct_field_alignment"})
+    results = {e["id"]: e for e in entries}
+    assert results["struct_field_alignment"]["severity"] == "low"
+    matches = results["struct_field_alignment"]["matches"]
+    flagged = {m["struct"]: m for m in matches}
+    assert "paddedRecord" in flagged
+    assert flagged["paddedRecord"]["current_size"] == 40
+    assert flagged["paddedRecord"]["optimal_size"] == 24
+    assert "packedRecord" not in flagged
+
+
+def test_syntax_only_skips_type_level_rules_even_when_opted_in(smell_results):
+    results, _ = smell_results
+    entries, _ = detect_smells(
+        FIXTURES, opt_in={"struct_field_alignment"}, syntax_only=True
+    )
+    syntax = {s["id"] for s in SMELL_CHECKS if s["requires"] == "syntax"}
+    assert "shared_param_group" in results
+    assert {e["id"] for e in entries} == set(results) & syntax
+
+
+def test_sprintf_strconv(smell_results):
+    results, _ = smell_results
+    matches = [
+        m
+        for m in results["sprintf_strconv"]["matches"]
+        if m["file"].endswith("sprintf.go")
+    ]
+    assert [m["content"] for m in matches] == ['return fmt.Sprintf("%d", n)']
+    assert matches[0]["suggestion"] == "strconv.Itoa(n) for int"
+    assert results["sprintf_strconv"]["severity"] == "low"
+
+
+def test_structured_sprintf_suggestions(smell_results):
+    results, _ = smell_results
+
+    def suggestions(rule):
+        return [
+            m["suggestion"]
+            for m in sorted(results[rule]["matches"], key=lambda m: m["line"])
+            if m["file"].endswith("structsprintf.go")
+        ]
+
+    assert suggestions("sprintf_path") == ['filepath.Join(dir, name+".yaml")']
+    assert suggestions("sprintf_duration") == [
+        "time.Duration(seconds) * time.Second",
+        "time.Duration(ms * float64(time.Millisecond))",
+    ]
+    assert results["sprintf_url_param"]["severity"] == "high"
+    assert [m["value"] for m in results["sprintf_url_param"]["matches"]] == ["term"]
+
+
+def test_time_layout_suggests_the_reference_layout(smell_results):
+    results, _ = smell_results
+    matches = sorted(results["time_layout"]["matches"], key=lambda m: m["line"])
+    assert [(m["layout"], m["suggestion"]) for m in matches] == [
+        ("YYYY-MM-DD", "2006-01-02"),
+        ("yyyy-MM-dd HH:mm:ss", "2006-01-02 15:04:05"),
+        ("%Y/%m/%d", "2006/01/02"),
+        ("DD.MM.YYYY", "02.01.2006"),
+    ]
+    assert matches[0]["tokens"] == ["YYYY", "MM", "DD"]
+
+
+def test_ineffective_break_reasons(smell_results):
+    results, _ = smell_results
+    matches = sorted(results["ineffective_break"]["matches"], key=lambda m: m["line"])
+    assert [(m["statement"], m["reason"]) for m in matches] == [
+        ("switch", "clause_end"),
+        ("select", "terminating_check"),
+        ("switch", "terminating_check"),
+    ]
+
+
+def test_time_equal_suggests_equal_and_is_zero(smell_results):
+    results, _ = smell_results
+    matches = sorted(results["time_equal"]["matches"], key=lambda m: m["line"])
+    assert [m["suggestion"] for m in matches] == [
+        "time.Now().Equal(deadline)",
+        "!c.Created.Equal(at)",
+        "c.Created.IsZero()",
+    ]
+
+
+def test_loop_runs_once_forms_and_exits(smell_results):
+    results, _ = smell_results
+    matches = sorted(
+        (m for m in results["loop_runs_once"]["matches"] if m["file"].endswith("loops.go")),
+        key=lambda m: m["line"],
+    )
+    assert [(m["loop"], m["exit"]) for m in matches] == [
+        ("bare", "return"),
+        ("counted", "break"),
+        ("condition", "return"),
+    ]
+    [unchanged] = results["loop_condition_unchanged"]["matches"]
+    assert (unchanged["condition"], unchanged["variables"]) == ("i < n", ["i", "n"])
+
+
+def test_append_no_prealloc(smell_results):
+    results, _ = smell_results
+    matches = [
+        m
+        for m in results["append_no_prealloc"]["matches"]
+        if m["file"].endswith("prealloc.go")
+    ]
+    assert [m["line"] for m in matches] == [6]
+    assert matches[0]["suggestion"] == "names := make([]string, 0, len(users))"
+
+
+def test_double_map_lookup(smell_results):
+    results, _ = smell_results
+    matches = [
+        m
+        for m in results["double_map_lookup"]["matches"]
+        if m["file"].endswith("maplookup.go")
+    ]
+    assert [m["line"] for m in matches] == [6]
+    assert matches[0]["suggestion"] == "if v, ok := prices[key]; ok {"
+
+
+def test_desloppify_ignore_directive(smell_results):
+    results, _ = smell_results
+    lines = [
+        m["line"]
+        for m in results["sprintf_strconv"]["matches"]
+        if m["file"].endswith("suppressed.go")
+    ]
+    assert lines == [16]
+
+
+def test_sprintf_strconv_bool_fix_when_strconv_imported(smell_results):
+    results, _ = smell_results
+    matches = {
+        m["file"].rsplit("/", 1)[-1]: m for m in results["sprintf_strconv"]["matches"]
+    }
+    assert matches["sprintf_fix.go"]["fix"] == {
+        "title": "Replace with strconv.FormatBool(enabled)",
+        "line": 9,
+        "old": 'fmt.Sprintf("%t", enabled)',
+        "new": "strconv.FormatBool(enabled)",
+    }
+    assert "fix" not in matches["sprintf.go"]
+
+
+def test_len_comparison(smell_results):
+    results, _ = smell_results
+    matches = [
+        m
+        for m in results["len_comparison"]["matches"]
+        if m["file"].endswith("lencmp.go")
+    ]
+    assert [(m["line"], m["always"]) for m in matches] == [
+        (6, "false"),
+        (9, "true"),
+        (15, "false"),
+        (18, "true"),
+    ]
+
+
+def test_constant_condition(smell_results):
+    results, _ = smell_results
+    matches = [
+        m
+        for m in results["constant_condition"]["matches"]
+        if m["file"].endswith("constcond.go")
+    ]
+    # `x == x` needs types; `f != f` in isNaN is never reported.
+    assert [(m["line"], m["always"]) for m in matches] == [
+        (8, "true"),
+        (14, "true"),
+        (17, "false"),
+    ]
+
+
+def test_unreachable_code(smell_results):
+    results, _ = smell_results
+    matches = [
+        m
+        for m in results["unreachable_code"]["matches"]
+        if m["file"].endswith("unreachable.go")
+    ]
+    assert [(m["line"], m["after"]) for m in matches] == [
+        (11, "return"),
+        (16, "panic"),
+        (53, "for {}"),
+    ]
+
+
+def test_duplicate_branch(smell_results):
+    results, _ = smell_results
+    matches = [
+        m
+        for m in results["duplicate_branch"]["matches"]
+        if m["file"].endswith("dupbranch.go")
+    ]
+    assert [(m["line"], m["first_line"]) for m in matches] == [(11, 7), (22, 20)]
+
+
+def test_empty_branch(smell_results):
+    results, _ = smell_results
+    matches = [
+        m
+        for m in results["empty_branch"]["matches"]
+        if m["file"].endswith("emptybranch.go")
+    ]
+    assert [(m["line"], m["kind"]) for m in matches] == [
+        (6, "if"),
+        (10, "else"),
+        (12, "for"),
+        (14, "switch"),
+    ]
+
+
+def test_useless_error_return(smell_results):
+    results, _ = smell_results
+    matches = [
+        m
+        for m in results["useless_error_return"]["matches"]
+        if m["file"].endswith("uselesserr.go")
+    ]
+    assert [(m["line"], m["function"]) for m in matches] == [(9, "validate"), (17, "load")]
+
+
+def test_value_with_error(smell_results):
+    results, _ = smell_results
+    matches = [
+        m
+        for m in results["value_with_error"]["matches"]
+        if m["file"].endswith("valuerr.go")
+    ]
+    assert [(m["line"], m["value"]) for m in matches] == [(15, "result"), (23, "8080")]
+
+
+def test_panic_string(smell_results):
+    results, _ = smell_results
+    matches = [
+        m
+        for m in results["panic_string"]["matches"]
+        if m["file"].endswith("panicstr.go")
+    ]
+    assert [(m["line"], m["suggestion"]) for m in matches] == [
+        (11, "panic(errors.New(...))"),
+        (19, "panic(fmt.Errorf(...))"),
+    ]
+
+
+def test_error_not_wrapped(smell_results):
+    results, _ = smell_results
+    matches = [
+        m
+        for m in results["error_not_wrapped"]["matches"]
+        if m["file"].endswith("wraperr.go")
+    ]
+    assert [(m["line"], m["verb"], m["arg"]) for m in matches] == [
+        (13, "%v", "err"),
+        (16, "%s", "ErrMissing"),
+    ]
+
+
+def test_multiple_wrap_verbs_only_before_go_1_20(smell_results):
+    results, _ = smell_results
+    matches = results["multiple_wrap_verbs"]["matches"]
+    assert [(m["file"].rsplit("/", 2)[-2:], m["line"]) for m in matches] == [
+        (["legacymod", "multiwrap.go"], 14)
+    ]
+    assert (matches[0]["wraps"], matches[0]["go_version"]) == (2, "1.19")
+    assert results["multiple_wrap_verbs"]["severity"] == "medium"
+
+
+def test_goroutine_index_capture_only_without_a_copy_before_go_1_22(smell_results):
+    results, _ = smell_results
+    matches = results["goroutine_index_capture"]["matches"]
+    # The i := i copy, the argument, the plain read and the Go 1.22 module
+    # (loopvar/) stay silent.
+    assert [(m["file"].rsplit("/", 1)[-1], m["line"], m["slice"], m["var"]) for m in matches] == [
+        ("goroutine_index.go", 14, "s", "i"),
+        ("goroutine_index.go", 26, "counts", "i"),
+    ]
+    assert results["goroutine_index_capture"]["severity"] == "high"
+
+
+def test_waitgroup_add_in_goroutine(smell_results):
+    results, _ = smell_results
+    matches = results["waitgroup_add_in_goroutine"]["matches"]
+    # Add before go, a goroutine waiting on its own group and a non-WaitGroup
+    # Add stay silent.
+    assert [(m["file"].rsplit("/", 1)[-1], m["line"], m["waitgroup"]) for m in matches] == [
+        ("waitgroup_add.go", 12, "wg"),
+        ("waitgroup_add.go", 26, "p.wg"),
+    ]
+
+
+def test_panic_in_lib_names_the_exported_root(smell_results):
+    results, _ = smell_results
+    reach = {
+        m["function"]: m["reached_from"]
+        for m in results["panic_in_lib"]["matches"]
+        if "panicreach/" in m["file"]
+    }
+    # mustQuiet is only used by a package-level var; MustPattern documents its panic.
+    assert reach == {
+        "parseDigits": "Parse",
+        "mustQuiet": "package initialization",
+        "visitNode": "NewWalker",
+    }
+    [helper] = [m for m in results["panic_in_lib_helper"]["matches"] if "panicreach/" in m["file"]]
+    assert helper["function"] == "assertSorted"
+    assert results["panic_in_lib"]["severity"] == "high"
+    assert results["panic_in_lib_helper"]["severity"] == "medium"
+
+
+@pytest.mark.parametrize(
+    ("expr", "constant", "chain"),
+    [
+        ('"   " + pat', True, True),
+        ("len(pat) << 2", True, True),
+        ("-(maxDepth * 2)", True, True),
+        ("path", False, False),
+        ("strings.TrimSpace(pat)", False, True),
+        ("strings.TrimSpace(path)", False, False),
+        ("cfg.Pattern", False, False),
+        ("os.Args[1]", False, False),
+    ],
+)
+def test_constant_expressions(expr, constant, chain):
+    constants = frozenset({"pat", "maxDepth"})
+    assert is_constant(expr, constants) is constant
+    assert deterministic(expr, constants, frozenset({"strings", "os"})) is chain
+
+
+def test_must_call_in_function_takes_extra_helpers(tmp_path, monkeypatch):
+    root = tmp_path / "mod"
+    root.mkdir()
+    (root / "p.go").write_text(
+        "package p\n\n"
+        'import "example.com/lo"\n\n'
+        "func parseOrDie(s string) int { return len(s) }\n\n"
+        "func Handle(s string) int {\n"
+        "\treturn lo.Must(s) + parseOrDie(s) + lo.Must(\"ok\")\n"
+        "}\n"
+    )
+    monkeypatch.chdir(root)
+    extra = {"must_call_in_function": {"functions": ["parseOrDie"]}}
+    with runtime_scope(RuntimeContext(project_root=root)):
+        entries, _ = detect_smells(root, rule_options=extra)
+    [entry] = [e for e in entries if e["id"] == "must_call_in_function"]
+    assert [(m["line"], m["function"]) for m in entry["matches"]] == [
+        (8, "lo.Must"),
+        (8, "parseOrDie"),
+    ]
+
+
+def test_deferred_error_ignored_methods_are_configurable(tmp_path, monkeypatch):
+    root = tmp_path / "mod"
+    root.mkdir()
+    (root / "p.go").write_text(
+        "package p\n\n"
+        'import "os"\n\n'
+        "func Load(path string) error {\n"
+        "\tf, err := os.Open(path)\n"
+        "\tif err != nil {\n"
+        "\t\treturn err\n"
+        "\t}\n"
+        "\tdefer f.Close()\n"
+        "\tdefer f.Sync()\n"
+        "\treturn nil\n"
+        "}\n"
+    )
+    monkeypatch.chdir(root)
+    extra = {"deferred_error_ignored": {"methods": ["Commit"], "writer_methods": []}}
+    with runtime_scope(RuntimeContext(project_root=root)):
+        entries, _ = detect_smells(root, rule_options=extra)
+        assert not [e for e in entries if e["id"] == "deferred_error_ignored"]
+        extra = {"deferred_error_ignored": {"methods": ["Close"]}}
+        entries, _ = detect_smells(root, rule_options=extra)
+    [entry] = [e for e in entries if e["id"] == "deferred_error_ignored"]
+    # Close is now reported even on a file opened for reading.
+    assert [(m["line"], m["call"]) for m in entry["matches"]] == [(10, "f.Close")]
+
+
+def test_printf_mismatch_checks_configured_wrappers(tmp_path, monkeypatch):
+    root = tmp_path / "mod"
+    root.mkdir()
+    (root / "p.go").write_text(
+        "package p\n\n"
+        "type Logger struct{}\n\n"
+        "func (l *Logger) Logf(level int, format string, args ...any) {}\n\n"
+        "func Infof(format string, args ...any) {}\n\n"
+        "func Run(l *Logger, name string) {\n"
+        '\tl.Logf(1, "%s started in %d ms", name)\n'
+        '\tInfof("%d", name)\n'
+        "}\n"
+    )
+    monkeypatch.chdir(root)
+    with runtime_scope(RuntimeContext(project_root=root)):
+        entries, _ = detect_smells(root)
+        assert not [e for e in entries if e["id"] == "printf_mismatch"]
+        extra = {"printf_mismatch": {"functions": ["Logf:1", "Infof"]}}
+        entries, _ = detect_smells(root, rule_options=extra)
+    [entry] = [e for e in entries if e["id"] == "printf_mismatch"]
+    assert [(m["line"], m["function"], m["problem"]) for m in entry["matches"]] == [
+        (10, "l.Logf", "too_few_args"),
+        (11, "Infof", "type_mismatch"),
+    ]
+
+
+def test_atomic_mixed_access_can_leave_out_init_and_tests(tmp_path, monkeypatch):
+    root = tmp_path / "mod"
+    root.mkdir()
+    (root / "p.go").write_text(
+        "package p\n\n"
+        'import "sync/atomic"\n\n'
+        "var hits int64\n\n"
+        "func init() {\n"
+        "\thits = 0\n"
+        "}\n\n"
+        "func Hit() {\n"
+        "\tatomic.AddInt64(&hits, 1)\n"
+        "}\n"
+    )
+    (root / "p_test.go").write_text(
+        "package p\n\n"
+        'import "testing"\n\n'
+        "func TestHit(t *testing.T) {\n"
+        "\tHit()\n"
+        "\tif hits != 1 {\n"
+        "\t\tt.Fatal(hits)\n"
+        "\t}\n"
+        "}\n"
+    )
+    monkeypatch.chdir(root)
+    with runtime_scope(RuntimeContext(project_root=root)):
+        entries, _ = detect_smells(root)
+        [entry] = [e for e in entries if e["id"] == "atomic_mixed_access"]
+        [match] = entry["matches"]
+        assert (match["line"], match["plain"]) == (8, ["p.go:8", "p_test.go:7", "p_test.go:8"])
+        extra = {"atomic_mixed_access": {"exclude": ["init", "tests"]}}
+        entries, _ = detect_smells(root, rule_options=extra)
+    assert not [e for e in entries if e["id"] == "atomic_mixed_access"]
+
+
+def test_resource_not_released_takes_more_pairs_and_follows_labels(tmp_path, monkeypatch):
+    root = tmp_path / "mod"
+    root.mkdir()
+    (root / "p.go").write_text(
+        "package p\n\n"
+        "func Sum(pool *Pool, rows [][]int) int {\n"
+        "\ttotal := 0\n"
+        "outer:\n"
+        "\tfor _, row := range rows {\n"
+        "\t\tconn, err := pool.AcquireConn()\n"
+        "\t\tif err != nil {\n"
+        "\t\t\treturn total\n"
+        "\t\t}\n"
+        "\t\tfor _, v := range row {\n"
+        "\t\t\tif v < 0 {\n"
+        "\t\t\t\tcontinue outer\n"
+        "\t\t\t}\n"
+        "\t\t\ttotal += conn.Add(v)\n"
+        "\t\t}\n"
+        "\t\tconn.Release()\n"
+        "\t}\n"
+        "\treturn total\n"
+        "}\n"
+    )
+    monkeypatch.chdir(root)
+    with runtime_scope(RuntimeContext(project_root=root)):
+        entries, _ = detect_smells(root)
+        assert not [e for e in entries if e["id"] == "resource_not_released"]
+        extra = {"resource_not_released": {"pairs": ["AcquireConn:Release|Close"]}}
+        entries, _ = detect_smells(root, rule_options=extra)
+    [entry] = [e for e in entries if e["id"] == "resource_not_released"]
+    [match] = entry["matches"]
+    assert (match["line"], match["release"], match["leaks_at"]) == (7, "conn.Release()", 13)
+
+
+def test_sql_rows_misuse_checks_configured_row_types(tmp_path, monkeypatch):
+    root = tmp_path / "mod"
+    root.mkdir()
+    (root / "p.go").write_text(
+        "package p\n\n"
+        'import "github.com/jackc/pgx/v5"\n\n'
+        "func count(rows pgx.Rows) int {\n"
+        "\tn := 0\n"
+        "\tfor rows.Next() {\n"
+        "\t\tn++\n"
+        "\t}\n"
+        "\treturn n\n"
+        "}\n"
+    )
+    monkeypatch.chdir(root)
+    with runtime_scope(RuntimeContext(project_root=root)):
+        entries, _ = detect_smells(root)
+        assert not [e for e in entries if e["id"] == "sql_rows_misuse"]
+        extra = {"sql_rows_misuse": {"types": ["sql.Rows", "pgx.Rows"]}}
+        entries, _ = detect_smells(root, rule_options=extra)
+    [entry] = [e for e in entries if e["id"] == "sql_rows_misuse"]
+    assert [(m["line"], m["problem"]) for m in entry["matches"]] == [(7, "err_unchecked")]
+
+
+def test_json_tag_missing_follows_encoded_structs_when_enabled(tmp_path, monkeypatch):
+    root = tmp_path / "mod"
+    root.mkdir()
+    (root / "types.go").write_text(
+        "package p\n\n"
+        "type Order struct {\n"
+        '\tID    int `json:"id"`\n'
+        "\tItems []Item\n"
+        "\tnote  string\n"
+        "}\n\n"
+        "type Item struct {\n"
+        "\tSKU string\n"
+        "}\n\n"
+        "type Internal struct {\n"
+        "\tState string\n"
+        "}\n"
+    )
+    (root / "api.go").write_text(
+        "package p\n\n"
+        'import "encoding/json"\n\n'
+        "func Save(o *Order) ([]byte, error) {\n"
+        "\treturn json.Marshal(o)\n"
+        "}\n"
+    )
+    monkeypatch.chdir(root)
+    with runtime_scope(RuntimeContext(project_root=root)):
+        entries, _ = detect_smells(root)
+        assert not [e for e in entries if e["id"] == "json_tag_missing"]
+        extra = {"json_tag_missing": {"enabled": True}}
+        entries, _ = detect_smells(root, rule_options=extra)
+    [entry] = [e for e in entries if e["id"] == "json_tag_missing"]
+    assert [(m["struct"], m["field"]) for m in entry["matches"]] == [
+        ("Order", "Items"),
+        ("Item", "SKU"),
+    ]
+
+
+def test_unkeyed_struct_literal_threshold_and_small_elements(tmp_path, monkeypatch):
+    root = tmp_path / "mod"
+    root.mkdir()
+    (root / "p.go").write_text(
+        "package p\n\n"
+        "type Point struct {\n"
+        "\tX, Y int\n"
+        "}\n\n"
+        "type Span struct {\n"
+        "\tStart, End, Step int\n"
+        "}\n\n"
+        "var origin = Point{0, 0}\n\n"
+        "var spans = []Span{{0, 10, 1}}\n"
+    )
+    monkeypatch.chdir(root)
+    with runtime_scope(RuntimeContext(project_root=root)):
+        entries, _ = detect_smells(root)
+        [entry] = [e for e in entries if e["id"] == "unkeyed_struct_literal"]
+        assert [m["type"] for m in entry["matches"]] == ["Span"]
+        assert entry["matches"][0]["fix"]["new"] == "{Start: 0, End: 10, Step: 1}"
+        extra = {"unkeyed_struct_literal": {"max_fields": 1, "exclude": ["small_elements"]}}
+        entries, _ = detect_smells(root, rule_options=extra)
+    [entry] = [e for e in entries if e["id"] == "unkeyed_struct_literal"]
+    assert [(m["type"], m["fields"]) for m in entry["matches"]] == [("Point", 2)]
+
+
+def test_pure_result_discarded_takes_more_functions(tmp_path, monkeypatch):
+    root = tmp_path / "mod"
+    root.mkdir()
+    (root / "p.go").write_text(
+        "package p\n\n"
+        'import (\n\t"strings"\n\n\t"example.com/units"\n)\n\n'
+        "func Normalize(s string) string {\n"
+        "\tstrings.ToLower(s)\n"
+        "\tunits.Parse(s)\n"
+        "\treturn s\n"
+        "}\n"
+    )
+    monkeypatch.chdir(root)
+    with runtime_scope(RuntimeContext(project_root=root)):
+        entries, _ = detect_smells(root)
+        [entry] = [e for e in entries if e["id"] == "pure_result_discarded"]
+        assert [m["function"] for m in entry["matches"]] == ["strings.ToLower"]
+        extra = {"pure_result_discarded": {"functions": ["units.*"]}}
+        entries, _ = detect_smells(root, rule_options=extra)
+    [entry] = [e for e in entries if e["id"] == "pure_result_discarded"]
+    assert [m["function"] for m in entry["matches"]] == ["strings.ToLower", "units.Parse"]
+
+
+def test_name_length_limits_are_configurable(tmp_path, monkeypatch):
+    root = tmp_path / "mod"
+    root.mkdir()
+    body = "".join(f"\tcc += {i}\n" for i in range(8))
+    (root / "p.go").write_text(
+        "package p\n\n"
+        "func Total() int {\n"
+        "\tcc := 0\n" + body + "\treturn cc\n"
+        "}\n\n"
+        "func averageOfAllTheValues() int {\n"
+        "\treturn 1\n"
+        "}\n"
+    )
+    monkeypatch.chdir(root)
+    with runtime_scope(RuntimeContext(project_root=root)):
+        entries, _ = detect_smells(root)
+        assert not [e for e in entries if e["id"] == "name_length"]
+        extra = {"name_length": {"max_scope_lines": 5, "max_length": 20}}
+        entries, _ = detect_smells(root, rule_options=extra)
+        [entry] = [e for e in entries if e["id"] == "name_length"]
+        assert [(m["name"], m["reason"]) for m in entry["matches"]] == [
+            ("cc", "short"),
+            ("averageOfAllTheValues", "long"),
+        ]
+        assert entry["matches"][0]["scope"] == "4-13"
+        extra["name_length"]["allow"] = ["cc"]
+        entries, _ = detect_smells(root, rule_options=extra)
+    [entry] = [e for e in entries if e["id"] == "name_length"]
+    assert [m["name"] for m in entry["matches"]] == ["averageOfAllTheValues"]
+
+
+def test_package_layout_limits_are_configurable(tmp_path, monkeypatch):
+    root = tmp_path / "mod"
+    store = root / "store"
+    store.mkdir(parents=True)
+    (root / "go.mod").write_text("module example.com/mod\n\ngo 1.21\n")
+    (store / "a.go").write_text("package store\n\nfunc A() int { return 1 }\n")
+    (store / "b.go").write_text("package store\n\nfunc B() int { return 2 }\n")
+    (store / "c.go").write_text(
+        "package store\n\n" + "".join(f"var v{i} = {i}\n" for i in range(120))
+    )
+    (store / "c_test.go").write_text(
+        "package store\n\n" + "".join(f"// case {i}\n" for i in range(200))
+    )
+    monkeypatch.chdir(root)
+    layout = ("package_too_many_files", "file_too_long", "package_test_heavy")
+    with runtime_scope(RuntimeContext(project_root=root)):
+        entries, _ = detect_smells(root)
+        found = {e["id"]: e["matches"] for e in entries if e["id"] in layout}
+        assert list(found) == ["package_test_heavy"]
+        [heavy] = found["package_test_heavy"]
+        assert (heavy["file"], heavy["line"]) == ("store/a.go", 1)
+        assert (heavy["test_lines"], heavy["code_lines"]) == (202, 128)
+        extra = {
+            "package_too_many_files": {"max_files": 2},
+            "file_too_long": {"max_lines": 100},
+        }
+        entries, _ = detect_smells(root, rule_options=extra)
+    found = {e["id"]: e["matches"] for e in entries if e["id"] in layout}
+    assert [m["files"] for m in found["package_too_many_files"]] == [3]
+    assert [(m["file"], m["lines"]) for m in found["file_too_long"]] == [("store/c.go", 122)]
+
+
+def test_package_name_style_reports_plurals_when_asked(tmp_path, monkeypatch):
+    root = tmp_path / "mod"
+    (root / "models").mkdir(parents=True)
+    (root / "strings").mkdir()
+    (root / "go.mod").write_text("module example.com/mod\n\ngo 1.21\n")
+    (root / "models" / "user.go").write_text("package models\n\ntype User struct{}\n")
+    (root / "strings" / "trim.go").write_text("package strings\n\nconst Space = \" \"\n")
+    monkeypatch.chdir(root)
+    with runtime_scope(RuntimeContext(project_root=root)):
+        entries, _ = detect_smells(root)
+        assert not [e for e in entries if e["id"] == "package_name_style"]
+        extra = {"package_name_style": {"reasons": ["plural"]}}
+        entries, _ = detect_smells(root, rule_options=extra)
+    [entry] = [e for e in entries if e["id"] == "package_name_style"]
+    assert [(m["package"], m["reason"]) for m in entry["matches"]] == [("models", "plural")]
+
+
+def test_import_allowlists_are_configurable(tmp_path, monkeypatch):
+    root = tmp_path / "mod"
+    root.mkdir()
+    (root / "p.go").write_text(
+        "package p\n\n"
+        "import (\n"
+        '\t_ "github.com/lib/pq"\n'
+        '\t. "github.com/onsi/gomega"\n'
+        '\t. "example.com/dsl/v2/match"\n'
+        ")\n\n"
+        "func Ok() bool { return Equal(1) != nil && Match() }\n"
+    )
+    monkeypatch.chdir(root)
+    with runtime_scope(RuntimeContext(project_root=root)):
+        entries, _ = detect_smells(root)
+        found = {e["id"]: [m["path"] for m in e["matches"]] for e in entries}
+        assert found["blank_import"] == ["github.com/lib/pq"]
+        assert found["dot_import"] == ["example.com/dsl/v2/match"]
+        extra = {
+            "blank_import": {"allow": ["github.com/lib/pq"]},
+            "dot_import": {"allow": ["example.com/dsl/..."]},
+        }
+        entries, _ = detect_smells(root, rule_options=extra)
+    found = {e["id"]: [m["path"] for m in e["matches"]] for e in entries}
+    assert "blank_import" not in found
+    assert found["dot_import"] == ["github.com/onsi/gomega"]
+
+
+def test_pointer_to_small_type_size_is_configurable(tmp_path, monkeypatch):
+    root = tmp_path / "mod"
+    root.mkdir()
+    (root / "p.go").write_text(
+        "package p\n\n"
+        "type Span struct{ Start, End, Step int }\n\n"
+        "func Width(s *Span) int { return s.End - s.Start }\n\n"
+        "func Twice(n *int64) int64 { return *n * 2 }\n"
+    )
+    monkeypatch.chdir(root)
+    with runtime_scope(RuntimeContext(project_root=root)):
+        entries, _ = detect_smells(root)
+        found = {e["id"]: e["matches"] for e in entries}
+        assert [(m["name"], m["size"]) for m in found["pointer_to_small_type"]] == [("n", 8)]
+        extra = {"pointer_to_small_type": {"max_bytes": 24}}
+        entries, _ = detect_smells(root, rule_options=extra)
+    found = {e["id"]: e["matches"] for e in entries}
+    matches = sorted(found["pointer_to_small_type"], key=lambda m: m["line"])
+    assert [(m["name"], m["size"]) for m in matches] == [("s", 24), ("n", 8)]
+
+
+def test_error_only_logged_best_effort_prefixes_are_configurable(tmp_path, monkeypatch):
+    root = tmp_path / "mod"
+    root.mkdir()
+    (root / "p.go").write_text(
+        "package p\n\n"
+        'import (\n\t"log"\n\t"os"\n)\n\n'
+        "func tryClose(f *os.File) error {\n"
+        "\tif err := f.Close(); err != nil {\n\t\tlog.Println(err)\n\t}\n"
+        "\treturn nil\n}\n\n"
+        "func quietSync(f *os.File) error {\n"
+        "\tif err := f.Sync(); err != nil {\n\t\tlog.Println(err)\n\t}\n"
+        "\treturn nil\n}\n"
+    )
+    monkeypatch.chdir(root)
+    with runtime_scope(RuntimeContext(project_root=root)):
+        entries, _ = detect_smells(root)
+        found = {e["id"]: [m["line"] for m in e["matches"]] for e in entries}
+        assert found["error_only_logged"] == [16]
+        extra = {"error_only_logged": {"best_effort": ["quiet"]}}
+        entries, _ = detect_smells(root, rule_options=extra)
+    found = {e["id"]: [m["line"] for m in e["matches"]] for e in entries}
+    assert found["error_only_logged"] == [9]
+
+
+def test_time_not_utc_is_opt_in(tmp_path, monkeypatch):
+    root = tmp_path / "mod"
+    root.mkdir()
+    (root / "p.go").write_text(
+        "package p\n\n"
+        'import (\n\t"database/sql"\n\t"log"\n\t"time"\n)\n\n'
+        "func Touch(db *sql.DB, id int) error {\n"
+        "\tnow := time.Now()\n"
+        '\tlog.Printf("touch %d at %s", id, time.Now().Format(time.RFC3339))\n'
+        '\t_, err := db.Exec("UPDATE t SET at = ? WHERE id = ?", now, id)\n'
+        '\t_, err = db.Exec("UPDATE t SET seen = ?", time.Now().UTC())\n'
+        "\tlog.Println(time.Since(now))\n"
+        "\treturn err\n"
+        "}\n"
+    )
+    monkeypatch.chdir(root)
+    with runtime_scope(RuntimeContext(project_root=root)):
+        entries, _ = detect_smells(root)
+        assert "time_not_utc" not in {e["id"] for e in entries}
+        entries, _ = detect_smells(root, rule_options={"time_not_utc": {"enabled": True}})
+    [entry] = [e for e in entries if e["id"] == "time_not_utc"]
+    assert [(m["line"], m["use"]) for m in entry["matches"]] == [(11, "format"), (12, "sql")]
+
+
+def test_unused_field_counts_tests_and_other_platforms(tmp_path, monkeypatch):
+    root = tmp_path / "mod"
+    root.mkdir()
+    (root / "go.mod").write_text("module example.com/mod\n\ngo 1.21\n")
+    (root / "conn.go").write_text(
+        "package p\n\n"
+        "type Conn struct {\n"
+        "\thandle uintptr\n"
+        "\tdebug  bool\n"
+        "\tstale  int\n"
+        "}\n"
+    )
+    (root / "conn_windows.go").write_text(
+        "package p\n\nfunc (c *Conn) Fd() uintptr { return c.handle }\n"
+    )
+    (root / "conn_test.go").write_text(
+        "package p\n\nfunc debugConn() *Conn { return &Conn{debug: true} }\n"
+    )
+    monkeypatch.chdir(root)
+    with runtime_scope(RuntimeContext(project_root=root)):
+        entries, _ = detect_smells(root)
+    [entry] = [e for e in entries if e["id"] == "unused_field"]
+    assert [(m["line"], m["field"]) for m in entry["matches"]] == [(6, "stale")]
+
+
+def test_variable_reuse_is_opt_in_and_skips_updates(tmp_path, monkeypatch):
+    root = tmp_path / "mod"
+    root.mkdir()
+    (root / "p.go").write_text(
+        "package p\n\n"
+        'import (\n\t"encoding/json"\n\t"fmt"\n\t"io"\n\t"log"\n\t"net/http"\n'
+        '\t"strconv"\n\t"strings"\n)\n\n'
+        "func Describe(r *http.Request, n int) {\n"
+        "\tdata, err := io.ReadAll(r.Body)\n"
+        "\tif err != nil {\n\t\treturn\n\t}\n"
+        "\tlog.Println(string(data))\n"
+        "\tdata = []byte(r.URL.Path)\n"
+        "\tlog.Println(string(data))\n"
+        "\tdata = strconv.AppendInt(nil, int64(n), 10)\n"
+        "\tlog.Println(string(data))\n"
+        '\tdata = json.RawMessage("{}")\n'
+        "\tlog.Println(string(data))\n"
+        '\tdata = fmt.Appendf(nil, "%d", n)\n'
+        "\tlog.Println(string(data))\n"
+        "}\n\n"
+        "func Clean(s string) string {\n"
+        "\tname := strings.TrimSpace(s)\n"
+        "\tname = strings.ToLower(name)\n"
+        "\tname = fmt.Sprint(name, s)\n"
+        "\tname = strconv.Quote(name)\n"
+        "\tname = strings.Repeat(name, 2)\n"
+        "\treturn name\n"
+        "}\n\n"
+        "func Mixed(r *http.Request, n int) {\n"
+        "\tvar data interface{}\n"
+        "\tdata, _ = io.ReadAll(r.Body)\n"
+        "\tlog.Println(data)\n"
+        "\tdata = strconv.Itoa(n)\n"
+        "\tlog.Println(data)\n"
+        "\tdata = []int{1}\n"
+        "\tlog.Println(data)\n"
+        '\tdata = "x"\n'
+        "\tlog.Println(data)\n"
+        "\tdata = strconv.Quote(r.URL.Path)\n"
+        "\tlog.Println(data)\n"
+        "}\n"
+    )
+    monkeypatch.chdir(root)
+    with runtime_scope(RuntimeContext(project_root=root)):
+        entries, _ = detect_smells(root)
+        assert "variable_reuse" not in {e["id"] for e in entries}
+        entries, _ = detect_smells(root, rule_options={"variable_reuse": {"enabled": True}})
+    [entry] = [e for e in entries if e["id"] == "variable_reuse"]
+    assert [(m["variable"], m["sites"]) for m in entry["matches"]] == [
+        ("data", [14, 19, 21, 23, 25]),
+        ("data", [40, 42, 44, 46, 48]),
+    ]
+
+
+def test_blocking_under_lock_kinds_are_configurable(tmp_path, monkeypatch):
+    root = tmp_path / "mod"
+    root.mkdir()
+    (root / "p.go").write_text(
+        "package p\n\n"
+        'import (\n\t"net"\n\t"sync"\n\t"time"\n)\n\n'
+        "var mu sync.RWMutex\n\n"
+        "func Probe(addr string) {\n"
+        "\tmu.RLock()\n"
+        "\tdefer mu.RUnlock()\n"
+        "\t_, _ = net.Dial(\"tcp\", addr)\n"
+        "\ttime.Sleep(time.Millisecond)\n"
+        "}\n"
+    )
+    monkeypatch.chdir(root)
+    extra = {"blocking_under_lock": {"read_blocking": ["network"]}}
+    with runtime_scope(RuntimeContext(project_root=root)):
+        entries, _ = detect_smells(root, rule_options=extra)
+    [entry] = [e for e in entries if e["id"] == "blocking_under_lock"]
+    assert [(m["line"], m["blocking"]) for m in entry["matches"]] == [(14, "network")]
+
+
+def test_blocking_under_lock_takes_more_calls(tmp_path, monkeypatch):
+    root = tmp_path / "mod"
+    root.mkdir()
+    (root / "p.go").write_text(
+        "package p\n\n"
+        'import (\n\t"net/http"\n\t"sync"\n)\n\n'
+        "type Fetcher struct {\n"
+        "\tmu     sync.Mutex\n\tclient *http.Client\n\tc      *http.Client\n"
+        "}\n\n"
+        "func (f *Fetcher) Fetch(url string) {\n"
+        "\tf.mu.Lock()\n"
+        "\tdefer f.mu.Unlock()\n"
+        "\t_, _ = f.client.Get(url)\n"
+        '\t_, _ = f.c.Post(url, "text/plain", nil)\n'
+        "}\n"
+    )
+    monkeypatch.chdir(root)
+    with runtime_scope(RuntimeContext(project_root=root)):
+        entries, _ = detect_smells(root)
+        [entry] = [e for e in entries if e["id"] == "blocking_under_lock"]
+        assert [(m["line"], m["blocking"]) for m in entry["matches"]] == [(17, "network")]
+        extra = {"blocking_under_lock": {"calls": [r"network:\bc\.Post\(", "disk:x", "sql:("]}}
+        entries, _ = detect_smells(root, rule_options=extra)
+    [entry] = [e for e in entries if e["id"] == "blocking_under_lock"]
+    assert [(m["line"], m["blocking"]) for m in entry["matches"]] == [
+        (17, "network"),
+        (18, "network"),
+    ]
+
+
+def test_long_function_suggests_extraction_points(smell_results):
+    results, _ = smell_results
+    [match] = [m for m in results["long_function"]["matches"] if "longfunc/" in m["file"]]
+    # The map literal and the one-statement-per-case switch are not counted.
+    assert (match["function"], match["lines"], match["statements"]) == ("Checkout", 135, 89)
+    assert [(p["start"], p["end"], p["title"]) for p in match["extract"]] == [
+        (27, 68, "Validate inputs"),
+        (85, 137, "Price the order"),
+        (140, 158, "Apply regional tax"),
+    ]
+    assert match["hint"].startswith("consider extracting lines 27–68, 'Validate inputs'; ")
+
+
+def test_long_function_descends_into_a_single_loop(tmp_path, monkeypatch):
+    root = tmp_path / "mod"
+    root.mkdir()
+    step = "\t\tn += len(line)\n"
+    (root / "p.go").write_text(
+        "package p\n\n"
+        "func Scan(lines []string) (n int) {\n"
+        "\tfor _, line := range lines {\n"
+        "\t\t// Count the bytes.\n" + step * 6 + "\n"
+        "\t\t// Count them again.\n" + step * 8 + "\t}\n"
+        "\treturn n\n"
+        "}\n\n"
+        "func Names(k int) string {\n"
+        "\tswitch k {\n" + "".join(f'\tcase {i}:\n\t\treturn "{i}"\n' for i in range(8))
+        + "\t}\n\treturn \"\"\n}\n"
+    )
+    monkeypatch.chdir(root)
+    options = {"long_function": {"max_statements": 10, "exclude": ["composite_literals"]}}
+    with runtime_scope(RuntimeContext(project_root=root)):
+        entries, _ = detect_smells(root, rule_options=options)
+    [entry] = [e for e in entries if e["id"] == "long_function"]
+    scan, names = entry["matches"]
+    assert [(p["start"], p["end"], p["title"]) for p in scan["extract"]] == [
+        (6, 11, "Count the bytes"),
+        (14, 21, "Count them again"),
+    ]
+    # Counting case labels makes the switch long; it has no seams to suggest.
+    assert (names["function"], names["statements"], names["extract"]) == ("Names", 18, [])
+
+
+def test_shared_param_groups_need_three_functions_and_three_params():
+    def sig(name, *params):
+        return Signature(name, "p.go", 1, tuple(tuple(p.split(" ", 1)) for p in params))
+
+    a, b, c = "addr string", "port int", "tls bool"
+    signatures = [
+        sig("One", a, "x int", b, c, "y int"),
+        sig("Two", a, b, "z int", c, "y int"),
+        sig("Three", "w int", a, b, c),
+        sig("Pair", "x int", "y int", "z int"),
+        sig("Other", "x int", "y int", "z int"),
+    ]
+    [cluster] = clusters(signatures)
+    # One and Two also share y, but Three does not: the reported group is the
+    # longest all three have in order.
+    assert cluster.params == (("addr", "string"), ("port", "int"), ("tls", "bool"))
+    assert [s.name for s in cluster.functions] == ["One", "Two", "Three"]
+
+
+def test_parallel_packages_match_serial_output(tmp_path, monkeypatch):
+    root = make_synthetic_tree(tmp_path, packages=6, files_per_package=2, fillers=1)
+    monkeypatch.chdir(root)
+    with runtime_scope(RuntimeContext(project_root=root)):
+        serial, serial_files = detect_smells(root, jobs=1)
+        parallel, parallel_files = detect_smells(root, jobs=3)
+    assert serial and parallel == serial
+    assert parallel_files == serial_files == 12
+    [dupes] = [e for e in parallel if e["id"] == "duplicate_branch"]
+    assert [m["file"] for m in dupes["matches"]] == sorted(
+        m["file"] for m in dupes["matches"]
+    )
+
+
+def test_cached_packages_are_not_rescanned(tmp_path, monkeypatch):
+    root = make_synthetic_tree(tmp_path / "mod", packages=3, files_per_package=1, fillers=0)
+    monkeypatch.chdir(root)
+    cache = ResultCache(tmp_path / "cache")
+    with runtime_scope(RuntimeContext(project_root=root)):
+        cold, _ = detect_smells(root, cache=cache)
+        assert (cache.stats.hits, cache.stats.misses) == (0, 3)
+
+        monkeypatch.setattr(
+            "desloppify.languages.go.detectors.smells._scan_package",
+            lambda *_a, **_k: pytest.fail("cached package was re-analyzed"),
+        )
+        warm, _ = detect_smells(root, cache=cache)
+        assert warm == cold
+        assert (cache.stats.hits, cache.stats.misses) == (3, 3)
+
+
+def test_shared_inspector_walks_each_file_once():
+    paths = sorted(FIXTURES.glob("*.go"))
+    parses, walks, _seconds, shared = count_traversals(paths, shared=True)
+    assert parses == walks == len(paths)
+    isolated_parses, isolated_walks, _seconds, isolated = count_traversals(paths, shared=False)
+    assert isolated_parses > parses and isolated_walks > walks
+    assert shared == isolated
+
+
+@pytest.mark.parametrize(
+    "fixture", ["go", "go_fix", "go_broken", "go_custom_rules", "go_patterns", "go_build_tags"]
+)
+def test_shared_pass_reports_what_per_rule_parses_do(fixture):
+    root = FIXTURES.parent / fixture
+    paths = sorted(p for p in root.rglob("*.go") if not p.name.endswith("_test.go"))
+    custom_rules = tuple(load_custom_rules(root / "config.json"))
+    parses, _walks, _seconds, shared = count_traversals(
+        paths, shared=True, custom_rules=custom_rules
+    )
+    assert parses == len(paths)
+    _parses, _walks, _seconds, isolated = count_traversals(
+        paths, shared=False, custom_rules=custom_rules
+    )
+    assert shared == isolated
+    assert all(shared[rule.id] for rule in custom_rules)
+
+
+def test_timings_charge_traversal_callbacks_to_their_rule(tmp_path, monkeypatch):
+    root = make_synthetic_tree(tmp_path, packages=3, files_per_package=2, fillers=0)
+    monkeypatch.chdir(root)
+    diagnostics = RunDiagnostics(timings=RunTimings())
+    with runtime_scope(RuntimeContext(project_root=root)):
+        plain, _ = detect_smells(root)
+        timed, _ = detect_smells(root, diagnostics=diagnostics)
+    assert timed == plain
+    # Every rule row, not just the slowest few: the ranking depends on timing.
+    report = diagnostics.timings.as_dict(top=len(SMELL_CHECKS) + 2)
+    rules = {row["name"] for row in report["rules"]}
+    assert {"constant_condition", "empty_branch", "duplicate_branch", WALK_RULE} <= rules
+    assert sorted(row["name"] for row in report["packages"]) == [
+        f"internal/pkg{p:04d}" for p in range(3)
+    ]
+
+
+def test_category_flag_runs_exactly_the_concurrency_rules(tmp_path, monkeypatch):
+    root = make_synthetic_tree(tmp_path, packages=2, files_per_package=1, fillers=0)
+    monkeypatch.chdir(root)
+    args = create_parser(langs=["go"], detector_names=[]).parse_args(
+        ["scan", "--category", "concurrency"]
+    )
+    lang = make_lang_run(get_lang("go"))
+    apply_rule_categories(args, lang)
+    rule_options = select_categories(None, lang.runtime_setting("categories"))
+    diagnostics = RunDiagnostics(timings=RunTimings())
+    with runtime_scope(RuntimeContext(project_root=root)):
+        detect_smells(root, rule_options=rule_options, diagnostics=diagnostics)
+    report = diagnostics.timings.as_dict(top=len(SMELL_CHECKS) + 2)
+    assert {row["name"] for row in report["rules"]} - {PARSE_RULE, WALK_RULE} == {
+        "atomic_mixed_access",
+        "blocking_under_lock",
+        "busy_select_default",
+        "channel_as_mutex",
+        "fire_and_forget_goroutine",
+        "goroutine_index_capture",
+        "goroutine_send_leak",
+        "loop_ignores_context",
+        "magic_channel_buffer",
+        "mutex_unlock_missing",
+        "racy_lazy_init",
+        "send_after_close",
+        "single_case_select",
+        "time_tick_leak",
+        "unbuffered_signal",
+        "waitgroup_add_in_goroutine",
+    }
+
+
+def test_excluded_categories_win_over_enabled_rules():
+    configured = {"too_many_params": {"max": 7, "enabled": True}}
+    selected = select_categories(configured, exclude=["style"])
+    assert selected["too_many_params"] == {"max": 7, "enabled": False}
+    assert "nil_map_write" not in selected
+    assert select_categories(configured) is configured
+
+
+def test_entries_keep_the_first_matches_by_file_and_line(tmp_path, monkeypatch):
+    root = make_synthetic_tree(tmp_path, packages=60, files_per_package=1, fillers=0)
+    monkeypatch.chdir(root)
+    with runtime_scope(RuntimeContext(project_root=root)):
+        entries, _ = detect_smells(root)
+    [dupes] = [e for e in entries if e["id"] == "duplicate_branch"]
+    assert dupes["count"] == dupes["files"] == 60
+    assert [m["file"] for m in dupes["matches"]] == [
+        f"internal/pkg{p:04d}/file0.go" for p in range(50)
+    ]
+
+
+def test_ten_thousand_file_module_stays_under_rss_ceiling(tmp_path):
+    # A fresh interpreter, so the pytest process's own footprint is not counted.
+    result = subprocess.run(
+        [
+            sys.executable,
+            "-m",
+            "desloppify.languages.go.tests.bench_smells",
+            "--packages",
+            "2500",
+            "--files-per-package",
+            "4",
+            "--rss-ceiling",
+            "128",
+        ],
+        cwd=tmp_path,
+        env={**os.environ, "PYTHONPATH": str(Path(__file__).resolve().parents[4])},
+        capture_output=True,
+        text=True,
+        check=False,
+    )
+    assert result.returncode == 0, result.stdout + result.stderr
+    assert result.stdout.startswith("10000 files")
+
+
+def test_rule_benchmarks_cover_every_rule():
+    out = io.StringIO()
+    results = run_benchmarks("Rule/", benchtime="2x", out=out)
+    assert [r.name for r in results] == [
+        f"BenchmarkRule/{check['id']}" for check in SMELL_CHECKS
+    ]
+    assert all(r.n == 2 and r.ns_per_op > 0 for r in results)
+    assert out.getvalue().splitlines()[0].split()[:2] == [results[0].name, "2"]
+
+
+def test_benchmark_selection_and_memory_column():
+    out = io.StringIO()
+    results = run_benchmarks("Parse|AllRules", benchtime="1x", benchmem=True, out=out)
+    assert [r.name for r in results] == ["BenchmarkAllRules", "BenchmarkParse"]
+    for line in out.getvalue().splitlines():
+        assert line.endswith("peak-B/op") and " ns/op " in line
+
+
+def test_slowest_rules_rank_per_rule_results_only():
+    results = [
+        Result("BenchmarkParse", 1, 900.0),
+        Result("BenchmarkRule/a", 1, 100.0),
+        Result("BenchmarkRule/b", 1, 300.0),
+    ]
+    assert slowest_rules(results, 1) == [("b", 300.0, 0.75)]
+
+
+def test_rule_benchmark_cli(capsys):
+    argv = ["-bench", "Rule/yoda", "-benchtime", "1x", "-files", "2", "-slowest", "1"]
+    assert bench_rules_main(argv) == 0
+    out = capsys.readouterr().out
+    assert out.startswith("BenchmarkRule/yoda_condition")
+    assert "Slowest 1 rules:" in out
+    assert bench_rules_main(["-bench", "NoSuchBenchmark", "-benchtime", "1x"]) == 1
+
+
+def test_else_after_return(smell_results):
+    results, _ = smell_results
+    matches = [
+        m
+        for m in results["else_after_return"]["matches"]
+        if m["file"].endswith("elseret.go")
+    ]
+    # else-if chains and ifs that fall through keep their else.
+    assert [(m["line"], "fix" in m) for m in matches] == [(8, True)]
+
+
+def test_bool_literal_return(smell_results):
+    results, _ = smell_results
+    matches = [
+        m
+        for m in results["bool_literal_return"]["matches"]
+        if m["file"].endswith("boolret.go")
+    ]
+    assert [(m["line"], m["fix"]["new"]) for m in matches] == [
+        (4, "return n < 10"),
+        (11, "return !(n < 100)"),
+    ]
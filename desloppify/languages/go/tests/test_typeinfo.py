@@ -0,0 +1,330 @@
+"""Tests for ``go/types`` info on the Go ``Pass`` (``desloppify.languages.go.typeinfo``)."""
+
+from __future__ import annotations
+
+import re
+import shutil
+
+import pytest
+
+from desloppify.core.runtime_state import RuntimeContext, runtime_scope
+from desloppify.languages.go import typeinfo
+from desloppify.languages.go.detectors.smells import detect_smells
+from desloppify.languages.go.rules import Rule, register_rule, unregister_rules
+
+needs_go = pytest.mark.skipif(shutil.which("go") is None, reason="Go toolchain not installed")
+
+_SOURCE = """package p
+
+import "bytes"
+
+type Celsius float64
+
+// Same says whether two readings match — exactly.
+func Same(a, b float64, c Celsius, n int) bool {
+	var buf bytes.Buffer
+	buf.WriteString("é")
+	return a == b || c == 0 || n == 1
+}
+"""
+_EQ_RE = re.compile(r"(\w+) == ")
+
+
+def _float_equality(pass_):
+    """Reports ``x == ...`` where ``x`` is floating point, with the type seen."""
+    for m in _EQ_RE.finditer(pass_.file.masked):
+        typ = pass_.type_of(m.start(1))
+        if typ in ("float64", "Celsius"):
+            pass_.report(pass_.file.line_at(m.start()), type=typ)
+
+
+@pytest.fixture
+def module(tmp_path, monkeypatch):
+    root = tmp_path / "mod"
+    (root / "p").mkdir(parents=True)
+    (root / "go.mod").write_text("module example.com/mod\n\ngo 1.21\n")
+    (root / "p" / "p.go").write_text(_SOURCE)
+    monkeypatch.chdir(root)
+    with runtime_scope(RuntimeContext(project_root=root)):
+        yield root
+    unregister_rules()
+
+
+def _register(requires: str) -> None:
+    register_rule(Rule("float_eq", "== on floats", _float_equality, requires=requires))
+
+
+@needs_go
+def test_type_aware_rules_get_types_from_go_types(module):
+    _register("types")
+    entries, _ = detect_smells(module)
+    [entry] = entries
+    assert [(m["line"], m["type"]) for m in entry["matches"]] == [
+        (11, "float64"),
+        (11, "Celsius"),
+    ]
+
+
+@needs_go
+def test_type_of_picks_the_shortest_expression_or_an_exact_span(module):
+    content = (module / "p" / "p.go").read_text()
+    info = typeinfo.check_package({"p/p.go": content})
+    assert info.errors == []
+    # Offsets are characters even past the non-ASCII comment and literal.
+    buf = content.index("buf.WriteString")
+    assert info.type_of("p/p.go", buf) == "bytes.Buffer"
+    call_end = content.index(")", buf) + 1
+    assert info.type_of("p/p.go", buf, call_end) == "(n int, err error)"
+    assert info.type_of("p/p.go", buf, call_end - 1) is None
+
+
+def test_packages_are_not_type_checked_without_a_type_aware_rule(module, monkeypatch):
+    calls = []
+    monkeypatch.setattr(
+        typeinfo, "check_package", lambda files: calls.append(files) or typeinfo.TypesInfo()
+    )
+    # A syntax rule asking anyway gets nothing rather than a type-check.
+    _register("syntax")
+    assert detect_smells(module)[0] == []
+    assert calls == []
+    unregister_rules()
+    _register("types")
+    detect_smells(module, syntax_only=True)
+    assert calls == []
+    detect_smells(module)
+    assert [sorted(files) for files in calls] == [["p/p.go"]]
+
+
+@needs_go
+def test_struct_literals_of_other_packages_need_a_type_checked_scan(tmp_path, monkeypatch):
+    root = tmp_path / "mod"
+    root.mkdir()
+    (root / "go.mod").write_text("module example.com/mod\n\ngo 1.21\n")
+    (root / "p.go").write_text('package p\n\nimport "image"\n\nvar origin = image.Point{0, 0}\n')
+    monkeypatch.chdir(root)
+    with runtime_scope(RuntimeContext(project_root=root)):
+        entries, _ = detect_smells(root)
+        assert not [e for e in entries if e["id"] == "unkeyed_struct_literal"]
+        alignment = {"struct_field_alignment": {"enabled": True}}
+        entries, _ = detect_smells(root, rule_options=alignment)
+    [entry] = [e for e in entries if e["id"] == "unkeyed_struct_literal"]
+    [match] = entry["matches"]
+    assert (match["foreign"], match["fix"]["new"]) == (True, "image.Point{X: 0, Y: 0}")
+
+
+@needs_go
+def test_nil_checks_on_operands_of_unknown_type_need_a_type_checked_scan(tmp_path, monkeypatch):
+    root = tmp_path / "mod"
+    root.mkdir()
+    (root / "go.mod").write_text("module example.com/mod\n\ngo 1.21\n")
+    (root / "p.go").write_text(
+        "package p\n\n"
+        'import "strings"\n\n'
+        "func Fields(s string) bool {\n"
+        "\tparts := strings.Fields(s)\n"
+        "\treturn parts != nil && len(parts) > 0\n"
+        "}\n"
+    )
+    monkeypatch.chdir(root)
+    with runtime_scope(RuntimeContext(project_root=root)):
+        entries, _ = detect_smells(root)
+        assert not [e for e in entries if e["id"] == "redundant_nil_check"]
+        alignment = {"struct_field_alignment": {"enabled": True}}
+        entries, _ = detect_smells(root, rule_options=alignment)
+    [entry] = [e for e in entries if e["id"] == "redundant_nil_check"]
+    assert [(m["line"], m["operand"]) for m in entry["matches"]] == [(7, "parts")]
+
+
+@needs_go
+def test_always_nil_error_skips_methods_that_other_packages_interfaces_need(
+    tmp_path, monkeypatch
+):
+    root = tmp_path / "mod"
+    root.mkdir()
+    (root / "go.mod").write_text("module example.com/mod\n\ngo 1.21\n")
+    (root / "store.go").write_text(
+        "package p\n\n"
+        'import "io"\n\n'
+        "type Store struct{}\n\n"
+        "func (s *Store) Close() error { return nil }\n\n"
+        "func (s *Store) Flush() error { return nil }\n\n"
+        "func validate(name string) error {\n"
+        '\tif name == "" {\n'
+        "\t\treturn nil\n"
+        "\t}\n"
+        "\treturn nil\n"
+        "}\n\n"
+        "func later() error {\n"
+        "\t// TODO: reject unknown names.\n"
+        "\treturn nil\n"
+        "}\n\n"
+        "var _ io.Closer = (*Store)(nil)\n"
+    )
+    (root / "use.go").write_text(
+        "package p\n\n"
+        "func Use(s *Store) error {\n"
+        '\tif err := validate("x"); err != nil {\n'
+        "\t\treturn err\n"
+        "\t}\n"
+        "\ts.Flush()\n"
+        "\t_ = later()\n"
+        "\terr := s.Flush()\n"
+        "\treturn err\n"
+        "}\n"
+    )
+    monkeypatch.chdir(root)
+    with runtime_scope(RuntimeContext(project_root=root)):
+        entries, _ = detect_smells(root)
+        ids = {e["id"] for e in entries}
+        assert "useless_error_return" in ids and "always_nil_error" not in ids
+        entries, _ = detect_smells(root, rule_options={"always_nil_error": {"enabled": True}})
+    # Both report; the scan's overlap pass folds useless_error_return into it.
+    assert {"useless_error_return", "always_nil_error"} <= {e["id"] for e in entries}
+    [entry] = [e for e in entries if e["id"] == "always_nil_error"]
+    assert [(m["function"], m["calls"], m["checked_calls"]) for m in entry["matches"]] == [
+        ("Store.Flush", 2, 1),
+        ("validate", 1, 1),
+    ]
+
+
+@needs_go
+def test_sprintf_strconv_suggests_the_conversion_for_the_operand_type(tmp_path, monkeypatch):
+    root = tmp_path / "mod"
+    root.mkdir()
+    (root / "go.mod").write_text("module example.com/mod\n\ngo 1.21\n")
+    (root / "p.go").write_text(
+        "package p\n\n"
+        'import "fmt"\n\n'
+        "func Labels(n int, id int64, size uint64, port uint16) []string {\n"
+        "\treturn []string{\n"
+        '\t\tfmt.Sprintf("%d", n),\n'
+        '\t\tfmt.Sprintf("%d", id),\n'
+        '\t\tfmt.Sprintf("%d", size),\n'
+        '\t\tfmt.Sprintf("%d", port),\n'
+        "\t}\n"
+        "}\n"
+    )
+    monkeypatch.chdir(root)
+
+    def suggestions(entries):
+        [entry] = [e for e in entries if e["id"] == "sprintf_strconv"]
+        return [m["suggestion"] for m in entry["matches"]]
+
+    with runtime_scope(RuntimeContext(project_root=root)):
+        assert suggestions(detect_smells(root)[0]) == [
+            "strconv.Itoa(n) for int",
+            "strconv.Itoa(id) for int",
+            "strconv.Itoa(size) for int",
+            "strconv.Itoa(port) for int",
+        ]
+        alignment = {"struct_field_alignment": {"enabled": True}}
+        entries, _ = detect_smells(root, rule_options=alignment)
+    assert suggestions(entries) == [
+        "strconv.Itoa(n)",
+        "strconv.FormatInt(id, 10)",
+        "strconv.FormatUint(size, 10)",
+        "strconv.FormatUint(uint64(port), 10)",
+    ]
+
+
+@needs_go
+def test_self_comparison_is_constant_only_for_operands_that_are_not_floats(
+    tmp_path, monkeypatch
+):
+    root = tmp_path / "mod"
+    root.mkdir()
+    (root / "go.mod").write_text("module example.com/mod\n\ngo 1.21\n")
+    (root / "p.go").write_text(
+        "package p\n\n"
+        "type Celsius float64\n\n"
+        "func Check(n int, f float64, c Celsius, s string) int {\n"
+        "\tif n == n {\n"
+        "\t\treturn 1\n"
+        "\t}\n"
+        "\tif f != f {\n"
+        "\t\treturn 2\n"
+        "\t}\n"
+        "\tif c != c {\n"
+        "\t\treturn 3\n"
+        "\t}\n"
+        "\tif s != s {\n"
+        "\t\treturn 4\n"
+        "\t}\n"
+        "\treturn 0\n"
+        "}\n"
+    )
+    monkeypatch.chdir(root)
+    with runtime_scope(RuntimeContext(project_root=root)):
+        entries, _ = detect_smells(root)
+        assert not [e for e in entries if e["id"] == "constant_condition"]
+        alignment = {"struct_field_alignment": {"enabled": True}}
+        entries, _ = detect_smells(root, rule_options=alignment)
+    [entry] = [e for e in entries if e["id"] == "constant_condition"]
+    assert [(m["line"], m["always"]) for m in entry["matches"]] == [(6, "true"), (15, "false")]
+
+
+def test_type_of_is_none_without_a_toolchain(module, monkeypatch):
+    monkeypatch.setattr(typeinfo, "_helper_binary", lambda: None)
+    info = typeinfo.check_package({"p/p.go": _SOURCE})
+    assert info.type_of("p/p.go", _SOURCE.index("a ==")) is None
+    assert info.errors == ["go/types unavailable: no Go toolchain to build the helper"]
+
+
+@needs_go
+def test_duration_double_unit_with_types_skips_untyped_constants(tmp_path, monkeypatch):
+    root = tmp_path / "mod"
+    root.mkdir()
+    (root / "go.mod").write_text("module example.com/mod\n\ngo 1.21\n")
+    (root / "wait.go").write_text(
+        "package p\n\n"
+        'import "time"\n\n'
+        "const retries = 3\n\n"
+        "func timeout() time.Duration { return time.Minute }\n\n"
+        "func Wait(n int) time.Duration {\n"
+        "\tt := timeout()\n"
+        "\treturn retries*time.Second + t*time.Second + time.Duration(n)*time.Second\n"
+        "}\n"
+    )
+    monkeypatch.chdir(root)
+    with runtime_scope(RuntimeContext(project_root=root)):
+        entries, _ = detect_smells(root)
+        assert not [e for e in entries if e["id"] == "duration_double_unit"]
+        alignment = {"struct_field_alignment": {"enabled": True}}
+        entries, _ = detect_smells(root, rule_options=alignment)
+    [entry] = [e for e in entries if e["id"] == "duration_double_unit"]
+    assert [(m["line"], m["duration"]) for m in entry["matches"]] == [(11, "t")]
+
+
+@needs_go
+def test_loop_runs_once_reports_range_over_slices_with_types(tmp_path, monkeypatch):
+    root = tmp_path / "mod"
+    root.mkdir()
+    (root / "go.mod").write_text("module example.com/mod\n\ngo 1.21\n")
+    (root / "first.go").write_text(
+        "package p\n\n"
+        "func First(xs []int) int {\n"
+        "\tfor _, x := range xs {\n"
+        "\t\treturn x\n"
+        "\t}\n"
+        "\treturn 0\n"
+        "}\n\n"
+        "func Any(m map[string]int) string {\n"
+        "\tfor k := range m {\n"
+        "\t\treturn k\n"
+        "\t}\n"
+        '\treturn ""\n'
+        "}\n"
+    )
+    monkeypatch.chdir(root)
+    with runtime_scope(RuntimeContext(project_root=root)):
+        entries, _ = detect_smells(root)
+        assert not [e for e in entries if e["id"] == "loop_runs_once"]
+        alignment = {"struct_field_alignment": {"enabled": True}}
+        entries, _ = detect_smells(root, rule_options=alignment)
+    [entry] = [e for e in entries if e["id"] == "loop_runs_once"]
+    assert [(m["line"], m["loop"]) for m in entry["matches"]] == [(4, "range")]
+
+
+def test_requires_types():
+    assert Rule("x", "x", lambda pass_: None, requires="types").requires_types()
+    assert not Rule("x", "x", lambda pass_: None).requires_types()
@@ -0,0 +1,79 @@
+"""Tests for skipping vendored and third-party Go code (``vendor/``, ``third_party_paths``)."""
+
+from __future__ import annotations
+
+from argparse import Namespace
+
+import pytest
+
+from desloppify.app.commands.scan.scan_patterns import apply_vendoring
+from desloppify.core.runtime_state import RuntimeContext, runtime_scope
+from desloppify.engine.planning.scan import iter_phase_results
+from desloppify.file_discovery import set_vendored_paths
+from desloppify.languages import get_lang
+from desloppify.languages._framework.base.types import DetectorPhase
+from desloppify.languages._framework.runtime import make_lang_run
+from desloppify.languages.go.extractors import find_go_files, find_vendored_go_files
+from desloppify.languages.go.modules import discover_modules
+
+_FILES = {
+    "go.mod": "module example.com/app\n\ngo 1.21\n",
+    "app/app.go": "package app\n",
+    "vendor/github.com/pkg/errors/errors.go": "package errors\n",
+    "third_party/proto/proto.go": "package proto\n",
+    "internal/forks/yaml/yaml.go": "package yaml\n",
+    "internal/forks/yaml/go.mod": "module gopkg.in/yaml.v3\n",
+}
+
+
+@pytest.fixture
+def project(tmp_path, monkeypatch):
+    for name, content in _FILES.items():
+        (tmp_path / name).parent.mkdir(parents=True, exist_ok=True)
+        (tmp_path / name).write_text(content)
+    monkeypatch.chdir(tmp_path)
+    with runtime_scope(RuntimeContext(project_root=tmp_path)):
+        yield tmp_path
+
+
+def test_vendor_and_third_party_paths_are_skipped_unless_asked_for(project):
+    assert find_go_files(project) == [
+        "app/app.go",
+        "internal/forks/yaml/yaml.go",
+        "third_party/proto/proto.go",
+    ]
+    set_vendored_paths(["third_party", "internal/forks/"])
+    assert find_go_files(project) == ["app/app.go"]
+    assert [m.path for m in discover_modules(project)] == ["example.com/app"]
+    # Graph builders still see every package as a node.
+    assert len(find_go_files(project, vendored=True)) == 4
+    assert find_vendored_go_files(project) == [
+        "internal/forks/yaml/yaml.go",
+        "third_party/proto/proto.go",
+        "vendor/github.com/pkg/errors/errors.go",
+    ]
+    set_vendored_paths(["third_party", "internal/forks"], include=True)
+    assert len(find_go_files(project)) == 4
+    assert find_vendored_go_files(project) == []
+
+
+def test_scan_drops_vendored_findings_and_summarizes_once(project, monkeypatch, capsys):
+    def every_node(path, lang):
+        files = find_go_files(path, vendored=True)
+        return [{"file": f, "detector": "coupling"} for f in files], {"coupling": len(files)}
+
+    lang = make_lang_run(get_lang("go"))
+    monkeypatch.setattr(lang.config, "phases", [DetectorPhase("graph", every_node)])
+    lang.state.runtime_settings["third_party_paths"] = ["internal/forks"]
+    apply_vendoring(Namespace(include_vendor=False), lang)
+    [result] = iter_phase_results(project, lang)
+    assert [f["file"] for f in result.findings] == ["app/app.go", "third_party/proto/proto.go"]
+    assert result.potentials == {"coupling": 4}
+    assert capsys.readouterr().err.count(
+        "Vendored: 2 files in 2 packages not analyzed (--include-vendor to audit them)"
+    ) == 1
+
+    apply_vendoring(Namespace(include_vendor=True), lang)
+    [result] = iter_phase_results(project, lang)
+    assert len(result.findings) == 4
+    assert "Vendored:" not in capsys.readouterr().err
@@ -0,0 +1,80 @@
+"""Tests for the ``go vet -vettool`` shim (``desloppify.languages.go.vettool``)."""
+
+from __future__ import annotations
+
+import os
+import shutil
+import subprocess
+import sys
+from pathlib import Path
+
+import pytest
+
+from desloppify.cli import create_parser
+from desloppify.languages.go.vettool import build_vettool
+
+needs_go = pytest.mark.skipif(shutil.which("go") is None, reason="Go toolchain not installed")
+
+_REPO_ROOT = Path(__file__).resolve().parents[4]
+
+
+@pytest.fixture(scope="module")
+def vettool():
+    binary = build_vettool()
+    assert binary is not None and binary.exists()
+    return binary
+
+
+def test_vettool_command_is_registered():
+    args = create_parser().parse_args(["vettool"])
+    assert args.command == "vettool"
+
+
+def test_all_matches_flag_parses():
+    args = create_parser().parse_args(["scan", "--stream", "--all-matches"])
+    assert args.all_matches is True
+
+
+@needs_go
+def test_shim_answers_go_vet_handshake(vettool):
+    version = subprocess.run([str(vettool), "-V=full"], capture_output=True, text=True)
+    assert version.returncode == 0
+    assert version.stdout.startswith("desloppify-vet version devel buildID=")
+
+    flags = subprocess.run([str(vettool), "-flags"], capture_output=True, text=True)
+    assert flags.returncode == 0
+    assert flags.stdout.strip() == "[]"
+
+
+@needs_go
+def test_go_vet_reports_smells_through_the_shim(vettool, tmp_path):
+    root = tmp_path / "mod"
+    (root / "pkg").mkdir(parents=True)
+    (root / "go.mod").write_text("module example.com/mod\n\ngo 1.21\n")
+    (root / "pkg" / "count.go").write_text(
+        'package pkg\n\nimport "fmt"\n\n'
+        "func Count(n int) string {\n"
+        '\treturn fmt.Sprintf("%d", n)\n'
+        "}\n"
+    )
+    (root / "pkg" / "clean.go").write_text("package pkg\n\nfunc Clean() int { return 1 }\n")
+    env = {
+        **os.environ,
+        "DESLOPPIFY": f"{sys.executable} -m desloppify",
+        "PYTHONPATH": os.pathsep.join(
+            p for p in (str(_REPO_ROOT), os.environ.get("PYTHONPATH", "")) if p
+        ),
+    }
+    result = subprocess.run(
+        ["go", "vet", f"-vettool={vettool}", "./..."],
+        cwd=root,
+        env=env,
+        capture_output=True,
+        text=True,
+        timeout=300,
+    )
+    assert result.returncode != 0
+    diagnostics = [line for line in result.stderr.splitlines() if "(sprintf_strconv)" in line]
+    assert len(diagnostics) == 1, result.stderr
+    assert diagnostics[0].startswith(os.path.join("pkg", "count.go") + ":6: ")
+    assert "clean.go" not in result.stderr
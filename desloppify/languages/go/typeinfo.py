@@ -0,0 +1,136 @@
+"""Expression types from ``go/types``, for rules that need more than syntax.
+
+``helpers/typeinfo`` is a small stdlib-only Go program that type-checks one
+package and prints the type of every expression and declared name. It is
+built once per helper source into the user cache dir and run per package,
+only when a rule that declares ``requires="types"`` asks for it. Without a
+Go toolchain, or when the package does not type-check, rules get whatever
+types could still be inferred (possibly none) plus the errors.
+"""
+
+from __future__ import annotations
+
+import bisect
+import hashlib
+import json
+import os
+import shutil
+import tempfile
+from dataclasses import dataclass, field
+from pathlib import Path
+
+from desloppify.core.result_cache import user_cache_dir
+from desloppify.languages.go.health import _run
+
+HELPER_SOURCE = Path(__file__).parent / "helpers" / "typeinfo" / "main.go"
+
+
+@dataclass
+class TypesInfo:
+    """Types by source span for the files of one package, like ``types.Info``.
+
+    Spans are character offsets into each file's content, as ``GoFile`` uses.
+    """
+
+    # path -> sorted (start, end, type) spans.
+    spans: dict[str, list[tuple[int, int, str]]] = field(default_factory=dict)
+    # path -> sorted (start, end, interface) spans of method names.
+    implements: dict[str, list[tuple[int, int, str]]] = field(default_factory=dict)
+    errors: list[str] = field(default_factory=list)
+
+    def type_of(self, path: str, start: int, end: int | None = None) -> str | None:
+        """Type of the expression spanning ``start``..``end`` in ``path``.
+
+        Without ``end``, the shortest expression starting at ``start``: an
+        identifier or literal rather than the call or selector it begins.
+        """
+        spans = self.spans.get(path, [])
+        index = bisect.bisect_left(spans, (start,))
+        while index < len(spans) and spans[index][0] == start:
+            if end is None or spans[index][1] == end:
+                return spans[index][2]
+            index += 1
+        return None
+
+    def interfaces_of(self, path: str, start: int) -> list[str]:
+        """Interfaces of other packages that need the method named at ``start``."""
+        spans = self.implements.get(path, [])
+        index = bisect.bisect_left(spans, (start,))
+        found = []
+        while index < len(spans) and spans[index][0] == start:
+            found.append(spans[index][2])
+            index += 1
+        return found
+
+
+def build_helper(source_path: Path, name: str) -> Path | None:
+    """The helper built from ``source_path``, building it first if needed.
+
+    Binaries live in the user cache dir, one per source digest. None without
+    ``go`` or when the build fails.
+    """
+    source = source_path.read_bytes()
+    digest = hashlib.sha1(source).hexdigest()[:16]
+    binary = user_cache_dir() / f"go-{name}" / digest / (
+        f"{name}.exe" if os.name == "nt" else name
+    )
+    if binary.exists():
+        return binary
+    if shutil.which("go") is None:
+        return None
+    binary.parent.mkdir(parents=True, exist_ok=True)
+    with tempfile.TemporaryDirectory(dir=binary.parent) as tmp:
+        built = Path(tmp) / binary.name
+        result = _run(["go", "build", "-o", str(built), str(source_path)], source_path.parent)
+        if result is None or result.returncode != 0 or not built.exists():
+            return None
+        os.replace(built, binary)
+    return binary
+
+
+def _helper_binary() -> Path | None:
+    """The built helper, building it first if needed; None without ``go``."""
+    return build_helper(HELPER_SOURCE, "typeinfo")
+
+
+def _char_offsets(content: str) -> list[int] | None:
+    """Byte offset -> char offset table for ``content``; None when ASCII."""
+    if content.isascii():
+        return None
+    table: list[int] = []
+    for index, char in enumerate(content):
+        table.extend([index] * len(char.encode()))
+    table.append(len(content))
+    return table
+
+
+def check_package(files: dict[str, str]) -> TypesInfo:
+    """Type-check one package given as {path: content}."""
+    if not files:
+        return TypesInfo()
+    binary = _helper_binary()
+    if binary is None:
+        return TypesInfo(errors=["go/types unavailable: no Go toolchain to build the helper"])
+    by_abs = {str(Path(path).resolve()): path for path in files}
+    cwd = Path(next(iter(by_abs))).parent
+    result = _run([str(binary), *by_abs], cwd)
+    if result is None:
+        return TypesInfo(errors=["go/types helper did not finish"])
+    try:
+        raw = json.loads(result.stdout)
+    except json.JSONDecodeError:
+        return TypesInfo(errors=[result.stderr.strip() or "go/types helper failed"])
+    info = TypesInfo(errors=list(raw.get("errors") or []))
+    for key, target in (("files", info.spans), ("implements", info.implements)):
+        for abs_path, spans in (raw.get(key) or {}).items():
+            path = by_abs.get(abs_path)
+            if path is None:
+                continue
+            table = _char_offsets(files[path])
+            if table is not None:
+                spans = [(table[start], table[end], typ) for start, end, typ in spans]
+            target[path] = [tuple(span) for span in spans]
+    return info
+
+
+__all__ = ["HELPER_SOURCE", "TypesInfo", "build_helper", "check_package"]
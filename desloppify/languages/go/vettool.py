@@ -0,0 +1,24 @@
+"""The ``go vet -vettool`` shim, so go vet reports desloppify's Go smells.
+
+``helpers/vettool`` is a small stdlib-only Go program that speaks go vet's
+unitchecker protocol: for each package it reads the JSON config go vet
+writes, runs ``desloppify scan --stream`` on the package from its module
+root and prints the smells in the package's files as vet diagnostics. It is
+built like the typeinfo helper, once per source into the user cache dir.
+"""
+
+from __future__ import annotations
+
+from pathlib import Path
+
+from desloppify.languages.go.typeinfo import build_helper
+
+SOURCE = Path(__file__).parent / "helpers" / "vettool" / "main.go"
+
+
+def build_vettool() -> Path | None:
+    """The built shim, building it first if needed; None without ``go``."""
+    return build_helper(SOURCE, "desloppify-vet")
+
+
+__all__ = ["SOURCE", "build_vettool"]
@@ -1,17 +1,9 @@
 """Shared fixer utilities: bracket tracking, body extraction, fixer template."""
 
-import logging
 import re
-import sys
-from pathlib import Path
 
-from desloppify.core._internal.text_utils import PROJECT_ROOT
-from desloppify.core.fallbacks import log_best_effort_failure
-from desloppify.file_discovery import rel, safe_write_text
+from desloppify.languages._framework.fixers import apply_fixer as _apply_fixer
 from desloppify.languages.typescript.detectors._smell_helpers import scan_code
-from desloppify.utils import colorize
-
-logger = logging.getLogger(__name__)
 
 _CHAR_DEPTH_DELTA: dict[str, tuple[str, int]] = {
     "(": ("parens", 1),
@@ -23,16 +15,6 @@ _CHAR_DEPTH_DELTA: dict[str, tuple[str, int]] = {
 }
 
 
-def _group_entries(entries: list[dict], file_key: str) -> dict[str, list[dict]]:
-    grouped: dict[str, list[dict]] = {}
-    for entry in entries:
-        filepath = entry.get(file_key)
-        if not isinstance(filepath, str) or not filepath:
-            continue
-        grouped.setdefault(filepath, []).append(entry)
-    return grouped
-
-
 def find_balanced_end(
     lines: list[str], start: int, *, track: str = "parens", max_lines: int = 80
 ) -> int | None:
@@ -110,70 +92,15 @@ def apply_fixer(
 
     Groups *entries* by file, reads each file, calls
     ``transform_fn(lines, file_entries) -> (new_lines, removed_names)``
-    and writes back if changed.
+    and writes back if changed (or attaches a ``diff`` on dry runs).
     """
-    by_file = _group_entries(entries, file_key)
-    results = []
-    skipped_files: list[tuple[str, str]] = []
-    for filepath, file_entries in sorted(by_file.items()):
-        try:
-            changed = _process_fixer_file(
-                filepath,
-                file_entries,
-                transform_fn=transform_fn,
-                dry_run=dry_run,
-            )
-            if changed is not None:
-                results.append(changed)
-        except (OSError, UnicodeDecodeError) as ex:
-            skipped_files.append((filepath, str(ex)))
-            print(colorize(f"  Skip {rel(filepath)}: {ex}", "yellow"), file=sys.stderr)
-
-    if skipped_files:
-        log_best_effort_failure(
-            logger,
-            f"apply TypeScript fixer across {len(skipped_files)} skipped file(s)",
-            OSError(
-                "; ".join(f"{path}: {reason}" for path, reason in skipped_files[:5])
-            ),
-        )
-
-    return results
-
-
-def _process_fixer_file(
-    filepath: str,
-    file_entries: list[dict],
-    *,
-    transform_fn,
-    dry_run: bool,
-) -> dict[str, object] | None:
-    p = Path(filepath) if Path(filepath).is_absolute() else PROJECT_ROOT / filepath
-    original = p.read_text()
-    lines = original.splitlines(keepends=True)
-
-    new_lines, removed_names = transform_fn(lines, file_entries)
-    new_content = "".join(new_lines)
-    if new_content == original:
-        return None
-
-    if not dry_run:
-        _write_fixer_content(p, new_content)
-
-    lines_removed = len(original.splitlines()) - len(new_content.splitlines())
-    return {
-        "file": filepath,
-        "removed": removed_names,
-        "lines_removed": lines_removed,
-    }
-
-
-def _write_fixer_content(path: Path, content: str) -> None:
-    try:
-        safe_write_text(path, content)
-    except OSError as exc:
-        log_best_effort_failure(logger, f"write TypeScript fixer output {path}", exc)
-        raise
+    return _apply_fixer(
+        entries,
+        transform_fn,
+        dry_run=dry_run,
+        file_key=file_key,
+        lang_label="TypeScript",
+    )
 
 
 def collapse_blank_lines(
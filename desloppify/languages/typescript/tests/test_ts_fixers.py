@@ -186,6 +186,7 @@ class TestCommonApplyFixer:
         results = apply_fixer(entries, transform, dry_run=True)
         assert len(results) == 1
         assert ts_file.read_text() == original
+        assert "-line_b\n" in results[0]["diff"]
 
     def test_no_change_no_result(self, tmp_path):
         """If transform returns the same content, no result is produced."""
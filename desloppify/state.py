@@ -57,6 +57,7 @@ from desloppify.engine._state.schema import (
     validate_state_invariants,
 )
 from desloppify.engine._state.scoring import (
+    owner_scoped_state,
     suppression_metrics,
 )
 
@@ -108,6 +109,7 @@ __all__ = [
     "get_objective_score",
     "get_overall_score",
     "open_scope_breakdown",
+    "owner_scoped_state",
     "get_strict_score",
     "get_verified_strict_score",
     "is_ignored",
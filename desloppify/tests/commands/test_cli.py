@@ -11,6 +11,7 @@ import pytest
 
 import desloppify.app.commands.helpers.lang as lang_helpers_mod
 import desloppify.cli as cli_mod
+from desloppify.app.cli_support.parser import expand_scan_shorthand
 from desloppify.app.commands.helpers.lang import resolve_lang, resolve_lang_settings
 from desloppify.app.commands.helpers.query import write_query
 from desloppify.app.commands.helpers.runtime_options import resolve_lang_runtime_options
@@ -99,6 +100,23 @@ class TestCreateParser:
         )
         assert args.exclude == ["node_modules", "dist"]
 
+    def test_scan_with_patterns_and_tags(self, parser):
+        args = parser.parse_args(["scan", "./internal/...", "./cmd/...", "-tags", "debug"])
+        assert args.patterns == ["./internal/...", "./cmd/..."]
+        assert args.tags == "debug"
+
+    def test_scan_with_modules(self, parser):
+        args = parser.parse_args(["scan", "--module", "services/api", "--module", "example.com/b"])
+        assert args.module == ["services/api", "example.com/b"]
+
+    def test_bare_pattern_is_shorthand_for_scan(self, parser):
+        assert expand_scan_shorthand(["scan", "./..."]) == ["scan", "./..."]
+        assert expand_scan_shorthand(["show", "x..."]) == ["show", "x..."]
+        argv = expand_scan_shorthand(["--lang", "go", "./...", "--tags", "debug"])
+        assert argv == ["--lang", "go", "scan", "./...", "--tags", "debug"]
+        args = parser.parse_args(argv)
+        assert (args.command, args.patterns, args.tags) == ("scan", ["./..."], "debug")
+
     def test_status_command(self, parser):
         args = parser.parse_args(["status"])
         assert args.command == "status"
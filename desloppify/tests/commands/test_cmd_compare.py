@@ -0,0 +1,122 @@
+"""Tests for the compare command and run matching (``desloppify.engine.compare``)."""
+
+from __future__ import annotations
+
+import json
+from types import SimpleNamespace
+
+import pytest
+
+from desloppify.app.commands.compare_cmd import cmd_compare
+from desloppify.app.commands.helpers.exit_codes import ERROR_EXIT_CODE, FINDINGS_EXIT_CODE
+from desloppify.core.runtime_state import RuntimeContext, runtime_scope
+from desloppify.engine.compare import MOVED, PROBABLY_MOVED, Run, compare_runs
+from desloppify.engine.planning.fingerprint import stamp_fingerprints
+
+
+def _finding(file: str, name: str, *, detector: str = "smells", line: int = 3, **extra) -> dict:
+    return {
+        "id": f"{detector}::{file}::{name}",
+        "file": file,
+        "detector": detector,
+        "summary": f"{name} in {file}",
+        "detail": {"line": line},
+        **extra,
+    }
+
+
+def _state(findings: list[dict], strict: float) -> dict:
+    return {
+        "overall_score": strict + 5,
+        "strict_score": strict,
+        "findings": {f["id"]: {"status": "open", **f} for f in findings},
+    }
+
+
+def test_renamed_file_is_matched_by_fingerprint_and_code(tmp_path, monkeypatch):
+    for name in ("old.go", "new.go"):
+        (tmp_path / name).write_text("package p\n\nfunc Run(a, b, c, d, e, f int) {}\n")
+    monkeypatch.chdir(tmp_path)
+    with runtime_scope(RuntimeContext(project_root=tmp_path)):
+        before = _finding("old.go", "Run")
+        after = _finding("new.go", "Run")
+        stamp_fingerprints(before)
+        stamp_fingerprints(after)
+        # The id changed with the path; the code did not.
+        renamed = _finding("new.go", "Run/renamed")
+        renamed["snippet_hash"] = after["snippet_hash"]
+    assert before["fingerprint"] == after["fingerprint"]
+
+    result = compare_runs(Run([before]), Run([after]))
+    assert (result.introduced, result.resolved) == ([], [])
+    assert [(b["file"], a["file"], how) for b, a, how in result.moved] == [
+        ("old.go", "new.go", MOVED)
+    ]
+    result = compare_runs(Run([before]), Run([renamed]))
+    assert [how for *_, how in result.moved] == [PROBABLY_MOVED]
+
+
+def test_introduced_resolved_and_persisting():
+    kept = _finding("a.go", "todo_fixme")
+    fixed = _finding("a.go", "empty_branch")
+    new = _finding("b.go", "empty_branch")
+    result = compare_runs(Run([kept, fixed]), Run([kept, new]))
+    # Without a code hash, a fingerprint only pairs when it is unique on both
+    # sides; here it is, so b.go's finding is read as a.go's one moved.
+    assert result.moved and not result.introduced
+    other = _finding("c.go", "empty_branch")
+    result = compare_runs(Run([kept, fixed]), Run([kept, new, other]))
+    assert [f["file"] for f in result.introduced] == ["b.go", "c.go"]
+    assert [f["file"] for f in result.resolved] == ["a.go"]
+    assert len(result.persisting) == 1
+
+
+def _compare(tmp_path, old, new, **args) -> int:
+    (tmp_path / "old.json").write_text(old)
+    (tmp_path / "new.json").write_text(new)
+    try:
+        cmd_compare(
+            SimpleNamespace(old=str(tmp_path / "old.json"), new=str(tmp_path / "new.json"), **args)
+        )
+    except SystemExit as exc:
+        return exc.code
+    return 0
+
+
+def test_compare_state_files_as_json_and_gate_on_new_findings(tmp_path, capsys):
+    kept = _finding("a.go", "todo_fixme")
+    fixed = _finding("a.go", "dead_code", detector="structural")
+    added = [_finding("b.go", "magic_number", line=4), _finding("c.go", "magic_number", line=9)]
+    old = json.dumps(_state([kept, fixed], 80.0))
+    new = json.dumps(_state([kept, *added], 82.5))
+
+    assert _compare(tmp_path, old, new, json=True) == FINDINGS_EXIT_CODE
+    payload = json.loads(capsys.readouterr().out)
+    assert [row["file"] for row in payload["introduced"]] == ["b.go", "c.go"]
+    assert [row["id"] for row in payload["resolved"]] == [fixed["id"]]
+    assert payload["persisting"] == 1
+    assert payload["score"]["strict"] == {"old": 80.0, "new": 82.5, "delta": 2.5}
+
+    assert _compare(tmp_path, old, new, max_introduced=2) == 0
+    out = capsys.readouterr().out
+    assert "Introduced: 2  Resolved: 1  Persisting: 1" in out
+    assert "Strict score: 80.0 → 82.5 (+2.5)" in out
+    assert "b.go:4" in out
+
+
+def test_compare_reads_stream_output_and_rejects_other_input(tmp_path, capsys):
+    stream = "\n".join(
+        [json.dumps(_finding("a.go", "todo_fixme")), json.dumps({"summary": {"total": 1}})]
+    )
+    assert _compare(tmp_path, stream, stream) == 0
+    assert "Introduced: 0  Resolved: 0  Persisting: 1" in capsys.readouterr().out
+    assert _compare(tmp_path, stream, "not json") == ERROR_EXIT_CODE
+    assert "new.json:1: not a state file or scan --stream output" in capsys.readouterr().err
+
+
+@pytest.mark.parametrize("status", ["fixed", "wontfix"])
+def test_closed_findings_in_state_are_not_compared(tmp_path, status):
+    gone = _finding("a.go", "todo_fixme")
+    state = _state([gone], 90.0)
+    state["findings"][gone["id"]]["status"] = status
+    assert _compare(tmp_path, json.dumps(_state([], 90.0)), json.dumps(state)) == 0
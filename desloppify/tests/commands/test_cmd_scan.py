@@ -13,6 +13,8 @@ from desloppify.app.commands.scan.scan import (
     _effective_include_slow,
     _format_delta,
     _resolve_scan_profile,
+    _wants_progress,
+    _wants_timings,
     _warn_explicit_lang_with_no_files,
     cmd_scan,
     show_diff_summary,
@@ -238,6 +240,37 @@ class TestScanProfiles:
         assert _effective_include_slow(False, "ci") is False
 
 
+class TestWantsTimings:
+    def test_verbose_implies_timings(self):
+        assert _wants_timings(SimpleNamespace(verbose=True)) is True
+        assert _wants_timings(SimpleNamespace(timings=True)) is True
+        assert _wants_timings(SimpleNamespace()) is False
+
+    def test_verbose_stdin_keeps_its_own_report(self):
+        assert _wants_timings(SimpleNamespace(verbose=True, stdin=True)) is False
+        assert _wants_timings(SimpleNamespace(timings=True, stdin=True)) is True
+
+
+class _Stderr:
+    def __init__(self, tty: bool):
+        self.tty = tty
+
+    def isatty(self) -> bool:
+        return self.tty
+
+
+class TestWantsProgress:
+    def test_piped_stderr_gets_no_progress(self, monkeypatch):
+        monkeypatch.setattr(scan_cmd_mod.sys, "stderr", _Stderr(False))
+        assert _wants_progress(SimpleNamespace()) is False
+
+    def test_terminal_gets_progress_unless_quiet(self, monkeypatch):
+        monkeypatch.setattr(scan_cmd_mod.sys, "stderr", _Stderr(True))
+        assert _wants_progress(SimpleNamespace()) is True
+        assert _wants_progress(SimpleNamespace(quiet=True)) is False
+        assert _wants_progress(SimpleNamespace(stdin=True)) is False
+
+
 # ---------------------------------------------------------------------------
 # _format_delta
 # ---------------------------------------------------------------------------
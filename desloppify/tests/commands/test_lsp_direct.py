@@ -0,0 +1,271 @@
+"""Direct tests for the lsp command (framing, diagnostics, code actions, server)."""
+
+from __future__ import annotations
+
+import io
+import json
+from pathlib import Path
+
+from desloppify.app.commands.lsp.diagnostics import (
+    ENCODING_UTF32,
+    SEVERITY_HINT,
+    SEVERITY_WARNING,
+    code_actions,
+    findings_to_diagnostics,
+    rule_docs_url,
+)
+from desloppify.app.commands.lsp.protocol import MessageWriter, read_message
+from desloppify.app.commands.lsp.server import LspServer, uri_to_path
+from desloppify.languages.go.detectors.smells import smell_rule_catalog
+
+_SOURCE = 'package main\n\nfunc f(b bool) string {\n\treturn fmt.Sprintf("%t", b)\n}\n'
+
+_SMELL_FINDING = {
+    "id": "smells::main.go::sprintf_strconv",
+    "detector": "smells",
+    "tier": 3,
+    "summary": "1x fmt.Sprintf for a single conversion (use strconv)",
+    "detail": {
+        "smell_id": "sprintf_strconv",
+        "severity": "low",
+        "matches": [
+            {
+                "line": 4,
+                "suggestion": "strconv.FormatBool(b)",
+                "fix": {
+                    "title": "Replace with strconv.FormatBool(b)",
+                    "line": 4,
+                    "old": 'fmt.Sprintf("%t", b)',
+                    "new": "strconv.FormatBool(b)",
+                },
+            }
+        ],
+    },
+}
+
+
+def _frame(payload: dict) -> bytes:
+    body = json.dumps(payload).encode("utf-8")
+    return f"Content-Length: {len(body)}\r\n\r\n".encode("ascii") + body
+
+
+def _read_all(raw: bytes) -> list[dict]:
+    stream = io.BytesIO(raw)
+    messages = []
+    while (message := read_message(stream)) is not None:
+        messages.append(message)
+    return messages
+
+
+def test_message_framing_round_trips():
+    out = io.BytesIO()
+    writer = MessageWriter(out)
+    writer.respond(1, {"ok": True})
+    writer.notify("window/logMessage", {"message": "héllo"})
+    messages = _read_all(out.getvalue())
+    assert messages == [
+        {"jsonrpc": "2.0", "id": 1, "result": {"ok": True}},
+        {"jsonrpc": "2.0", "method": "window/logMessage", "params": {"message": "héllo"}},
+    ]
+
+
+def test_findings_to_diagnostics_maps_smell_matches():
+    [diagnostic] = findings_to_diagnostics([_SMELL_FINDING], _SOURCE, lang="go")
+    assert diagnostic["code"] == "sprintf_strconv"
+    assert diagnostic["source"] == "desloppify"
+    assert diagnostic["severity"] == SEVERITY_HINT
+    assert diagnostic["codeDescription"]["href"].endswith(
+        "/docs/go-quality-pipeline.md#sprintf_strconv"
+    )
+    assert diagnostic["range"]["start"] == {"line": 3, "character": 1}
+    assert "strconv.FormatBool(b)" in diagnostic["message"]
+    assert diagnostic["data"]["suppressible"] is True
+
+
+def test_findings_to_diagnostics_falls_back_to_tier_and_detail_line():
+    finding = {"id": "x", "detector": "structural", "tier": 2, "summary": "big", "detail": {}}
+    [diagnostic] = findings_to_diagnostics([finding], _SOURCE, lang="go")
+    assert diagnostic["code"] == "structural"
+    assert diagnostic["codeDescription"]["href"].endswith(
+        "#3-what-only-desloppify-covers-go"
+    )
+    assert diagnostic["severity"] == SEVERITY_WARNING
+    assert diagnostic["range"]["start"]["line"] == 0
+    assert diagnostic["data"]["suppressible"] is False
+
+
+def test_code_actions_offer_fix_then_ignore():
+    diagnostics = findings_to_diagnostics([_SMELL_FINDING], _SOURCE, lang="go")
+    fix, ignore = code_actions("file:///p/main.go", diagnostics, _SOURCE, lang="go")
+
+    [edit] = fix["edit"]["changes"]["file:///p/main.go"]
+    assert edit["newText"] == "strconv.FormatBool(b)"
+    assert edit["range"]["start"] == {"line": 3, "character": 8}
+    assert edit["range"]["end"]["character"] == 8 + len('fmt.Sprintf("%t", b)')
+
+    [insert] = ignore["edit"]["changes"]["file:///p/main.go"]
+    assert insert["newText"] == "\t//desloppify:ignore sprintf_strconv\n"
+    assert insert["range"]["start"] == insert["range"]["end"] == {"line": 3, "character": 0}
+
+
+def test_every_builtin_go_rule_has_a_docs_anchor():
+    docs = (Path(__file__).parents[3] / "docs" / "go-quality-pipeline.md").read_text()
+    for rule in smell_rule_catalog():
+        if not rule.get("plugin"):
+            assert f'<a id="{rule["id"]}"></a>' in docs, rule["id"]
+            assert rule_docs_url("go", rule["id"]).endswith(f"#{rule['id']}")
+
+
+def test_positions_count_utf16_code_units_unless_utf32_was_agreed():
+    source = 'package main\n\nfunc f(b bool) string {\n\t_ = "😀é"; return fmt.Sprintf("%t", b)\n}\n'
+    prefix = len('\t_ = "😀é"; return ')
+    diagnostics = findings_to_diagnostics([_SMELL_FINDING], source, lang="go")
+    assert diagnostics[0]["range"]["end"]["character"] == len(source.splitlines()[3]) + 1
+    [fix, _ignore] = code_actions("file:///p/main.go", diagnostics, source, lang="go")
+    [edit] = fix["edit"]["changes"]["file:///p/main.go"]
+    assert edit["range"]["start"]["character"] == prefix + 1
+
+    diagnostics = findings_to_diagnostics(
+        [_SMELL_FINDING], source, lang="go", encoding=ENCODING_UTF32
+    )
+    assert diagnostics[0]["range"]["end"]["character"] == len(source.splitlines()[3])
+    [fix, _ignore] = code_actions(
+        "file:///p/main.go", diagnostics, source, lang="go", encoding=ENCODING_UTF32
+    )
+    [edit] = fix["edit"]["changes"]["file:///p/main.go"]
+    assert edit["range"]["start"]["character"] == prefix
+
+
+def test_code_actions_skip_ignore_without_directive_support():
+    diagnostics = findings_to_diagnostics([_SMELL_FINDING], _SOURCE, lang="python")
+    actions = code_actions("file:///p/main.py", diagnostics, _SOURCE, lang="python")
+    assert [a["title"] for a in actions] == ["Replace with strconv.FormatBool(b)"]
+
+
+class _ManualTimer:
+    def __init__(self, interval, function, args=()):
+        self.interval = interval
+        self.function = function
+        self.args = args
+        self.started = False
+        self.cancelled = False
+
+    def start(self):
+        self.started = True
+
+    def cancel(self):
+        self.cancelled = True
+
+    def fire(self):
+        self.function(*self.args)
+
+
+def test_lsp_server_session(tmp_path):
+    uri = (tmp_path / "main.go").as_uri()
+    analyzed: list[str] = []
+    timers: list[_ManualTimer] = []
+
+    def analyze(path, text):
+        analyzed.append(text)
+        assert path == tmp_path / "main.go"
+        return "go", [_SMELL_FINDING] if "Sprintf" in text else []
+
+    def timer_factory(*args, **kwargs):
+        timers.append(_ManualTimer(*args, **kwargs))
+        return timers[-1]
+
+    requests = [
+        {"id": 1, "method": "initialize", "params": {}},
+        {"method": "initialized", "params": {}},
+        {
+            "method": "textDocument/didOpen",
+            "params": {"textDocument": {"uri": uri, "languageId": "go", "text": _SOURCE}},
+        },
+        {
+            "id": 2,
+            "method": "textDocument/codeAction",
+            "params": {
+                "textDocument": {"uri": uri},
+                "context": {
+                    "diagnostics": findings_to_diagnostics(
+                        [_SMELL_FINDING], _SOURCE, lang="go"
+                    )
+                },
+            },
+        },
+        {
+            "method": "textDocument/didChange",
+            "params": {
+                "textDocument": {"uri": uri},
+                "contentChanges": [{"text": "package main\n"}],
+            },
+        },
+        {"id": 3, "method": "unknown/method", "params": {}},
+        {"id": 4, "method": "shutdown"},
+        {"method": "exit"},
+    ]
+    out = io.BytesIO()
+    server = LspServer(
+        io.BytesIO(b"".join(_frame(r) for r in requests)),
+        out,
+        analyze,
+        debounce=0.25,
+        timer_factory=timer_factory,
+    )
+    assert server.serve() == 0
+
+    # didChange is debounced: analysis only runs once the timer fires.
+    assert analyzed == [_SOURCE]
+    [timer] = timers
+    assert timer.interval == 0.25 and timer.started and timer.cancelled
+
+    messages = _read_all(out.getvalue())
+    by_id = {m["id"]: m for m in messages if "id" in m}
+    assert by_id[1]["result"]["capabilities"]["codeActionProvider"]
+    assert by_id[1]["result"]["capabilities"]["positionEncoding"] == "utf-16"
+    assert len(by_id[2]["result"]) == 2
+    assert by_id[3]["error"]["code"] == -32601
+    assert by_id[4]["result"] is None
+
+    [published] = [m for m in messages if m.get("method") == "textDocument/publishDiagnostics"]
+    assert published["params"]["uri"] == uri
+    assert [d["code"] for d in published["params"]["diagnostics"]] == ["sprintf_strconv"]
+
+
+def test_lsp_server_debounced_publish_and_close(tmp_path):
+    uri = (tmp_path / "main.go").as_uri()
+    timers: list[_ManualTimer] = []
+
+    def timer_factory(*args, **kwargs):
+        timers.append(_ManualTimer(*args, **kwargs))
+        return timers[-1]
+
+    out = io.BytesIO()
+    server = LspServer(
+        io.BytesIO(), out, lambda _p, _t: ("go", []), timer_factory=timer_factory
+    )
+    server.handle(
+        {
+            "method": "textDocument/didChange",
+            "params": {"textDocument": {"uri": uri}, "contentChanges": [{"text": "x"}]},
+        }
+    )
+    timers[-1].fire()
+    server.handle({"method": "textDocument/didClose", "params": {"textDocument": {"uri": uri}}})
+
+    published = [m["params"] for m in _read_all(out.getvalue())]
+    assert published == [{"uri": uri, "diagnostics": []}, {"uri": uri, "diagnostics": []}]
+    assert server.serve() == 1  # EOF without shutdown
+
+
+def test_uri_to_path_decodes_escapes(tmp_path):
+    path = tmp_path / "with space.go"
+    assert uri_to_path(path.as_uri()) == path
+
+
+def test_lsp_server_uses_utf32_when_the_client_offers_it():
+    server = LspServer(io.BytesIO(), io.BytesIO(), lambda _p, _t: ("go", []))
+    result = server._initialize(
+        {"capabilities": {"general": {"positionEncodings": ["utf-8", "utf-32", "utf-16"]}}}
+    )
+    assert result["capabilities"]["positionEncoding"] == ENCODING_UTF32
@@ -0,0 +1,176 @@
+"""Direct tests for the triage command (items, decisions log, prompt loop)."""
+
+from __future__ import annotations
+
+import json
+
+from desloppify.app.commands.triage.cmd import run_triage
+from desloppify.app.commands.triage.session import (
+    TRIAGE_FILE,
+    TriageLog,
+    locate,
+    triage_items,
+)
+from desloppify.core.runtime_state import RuntimeContext, runtime_scope
+
+_SOURCE = """package a
+
+func f(x int) bool {
+\tif 0 == x {
+\t\treturn true
+\t}
+\treturn false
+}
+"""
+
+
+def _finding(**overrides):
+    finding = {
+        "id": "smells::a.go::go_smell::yoda_condition",
+        "detector": "smells",
+        "file": "a.go",
+        "tier": 3,
+        "status": "open",
+        "summary": "1x Yoda condition",
+        "detail": {
+            "smell_id": "yoda_condition",
+            "count": 1,
+            "matches": [
+                {
+                    "file": "a.go",
+                    "line": 4,
+                    "content": "if 0 == x {",
+                    "fix": {"title": "Swap operands", "line": 4, "old": "0 == x", "new": "x == 0"},
+                }
+            ],
+        },
+    }
+    finding.update(overrides)
+    return finding
+
+
+def _state(*findings):
+    return {"findings": {f["id"]: f for f in findings}}
+
+
+def _answers(*values):
+    replies = iter(values)
+
+    def ask(_prompt: str) -> str:
+        try:
+            return next(replies)
+        except StopIteration:
+            raise EOFError from None
+
+    return ask
+
+
+def _run(tmp_path, state, *answers, log=None):
+    log = log or TriageLog(tmp_path / TRIAGE_FILE)
+    saves = []
+    with runtime_scope(RuntimeContext(project_root=tmp_path)):
+        run_triage(
+            triage_items(state["findings"]),
+            log,
+            state=state,
+            save_state=lambda: saves.append(True),
+            lang_name="go",
+            ask=_answers(*answers),
+        )
+    return log, saves
+
+
+def test_triage_items_skip_closed_and_order_by_tier():
+    state = _state(
+        _finding(),
+        _finding(id="b", tier=1, detail={}, file="b.go", summary="worse"),
+        _finding(id="c", status="fixed"),
+    )
+    items = triage_items(state["findings"])
+    assert [item.finding_id for item in items] == ["b", "smells::a.go::go_smell::yoda_condition"]
+    assert items[1].fix is not None and items[0].fix is None
+
+
+def test_locate_follows_moved_lines():
+    item = triage_items(_state(_finding())["findings"])[0]
+    lines = ["// new header", *_SOURCE.splitlines()]
+    assert locate(lines, item) == 5
+    assert locate(["package a"], item) is None
+
+
+def test_fix_applies_edit_and_records_decision(tmp_path):
+    (tmp_path / "a.go").write_text(_SOURCE)
+    log, _ = _run(tmp_path, _state(_finding()), "f")
+    assert "if x == 0 {" in (tmp_path / "a.go").read_text()
+    saved = json.loads((tmp_path / TRIAGE_FILE).read_text())
+    assert [d["action"] for d in saved["decisions"].values()] == ["fix"]
+
+
+def test_suppress_inserts_directive_with_reason(tmp_path):
+    (tmp_path / "a.go").write_text(_SOURCE)
+    log, _ = _run(tmp_path, _state(_finding()), "s", "", "s", "generated code")
+    lines = (tmp_path / "a.go").read_text().splitlines()
+    assert lines[3] == "\t//desloppify:ignore yoda_condition // generated code"
+    assert lines[4] == "\tif 0 == x {"
+    (decision,) = log.decisions.values()
+    assert decision == {**decision, "action": "suppress", "reason": "generated code"}
+
+
+def test_baseline_marks_finding_wontfix(tmp_path):
+    (tmp_path / "a.go").write_text(_SOURCE)
+    state = _state(_finding())
+    _, saves = _run(tmp_path, state, "b")
+    finding = state["findings"]["smells::a.go::go_smell::yoda_condition"]
+    assert finding["status"] == "wontfix"
+    assert saves == [True]
+    assert (tmp_path / "a.go").read_text() == _SOURCE
+
+
+def test_interrupted_session_resumes_after_decided_items(tmp_path):
+    (tmp_path / "a.go").write_text(_SOURCE)
+    (tmp_path / "b.go").write_text("package a\n")
+    state = _state(_finding(), _finding(id="b", detail={}, file="b.go"))
+    log, _ = _run(tmp_path, state, "n")  # then EOF: quit
+    assert len(log.decisions) == 1
+
+    resumed = TriageLog(tmp_path / TRIAGE_FILE)
+    prompts = []
+
+    def ask(prompt: str) -> str:
+        prompts.append(prompt)
+        return "n"
+
+    with runtime_scope(RuntimeContext(project_root=tmp_path)):
+        run_triage(
+            triage_items(state["findings"]),
+            resumed,
+            state=state,
+            save_state=lambda: None,
+            lang_name="go",
+            ask=ask,
+        )
+    assert len(prompts) == 1 and "[f]ix" in prompts[0]
+    assert len(resumed.decisions) == 2
+
+
+def test_unavailable_actions_are_not_offered(tmp_path):
+    (tmp_path / "a.go").write_text(_SOURCE)
+    state = _state(_finding(detector="structural", detail={}))
+    prompts = []
+
+    def ask(prompt: str) -> str:
+        prompts.append(prompt)
+        return "s" if len(prompts) == 1 else "n"
+
+    with runtime_scope(RuntimeContext(project_root=tmp_path)):
+        run_triage(
+            triage_items(state["findings"]),
+            TriageLog(tmp_path / TRIAGE_FILE),
+            state=state,
+            save_state=lambda: None,
+            lang_name="go",
+            ask=ask,
+        )
+    assert "[s]uppress" not in prompts[0]
+    assert len(prompts) == 2
+    assert (tmp_path / "a.go").read_text() == _SOURCE
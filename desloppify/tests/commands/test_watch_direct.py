@@ -0,0 +1,180 @@
+"""Direct tests for the watch command (polling, incremental refresh, output)."""
+
+from __future__ import annotations
+
+import io
+import json
+from pathlib import Path
+
+from desloppify.app.commands.watch.render import emit_jsonl, render_tty
+from desloppify.app.commands.watch.session import (
+    FindingsDelta,
+    WatchSession,
+    exported_surface,
+    finding_items,
+)
+from desloppify.app.commands.watch.snapshot import (
+    ChangeWatcher,
+    diff_snapshots,
+    take_snapshot,
+)
+from desloppify.core.runtime_state import RuntimeContext, runtime_scope
+from desloppify.languages import get_lang
+from desloppify.languages._framework.runtime import make_lang_run
+
+
+def test_diff_snapshots_reports_added_removed_and_modified():
+    previous = {"a.go": (1, 10), "b.go": (1, 10), "c.go": (1, 10)}
+    current = {"a.go": (1, 10), "b.go": (2, 12), "d.go": (1, 1)}
+    assert diff_snapshots(previous, current) == {"b.go", "c.go", "d.go"}
+
+
+def test_take_snapshot_skips_missing_files(tmp_path):
+    (tmp_path / "a.go").write_text("package a\n")
+    snapshot = take_snapshot(["a.go", "gone.go"], tmp_path)
+    assert list(snapshot) == ["a.go"]
+
+
+def test_change_watcher_debounces_bursts():
+    polls = iter(
+        [
+            {"a.go": (1, 1)},  # baseline
+            {"a.go": (1, 1)},  # quiet
+            {"a.go": (2, 1)},  # first edit
+            {"a.go": (2, 1), "b.go": (1, 1)},  # second edit inside debounce
+            {"a.go": (2, 1), "b.go": (1, 1)},  # quiet but debounce not elapsed
+            {"a.go": (2, 1), "b.go": (1, 1)},  # quiet, debounce elapsed
+        ]
+    )
+    now = [0.0]
+
+    def sleep(seconds: float) -> None:
+        now[0] += seconds
+
+    watcher = ChangeWatcher(
+        lambda: next(polls), interval=0.1, debounce=0.15, sleep=sleep, clock=lambda: now[0]
+    )
+    assert watcher.wait() == {"a.go", "b.go"}
+
+
+def test_finding_items_explodes_aggregated_matches():
+    items = finding_items(
+        [
+            {
+                "id": "smells::a/x.go::go_smell::todo_fixme",
+                "detector": "smells",
+                "file": "a/x.go",
+                "detail": {
+                    "smell_id": "todo_fixme",
+                    "matches": [
+                        {"file": "a/x.go", "line": 3, "content": "// TODO"},
+                        {"file": "b/y.go", "line": 9, "content": "// TODO"},
+                    ],
+                },
+            },
+            {"id": "structural::c/z.go", "detector": "structural", "file": "c/z.go"},
+        ]
+    )
+    assert sorted(item["file"] for item in items.values()) == ["a/x.go", "b/y.go", "c/z.go"]
+    assert "todo_fixme::b/y.go::// TODO#1" in items
+
+
+def test_exported_surface_ignores_bodies_and_unexported():
+    before = exported_surface("package a\n\nfunc Run(x int) error {\n\treturn nil\n}\nfunc helper() {}\n")
+    body_edit = exported_surface("package a\n\nfunc Run(x int) error {\n\tx++\n\treturn nil\n}\n")
+    signature_edit = exported_surface("package a\n\nfunc Run(x, y int) error {\n\treturn nil\n}\n")
+    assert before == body_edit
+    assert before != signature_edit
+
+
+def _go_project(root: Path) -> None:
+    for rel_path, body in {
+        "go.mod": "module x\n\ngo 1.21\n",
+        "a/a.go": "package a\n\n// TODO: first\nfunc A() {}\n",
+        "b/b.go": "package b\n\n// TODO: second\nfunc B() {}\n",
+    }.items():
+        target = root / rel_path
+        target.parent.mkdir(parents=True, exist_ok=True)
+        target.write_text(body)
+
+
+def test_watch_session_refresh_only_touches_changed_package(tmp_path, monkeypatch):
+    _go_project(tmp_path)
+    monkeypatch.chdir(tmp_path)
+    lang_cfg = get_lang("go")
+
+    with runtime_scope(RuntimeContext(project_root=tmp_path)):
+        session = WatchSession(tmp_path, lambda: make_lang_run(lang_cfg), root=tmp_path)
+        first = session.initial(lang_cfg.file_finder(tmp_path))
+        assert {item["file"] for item in first.added} == {"a/a.go", "b/b.go"}
+
+        (tmp_path / "a" / "a.go").write_text("package a\n\nfunc A() {}\n")
+        delta = session.refresh(["a/a.go"])
+
+    assert delta.dirs == ["a"]
+    assert delta.added == []
+    assert [item["summary"] for item in delta.removed] == ["// TODO: first"]
+    assert delta.unchanged == 0
+    assert {item["file"] for item in session.items.values()} == {"b/b.go"}
+
+
+def test_watch_session_touch_without_edit_reports_no_changes(tmp_path, monkeypatch):
+    _go_project(tmp_path)
+    todos = "".join(f"// TODO: item {n}\n" for n in range(60))
+    (tmp_path / "a" / "a.go").write_text(f"package a\n\n{todos}func A() {{}}\n")
+    monkeypatch.chdir(tmp_path)
+    lang_cfg = get_lang("go")
+
+    with runtime_scope(RuntimeContext(project_root=tmp_path)):
+        session = WatchSession(tmp_path, lambda: make_lang_run(lang_cfg), root=tmp_path)
+        session.initial(lang_cfg.file_finder(tmp_path))
+        before = dict(session.items)
+        (tmp_path / "b" / "b.go").touch()
+        delta = session.refresh(["b/b.go"])
+
+    assert (delta.added, delta.removed, delta.unchanged) == ([], [], 1)
+    assert session.items == before
+
+
+def test_watch_session_adds_dependents_on_signature_change(tmp_path):
+    (tmp_path / "a").mkdir()
+    (tmp_path / "a" / "a.go").write_text("package a\n\nfunc A() {}\n")
+
+    class _Lang:
+        build_dep_graph = staticmethod(
+            lambda _path: {"a/a.go": {"importers": {"c/c.go"}}}
+        )
+
+    with runtime_scope(RuntimeContext(project_root=tmp_path)):
+        session = WatchSession(tmp_path, lambda: _Lang(), root=tmp_path)
+        session._surfaces = {"a/a.go": exported_surface("package a\n\nfunc A() {}\n")}
+        assert session.affected_dirs(["a/a.go"]) == ["a"]
+        (tmp_path / "a" / "a.go").write_text("package a\n\nfunc A(n int) {}\n")
+        assert session.affected_dirs(["a/a.go"]) == ["a", "c"]
+
+
+def test_emit_jsonl_writes_findings_event():
+    out = io.StringIO()
+    item = {"detector": "smells", "kind": "todo_fixme", "file": "a.go", "line": 1, "summary": "x"}
+    emit_jsonl(FindingsDelta(added=[item], unchanged=2, dirs=["."]), out)
+    event = json.loads(out.getvalue())
+    assert event["event"] == "findings"
+    assert event["added"] == [item]
+    assert event["removed"] == []
+    assert event["unchanged"] == 2
+
+
+def test_render_tty_clears_and_summarizes():
+    out = io.StringIO()
+    item = {"detector": "smells", "kind": "todo_fixme", "file": "a.go", "line": 4, "summary": "// TODO"}
+    render_tty(
+        FindingsDelta(added=[item], removed=[item], unchanged=3, dirs=["pkg"]),
+        out,
+        target=".",
+        total=4,
+    )
+    text = out.getvalue()
+    assert text.startswith("\033[2J\033[H")
+    assert "+ a.go:4  [todo_fixme] // TODO" in text
+    assert "resolved: a.go:4" in text
+    assert "3 unchanged" in text
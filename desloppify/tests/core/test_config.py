@@ -6,10 +6,13 @@ import pytest
 
 from desloppify.core.config import (
     CONFIG_SCHEMA,
+    ConfigError,
     _migrate_from_state_files,
     add_ignore_pattern,
     config_for_query,
+    config_line,
     default_config,
+    did_you_mean,
     load_config,
     save_config,
     set_config_value,
@@ -82,6 +85,16 @@ class TestLoadSaveConfig:
         cfg = load_config(p)
         assert cfg == default_config()
 
+    def test_strict_load_raises_on_corrupted_or_non_object_file(self, tmp_path):
+        p = tmp_path / "config.json"
+        p.write_text("not valid json{{{")
+        with pytest.raises(ConfigError, match="cannot be read as JSON"):
+            load_config(p, strict=True)
+        p.write_text("[]")
+        with pytest.raises(ConfigError, match="expected a JSON object"):
+            load_config(p, strict=True)
+        assert load_config(p) == default_config()
+
     def test_legacy_csharp_keys_are_not_auto_migrated(self, tmp_path):
         p = tmp_path / "config.json"
         p.parent.mkdir(parents=True, exist_ok=True)
@@ -161,6 +174,13 @@ class TestSetConfigValue:
         set_config_value(cfg, "badge_path", "badges/health.png")
         assert cfg["badge_path"] == "badges/health.png"
 
+    def test_set_staged_fail_severity_validates_level(self):
+        cfg = default_config()
+        set_config_value(cfg, "staged_fail_severity", "high")
+        assert cfg["staged_fail_severity"] == "high"
+        with pytest.raises(ValueError):
+            set_config_value(cfg, "staged_fail_severity", "critical")
+
     def test_set_badge_path_filename_only_is_valid(self):
         cfg = default_config()
         set_config_value(cfg, "badge_path", "scorecard.png")
@@ -378,3 +398,45 @@ class TestMigrateFromStateFiles:
         result = _migrate_from_state_files(config_path)
         assert "csharp_corroboration_min_signals" not in result
         assert "csharp_high_fanout_threshold" not in result
+
+
+# ===========================================================================
+# config_line
+# ===========================================================================
+
+
+class TestConfigLine:
+    def test_points_at_nested_values(self, tmp_path):
+        p = tmp_path / "config.json"
+        p.write_text(
+            '{\n  "exclude": ["a", "b"],\n  "languages": {\n'
+            '    "go": {"rules": [\n      {"id": "x"},\n      {"id": "y",\n'
+            '       "kind": "k"}\n    ]}\n  }\n}\n'
+        )
+        assert config_line(("exclude",), p) == 2
+        assert config_line(("languages", "go", "rules", 1), p) == 6
+        assert config_line(("languages", "go", "rules", 1, "kind"), p) == 7
+
+    def test_missing_values_and_files(self, tmp_path):
+        p = tmp_path / "config.json"
+        p.write_text('{"exclude": []}')
+        assert config_line(("exclude", 0), p) is None
+        assert config_line(("languages",), p) is None
+        assert config_line(("exclude",), tmp_path / "absent.json") is None
+
+
+# ===========================================================================
+# did_you_mean
+# ===========================================================================
+
+
+class TestDidYouMean:
+    def test_suggests_the_closest_known_name(self):
+        known = ["opt_in_smells", "custom_rules", "rule_options"]
+        assert did_you_mean("opt_in_smell", known) == "; did you mean 'opt_in_smells'?"
+        assert did_you_mean("rule_option", known) == "; did you mean 'rule_options'?"
+
+    def test_no_suggestion_when_nothing_is_close(self):
+        assert did_you_mean("severity", ["opt_in_smells", "custom_rules"]) == ""
+        assert did_you_mean("q", ["v", "s"]) == ""
+        assert did_you_mean("max", []) == ""
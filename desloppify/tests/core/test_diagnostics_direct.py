@@ -0,0 +1,49 @@
+"""Direct tests for run diagnostics (timings, profiles, traces)."""
+
+from __future__ import annotations
+
+import json
+import pstats
+import tracemalloc
+
+from desloppify.core.diagnostics import (
+    RunTimings,
+    current_diagnostics,
+    diagnostics_scope,
+)
+
+
+def test_timings_rank_slowest_first_and_cap_rules_and_packages():
+    timings = RunTimings()
+    timings.add_rules({f"rule{i:02d}": float(i) for i in range(30)})
+    timings.add_rules({"rule00": 100.0})
+    timings.add_package("pkg/a", 0.5)
+    timings.add_phase("Go smells", 2.0)
+    report = timings.as_dict(top=20)
+    assert len(report["rules"]) == 20
+    assert report["rules"][0] == {"name": "rule00", "seconds": 100.0}
+    assert report["rules"][1]["name"] == "rule29"
+    assert report["packages"] == [{"name": "pkg/a", "seconds": 0.5}]
+    assert "Rules (top 20):" in timings.render()
+
+
+def test_scope_writes_requested_profiles_and_trace(tmp_path):
+    cpu, mem, trace = tmp_path / "cpu.prof", tmp_path / "mem.snap", tmp_path / "trace.json"
+    with diagnostics_scope(
+        cpuprofile=str(cpu), memprofile=str(mem), trace=str(trace), timings=True
+    ) as diagnostics:
+        assert current_diagnostics() is diagnostics
+        with diagnostics.span("Go smells", "phase"):
+            sum(range(1000))
+    assert current_diagnostics() is None
+    assert [row["name"] for row in diagnostics.timings.as_dict()["phases"]] == ["Go smells"]
+    pstats.Stats(str(cpu))
+    tracemalloc.Snapshot.load(str(mem))
+    [event] = json.loads(trace.read_text())["traceEvents"]
+    assert (event["name"], event["cat"], event["ph"]) == ("Go smells", "phase", "X")
+
+
+def test_scope_without_flags_yields_none():
+    with diagnostics_scope() as diagnostics:
+        assert diagnostics is None
+        assert current_diagnostics() is None
@@ -0,0 +1,111 @@
+"""Direct tests for the in-process embedding API."""
+
+from __future__ import annotations
+
+import io
+import json
+import os
+import threading
+
+import pytest
+
+from desloppify import api
+from desloppify.languages.go.detectors import smells
+
+_SPRINTF = 'package main\n\nimport "fmt"\n\nfunc f(n int) string {\n\treturn fmt.Sprintf("%d", n)\n}\n'
+
+
+def _options(project, **kwargs) -> api.Options:
+    # Slow phases shell out to external tools (jscpd via npx); keep tests local.
+    return api.Options(path=project, syntax_only=True, include_slow=False, **kwargs)
+
+
+def _project(tmp_path):
+    (tmp_path / "go.mod").write_text("module example.com/x\n\ngo 1.22\n")
+    (tmp_path / "main.go").write_text(_SPRINTF)
+    return tmp_path
+
+
+def test_run_returns_filtered_findings_and_streams_phases(tmp_path):
+    project = _project(tmp_path)
+    cwd = os.getcwd()
+    phases: list[str] = []
+
+    report = api.run(
+        _options(project, rules=frozenset({"sprintf_strconv"})),
+        on_phase=lambda result: phases.append(result.phase.label),
+    )
+
+    assert report.lang == "go"
+    assert [f["id"] for f in report.findings] == [
+        "smells::main.go::go_smell::sprintf_strconv"
+    ]
+    assert report.findings[0]["lang"] == "go"
+    assert len(phases) > 1
+    assert os.getcwd() == cwd
+
+
+def test_iter_phases_stops_when_cancelled(tmp_path):
+    project = _project(tmp_path)
+    cancel = threading.Event()
+    seen = []
+    with pytest.raises(api.ScanCancelled):
+        for result in api.iter_phases(_options(project), cancel=cancel):
+            seen.append(result.phase.label)
+            cancel.set()
+    assert len(seen) == 1
+
+
+def test_cancel_stops_the_smell_phase_between_packages(tmp_path, monkeypatch):
+    project = _project(tmp_path)
+    for name in ("a", "b", "c"):
+        (project / name).mkdir()
+        source = _SPRINTF.replace("package main", f"package {name}")
+        (project / name / f"{name}.go").write_text(source)
+    monkeypatch.setenv("GOMAXPROCS", "1")
+    cancel = threading.Event()
+    scanned = []
+    scan_package = smells._scan_package
+
+    def scan_then_cancel(files, **kwargs):
+        scanned.append(files)
+        cancel.set()
+        return scan_package(files, **kwargs)
+
+    monkeypatch.setattr(smells, "_scan_package", scan_then_cancel)
+    seen = []
+    with pytest.raises(api.ScanCancelled):
+        for result in api.iter_phases(_options(project), cancel=cancel):
+            seen.append(result.phase.label)
+    assert len(scanned) == 1
+    assert "Go smells" not in seen
+
+
+def test_run_reads_language_settings_from_config(tmp_path):
+    project = _project(tmp_path)
+    (project / "layout.go").write_text(
+        "package main\n\ntype padded struct {\n\ta bool\n\tb int64\n\tc bool\n\td int64\n\te bool\n}\n"
+    )
+    rules = frozenset({"struct_field_alignment"})
+    default = api.run(_options(project, rules=rules))
+    opted_in = api.run(
+        _options(
+            project,
+            rules=rules,
+            config={"languages": {"go": {"opt_in_smells": ["struct_field_alignment"]}}},
+        )
+    )
+    assert default.findings == []
+    assert [f["file"] for f in opted_in.findings] == ["layout.go"]
+
+
+def test_write_json_round_trips_report():
+    report = api.Report(lang="go", path="/p", findings=[{"id": "x"}], potentials={"smells": 1})
+    out = io.StringIO()
+    api.write_json(report, out)
+    assert json.loads(out.getvalue()) == report.to_dict()
+
+
+def test_unknown_language_is_reported(tmp_path):
+    with pytest.raises(ValueError, match="could not detect"):
+        api.run(api.Options(path=tmp_path))
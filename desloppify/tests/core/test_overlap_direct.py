@@ -0,0 +1,104 @@
+"""Direct tests for merging overlapping findings from related rules."""
+
+from __future__ import annotations
+
+from desloppify.core.runtime_state import RuntimeContext, runtime_scope
+from desloppify.engine.planning.overlap import (
+    finding_rule,
+    merge_overlapping,
+    resolve_subsumes,
+)
+from desloppify.languages import get_lang
+from desloppify.languages._framework.runtime import make_lang_run
+from desloppify.languages.go.phases import _phase_smells
+
+
+def _smell(file: str, smell_id: str, lines: list[int], *, name: str = "") -> dict:
+    return {
+        "id": f"smells::{file}::{name or smell_id}",
+        "file": file,
+        "detector": "smells",
+        "summary": f"{len(lines)}x {smell_id}",
+        "detail": {"smell_id": smell_id, "count": len(lines), "lines": lines},
+    }
+
+
+def test_covered_findings_fold_into_the_most_specific_rule():
+    constant = _smell("a.go", "constant_condition", [5])
+    empty = _smell("a.go", "empty_branch", [5])
+    duplicate = _smell("a.go", "duplicate_branch", [5])
+    merged, stats = merge_overlapping([constant, duplicate, empty])
+
+    assert merged == [constant]
+    assert sorted(r["rule"] for r in constant["detail"]["related"]) == [
+        "smells::duplicate_branch",
+        "smells::empty_branch",
+    ]
+    assert stats.as_dict() == {"before": 3, "after": 1, "duplicates": 0, "subsumed": 2}
+
+
+def test_partial_or_unknown_coverage_keeps_findings_top_level():
+    constant = _smell("a.go", "constant_condition", [5])
+    empty = _smell("a.go", "empty_branch", [5, 9])
+    # Only the first lines of a long match list are kept; the rest are unknown.
+    truncated = _smell("b.go", "empty_branch", [3])
+    truncated["detail"]["count"] = 12
+    other_file = _smell("b.go", "constant_condition", [3])
+    merged, stats = merge_overlapping([constant, empty, other_file, truncated])
+
+    assert merged == [constant, empty, other_file, truncated]
+    assert stats.subsumed == 0
+
+
+def test_go_smell_phase_output_folds_through_its_matches(tmp_path, monkeypatch):
+    (tmp_path / "go.mod").write_text("module example.com/mod\n\ngo 1.21\n")
+    (tmp_path / "a.go").write_text(
+        "package p\n\nfunc Check(n int) int {\n\tif true {\n\t}\n\treturn n\n}\n"
+    )
+    (tmp_path / "b.go").write_text("package p\n\nfunc Other() {\n\tif true {\n\t}\n}\n")
+    monkeypatch.chdir(tmp_path)
+    with runtime_scope(RuntimeContext(project_root=tmp_path)):
+        findings, _ = _phase_smells(tmp_path, make_lang_run(get_lang("go")))
+    merged, stats = merge_overlapping(sorted(findings, key=lambda f: f["file"]))
+
+    # One aggregated finding per rule, each with its matches in a.go and b.go.
+    assert sorted(finding_rule(f) for f in merged) == [
+        "smells::constant_condition",
+        "smells::empty_branch",
+    ]
+    assert stats.subsumed == 0
+
+    (tmp_path / "b.go").unlink()
+    with runtime_scope(RuntimeContext(project_root=tmp_path)):
+        findings, _ = _phase_smells(tmp_path, make_lang_run(get_lang("go")))
+    merged, stats = merge_overlapping(sorted(findings, key=lambda f: f["file"]))
+
+    [constant] = merged
+    assert finding_rule(constant) == "smells::constant_condition"
+    assert [r["rule"] for r in constant["detail"]["related"]] == ["smells::empty_branch"]
+    assert stats.as_dict() == {"before": 2, "after": 1, "duplicates": 0, "subsumed": 1}
+
+
+def test_same_rule_at_the_same_position_collapses_unconditionally():
+    aggregated = _smell("a.go", "unreachable_code", [7, 12])
+    per_line = _smell("a.go", "unreachable_code", [12], name="unreachable_code::12")
+    per_line["detail"] = {"line": 12}
+    repeated = dict(aggregated)
+    merged, stats = merge_overlapping([aggregated, per_line, repeated], subsumes={})
+
+    assert finding_rule(per_line) == "smells::unreachable_code"
+    assert merged == [aggregated]
+    assert stats.duplicates == 2
+
+
+def test_config_overrides_replace_or_drop_default_entries():
+    assert resolve_subsumes({"smells::constant_condition": []}) == {
+        "smells::empty_branch": frozenset({"smells::duplicate_branch"}),
+        "smells::always_nil_error": frozenset({"smells::useless_error_return"}),
+        "smells::mutex_unlock_missing": frozenset({"smells::resource_not_released"}),
+    }
+    relation = resolve_subsumes({"vet": ["smells::sprintf_strconv"]})
+    assert relation["vet"] == frozenset({"smells::sprintf_strconv"})
+    assert relation["smells::constant_condition"] == frozenset(
+        {"smells::empty_branch", "smells::duplicate_branch"}
+    )
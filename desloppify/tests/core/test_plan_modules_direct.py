@@ -9,6 +9,7 @@ import desloppify.engine._state.filtering as filtering_mod
 import desloppify.engine.planning.common as plan_common_mod
 import desloppify.engine.planning.scan as plan_scan_mod
 import desloppify.engine.planning.select as plan_select_mod
+from desloppify.languages._framework.base.types import DetectorPhase
 
 
 class _Phase:
@@ -61,6 +62,33 @@ def test_select_phases_and_run_phases_behavior():
     assert potentials == {"fast": 1, "slow": 2, "review": 3}
 
 
+def test_select_phases_syntax_only_drops_package_phases():
+    file_phase = _Phase("File", False, [], {})
+    file_phase.needs_package = False
+    tool_phase = _Phase("Tool", False, [], {})
+    tool_phase.needs_package = True
+    lang = SimpleNamespace(phases=[file_phase, tool_phase], zone_map=None, name="go")
+
+    selected = plan_scan_mod._select_phases(
+        lang, include_slow=True, profile="full", syntax_only=True
+    )
+    assert [phase.label for phase in selected] == ["File"]
+
+
+def test_select_phases_syntax_only_drops_type_and_module_level_phases():
+    phases = [
+        DetectorPhase("Parse", lambda *_: ([], {})),
+        DetectorPhase("Vet", lambda *_: ([], {}), requires="types"),
+        DetectorPhase("Graph", lambda *_: ([], {}), requires="module"),
+    ]
+    lang = SimpleNamespace(phases=phases, zone_map=None, name="go")
+
+    selected = plan_scan_mod._select_phases(
+        lang, include_slow=True, profile="full", syntax_only=True
+    )
+    assert [phase.label for phase in selected] == ["Parse"]
+
+
 def test_resolve_lang_prefers_explicit_and_fallbacks(monkeypatch):
     explicit = object()
     assert plan_scan_mod._resolve_lang(explicit, Path(".")) is explicit
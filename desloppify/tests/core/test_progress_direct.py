@@ -0,0 +1,39 @@
+"""Direct tests for the live scan progress line."""
+
+from __future__ import annotations
+
+import io
+
+from desloppify.core.progress import ScanProgress, current_progress, progress_scope
+
+
+def test_progress_redraws_in_place_at_most_once_per_interval():
+    now = [0.0]
+    stream = io.StringIO()
+    progress = ScanProgress(stream, interval=0.1, clock=lambda: now[0])
+    progress.start(10)
+    progress.advance(4, "pkg/a")
+    progress.advance(3, "pkg/b")
+    now[0] = 0.5
+    progress.advance(3, "")
+    progress.clear()
+    assert stream.getvalue() == "\r\x1b[K  4/10 files  pkg/a\r\x1b[K  10/10 files  .\r\x1b[K"
+    assert (progress.done, progress.total) == (0, 0)
+
+
+def test_scope_is_empty_when_disabled():
+    stream = io.StringIO()
+    with progress_scope(enabled=False, stream=stream) as progress:
+        assert progress is None
+        assert current_progress() is None
+    assert stream.getvalue() == ""
+
+
+def test_scope_clears_the_line_on_exit():
+    stream = io.StringIO()
+    with progress_scope(enabled=True, stream=stream) as progress:
+        assert current_progress() is progress
+        progress.start(2)
+        progress.advance(2, "cmd")
+    assert current_progress() is None
+    assert stream.getvalue().endswith("\r\x1b[K")
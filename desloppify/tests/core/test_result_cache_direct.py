@@ -0,0 +1,41 @@
+"""Direct tests for the on-disk per-package result cache."""
+
+from __future__ import annotations
+
+from desloppify.core.result_cache import CACHE_DIR_ENV, ResultCache, user_cache_dir
+
+
+def test_user_cache_dir_honours_override_and_xdg(monkeypatch, tmp_path):
+    monkeypatch.setenv(CACHE_DIR_ENV, str(tmp_path / "override"))
+    assert user_cache_dir() == tmp_path / "override"
+
+    monkeypatch.delenv(CACHE_DIR_ENV)
+    monkeypatch.setattr("sys.platform", "linux")
+    monkeypatch.setenv("XDG_CACHE_HOME", str(tmp_path / "xdg"))
+    assert user_cache_dir() == tmp_path / "xdg" / "desloppify"
+
+
+def test_round_trip_stats_and_clean(tmp_path):
+    cache = ResultCache(tmp_path / "cache")
+    key = cache.key("go-smells", ["file:a.go:123"])
+    assert key == cache.key("go-smells", ["file:a.go:123"])
+    assert key != cache.key("go-smells", ["file:a.go:124"])
+    assert key != cache.key("other", ["file:a.go:123"])
+
+    assert cache.get("go-smells", key) is None
+    cache.put("go-smells", key, {"empty_branch": [{"file": "a.go", "line": 3}]})
+    assert cache.get("go-smells", key) == {"empty_branch": [{"file": "a.go", "line": 3}]}
+    assert (cache.stats.hits, cache.stats.misses) == (1, 1)
+
+    assert cache.clean() == 1
+    assert cache.get("go-smells", key) is None
+    assert cache.clean() == 0
+
+
+def test_corrupt_entry_is_a_miss(tmp_path):
+    cache = ResultCache(tmp_path)
+    key = cache.key("ns", ["x"])
+    cache.put("ns", key, [1])
+    (tmp_path / "ns" / key[:2] / f"{key}.json").write_text("{not json")
+    assert cache.get("ns", key) is None
+    assert cache.stats.misses == 1
@@ -0,0 +1,48 @@
+"""Direct tests for the bounded-memory finding spill store."""
+
+from __future__ import annotations
+
+from desloppify.engine.planning.spill import (
+    FindingCounters,
+    FindingSpill,
+    finding_sort_key,
+    spill_threshold_bytes,
+)
+
+
+def _finding(file: str, detector: str, n: int, tier: int = 3) -> dict:
+    return {"id": f"{detector}::{file}::{n}", "file": file, "detector": detector, "tier": tier}
+
+
+def test_spill_merges_sorted_runs_with_the_buffer():
+    findings = [
+        _finding(f"pkg{i % 7}/f.go", "smells" if i % 2 else "structural", i)
+        for i in range(40)
+    ]
+    with FindingSpill(threshold_bytes=300) as spill:
+        spill.extend(findings)
+        assert spill.spilled_runs > 1
+        assert spill.count == 40
+        assert list(spill.sorted()) == sorted(findings, key=finding_sort_key)
+
+
+def test_spill_under_budget_keeps_objects_in_memory():
+    finding = _finding("a.go", "smells", 1) | {"detail": {"span": (1, 2)}}
+    with FindingSpill(spill_threshold_bytes(None)) as spill:
+        spill.add(finding)
+        assert spill.spilled_runs == 0
+        assert next(spill.sorted()) is finding
+
+
+def test_threshold_follows_max_memory_hint_and_counters_stream():
+    assert spill_threshold_bytes(400) == 100 * 1024 * 1024
+    assert spill_threshold_bytes(0) == spill_threshold_bytes(None)
+
+    counters = FindingCounters()
+    for finding in (_finding("a.go", "smells", 1), _finding("b.go", "smells", 2, tier=2)):
+        counters.add(finding)
+    assert counters.as_dict() == {
+        "total": 2,
+        "by_detector": {"smells": 2},
+        "by_tier": {"2": 1, "3": 1},
+    }
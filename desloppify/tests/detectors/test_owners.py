@@ -0,0 +1,136 @@
+"""Tests for CODEOWNERS parsing and ownership reports (``desloppify.engine.policy.owners``)."""
+
+from __future__ import annotations
+
+from types import SimpleNamespace
+
+import pytest
+
+from desloppify import state as state_mod
+from desloppify.app.commands.plan_cmd import cmd_plan_output
+from desloppify.core.runtime_state import RuntimeContext, runtime_scope
+from desloppify.engine._state.scoring import _recompute_stats
+from desloppify.engine.planning.render import generate_plan_md
+from desloppify.engine.planning.scan import _stamp_finding_context
+from desloppify.engine.policy.owners import (
+    UNOWNED,
+    find_codeowners,
+    owner_counts,
+    parse_codeowners,
+)
+
+CODEOWNERS = """\
+# Default owners, overridden by anything below.
+*                   @org/everyone
+*.go                @org/gophers
+/internal/          @org/platform-team   # inline comment
+docs/*              docs@example.com
+apps/               @org/apps
+**/generated        @org/codegen
+/internal/vendor/
+\\#notes.txt        @org/notes
+!internal/keep.go   @org/nobody
+internal/[ab].go    @org/nobody
+"""
+
+
+@pytest.fixture()
+def owners():
+    return parse_codeowners(CODEOWNERS)
+
+
+@pytest.mark.parametrize(
+    ("path", "expected"),
+    [
+        ("README.md", ("@org/everyone",)),
+        # The last matching line wins, wherever it sits in the tree.
+        ("cmd/main.go", ("@org/gophers",)),
+        ("internal/api/server.go", ("@org/platform-team",)),
+        # A leading slash anchors to the root: this internal/ is not that one.
+        ("pkg/internal/x.txt", ("@org/everyone",)),
+        # A trailing /* covers files directly in the directory only.
+        ("docs/intro.md", ("docs@example.com",)),
+        ("docs/guides/setup.md", ("@org/everyone",)),
+        # A trailing slash without a leading one matches the directory anywhere.
+        ("services/apps/web/index.go", ("@org/apps",)),
+        ("apps.go", ("@org/gophers",)),
+        ("internal/api/generated/types.go", ("@org/codegen",)),
+        ("generated/types.go", ("@org/codegen",)),
+        # A pattern with no owners un-owns what it matches.
+        ("internal/vendor/lib.go", ()),
+        ("#notes.txt", ("@org/notes",)),
+    ],
+)
+def test_owners_follow_github_precedence(owners, path, expected):
+    assert owners.owners_of(path) == expected
+
+
+def test_negation_and_ranges_are_reported_not_applied(owners):
+    assert owners.errors == [
+        "CODEOWNERS:10: negation (!) is not supported in CODEOWNERS",
+        "CODEOWNERS:11: character ranges ([ ]) are not supported",
+    ]
+    # Neither line took effect: the earlier /internal/ rule still owns these.
+    assert owners.owners_of("internal/keep.go") == ("@org/platform-team",)
+    assert owners.owners_of("internal/a.go") == ("@org/platform-team",)
+
+
+def test_invalid_owner_skips_the_line():
+    parsed = parse_codeowners("* @org/all\n*.py not-an-owner\n")
+    assert parsed.errors == ["CODEOWNERS:2: invalid owner 'not-an-owner'"]
+    assert parsed.owners_of("a.py") == ("@org/all",)
+
+
+def _state_with(findings: list[dict]) -> dict:
+    state = state_mod.empty_state()
+    state["last_scan"] = "2026-01-01T00:00:00+00:00"
+    state["findings"] = {
+        f["id"]: {"status": "open", "tier": 3, "confidence": "medium", "summary": f["id"], **f}
+        for f in findings
+    }
+    return state
+
+
+def test_findings_are_stamped_and_reports_bucket_by_owner(tmp_path, monkeypatch, capsys):
+    (tmp_path / ".github").mkdir()
+    (tmp_path / ".github" / "CODEOWNERS").write_text(CODEOWNERS)
+    (tmp_path / "CODEOWNERS").write_text("* @ignored\n")
+    monkeypatch.chdir(tmp_path)
+    with runtime_scope(RuntimeContext(project_root=tmp_path)):
+        assert find_codeowners(tmp_path) == tmp_path / ".github" / "CODEOWNERS"
+        findings = [
+            {"id": "smells::internal/a.go::x", "detector": "smells", "file": "internal/a.go"},
+            {"id": "smells::internal/b.go::x", "detector": "smells", "file": "internal/b.go"},
+            {"id": "smells::cmd/main.go::x", "detector": "smells", "file": "cmd/main.go"},
+            {"id": "smells::internal/vendor/v.go::x", "detector": "smells",
+             "file": "internal/vendor/v.go"},
+        ]
+        _stamp_finding_context(findings, SimpleNamespace(name="go", zone_map=None))
+    assert [f["owners"] for f in findings] == [
+        ["@org/platform-team"], ["@org/platform-team"], ["@org/gophers"], []
+    ]
+    assert owner_counts(findings) == {
+        "@org/platform-team": 2, "@org/gophers": 1, UNOWNED: 1
+    }
+
+    state = _state_with(findings)
+    _recompute_stats(state)
+    assert state["stats"]["by_owner"] == {"@org/platform-team": 2, "@org/gophers": 1, UNOWNED: 1}
+    plan = generate_plan_md(state)
+    assert "## Findings by Owner" in plan
+    assert "| @org/platform-team | 2 |" in plan
+
+    scoped = state_mod.owner_scoped_state(state, "@ORG/platform-team")
+    assert sorted(scoped["findings"]) == ["smells::internal/a.go::x", "smells::internal/b.go::x"]
+    assert scoped["stats"]["open"] == 2
+    unowned = state_mod.owner_scoped_state(state, "unowned")
+    assert list(unowned["findings"]) == ["smells::internal/vendor/v.go::x"]
+
+    monkeypatch.setattr(
+        "desloppify.app.commands.plan_cmd.command_runtime",
+        lambda args: SimpleNamespace(state=state),
+    )
+    cmd_plan_output(SimpleNamespace(owner="@org/gophers", output=None))
+    out = capsys.readouterr().out
+    assert "**Owner:** @org/gophers" in out
+    assert "cmd/main.go" in out and "internal/a.go" not in out
@@ -0,0 +1,69 @@
+package atomics
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+var (
+	requests int64
+	failures int64
+	ready    uint32
+)
+
+type Stats struct {
+	mu    sync.Mutex
+	hits  int64
+	total int64
+	typed atomic.Int64
+}
+
+func Serve(fail bool) {
+	atomic.AddInt64(&requests, 1)
+	if fail {
+		atomic.AddInt64(&failures, 1)
+	}
+	atomic.StoreUint32(&ready, 1)
+}
+
+// A plain read of a counter other goroutines add to atomically.
+func Report() int64 {
+	return requests // want atomic_mixed_access "Variable accessed both through sync/atomic and plainly"
+}
+
+// Every access to failures is atomic.
+func Failures() int64 {
+	return atomic.LoadInt64(&failures)
+}
+
+func (s *Stats) Hit() {
+	atomic.AddInt64(&s.hits, 1)
+	s.typed.Add(1)
+}
+
+// Holding a mutex does not make the plain write safe against the atomic add.
+func (s *Stats) Reset() {
+	s.mu.Lock()
+	s.hits = 0 // want atomic_mixed_access "Variable accessed both through sync/atomic and plainly"
+	s.total = 0
+	s.mu.Unlock()
+}
+
+func (s *Stats) Hits() int64 {
+	return s.hits
+}
+
+func (s *Stats) Typed() int64 {
+	return s.typed.Load()
+}
+
+// A local of the same name is not the package variable.
+func Local() uint32 {
+	ready := uint32(0)
+	ready++
+	return ready
+}
+
+func init() {
+	ready = 0 // want atomic_mixed_access "Variable accessed both through sync/atomic and plainly"
+}
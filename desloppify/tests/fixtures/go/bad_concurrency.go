@@ -9,7 +9,7 @@ import (
 
 // time.Tick leak
 func tickLeak() {
-	ch := time.Tick(time.Second)
+	ch := time.Tick(time.Second) // want time_tick_leak "time.Tick leaks ticker (use time.NewTicker with Stop)"
 	for range ch {
 		fmt.Println("tick")
 	}
@@ -26,7 +26,7 @@ func deferInLoop(paths []string) {
 
 // Fire-and-forget goroutine
 func fireAndForget() {
-	go func() {
+	go func() { // want fire_and_forget_goroutine "Fire-and-forget goroutine (no sync mechanism)"
 		fmt.Println("running in background")
 	}()
 }
@@ -34,13 +34,13 @@ func fireAndForget() {
 // Unbuffered signal channel
 func unbufferedSignal() {
 	ch := make(chan os.Signal)
-	signal.Notify(ch, os.Interrupt)
+	signal.Notify(ch, os.Interrupt) // want unbuffered_signal "Unbuffered signal channel (may miss signals)"
 	<-ch
 }
 
 // Single-case select
 func singleSelect(ch chan int) {
-	select {
+	select { // want single_case_select "Single-case select (unnecessary overhead)"
 	case v := <-ch:
 		fmt.Println(v)
 	}
@@ -0,0 +1,24 @@
+package main
+
+func isSmall(n int) bool {
+	if n < 10 {
+		return true
+	}
+	return false
+}
+
+func isLarge(n int) bool {
+	if n < 100 {
+		return false
+	} else {
+		return true
+	}
+}
+
+func isZero(n int) bool {
+	if n == 0 {
+		// Zero is special-cased by callers.
+		return true
+	}
+	return false
+}
@@ -0,0 +1,91 @@
+package busyselect
+
+import (
+	"runtime"
+	"time"
+)
+
+func handle(v int) {}
+
+// Nothing ready means straight back to the top: one core at 100%.
+func Spin(ch <-chan int) {
+	for {
+		select {
+		case v := <-ch:
+			handle(v)
+		default: // want busy_select_default "select default inside an endless loop never waits"
+		}
+	}
+}
+
+// Counting misses is work, but it does not wait either.
+func Poll(ch <-chan int, stop *bool) {
+	misses := 0
+	for !*stop {
+		select {
+		case v := <-ch:
+			handle(v)
+		default: // want busy_select_default "select default inside an endless loop never waits"
+			misses++
+		}
+	}
+}
+
+// Backs off when there is nothing to do.
+func Backoff(ch <-chan int) {
+	for {
+		select {
+		case v := <-ch:
+			handle(v)
+		default:
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+}
+
+// Yields the processor on a miss.
+func Yield(ch <-chan int) {
+	for {
+		select {
+		case v := <-ch:
+			handle(v)
+		default:
+			runtime.Gosched()
+		}
+	}
+}
+
+// Without a default the select blocks until a case is ready.
+func Block(ch <-chan int, quit <-chan struct{}) {
+	for {
+		select {
+		case v := <-ch:
+			handle(v)
+		case <-quit:
+			return
+		}
+	}
+}
+
+// The non-blocking check is followed by a receive that waits.
+func Drain(ch <-chan int, quit <-chan struct{}) {
+	for {
+		select {
+		case <-quit:
+			return
+		default:
+		}
+		handle(<-ch)
+	}
+}
+
+// A bounded loop tries each channel once.
+func TryAll(chs []chan int) {
+	for _, ch := range chs {
+		select {
+		case v := <-ch:
+			handle(v)
+		default:
+		}
+	}
+}
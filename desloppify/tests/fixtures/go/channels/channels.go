@@ -0,0 +1,158 @@
+package channels
+
+import (
+	"context"
+	"errors"
+)
+
+type Cache struct {
+	sem  chan struct{}
+	data map[string]string
+}
+
+func NewCache() *Cache {
+	return &Cache{
+		sem:  make(chan struct{}, 1), // want channel_as_mutex "Channel used as a mutex"
+		data: map[string]string{},
+	}
+}
+
+func (c *Cache) Get(key string) string {
+	c.sem <- struct{}{}
+	defer func() { <-c.sem }()
+	return c.data[key]
+}
+
+func (c *Cache) Set(key, value string) {
+	c.sem <- struct{}{}
+	c.data[key] = value
+	<-c.sem
+}
+
+// A local lock around a counter.
+func Count(items []string) int {
+	lock := make(chan bool, 1) // want channel_as_mutex "Channel used as a mutex"
+	n := 0
+	for range items {
+		lock <- true
+		n++
+		<-lock
+	}
+	return n
+}
+
+// One goroutine signals another: not a lock.
+func Signal() {
+	done := make(chan struct{}, 1)
+	go func() { // want fire_and_forget_goroutine "Fire-and-forget goroutine"
+		done <- struct{}{}
+	}()
+	<-done
+}
+
+func Buffers() {
+	jobs := make(chan int, 64) // want magic_channel_buffer "Channel buffer size without explanation"
+	// One slot per worker, so no send blocks.
+	results := make(chan int, 8)
+	errs := make(chan error, 4) // room for every stage
+	close(jobs)
+	close(results)
+	close(errs)
+}
+
+func fetch() (string, error) { return "", errors.New("unavailable") }
+
+// The parent stops reading when the context ends first.
+func Fetch(ctx context.Context) (string, error) {
+	ch := make(chan string)
+	go func() { // want fire_and_forget_goroutine "Fire-and-forget goroutine"
+		v, _ := fetch()
+		ch <- v // want goroutine_send_leak "Goroutine can block forever sending"
+	}()
+	select {
+	case v := <-ch:
+		return v, nil
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+// A send in a select can give up.
+func FetchSafe(ctx context.Context) (string, error) {
+	ch := make(chan string)
+	go func() { // want fire_and_forget_goroutine "Fire-and-forget goroutine"
+		v, _ := fetch()
+		select {
+		case ch <- v:
+		case <-ctx.Done():
+		}
+	}()
+	select {
+	case v := <-ch:
+		return v, nil
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+// An error check returns before the receive.
+func Validate(input string) (string, error) {
+	out := make(chan string)
+	go func() { // want fire_and_forget_goroutine "Fire-and-forget goroutine"
+		out <- input + "!" // want goroutine_send_leak "Goroutine can block forever sending"
+	}()
+	if input == "" {
+		return "", errors.New("empty input")
+	}
+	return <-out, nil
+}
+
+// Each worker sends once; only the first result is read.
+func First(urls []string) string {
+	results := make(chan string)
+	for _, u := range urls {
+		go func(u string) { // want fire_and_forget_goroutine "Fire-and-forget goroutine"
+			results <- u // want goroutine_send_leak "Goroutine can block forever sending"
+		}(u)
+	}
+	return <-results
+}
+
+// Every result is read.
+func All(urls []string) []string {
+	results := make(chan string)
+	for _, u := range urls {
+		go func(u string) { // want fire_and_forget_goroutine "Fire-and-forget goroutine"
+			results <- u
+		}(u)
+	}
+	out := make([]string, 0, len(urls))
+	for range urls {
+		out = append(out, <-results)
+	}
+	return out
+}
+
+// The receive comes first, so the return is safe.
+func Wait() int {
+	ch := make(chan int)
+	go func() { // want fire_and_forget_goroutine "Fire-and-forget goroutine"
+		ch <- 1
+	}()
+	v := <-ch
+	if v == 0 {
+		return -1
+	}
+	return v
+}
+
+// Handed to a helper, which may read it.
+func Handoff() {
+	ch := make(chan int)
+	go func() { // want fire_and_forget_goroutine "Fire-and-forget goroutine"
+		ch <- 1
+	}()
+	drain(ch)
+}
+
+func drain(ch chan int) { <-ch }
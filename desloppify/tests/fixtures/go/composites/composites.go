@@ -0,0 +1,57 @@
+package composites
+
+import (
+	"net/http"
+	"time"
+)
+
+type Config struct {
+	Addr    string
+	Timeout time.Duration
+	Debug   bool
+}
+
+type Point struct {
+	X, Y int
+}
+
+type Span struct {
+	Start, End, Step int
+}
+
+type Named struct {
+	Config
+	Name string
+	Tags []string
+}
+
+func Defaults() Config {
+	return Config{":8080", 30 * time.Second, true} // want unkeyed_struct_literal "Struct literal with positional fields"
+}
+
+func Keyed() Config {
+	return Config{Addr: ":8080", Timeout: time.Second}
+}
+
+func Small() Point {
+	return Point{1, 2}
+}
+
+func Spans() []Span {
+	return []Span{
+		{0, 10, 1}, // want unkeyed_struct_literal "Struct literal with positional fields"
+		{Start: 10, End: 20, Step: 2},
+	}
+}
+
+func Embedded(c Config) *Named {
+	return &Named{c, "api", nil} // want unkeyed_struct_literal "Struct literal with positional fields"
+}
+
+func Cookie() *http.Cookie {
+	return &http.Cookie{Name: "session"}
+}
+
+func Pair() http.Header {
+	return http.Header{"Accept": {"*/*"}}
+}
@@ -0,0 +1,48 @@
+package main
+
+import "fmt"
+
+type node struct{ next *node }
+
+func conditions(x int, n *node, ready bool) {
+	if true {
+		fmt.Println("debug")
+	}
+	if x == x {
+		fmt.Println("always")
+	}
+	if n != nil || n == nil {
+		fmt.Println("always")
+	}
+	if ready && !ready {
+		fmt.Println("never")
+	}
+	if err := check(x); err != nil {
+		fmt.Println(err)
+	}
+	if x > 0 && ready {
+		fmt.Println("normal")
+	}
+	for i := 0; i < x; i++ {
+		fmt.Println(i)
+	}
+	for n != nil {
+		n = n.next
+	}
+	for {
+		break
+	}
+}
+
+func reportNaN(f float64) {
+	if f != f {
+		fmt.Println("NaN")
+	}
+}
+
+func check(x int) error {
+	if x < 0 {
+		return fmt.Errorf("negative: %d", x)
+	}
+	return nil
+}
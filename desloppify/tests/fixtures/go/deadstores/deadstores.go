@@ -0,0 +1,125 @@
+package deadstores
+
+import (
+	"errors"
+	"os"
+	"strings"
+)
+
+func load(name string) (string, error) {
+	if name == "" {
+		return "", errors.New("no name")
+	}
+	return name, nil
+}
+
+func Overwritten() string {
+	x, _ := load("a") // want ineffective_assignment "Value assigned and never read before it is overwritten or out of scope"
+	x, _ = load("b")
+	return x
+}
+
+func LostError() error {
+	_, err := load("a") // want error_overwritten "Error assigned again before it was checked (the first failure is lost)"
+	_, err = load("b")
+	return err
+}
+
+func CheckedError() error {
+	_, err := load("a")
+	if err != nil {
+		return err
+	}
+	_, err = load("b")
+	return err
+}
+
+func NeverRead(names []string) {
+	total := len(names) // want ineffective_assignment "Value assigned and never read before it is overwritten or out of scope"
+	for _, n := range names {
+		total = len(n) // want ineffective_assignment "Value assigned and never read before it is overwritten or out of scope"
+	}
+}
+
+func LoopCarried(names []string) int {
+	last := 0
+	for _, n := range names {
+		if last > 0 {
+			return last
+		}
+		last = len(n)
+	}
+	return 0
+}
+
+func Branch(ok bool) int {
+	v := 1
+	if ok {
+		v = 2
+	}
+	return v
+}
+
+func Shadowed() error {
+	err := os.Remove("a")
+	if err := os.Remove("b"); err != nil {
+		return err
+	}
+	return err
+}
+
+func Captured() func() int {
+	n := 1
+	f := func() int { return n }
+	n = 2
+	return f
+}
+
+func Trim(s string) string {
+	strings.TrimSpace(s) // want pure_result_discarded "Result of a function with no side effects thrown away (the call does nothing)"
+	return s
+}
+
+func Switch(k int) string {
+	s := ""
+	switch k {
+	case 1:
+		s = "one"
+	case 2:
+		s = "two"
+	}
+	return s
+}
+
+func SameLine(name string) int {
+	n := len(name); n = 2 // want ineffective_assignment "Value assigned and never read before it is overwritten or out of scope"
+	return n
+}
+
+func ReadBetween(name string) int {
+	n := len(name)
+	m := n
+	n = 2
+	return n + m
+}
+
+func Accumulated(names []string) int {
+	total := len(names)
+	total += len(names[0])
+	return total
+}
+
+func AccumulatedThenReset(names []string) int {
+	total := len(names)
+	total += len(names[0]) // want ineffective_assignment "Value assigned and never read before it is overwritten or out of scope"
+	total = 0
+	return total
+}
+
+func Stepped(names []string) int {
+	count := 0
+	for i := 0; i < len(names); i = i + 2 {
+		count++
+	}
+	return count
+}
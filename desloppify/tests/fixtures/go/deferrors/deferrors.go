@@ -0,0 +1,116 @@
+package deferrors
+
+import (
+	"bufio"
+	"database/sql"
+	"io"
+	"os"
+)
+
+// A failed commit goes unnoticed and the caller reports success.
+func Save(db *sql.DB, name string) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Commit() // want deferred_error_ignored "Deferred call discards an error that matters"
+	_, err = tx.Exec("INSERT INTO names VALUES (?)", name)
+	return err
+}
+
+// Closing a file that was written can be where the write fails.
+func Write(path string, data []byte) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close() // want deferred_error_ignored "Deferred call discards an error that matters"
+	_, err = f.Write(data)
+	return err
+}
+
+// The closure calls Flush but still drops its error.
+func Report(out io.Writer, lines []string) {
+	w := bufio.NewWriter(out)
+	defer func() {
+		w.Flush() // want deferred_error_ignored "Deferred call discards an error that matters"
+	}()
+	for _, line := range lines {
+		_, _ = w.WriteString(line)
+	}
+}
+
+// Discarding on purpose, in a closure, is a decision someone made.
+func Dump(out io.Writer, lines []string) {
+	w := bufio.NewWriter(out)
+	defer func() { _ = w.Flush() }()
+	for _, line := range lines {
+		_, _ = w.WriteString(line)
+	}
+}
+
+// A file only read may drop its Close error.
+func Read(path string) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return io.ReadAll(f)
+}
+
+// The error reaches the caller through the named result.
+func Store(db *sql.DB, name string) (err error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if cerr := tx.Commit(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}()
+	_, err = tx.Exec("INSERT INTO names VALUES (?)", name)
+	return err
+}
+
+// Rollback after a commit is expected to fail; its error is noise.
+func Update(db *sql.DB, name string) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if _, err := tx.Exec("UPDATE names SET name = ?", name); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// When Open fails, f is nil and the deferred Close panics.
+func Size(path string) (int64, error) {
+	f, err := os.Open(path)
+	defer f.Close() // want defer_on_maybe_nil "defer on a value before its error is checked"
+	if err != nil {
+		return 0, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		return 0, err
+	}
+	return info.Size(), nil
+}
+
+// Checking first is the safe order.
+func Lines(path string) (int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	n := 0
+	for s := bufio.NewScanner(f); s.Scan(); {
+		n++
+	}
+	return n, nil
+}
@@ -0,0 +1,47 @@
+package main
+
+import "fmt"
+
+func describe(x int) string {
+	switch x {
+	case 1:
+		return "one"
+	case 2, 3:
+		return "few"
+	case 1:
+		return "one again"
+	}
+	return "many"
+}
+
+func classify(x int) {
+	if x == 1 {
+		fmt.Println("one")
+	} else if x == 2 {
+		fmt.Println("two")
+	} else if x == 2 {
+		fmt.Println("two again")
+	} else {
+		fmt.Println("other")
+	}
+}
+
+func normal(x int, s string) {
+	switch s {
+	case "a", "b":
+		fmt.Println(s)
+	case "c":
+		switch x {
+		case 1:
+			fmt.Println("nested one")
+		}
+	default:
+		fmt.Println("default")
+	}
+	if x > 0 {
+		fmt.Println("positive")
+	}
+	if x > 0 {
+		fmt.Println("separate statement")
+	}
+}
@@ -0,0 +1,30 @@
+package durations
+
+import (
+	"context"
+	"time"
+)
+
+const pollInterval = 250 * time.Millisecond
+
+func Retry(ctx context.Context, backoff time.Duration) error {
+	time.Sleep(5) // want bare_duration "Duration given as a bare integer"
+	time.Sleep(5 * time.Second)
+	time.Sleep(backoff)
+	time.Sleep(pollInterval)
+	time.Sleep(0)
+
+	timer := time.NewTimer(100 * 10) // want bare_duration "Duration given as a bare integer"
+	defer timer.Stop()
+
+	ctx, cancel := context.WithTimeout(ctx, 30) // want bare_duration "Duration given as a bare integer"
+	defer cancel()
+
+	select {
+	case <-time.After(time.Duration(3) * time.Second):
+	case <-timer.C:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return nil
+}
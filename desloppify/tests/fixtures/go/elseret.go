@@ -0,0 +1,33 @@
+package main
+
+import "errors"
+
+func parity(n int) (string, error) {
+	if n < 0 {
+		return "", errors.New("negative")
+	} else {
+		if n%2 == 0 {
+			return "even", nil
+		}
+		return "odd", nil
+	}
+}
+
+func sign(n int) string {
+	if n < 0 {
+		return "-"
+	} else if n > 0 {
+		return "+"
+	} else {
+		return "0"
+	}
+}
+
+func clamp(n int) int {
+	if n > 10 {
+		n = 10
+	} else {
+		n++
+	}
+	return n
+}
@@ -0,0 +1,35 @@
+package main
+
+import "fmt"
+
+func handle(err error, items []string, n int) {
+	if err != nil {
+	}
+	if n > 0 {
+		fmt.Println("positive")
+	} else {
+	}
+	for _, item := range items {
+	}
+	switch n {
+	}
+}
+
+func normal(err error, key [32]byte, buf []byte, done chan struct{}) {
+	if err != nil {
+		fmt.Println(err)
+	}
+	if err == nil {
+		// Nothing to report; success is the common path.
+	}
+	if key == [32]byte{} {
+		fmt.Println("zero key")
+	}
+	p := 0
+	for ; p < len(buf) && buf[p] == ' '; p++ {
+	}
+	for range done {
+	}
+	for {
+	}
+}
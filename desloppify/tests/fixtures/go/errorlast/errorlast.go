@@ -0,0 +1,40 @@
+package errorlast
+
+import (
+	"errors"
+	"strings"
+)
+
+type Parser struct{ input string }
+
+func split(s string) (error, string) { // want error_not_last "Error result declared before another result (Go puts error last)"
+	if s == "" {
+		return errors.New("empty input"), ""
+	}
+	return nil, strings.TrimSpace(s)
+}
+
+func (p *Parser) Next() (err error, token string, ok bool) { // want error_not_last "Error result declared before another result (Go puts error last)"
+	token, ok = strings.CutPrefix(p.input, " ")
+	return nil, token, ok
+}
+
+func trim(s string) (string, error) {
+	if s == "" {
+		return "", errors.New("empty input")
+	}
+	return strings.TrimSpace(s), nil
+}
+
+func check(s string) error {
+	if s == "" {
+		return errors.New("empty input")
+	}
+	return nil
+}
+
+func pair() (first, second error) {
+	return check("a"), check("b")
+}
+
+var _ = []any{split, (*Parser).Next, trim, pair}
@@ -0,0 +1,47 @@
+package errorsas
+
+import (
+	"errors"
+	"io/fs"
+	"net"
+)
+
+type TimeoutError struct{ Op string }
+
+func (e TimeoutError) Error() string { return e.Op + ": timeout" }
+
+var lastTimeout TimeoutError
+
+func Value(err error) bool {
+	var myErr TimeoutError
+	return errors.As(err, myErr) // want errors_as_target "errors.As target is not a pointer"
+}
+
+func Address(err error) bool {
+	var myErr TimeoutError
+	return errors.As(err, &myErr)
+}
+
+func Pointer(err error) string {
+	target := new(fs.PathError)
+	if errors.As(err, target) {
+		return target.Path
+	}
+	return ""
+}
+
+func Param(err error, target *net.OpError) bool {
+	return errors.As(err, target)
+}
+
+func Literal(err error) bool {
+	return errors.As(err, TimeoutError{}) // want errors_as_target "errors.As target is not a pointer"
+}
+
+func Global(err error) bool {
+	return errors.As(err, lastTimeout) // want errors_as_target "errors.As target is not a pointer"
+}
+
+func Nil(err error) bool {
+	return errors.As(err, nil) // want errors_as_target "errors.As target is not a pointer"
+}
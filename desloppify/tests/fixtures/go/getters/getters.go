@@ -0,0 +1,50 @@
+package getters
+
+// User holds a display name.
+type User struct {
+	name string
+}
+
+// GetName reads the name through a Get prefix.
+func (u *User) GetName() string { // want getter_prefix "Getter named GetX (Go getters drop the Get prefix)"
+	return u.name
+}
+
+// Account holds a display name too.
+type Account struct {
+	name string
+}
+
+// Name is the idiomatic getter.
+func (a *Account) Name() string {
+	return a.name
+}
+
+// Cache is a map-like store.
+type Cache[V any] struct {
+	items map[string]V
+}
+
+// Get looks a key up; lookups with arguments keep their Get.
+func (c *Cache[V]) Get(key string) (V, bool) {
+	v, ok := c.items[key]
+	return v, ok
+}
+
+// Record exposes its ID as a field, so GetID cannot become ID.
+type Record struct {
+	ID string
+}
+
+// GetID is nil-safe, like a generated getter.
+func (r *Record) GetID() string {
+	if r == nil {
+		return ""
+	}
+	return r.ID
+}
+
+// GetReady only acts; it returns nothing.
+func (u *User) GetReady() {
+	u.name = "ready"
+}
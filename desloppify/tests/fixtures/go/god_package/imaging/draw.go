@@ -0,0 +1,11 @@
+package draw // want package_dir_mismatch "Package name does not match its directory"
+
+// Point is a position on the canvas.
+type Point struct {
+	X, Y int
+}
+
+// Add moves p by q.
+func (p Point) Add(q Point) Point {
+	return Point{X: p.X + q.X, Y: p.Y + q.Y}
+}
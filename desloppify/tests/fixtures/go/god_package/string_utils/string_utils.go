@@ -0,0 +1,11 @@
+package string_utils // want package_name_style "Package name with underscores or mixed caps (or, optionally, plural)"
+
+import "strings"
+
+// Title upper-cases the first letter of s.
+func Title(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
@@ -1,10 +1,11 @@
-package utils
+// want-package god_package "God package 'god_package': 43 exported symbols"
+package utils // want package_dir_mismatch "Package name does not match its directory"
 
 // God package: generic name + many exported symbols
 
 func FormatName(first, last string) string { return first + " " + last }
 func FormatDate(year, month, day int) string { return "" }
-func FormatTime(hour, min, sec int) string { return "" }
+func FormatTime(hour, min, sec int) string { return "" } // want builtin_shadow "Declaration shadows a builtin identifier"
 func FormatCurrency(amount float64) string { return "" }
 func FormatPercent(val float64) string { return "" }
 func ParseName(s string) string { return s }
@@ -35,8 +36,8 @@ func Keys(m map[string]string) []string { return nil }
 func Values(m map[string]string) []string { return nil }
 func Merge(a, b map[string]string) map[string]string { return nil }
 func DeepCopy(m map[string]string) map[string]string { return nil }
-func Retry(fn func() error, times int) error { return nil }
-func Must(err error) { if err != nil { panic(err) } }
+func Retry(fn func() error, times int) error { return nil } // want useless_error_return "Function returns error but only ever returns nil"
+func Must(err error) { if err != nil { panic(err) } } // want panic_in_lib "panic() in library code reachable from the exported API"
 func Ptr(s string) *string { return &s }
 func Deref(s *string) string { if s == nil { return "" }; return *s }
 func Coalesce(vals ...string) string { return "" }
@@ -0,0 +1,6 @@
+package utilsHelpers // want package_name_style "Package name with underscores or mixed caps (or, optionally, plural)"
+
+// Clamp limits v to [lo, hi].
+func Clamp(v, lo, hi int) int {
+	return max(lo, min(hi, v))
+}
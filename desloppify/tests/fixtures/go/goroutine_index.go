@@ -0,0 +1,66 @@
+package main
+
+import "sync"
+
+func compute(n int) int { return n * n }
+
+// Every goroutine may see the last i and write the same element.
+func squaresCaptured(s []int) {
+	var wg sync.WaitGroup
+	for i := range s {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s[i] = compute(i)
+		}()
+	}
+	wg.Wait()
+}
+
+func countsCaptured(counts []int, n int) {
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			counts[i]++
+		}()
+	}
+	wg.Wait()
+}
+
+// The i := i copy gives each goroutine its own index.
+func squaresCopied(s []int) {
+	var wg sync.WaitGroup
+	for i := range s {
+		i := i
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s[i] = compute(i)
+		}()
+	}
+	wg.Wait()
+}
+
+// Passing the index as an argument works too.
+func squaresPassed(s []int) {
+	var wg sync.WaitGroup
+	for i := range s {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			s[i] = compute(i)
+		}(i)
+	}
+	wg.Wait()
+}
+
+// Reading s[i] is racy on i as well, but only writes are flagged.
+func squaresRead(s []int, out chan<- int) {
+	for i := range s {
+		go func() {
+			out <- s[i]
+		}()
+	}
+}
@@ -0,0 +1,87 @@
+package heldlocks
+
+import (
+	"net/http"
+	"sync"
+	"time"
+)
+
+type Cache struct {
+	mu     sync.Mutex
+	rw     sync.RWMutex
+	client *http.Client
+	data   map[string]string
+	events chan string
+}
+
+// The deferred unlock holds the lock across the whole request.
+func (c *Cache) Refresh(url string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return err
+	}
+	_, err = c.client.Do(req) // want blocking_under_lock "Blocking call while holding a mutex"
+	return err
+}
+
+// Every other caller waits out the sleep.
+func (c *Cache) Retry(key string) string {
+	c.mu.Lock()
+	for i := 0; i < 3; i++ {
+		if v, ok := c.data[key]; ok {
+			c.mu.Unlock()
+			return v
+		}
+		time.Sleep(time.Second) // want blocking_under_lock "Blocking call while holding a mutex"
+	}
+	c.mu.Unlock()
+	return ""
+}
+
+// A send on a full channel parks the goroutine with the read lock held.
+func (c *Cache) Announce(key string) {
+	c.rw.RLock()
+	defer c.rw.RUnlock()
+	c.events <- c.data[key] // want blocking_under_lock "Blocking call while holding a mutex"
+}
+
+// Released before the slow part.
+func (c *Cache) Store(key string, notify chan<- string) {
+	c.mu.Lock()
+	c.data[key] = "pending"
+	c.mu.Unlock()
+	notify <- key
+}
+
+// The branch unlocks before it fetches.
+func (c *Cache) Fetch(key, url string) (*http.Response, error) {
+	c.mu.Lock()
+	if _, ok := c.data[key]; !ok {
+		c.mu.Unlock()
+		return http.Get(url)
+	}
+	c.mu.Unlock()
+	return nil, nil
+}
+
+// The goroutine does not hold the lock.
+func (c *Cache) Later(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.data[key] = "later"
+	go func() { // want fire_and_forget_goroutine "Fire-and-forget goroutine"
+		time.Sleep(time.Second)
+		c.events <- key
+	}()
+}
+
+// Network calls under a read lock are allowed by default.
+func (c *Cache) Mirror(url string) error {
+	c.rw.RLock()
+	defer c.rw.RUnlock()
+	req, _ := http.NewRequest("PUT", url, nil)
+	_, err := c.client.Do(req)
+	return err
+}
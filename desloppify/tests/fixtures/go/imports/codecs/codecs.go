@@ -0,0 +1,7 @@
+// Package codecs registers the image formats programs can decode.
+package codecs
+
+import (
+	_ "image/gif"
+	_ "image/jpeg"
+)
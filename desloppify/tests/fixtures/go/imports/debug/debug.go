@@ -0,0 +1,11 @@
+package debug
+
+import (
+	"net/http"
+	_ "net/http/pprof"
+)
+
+// Serve exposes the pprof handlers on addr.
+func Serve(addr string) error {
+	return http.ListenAndServe(addr, nil)
+}
@@ -0,0 +1,24 @@
+package imports
+
+import (
+	_ "embed"
+	"image"
+	_ "image/png" // want blank_import "Blank import outside package main (hidden init side effects)"
+	"io"
+	_ "net/http/pprof" // want blank_import "Blank import outside package main (hidden init side effects)"
+	. "strings" // want dot_import "Dot import (names read as if declared locally)"
+)
+
+//go:embed imports.go
+var self string
+
+// Decode reads an image in any registered format.
+func Decode(r io.Reader) (image.Image, error) {
+	img, _, err := image.Decode(r)
+	return img, err
+}
+
+// Shout upper-cases s through a dot import.
+func Shout(s string) string {
+	return ToUpper(s) + self[:0]
+}
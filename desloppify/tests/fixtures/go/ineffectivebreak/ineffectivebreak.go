@@ -0,0 +1,115 @@
+package ineffectivebreak
+
+import (
+	"bufio"
+	"context"
+	"io"
+)
+
+func Drain(next func() int) int {
+	total := 0
+	for {
+		switch v := next(); {
+		case v < 0:
+			break // want ineffective_break "Unlabeled break in a switch/select inside a loop (leaves the switch, not the loop)"
+		default:
+			total = total + v
+		}
+	}
+}
+
+func Wait(ctx context.Context, jobs <-chan int, run func(int)) {
+	for {
+		select {
+		case <-ctx.Done():
+			break // want ineffective_break "Unlabeled break in a switch/select inside a loop (leaves the switch, not the loop)"
+		case j := <-jobs:
+			run(j)
+		}
+	}
+}
+
+func Lines(r *bufio.Reader, emit func(string)) {
+	for {
+		line, err := r.ReadString('\n')
+		switch {
+		case len(line) > 0:
+			if err == io.EOF {
+				break // want ineffective_break "Unlabeled break in a switch/select inside a loop (leaves the switch, not the loop)"
+			}
+			emit(line)
+		default:
+			return
+		}
+	}
+}
+
+func Labeled(next func() int) int {
+	total := 0
+loop:
+	for {
+		switch v := next(); {
+		case v < 0:
+			break loop
+		default:
+			total = total + v
+		}
+	}
+	return total
+}
+
+func Classify(v int) string {
+	kind := "small"
+	switch {
+	case v < 0:
+		break
+	case v > 100:
+		kind = "large"
+	}
+	return kind
+}
+
+func Inner(groups [][]int) int {
+	total := 0
+	for _, g := range groups {
+		switch len(g) {
+		case 0:
+			total--
+		default:
+			for _, v := range g {
+				if v < 0 {
+					break
+				}
+				total = total + v
+			}
+		}
+	}
+	return total
+}
+
+func Skip(words []string, emit func(string)) {
+	for _, w := range words {
+		switch w {
+		case "":
+			if len(words) > 1 {
+				break
+			}
+			emit("empty")
+		default:
+			emit(w)
+		}
+	}
+}
+
+func Printable(s string) int {
+	n := 0
+	for _, r := range s {
+		switch {
+		case r == '\t':
+			break
+		case r >= ' ':
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,51 @@
+package jsontags
+
+import (
+	"encoding/json"
+	"io"
+)
+
+type User struct {
+	ID       int    `json:"id"`
+	Name     string `json:"name,omitempy"` // want json_tag_invalid "json tag with an unknown option or malformed key"
+	Email    string `json: "email"`      // want json_tag_invalid "json tag with an unknown option or malformed key"
+	Login    string `json:"name"`        // want json_tag_duplicate "Struct fields with the same JSON name"
+	password string `json:"password"`    // want json_tag_unexported "json tag on an unexported field"
+	Age      int    `json:",string"`
+	Internal string `json:"-"`
+	Dash     string `json:"-,"`
+	Address  Address
+	secret   string // want unused_field "Unexported struct field nothing in the package reads or sets"
+}
+
+type Address struct {
+	City string `json:"city,omitempty"`
+	Zip  string `json:"zip,omitzero"`
+}
+
+func Encode(u User) ([]byte, error) {
+	return json.Marshal(u)
+}
+
+func Decode(data []byte) (User, error) {
+	var u User
+	err := json.Unmarshal(data, u) // want json_unmarshal_non_pointer "json Unmarshal or Decode into a non-pointer"
+	return u, err
+}
+
+func DecodeInto(data []byte) (User, error) {
+	var u User
+	err := json.Unmarshal(data, &u)
+	return u, err
+}
+
+func Stream(r io.Reader) (map[string]any, error) {
+	var m map[string]any
+	dec := json.NewDecoder(r)
+	if err := dec.Decode(m); err != nil { // want json_unmarshal_non_pointer "json Unmarshal or Decode into a non-pointer"
+		return nil, err
+	}
+	out := new(Address)
+	err := json.NewDecoder(r).Decode(out)
+	return m, err
+}
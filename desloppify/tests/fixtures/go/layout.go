@@ -0,0 +1,27 @@
+package main
+
+import "fmt"
+
+// paddedRecord interleaves bools with int64s: 40 bytes, 24 when reordered.
+type paddedRecord struct {
+	active  bool
+	id      int64
+	enabled bool
+	total   int64
+	ok      bool
+}
+
+// packedRecord already orders fields by alignment: 24 bytes, no waste.
+type packedRecord struct {
+	id      int64
+	total   int64
+	count   int32
+	active  bool
+	enabled bool
+}
+
+func describeRecords() {
+	p := paddedRecord{id: 1}
+	q := packedRecord{id: 2}
+	fmt.Println(p.id, q.id)
+}
@@ -0,0 +1,131 @@
+package lazyinit
+
+import (
+	"net/http"
+	"sync"
+)
+
+type Config struct{ Name string }
+
+func load() *Config { return &Config{Name: "default"} }
+
+var (
+	mu       sync.Mutex
+	instance *Config
+	naive    *Config
+	guarded  *Config
+	defaults *Config
+	private  *Config
+	once     sync.Once
+	single   *Config
+	boot     *Config
+)
+
+// The outer check reads instance while another caller may be writing it.
+func Instance() *Config {
+	if instance == nil { // want racy_lazy_init "Lazy initialization racing with its readers"
+		mu.Lock()
+		if instance == nil {
+			instance = load()
+		}
+		mu.Unlock()
+	}
+	return instance
+}
+
+// Without the second check, two callers can both initialize.
+func Naive() *Config {
+	if naive == nil { // want racy_lazy_init "Lazy initialization racing with its readers"
+		mu.Lock()
+		naive = load()
+		mu.Unlock()
+	}
+	return naive
+}
+
+// Locked throughout, so this one is fine...
+func Guarded() *Config {
+	mu.Lock()
+	defer mu.Unlock()
+	if guarded == nil {
+		guarded = load()
+	}
+	return guarded
+}
+
+// ...but Peek reads guarded without the lock.
+func Peek() string {
+	if guarded != nil { // want racy_lazy_init "Lazy initialization racing with its readers"
+		return guarded.Name
+	}
+	return ""
+}
+
+// A caller of a *Locked function holds mu.
+func nameLocked() string {
+	return guarded.Name
+}
+
+// No lock at all, and DefaultName reads defaults too.
+func Defaults() *Config {
+	if defaults == nil { // want racy_lazy_init "Lazy initialization racing with its readers"
+		defaults = load()
+	}
+	return defaults
+}
+
+func DefaultName() string {
+	return defaults.Name
+}
+
+// Nothing else touches private.
+func Private() *Config {
+	if private == nil {
+		private = load()
+	}
+	return private
+}
+
+func Single() *Config {
+	once.Do(func() { single = load() })
+	return single
+}
+
+// The local shadows the package variable.
+func Fresh() *Config {
+	var instance *Config
+	if instance == nil {
+		instance = load()
+	}
+	return instance
+}
+
+// init runs before any goroutine.
+func init() {
+	if boot == nil {
+		boot = load()
+	}
+}
+
+func Boot() *Config {
+	return boot
+}
+
+type Client struct {
+	mu   sync.Mutex
+	http *http.Client
+}
+
+// Reset guards c.http with c.mu; HTTP does not.
+func (c *Client) HTTP() *http.Client {
+	if c.http == nil { // want racy_lazy_init "Lazy initialization racing with its readers"
+		c.http = &http.Client{}
+	}
+	return c.http
+}
+
+func (c *Client) Reset() {
+	c.mu.Lock()
+	c.http = nil
+	c.mu.Unlock()
+}
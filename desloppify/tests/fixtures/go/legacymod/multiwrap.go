@@ -0,0 +1,19 @@
+package legacymod
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrClosed  = errors.New("closed")
+	ErrTimeout = errors.New("timeout")
+)
+
+func closeBoth() error {
+	return fmt.Errorf("close: %w, %w", ErrClosed, ErrTimeout)
+}
+
+func closeOne() error {
+	return fmt.Errorf("close: %w", ErrClosed)
+}
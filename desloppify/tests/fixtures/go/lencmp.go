@@ -0,0 +1,27 @@
+package main
+
+import "fmt"
+
+func lengthChecks(items []string, extra int) {
+	if len(items) < 0 {
+		fmt.Println("never")
+	}
+	if len(items) >= 0 {
+		fmt.Println("always")
+	}
+	if len(items) > 0 {
+		fmt.Println("non-empty")
+	}
+	if 0 > len(items) {
+		fmt.Println("never")
+	}
+	if len(items) == len(items) {
+		fmt.Println("always")
+	}
+	if extra+len(items) < 0 {
+		fmt.Println("overflow guard")
+	}
+	if len(items) < 0+extra {
+		fmt.Println("depends on extra")
+	}
+}
@@ -0,0 +1,79 @@
+package loggederr
+
+import (
+	"log"
+	"log/slog"
+	"os"
+	"strconv"
+)
+
+func Load(path string) ([]byte, error) { // want useless_error_return "Function returns error but only ever returns nil"
+	data, err := os.ReadFile(path)
+	if err != nil { // want error_only_logged "Error checked, logged and then ignored (the code carries on as if it worked)"
+		log.Printf("reading %s: %v", path, err)
+	}
+	return data, nil
+}
+
+func Port(s string) int {
+	port, err := strconv.Atoi(s)
+	if err != nil { // want error_only_logged "Error checked, logged and then ignored (the code carries on as if it worked)"
+		slog.Warn("bad port", "value", s, "err", err)
+	}
+	return port + 1
+}
+
+func Max(fields []string) int {
+	total := 0
+	for _, f := range fields {
+		n, err := strconv.Atoi(f)
+		if err != nil {
+			log.Printf("skipping %q: %v", f, err)
+			continue
+		}
+		total = max(total, n)
+	}
+	return total
+}
+
+func tryRemove(path string) error {
+	err := os.Remove(path)
+	if err != nil {
+		log.Println(err)
+	}
+	return nil
+}
+
+// Cleanup removes the scratch directory, best-effort.
+func Cleanup(dir string) error { // want useless_error_return "Function returns error but only ever returns nil"
+	if err := os.RemoveAll(dir); err != nil {
+		log.Println(err)
+	}
+	return nil
+}
+
+func Flush(f *os.File) {
+	if err := f.Sync(); err != nil {
+		log.Println(err)
+	}
+}
+
+func MustOpen(path string) *os.File {
+	f, err := os.Open(path)
+	if err != nil {
+		log.Fatalf("open %s: %v", path, err)
+	}
+	return f
+}
+
+func Parse(s string) (int, error) {
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		log.Println(err)
+	} else {
+		n *= 2
+	}
+	return n, err
+}
+
+var _ = tryRemove
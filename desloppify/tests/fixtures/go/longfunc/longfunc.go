@@ -0,0 +1,452 @@
+package longfunc
+
+import (
+	"errors"
+	"strings"
+)
+
+type Item struct {
+	SKU   string
+	Qty   int
+	Price float64
+}
+
+type Order struct {
+	ID       string
+	Customer string
+	Email    string
+	Country  string
+	Coupon   string
+	Items    []Item
+	Notes    []string
+}
+
+// Checkout does everything inline.
+func Checkout(o *Order) (float64, error) { // want long_function "Function too long" name_length "Short name used far"
+	// Validate inputs.
+	if o.ID == "" {
+		return 0, errors.New("missing id")
+	}
+	if len(o.Items) == 0 {
+		return 0, errors.New("no items")
+	}
+	if o.Items[0%len(o.Items)].Qty < 0 {
+		return 0, errors.New("negative quantity")
+	}
+	if o.Items[1%len(o.Items)].Qty < 0 {
+		return 0, errors.New("negative quantity")
+	}
+	if o.Items[2%len(o.Items)].Qty < 0 {
+		return 0, errors.New("negative quantity")
+	}
+	if o.Items[3%len(o.Items)].Qty < 0 {
+		return 0, errors.New("negative quantity")
+	}
+	if o.Items[4%len(o.Items)].Qty < 0 {
+		return 0, errors.New("negative quantity")
+	}
+	if o.Items[5%len(o.Items)].Qty < 0 {
+		return 0, errors.New("negative quantity")
+	}
+	if o.Items[6%len(o.Items)].Qty < 0 {
+		return 0, errors.New("negative quantity")
+	}
+	if o.Items[7%len(o.Items)].Qty < 0 {
+		return 0, errors.New("negative quantity")
+	}
+	if o.Items[8%len(o.Items)].Qty < 0 {
+		return 0, errors.New("negative quantity")
+	}
+	if o.Items[9%len(o.Items)].Qty < 0 {
+		return 0, errors.New("negative quantity")
+	}
+	if o.Items[10%len(o.Items)].Qty < 0 {
+		return 0, errors.New("negative quantity")
+	}
+	if o.Items[11%len(o.Items)].Qty < 0 {
+		return 0, errors.New("negative quantity")
+	}
+
+	// Normalize customer fields.
+	o.Customer = strings.TrimSpace(o.Customer)
+	o.Customer = strings.ToLower(o.Customer)
+	o.Notes = append(o.Notes, "normalized customer")
+	o.Email = strings.TrimSpace(o.Email)
+	o.Email = strings.ToLower(o.Email)
+	o.Notes = append(o.Notes, "normalized email")
+	o.Country = strings.TrimSpace(o.Country)
+	o.Country = strings.ToLower(o.Country)
+	o.Notes = append(o.Notes, "normalized country")
+	o.Coupon = strings.TrimSpace(o.Coupon)
+	o.Coupon = strings.ToLower(o.Coupon)
+	o.Notes = append(o.Notes, "normalized coupon")
+
+	// Price the order.
+	total := 0.0
+	if len(o.Items) > 1 {
+		total += o.Items[1].Price * float64(o.Items[1].Qty)
+	}
+	if len(o.Items) > 2 {
+		total += o.Items[2].Price * float64(o.Items[2].Qty)
+	}
+	if len(o.Items) > 3 {
+		total += o.Items[3].Price * float64(o.Items[3].Qty)
+	}
+	if len(o.Items) > 4 {
+		total += o.Items[4].Price * float64(o.Items[4].Qty)
+	}
+	if len(o.Items) > 5 {
+		total += o.Items[5].Price * float64(o.Items[5].Qty)
+	}
+	if len(o.Items) > 6 {
+		total += o.Items[6].Price * float64(o.Items[6].Qty)
+	}
+	if len(o.Items) > 7 {
+		total += o.Items[7].Price * float64(o.Items[7].Qty)
+	}
+	if len(o.Items) > 8 {
+		total += o.Items[8].Price * float64(o.Items[8].Qty)
+	}
+	if len(o.Items) > 9 {
+		total += o.Items[9].Price * float64(o.Items[9].Qty)
+	}
+	if len(o.Items) > 10 {
+		total += o.Items[10].Price * float64(o.Items[10].Qty)
+	}
+	if len(o.Items) > 11 {
+		total += o.Items[11].Price * float64(o.Items[11].Qty)
+	}
+	if len(o.Items) > 12 {
+		total += o.Items[12].Price * float64(o.Items[12].Qty)
+	}
+	if len(o.Items) > 13 {
+		total += o.Items[13].Price * float64(o.Items[13].Qty)
+	}
+	if len(o.Items) > 14 {
+		total += o.Items[14].Price * float64(o.Items[14].Qty)
+	}
+	if len(o.Items) > 15 {
+		total += o.Items[15].Price * float64(o.Items[15].Qty)
+	}
+	if len(o.Items) > 16 {
+		total += o.Items[16].Price * float64(o.Items[16].Qty)
+	}
+	total += o.Items[0].Price * float64(o.Items[0].Qty)
+	if o.Coupon == "half" {
+		total /= 2
+	}
+
+	// Apply regional tax.
+	if o.Country == "de" {
+		total *= 1.19
+	}
+	if o.Country == "fr" {
+		total *= 1.20
+	}
+	if o.Country == "it" {
+		total *= 1.22
+	}
+	if o.Country == "es" {
+		total *= 1.21
+	}
+	if o.Country == "nl" {
+		total *= 1.21
+	}
+	if o.Country == "pl" {
+		total *= 1.23
+	}
+	return total, nil
+}
+
+var statusNames = map[int]string{
+	0: "status-0",
+	1: "status-1",
+	2: "status-2",
+	3: "status-3",
+	4: "status-4",
+	5: "status-5",
+	6: "status-6",
+	7: "status-7",
+	8: "status-8",
+	9: "status-9",
+	10: "status-10",
+	11: "status-11",
+	12: "status-12",
+	13: "status-13",
+	14: "status-14",
+	15: "status-15",
+	16: "status-16",
+	17: "status-17",
+	18: "status-18",
+	19: "status-19",
+	20: "status-20",
+	21: "status-21",
+	22: "status-22",
+	23: "status-23",
+	24: "status-24",
+	25: "status-25",
+	26: "status-26",
+	27: "status-27",
+	28: "status-28",
+	29: "status-29",
+	30: "status-30",
+	31: "status-31",
+	32: "status-32",
+	33: "status-33",
+	34: "status-34",
+	35: "status-35",
+	36: "status-36",
+	37: "status-37",
+	38: "status-38",
+	39: "status-39",
+	40: "status-40",
+	41: "status-41",
+	42: "status-42",
+	43: "status-43",
+	44: "status-44",
+	45: "status-45",
+	46: "status-46",
+	47: "status-47",
+	48: "status-48",
+	49: "status-49",
+	50: "status-50",
+	51: "status-51",
+	52: "status-52",
+	53: "status-53",
+	54: "status-54",
+	55: "status-55",
+	56: "status-56",
+	57: "status-57",
+	58: "status-58",
+	59: "status-59",
+	60: "status-60",
+	61: "status-61",
+	62: "status-62",
+	63: "status-63",
+	64: "status-64",
+	65: "status-65",
+	66: "status-66",
+	67: "status-67",
+	68: "status-68",
+	69: "status-69",
+	70: "status-70",
+	71: "status-71",
+	72: "status-72",
+	73: "status-73",
+	74: "status-74",
+	75: "status-75",
+	76: "status-76",
+	77: "status-77",
+	78: "status-78",
+	79: "status-79",
+	80: "status-80",
+	81: "status-81",
+	82: "status-82",
+	83: "status-83",
+	84: "status-84",
+	85: "status-85",
+	86: "status-86",
+	87: "status-87",
+	88: "status-88",
+	89: "status-89",
+	90: "status-90",
+	91: "status-91",
+	92: "status-92",
+	93: "status-93",
+	94: "status-94",
+	95: "status-95",
+	96: "status-96",
+	97: "status-97",
+	98: "status-98",
+	99: "status-99",
+}
+
+type Kind int
+
+// KindName is a marshaling switch: long, but one statement per case.
+func KindName(k Kind) string {
+	switch k {
+	case 0:
+		return "kind-0"
+	case 1:
+		return "kind-1"
+	case 2:
+		return "kind-2"
+	case 3:
+		return "kind-3"
+	case 4:
+		return "kind-4"
+	case 5:
+		return "kind-5"
+	case 6:
+		return "kind-6"
+	case 7:
+		return "kind-7"
+	case 8:
+		return "kind-8"
+	case 9:
+		return "kind-9"
+	case 10:
+		return "kind-10"
+	case 11:
+		return "kind-11"
+	case 12:
+		return "kind-12"
+	case 13:
+		return "kind-13"
+	case 14:
+		return "kind-14"
+	case 15:
+		return "kind-15"
+	case 16:
+		return "kind-16"
+	case 17:
+		return "kind-17"
+	case 18:
+		return "kind-18"
+	case 19:
+		return "kind-19"
+	case 20:
+		return "kind-20"
+	case 21:
+		return "kind-21"
+	case 22:
+		return "kind-22"
+	case 23:
+		return "kind-23"
+	case 24:
+		return "kind-24"
+	case 25:
+		return "kind-25"
+	case 26:
+		return "kind-26"
+	case 27:
+		return "kind-27"
+	case 28:
+		return "kind-28"
+	case 29:
+		return "kind-29"
+	case 30:
+		return "kind-30"
+	case 31:
+		return "kind-31"
+	case 32:
+		return "kind-32"
+	case 33:
+		return "kind-33"
+	case 34:
+		return "kind-34"
+	case 35:
+		return "kind-35"
+	case 36:
+		return "kind-36"
+	case 37:
+		return "kind-37"
+	case 38:
+		return "kind-38"
+	case 39:
+		return "kind-39"
+	case 40:
+		return "kind-40"
+	case 41:
+		return "kind-41"
+	case 42:
+		return "kind-42"
+	case 43:
+		return "kind-43"
+	case 44:
+		return "kind-44"
+	case 45:
+		return "kind-45"
+	case 46:
+		return "kind-46"
+	case 47:
+		return "kind-47"
+	case 48:
+		return "kind-48"
+	case 49:
+		return "kind-49"
+	case 50:
+		return "kind-50"
+	case 51:
+		return "kind-51"
+	case 52:
+		return "kind-52"
+	case 53:
+		return "kind-53"
+	case 54:
+		return "kind-54"
+	case 55:
+		return "kind-55"
+	case 56:
+		return "kind-56"
+	case 57:
+		return "kind-57"
+	case 58:
+		return "kind-58"
+	case 59:
+		return "kind-59"
+	case 60:
+		return "kind-60"
+	case 61:
+		return "kind-61"
+	case 62:
+		return "kind-62"
+	case 63:
+		return "kind-63"
+	case 64:
+		return "kind-64"
+	case 65:
+		return "kind-65"
+	case 66:
+		return "kind-66"
+	case 67:
+		return "kind-67"
+	case 68:
+		return "kind-68"
+	case 69:
+		return "kind-69"
+	case 70:
+		return "kind-70"
+	case 71:
+		return "kind-71"
+	case 72:
+		return "kind-72"
+	case 73:
+		return "kind-73"
+	case 74:
+		return "kind-74"
+	case 75:
+		return "kind-75"
+	case 76:
+		return "kind-76"
+	case 77:
+		return "kind-77"
+	case 78:
+		return "kind-78"
+	case 79:
+		return "kind-79"
+	case 80:
+		return "kind-80"
+	case 81:
+		return "kind-81"
+	case 82:
+		return "kind-82"
+	case 83:
+		return "kind-83"
+	case 84:
+		return "kind-84"
+	case 85:
+		return "kind-85"
+	case 86:
+		return "kind-86"
+	case 87:
+		return "kind-87"
+	case 88:
+		return "kind-88"
+	case 89:
+		return "kind-89"
+	default:
+		return "unknown"
+	}
+}
@@ -0,0 +1,105 @@
+package loopctx
+
+import (
+	"context"
+	"net"
+	"time"
+)
+
+type Job struct{ ID int }
+
+func handle(j Job) {}
+
+func process(ctx context.Context, j Job) error { return ctx.Err() }
+
+// Keeps draining jobs after the caller has given up.
+func Consume(ctx context.Context, jobs <-chan Job) {
+	for j := range jobs { // want loop_ignores_context "Loop never checks its function's context"
+		handle(j)
+	}
+}
+
+// Polls forever; cancelling ctx does not stop it.
+func Poll(ctx context.Context, check func() bool) {
+	for { // want loop_ignores_context "Loop never checks its function's context"
+		if check() {
+			return
+		}
+		time.Sleep(time.Second)
+	}
+}
+
+// Bounded, but every iteration can block on the network.
+func DialAll(ctx context.Context, addrs []string) []net.Conn {
+	conns := make([]net.Conn, 0, len(addrs))
+	for _, addr := range addrs { // want loop_ignores_context "Loop never checks its function's context"
+		conn, err := net.Dial("tcp", addr)
+		if err == nil {
+			conns = append(conns, conn)
+		}
+	}
+	return conns
+}
+
+// The goroutine captures ctx, so its loop is held to the same standard.
+func Tick(ctx context.Context, fn func()) {
+	ticker := time.NewTicker(time.Minute)
+	go func() { // want fire_and_forget_goroutine "Fire-and-forget goroutine"
+		for range ticker.C { // want loop_ignores_context "Loop never checks its function's context"
+			fn()
+		}
+	}()
+}
+
+// The correct worker loop: stops as soon as ctx is done.
+func Worker(ctx context.Context, jobs <-chan Job) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case j, ok := <-jobs:
+			if !ok {
+				return nil
+			}
+			handle(j)
+		}
+	}
+}
+
+// ctx goes to the callee, which can give up.
+func ProcessAll(ctx context.Context, jobs <-chan Job) error {
+	for j := range jobs {
+		if err := process(ctx, j); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// The header checks ctx on every iteration.
+func Retry(ctx context.Context, try func() bool) {
+	for ctx.Err() == nil {
+		if try() {
+			return
+		}
+		time.Sleep(time.Second)
+	}
+}
+
+// A bounded loop that never blocks is fine.
+func Positive(ctx context.Context, values []int) int {
+	n := 0
+	for _, v := range values {
+		if v > 0 {
+			n++
+		}
+	}
+	return n
+}
+
+// No context to check.
+func Forever(jobs <-chan Job) {
+	for j := range jobs {
+		handle(j)
+	}
+}
@@ -0,0 +1,101 @@
+package loops
+
+import "errors"
+
+var errEmpty = errors.New("empty")
+
+func First(names []string) (string, error) {
+	for { // want loop_runs_once "Loop whose body always leaves on its first pass (never repeats)"
+		if len(names) == 0 {
+			break
+		}
+		return names[0], nil
+	}
+	return "", errEmpty
+}
+
+func Index(s string, c byte) int {
+	for i := 0; i < len(s); i++ { // want loop_runs_once "Loop whose body always leaves on its first pass (never repeats)"
+		if s[i] == c {
+			return i
+		}
+		break
+	}
+	return -1
+}
+
+func Sign(n int) int {
+	for n != 0 { // want loop_runs_once "Loop whose body always leaves on its first pass (never repeats)"
+		if n > 0 {
+			break
+		} else {
+			return -1
+		}
+	}
+	if n == 0 {
+		return 0
+	}
+	return 1
+}
+
+func Retry(attempt func() error) error {
+	for {
+		err := attempt()
+		if errors.Is(err, errEmpty) {
+			continue
+		}
+		return err
+	}
+}
+
+func Outer(rows [][]int) int {
+rows:
+	for {
+		for _, row := range rows {
+			if len(row) == 0 {
+				continue rows
+			}
+		}
+		return len(rows)
+	}
+}
+
+func Pick(m map[string]int) string {
+	for k := range m {
+		return k
+	}
+	return ""
+}
+
+func Sum(n int) int {
+	i, total := 0, 0
+	for i < n { // want loop_condition_unchanged "Loop condition over variables the loop never changes (runs zero times or forever)"
+		total = total + n
+	}
+	return total
+}
+
+func Count(n int) int {
+	i := 0
+	for i < n {
+		i++
+	}
+	return i
+}
+
+func Drain(queue []int) int {
+	total := 0
+	for len(queue) > 0 {
+		total = total + queue[0]
+		queue = queue[1:]
+	}
+	return total
+}
+
+func Advance(next func() bool) int {
+	steps := 0
+	for next() {
+		steps++
+	}
+	return steps
+}
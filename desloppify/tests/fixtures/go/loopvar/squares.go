@@ -0,0 +1,16 @@
+package loopvar
+
+import "sync"
+
+// From Go 1.22 every iteration has its own i, so this is correct.
+func Squares(s []int) {
+	var wg sync.WaitGroup
+	for i := range s {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s[i] = i * i
+		}()
+	}
+	wg.Wait()
+}
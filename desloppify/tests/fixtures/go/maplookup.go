@@ -0,0 +1,22 @@
+package main
+
+import "fmt"
+
+func lookupTwice(prices map[string]int, key string) {
+	if _, ok := prices[key]; ok {
+		price := prices[key]
+		fmt.Println(price)
+	}
+}
+
+func lookupOnce(prices map[string]int, key string) {
+	if price, ok := prices[key]; ok {
+		fmt.Println(price)
+	}
+}
+
+func overwriteExisting(prices map[string]int, key string) {
+	if _, ok := prices[key]; ok {
+		prices[key] = 0
+	}
+}
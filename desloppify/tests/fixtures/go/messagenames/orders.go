@@ -0,0 +1,48 @@
+package messagenames
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+)
+
+type Service struct{ dir, name string } // want unused_field "Unexported struct field nothing in the package reads or sets"
+
+func ProcessOrder(id int) error {
+	if id <= 0 {
+		return errors.New("ProcessOrder: invalid id") // want message_function_name "Error or log message prefixed with its function's name by hand (stale on rename)"
+	}
+	if err := save(id); err != nil {
+		return fmt.Errorf("processOrder: save %d: %w", id, err) // want message_function_name "Error or log message prefixed with its function's name by hand (stale on rename)"
+	}
+	return nil
+}
+
+func (s *Service) Ship(id int) error {
+	if err := save(id); err != nil {
+		log.Printf("(*Service).Ship: order %d not saved: %v", id, err) // want message_function_name "Error or log message prefixed with its function's name by hand (stale on rename)"
+		return fmt.Errorf("shipping order %d: %w", id, err)
+	}
+	return nil
+}
+
+func Cancel(id int) error {
+	if id <= 0 {
+		return fmt.Errorf("CancelOrder: invalid id %d", id) // want message_stale_name "Error or log message prefixed with a name nothing in the package has"
+	}
+	return fmt.Errorf("orders.RefundPayment: order %d: %w", id, errRefund) // want message_stale_name "Error or log message prefixed with a name nothing in the package has"
+}
+
+func Open(s *Service) (*os.File, error) {
+	f, err := os.Open(s.dir)
+	if err != nil {
+		return nil, fmt.Errorf("os.Open: %w", err)
+	}
+	return f, nil
+}
+
+func Warn(id int) {
+	log.Printf("warning: order %d is late", id)
+	log.Printf("loadOrder: order %d cached", id)
+}
@@ -0,0 +1,16 @@
+package messagenames
+
+import "errors"
+
+var errRefund = errors.New("refund failed")
+
+func save(id int) error {
+	if id > 1000 {
+		return errors.New("save: id out of range") // want message_function_name "Error or log message prefixed with its function's name by hand (stale on rename)"
+	}
+	return nil
+}
+
+func loadOrder(id int) int {
+	return id * 2
+}
@@ -0,0 +1,16 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	errShutdown = errors.New("shutdown")
+	errDrain    = errors.New("drain")
+)
+
+// The fixture module targets Go 1.21, where several %w verbs are legal.
+func stopWorkers() error {
+	return fmt.Errorf("stop: %w, %w", errShutdown, errDrain)
+}
@@ -0,0 +1,67 @@
+package musts
+
+import (
+	"errors"
+	"html/template"
+	"net/http"
+	"regexp"
+	"strconv"
+)
+
+const (
+	digits = `^\d+$`
+	page   = `<p>{{.}}</p>`
+)
+
+// Package-level initializers fail at startup, which is what Must is for.
+var (
+	idPattern = regexp.MustCompile(digits)
+	pageTmpl  = template.Must(template.New("page").Parse(page))
+)
+
+var routes map[string]*regexp.Regexp
+
+func init() {
+	routes = map[string]*regexp.Regexp{"id": regexp.MustCompile(lookupPattern("id"))}
+}
+
+func lookupPattern(name string) string { return name }
+
+// MustAtoi is strconv.Atoi that panics on error. Bodies of Must* helpers are not checked.
+func MustAtoi(s string) int {
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		panic(err)
+	}
+	return n
+}
+
+// mustPositive panics on a negative n.
+func mustPositive(n int) int {
+	if n < 0 {
+		panic(errors.New("negative"))
+	}
+	return n
+}
+
+// Constant arguments succeed or fail the same way on every call.
+func Version() *regexp.Regexp {
+	return regexp.MustCompile(`^v\d+\.\d+$` + "(-rc)?")
+}
+
+func Banner() *template.Template {
+	return template.Must(template.New("banner").Parse(page))
+}
+
+func Search(w http.ResponseWriter, r *http.Request) {
+	re := regexp.MustCompile(r.URL.Query().Get("q")) // want must_call_in_function "Must* call with non-constant arguments"
+	limit := MustAtoi(r.FormValue("limit")) // want must_call_in_function "Must* call with non-constant arguments"
+	_ = mustPositive(limit)                  // want must_call_in_function "Must* call with non-constant arguments"
+	_, _ = re, idPattern
+	_ = pageTmpl.Execute(w, nil)
+}
+
+func Render(w http.ResponseWriter, body string) {
+	t := template.Must(template.New("body").Parse(body)) // want must_call_in_function "Must* call with non-constant arguments"
+	_ = t.Execute(w, nil)
+}
@@ -0,0 +1,147 @@
+package mutexes
+
+import (
+	"errors"
+	"sync"
+)
+
+var errMissing = errors.New("missing")
+
+type Counter struct {
+	mu    sync.Mutex
+	rw    sync.RWMutex
+	tx    sync.Mutex
+	n     int
+	cache map[string]int
+}
+
+// Never released: the next caller deadlocks.
+func (c *Counter) Inc() {
+	c.mu.Lock() // want resource_not_released "Resource with a path that never releases it" mutex_unlock_missing "Mutex locked with a path that never unlocks it"
+	c.n++
+}
+
+// The early return leaves the read lock held.
+func (c *Counter) Get(key string) (int, error) {
+	c.rw.RLock() // want resource_not_released "Resource with a path that never releases it" mutex_unlock_missing "Mutex locked with a path that never unlocks it"
+	v, ok := c.cache[key]
+	if !ok {
+		return 0, errMissing
+	}
+	c.rw.RUnlock()
+	return v, nil
+}
+
+// Unlock instead of RUnlock does not release a read lock.
+func (c *Counter) Peek(key string) int {
+	c.rw.RLock() // want resource_not_released "Resource with a path that never releases it" mutex_unlock_missing "Mutex locked with a path that never unlocks it"
+	v := c.cache[key]
+	c.rw.Unlock()
+	return v
+}
+
+// continue skips the unlock and the next iteration locks again.
+func (c *Counter) AddAll(keys []string) {
+	for _, k := range keys {
+		c.mu.Lock() // want resource_not_released "Resource with a path that never releases it" mutex_unlock_missing "Mutex locked with a path that never unlocks it"
+		if k == "" {
+			continue
+		}
+		c.cache[k]++
+		c.mu.Unlock()
+	}
+}
+
+func (c *Counter) Deferred() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.n
+}
+
+func (c *Counter) Balanced(key string) (int, error) {
+	c.rw.RLock()
+	v, ok := c.cache[key]
+	if !ok {
+		c.rw.RUnlock()
+		return 0, errMissing
+	}
+	c.rw.RUnlock()
+	return v, nil
+}
+
+func (c *Counter) Branches(reset bool) {
+	c.mu.Lock()
+	if reset {
+		c.n = 0
+		c.mu.Unlock()
+	} else {
+		c.n++
+		c.mu.Unlock()
+	}
+}
+
+func (c *Counter) Drain(keys []string) {
+	for _, k := range keys {
+		c.mu.Lock()
+		switch k {
+		case "":
+			c.mu.Unlock()
+			continue
+		default:
+			delete(c.cache, k)
+		}
+		c.mu.Unlock()
+	}
+}
+
+func (c *Counter) Closure() func() {
+	c.mu.Lock()
+	defer func() {
+		c.n++
+		c.mu.Unlock()
+	}()
+	return func() {}
+}
+
+// Begin and End hand the lock across calls; Begin is not a leak.
+func (c *Counter) Begin() {
+	c.tx.Lock()
+	c.n++
+}
+
+func (c *Counter) End() {
+	c.n--
+	c.tx.Unlock()
+}
+
+func withLock(mu *sync.Mutex, fn func()) {
+	fn()
+	mu.Unlock()
+}
+
+func (c *Counter) Handoff() {
+	var mu sync.Mutex
+	mu.Lock()
+	withLock(&mu, func() { c.n++ })
+}
+
+func (c *Counter) lockAll() {
+	c.mu.Lock()
+	c.rw.Lock()
+}
+
+// Named for what it adds, not for locking: the empty key returns still locked.
+func (c *Counter) AddBlock(key string) {
+	c.mu.Lock() // want resource_not_released "Resource with a path that never releases it" mutex_unlock_missing "Mutex locked with a path that never unlocks it"
+	if key == "" {
+		return
+	}
+	c.cache[key]++
+	c.mu.Unlock()
+}
+
+// Returns with c.rw held; the caller unlocks it.
+func (c *Counter) resetLocked() {
+	c.rw.Lock()
+	c.n = 0
+}
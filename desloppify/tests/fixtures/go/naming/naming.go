@@ -0,0 +1,166 @@
+package naming
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+type Report struct {
+	Lines []string
+	Total int
+}
+
+// Render reads s a screenful after declaring it.
+func Render(rows []string) string {
+	s := &strings.Builder{} // want name_length "Short name used far from its declaration, or overlong name"
+	s.WriteString("report\n")
+	for _, row := range rows {
+		if row == "" {
+			continue
+		}
+		fields := strings.Fields(row)
+		if len(fields) < 2 {
+			continue
+		}
+		fmt.Fprintf(s, "%s=%s\n", fields[0], fields[1])
+	}
+	total := len(rows)
+	if total > 100 {
+		total = 100
+	}
+	footer := fmt.Sprintf("total %d", total)
+	footer = strings.ToUpper(footer)
+	if strings.HasPrefix(footer, "TOTAL 0") {
+		footer = "EMPTY"
+	}
+	header := strings.Repeat("-", len(footer))
+	header = strings.TrimSpace(header)
+	if header == "" {
+		header = "-"
+	}
+	s.WriteString(header)
+	s.WriteString("\n")
+	s.WriteString(footer)
+	return s.String()
+}
+
+// Count uses short names only where they stay close.
+func Count(rows []string) int {
+	n := 0
+	for i := range rows {
+		if rows[i] != "" {
+			n++
+		}
+	}
+	return n
+}
+
+// Sum keeps i and ok idiomatic even across a long body.
+func (r *Report) Sum(values map[string]int) int {
+	for i := 0; i < len(r.Lines); i++ {
+		line := r.Lines[i]
+		v, ok := values[line]
+		if !ok {
+			continue
+		}
+		r.Total += v
+		if r.Total > 1000 {
+			r.Total = 1000
+		}
+		switch {
+		case strings.HasPrefix(line, "a"):
+			r.Total++
+		case strings.HasPrefix(line, "b"):
+			r.Total--
+		case strings.HasPrefix(line, "c"):
+			r.Total += 2
+		case strings.HasPrefix(line, "d"):
+			r.Total -= 2
+		}
+		if strings.HasSuffix(line, "!") {
+			r.Total *= 2
+		}
+		if i > 0 && ok {
+			r.Total++
+		}
+	}
+	return r.Total
+}
+
+// Serve writes to w and reads r the way every handler does.
+func Serve(w http.ResponseWriter, r *http.Request) {
+	name := r.URL.Query().Get("name")
+	if name == "" {
+		name = "world"
+	}
+	greeting := "hello"
+	if r.Method == http.MethodHead {
+		greeting = ""
+	}
+	switch r.URL.Query().Get("lang") {
+	case "fr":
+		greeting = "bonjour"
+	case "de":
+		greeting = "hallo"
+	case "es":
+		greeting = "hola"
+	case "it":
+		greeting = "ciao"
+	}
+	if strings.HasPrefix(name, "x") {
+		name = strings.TrimPrefix(name, "x")
+	}
+	if len(name) > 20 {
+		name = name[:20]
+	}
+	greeting = strings.TrimSpace(greeting)
+	if greeting == "" {
+		return
+	}
+	fmt.Fprintf(w, "%s, %s from %s\n", greeting, name, r.URL.Path)
+}
+
+// Scale takes a parameter whose name reaches across the whole body.
+func Scale(v []int, factor int) []int { // want name_length "Short name used far from its declaration, or overlong name"
+	out := make([]int, 0, len(v))
+	for _, item := range v {
+		scaled := item * factor
+		if scaled > 100 {
+			scaled = 100
+		}
+		if scaled < -100 {
+			scaled = -100
+		}
+		out = append(out, scaled)
+	}
+	if factor == 0 {
+		return out
+	}
+	if factor > 10 {
+		out = append(out, factor)
+	}
+	if factor < -10 {
+		out = append(out, -factor)
+	}
+	if len(out) > 100 {
+		out = out[:100]
+	}
+	if len(out) == 0 {
+		out = nil
+	}
+	if len(out) > 1 && out[0] > out[1] {
+		out[0], out[1] = out[1], out[0]
+	}
+	return append(out, len(v))
+}
+
+func numberOfRowsThatWereSkippedBecauseTheyWereEmpty(rows []string) int { // want name_length "Short name used far from its declaration, or overlong name"
+	countOfRowsThatWereSkippedBecauseTheyAreEmpty := 0 // want name_length "Short name used far from its declaration, or overlong name"
+	for _, row := range rows {
+		if row == "" {
+			countOfRowsThatWereSkippedBecauseTheyAreEmpty++
+		}
+	}
+	return countOfRowsThatWereSkippedBecauseTheyAreEmpty
+}
@@ -0,0 +1,92 @@
+package nilchecks
+
+import "errors"
+
+type Names []string
+
+var registry map[string]int
+
+func HasItems(s []int) bool {
+	return s != nil && len(s) > 0 // want redundant_nil_check "Nil check that len() or range already makes (drop it)"
+}
+
+func Empty(m map[string]int) bool {
+	return m == nil || len(m) == 0 // want redundant_nil_check "Nil check that len() or range already makes (drop it)"
+}
+
+func NamedType(n Names) bool {
+	return n != nil && len(n) != 0 // want redundant_nil_check "Nil check that len() or range already makes (drop it)"
+}
+
+func PackageLevel() bool {
+	return registry != nil && len(registry) > 0 // want redundant_nil_check "Nil check that len() or range already makes (drop it)"
+}
+
+func Count() int {
+	n := 0
+	if registry != nil { // want redundant_nil_check "Nil check that len() or range already makes (drop it)"
+		for range registry {
+			n++
+		}
+	}
+	return n
+}
+
+func PointerToArray(p *[4]int) bool {
+	return p != nil && len(p) > 0
+}
+
+func Unknown(v interface{ Len() int }, s []int) bool {
+	return v != nil && v.Len() > 0 || len(s) > 0
+}
+
+func Drain(ch chan int) int {
+	n := 0
+	if ch != nil {
+		for range ch {
+			n++
+		}
+	}
+	return n
+}
+
+func check(ok bool) error {
+	if !ok {
+		return errors.New("not ok")
+	}
+	return nil
+}
+
+func Forward(ok bool) error {
+	err := check(ok)
+	if err != nil { // want redundant_error_check "if err != nil { return err } followed by return nil (return err)"
+		return err
+	}
+	return nil
+}
+
+func ForwardElse(ok bool) (int, error) {
+	err := check(ok)
+	if err != nil { // want redundant_error_check "if err != nil { return err } followed by return nil (return err)"
+		return 0, err
+	} else { // want else_after_return "else after an if block that ends in return (outdent the else body)"
+		return 0, nil
+	}
+}
+
+func Wrapped(ok bool) error {
+	err := check(ok)
+	if err != nil {
+		return errors.Join(err, errors.New("wrapped"))
+	}
+	return nil
+}
+
+func Explained(ok bool) error {
+	err := check(ok)
+	if err != nil {
+		// Callers compare against this error directly.
+		return err
+	}
+	return nil
+}
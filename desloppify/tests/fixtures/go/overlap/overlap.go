@@ -0,0 +1,52 @@
+package overlap
+
+import "fmt"
+
+// rest shares items' array, so printing items shows the shifted tail.
+func Without(items []string, i int) []string {
+	rest := append(items[:i], items[i+1:]...) // want slice_overlap_append "append through a reslice overwrites the slice it came from"
+	fmt.Println("before:", items)
+	return rest
+}
+
+// Deleting in place is the idiom.
+func Delete(items []string, i int) []string {
+	items = append(items[:i], items[i+1:]...)
+	return items
+}
+
+func Remove(items []string, i int) []string {
+	return append(items[:i], items[i+1:]...)
+}
+
+// Different arrays: nothing is overwritten.
+func Join(head, tail []string) []string {
+	all := append(head[:len(head):len(head)], tail[1:]...)
+	return all
+}
+
+// items is assigned before it is read again.
+func Shift(items []string) []string {
+	first := append(items[:0], items[1:]...)
+	items = nil
+	return append(first, items...)
+}
+
+func Header(src []byte) []byte {
+	copy(make([]byte, 4), src) // want copy_length_ignored "copy into a fixed-length slice with its count ignored"
+	buf := make([]byte, 16)
+	copy(buf, src) // want copy_length_ignored "copy into a fixed-length slice with its count ignored"
+	return buf
+}
+
+func Clone(src []byte) ([]byte, error) {
+	dst := make([]byte, len(src))
+	copy(dst, src)
+	head := make([]byte, 4)
+	if n := copy(head, src); n < len(src) {
+		return nil, fmt.Errorf("header too long: %d bytes", len(src))
+	}
+	prefix := make([]byte, 4)
+	copy(prefix, src[:4])
+	return append(dst, prefix...), nil
+}
@@ -0,0 +1,66 @@
+package panicreach
+
+import (
+	"errors"
+	"regexp"
+)
+
+var errEmpty = errors.New("empty input")
+
+// Parse is the package API; its helper's panic is reachable through it.
+func Parse(s string) int {
+	return parseDigits(s)
+}
+
+func parseDigits(s string) int {
+	if s == "" {
+		panic(errEmpty) // want panic_in_lib "panic() in library code reachable from the exported API"
+	}
+	return len(s)
+}
+
+// assertSorted guards an internal invariant; nothing exported calls it.
+func assertSorted(xs []int) {
+	for i := 1; i < len(xs); i++ {
+		if xs[i-1] > xs[i] {
+			panic(errors.New("unsorted")) // want panic_in_lib_helper "panic() in library code only reachable from unexported helpers"
+		}
+	}
+}
+
+func rebuildIndex(xs []int) {
+	assertSorted(xs)
+}
+
+// MustPattern is like Compile but panics if the pattern does not compile.
+func MustPattern(expr string) *regexp.Regexp {
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		panic(err)
+	}
+	return re
+}
+
+// Package-level initializers run on import, so their panics count as exported.
+var defaultPattern = mustQuiet(`^\d+$`)
+
+func mustQuiet(expr string) *regexp.Regexp {
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		panic(err) // want panic_in_lib "panic() in library code reachable from the exported API"
+	}
+	return re
+}
+
+// A method value handed out as a callback is reachable as well.
+type Walker struct{ visit func(int) }
+
+func NewWalker() *Walker {
+	return &Walker{visit: visitNode}
+}
+
+func visitNode(n int) {
+	if n < 0 {
+		panic(errors.New("negative node")) // want panic_in_lib "panic() in library code reachable from the exported API"
+	}
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+func mustPositive(n int) int {
+	if n < 0 {
+		panic("negative input")
+	}
+	return n
+}
+
+func mustParse(s string) int {
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		panic(fmt.Sprintf("bad number %q", s))
+	}
+	return v
+}
+
+func mustAtoi(s string) int {
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+func mustNonEmpty(s string) string {
+	if s == "" {
+		panic(fmt.Errorf("empty value"))
+	}
+	if s == "-" {
+		panic(errors.New("dash is not a value"))
+	}
+	return s
+}
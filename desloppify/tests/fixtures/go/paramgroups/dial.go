@@ -0,0 +1,33 @@
+package paramgroups
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"time"
+)
+
+// Dial, Ping and Probe (in probe.go) all take host, port and timeout.
+func Dial(ctx context.Context, host string, port int, timeout time.Duration) (net.Conn, error) { // want shared_param_group "share a parameter group"
+	d := net.Dialer{Timeout: timeout}
+	return d.DialContext(ctx, "tcp", net.JoinHostPort(host, strconv.Itoa(port)))
+}
+
+func Ping(host string, port int, retries int, timeout time.Duration) error {
+	for i := 0; i < retries; i++ {
+		conn, err := Dial(context.Background(), host, port, timeout)
+		if err == nil {
+			return conn.Close()
+		}
+	}
+	return nil
+}
+
+// Only two functions share user and password, which is not a group yet.
+func Login(user, password string, realm string) bool {
+	return user != "" && password != "" && realm != ""
+}
+
+func Register(user, password string, realm string, email string) bool {
+	return Login(user, password, realm) && email != ""
+}
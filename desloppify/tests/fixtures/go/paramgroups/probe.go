@@ -0,0 +1,10 @@
+package paramgroups
+
+import "time"
+
+type Prober struct{ attempts int }
+
+func (p *Prober) Probe(host string, port int, timeout time.Duration, verbose bool) bool {
+	p.attempts++
+	return Ping(host, port, 1, timeout) == nil || verbose
+}
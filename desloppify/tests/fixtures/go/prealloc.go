@@ -0,0 +1,29 @@
+package main
+
+func collectNames(users []string) []string {
+	var names []string
+	for _, u := range users {
+		names = append(names, u)
+	}
+	return names
+}
+
+func collectPrealloc(users []string) []string {
+	names := make([]string, 0, len(users))
+	for _, u := range users {
+		names = append(names, u)
+	}
+	return names
+}
+
+func drainQueue(next func() (string, bool)) []string {
+	items := []string{}
+	for {
+		item, ok := next()
+		if !ok {
+			break
+		}
+		items = append(items, item)
+	}
+	return items
+}
@@ -0,0 +1,46 @@
+package printf
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+var errClosed = errors.New("closed")
+
+func TooFew(w io.Writer, name string) {
+	fmt.Fprintf(w, "%s has %d items\n", name) // want printf_mismatch "Printf-style format that does not match its arguments"
+}
+
+func TooMany(id int) string {
+	return fmt.Sprintf("user %d", id, "extra") // want printf_mismatch "Printf-style format that does not match its arguments"
+}
+
+func WrongType(port string, count int) {
+	fmt.Printf("listening on :%d\n", port) // want printf_mismatch "Printf-style format that does not match its arguments"
+	label := "items"
+	fmt.Printf("%d %d\n", count, label) // want printf_mismatch "Printf-style format that does not match its arguments"
+	fmt.Printf("%s\n", strconv.Itoa(count)+"!")
+	fmt.Printf("%s\n", 42) // want printf_mismatch "Printf-style format that does not match its arguments"
+}
+
+func BadIndex(a, b int) string {
+	return fmt.Sprintf("%[3]d", a, b) // want printf_mismatch "Printf-style format that does not match its arguments"
+}
+
+func WrapOutsideErrorf(err error) string {
+	return fmt.Sprintf("failed: %w", err) // want printf_mismatch "Printf-style format that does not match its arguments"
+}
+
+// Everything below is correct.
+func Fine(w io.Writer, name string, n int, ratio float64, args ...any) error {
+	fmt.Printf("%s has %d items (%.1f%%)\n", name, n, ratio)
+	fmt.Fprintf(w, "%-*s|\n", 10, name)
+	fmt.Printf("%[1]d %[1]x %v\n", n, strconv.Itoa(n))
+	fmt.Printf("%d entries\n", len(name))
+	fmt.Printf("%q %c %x\n", name, 'a', name)
+	fmt.Printf("%s: %v\n", name, args...)
+	fmt.Printf(name+"\n", n)
+	return fmt.Errorf("open %s: %w", name, errClosed)
+}
@@ -0,0 +1,82 @@
+package redundantcontinue
+
+import "strings"
+
+func Count(lines []string) int {
+	n := 0
+	for _, line := range lines {
+		if line == "" {
+			continue
+		}
+		n++
+		continue // want redundant_continue "continue as the last statement of a loop body (does nothing)"
+	}
+	return n
+}
+
+func Trim(lines []string) []string {
+	out := make([]string, 0, len(lines))
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "#") {
+			continue // want redundant_continue "continue as the last statement of a loop body (does nothing)"
+		} else {
+			out = append(out, line)
+			continue // want redundant_continue "continue as the last statement of a loop body (does nothing)"
+		}
+	}
+	return out
+}
+
+func Kinds(words []string) (short, long int) {
+	for _, w := range words {
+		switch {
+		case len(w) < 4:
+			short++
+			continue // want redundant_continue "continue as the last statement of a loop body (does nothing)"
+		default:
+			long++
+		}
+	}
+	return short, long
+}
+
+func Skip(words []string) []string {
+	var kept []string
+	for _, w := range words {
+		if w == "" {
+			continue
+		}
+		kept = append(kept, w) // want append_no_prealloc "append in range loop without preallocation (use make with capacity)"
+	}
+	return kept
+}
+
+func Nested(grid [][]int) int {
+	total := 0
+	for _, row := range grid {
+		for _, v := range row {
+			if v < 0 {
+				continue
+			}
+			total = total + v
+		}
+		if len(row) == 0 {
+			continue
+		}
+		total++
+	}
+	return total
+}
+
+func First(words []string) string {
+	for _, w := range words {
+		switch {
+		case w == "":
+			continue
+		default:
+			return w
+		}
+	}
+	return ""
+}
@@ -0,0 +1,180 @@
+package resources
+
+import (
+	"database/sql"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+)
+
+var errEmpty = errors.New("empty")
+
+const (
+	debit  = "UPDATE accounts SET n = n - 1 WHERE id = ?"
+	credit = "UPDATE accounts SET n = n + 1 WHERE id = ?"
+)
+
+// The empty-file path returns before the Close.
+func ReadConfig(path string) ([]byte, error) {
+	f, err := os.Open(path) // want resource_not_released "Resource with a path that never releases it"
+	if err != nil {
+		return nil, err
+	}
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return nil, err
+	}
+	f.Close()
+	if len(data) == 0 {
+		return nil, errEmpty
+	}
+	return data, nil
+}
+
+func ReadConfigDeferred(path string) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return io.ReadAll(f)
+}
+
+// The caller owns the file.
+func OpenLog(path string) (*os.File, error) {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func Status(url string) (int, error) {
+	resp, err := http.Get(url) // want resource_not_released "Resource with a path that never releases it"
+	if err != nil {
+		return 0, err
+	}
+	if resp.StatusCode >= 500 {
+		return resp.StatusCode, errEmpty
+	}
+	resp.Body.Close()
+	return resp.StatusCode, nil
+}
+
+// The first failed Exec returns without a Rollback.
+func Transfer(db *sql.DB, from, to int) error {
+	tx, err := db.Begin() // want resource_not_released "Resource with a path that never releases it"
+	if err != nil {
+		return err
+	}
+	if _, err := tx.Exec(debit, from); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(credit, to); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// A continue skips the Close for that file.
+func Sizes(paths []string) []int64 {
+	sizes := make([]int64, 0, len(paths))
+	for _, p := range paths {
+		f, err := os.Open(p) // want resource_not_released "Resource with a path that never releases it"
+		if err != nil {
+			continue
+		}
+		info, err := f.Stat()
+		if err != nil {
+			continue
+		}
+		sizes = append(sizes, info.Size())
+		f.Close()
+	}
+	return sizes
+}
+
+// Breaking out of the labeled loop still reaches the Close.
+func FirstLine(path string, stops []byte) (int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	buf := make([]byte, 512)
+	n := 0
+scan:
+	for {
+		read, err := f.Read(buf)
+		for _, b := range buf[:read] {
+			for _, stop := range stops {
+				if b == stop {
+					break scan
+				}
+			}
+			n++
+		}
+		if err != nil {
+			break
+		}
+	}
+	f.Close()
+	return n, nil
+}
+
+// Committing is what releases the transaction on the last path.
+func Rename(db *sql.DB, from, to string) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	if _, err := tx.Exec(debit, from); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// Breaking out of the outer loop skips the Close of the file just opened.
+func CountGroups(groups [][]string) int {
+	n := 0
+outer:
+	for _, group := range groups {
+		f, err := os.Open(group[0]) // want resource_not_released "Resource with a path that never releases it"
+		if err != nil {
+			continue
+		}
+		for _, name := range group[1:] {
+			if name == "" {
+				break outer
+			}
+		}
+		n++
+		f.Close()
+	}
+	return n
+}
+
+type cache struct {
+	mu    sync.Mutex
+	items map[string][]byte
+}
+
+// The miss returns with the cache still locked.
+func (c *cache) Get(key string) ([]byte, bool) {
+	c.mu.Lock() // want resource_not_released "Resource with a path that never releases it" mutex_unlock_missing "Mutex locked"
+	item, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+	c.mu.Unlock()
+	return item, true
+}
+
+func (c *cache) Put(key string, item []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.items[key] = item
+}
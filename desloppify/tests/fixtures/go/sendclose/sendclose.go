@@ -0,0 +1,81 @@
+package sendclose
+
+import "errors"
+
+var errStopped = errors.New("stopped")
+
+// The final value is sent after the close: a guaranteed panic.
+func Produce(out chan<- int, values []int) {
+	for _, v := range values {
+		out <- v
+	}
+	close(out)
+	out <- -1 // want send_after_close "Send on a channel this function may already have closed"
+}
+
+// Closing inside the loop lets the next iteration send on the closed channel.
+func Relay(in <-chan int, out chan<- int) {
+	for v := range in {
+		out <- v // want send_after_close "Send on a channel this function may already have closed"
+		if v < 0 {
+			close(out)
+		}
+	}
+}
+
+// Close at the end, after every send.
+func Fill(out chan<- int, n int) {
+	for i := 0; i < n; i++ {
+		out <- i
+	}
+	close(out)
+}
+
+// The branch that closes returns; the send is on the other path.
+func Forward(in <-chan int, out chan<- int) error {
+	for {
+		v, ok := <-in
+		if !ok {
+			close(out)
+			return errStopped
+		}
+		out <- v
+	}
+}
+
+// break leaves the loop, so the send in its body is not reached again.
+func Until(in <-chan int, out chan<- int, stop int) {
+	for v := range in {
+		if v == stop {
+			close(out)
+			break
+		}
+		out <- v
+	}
+}
+
+// An else branch is not reachable from the if that closed.
+func Either(out chan<- int, done bool) {
+	if done {
+		close(out)
+	} else {
+		out <- 1
+	}
+}
+
+// defer close runs after every send.
+func Deferred(out chan<- int) {
+	defer close(out)
+	out <- 1
+	out <- 2
+}
+
+// Receiving from a closed channel is fine.
+func Drain(ch chan int) int {
+	close(ch)
+	n := 0
+	for range ch {
+		n++
+	}
+	return n + <-ch
+}
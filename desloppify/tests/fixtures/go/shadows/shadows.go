@@ -0,0 +1,54 @@
+package shadows
+
+import (
+	"errors"
+	"strconv"
+)
+
+type Range struct {
+	Lo, Hi int
+}
+
+// Width returns how many values the range holds.
+func Width(r Range) int {
+	len := 5 // want builtin_shadow "Declaration shadows a builtin identifier"
+	if r.Hi > r.Lo {
+		len = r.Hi - r.Lo
+	}
+	return len
+}
+
+// Label names the range.
+func Label(r Range) string {
+	string := "x" // want builtin_shadow "Declaration shadows a builtin identifier"
+	if r.Lo < 0 {
+		string = "negative"
+	}
+	return string + strconv.Itoa(r.Lo)
+}
+
+// Parse reads a range bound and keeps err as the error's name.
+func Parse(s string) (int, error) {
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, errors.New("bad bound")
+	}
+	return n, nil
+}
+
+// Clamp takes a parameter named after a builtin.
+func Clamp(v, cap int) int { // want builtin_shadow "Declaration shadows a builtin identifier"
+	if v > cap {
+		return cap
+	}
+	return v
+}
+
+const (
+	minWidth = 1
+	true     = 0 // want builtin_shadow "Declaration shadows a builtin identifier"
+)
+
+type any struct { // want builtin_shadow "Declaration shadows a builtin identifier"
+	msg string // want unused_field "Unexported struct field nothing in the package reads or sets"
+}
@@ -0,0 +1,65 @@
+package smallptr
+
+import "sync"
+
+type Point struct{ X, Y int }
+
+type Options struct {
+	Retries *int // want pointer_to_small_type "Pointer to a small value type (pass or store the value)"
+	Verbose *bool `json:"verbose,omitempty"`
+	// Timeout is optional: nil means the default.
+	Timeout *int
+	Limit   *int
+	Origin  *Point
+	mu      *sync.Mutex
+}
+
+func double(n *int) int { // want pointer_to_small_type "Pointer to a small value type (pass or store the value)"
+	return *n * 2
+}
+
+func norm(p *Point) int { // want pointer_to_small_type "Pointer to a small value type (pass or store the value)"
+	return p.X*p.X + p.Y*p.Y
+}
+
+func checksum(buf *[1024]byte) int {
+	sum := 0
+	for _, b := range buf {
+		sum = sum*31 + int(b)
+	}
+	return sum
+}
+
+func poll(done *bool, work func()) {
+	for !*done {
+		work()
+	}
+}
+
+func increment(n *int) {
+	*n++
+}
+
+func reset(p *Point) {
+	p.X, p.Y = 0, 0
+}
+
+func orDefault(n *int, fallback int) int {
+	if n == nil {
+		return fallback
+	}
+	return *n
+}
+
+func lock(o *Options) {
+	o.mu.Lock()
+}
+
+func limit(o *Options) int {
+	if o.Limit == nil {
+		return 0
+	}
+	return *o.Limit + *o.Retries + orDefault(o.Timeout, 1) + norm(o.Origin)
+}
+
+var _ = []any{double, checksum, poll, increment, reset, lock, limit}
@@ -5,40 +5,40 @@ import "fmt"
 // Nil map write
 func nilMapWrite() {
 	var m map[string]int
-	m["key"] = 1
+	m["key"] = 1 // want nil_map_write "Potential write to nil map (runtime panic)"
 }
 
 // String concat in loop
 func stringConcatLoop(items []string) string {
 	var result string
 	for _, s := range items {
-		result += s + ","
+		result += s + "," // want string_concat_loop "String concatenation in loop (O(n²) allocations)"
 	}
 	return result
 }
 
 // Yoda condition
 func yodaCheck(x int) bool {
-	if 42 == x {
+	if 42 == x { // want bool_literal_return "if/else returning true/false literals (return the condition)" yoda_condition "Yoda condition (constant on left side of ==)"
 		return true
 	}
 	return false
 }
 
-// TODO comment
+// TODO comment // want todo_fixme "TODO/FIXME/HACK comments"
 func incomplete() {
-	// TODO: implement this properly
+	// TODO: implement this properly // want todo_fixme "TODO/FIXME/HACK comments"
 	fmt.Println("placeholder")
 }
 
 // Panic in library code (this file is package main, so panic won't be flagged)
 func panicInMain() {
-	panic("something went wrong")
+	panic("something went wrong") // want panic_string "panic() with a string instead of an error value"
 }
 
 // Dogsledding — excessive blank identifiers
 func dogsledding() {
-	_, _, _, x := multiReturn()
+	_, _, _, x := multiReturn() // want dogsledding "Excessive blank identifiers (3+ underscores on LHS)"
 	fmt.Println(x)
 }
 
@@ -47,6 +47,6 @@ func multiReturn() (int, int, int, int) {
 }
 
 // Too many parameters
-func tooManyParams(a int, b int, c string, d bool, e float64, f int) {
+func tooManyParams(a int, b int, c string, d bool, e float64, f int) { // want too_many_params "Too many function parameters (>5)"
 	fmt.Println(a, b, c, d, e, f)
 }
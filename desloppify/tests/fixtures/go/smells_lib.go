@@ -4,7 +4,7 @@ import "fmt"
 
 // Panic in library code (non-main package)
 func PanicInLib() {
-	panic("library panic")
+	panic("library panic") // want panic_in_lib "panic() in library code reachable from the exported API" panic_string "panic() with a string instead of an error value"
 }
 
 func SafeFunc() {
@@ -0,0 +1,15 @@
+package main
+
+import "fmt"
+
+func formatCount(n int) string {
+	return fmt.Sprintf("%d", n)
+}
+
+func formatHex(n int) string {
+	return fmt.Sprintf("%x", n)
+}
+
+func formatUser(n int) string {
+	return fmt.Sprintf("user %d", n)
+}
@@ -0,0 +1,14 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+)
+
+func formatFlag(enabled bool) string {
+	return fmt.Sprintf("%t", enabled)
+}
+
+func formatID(id int) string {
+	return strconv.Itoa(id)
+}
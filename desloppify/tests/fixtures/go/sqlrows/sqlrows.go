@@ -0,0 +1,82 @@
+package sqlrows
+
+import (
+	"context"
+	"database/sql"
+)
+
+const listUsers = "SELECT name FROM users"
+
+// The contract kept: deferred Close, Err after the loop.
+func Names(ctx context.Context, db *sql.DB) ([]string, error) {
+	rows, err := db.QueryContext(ctx, listUsers)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+	return names, rows.Err()
+}
+
+func Count(db *sql.DB) (int, error) {
+	rows, err := db.Query(listUsers) // want sql_rows_misuse "Query rows not closed, not checked with Err, or used after Close"
+	if err != nil {
+		return 0, err
+	}
+	n := 0
+	for rows.Next() { // want sql_rows_misuse "Query rows not closed, not checked with Err, or used after Close"
+		n++
+	}
+	return n, nil
+}
+
+func First(db *sql.DB) (string, error) {
+	rows, err := db.Query(listUsers)
+	if err != nil {
+		return "", err
+	}
+	rows.Close()
+	var name string
+	if rows.Next() { // want sql_rows_misuse "Query rows not closed, not checked with Err, or used after Close"
+		err = rows.Scan(&name)
+	}
+	return name, err
+}
+
+// scanNames owns the rows it is given.
+func AllNames(db *sql.DB) ([]string, error) {
+	rows, err := db.Query(listUsers)
+	if err != nil {
+		return nil, err
+	}
+	return scanNames(rows)
+}
+
+func scanNames(rows *sql.Rows) ([]string, error) {
+	defer rows.Close()
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+	return names, rows.Err()
+}
+
+// A parameter is the caller's to close, but its Err is still this loop's to check.
+func countRows(rows *sql.Rows) int {
+	n := 0
+	for rows.Next() { // want sql_rows_misuse "Query rows not closed, not checked with Err, or used after Close"
+		n++
+	}
+	return n
+}
@@ -0,0 +1,49 @@
+package structsprintf
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+)
+
+var apiBase = "https://api.example.com"
+
+var defaultQuery = "golang"
+
+func ConfigPath(dir, name string) string {
+	return fmt.Sprintf("%s/%s.yaml", dir, name) // want sprintf_path "fmt.Sprintf joining path segments (use filepath.Join)"
+}
+
+func Label(kind, name string) string {
+	return fmt.Sprintf("%s/%s", kind, name)
+}
+
+func SearchURL(query string) string {
+	term := query
+	return fmt.Sprintf("%s/search?q=%s", apiBase, term) // want sprintf_url_param "Parameter formatted into a URL query unescaped (use url.Values)"
+}
+
+func DefaultSearchURL() string {
+	return fmt.Sprintf("%s/search?q=%s", apiBase, defaultQuery) // want sprintf_url "Query value formatted into a URL unescaped (use url.Values)"
+}
+
+func PageURL(query string, page int) string {
+	return fmt.Sprintf("%s/search?q=%s&page=%d", apiBase, url.QueryEscape(query), page)
+}
+
+func Timeout(seconds int) (time.Duration, error) {
+	return time.ParseDuration(fmt.Sprintf("%ds", seconds)) // want sprintf_duration "Duration built with fmt.Sprintf and parsed back (use time.Duration arithmetic)"
+}
+
+func Interval(ms float64) time.Duration {
+	text := fmt.Sprintf("%.1fms", ms) // want sprintf_duration "Duration built with fmt.Sprintf and parsed back (use time.Duration arithmetic)"
+	d, err := time.ParseDuration(text)
+	if err != nil {
+		return 0
+	}
+	return d
+}
+
+func Describe(seconds int) string {
+	return fmt.Sprintf("%ds", seconds)
+}
@@ -0,0 +1,17 @@
+package main
+
+import "fmt"
+
+func suppressedSprintf(n int) string {
+	//desloppify:ignore sprintf_strconv
+	return fmt.Sprintf("%d", n)
+}
+
+func suppressedTrailing(n int) string {
+	return fmt.Sprintf("%d", n) //desloppify:ignore
+}
+
+func otherRuleIgnored(n int) string {
+	//desloppify:ignore todo_fixme
+	return fmt.Sprintf("%d", n)
+}
@@ -0,0 +1,29 @@
+package timelayout
+
+import "time"
+
+const stampLayout = "yyyy-MM-dd HH:mm:ss"
+
+func ParseDate(s string) (time.Time, error) {
+	return time.Parse("YYYY-MM-DD", s) // want time_layout "Time layout in another notation (YYYY-MM-DD); Go layouts use 2006-01-02"
+}
+
+func ParseISODate(s string) (time.Time, error) {
+	return time.Parse("2006-01-02", s)
+}
+
+func ParseStamp(s string, loc *time.Location) (time.Time, error) {
+	return time.ParseInLocation(stampLayout, s, loc) // want time_layout "Time layout in another notation (YYYY-MM-DD); Go layouts use 2006-01-02"
+}
+
+func Stamp(t time.Time) string {
+	return t.Format("%Y/%m/%d") // want time_layout "Time layout in another notation (YYYY-MM-DD); Go layouts use 2006-01-02"
+}
+
+func Clock(t time.Time) string {
+	return t.Format(time.Kitchen) + " " + t.Format("Mon Jan _2 15:04:05 MST 2006")
+}
+
+func AppendDate(buf []byte, t time.Time) []byte {
+	return t.AppendFormat(buf, "DD.MM.YYYY") // want time_layout "Time layout in another notation (YYYY-MM-DD); Go layouts use 2006-01-02"
+}
@@ -0,0 +1,56 @@
+package timevalues
+
+import (
+	"database/sql"
+	"log"
+	"time"
+)
+
+const retries = 3
+
+var defaultTimeout = 5 * time.Second
+
+type Config struct {
+	Timeout time.Duration
+	Seconds int
+	Created time.Time
+}
+
+func Expired(deadline time.Time) bool {
+	return time.Now() == deadline // want time_equal "time.Time compared with == (compares location and monotonic clock; use Equal)"
+}
+
+func Unchanged(c Config, at time.Time) bool {
+	return c.Created != at // want time_equal "time.Time compared with == (compares location and monotonic clock; use Equal)"
+}
+
+func Unset(c Config) bool {
+	return c.Created == time.Time{} // want time_equal "time.Time compared with == (compares location and monotonic clock; use Equal)"
+}
+
+func Same(a, b time.Time) bool {
+	return a.Equal(b) && a.Unix() == b.Unix()
+}
+
+func Wait(timeout time.Duration) {
+	time.Sleep(timeout * time.Second) // want duration_double_unit "time.Duration multiplied by a time unit again (timeout * time.Second)"
+}
+
+func Dial(c Config) time.Duration {
+	return time.Duration(c.Timeout) * time.Second // want duration_double_unit "time.Duration multiplied by a time unit again (timeout * time.Second)"
+}
+
+func Backoff(c Config, attempt int) time.Duration {
+	d := time.Duration(c.Seconds) * time.Second
+	return d*time.Duration(attempt) + retries*time.Second + defaultTimeout
+}
+
+func Elapsed(start time.Time) time.Duration {
+	return time.Since(start) * time.Millisecond // want duration_double_unit "time.Duration multiplied by a time unit again (timeout * time.Second)"
+}
+
+func Stamp(db *sql.DB) error {
+	log.Printf("started at %s", time.Now().UTC().Format(time.RFC3339))
+	_, err := db.Exec("UPDATE jobs SET done = ?", time.Now().UTC())
+	return err
+}
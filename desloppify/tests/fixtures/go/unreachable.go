@@ -0,0 +1,54 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+)
+
+func afterReturn(x int) int {
+	return x * 2
+	fmt.Println("never printed")
+}
+
+func afterPanic(msg string) {
+	panic(msg)
+	fmt.Println("never printed")
+}
+
+func afterFatal(err error) {
+	if err != nil {
+		log.Fatalf("fatal: %v",
+			err)
+	}
+	os.Exit(0)
+}
+
+func normal(x int) int {
+	if x > 0 {
+		return x
+	}
+	switch x {
+	case 0:
+		return 1
+	case -1:
+		panic("negative one")
+	default:
+	}
+	for {
+		if x > 10 {
+			break
+		}
+		x++
+	}
+	return func() int {
+		return x
+	}()
+}
+
+func serve(ch chan int) {
+	for {
+		fmt.Println(<-ch)
+	}
+	fmt.Println("stopped")
+}
@@ -0,0 +1,32 @@
+package unusedfields
+
+type Session struct {
+	ID      string
+	user    string
+	expires int64
+	legacy  bool   // want unused_field "Unexported struct field nothing in the package reads or sets"
+	token   string `json:"token"` // want json_tag_unexported "json tag on an unexported field (encoding/json ignores the field)"
+	row     int    `db:"row_id"`
+}
+
+func NewSession(id, user string) *Session {
+	return &Session{ID: id, user: user}
+}
+
+func (s *Session) Expired(now int64) bool {
+	return s.expires != 0 && now > s.expires
+}
+
+func (s *Session) User() string {
+	return s.user
+}
+
+type point struct {
+	x, y int
+}
+
+var origin = point{0, 0}
+
+func Origin() int {
+	return origin.x
+}
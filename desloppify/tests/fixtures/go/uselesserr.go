@@ -0,0 +1,53 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+)
+
+func validate(name string) error {
+	if name == "" {
+		return nil
+	}
+	fmt.Println(name)
+	return nil
+}
+
+func load(path string) (data []byte, err error) {
+	fmt.Println(path)
+	return
+}
+
+func parse(s string) (int, error) {
+	if s == "" {
+		return 0, errors.New("empty")
+	}
+	return len(s), nil
+}
+
+func read(path string) ([]byte, error) {
+	return os.ReadFile(path)
+}
+
+func open(path string) (f *os.File, err error) {
+	f, err = os.Open(path)
+	return
+}
+
+func recovered() (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return nil
+}
+
+func wrapped(path string) error {
+	check := func() error { return errors.New("x") }
+	if err := check(); err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+)
+
+type Settings struct{ Name string }
+
+func loadSettings(path string) (*Settings, error) {
+	result := &Settings{Name: path}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return result, err
+	}
+	result.Name = string(data)
+	return result, nil
+}
+
+func parsePort(s string) (int, error) {
+	if s == "" {
+		return 8080, errors.New("empty port")
+	}
+	return len(s), nil
+}
+
+func readSettings(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+func portFor(ports map[string]int, name string) (int, error) {
+	if port, ok := ports[name]; ok {
+		return port, nil
+	}
+	return 0, fmt.Errorf("no port for %q", name)
+}
+
+func openCount(path string) (int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return -1, err
+	}
+	defer f.Close()
+	return 1, nil
+}
+
+func statSettings(path string) (*Settings, error) {
+	cfg := &Settings{}
+	if _, err := os.Stat(path); err != nil {
+		return cfg, err //desloppify:ignore value_with_error
+	}
+	return cfg, nil
+}
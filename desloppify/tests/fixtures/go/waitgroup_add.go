@@ -0,0 +1,70 @@
+package main
+
+import "sync"
+
+func work(n int) {}
+
+// Wait may run before any goroutine has called Add.
+func addInside(n int) {
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		go func(i int) {
+			wg.Add(1)
+			defer wg.Done()
+			work(i)
+		}(i)
+	}
+	wg.Wait()
+}
+
+type pool struct {
+	wg *sync.WaitGroup
+}
+
+func (p *pool) spawn(n int) {
+	go func() {
+		p.wg.Add(1)
+		defer p.wg.Done()
+		work(n)
+	}()
+}
+
+// Add before go is the correct form.
+func addBefore(n int) {
+	wg := &sync.WaitGroup{}
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			work(i)
+		}(i)
+	}
+	wg.Wait()
+}
+
+// A goroutine that waits on its own group may Add inside it.
+func dispatcher(n int, done chan<- struct{}) {
+	go func() {
+		var workers sync.WaitGroup
+		for i := 0; i < n; i++ {
+			workers.Add(1)
+			go func(i int) {
+				defer workers.Done()
+				work(i)
+			}(i)
+		}
+		workers.Wait()
+		close(done)
+	}()
+}
+
+type counter struct{ total int }
+
+func (c *counter) Add(n int) { c.total += n }
+
+// Add on something that is not a WaitGroup is not this bug.
+func tally(c *counter) {
+	go func() {
+		c.Add(1)
+	}()
+}
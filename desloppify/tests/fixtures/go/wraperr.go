@@ -0,0 +1,34 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+)
+
+var ErrMissing = errors.New("missing")
+
+func openConfig(path string) error {
+	if _, err := os.Stat(path); err != nil {
+		return fmt.Errorf("stat config: %v", err)
+	}
+	if path == "" {
+		return fmt.Errorf("%s: opening %q", ErrMissing, path)
+	}
+	return nil
+}
+
+func wrapConfig(path string) error {
+	if _, err := os.Stat(path); err != nil {
+		return fmt.Errorf("stat %s: %w", path, err)
+	}
+	return nil
+}
+
+func countConfig(n int) error {
+	return fmt.Errorf("config: %d entries, want 1", n)
+}
+
+func describeConfig(err error) error {
+	return fmt.Errorf("config: %v%%", err.Error())
+}
@@ -0,0 +1,8 @@
+package broken
+
+// TODO: finish the signature below; this file deliberately does not parse.
+func Oops( {
+	if true {
+	}
+	return 1
+}
@@ -0,0 +1,14 @@
+package broken
+
+// Two unrelated mistakes; gofmt -e reports both.
+var total = 1 +
+
+func Sum(values []int) int {
+	n := 0
+	for _, v := range values {
+		n += v
+	}
+	return n
+}
+
+var names = []string{"a", "b"
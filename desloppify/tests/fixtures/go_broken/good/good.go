@@ -0,0 +1,10 @@
+package good
+
+// Sum adds the values.
+func Sum(values []int) int {
+	total := 0
+	for _, v := range values {
+		total += v
+	}
+	return total
+}
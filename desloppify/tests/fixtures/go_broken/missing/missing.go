@@ -0,0 +1,6 @@
+package missing
+
+import "example.com/gobroken-nowhere/pkg"
+
+// Value comes from a module that is not in go.mod, so the package cannot load.
+func Value() int { return pkg.Value }
@@ -0,0 +1,4 @@
+package typed
+
+// Use refers to an undefined name, so the package parses but does not type-check.
+func Use() int { return undefinedThing }
@@ -0,0 +1,4 @@
+//go:build ignore
+
+// Command gen regenerates tables.
+package main
@@ -0,0 +1,7 @@
+// Copyright notice.
+
+// +build !windows,!plan9
+
+package main
+
+const unixLike = true
@@ -0,0 +1,7 @@
+//go:build linux
+
+package main
+
+func platform() string {
+	return "linux"
+}
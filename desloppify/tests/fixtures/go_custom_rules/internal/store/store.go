@@ -0,0 +1,35 @@
+package store
+
+import (
+	"sync"
+	t "time"
+)
+
+type Store struct {
+	mu    sync.Mutex
+	other sync.Mutex
+	data  map[string]t.Time
+}
+
+func (s *Store) Touch(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data[key] = t.Now() // want no_time_now "clock.Now"
+}
+
+func (s *Store) Leak(key string) {
+	s.other.Lock() // want lock_without_unlock "Lock without Unlock" mutex_unlock_missing "never unlocks it" resource_not_released "never releases it"
+	s.mu.Unlock()
+	delete(s.data, key)
+}
+
+func (s *Store) Reset() {
+	s.mu.Lock() //desloppify:ignore lock_without_unlock -- released by Commit
+	s.data = map[string]t.Time{}
+}
+
+// time.Now() in a comment is not a call.
+func (s *Store) Commit() {
+	s.mu.Unlock()
+	_ = "time.Now()"
+}
@@ -0,0 +1,11 @@
+package main
+
+import (
+	"fmt"
+
+	"example.com/house/legacy" // want no_legacy_client "frozen"
+)
+
+func main() {
+	fmt.Println(legacy.Fetch())
+}
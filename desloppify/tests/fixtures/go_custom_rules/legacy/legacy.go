@@ -0,0 +1,6 @@
+package legacy
+
+// Fetch is the frozen client call.
+func Fetch() string {
+	return "data"
+}
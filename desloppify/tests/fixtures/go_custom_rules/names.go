@@ -0,0 +1,19 @@
+package main
+
+type SessionManager struct{} // want no_manager_names "not Manager"
+
+type (
+	Cache        struct{}
+	QueueManager struct{} // want no_manager_names "not Manager"
+)
+
+func NewManager() *SessionManager { // want no_manager_names "not Manager"
+	connManager := &SessionManager{} // want no_manager_names "not Manager"
+	return connManager
+}
+
+var managerCount int
+
+const (
+	maxRetries, PoolManager = 3, 4 // want no_manager_names "not Manager"
+)
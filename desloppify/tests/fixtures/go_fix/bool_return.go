@@ -0,0 +1,25 @@
+package main
+
+import "strings"
+
+func isHidden(name string) bool {
+	if strings.HasPrefix(name, ".") {
+		return true
+	}
+	return false
+}
+
+func isVisible(name string) bool {
+	if name == "" || strings.HasPrefix(name, ".") {
+		return false
+	} else {
+		return true
+	}
+}
+
+func isEmpty(name string) bool {
+	if name != "" {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,67 @@
+// Package main exercises fixes next to comments; every comment here must
+// survive `desloppify fix smells` byte for byte.
+package main
+
+import (
+	"fmt" // errors are wrapped below
+	"os"
+)
+
+// Limits, aligned the way gofmt aligns trailing comments.
+const (
+	minSize = 1    // smallest accepted
+	maxSize = 4096 // largest accepted
+)
+
+/*
+lookup stats a path.
+
+It is deliberately written in the old style.
+*/
+func lookup(path string) (os.FileInfo, error) {
+	// A comment inside the condition: reported, not rewritten.
+	if "" == path /* never */ { // trailing
+		return nil, fmt.Errorf("empty path") // no operand to wrap
+	}
+	info, err := os.Stat(path)
+	if nil != err { // stat failed
+		// Keep the cause for errors.Is.
+		return nil, fmt.Errorf("lookup %s: %v", path, err) // wrap me
+	}
+	return info, nil
+}
+
+// fits reports whether a file size is in range.
+func fits(size int64) bool {
+	if size < minSize {
+		return false // too small
+	} else /* a comment the rewrite would drop */ {
+		return size <= maxSize
+	}
+}
+
+// describe names a size.
+func describe(size int64) string {
+	if size == 0 {
+		return "empty" // nothing there
+	} else {
+		// Outdented along with the body.
+		return /* inline */ "file" // the common case
+	}
+}
+
+// isLarge keeps a comment inside its condition.
+func isLarge(size int64) bool {
+	if size > maxSize /* strictly */ {
+		return true
+	}
+	return false
+}
+
+// isSmall has a plain condition.
+func isSmall(size int64) bool {
+	if size < minSize {
+		return true
+	}
+	return false
+}
@@ -0,0 +1,17 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+)
+
+func label(ok bool) string {
+	if "true" == fmt.Sprintf("%t", ok) {
+		return fmt.Sprintf("%t", ok)
+	}
+	return strconv.Quote("no")
+}
+
+func main() {
+	fmt.Println(label(true), check(true), open("go.mod"), classify(1, "x"))
+}
@@ -0,0 +1,33 @@
+package main
+
+import "os"
+
+func size(path string) (int64, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0, err
+	} else {
+		// Directories report their entry size, not their contents.
+		if info.IsDir() {
+			return 0, nil
+		}
+		return info.Size(), nil
+	}
+}
+
+func mode(path string) string {
+	if info, err := os.Stat(path); err != nil {
+		return ""
+	} else {
+		return info.Mode().String()
+	}
+}
+
+func kind(path string) string {
+	if path == "" {
+		return "none"
+	} else {
+		name := path
+		return name
+	}
+}
@@ -0,0 +1,20 @@
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+func load(path string) error {
+	if _, err := os.Stat(path); err != nil {
+		return fmt.Errorf("stat %q: %v", path, err)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("open: %+v", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("close %s: %s", path, err)
+	}
+	return nil
+}
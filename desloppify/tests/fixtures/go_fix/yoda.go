@@ -0,0 +1,31 @@
+package main
+
+import (
+	"errors"
+	"os"
+)
+
+func open(path string) error {
+	f, err := os.Open(path)
+	if nil != err {
+		return err
+	}
+	return f.Close()
+}
+
+func classify(n int, name string) string {
+	if 0 < n && "admin" == name {
+		return "admin"
+	}
+	if 10 >= len(name) {
+		return "short"
+	}
+	return "other"
+}
+
+func check(ok bool) error {
+	if false == ok {
+		return errors.New("not ok")
+	}
+	return nil
+}
@@ -0,0 +1,8 @@
+package api
+
+import "fmt"
+
+// Route wires one handler; the module's own config allows seven parameters.
+func Route(method, path, name, group, version, owner string) string {
+	return fmt.Sprintf("%s %s %s %s %s %s", method, path, name, group, version, owner)
+}
@@ -0,0 +1,9 @@
+package billing
+
+import "fmt"
+
+// Charge formats one invoice line.
+func Charge(customer, plan, currency, region, period, note string) string {
+	fmt.Printf("%d\n", customer)
+	return customer + plan + currency + region + period + note
+}
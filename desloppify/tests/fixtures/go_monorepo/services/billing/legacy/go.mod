@@ -0,0 +1,3 @@
+module example.com/monorepo/billing/legacy
+
+go 1.20
@@ -0,0 +1,6 @@
+package legacy
+
+// Convert is kept for old callers.
+func Convert(a, b, c, d, e, f int) int {
+	return a + b + c + d + e + f
+}
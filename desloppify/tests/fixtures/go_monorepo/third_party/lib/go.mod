@@ -0,0 +1,3 @@
+module example.com/thirdparty/lib
+
+go 1.21
@@ -0,0 +1,6 @@
+package lib
+
+// Pack is vendored code nobody here maintains.
+func Pack(a, b, c, d, e, f int) int {
+	return a + b + c + d + e + f
+}
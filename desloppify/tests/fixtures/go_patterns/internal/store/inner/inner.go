@@ -0,0 +1,6 @@
+package inner
+
+// Inner is a nested package.
+func Inner() int {
+	return 1
+}
@@ -0,0 +1,6 @@
+package store
+
+// Get returns the stored value.
+func Get() string {
+	return "value"
+}
@@ -0,0 +1,8 @@
+//go:build debug
+
+package store
+
+// Dump is only built with -tags debug.
+func Dump() string {
+	return Get()
+}
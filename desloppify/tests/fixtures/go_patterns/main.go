@@ -0,0 +1,7 @@
+package main
+
+import "example.com/patterns/pkg/api"
+
+func main() {
+	api.Serve()
+}
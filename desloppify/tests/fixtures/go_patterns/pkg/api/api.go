@@ -0,0 +1,8 @@
+package api
+
+import "fmt"
+
+// Serve prints a greeting.
+func Serve() {
+	fmt.Println("serving")
+}
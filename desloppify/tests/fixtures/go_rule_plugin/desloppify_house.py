@@ -0,0 +1,13 @@
+"""A house build of desloppify with the example rules built in.
+
+The thin-main way to ship out-of-tree rules: import the rule modules, then
+hand over to the normal command line. ``python desloppify_house.py scan``
+behaves like ``desloppify scan`` with ``house_lint``'s rules registered.
+"""
+
+import house_lint  # noqa: F401 - registers the rules
+
+from desloppify.cli import main
+
+if __name__ == "__main__":
+    main()
@@ -0,0 +1,37 @@
+"""Example out-of-tree Go rules, loaded as a desloppify rule plugin.
+
+Nothing here is part of desloppify itself: the module only uses the public
+``desloppify.languages.go.rules`` interface, as a separate package would.
+"""
+
+from __future__ import annotations
+
+import re
+
+from desloppify.languages.go.rules import rule
+
+_FMT_PRINT_RE = re.compile(r"\bfmt\.Print(?:f|ln)?\s*\(")
+_FUNC_PARAMS_RE = re.compile(r"^func\s*(?:\([^)]*\)\s*)?\w+\s*\(([^)]*)\)", re.MULTILINE)
+
+
+@rule("fmt_print_in_lib", "fmt.Print in library code (use the logger)", severity="low")
+def fmt_print_in_lib(pass_):
+    if re.search(r"^package\s+main\b", pass_.file.masked, re.MULTILINE):
+        return
+    for number, line in enumerate(pass_.file.masked_lines, 1):
+        if _FMT_PRINT_RE.search(line):
+            pass_.report(number)
+
+
+@rule(
+    "context_not_first",
+    "context.Context is not the first parameter",
+    severity="medium",
+    opt_in=True,
+    categories=("style",),
+)
+def context_not_first(pass_):
+    for match in _FUNC_PARAMS_RE.finditer(pass_.file.masked):
+        params = [p.strip() for p in match.group(1).split(",")]
+        if any("context.Context" in p for p in params[1:]):
+            pass_.report(pass_.file.line_at(match.start()))
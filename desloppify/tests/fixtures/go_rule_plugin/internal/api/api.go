@@ -0,0 +1,24 @@
+package api
+
+import (
+	"context"
+	"fmt"
+)
+
+func Fetch(ctx context.Context, id string) error {
+	fmt.Println("fetching", id)
+	return ctx.Err()
+}
+
+func Store(id string, ctx context.Context) error {
+	// fmt.Println("not code")
+	return ctx.Err()
+}
+
+func Describe(id string) string {
+	return fmt.Sprintf("item %s", id) //desloppify:ignore fmt_print_in_lib
+}
+
+func Log(id string) {
+	fmt.Printf("%s\n", id) //desloppify:ignore fmt_print_in_lib
+}
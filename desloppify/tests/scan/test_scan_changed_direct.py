@@ -0,0 +1,294 @@
+"""Direct tests for changed-files scan selection (--changed / --since)."""
+
+from __future__ import annotations
+
+import subprocess
+from pathlib import Path
+from types import SimpleNamespace
+
+import desloppify.app.commands.scan.scan_changed as scan_changed_mod
+import desloppify.app.commands.scan.scan_workflow as scan_workflow_mod
+import desloppify.languages.go.detectors.smells as go_smells_mod
+from desloppify.cli import create_parser
+from desloppify.core.runtime_state import RuntimeContext, runtime_scope
+from desloppify.file_discovery import find_source_files, set_selected_dirs
+from desloppify.state import MergeScanOptions, empty_state, merge_scan
+
+
+def _git(root: Path, *args: str) -> None:
+    subprocess.run(
+        ["git", *args],
+        cwd=root,
+        check=True,
+        capture_output=True,
+        env={
+            "GIT_AUTHOR_NAME": "t",
+            "GIT_AUTHOR_EMAIL": "t@example.com",
+            "GIT_COMMITTER_NAME": "t",
+            "GIT_COMMITTER_EMAIL": "t@example.com",
+            "PATH": "/usr/bin:/bin:/usr/local/bin",
+        },
+    )
+
+
+def _make_repo(root: Path) -> None:
+    for rel_path in ("main.go", "pkg/a/a.go", "pkg/b/b.go", "pkg/c/c.go"):
+        target = root / rel_path
+        target.parent.mkdir(parents=True, exist_ok=True)
+        target.write_text("package x\n")
+    _git(root, "init", "-q")
+    _git(root, "add", "-A")
+    _git(root, "commit", "-q", "-m", "base")
+
+
+def test_parse_porcelain_includes_rename_sources():
+    output = " M pkg/a/a.go\0R  pkg/new/n.go\0pkg/old/n.go\0?? docs/x.go\0"
+    assert scan_changed_mod._parse_porcelain_z(output) == [
+        "pkg/a/a.go",
+        "pkg/new/n.go",
+        "pkg/old/n.go",
+        "docs/x.go",
+    ]
+
+
+def test_changed_dirs_maps_root_files_and_drops_outside_paths(tmp_path):
+    project = tmp_path / "proj"
+    project.mkdir()
+    dirs = scan_changed_mod.changed_dirs(
+        ["proj/main.go", "proj/pkg/a/a.go", "other/z.go"],
+        toplevel=tmp_path,
+        project_root=project,
+    )
+    assert dirs == [".", "pkg/a"]
+
+
+def test_changed_selects_modified_staged_and_untracked(tmp_path):
+    _make_repo(tmp_path)
+    (tmp_path / "pkg/a/a.go").write_text("package x\n// edit\n")
+    (tmp_path / "pkg/b/b.go").write_text("package x\n// staged\n")
+    _git(tmp_path, "add", "pkg/b/b.go")
+    (tmp_path / "pkg/d").mkdir()
+    (tmp_path / "pkg/d/d.go").write_text("package d\n")
+
+    with runtime_scope(RuntimeContext(project_root=tmp_path)):
+        selected = scan_changed_mod.resolve_changed_selection(
+            SimpleNamespace(changed=True, since=None)
+        )
+    assert selected == ("pkg/a", "pkg/b", "pkg/d")
+
+
+def test_since_selects_commit_range_and_composes_with_changed(tmp_path):
+    _make_repo(tmp_path)
+    (tmp_path / "pkg/c/c.go").write_text("package x\n// committed\n")
+    _git(tmp_path, "commit", "-q", "-am", "touch c")
+    (tmp_path / "main.go").write_text("package main\n// dirty\n")
+
+    with runtime_scope(RuntimeContext(project_root=tmp_path)):
+        since_only = scan_changed_mod.resolve_changed_selection(
+            SimpleNamespace(changed=False, since="HEAD~1")
+        )
+        both = scan_changed_mod.resolve_changed_selection(
+            SimpleNamespace(changed=True, since="HEAD~1")
+        )
+    assert since_only == ("pkg/c",)
+    assert both == (".", "pkg/c")
+
+
+def test_non_git_directory_warns_and_falls_back(tmp_path, capsys):
+    with runtime_scope(RuntimeContext(project_root=tmp_path)):
+        selected = scan_changed_mod.resolve_changed_selection(
+            SimpleNamespace(changed=True, since=None)
+        )
+    assert selected is None
+    assert "running a full scan" in capsys.readouterr().err
+
+
+def test_no_flags_means_no_selection():
+    assert (
+        scan_changed_mod.resolve_changed_selection(
+            SimpleNamespace(changed=False, since=None)
+        )
+        is None
+    )
+
+
+def test_selected_dirs_restrict_discovery_to_whole_packages(tmp_path):
+    _make_repo(tmp_path)
+    (tmp_path / "pkg/a/a2.go").write_text("package x\n")
+    with runtime_scope(RuntimeContext(project_root=tmp_path)):
+        set_selected_dirs(["pkg/a"])
+        files = find_source_files(tmp_path, [".go"])
+        set_selected_dirs(None)
+        all_files = find_source_files(tmp_path, [".go"])
+    assert files == ["pkg/a/a.go", "pkg/a/a2.go"]
+    assert len(all_files) == 5
+
+
+def test_merge_does_not_auto_resolve_outside_selection():
+    state = empty_state()
+    for fid, file in (("smells::pkg/a/a.go::x", "pkg/a/a.go"), ("smells::pkg/b/b.go::x", "pkg/b/b.go")):
+        state["findings"][fid] = {
+            "id": fid,
+            "detector": "smells",
+            "file": file,
+            "tier": 3,
+            "confidence": "medium",
+            "summary": "s",
+            "detail": {},
+            "status": "open",
+            "note": None,
+            "first_seen": "2025-01-01T00:00:00+00:00",
+            "last_seen": "2025-01-01T00:00:00+00:00",
+            "resolved_at": None,
+            "reopen_count": 0,
+            "lang": "go",
+        }
+
+    diff = merge_scan(
+        state,
+        [],
+        MergeScanOptions(lang="go", force_resolve=True, selected_dirs=("pkg/a",)),
+    )
+    assert diff["auto_resolved"] == 1
+    assert state["findings"]["smells::pkg/a/a.go::x"]["status"] == "auto_resolved"
+    assert state["findings"]["smells::pkg/b/b.go::x"]["status"] == "open"
+
+
+def test_merge_does_not_auto_resolve_findings_on_unchanged_lines():
+    state = empty_state()
+    for fid, line in (("smells::pkg/a/a.go::3", 3), ("smells::pkg/a/a.go::9", 9)):
+        state["findings"][fid] = {
+            "id": fid,
+            "detector": "smells",
+            "file": "pkg/a/a.go",
+            "tier": 3,
+            "confidence": "medium",
+            "summary": "s",
+            "detail": {"line": line},
+            "status": "open",
+            "note": None,
+            "first_seen": "2025-01-01T00:00:00+00:00",
+            "last_seen": "2025-01-01T00:00:00+00:00",
+            "resolved_at": None,
+            "reopen_count": 0,
+            "lang": "go",
+        }
+
+    diff = merge_scan(
+        state,
+        [],
+        MergeScanOptions(
+            lang="go",
+            force_resolve=True,
+            changed_lines={"pkg/a/a.go": frozenset({3})},
+        ),
+    )
+    assert diff["auto_resolved"] == 1
+    assert state["findings"]["smells::pkg/a/a.go::3"]["status"] == "auto_resolved"
+    assert state["findings"]["smells::pkg/a/a.go::9"]["status"] == "open"
+
+
+def _scanned_packages(monkeypatch) -> set[str]:
+    scanned: set[str] = set()
+    scan = go_smells_mod._scan_package
+
+    def record(files, **kwargs):
+        scanned.add(files[0].rsplit("/", 1)[0])
+        return scan(files, **kwargs)
+
+    monkeypatch.setattr(go_smells_mod, "_scan_package", record)
+    return scanned
+
+
+def test_changed_scan_analyzes_changed_packages_and_their_importers(tmp_path, monkeypatch):
+    (tmp_path / "go.mod").write_text("module example.com/x\n\ngo 1.22\n")
+    branches = "".join(f"\tif x == {i} {{\n\t\treturn y + {i}\n\t}}\n" for i in range(14))
+    files = {
+        "pkg/a/a.go": "package a\n\nimport \"fmt\"\n\n"
+        f"// Add sums its arguments.\nfunc Add(x, y int) int {{\n{branches}\treturn x + y\n}}\n\n"
+        "// Name labels n.\nfunc Name(n int) string { return fmt.Sprintf(\"%d\", n) }\n",
+        "pkg/b/b.go": "package b\n\nimport (\n\t\"fmt\"\n\n\t\"example.com/x/pkg/a\"\n)\n\n"
+        "// Twice doubles n.\nfunc Twice(n int) int { return a.Add(n, n) }\n\n"
+        "// Label labels n.\nfunc Label(n int) string { return fmt.Sprintf(\"%d\", n) }\n",
+        "pkg/b/b_test.go": "package b\n\nimport \"testing\"\n\n"
+        "func TestTwice(t *testing.T) {\n\tif Twice(2) != 4 {\n\t\tt.Fatal(\"bad\")\n\t}\n}\n",
+        "pkg/c/c.go": "package c\n\nimport \"fmt\"\n\n"
+        "// Label labels n.\nfunc Label(n int) string { return fmt.Sprintf(\"%d\", n) }\n",
+    }
+    for rel_path, text in files.items():
+        (tmp_path / rel_path).parent.mkdir(parents=True, exist_ok=True)
+        (tmp_path / rel_path).write_text(text)
+    _git(tmp_path, "init", "-q")
+    _git(tmp_path, "add", "-A")
+    _git(tmp_path, "commit", "-q", "-m", "base")
+    (tmp_path / "pkg/a/a.go").write_text(files["pkg/a/a.go"] + "\n// edit\n")
+    monkeypatch.chdir(tmp_path)
+    scanned = _scanned_packages(monkeypatch)
+
+    args = create_parser().parse_args(
+        ["--lang", "go", "scan", "--path", str(tmp_path), "--changed", "--skip-slow"]
+        + ["--jobs", "1", "--no-cache"]
+    )
+    with runtime_scope(RuntimeContext(project_root=tmp_path)):
+        runtime = scan_workflow_mod.prepare_scan_runtime(args)
+        findings, _potentials, _metrics = scan_workflow_mod.run_scan_generation(runtime)
+
+    # pkg/b imports pkg/a; pkg/c is neither analyzed nor reported.
+    assert runtime.selected_dirs == ("pkg/a", "pkg/b")
+    assert scanned == {"pkg/a", "pkg/b"}
+    assert {f["file"].rsplit("/", 1)[0] for f in findings} <= {"pkg/a", "pkg/b"}
+    # pkg/b's tests were discovered, so pkg/a is untested rather than in a test-less tree.
+    coverage = [f for f in findings if f["detector"] == "test_coverage"]
+    assert coverage and all("no test files" not in f["summary"] for f in coverage)
+    sprintf = [f for f in findings if f["detail"].get("smell_id") == "sprintf_strconv"]
+    assert [m["file"] for f in sprintf for m in f["detail"]["matches"]] == [
+        "pkg/a/a.go",
+        "pkg/b/b.go",
+    ]
+
+
+def test_parse_changed_lines_reads_new_side_hunks():
+    output = (
+        "diff --git pkg/a.go pkg/a.go\n--- pkg/a.go\n+++ pkg/a.go\n"
+        "@@ -3 +3 @@\n-x\n+y\n@@ -10,0 +11,2 @@\n+a\n+b\n"
+        "diff --git gone.go gone.go\n--- gone.go\n+++ /dev/null\n@@ -1,2 +0,0 @@\n-a\n-b\n"
+        "diff --git b.go b.go\n--- b.go\n+++ b.go\n@@ -4,2 +3,0 @@\n-a\n-b\n"
+    )
+    assert scan_changed_mod.parse_changed_lines(output) == {
+        "pkg/a.go": frozenset({3, 11, 12}),
+        "b.go": frozenset(),
+    }
+
+
+def test_diff_base_reports_only_changed_lines(tmp_path, monkeypatch):
+    (tmp_path / "go.mod").write_text("module example.com/x\n\ngo 1.22\n")
+    label = "func {name}(n int) string {{ return fmt.Sprintf(\"%d\", n) }}\n"
+    base = "package a\n\nimport \"fmt\"\n\n" + label.format(name="One")
+    for rel_path in ("pkg/a/a.go", "pkg/b/b.go"):
+        (tmp_path / rel_path).parent.mkdir(parents=True, exist_ok=True)
+        (tmp_path / rel_path).write_text(base)
+    _git(tmp_path, "init", "-q")
+    _git(tmp_path, "add", "-A")
+    _git(tmp_path, "commit", "-q", "-m", "base")
+    (tmp_path / "pkg/a/a.go").write_text(base + label.format(name="Two"))
+    monkeypatch.chdir(tmp_path)
+    scanned = _scanned_packages(monkeypatch)
+
+    args = create_parser().parse_args(
+        ["--lang", "go", "scan", "--path", str(tmp_path), "--diff-base", "HEAD"]
+        + ["--changed", "--skip-slow", "--jobs", "1", "--no-cache"]
+    )
+    with runtime_scope(RuntimeContext(project_root=tmp_path)):
+        runtime = scan_workflow_mod.prepare_scan_runtime(args)
+        findings, _potentials, _metrics = scan_workflow_mod.run_scan_generation(runtime)
+
+    assert scanned == {"pkg/a"}
+    sprintf = [f for f in findings if f["detail"].get("smell_id") == "sprintf_strconv"]
+    assert [m["line"] for f in sprintf for m in f["detail"]["matches"]] == [6]
+
+
+def test_diff_base_outside_git_warns_and_falls_back(tmp_path, capsys):
+    with runtime_scope(RuntimeContext(project_root=tmp_path)):
+        lines = scan_changed_mod.resolve_diff_base(SimpleNamespace(diff_base="HEAD"))
+    assert lines is None
+    assert "running a full scan" in capsys.readouterr().err
@@ -0,0 +1,115 @@
+"""Direct tests for CLI exit codes: 0 clean, 1 findings, 2 errors, 3 degraded."""
+
+from __future__ import annotations
+
+import sys
+from types import SimpleNamespace
+
+import pytest
+
+import desloppify.app.commands.scan.scan as scan_mod
+import desloppify.app.commands.scan.scan_stream as scan_stream_mod
+import desloppify.cli as cli_mod
+import desloppify.core.config as config_mod
+from desloppify.app.commands.helpers.exit_codes import (
+    DEGRADED_EXIT_CODE,
+    ERROR_EXIT_CODE,
+    FINDINGS_EXIT_CODE,
+)
+
+_HIGH = {"id": "a", "detector": "structural", "confidence": "high", "detail": {}}
+_LOW = {"id": "b", "detector": "smells", "detail": {"severity": "low"}}
+
+
+@pytest.fixture
+def stream(monkeypatch):
+    """Run ``scan --stream`` over canned findings; returns a setter for them."""
+    lang = SimpleNamespace(name="go", syntax_only=False, degraded_units=[])
+    runtime = SimpleNamespace(
+        lang=lang,
+        path=".",
+        effective_include_slow=True,
+        zone_overrides=None,
+        profile="full",
+        config={"fail_severity": "medium"},
+    )
+    found: list[dict] = []
+    monkeypatch.setattr(scan_stream_mod, "prepare_scan_runtime", lambda _args: runtime)
+    monkeypatch.setattr(
+        scan_stream_mod,
+        "iter_phase_results",
+        lambda *_a, **_k: iter([SimpleNamespace(findings=list(found))]),
+    )
+
+    def set_findings(*findings: dict, degraded: int = 0) -> None:
+        found[:] = findings
+        lang.degraded_units = [{"unit": "x"}] * degraded
+
+    return set_findings
+
+
+def _exit_code(**args) -> int:
+    try:
+        scan_mod.cmd_scan(SimpleNamespace(stream=True, **args))
+    except SystemExit as exc:
+        return exc.code
+    return 0
+
+
+def test_scan_exit_codes_follow_fail_severity_and_no_fail(stream):
+    stream(_LOW)
+    assert _exit_code() == 0
+    stream(_LOW, _HIGH)
+    assert _exit_code() == FINDINGS_EXIT_CODE
+    assert _exit_code(no_fail=True) == 0
+    # Findings outrank a degraded run; --no-fail silences both.
+    stream(_HIGH, degraded=1)
+    assert _exit_code(fail_on_degraded=True) == FINDINGS_EXIT_CODE
+    assert _exit_code(fail_on_degraded=True, no_fail=True) == 0
+    stream(_LOW, degraded=1)
+    assert _exit_code(fail_on_degraded=True) == DEGRADED_EXIT_CODE
+
+
+def test_only_open_findings_of_the_run_fail_a_full_scan():
+    state = {
+        "findings": {
+            "a": {**_HIGH, "status": "open"},
+            "c": {**_HIGH, "id": "c", "status": "wontfix"},
+            "d": {**_HIGH, "id": "d", "status": "open", "suppressed": True},
+        }
+    }
+    findings = [_HIGH, _LOW, {**_HIGH, "id": "c"}, {**_HIGH, "id": "d"}]
+    assert scan_mod._count_failing(findings, state, "medium") == 1
+    assert scan_mod._count_failing(findings, state, "low") == 2
+
+
+def _main(monkeypatch, *argv: str) -> int:
+    monkeypatch.setattr(sys, "argv", ["desloppify", *argv])
+    with pytest.raises(SystemExit) as exc:
+        cli_mod.main()
+    return exc.value.code
+
+
+def test_unparseable_arguments_exit_2(monkeypatch):
+    assert _main(monkeypatch, "scan", "--no-such-flag") == ERROR_EXIT_CODE
+
+
+def test_unreadable_config_exits_2_and_is_left_alone(tmp_path, monkeypatch, capsys):
+    config = tmp_path / "config.json"
+    config.write_text("{not json")
+    monkeypatch.setattr(config_mod, "CONFIG_FILE", config)
+    state = str(tmp_path / "state.json")
+    assert _main(monkeypatch, "status", "--state", state) == ERROR_EXIT_CODE
+    assert "Invalid config:" in capsys.readouterr().err
+    assert config.read_text() == "{not json"
+
+
+def test_internal_errors_exit_2(tmp_path, monkeypatch, capsys):
+    def crash(_args):
+        raise RuntimeError("boom")
+
+    monkeypatch.setattr(config_mod, "CONFIG_FILE", tmp_path / "config.json")
+    monkeypatch.setattr(cli_mod, "_resolve_handler", lambda _command: crash)
+    state = str(tmp_path / "state.json")
+    assert _main(monkeypatch, "status", "--state", state) == ERROR_EXIT_CODE
+    assert "Internal error: boom" in capsys.readouterr().err
@@ -0,0 +1,115 @@
+"""Direct tests for file-glob scan filters (--only / --skip)."""
+
+from __future__ import annotations
+
+from pathlib import Path
+from types import SimpleNamespace
+
+from desloppify.app.commands.scan.scan_patterns import apply_path_globs
+from desloppify.core.file_paths import PathGlobs, glob_regex
+from desloppify.core.runtime_state import RuntimeContext, runtime_scope
+from desloppify.file_discovery import find_source_files, set_exclusions
+from desloppify.state import MergeScanOptions, empty_state, merge_scan
+
+
+def _make_tree(root: Path) -> None:
+    for rel_path in (
+        "main.go",
+        "zz_gen.go",
+        "internal/a/a.go",
+        "internal/a/a_gen.go",
+        "internal/b/b.go",
+        "pkg/c/c.go",
+    ):
+        target = root / rel_path
+        target.parent.mkdir(parents=True, exist_ok=True)
+        target.write_text("package x\n")
+
+
+def _scan_files(root: Path, *, only=None, skip=None) -> list[str]:
+    args = SimpleNamespace(path=str(root), only=only, skip=skip)
+    with runtime_scope(RuntimeContext(project_root=root)):
+        apply_path_globs(args)
+        return find_source_files(root, [".go"])
+
+
+def test_glob_double_star_spans_directories_and_single_star_does_not():
+    assert glob_regex("internal/**").match("internal/a/a.go")
+    assert not glob_regex("internal/**").match("pkg/internal/a.go")
+    assert glob_regex("**/*_gen.go").match("zz_gen.go")
+    assert glob_regex("**/*_gen.go").match("internal/a/a_gen.go")
+    assert not glob_regex("*_gen.go").match("internal/a/a_gen.go")
+    assert glob_regex("pkg/{a,c}/*.go").match("pkg/c/c.go")
+
+
+def test_only_keeps_the_subtree(tmp_path):
+    _make_tree(tmp_path)
+    assert _scan_files(tmp_path, only=["internal/**"]) == [
+        "internal/a/a.go",
+        "internal/a/a_gen.go",
+        "internal/b/b.go",
+    ]
+
+
+def test_skip_drops_generated_files_at_any_depth(tmp_path):
+    _make_tree(tmp_path)
+    assert _scan_files(tmp_path, skip=["**/*_gen.go"]) == [
+        "internal/a/a.go",
+        "internal/b/b.go",
+        "main.go",
+        "pkg/c/c.go",
+    ]
+
+
+def test_only_and_skip_compose_with_each_other_and_exclusions(tmp_path):
+    _make_tree(tmp_path)
+    args = SimpleNamespace(
+        path=str(tmp_path), only=["internal/**,main.go"], skip=["**/*_gen.go"]
+    )
+    with runtime_scope(RuntimeContext(project_root=tmp_path)):
+        set_exclusions(["internal/b"])
+        apply_path_globs(args)
+        files = find_source_files(tmp_path, [".go"])
+    assert files == ["internal/a/a.go", "main.go"]
+
+
+def test_globs_are_relative_to_the_scan_path(tmp_path):
+    _make_tree(tmp_path)
+    args = SimpleNamespace(path=str(tmp_path / "internal"), only=["a/**"], skip=None)
+    with runtime_scope(RuntimeContext(project_root=tmp_path)):
+        apply_path_globs(args)
+        files = find_source_files(tmp_path / "internal", [".go"])
+    assert files == ["internal/a/a.go", "internal/a/a_gen.go"]
+
+
+def test_merge_does_not_auto_resolve_files_the_globs_leave_out():
+    state = empty_state()
+    for file in ("internal/a/a.go", "pkg/c/c.go"):
+        fid = f"smells::{file}::x"
+        state["findings"][fid] = {
+            "id": fid,
+            "detector": "smells",
+            "file": file,
+            "tier": 3,
+            "confidence": "medium",
+            "summary": "s",
+            "detail": {},
+            "status": "open",
+            "note": None,
+            "first_seen": "2025-01-01T00:00:00+00:00",
+            "last_seen": "2025-01-01T00:00:00+00:00",
+            "resolved_at": None,
+            "reopen_count": 0,
+            "lang": "go",
+        }
+
+    diff = merge_scan(
+        state,
+        [],
+        MergeScanOptions(
+            lang="go", force_resolve=True, path_globs=PathGlobs(only=("internal/**",))
+        ),
+    )
+    assert diff["auto_resolved"] == 1
+    assert state["findings"]["smells::internal/a/a.go::x"]["status"] == "auto_resolved"
+    assert state["findings"]["smells::pkg/c/c.go::x"]["status"] == "open"
@@ -0,0 +1,174 @@
+"""Direct tests for staged-content analysis (scan --staged) and install-hook."""
+
+from __future__ import annotations
+
+import subprocess
+from pathlib import Path
+from types import SimpleNamespace
+
+import pytest
+
+import desloppify.app.commands.scan.scan_staged as scan_staged_mod
+from desloppify.app.commands.helpers.runtime import CommandRuntime
+from desloppify.app.commands.install_hook import (
+    CHAINED_HOOK_NAME,
+    HOOK_MARKER,
+    install_hook,
+)
+from desloppify.core.runtime_state import RuntimeContext, runtime_scope
+from desloppify.state import empty_state
+
+_CLEAN = "package pkg\n\nfunc A(x int) int {\n\treturn x\n}\n"
+_DEAD = "package pkg\n\nfunc A(x int) int {\n\treturn x\n\tprintln(\"dead\")\n}\n"
+
+
+def _git(root: Path, *args: str) -> None:
+    subprocess.run(
+        ["git", *args],
+        cwd=root,
+        check=True,
+        capture_output=True,
+        env={
+            "GIT_AUTHOR_NAME": "t",
+            "GIT_AUTHOR_EMAIL": "t@example.com",
+            "GIT_COMMITTER_NAME": "t",
+            "GIT_COMMITTER_EMAIL": "t@example.com",
+            "PATH": "/usr/bin:/bin:/usr/local/bin",
+        },
+    )
+
+
+def _make_repo(root: Path) -> None:
+    (root / "pkg").mkdir(parents=True)
+    (root / "go.mod").write_text("module example.com/h\n\ngo 1.21\n")
+    (root / "pkg" / "a.go").write_text(_CLEAN)
+    (root / "pkg" / "b.go").write_text("package pkg\n")
+    _git(root, "init", "-q")
+    _git(root, "add", "-A")
+    _git(root, "commit", "-q", "-m", "base")
+
+
+def _args(tmp_path: Path, config: dict | None = None, **overrides) -> SimpleNamespace:
+    values = {
+        "staged": True,
+        "files": [],
+        "lang": "go",
+        "lang_opt": None,
+        "runtime": CommandRuntime(
+            config=config or {}, state=empty_state(), state_path=tmp_path / "state.json"
+        ),
+    }
+    values.update(overrides)
+    return SimpleNamespace(**values)
+
+
+def test_index_helpers_read_staged_not_worktree(tmp_path):
+    _make_repo(tmp_path)
+    (tmp_path / "pkg" / "a.go").write_text(_DEAD)
+    _git(tmp_path, "add", "pkg/a.go")
+    (tmp_path / "pkg" / "a.go").write_text(_CLEAN)
+
+    assert scan_staged_mod.git_staged_changes(tmp_path) == ["pkg/a.go"]
+    assert scan_staged_mod.index_tree_files(tmp_path, "pkg") == ["pkg/a.go", "pkg/b.go"]
+    assert scan_staged_mod.index_blob(tmp_path, "pkg/a.go") == _DEAD
+
+
+def test_cmd_scan_staged_checks_partially_staged_content(tmp_path, monkeypatch, capsys):
+    _make_repo(tmp_path)
+    (tmp_path / "pkg" / "a.go").write_text(_DEAD)
+    _git(tmp_path, "add", "pkg/a.go")
+    (tmp_path / "pkg" / "a.go").write_text(_CLEAN)
+    monkeypatch.chdir(tmp_path)
+
+    with runtime_scope(RuntimeContext(project_root=tmp_path)):
+        with pytest.raises(SystemExit) as exc:
+            scan_staged_mod.cmd_scan_staged(_args(tmp_path))
+
+    assert exc.value.code == 1
+    out = capsys.readouterr().out
+    assert "pkg/a.go:5" in out
+    assert "unreachable_code" in out
+
+
+def test_cmd_scan_staged_respects_severity_threshold(tmp_path, monkeypatch, capsys):
+    _make_repo(tmp_path)
+    (tmp_path / "pkg" / "a.go").write_text(_DEAD)
+    _git(tmp_path, "add", "pkg/a.go")
+    monkeypatch.chdir(tmp_path)
+
+    with runtime_scope(RuntimeContext(project_root=tmp_path)):
+        scan_staged_mod.cmd_scan_staged(
+            _args(tmp_path, {"staged_fail_severity": "high"}, files=["pkg/a.go"])
+        )
+
+    out = capsys.readouterr().out
+    assert "unreachable_code" in out
+    assert "No staged findings at or above high" in out
+
+
+def test_cmd_scan_staged_no_fail_reports_without_exiting(tmp_path, monkeypatch, capsys):
+    _make_repo(tmp_path)
+    (tmp_path / "pkg" / "a.go").write_text(_DEAD)
+    _git(tmp_path, "add", "pkg/a.go")
+    monkeypatch.chdir(tmp_path)
+
+    with runtime_scope(RuntimeContext(project_root=tmp_path)):
+        scan_staged_mod.cmd_scan_staged(_args(tmp_path, no_fail=True))
+
+    assert "1 finding(s) at or above medium" in capsys.readouterr().out
+
+
+def test_render_staged_counts_matches_at_or_above_threshold(capsys):
+    findings = [
+        {
+            "file": "a.go",
+            "detector": "smells",
+            "summary": "x (2 occurrences in 1 files)",
+            "detail": {
+                "smell_id": "todo_fixme",
+                "severity": "low",
+                "matches": [{"line": 3}, {"line": 9}],
+            },
+        },
+        {"file": "b.go", "detector": "structural", "confidence": "high", "detail": {}},
+    ]
+    assert scan_staged_mod.render_staged(findings, fail_severity="medium") == 1
+    assert scan_staged_mod.render_staged(findings, fail_severity="low") == 3
+    out = capsys.readouterr().out
+    assert "a.go:9" in out
+    assert "occurrences" not in out
+
+
+def test_render_staged_caps_rows_but_counts_every_failure(capsys):
+    findings = [
+        {
+            "file": name,
+            "detector": "smells",
+            "detail": {"smell_id": "todo_fixme", "severity": "medium", "line": 1},
+        }
+        for name in ("c.go", "a.go", "b.go")
+    ]
+    assert (
+        scan_staged_mod.render_staged(findings, fail_severity="medium", max_findings=2) == 3
+    )
+    out = capsys.readouterr().out
+    assert "a.go:1" in out and "b.go:1" in out and "c.go" not in out
+    assert "... and 1 more findings suppressed" in out
+
+
+def test_install_hook_chains_existing_hook_and_is_idempotent(tmp_path):
+    _make_repo(tmp_path)
+    hooks = tmp_path / ".git" / "hooks"
+    hooks.mkdir(exist_ok=True)
+    (hooks / "pre-commit").write_text("#!/bin/sh\necho legacy\n")
+
+    hook, chained = install_hook(tmp_path, "desloppify scan --staged")
+    again, chained_again = install_hook(tmp_path, "desloppify scan --staged")
+
+    assert hook == again == hooks / "pre-commit"
+    assert chained == chained_again == hooks / CHAINED_HOOK_NAME
+    assert chained.read_text() == "#!/bin/sh\necho legacy\n"
+    script = hook.read_text()
+    assert HOOK_MARKER in script
+    assert script.rstrip().endswith("exec desloppify scan --staged")
+    assert hook.stat().st_mode & 0o111
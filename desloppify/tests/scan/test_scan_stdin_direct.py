@@ -0,0 +1,172 @@
+"""Direct tests for stdin single-file analysis (scan --stdin)."""
+
+from __future__ import annotations
+
+import io
+import json
+from pathlib import Path
+from types import SimpleNamespace
+
+import pytest
+
+import desloppify.app.commands.scan.scan_stdin as scan_stdin_mod
+from desloppify.app.commands.helpers.runtime import CommandRuntime
+from desloppify.core.runtime_state import RuntimeContext, runtime_scope
+from desloppify.languages import get_lang
+from desloppify.languages._framework.runtime import make_lang_run
+from desloppify.languages.go.detectors import smells as go_smells_mod
+from desloppify.state import empty_state
+
+_BUFFER = (
+    "package pkg\n\nimport \"fmt\"\n\n"
+    "func Label(n int) string {\n\treturn fmt.Sprintf(\"%d\", n)\n}\n"
+)
+
+
+def _args(tmp_path: Path, filename: str, **overrides) -> SimpleNamespace:
+    values = {
+        "stdin": True,
+        "stdin_filename": filename,
+        "lang": "go",
+        "verbose": False,
+        "lang_opt": None,
+        "runtime": CommandRuntime(
+            config={}, state=empty_state(), state_path=tmp_path / "state.json"
+        ),
+    }
+    values.update(overrides)
+    return SimpleNamespace(**values)
+
+
+def test_stage_overlay_copies_package_and_substitutes_buffer(tmp_path):
+    project = tmp_path / "proj"
+    pkg = project / "pkg"
+    pkg.mkdir(parents=True)
+    (project / "go.mod").write_text("module x\n\ngo 1.21\n")
+    (pkg / "a.go").write_text("package pkg\n")
+    (pkg / "b.go").write_text("package pkg // on disk\n")
+    (pkg / "notes.txt").write_text("ignored\n")
+    overlay = tmp_path / "overlay"
+    overlay.mkdir()
+
+    with runtime_scope(RuntimeContext(project_root=project)):
+        target = scan_stdin_mod.stage_overlay(
+            pkg / "b.go", "package pkg // buffer\n", overlay, get_lang("go"),
+            package_context=True,
+        )
+
+    assert sorted(p.name for p in overlay.iterdir()) == ["go.mod", "pkg"]
+    assert sorted(p.name for p in (overlay / "pkg").iterdir()) == ["a.go", "b.go"]
+    assert target == overlay / "pkg" / "b.go"
+    assert target.read_text() == "package pkg // buffer\n"
+
+
+def test_stage_overlay_new_file_stands_alone(tmp_path):
+    overlay = tmp_path / "overlay"
+    overlay.mkdir()
+    target = scan_stdin_mod.stage_overlay(
+        tmp_path / "missing" / "new.go", "package pkg\n", overlay, get_lang("go"),
+        package_context=False,
+    )
+    assert [p.name for p in overlay.iterdir()] == ["missing"]
+    assert target == overlay / "missing" / "new.go"
+    assert target.read_text() == "package pkg\n"
+
+
+def test_findings_for_file_narrows_aggregated_matches(tmp_path):
+    target = tmp_path / "pkg" / "b.go"
+    findings = [
+        {
+            "id": "smells::pkg/a.go::go_smell::todo_fixme",
+            "file": "pkg/a.go",
+            "detail": {
+                "matches": [
+                    {"file": "pkg/a.go", "line": 1},
+                    {"file": "pkg/b.go", "line": 4},
+                ]
+            },
+        },
+        {"id": "structural::pkg/a.go", "file": "pkg/a.go", "detail": {}},
+        {"id": "structural::pkg/b.go", "file": "pkg/b.go", "detail": {}},
+    ]
+    selected = scan_stdin_mod.findings_for_file(findings, target, "pkg/b.go")
+    assert [f["id"] for f in selected] == [
+        "smells::pkg/a.go::go_smell::todo_fixme",
+        "structural::pkg/b.go",
+    ]
+    assert selected[0]["file"] == "pkg/b.go"
+    assert selected[0]["detail"]["matches"] == [{"file": "pkg/b.go", "line": 4}]
+
+
+def test_cmd_scan_stdin_new_file_degrades_to_syntax_only(tmp_path, monkeypatch, capsys):
+    project = tmp_path / "proj"
+    (project / "pkg").mkdir(parents=True)
+    monkeypatch.chdir(project)
+    monkeypatch.setattr("sys.stdin", io.StringIO(_BUFFER))
+
+    with runtime_scope(RuntimeContext(project_root=project)):
+        scan_stdin_mod.cmd_scan_stdin(_args(tmp_path, "pkg/new.go"))
+
+    payload = json.loads(capsys.readouterr().out)
+    assert payload["file"] == "pkg/new.go"
+    assert payload["mode"] == "syntax_only"
+    ids = [f["id"] for f in payload["findings"]]
+    assert "smells::pkg/new.go::go_smell::sprintf_strconv" in ids
+    assert all(f["file"] == "pkg/new.go" for f in payload["findings"])
+    assert not (project / "pkg" / "new.go").exists()
+
+
+def test_new_file_runs_only_syntax_level_rules(tmp_path, monkeypatch):
+    project = tmp_path / "proj"
+    (project / "pkg").mkdir(parents=True)
+    monkeypatch.chdir(project)
+    ran: list[set[str]] = []
+    scan = go_smells_mod._scan_package
+
+    def record(files, **kwargs):
+        checks = go_smells_mod._enabled_checks(
+            kwargs["opt_in"],
+            syntax_only=kwargs["syntax_only"],
+            rule_options=kwargs["rule_options"],
+        )
+        ran.append({s["requires"] for s in checks})
+        return scan(files, **kwargs)
+
+    monkeypatch.setattr(go_smells_mod, "_scan_package", record)
+    lang_run = make_lang_run(get_lang("go"))
+    with runtime_scope(RuntimeContext(project_root=project)):
+        result = scan_stdin_mod.analyze_buffer(project / "pkg" / "new.go", _BUFFER, lang_run)
+
+    assert result.mode == "syntax_only"
+    assert ran == [{"syntax"}]
+    assert lang_run.syntax_only is False
+
+
+def test_buffers_are_checked_against_their_real_directory(tmp_path, monkeypatch):
+    project = tmp_path / "proj"
+    (project / "internal" / "pkg").mkdir(parents=True)
+    (project / "go.mod").write_text("module example.com/proj\n\ngo 1.21\n")
+    (project / "internal" / "pkg" / "a.go").write_text("package pkg\n")
+    monkeypatch.chdir(project)
+    lang_run = make_lang_run(get_lang("go"))
+
+    def mismatches(filename: str, source: str) -> list[dict]:
+        result = scan_stdin_mod.analyze_buffer(project / filename, source, lang_run)
+        return [
+            m
+            for f in result.findings
+            if f["detail"].get("smell_id") == "package_dir_mismatch"
+            for m in f["detail"]["matches"]
+        ]
+
+    with runtime_scope(RuntimeContext(project_root=project)):
+        assert mismatches("internal/pkg/a.go", _BUFFER) == []
+        assert mismatches("internal/pkg/new.go", _BUFFER) == []
+        [match] = mismatches("internal/pkg/a.go", _BUFFER.replace("package pkg", "package other"))
+    assert (match["package"], match["directory"]) == ("other", "pkg")
+
+
+def test_cmd_scan_stdin_requires_filename(tmp_path, monkeypatch):
+    monkeypatch.setattr("sys.stdin", io.StringIO(_BUFFER))
+    with pytest.raises(SystemExit):
+        scan_stdin_mod.cmd_scan_stdin(_args(tmp_path, None))
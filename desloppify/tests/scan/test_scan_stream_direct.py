@@ -0,0 +1,98 @@
+"""Direct tests for streaming scan output (scan --stream)."""
+
+from __future__ import annotations
+
+import json
+from types import SimpleNamespace
+
+import pytest
+
+import desloppify.app.commands.scan.scan as scan_mod
+import desloppify.app.commands.scan.scan_stream as scan_stream_mod
+
+
+def test_stream_prints_findings_per_phase_then_summary(monkeypatch, capsys):
+    lang = SimpleNamespace(name="go", syntax_only=False, degraded_units=[])
+    runtime = SimpleNamespace(
+        lang=lang,
+        path=".",
+        effective_include_slow=True,
+        zone_overrides=None,
+        profile="full",
+    )
+    printed_before: list[int] = []
+
+    def fake_phases(path, lang_run, *, options):
+        assert lang_run is lang and options.profile == "full"
+        yield SimpleNamespace(findings=[{"id": "a", "detector": "smells", "tier": 3}])
+        printed_before.append(capsys.readouterr().out.count("\n"))
+        yield SimpleNamespace(findings=[{"id": "b", "detector": "security", "tier": 2}])
+
+    monkeypatch.setattr(scan_stream_mod, "prepare_scan_runtime", lambda _args: runtime)
+    monkeypatch.setattr(scan_stream_mod, "iter_phase_results", fake_phases)
+
+    scan_stream_mod.cmd_scan_stream(SimpleNamespace())
+
+    assert printed_before == [1]
+    lines = [json.loads(line) for line in capsys.readouterr().out.splitlines()]
+    assert lines[0]["id"] == "b"
+    assert lines[-1] == {
+        "summary": {
+            "total": 2,
+            "by_detector": {"security": 1, "smells": 1},
+            "by_tier": {"2": 1, "3": 1},
+        }
+    }
+
+
+def test_stream_summary_lists_degraded_units_and_policy_sets_exit_code(
+    monkeypatch, capsys
+):
+    degraded = {
+        "unit": "broken",
+        "kind": "package",
+        "skipped": "types",
+        "phase": "go vet",
+        "error": "broken/broken.go:3:12: expected ')', found '{'",
+    }
+    lang = SimpleNamespace(name="go", syntax_only=False, degraded_units=[degraded])
+    runtime = SimpleNamespace(
+        lang=lang, path=".", effective_include_slow=True, zone_overrides=None, profile="full"
+    )
+    monkeypatch.setattr(scan_stream_mod, "prepare_scan_runtime", lambda _args: runtime)
+    monkeypatch.setattr(scan_stream_mod, "iter_phase_results", lambda *_a, **_k: iter(()))
+
+    scan_mod.cmd_scan(SimpleNamespace(stream=True))
+    summary = json.loads(capsys.readouterr().out.splitlines()[-1])
+    assert summary["diagnostics"] == {"degraded": [degraded]}
+
+    with pytest.raises(SystemExit) as exc:
+        scan_mod.cmd_scan(SimpleNamespace(stream=True, fail_on_degraded=True))
+    assert exc.value.code == scan_mod.DEGRADED_EXIT_CODE == 3
+
+
+def test_stream_max_findings_caps_lines_and_keeps_the_exit_code(monkeypatch, capsys):
+    lang = SimpleNamespace(name="go", syntax_only=False, degraded_units=[])
+    runtime = SimpleNamespace(
+        lang=lang, path=".", effective_include_slow=True, zone_overrides=None, profile="full"
+    )
+    findings = [
+        {"id": f"smells::{name}", "file": name, "detector": "smells", "confidence": "high"}
+        for name in ("c.go", "a.go", "b.go")
+    ]
+    monkeypatch.setattr(scan_stream_mod, "prepare_scan_runtime", lambda _args: runtime)
+    monkeypatch.setattr(
+        scan_stream_mod,
+        "iter_phase_results",
+        lambda *_a, **_k: iter([SimpleNamespace(findings=findings)]),
+    )
+
+    with pytest.raises(SystemExit) as exc:
+        scan_mod.cmd_scan(SimpleNamespace(stream=True, max_findings=2))
+    assert exc.value.code == scan_mod.FINDINGS_EXIT_CODE
+    captured = capsys.readouterr()
+    lines = [json.loads(line) for line in captured.out.splitlines()]
+    assert [line["file"] for line in lines[:-1]] == ["a.go", "b.go"]
+    assert lines[-1]["summary"]["total"] == 3
+    assert lines[-1]["suppressed"] == 1
+    assert "... and 1 more findings suppressed" in captured.err